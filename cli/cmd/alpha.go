@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newCmdAlpha() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alpha",
+		Short: "Experimental commands",
+		Long: `Experimental commands.
+
+These commands are subject to change or removal in future releases.`,
+	}
+
+	cmd.AddCommand(newCmdLoadFixtures())
+
+	return cmd
+}
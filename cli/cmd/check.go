@@ -1,34 +1,45 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"time"
 
+	"github.com/linkerd/linkerd2/cli/output"
 	"github.com/linkerd/linkerd2/pkg/healthcheck"
 	"github.com/spf13/cobra"
 )
 
 const (
-	retryStatus = "[retry]"
-	failStatus  = "[FAIL]"
+	streamJSONOutput = "stream-json"
+	checkJSONOutput  = "json"
 )
 
 type checkOptions struct {
-	versionOverride string
-	preInstallOnly  bool
-	dataPlaneOnly   bool
-	wait            bool
-	namespace       string
+	versionOverride           string
+	preInstallOnly            bool
+	dataPlaneOnly             bool
+	wait                      bool
+	namespace                 string
+	output                    string
+	crtExpiryWarningThreshold time.Duration
+	imageLockFile             string
+	pinnedImages              []string
 }
 
 func newCheckOptions() *checkOptions {
 	return &checkOptions{
-		versionOverride: "",
-		preInstallOnly:  false,
-		dataPlaneOnly:   false,
-		wait:            true,
-		namespace:       "",
+		versionOverride:           "",
+		preInstallOnly:            false,
+		dataPlaneOnly:             false,
+		wait:                      true,
+		namespace:                 "",
+		output:                    "",
+		crtExpiryWarningThreshold: healthcheck.DefaultCertExpiryWarningThreshold,
+		imageLockFile:             "",
+		pinnedImages:              []string{},
 	}
 }
 
@@ -51,10 +62,42 @@ non-zero exit code.`,
   linkerd check --pre --linkerd-namespace test
 
   # Check that the Linkerd data plane proxies in the "app" namespace are up and running
-  linkerd check --proxy --namespace app`,
+  linkerd check --proxy --namespace app
+
+  # Stream each check result as a line of JSON as soon as it completes
+  linkerd check --output stream-json
+
+  # Write all the check results, grouped by category, as a JSON document
+  linkerd check --output json
+
+  # Only warn about issuer and webhook certificates that expire within a week
+  linkerd check --crt-expiry-warning-threshold 168h
+
+  # Check that the images pinned for an air-gapped install can be pulled from the mirror
+  linkerd check --pre --image-lock-file images.lock.yaml`,
 		Args: cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch options.output {
+			case "", streamJSONOutput, checkJSONOutput:
+			default:
+				return fmt.Errorf("output format \"%s\" not recognized", options.output)
+			}
+			if options.crtExpiryWarningThreshold <= 0 {
+				return fmt.Errorf("--crt-expiry-warning-threshold must be positive, got %s", options.crtExpiryWarningThreshold)
+			}
+			if options.imageLockFile != "" {
+				if !options.preInstallOnly {
+					return fmt.Errorf("--image-lock-file can only be used with --pre")
+				}
+				digests, err := readImageLockFile(options.imageLockFile)
+				if err != nil {
+					return err
+				}
+				options.pinnedImages = pinnedImages(digests)
+			}
+
 			configureAndRunChecks(options)
+			return nil
 		},
 	}
 
@@ -64,6 +107,9 @@ non-zero exit code.`,
 	cmd.PersistentFlags().BoolVar(&options.dataPlaneOnly, "proxy", options.dataPlaneOnly, "Only run data-plane checks, to determine if the data plane is healthy")
 	cmd.PersistentFlags().BoolVar(&options.wait, "wait", options.wait, "Retry and wait for some checks to succeed if they don't pass the first time")
 	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace to use for --proxy checks (default: all namespaces)")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: json, stream-json")
+	cmd.PersistentFlags().StringVar(&options.imageLockFile, "image-lock-file", options.imageLockFile, "Path of the image lock file that the control plane is installed with; with --pre, checks that the pinned images can be pulled and skips the version checks, which require internet access")
+	cmd.PersistentFlags().DurationVar(&options.crtExpiryWarningThreshold, "crt-expiry-warning-threshold", options.crtExpiryWarningThreshold, "Report the issuer certificate and the serving certificates of the webhooks and the tap API server as about to expire when they expire within this duration")
 
 	return cmd
 }
@@ -80,54 +126,171 @@ func configureAndRunChecks(options *checkOptions) {
 		checks = append(checks, healthcheck.LinkerdAPIChecks)
 	}
 
-	checks = append(checks, healthcheck.LinkerdVersionChecks)
+	// air-gapped clusters can't reach the version check endpoint
+	if options.imageLockFile == "" {
+		checks = append(checks, healthcheck.LinkerdVersionChecks)
+	}
 
 	hc := healthcheck.NewHealthChecker(checks, &healthcheck.HealthCheckOptions{
 		ControlPlaneNamespace:          controlPlaneNamespace,
 		DataPlaneNamespace:             options.namespace,
 		KubeConfig:                     kubeconfigPath,
+		KubeContext:                    kubeContext,
+		Impersonate:                    impersonate,
+		ImpersonateGroup:               impersonateGroup,
 		APIAddr:                        apiAddr,
 		VersionOverride:                options.versionOverride,
 		ShouldRetry:                    options.wait,
 		ShouldCheckKubeVersion:         true,
 		ShouldCheckControlPlaneVersion: !(options.preInstallOnly || options.dataPlaneOnly),
 		ShouldCheckDataPlaneVersion:    options.dataPlaneOnly,
+		CertExpiryWarningThreshold:     options.crtExpiryWarningThreshold,
+		PinnedImages:                   options.pinnedImages,
 	})
 
+	if options.output == streamJSONOutput {
+		success := runChecksStreamJSON(os.Stdout, hc)
+		if !success {
+			os.Exit(2)
+		}
+		return
+	}
+
+	if options.output == checkJSONOutput {
+		success := runChecksJSON(os.Stdout, hc)
+		if !success {
+			os.Exit(2)
+		}
+		return
+	}
+
 	success := runChecks(os.Stdout, hc)
 
 	fmt.Println("")
 
 	if !success {
-		fmt.Printf("Status check results are %s\n", failStatus)
+		fmt.Printf("Status check results are %s\n", output.Fail.Render())
 		os.Exit(2)
 	}
 
-	fmt.Printf("Status check results are %s\n", okStatus)
+	fmt.Printf("Status check results are %s\n", output.Ok.Render())
 }
 
 func runChecks(w io.Writer, hc *healthcheck.HealthChecker) bool {
 	prettyPrintResults := func(result *healthcheck.CheckResult) {
 		checkLabel := fmt.Sprintf("%s: %s", result.Category, result.Description)
 
-		filler := ""
-		lineBreak := "\n"
-		for i := 0; i < lineWidth-len(checkLabel)-len(okStatus)-len(lineBreak); i++ {
-			filler = filler + "."
-		}
-
 		if result.Retry {
-			fmt.Fprintf(w, "%s%s%s -- %s%s", checkLabel, filler, retryStatus, result.Err, lineBreak)
+			output.StatusLine(w, checkLabel, output.Retry, result.Err.Error())
 			return
 		}
 
 		if result.Err != nil {
-			fmt.Fprintf(w, "%s%s%s -- %s%s", checkLabel, filler, failStatus, result.Err, lineBreak)
+			output.StatusLine(w, checkLabel, output.Fail, result.Err.Error())
 			return
 		}
 
-		fmt.Fprintf(w, "%s%s%s%s", checkLabel, filler, okStatus, lineBreak)
+		output.StatusLine(w, checkLabel, output.Ok, "")
 	}
 
 	return hc.RunChecks(prettyPrintResults)
 }
+
+// checkEvent is a single line of "stream-json" output. One event of type
+// "check" is written for every check result, including retries, followed by
+// a single event of type "summary" once all checks have run.
+type checkEvent struct {
+	Type        string `json:"type"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+func runChecksStreamJSON(w io.Writer, hc *healthcheck.HealthChecker) bool {
+	encoder := json.NewEncoder(w)
+
+	streamResult := func(result *healthcheck.CheckResult) {
+		event := checkEvent{
+			Type:        "check",
+			Category:    result.Category,
+			Description: result.Description,
+			Status:      checkStatus(result),
+		}
+		if result.Err != nil {
+			event.Error = result.Err.Error()
+		}
+
+		encoder.Encode(event)
+	}
+
+	success := hc.RunChecks(streamResult)
+
+	summary := checkEvent{Type: "summary", Status: "ok"}
+	if !success {
+		summary.Status = "fail"
+	}
+	encoder.Encode(summary)
+
+	return success
+}
+
+// checkOutput is the output of "json", written once all checks have run. The
+// results are grouped by category, in the order of the checks, so that the
+// output of runs with the same results is the same. The results of retries
+// are replaced by the final result of their check.
+type checkOutput struct {
+	Success    bool                  `json:"success"`
+	Categories []checkCategoryOutput `json:"categories"`
+}
+
+type checkCategoryOutput struct {
+	Category string              `json:"category"`
+	Checks   []checkResultOutput `json:"checks"`
+}
+
+type checkResultOutput struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+func runChecksJSON(w io.Writer, hc *healthcheck.HealthChecker) bool {
+	results := healthcheck.NewCheckResults()
+	success := hc.RunChecks(results.Observe)
+
+	out := checkOutput{
+		Success:    success,
+		Categories: make([]checkCategoryOutput, 0),
+	}
+	for _, category := range results.Categories() {
+		categoryOut := checkCategoryOutput{Category: category.Category}
+		for _, result := range category.Results {
+			resultOut := checkResultOutput{
+				Description: result.Description,
+				Status:      checkStatus(result),
+			}
+			if result.Err != nil {
+				resultOut.Error = result.Err.Error()
+			}
+			categoryOut.Checks = append(categoryOut.Checks, resultOut)
+		}
+		out.Categories = append(out.Categories, categoryOut)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(out)
+
+	return success
+}
+
+func checkStatus(result *healthcheck.CheckResult) string {
+	if result.Retry {
+		return "retry"
+	}
+	if result.Err != nil {
+		return "fail"
+	}
+	return "ok"
+}
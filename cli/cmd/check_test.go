@@ -36,4 +36,57 @@ func TestCheckStatus(t *testing.T) {
 			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
 		}
 	})
+
+	t.Run("Streams results as JSON", func(t *testing.T) {
+		hc := healthcheck.NewHealthChecker(
+			[]healthcheck.Checks{},
+			&healthcheck.HealthCheckOptions{},
+		)
+		hc.Add("category", "check1", func() error {
+			return nil
+		})
+		hc.Add("category", "check2", func() error {
+			return fmt.Errorf("This should contain instructions for fail")
+		})
+
+		output := bytes.NewBufferString("")
+		runChecksStreamJSON(output, hc)
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/check_output_stream_json.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedContent := string(goldenFileBytes)
+
+		if expectedContent != output.String() {
+			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
+		}
+	})
+	t.Run("Writes the results grouped by category as JSON", func(t *testing.T) {
+		hc := healthcheck.NewHealthChecker(
+			[]healthcheck.Checks{},
+			&healthcheck.HealthCheckOptions{},
+		)
+		hc.Add("category", "check1", func() error {
+			return nil
+		})
+		hc.Add("category", "check2", func() error {
+			return fmt.Errorf("This should contain instructions for fail")
+		})
+
+		output := bytes.NewBufferString("")
+		runChecksJSON(output, hc)
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/check_output_json.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedContent := string(goldenFileBytes)
+
+		if expectedContent != output.String() {
+			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
+		}
+	})
 }
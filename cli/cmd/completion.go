@@ -4,10 +4,74 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
+// bashCompletionFunc completes the names of namespaces, and of the resources
+// of the type given to stat, tap, top and routes, by listing them with the
+// hidden "completion resources" command. The kubeconfig, context and namespace
+// flags of the command line are passed on, so that the names are listed from
+// the same cluster and namespace.
+const bashCompletionFunc = `
+__linkerd_flag_args()
+{
+    local i
+    for ((i = 1; i < ${#words[@]} - 1; i++)); do
+        case "${words[i]}" in
+            --kubeconfig|--context|--namespace|-n)
+                echo "${words[i]}=${words[i+1]}"
+                ;;
+            --kubeconfig=*|--context=*|--namespace=*)
+                echo "${words[i]}"
+                ;;
+        esac
+    done
+}
+
+__linkerd_complete_resources()
+{
+    local out
+    if out=$(linkerd completion resources "$1" $(__linkerd_flag_args) 2>/dev/null); then
+        COMPREPLY=( $( compgen -W "${out[*]}" -- "$cur" ) )
+    fi
+}
+
+__linkerd_get_namespaces()
+{
+    __linkerd_complete_resources namespace
+}
+
+__custom_func() {
+    case ${last_command} in
+        linkerd_stat | linkerd_tap | linkerd_top | linkerd_routes)
+            # names are completed after the type of the resource
+            if [[ ${#nouns[@]} -eq 1 ]]; then
+                __linkerd_complete_resources "${nouns[0]}"
+            fi
+            return
+            ;;
+        *)
+            ;;
+    esac
+}
+`
+
+// resourceTypeAliases are the short and plural names of the resource types
+// that stat, tap, top and routes accept along with their ValidArgs, which are
+// completed as resource types too.
+var resourceTypeAliases = []string{
+	"deploy", "deployments",
+	"ns", "namespaces",
+	"po", "pods",
+	"rc", "replicationcontrollers",
+	"au", "authorities",
+}
+
 func newCmdCompletion() *cobra.Command {
 	example := `  # bash <= 3.2
   source /dev/stdin <<< "$(linkerd completion bash)"
@@ -30,9 +94,13 @@ func newCmdCompletion() *cobra.Command {
   linkerd completion zsh > "${fpath[1]}/_linkerd"`
 
 	cmd := &cobra.Command{
-		Use:       "completion [bash|zsh]",
-		Short:     "Shell completion",
-		Long:      "Output completion code for the specified shell (bash or zsh).",
+		Use:   "completion [bash|zsh]",
+		Short: "Shell completion",
+		Long: `Output completion code for the specified shell (bash or zsh).
+
+The bash completion also completes the names of namespaces, and of the
+resources given to the stat, tap, top and routes commands, by listing them
+from the cluster.`,
 		Example:   example,
 		Args:      cobra.ExactArgs(1),
 		ValidArgs: []string{"bash", "zsh"},
@@ -47,9 +115,102 @@ func newCmdCompletion() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newCmdCompletionResources())
+
 	return cmd
 }
 
+func newCmdCompletionResources() *cobra.Command {
+	namespace := "default"
+
+	cmd := &cobra.Command{
+		Use:    "resources [flags] TYPE",
+		Short:  "List the names of the resources of a type, for shell completion",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+			if err != nil {
+				return err
+			}
+
+			names, err := resourceNames(clientset, args[0], namespace)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the resources")
+
+	return cmd
+}
+
+// markNamespaceFlagCompletion completes the values of a namespace flag of the
+// command with the names of the namespaces of the cluster, in bash.
+func markNamespaceFlagCompletion(cmd *cobra.Command, name string) {
+	cmd.PersistentFlags().SetAnnotation(name, cobra.BashCompCustom, []string{"__linkerd_get_namespaces"})
+}
+
+// resourceNames returns the sorted names of the resources of the type, which
+// may be any of the names of the types accepted by stat, in the namespace.
+// Authorities aren't Kubernetes resources, and have no names to complete.
+func resourceNames(clientset kubernetes.Interface, friendlyName, namespace string) ([]string, error) {
+	resourceType, err := k8s.CanonicalResourceNameFromFriendlyName(friendlyName)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	switch resourceType {
+	case k8s.Namespace:
+		list, err := clientset.CoreV1().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	case k8s.Deployment:
+		list, err := clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	case k8s.Pod:
+		list, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	case k8s.ReplicationController:
+		list, err := clientset.CoreV1().ReplicationControllers(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
 func getCompletion(sh string, parent *cobra.Command) (string, error) {
 	var err error
 	var buf bytes.Buffer
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+
+	appsV1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestCompletion(t *testing.T) {
@@ -27,6 +33,24 @@ func TestCompletion(t *testing.T) {
 		}
 	})
 
+	t.Run("Completes the names of namespaces and resources in bash", func(t *testing.T) {
+		bash, err := getCompletion("bash", RootCmd)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		for _, expected := range []string{
+			"__custom_func()",
+			"flags_with_completion+=(\"--namespace\")",
+			"flags_completion+=(\"__linkerd_get_namespaces\")",
+			"noun_aliases+=(\"deploy\")",
+		} {
+			if !strings.Contains(bash, expected) {
+				t.Fatalf("Expected the bash completion to contain [%s]", expected)
+			}
+		}
+	})
+
 	t.Run("Fails with invalid shell type", func(t *testing.T) {
 		out, err := getCompletion("foo", RootCmd)
 		if err == nil {
@@ -34,3 +58,39 @@ func TestCompletion(t *testing.T) {
 		}
 	})
 }
+
+func TestResourceNames(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "emojivoto"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&appsV1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "emojivoto"}},
+		&appsV1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "voting", Namespace: "emojivoto"}},
+		&appsV1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"}},
+	)
+
+	testCases := []struct {
+		resourceType  string
+		namespace     string
+		expectedNames []string
+	}{
+		{"ns", "default", []string{"default", "emojivoto"}},
+		{"deploy", "emojivoto", []string{"voting", "web"}},
+		{"deployments", "default", []string{"books"}},
+		{"pod", "emojivoto", []string{}},
+		{"authority", "emojivoto", []string{}},
+	}
+
+	for _, tc := range testCases {
+		names, err := resourceNames(clientset, tc.resourceType, tc.namespace)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names, tc.expectedNames) {
+			t.Fatalf("Expected names %v for %s in %s, got %v", tc.expectedNames, tc.resourceType, tc.namespace, names)
+		}
+	}
+
+	if _, err := resourceNames(clientset, "foo", "default"); err == nil {
+		t.Fatal("Expected an error for an unknown resource type, got none")
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// crashReport is the JSON document written locally when the CLI panics. It is
+// never sent anywhere unless the user uploads it with "linkerd diagnostics
+// upload".
+type crashReport struct {
+	Timestamp     time.Time         `json:"timestamp"`
+	Command       string            `json:"command"`
+	Args          []string          `json:"args"`
+	Flags         map[string]string `json:"flags"`
+	Error         string            `json:"error"`
+	Stack         string            `json:"stack"`
+	ClientVersion string            `json:"clientVersion"`
+	GoVersion     string            `json:"goVersion"`
+	Platform      string            `json:"platform"`
+}
+
+// RecoverPanic is deferred by the CLI's main function. If a command panics, it
+// writes a crash report to the system's temporary directory, prints its path
+// along with instructions for sharing it, and exits.
+func RecoverPanic() {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	cmd, _, err := RootCmd.Find(os.Args[1:])
+	if err != nil {
+		cmd = RootCmd
+	}
+
+	fmt.Fprintf(os.Stderr, "linkerd crashed unexpectedly: %v\n", recovered)
+
+	report := newCrashReport(cmd, recovered, debug.Stack())
+	path, err := writeCrashReport(os.TempDir(), report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write crash report: %s\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Fprintf(os.Stderr, "A crash report was written to %s\n", path)
+	fmt.Fprintf(os.Stderr, "No data has been sent. To share the report with the Linkerd maintainers, attach it to a GitHub issue, or run:\n")
+	fmt.Fprintf(os.Stderr, "  linkerd diagnostics upload %s --to <url>\n", path)
+	os.Exit(2)
+}
+
+// newCrashReport describes a panic in cmd. Only the flags that were set are
+// recorded, and the values of flags that look like they hold credentials are
+// redacted.
+func newCrashReport(cmd *cobra.Command, recovered interface{}, stack []byte) *crashReport {
+	flags := make(map[string]string)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if sensitiveName.MatchString(f.Name) {
+			value = redactedValue
+		}
+		flags[f.Name] = value
+	})
+
+	return &crashReport{
+		Timestamp:     time.Now().UTC(),
+		Command:       cmd.CommandPath(),
+		Args:          cmd.Flags().Args(),
+		Flags:         flags,
+		Error:         fmt.Sprintf("%v", recovered),
+		Stack:         string(stack),
+		ClientVersion: version.Version,
+		GoVersion:     runtime.Version(),
+		Platform:      fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+// writeCrashReport writes the report as a timestamped JSON file in dir, and
+// returns the file's path.
+func writeCrashReport(dir string, report *crashReport) (string, error) {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("linkerd-crash-%s.json", report.Timestamp.Format("20060102T150405Z")))
+	if err := ioutil.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestNewCrashReport(t *testing.T) {
+	var namespace, apiToken, output string
+	cmd := &cobra.Command{Use: "tap"}
+	cmd.Flags().StringVar(&namespace, "namespace", "default", "")
+	cmd.Flags().StringVar(&apiToken, "api-token", "", "")
+	cmd.Flags().StringVar(&output, "output", "", "")
+
+	if err := cmd.Flags().Parse([]string{"deploy/web", "--namespace", "emojivoto", "--api-token", "hunter2"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := newCrashReport(cmd, "index out of range", []byte("goroutine 1 [running]:"))
+
+	if report.Command != "tap" {
+		t.Fatalf("Expected command [tap], got [%s]", report.Command)
+	}
+	if !reflect.DeepEqual(report.Args, []string{"deploy/web"}) {
+		t.Fatalf("Expected args [deploy/web], got %v", report.Args)
+	}
+
+	expectedFlags := map[string]string{"namespace": "emojivoto", "api-token": redactedValue}
+	if !reflect.DeepEqual(report.Flags, expectedFlags) {
+		t.Fatalf("Expected flags %v, got %v", expectedFlags, report.Flags)
+	}
+
+	if report.Error != "index out of range" || report.Stack != "goroutine 1 [running]:" {
+		t.Fatalf("Unexpected error or stack: %+v", report)
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crash")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := newCrashReport(&cobra.Command{Use: "linkerd"}, "boom", nil)
+	path, err := writeCrashReport(dir, report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(path, dir+"/linkerd-crash-") {
+		t.Fatalf("Expected report to be written to %s, got %s", dir, path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var written crashReport
+	if err = json.Unmarshal(content, &written); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if written.Error != "boom" || written.ClientVersion == "" || written.GoVersion == "" {
+		t.Fatalf("Unexpected crash report: %+v", written)
+	}
+}
+
+func TestUploadDiagnostics(t *testing.T) {
+	t.Run("Uploads the file with a PUT request", func(t *testing.T) {
+		var method, body string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			content, _ := ioutil.ReadAll(req.Body)
+			method, body = req.Method, string(content)
+		}))
+		defer server.Close()
+
+		err := uploadDiagnostics(server.Client(), strings.NewReader("report"), server.URL+"/crash.json")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if method != http.MethodPut || body != "report" {
+			t.Fatalf("Expected a PUT request with the report, got %s [%s]", method, body)
+		}
+	})
+
+	t.Run("Returns an error when the upload is rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := uploadDiagnostics(server.Client(), strings.NewReader("report"), server.URL)
+		if err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+			t.Fatalf("Expected a 403 error, got %v", err)
+		}
+	})
+
+	t.Run("Rejects unsupported destinations", func(t *testing.T) {
+		err := uploadDiagnostics(http.DefaultClient, strings.NewReader("report"), "s3://bucket/crash.json")
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
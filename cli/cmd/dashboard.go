@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
@@ -19,9 +27,22 @@ const (
 
 	// showURL displays dashboard URLs without opening a browser.
 	showURL = "url"
+
+	// webPort is the port of the dashboard in the web pod, which serves
+	// Grafana as well.
+	webPort = 8084
 )
 
+// grafanaDashboards are the resource types with a Grafana dashboard, named
+// "linkerd-<type>".
+var grafanaDashboards = []string{
+	k8s.Deployment,
+	k8s.Pod,
+	k8s.ReplicationController,
+}
+
 type dashboardOptions struct {
+	namespace          string
 	dashboardProxyPort int
 	dashboardShow      string
 	wait               bool
@@ -29,6 +50,7 @@ type dashboardOptions struct {
 
 func newDashboardOptions() *dashboardOptions {
 	return &dashboardOptions{
+		namespace:          "default",
 		dashboardProxyPort: 0,
 		dashboardShow:      showLinkerd,
 		wait:               true,
@@ -39,8 +61,13 @@ func newCmdDashboard() *cobra.Command {
 	options := newDashboardOptions()
 
 	cmd := &cobra.Command{
-		Use:   "dashboard [flags]",
+		Use:   "dashboard [flags] [RESOURCE]",
 		Short: "Open the Linkerd dashboard in a web browser",
+		Long: `Open the Linkerd dashboard in a web browser.
+
+  The Grafana dashboard of a resource is opened with --show grafana, e.g.:
+
+  linkerd dashboard --show grafana -n emojivoto deploy/web`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if options.dashboardProxyPort < 0 {
 				return fmt.Errorf("port must be greater than or equal to zero, was %d", options.dashboardProxyPort)
@@ -51,43 +78,76 @@ func newCmdDashboard() *cobra.Command {
 					options.dashboardShow, showLinkerd, showGrafana, showURL)
 			}
 
-			kubernetesProxy, err := k8s.NewProxy(kubeconfigPath, options.dashboardProxyPort)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to initialize proxy: %s\n", err)
-				os.Exit(1)
+			// the dashboard proxies Grafana under the path of the Kubernetes
+			// API's service proxy, which Grafana's root_url is configured with
+			grafanaPath := fmt.Sprintf("/api/v1/namespaces/%s/services/grafana:http/proxy/", controlPlaneNamespace)
+			dashboardPath := ""
+			if len(args) > 0 {
+				if options.dashboardShow == showLinkerd {
+					return fmt.Errorf("a resource can only be given with --show %s or %s", showGrafana, showURL)
+				}
+				resource, err := util.BuildResource(options.namespace, args...)
+				if err != nil {
+					return err
+				}
+				dashboardPath, err = grafanaDashboardPath(resource)
+				if err != nil {
+					return err
+				}
+				grafanaPath += dashboardPath
 			}
 
-			url, err := kubernetesProxy.URLFor(controlPlaneNamespace, "/services/web:http/proxy/")
+			port := options.dashboardProxyPort
+			if port != 0 && !portAvailable(port) {
+				fmt.Fprintf(os.Stderr, "Port %d is not available, using a random port instead\n", port)
+				port = 0
+			}
+
+			// ensure we can connect to the public API before forwarding the port
+			validatedPublicAPIClient(options.wait)
+
+			portForward, err := k8s.NewControlPlanePortForward(kubeconfigPath, kubeContext, impersonate, impersonateGroup, controlPlaneNamespace, "web", port, webPort, ioutil.Discard, os.Stderr)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to generate URL for dashboard: %s\n", err)
+				fmt.Fprintf(os.Stderr, "Failed to initialize port-forward: %s\n", err)
 				os.Exit(1)
 			}
 
-			grafanaUrl, err := kubernetesProxy.URLFor(controlPlaneNamespace, "/services/grafana:http/proxy/")
+			go func() {
+				if err := portForward.Run(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error running port-forward: %s\n", err)
+					os.Exit(1)
+				}
+			}()
+			<-portForward.Ready()
+
+			webUrl := portForward.URLFor("/")
+			// the dashboards of an existing Grafana aren't proxied
+			externalGrafanaUrl, err := externalGrafanaURL(dashboardPath)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to generate URL for Grafana: %s\n", err)
 				os.Exit(1)
 			}
+			grafanaUrl := portForward.URLFor(grafanaPath)
+			if externalGrafanaUrl != nil {
+				grafanaUrl = externalGrafanaUrl.String()
+			}
 
-			// ensure we can connect to the public API before starting the proxy
-			validatedPublicAPIClient(options.wait)
-
-			fmt.Printf("Linkerd dashboard available at:\n%s\n", url.String())
-			fmt.Printf("Grafana dashboard available at:\n%s\n", grafanaUrl.String())
+			fmt.Printf("Linkerd dashboard available at:\n%s\n", webUrl)
+			fmt.Printf("Grafana dashboard available at:\n%s\n", grafanaUrl)
 
 			switch options.dashboardShow {
 			case showLinkerd:
 				fmt.Println("Opening Linkerd dashboard in the default browser")
 
-				err = browser.OpenURL(url.String())
+				err = browser.OpenURL(webUrl)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to open Linkerd URL %s in the default browser: %s", url, err)
+					fmt.Fprintf(os.Stderr, "Failed to open Linkerd URL %s in the default browser: %s", webUrl, err)
 					os.Exit(1)
 				}
 			case showGrafana:
 				fmt.Println("Opening Grafana dashboard in the default browser")
 
-				err = browser.OpenURL(grafanaUrl.String())
+				err = browser.OpenURL(grafanaUrl)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Failed to open Grafana URL %s in the default browser: %s", grafanaUrl, err)
 					os.Exit(1)
@@ -97,21 +157,63 @@ func newCmdDashboard() *cobra.Command {
 			}
 
 			// blocks until killed
-			err = kubernetesProxy.Run()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error running proxy: %s", err)
-				os.Exit(1)
-			}
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			<-signals
+			portForward.Stop()
 
 			return nil
 		},
 	}
 
-	cmd.Args = cobra.NoArgs
-	// This is identical to what `kubectl proxy --help` reports, `--port 0` indicates a random port.
-	cmd.PersistentFlags().IntVarP(&options.dashboardProxyPort, "port", "p", options.dashboardProxyPort, "The port on which to run the proxy (when set to 0, a random port will be used)")
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the resource whose Grafana dashboard is shown")
+	cmd.PersistentFlags().IntVarP(&options.dashboardProxyPort, "port", "p", options.dashboardProxyPort, "The local port forwarded to the dashboard (when set to 0, or if the port isn't available, a random port will be used)")
 	cmd.PersistentFlags().StringVar(&options.dashboardShow, "show", options.dashboardShow, "Open a dashboard in a browser or show URLs in the CLI (one of: linkerd, grafana, url)")
 	cmd.PersistentFlags().BoolVar(&options.wait, "wait", options.wait, "Wait for dashboard to become available if it's not available when the command is run")
 
 	return cmd
 }
+
+// grafanaDashboardPath returns the path of the Grafana dashboard of the
+// resource, relative to Grafana's root.
+func grafanaDashboardPath(resource pb.Resource) (string, error) {
+	supported := false
+	for _, t := range grafanaDashboards {
+		if resource.Type == t {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return "", fmt.Errorf("no Grafana dashboard for resource type [%s]", resource.Type)
+	}
+
+	query := url.Values{}
+	query.Set("var-namespace", resource.Namespace)
+	if resource.Name != "" {
+		query.Set("var-"+resource.Type, resource.Name)
+	}
+	return fmt.Sprintf("dashboard/db/linkerd-%s?%s", resource.Type, query.Encode()), nil
+}
+
+// externalGrafanaURL returns the URL of the dashboard at dashboardPath of the
+// existing Grafana server that the control plane was installed with, or nil if
+// the control plane runs its own Grafana.
+func externalGrafanaURL(dashboardPath string) (*url.URL, error) {
+	installConfig, err := getInstallConfig()
+	if err != nil || installConfig == nil || installConfig.Data[installGrafanaURLKey] == "" {
+		return nil, nil
+	}
+	return url.Parse(strings.TrimSuffix(installConfig.Data[installGrafanaURLKey], "/") + "/" + dashboardPath)
+}
+
+// portAvailable returns true if the proxy can listen on the local port.
+func portAvailable(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
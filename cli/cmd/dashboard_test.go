@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestGrafanaDashboardPath(t *testing.T) {
+	t.Run("Returns the path of the dashboard of a resource", func(t *testing.T) {
+		expectations := []struct {
+			resource pb.Resource
+			expected string
+		}{
+			{
+				pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+				"dashboard/db/linkerd-deployment?var-deployment=web&var-namespace=emojivoto",
+			},
+			{
+				pb.Resource{Namespace: "emojivoto", Type: "pod", Name: "web-5f86686c4d-58p7k"},
+				"dashboard/db/linkerd-pod?var-namespace=emojivoto&var-pod=web-5f86686c4d-58p7k",
+			},
+			{
+				pb.Resource{Namespace: "emojivoto", Type: "deployment"},
+				"dashboard/db/linkerd-deployment?var-namespace=emojivoto",
+			},
+		}
+
+		for _, exp := range expectations {
+			path, err := grafanaDashboardPath(exp.resource)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if path != exp.expected {
+				t.Fatalf("Expected path [%s], got [%s]", exp.expected, path)
+			}
+		}
+	})
+
+	t.Run("Rejects resources without a dashboard", func(t *testing.T) {
+		_, err := grafanaDashboardPath(pb.Resource{Type: "authority", Name: "web.emojivoto.svc.cluster.local"})
+		expected := "no Grafana dashboard for resource type [authority]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/cli/output"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	redactedValue       = "REDACTED"
+	diagnosticsManifest = "MANIFEST"
+	proxyMetricsPort    = "linkerd-metrics"
+)
+
+// sensitiveName matches the names of environment variables and flags whose
+// values are redacted from pod descriptions and crash reports.
+var sensitiveName = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|credential|key)`)
+
+type diagnosticsOptions struct {
+	outputFile string
+	logsSince  time.Duration
+}
+
+// diagnosticsBundle accumulates the files collected for a diagnostics
+// archive, along with any errors encountered while collecting them.
+type diagnosticsBundle struct {
+	files  map[string][]byte
+	errors map[string]error
+}
+
+func newDiagnosticsOptions() *diagnosticsOptions {
+	return &diagnosticsOptions{
+		outputFile: fmt.Sprintf("linkerd-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z")),
+		logsSince:  time.Hour,
+	}
+}
+
+func newDiagnosticsBundle() *diagnosticsBundle {
+	return &diagnosticsBundle{
+		files:  make(map[string][]byte),
+		errors: make(map[string]error),
+	}
+}
+
+func newCmdDiagnostics() *cobra.Command {
+	options := newDiagnosticsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "diagnostics [flags]",
+		Short: "Collect diagnostic information about the Linkerd installation",
+		Long: `Collect diagnostic information about the Linkerd installation.
+
+The diagnostics command gathers the output of "linkerd check", descriptions and
+logs of the control plane pods, a snapshot of each control plane proxy's
+metrics, and the control plane configuration into a single tar.gz archive that
+can be attached to a support request. Secret values and sensitive environment
+variables are redacted. The archive contains a MANIFEST file listing every
+collected file and anything that could not be collected.`,
+		Example: `  # Collect diagnostics into a timestamped archive in the current directory
+  linkerd diagnostics
+
+  # Collect diagnostics, including the last 10 minutes of logs, into a specific file
+  linkerd diagnostics --output /tmp/linkerd.tar.gz --logs-since 10m
+
+  # Replay the bootstrap sequence of the proxy in a pod
+  linkerd diagnostics proxy-bootstrap web-1 --namespace emojivoto
+
+  # Share an archive by uploading it to a URL
+  linkerd diagnostics upload linkerd-diagnostics.tar.gz --to https://example.com/uploads/linkerd.tar.gz`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.logsSince < 0 {
+				return fmt.Errorf("--logs-since must be greater than or equal to zero, was %s", options.logsSince)
+			}
+
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+			if err != nil {
+				return err
+			}
+
+			bundle := newDiagnosticsBundle()
+			bundle.collectChecks()
+			bundle.collectControlPlane(clientset, options)
+
+			f, err := os.Create(options.outputFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err = bundle.writeArchive(f); err != nil {
+				return err
+			}
+
+			fmt.Printf("Diagnostics written to %s (%d files, %d errors)\n", options.outputFile, len(bundle.files), len(bundle.errors))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.outputFile, "output", "o", options.outputFile, "Path of the tar.gz archive to write")
+	cmd.Flags().DurationVar(&options.logsSince, "logs-since", options.logsSince, "Only collect logs newer than a relative duration like 5s, 2m, or 3h (when set to 0, all logs are collected)")
+
+	cmd.AddCommand(newCmdProxyBootstrap())
+	cmd.AddCommand(newCmdDiagnosticsUpload())
+
+	return cmd
+}
+
+func (b *diagnosticsBundle) add(name string, content []byte, err error) {
+	if err != nil {
+		b.errors[name] = err
+		return
+	}
+	b.files[name] = content
+}
+
+func (b *diagnosticsBundle) addYAML(name string, obj interface{}) {
+	content, err := yaml.Marshal(obj)
+	b.add(name, content, err)
+}
+
+func (b *diagnosticsBundle) collectChecks() {
+	checks := []healthcheck.Checks{
+		healthcheck.KubernetesAPIChecks,
+		healthcheck.LinkerdAPIChecks,
+		healthcheck.LinkerdVersionChecks,
+	}
+
+	hc := healthcheck.NewHealthChecker(checks, &healthcheck.HealthCheckOptions{
+		ControlPlaneNamespace:          controlPlaneNamespace,
+		KubeConfig:                     kubeconfigPath,
+		KubeContext:                    kubeContext,
+		Impersonate:                    impersonate,
+		ImpersonateGroup:               impersonateGroup,
+		APIAddr:                        apiAddr,
+		ShouldRetry:                    false,
+		ShouldCheckKubeVersion:         true,
+		ShouldCheckControlPlaneVersion: true,
+	})
+
+	// the results are written to a file of the bundle, without color
+	defer output.SetColorEnabled(output.ColorEnabled())
+	output.SetColorEnabled(false)
+
+	var buf bytes.Buffer
+	if runChecks(&buf, hc) {
+		fmt.Fprintf(&buf, "\nStatus check results are %s\n", output.Ok)
+	} else {
+		fmt.Fprintf(&buf, "\nStatus check results are %s\n", output.Fail)
+	}
+	b.add("check.txt", buf.Bytes(), nil)
+}
+
+func (b *diagnosticsBundle) collectControlPlane(clientset kubernetes.Interface, options *diagnosticsOptions) {
+	pods, err := clientset.CoreV1().Pods(controlPlaneNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		b.add("pods", nil, err)
+	} else {
+		for i := range pods.Items {
+			pod := pods.Items[i]
+			redactPod(&pod)
+			b.addYAML(path.Join("pods", pod.Name+".yaml"), pod)
+			b.collectProxyMetrics(clientset, &pod)
+		}
+
+		for _, target := range logTargets(pods.Items, "") {
+			b.collectLogs(clientset, target, options)
+		}
+	}
+
+	configMaps, err := clientset.CoreV1().ConfigMaps(controlPlaneNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		b.add("config/configmaps", nil, err)
+	} else {
+		for _, cm := range configMaps.Items {
+			b.addYAML(path.Join("config", "configmaps", cm.Name+".yaml"), cm)
+		}
+	}
+
+	secrets, err := clientset.CoreV1().Secrets(controlPlaneNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		b.add("config/secrets", nil, err)
+	} else {
+		for i := range secrets.Items {
+			secret := secrets.Items[i]
+			redactSecret(&secret)
+			b.addYAML(path.Join("config", "secrets", secret.Name+".yaml"), secret)
+		}
+	}
+}
+
+func (b *diagnosticsBundle) collectLogs(clientset kubernetes.Interface, target podContainer, options *diagnosticsOptions) {
+	logOptions := &v1.PodLogOptions{Container: target.container}
+	if options.logsSince > 0 {
+		sinceSeconds := int64(options.logsSince.Seconds())
+		logOptions.SinceSeconds = &sinceSeconds
+	}
+
+	name := path.Join("logs", target.pod, target.container+".log")
+	stream, err := clientset.CoreV1().Pods(controlPlaneNamespace).GetLogs(target.pod, logOptions).Stream()
+	if err != nil {
+		b.add(name, nil, err)
+		return
+	}
+	defer stream.Close()
+
+	content, err := ioutil.ReadAll(stream)
+	b.add(name, content, err)
+}
+
+func (b *diagnosticsBundle) collectProxyMetrics(clientset kubernetes.Interface, pod *v1.Pod) {
+	if pod.Status.Phase != v1.PodRunning {
+		return
+	}
+
+	for _, c := range pod.Spec.Containers {
+		if c.Name == k8s.ProxyContainerName {
+			content, err := clientset.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, proxyMetricsPort, "/metrics", nil).DoRaw()
+			b.add(path.Join("metrics", pod.Name+".txt"), content, err)
+			return
+		}
+	}
+}
+
+// writeArchive writes every collected file, followed by the manifest, to w as
+// a gzipped tarball.
+func (b *diagnosticsBundle) writeArchive(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make(map[string][]byte, len(b.files)+1)
+	for name, content := range b.files {
+		files[name] = content
+	}
+	files[diagnosticsManifest] = b.manifest(names)
+	names = append(names, diagnosticsManifest)
+
+	for _, name := range names {
+		content := files[name]
+		hdr := &tar.Header{
+			Name:    path.Join("linkerd-diagnostics", name),
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (b *diagnosticsBundle) manifest(names []string) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Collected files:\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "  %s (%d bytes)\n", name, len(b.files[name]))
+	}
+
+	if len(b.errors) > 0 {
+		failed := make([]string, 0, len(b.errors))
+		for name := range b.errors {
+			failed = append(failed, name)
+		}
+		sort.Strings(failed)
+
+		fmt.Fprintf(&buf, "\nCould not collect:\n")
+		for _, name := range failed {
+			fmt.Fprintf(&buf, "  %s: %s\n", name, b.errors[name])
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// redactSecret replaces every value in the secret with a placeholder, so that
+// only the names of its keys are included in the archive. Its annotations are
+// dropped, as `kubectl apply` records the whole secret, values included, in
+// its last-applied-configuration annotation, and other tools may do the same.
+func redactSecret(secret *v1.Secret) {
+	for key := range secret.Data {
+		secret.Data[key] = []byte(redactedValue)
+	}
+	for key := range secret.StringData {
+		secret.StringData[key] = redactedValue
+	}
+	secret.Annotations = nil
+}
+
+// redactPod replaces the values of environment variables that look like they
+// hold credentials.
+func redactPod(pod *v1.Pod) {
+	redactContainers := func(containers []v1.Container) {
+		for i := range containers {
+			for j, env := range containers[i].Env {
+				if env.Value != "" && sensitiveName.MatchString(env.Name) {
+					containers[i].Env[j].Value = redactedValue
+				}
+			}
+		}
+	}
+
+	redactContainers(pod.Spec.InitContainers)
+	redactContainers(pod.Spec.Containers)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRedaction(t *testing.T) {
+	t.Run("Redacts all secret values", func(t *testing.T) {
+		secret := &v1.Secret{
+			Data:       map[string][]byte{"tls.key": []byte("private")},
+			StringData: map[string]string{"token": "hunter2"},
+		}
+
+		redactSecret(secret)
+
+		if string(secret.Data["tls.key"]) != redactedValue {
+			t.Fatalf("Expected data to be redacted, got [%s]", secret.Data["tls.key"])
+		}
+		if secret.StringData["token"] != redactedValue {
+			t.Fatalf("Expected string data to be redacted, got [%s]", secret.StringData["token"])
+		}
+	})
+
+	t.Run("Redacts the config recorded by kubectl apply", func(t *testing.T) {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "linkerd-identity-issuer",
+				Labels: map[string]string{"linkerd.io/control-plane-component": "identity"},
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Secret","stringData":{"key.pem":"hunter2"}}`,
+				},
+			},
+			Data: map[string][]byte{"key.pem": []byte("hunter2")},
+		}
+
+		redactSecret(secret)
+
+		content, err := yaml.Marshal(secret)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(string(content), "hunter2") {
+			t.Fatalf("Expected the secret to be redacted, got [%s]", content)
+		}
+		if secret.Labels["linkerd.io/control-plane-component"] != "identity" {
+			t.Fatalf("Expected the labels to be kept, got %v", secret.Labels)
+		}
+	})
+
+	t.Run("Redacts only sensitive environment variables", func(t *testing.T) {
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Env: []v1.EnvVar{
+							{Name: "LINKERD2_PROXY_LOG", Value: "warn"},
+							{Name: "GF_SECURITY_ADMIN_PASSWORD", Value: "admin"},
+							{Name: "API_TOKEN", Value: "abc123"},
+						},
+					},
+				},
+			},
+		}
+
+		redactPod(pod)
+
+		expected := []string{"warn", redactedValue, redactedValue}
+		for i, env := range pod.Spec.Containers[0].Env {
+			if env.Value != expected[i] {
+				t.Fatalf("Expected %s to be [%s], got [%s]", env.Name, expected[i], env.Value)
+			}
+		}
+	})
+}
+
+func TestWriteArchive(t *testing.T) {
+	bundle := newDiagnosticsBundle()
+	bundle.add("check.txt", []byte("all good\n"), nil)
+	bundle.add("logs/web/web.log", []byte("started\n"), nil)
+	bundle.add("metrics/web.txt", nil, errors.New("connection refused"))
+
+	var buf bytes.Buffer
+	if err := bundle.writeArchive(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	contents := make(map[string]string)
+	names := make([]string, 0)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		names = append(names, hdr.Name)
+		contents[hdr.Name] = string(content)
+	}
+
+	expectedNames := []string{
+		"linkerd-diagnostics/check.txt",
+		"linkerd-diagnostics/logs/web/web.log",
+		"linkerd-diagnostics/MANIFEST",
+	}
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Fatalf("Expected archive entries %v, got %v", expectedNames, names)
+	}
+
+	expectedManifest := `Collected files:
+  check.txt (9 bytes)
+  logs/web/web.log (8 bytes)
+
+Could not collect:
+  metrics/web.txt: connection refused
+`
+	if contents["linkerd-diagnostics/MANIFEST"] != expectedManifest {
+		t.Fatalf("Expected manifest:\n%s\ngot:\n%s", expectedManifest, contents["linkerd-diagnostics/MANIFEST"])
+	}
+}
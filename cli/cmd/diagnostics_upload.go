@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type diagnosticsUploadOptions struct {
+	to      string
+	timeout time.Duration
+}
+
+func newDiagnosticsUploadOptions() *diagnosticsUploadOptions {
+	return &diagnosticsUploadOptions{
+		to:      "",
+		timeout: 30 * time.Second,
+	}
+}
+
+func newCmdDiagnosticsUpload() *cobra.Command {
+	options := newDiagnosticsUploadOptions()
+
+	cmd := &cobra.Command{
+		Use:   "upload [flags] FILE",
+		Short: "Upload a crash report or diagnostics archive",
+		Long: `Upload a crash report or diagnostics archive.
+
+The CLI never sends crash reports or diagnostics on its own. The upload command
+sends the given file, as written by "linkerd diagnostics" or after a crash, to
+a URL of your choosing with an HTTP PUT request, such as a pre-signed object
+storage URL shared by whoever is helping you debug.`,
+		Example: `  # Upload a crash report
+  linkerd diagnostics upload /tmp/linkerd-crash-20180920T101500Z.json --to https://example.com/uploads/crash.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.to == "" {
+				return fmt.Errorf("--to is required")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			client := &http.Client{Timeout: options.timeout}
+			if err = uploadDiagnostics(client, f, options.to); err != nil {
+				return err
+			}
+
+			fmt.Printf("Uploaded %s to %s\n", args[0], options.to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.to, "to", options.to, "URL to upload the file to")
+	cmd.Flags().DurationVar(&options.timeout, "timeout", options.timeout, "Maximum time to wait for the upload to complete")
+
+	return cmd
+}
+
+// uploadDiagnostics sends content to the destination URL with a PUT request,
+// which is supported by most object stores.
+func uploadDiagnostics(client *http.Client, content io.Reader, destination string) error {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported upload destination scheme: %s", u.Scheme)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), content)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status uploading to %s: %s", u.Host, rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/spf13/cobra"
+)
+
+type endpointsOptions struct {
+	output string
+}
+
+// authorityEndpoints are the endpoints that the destination service resolves
+// an authority to.
+type authorityEndpoints struct {
+	Authority string                    `json:"authority"`
+	Exists    bool                      `json:"exists"`
+	Endpoints []*pb.DestinationEndpoint `json:"endpoints"`
+}
+
+func newEndpointsOptions() *endpointsOptions {
+	return &endpointsOptions{
+		output: "",
+	}
+}
+
+func newCmdEndpoints() *cobra.Command {
+	options := newEndpointsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "endpoints [flags] AUTHORITY [AUTHORITY...]",
+		Short: "Display the endpoints that the destination service resolves authorities to",
+		Long: `Display the endpoints that the destination service resolves authorities to.
+
+The endpoints command asks the destination service of the control plane what
+the proxies are told about each of the given authorities: the addresses of its
+endpoints, the pods behind them, their weights when the traffic is split
+across backends, the TLS identities that the proxies expect them to have, and
+whether they accept HTTP/2. Authorities that the destination service doesn't
+know are resolved by the proxies through DNS.`,
+		Example: `  # Display the endpoints of the web service in the emojivoto namespace
+  linkerd endpoints web.emojivoto.svc.cluster.local:80
+
+  # Display the endpoints of several authorities as JSON
+  linkerd endpoints web.emojivoto.svc.cluster.local:80 emoji-svc.emojivoto.svc.cluster.local:8080 -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch options.output {
+			case "", jsonOutput:
+			default:
+				return fmt.Errorf("output format \"%s\" not recognized", options.output)
+			}
+
+			resolved, err := requestEndpointsFromAPI(validatedPublicAPIClient(false), args)
+			if err != nil {
+				return err
+			}
+
+			if options.output == jsonOutput {
+				return renderEndpointsJSON(os.Stdout, resolved)
+			}
+			renderEndpoints(os.Stdout, resolved)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: json")
+
+	return cmd
+}
+
+func requestEndpointsFromAPI(client pb.ApiClient, authorities []string) ([]authorityEndpoints, error) {
+	resolved := make([]authorityEndpoints, 0, len(authorities))
+	for _, authority := range authorities {
+		rsp, err := client.ResolveDestination(context.Background(), &pb.ResolveDestinationRequest{Authority: authority})
+		if err != nil {
+			return nil, fmt.Errorf("ResolveDestination API error for %s: %v", authority, err)
+		}
+
+		endpoints := rsp.GetEndpoints()
+		if endpoints == nil {
+			endpoints = []*pb.DestinationEndpoint{}
+		}
+		resolved = append(resolved, authorityEndpoints{
+			Authority: authority,
+			Exists:    rsp.GetExists(),
+			Endpoints: endpoints,
+		})
+	}
+	return resolved, nil
+}
+
+func renderEndpoints(w io.Writer, resolved []authorityEndpoints) {
+	tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+	fmt.Fprintln(tw, "AUTHORITY\tADDRESS\tPOD\tWEIGHT\tH2\tIDENTITY")
+	for _, a := range resolved {
+		if !a.Exists {
+			fmt.Fprintf(tw, "%s\t(unknown, resolved through DNS)\t-\t-\t-\t-\n", a.Authority)
+			continue
+		}
+		if len(a.Endpoints) == 0 {
+			fmt.Fprintf(tw, "%s\t(no endpoints)\t-\t-\t-\t-\n", a.Authority)
+			continue
+		}
+		for _, e := range a.Endpoints {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%t\t%s\n", a.Authority, e.Address, orDash(e.Pod), e.Weight, e.H2, orDash(e.TlsIdentity))
+		}
+	}
+	tw.Flush()
+}
+
+func renderEndpointsJSON(w io.Writer, resolved []authorityEndpoints) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(resolved)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestEndpoints(t *testing.T) {
+	mockClient := &public.MockApiClient{
+		ResolveDestinationToReturn: &pb.ResolveDestinationResponse{
+			Exists:    true,
+			Addresses: []string{"10.1.0.7:80", "10.1.0.8:80"},
+			Endpoints: []*pb.DestinationEndpoint{
+				{
+					Address:     "10.1.0.7:80",
+					Pod:         "web-1",
+					Weight:      1,
+					TlsIdentity: "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local",
+					H2:          true,
+				},
+				{
+					Address: "10.1.0.8:80",
+					Weight:  1,
+				},
+			},
+		},
+	}
+
+	t.Run("Renders the endpoints of authorities", func(t *testing.T) {
+		resolved, err := requestEndpointsFromAPI(mockClient, []string{"web.emojivoto.svc.cluster.local:80"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := `AUTHORITY                            ADDRESS       POD     WEIGHT   H2      IDENTITY
+web.emojivoto.svc.cluster.local:80   10.1.0.7:80   web-1   1        true    web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local
+web.emojivoto.svc.cluster.local:80   10.1.0.8:80   -       1        false   -
+`
+
+		var buf bytes.Buffer
+		renderEndpoints(&buf, resolved)
+		diffCompare(t, buf.String(), expectedOutput)
+	})
+
+	t.Run("Renders authorities without endpoints", func(t *testing.T) {
+		resolved := []authorityEndpoints{
+			{Authority: "web.emojivoto.svc.cluster.local:80", Exists: true, Endpoints: []*pb.DestinationEndpoint{}},
+			{Authority: "example.com:80", Exists: false, Endpoints: []*pb.DestinationEndpoint{}},
+		}
+
+		expectedOutput := `AUTHORITY                            ADDRESS                           POD   WEIGHT   H2   IDENTITY
+web.emojivoto.svc.cluster.local:80   (no endpoints)                    -     -        -    -
+example.com:80                       (unknown, resolved through DNS)   -     -        -    -
+`
+
+		var buf bytes.Buffer
+		renderEndpoints(&buf, resolved)
+		diffCompare(t, buf.String(), expectedOutput)
+	})
+
+	t.Run("Renders the endpoints as JSON", func(t *testing.T) {
+		resolved, err := requestEndpointsFromAPI(mockClient, []string{"web.emojivoto.svc.cluster.local:80"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := `[
+  {
+    "authority": "web.emojivoto.svc.cluster.local:80",
+    "exists": true,
+    "endpoints": [
+      {
+        "address": "10.1.0.7:80",
+        "pod": "web-1",
+        "weight": 1,
+        "tls_identity": "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local",
+        "h2": true
+      },
+      {
+        "address": "10.1.0.8:80",
+        "weight": 1
+      }
+    ]
+  }
+]
+`
+
+		var buf bytes.Buffer
+		if err := renderEndpointsJSON(&buf, resolved); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, buf.String(), expectedOutput)
+	})
+
+	t.Run("Returns the errors of the API", func(t *testing.T) {
+		_, err := requestEndpointsFromAPI(&public.MockApiClient{ErrorToReturn: errors.New("unavailable")}, []string{"web.emojivoto.svc.cluster.local:80"})
+
+		expected := "ResolveDestination API error for web.emojivoto.svc.cluster.local:80: unavailable"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// getPodsPageSize is the number of pods requested from the public API at a
+// time, so that listing large clusters doesn't time out.
+const getPodsPageSize = 500
+
 type getOptions struct {
 	namespace     string
 	allNamespaces bool
@@ -27,18 +31,21 @@ func newCmdGet() *cobra.Command {
 	options := newGetOptions()
 
 	cmd := &cobra.Command{
-		Use:   "get [flags] pods",
+		Use:   "get [flags] (pods | services)",
 		Short: "Display one or many mesh resources",
 		Long: `Display one or many mesh resources.
 
-Only pod resources (aka pods, po) are supported.`,
+Only pod (aka pods, po) and service (aka services, svc) resources are supported.`,
 		Example: `  # get all pods
   linkerd get pods
 
   # get pods from namespace linkerd
-  linkerd get pods --namespace linkerd`,
+  linkerd get pods --namespace linkerd
+
+  # get all services across all namespaces
+  linkerd get services --all-namespaces`,
 		Args:      cobra.ExactArgs(1),
-		ValidArgs: []string{k8s.Pod},
+		ValidArgs: []string{k8s.Pod, k8s.Service},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return errors.New("please specify a resource type")
@@ -51,47 +58,78 @@ Only pod resources (aka pods, po) are supported.`,
 			friendlyName := args[0]
 			resourceType, err := k8s.CanonicalResourceNameFromFriendlyName(friendlyName)
 
-			if err != nil || resourceType != k8s.Pod {
-				return fmt.Errorf("invalid resource type %s, valid types: %s", friendlyName, k8s.Pod)
+			var names []string
+			switch {
+			case err == nil && resourceType == k8s.Pod:
+				names, err = getPods(validatedPublicAPIClient(false), options)
+			case err == nil && resourceType == k8s.Service:
+				names, err = getServices(validatedPublicAPIClient(false), options)
+			default:
+				return fmt.Errorf("invalid resource type %s, valid types: %s, %s", friendlyName, k8s.Pod, k8s.Service)
 			}
-
-			podNames, err := getPods(validatedPublicAPIClient(false), options)
 			if err != nil {
 				return err
 			}
 
-			if len(podNames) == 0 {
+			if len(names) == 0 {
 				fmt.Fprintln(os.Stderr, "No resources found.")
 				os.Exit(0)
 			}
 
-			for _, podName := range podNames {
-				fmt.Println(podName)
+			for _, name := range names {
+				fmt.Println(name)
 			}
 
 			return nil
 		},
 	}
 
-	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of pods")
-	cmd.PersistentFlags().BoolVar(&options.allNamespaces, "all-namespaces", options.allNamespaces, "If present, returns pods across all namespaces, ignoring the \"--namespace\" flag")
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the resources")
+	cmd.PersistentFlags().BoolVar(&options.allNamespaces, "all-namespaces", options.allNamespaces, "If present, returns resources across all namespaces, ignoring the \"--namespace\" flag")
 	return cmd
 }
 
 func getPods(apiClient pb.ApiClient, options *getOptions) ([]string, error) {
-	req := &pb.ListPodsRequest{}
+	req := &pb.ListPodsRequest{
+		Limit:         getPodsPageSize,
+		ExcludeStatus: true,
+	}
+	if !options.allNamespaces {
+		req.Namespace = options.namespace
+	}
+
+	names := make([]string, 0)
+	for {
+		resp, err := apiClient.ListPods(context.Background(), req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pod := range resp.GetPods() {
+			names = append(names, pod.Name)
+		}
+
+		if resp.GetContinueToken() == "" {
+			return names, nil
+		}
+		req.ContinueToken = resp.GetContinueToken()
+	}
+}
+
+func getServices(apiClient pb.ApiClient, options *getOptions) ([]string, error) {
+	req := &pb.ListServicesRequest{}
 	if !options.allNamespaces {
 		req.Namespace = options.namespace
 	}
 
-	resp, err := apiClient.ListPods(context.Background(), req)
+	resp, err := apiClient.ListServices(context.Background(), req)
 	if err != nil {
 		return nil, err
 	}
 
 	names := make([]string, 0)
-	for _, pod := range resp.GetPods() {
-		names = append(names, pod.Name)
+	for _, svc := range resp.GetServices() {
+		names = append(names, svc.Namespace+"/"+svc.Name)
 	}
 
 	return names, nil
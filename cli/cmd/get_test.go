@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
@@ -68,3 +69,39 @@ func TestGetPods(t *testing.T) {
 		}
 	})
 }
+
+func TestGetServices(t *testing.T) {
+	t.Run("Returns names of existing services if everything went ok", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		mockClient.ListServicesResponseToReturn = &pb.ListServicesResponse{
+			Services: []*pb.Service{
+				{Name: "svc-a", Namespace: "default"},
+				{Name: "svc-b", Namespace: "default"},
+			},
+		}
+
+		expectedNames := []string{
+			"default/svc-a",
+			"default/svc-b",
+		}
+
+		actualNames, err := getServices(mockClient, newGetOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(expectedNames, actualNames) {
+			t.Fatalf("Expected %v, got %v", expectedNames, actualNames)
+		}
+	})
+
+	t.Run("Returns error if cant find services in API", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		mockClient.ErrorToReturn = errors.New("expected")
+
+		_, err := getServices(mockClient, newGetOptions())
+		if err == nil {
+			t.Fatalf("Expecting error, got noting")
+		}
+	})
+}
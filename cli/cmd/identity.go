@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	proxyIdentityLocalNameEnvVar = "LINKERD2_PROXY_IDENTITY_LOCAL_NAME"
+	proxyPublicListenerEnvVar    = "LINKERD2_PROXY_PUBLIC_LISTENER"
+
+	// defaultProxyInboundPort is the port of the proxy's public listener if
+	// it isn't configured.
+	defaultProxyInboundPort = 4143
+
+	// proxyHandshakeTimeout bounds the port-forward to a pod's proxy and the
+	// TLS handshake with it.
+	proxyHandshakeTimeout = 10 * time.Second
+)
+
+type identityOptions struct {
+	namespace string
+}
+
+// podProxy is the proxy of a pod, along with the identity it's configured
+// with and the port of its public listener, which serves its certificate.
+type podProxy struct {
+	pod      *v1.Pod
+	identity string
+	port     int
+}
+
+// podCertificate is the certificate served by the proxy of a pod, along with
+// the identity the proxy is configured with.
+type podCertificate struct {
+	pod         *v1.Pod
+	identity    string
+	certificate *x509.Certificate
+}
+
+func newIdentityOptions() *identityOptions {
+	return &identityOptions{
+		namespace: "default",
+	}
+}
+
+func newCmdIdentity() *cobra.Command {
+	options := newIdentityOptions()
+
+	cmd := &cobra.Command{
+		Use:   "identity [flags] POD [POD...]",
+		Short: "Display the certificate of a pod's proxy",
+		Long: `Display the certificate of a pod's proxy.
+
+The identity command prints the subject, subject alternative names, issuer and
+validity period of the certificate that the proxy of each of the given pods
+serves, and whether it's valid for the proxy's identity under the current
+trust anchors of the control plane.`,
+		Example: `  # Display the certificate of the proxy in the web-1 pod
+  linkerd identity web-1 --namespace emojivoto`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+			if err != nil {
+				return err
+			}
+
+			trustAnchors, err := clientset.CoreV1().ConfigMaps(controlPlaneNamespace).Get(k8s.TLSTrustAnchorConfigMapName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			certificates := make([]*podCertificate, 0)
+			for _, name := range args {
+				proxy, err := getPodProxy(clientset, options.namespace, name)
+				if err != nil {
+					return err
+				}
+				certificate, err := fetchProxyCertificate(proxy)
+				if err != nil {
+					return err
+				}
+				certificates = append(certificates, &podCertificate{
+					pod:         proxy.pod,
+					identity:    proxy.identity,
+					certificate: certificate,
+				})
+			}
+
+			renderPodCertificates(os.Stdout, certificates, []byte(trustAnchors.Data[k8s.TLSTrustAnchorFileName]), time.Now())
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the pods")
+
+	return cmd
+}
+
+// getPodProxy returns the proxy of the pod, and the identity and port under
+// which it serves its certificate.
+func getPodProxy(clientset kubernetes.Interface, namespace, name string) (*podProxy, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var proxy *v1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == k8s.ProxyContainerName {
+			proxy = &pod.Spec.Containers[i]
+		}
+	}
+	if proxy == nil {
+		return nil, fmt.Errorf("pod %s/%s does not have a %s container", namespace, name, k8s.ProxyContainerName)
+	}
+
+	identity := proxyEnv(proxy, proxyPodIdentityEnvVar)
+	if pod.Annotations[k8s.IdentityModeAnnotation] == k8s.IdentityModeServiceAccount {
+		identity = proxyEnv(proxy, proxyIdentityLocalNameEnvVar)
+	}
+	if identity == "" {
+		return nil, fmt.Errorf("TLS is disabled for the proxy of pod %s/%s", namespace, name)
+	}
+
+	port := defaultProxyInboundPort
+	if listener := proxyEnv(proxy, proxyPublicListenerEnvVar); listener != "" {
+		addr, err := url.Parse(listener)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", proxyPublicListenerEnvVar, err)
+		}
+		port, err = strconv.Atoi(addr.Port())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", proxyPublicListenerEnvVar, listener)
+		}
+	}
+
+	return &podProxy{
+		pod:      pod,
+		identity: identity,
+		port:     port,
+	}, nil
+}
+
+// fetchProxyCertificate returns the certificate that the proxy serves, which
+// it presents in a TLS handshake over a port-forward to its public listener.
+func fetchProxyCertificate(proxy *podProxy) (*x509.Certificate, error) {
+	portForward, err := k8s.NewPodPortForward(kubeconfigPath, kubeContext, impersonate, impersonateGroup, proxy.pod.Namespace, proxy.pod.Name, 0, proxy.port, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+	defer portForward.Stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- portForward.Run()
+	}()
+
+	select {
+	case <-portForward.Ready():
+	case err := <-errCh:
+		return nil, fmt.Errorf("failed to port-forward to pod %s/%s: %s", proxy.pod.Namespace, proxy.pod.Name, err)
+	case <-time.After(proxyHandshakeTimeout):
+		return nil, fmt.Errorf("timed out port-forwarding to pod %s/%s", proxy.pod.Namespace, proxy.pod.Name)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", portForward.LocalPort())
+	certificate, err := getPeerCertificate(addr, proxy.identity, proxyHandshakeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the certificate of pod %s/%s: %s", proxy.pod.Namespace, proxy.pod.Name, err)
+	}
+	return certificate, nil
+}
+
+// getPeerCertificate returns the certificate that the TLS server at addr
+// presents for the identity. The certificate isn't verified in the handshake,
+// so that untrusted or expired certificates are displayed rather than
+// rejected; renderPodCertificates reports whether it's valid.
+func getPeerCertificate(addr, identity string, timeout time.Duration) (*x509.Certificate, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, &tls.Config{
+		ServerName:         identity,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificate was presented")
+	}
+	return certificates[0], nil
+}
+
+func renderPodCertificates(w io.Writer, certificates []*podCertificate, trustAnchorsPEM []byte, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+
+	for i, c := range certificates {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+
+		crt := c.certificate
+		status := "valid"
+		if _, err := verifyIdentity(crt.Raw, trustAnchorsPEM, c.identity, now); err != nil {
+			status = fmt.Sprintf("invalid: %s", err)
+		}
+
+		fmt.Fprintf(tw, "Pod:\t%s/%s\n", c.pod.Namespace, c.pod.Name)
+		fmt.Fprintf(tw, "Identity:\t%s\n", c.identity)
+		fmt.Fprintf(tw, "Subject:\t%s\n", crt.Subject.CommonName)
+		fmt.Fprintf(tw, "SANs:\t%s\n", strings.Join(crt.DNSNames, ", "))
+		fmt.Fprintf(tw, "Issuer:\t%s\n", crt.Issuer.CommonName)
+		fmt.Fprintf(tw, "Not Before:\t%s\n", crt.NotBefore.UTC().Format(time.RFC3339))
+		fmt.Fprintf(tw, "Not After:\t%s (%s)\n", crt.NotAfter.UTC().Format(time.RFC3339), expiresIn(crt.NotAfter, now))
+		fmt.Fprintf(tw, "Status:\t%s\n", status)
+	}
+
+	tw.Flush()
+}
+
+// expiresIn describes how long until the certificate expires, or how long ago
+// it expired.
+func expiresIn(notAfter, now time.Time) string {
+	remaining := notAfter.Sub(now).Round(time.Minute)
+	if remaining < 0 {
+		return fmt.Sprintf("expired %s ago", -remaining)
+	}
+	return fmt.Sprintf("expires in %s", remaining)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/ca"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodCertificates(t *testing.T) {
+	identity := "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"
+
+	issuer, err := ca.NewCA()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	crt, err := issuer.IssueEndEntityCertificate(identity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(crt.PrivateKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{crt.Certificate}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "emojivoto"}}
+
+	t.Run("Displays the certificate served by the proxy", func(t *testing.T) {
+		certificate, err := getPeerCertificate(listener.Addr().String(), identity, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output := bytes.NewBufferString("")
+		renderPodCertificates(output, []*podCertificate{{pod, identity, certificate}}, []byte(issuer.TrustAnchorPEM()), time.Now())
+
+		expectedLines := []string{
+			"Pod:        emojivoto/web-1",
+			"Identity:   " + identity,
+			"SANs:       " + identity,
+			"Issuer:     Cluster-local Managed Pod CA",
+			"Status:     valid",
+		}
+		for _, line := range expectedLines {
+			if !strings.Contains(output.String(), line) {
+				t.Fatalf("Expected output to contain [%s], got:\n%s", line, output)
+			}
+		}
+	})
+
+	t.Run("Reports certificates that aren't trusted", func(t *testing.T) {
+		otherIssuer, err := ca.NewCA()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		certificate, err := getPeerCertificate(listener.Addr().String(), identity, time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output := bytes.NewBufferString("")
+		renderPodCertificates(output, []*podCertificate{{pod, identity, certificate}}, []byte(otherIssuer.TrustAnchorPEM()), time.Now())
+
+		if !strings.Contains(output.String(), "Status:     invalid: ") {
+			t.Fatalf("Expected the certificate to be reported as invalid, got:\n%s", output)
+		}
+	})
+}
+
+func TestGetPodProxy(t *testing.T) {
+	proxyPod := func(name string, annotations map[string]string, env ...v1.EnvVar) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "emojivoto", Annotations: annotations},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "web"},
+					{Name: "linkerd-proxy", Env: env},
+				},
+			},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(
+		proxyPod("web-1", nil,
+			v1.EnvVar{Name: "LINKERD2_PROXY_TLS_POD_IDENTITY", Value: "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"},
+		),
+		proxyPod("web-2", map[string]string{"linkerd.io/identity-mode": "service-account"},
+			v1.EnvVar{Name: "LINKERD2_PROXY_PUBLIC_LISTENER", Value: "tcp://0.0.0.0:5143"},
+			v1.EnvVar{Name: "LINKERD2_PROXY_IDENTITY_LOCAL_NAME", Value: "web.emojivoto.serviceaccount.identity.linkerd.cluster.local"},
+		),
+		proxyPod("web-3", nil),
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-4", Namespace: "emojivoto"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+		},
+	)
+
+	testCases := []struct {
+		pod      string
+		identity string
+		port     int
+	}{
+		{"web-1", "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local", 4143},
+		{"web-2", "web.emojivoto.serviceaccount.identity.linkerd.cluster.local", 5143},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Returns the identity and port of the proxy of %s", tc.pod), func(t *testing.T) {
+			proxy, err := getPodProxy(clientset, "emojivoto", tc.pod)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if proxy.identity != tc.identity {
+				t.Fatalf("Expected identity [%s], got [%s]", tc.identity, proxy.identity)
+			}
+			if proxy.port != tc.port {
+				t.Fatalf("Expected port %d, got %d", tc.port, proxy.port)
+			}
+		})
+	}
+
+	t.Run("Fails for proxies without TLS", func(t *testing.T) {
+		if _, err := getPodProxy(clientset, "emojivoto", "web-3"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Fails for pods without a proxy", func(t *testing.T) {
+		if _, err := getPodProxy(clientset, "emojivoto", "web-4"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestExpiresIn(t *testing.T) {
+	now := time.Date(2018, 9, 20, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		notAfter time.Time
+		expected string
+	}{
+		{now.Add(36 * time.Hour), "expires in 36h0m0s"},
+		{now.Add(-90 * time.Minute), "expired 1h30m0s ago"},
+	}
+
+	for _, tc := range testCases {
+		if actual := expiresIn(tc.notAfter, now); actual != tc.expected {
+			t.Fatalf("Expected [%s], got [%s]", tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// imageDigest matches the digests that images are pinned by.
+var imageDigest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// imageLock is the format of the image lock file, which pins the images that
+// Linkerd is installed and injected with by their digests, e.g.:
+//
+//	images:
+//	  registry.example.com/linkerd/proxy:stable-2.1.0: sha256:...
+//
+// The images are the ones that are rendered without the lock file, i.e. with
+// the registry and the version that Linkerd is installed with, so that the
+// lock file of an air-gapped install lists the images of its mirror.
+type imageLock struct {
+	Images map[string]string `json:"images"`
+}
+
+// readImageLockFile returns the digests of the images pinned by an image
+// lock file, by image.
+func readImageLockFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock imageLock
+	if err := yaml.Unmarshal(b, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+	if len(lock.Images) == 0 {
+		return nil, fmt.Errorf("%s doesn't pin any image", path)
+	}
+
+	for _, image := range sortedImages(lock.Images) {
+		if strings.Contains(image, "@") {
+			return nil, fmt.Errorf("%s pins the %s image, which is already pinned by a digest", path, image)
+		}
+		if !imageDigest.MatchString(lock.Images[image]) {
+			return nil, fmt.Errorf("%s pins the %s image to an invalid digest: %s", path, image, lock.Images[image])
+		}
+	}
+	return lock.Images, nil
+}
+
+// pinnedImages returns the images of an image lock file, pinned by their
+// digests, in a stable order.
+func pinnedImages(digests map[string]string) []string {
+	images := sortedImages(digests)
+	for i, image := range images {
+		images[i] = image + "@" + digests[image]
+	}
+	return images
+}
+
+func sortedImages(digests map[string]string) []string {
+	images := make([]string, 0, len(digests))
+	for image := range digests {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// readImageLock reads the digests that the image lock file, if any, pins the
+// images by.
+func (options *proxyConfigOptions) readImageLock() error {
+	if options.imageLockFile == "" {
+		return nil
+	}
+
+	digests, err := readImageLockFile(options.imageLockFile)
+	if err != nil {
+		return err
+	}
+	options.imageDigests = digests
+	return nil
+}
+
+// pinnedImage returns the image pinned by the digest of the image lock file,
+// if any, so that the image that was locked is pulled even if its tag is
+// moved to another image.
+func (options *proxyConfigOptions) pinnedImage(image string) string {
+	if digest, ok := options.imageDigests[image]; ok {
+		return image + "@" + digest
+	}
+	return image
+}
+
+// proxyImages returns the images of the proxy and, unless the CNI plugin
+// configures the pods' iptables, of the init container.
+func (options *proxyConfigOptions) proxyImages() []string {
+	if options.noInitContainer {
+		return []string{options.taggedProxyImage()}
+	}
+	return []string{options.taggedProxyImage(), options.taggedProxyInitImage()}
+}
+
+// validateImagesPinned returns an error if an image lock file is given and
+// doesn't pin all the images, which would otherwise be pulled by their tags.
+func (options *proxyConfigOptions) validateImagesPinned(images ...string) error {
+	if options.imageLockFile == "" {
+		return nil
+	}
+
+	unpinned := []string{}
+	for _, image := range images {
+		if !strings.Contains(image, "@") {
+			unpinned = append(unpinned, image)
+		}
+	}
+	if len(unpinned) > 0 {
+		return fmt.Errorf("%s doesn't pin the images: %s", options.imageLockFile, strings.Join(unpinned, ", "))
+	}
+	return nil
+}
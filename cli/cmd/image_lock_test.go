@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const testImageDigest = "sha256:a0f9e1fdd2d0a0a1e0e8d7b6c8f2d4e3a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1"
+
+func TestReadImageLockFile(t *testing.T) {
+	writeLock := func(t *testing.T, content string) string {
+		file, err := ioutil.TempFile("", "images.lock")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		file.WriteString(content)
+		file.Close()
+		return file.Name()
+	}
+
+	t.Run("Reads the digests of the images", func(t *testing.T) {
+		path := writeLock(t, `images:
+  registry.example.com/linkerd/proxy:stable-2.1.0: `+testImageDigest+`
+  registry.example.com/linkerd/controller:stable-2.1.0: `+testImageDigest+`
+`)
+		defer os.Remove(path)
+
+		digests, err := readImageLockFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{
+			"registry.example.com/linkerd/controller:stable-2.1.0@" + testImageDigest,
+			"registry.example.com/linkerd/proxy:stable-2.1.0@" + testImageDigest,
+		}
+		if images := pinnedImages(digests); !reflect.DeepEqual(images, expected) {
+			t.Fatalf("Expected pinned images %v, got %v", expected, images)
+		}
+	})
+
+	t.Run("Rejects invalid image lock files", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			content string
+		}{
+			{"no images", "images: {}\n"},
+			{"invalid digest", "images:\n  registry.example.com/linkerd/proxy:stable-2.1.0: sha256:abc\n"},
+			{"image already pinned", "images:\n  registry.example.com/linkerd/proxy@" + testImageDigest + ": " + testImageDigest + "\n"},
+			{"invalid YAML", "images: [\n"},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				path := writeLock(t, tc.content)
+				defer os.Remove(path)
+
+				if _, err := readImageLockFile(path); err == nil {
+					t.Fatal("Expected an error, got none")
+				}
+			})
+		}
+	})
+}
@@ -3,21 +3,30 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/cli/output"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	appsV1 "k8s.io/api/apps/v1"
 	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	k8sMeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -32,12 +41,17 @@ const (
 	ControlPlanePodName = "controller"
 	// The name of the variable used to pass the pod's namespace.
 	PodNamespaceEnvVarName = "LINKERD2_PROXY_POD_NAMESPACE"
+	// The name of the variable used to pass the pod's ServiceAccount.
+	PodServiceAccountEnvVarName = "LINKERD2_PROXY_POD_SERVICE_ACCOUNT"
 
 	// for inject reports
 	hostNetworkDesc = "hostNetwork: pods do not use host networking"
 	sidecarDesc     = "sidecar: pods do not have a proxy or initContainer already injected"
 	unsupportedDesc = "supported: at least one resource injected"
 	udpDesc         = "udp: pod specs do not include UDP ports"
+
+	yamlOutput      = "yaml"
+	jsonPatchOutput = "json-patch"
 )
 
 type injectOptions struct {
@@ -45,6 +59,11 @@ type injectOptions struct {
 	outboundPort        uint
 	ignoreInboundPorts  []uint
 	ignoreOutboundPorts []uint
+	readinessGate       bool
+	failIfNoneInjected  bool
+	controlPlaneAddress string
+	output              string
+	ignoreCluster       bool
 	*proxyConfigOptions
 }
 
@@ -54,6 +73,38 @@ type injectReport struct {
 	sidecar             bool
 	udp                 bool // true if any port in any container has `protocol: UDP`
 	unsupportedResource bool
+	injectDisabled      bool
+	unsupportedArch     string // the architecture that pods are scheduled on, if the proxy isn't published for it
+}
+
+// injected returns true if the proxy was injected into the resource.
+func (r injectReport) injected() bool {
+	return !r.hostNetwork && !r.sidecar && !r.unsupportedResource && !r.injectDisabled && r.unsupportedArch == ""
+}
+
+// skipReason describes why the proxy wasn't injected into the resource.
+func (r injectReport) skipReason() string {
+	switch {
+	case r.injectDisabled:
+		return fmt.Sprintf("injection disabled by the %s annotation", k8s.ProxyInjectAnnotation)
+	case r.hostNetwork:
+		return "pods use host networking"
+	case r.sidecar:
+		return "pods already have a proxy, an init container or a known sidecar"
+	case r.unsupportedResource:
+		return "unsupported resource kind"
+	case r.unsupportedArch != "":
+		return fmt.Sprintf("pods are scheduled on the %s architecture, which the proxy images aren't published for", r.unsupportedArch)
+	}
+	return ""
+}
+
+// patchOperation is a JSON patch operation, as output by
+// `linkerd inject --output json-patch`.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
 }
 
 // objMeta provides a generic struct to parse the names of Kubernetes objects
@@ -67,10 +118,34 @@ func newInjectOptions() *injectOptions {
 		outboundPort:        4140,
 		ignoreInboundPorts:  nil,
 		ignoreOutboundPorts: nil,
+		readinessGate:       false,
+		failIfNoneInjected:  false,
+		controlPlaneAddress: "",
+		output:              yamlOutput,
+		ignoreCluster:       false,
 		proxyConfigOptions:  newProxyConfigOptions(),
 	}
 }
 
+func (options *injectOptions) validate() error {
+	if options.output != yamlOutput && options.output != jsonPatchOutput {
+		return fmt.Errorf("--output must be one of: %s, %s", yamlOutput, jsonPatchOutput)
+	}
+	if options.controlPlaneAddress != "" {
+		if _, _, err := net.SplitHostPort(options.controlPlaneAddress); err != nil {
+			return fmt.Errorf("--control-plane-address must be of the form host:port: %s", err)
+		}
+	}
+	for _, ports := range [][]uint{options.ignoreInboundPorts, options.ignoreOutboundPorts} {
+		for _, port := range ports {
+			if port == 0 || port > 65535 {
+				return fmt.Errorf("%d is not a valid port to skip", port)
+			}
+		}
+	}
+	return options.proxyConfigOptions.validate()
+}
+
 func newCmdInject() *cobra.Command {
 	options := newInjectOptions()
 
@@ -81,8 +156,23 @@ func newCmdInject() *cobra.Command {
 
 You can use a config file from stdin by using the '-' argument
 with 'linkerd inject'. e.g. curl http://url.to/yml | linkerd inject -
-Also works with a folder containing resource files and other
+Also works with a URL, e.g. linkerd inject http://url.to/yml, and
+with a folder containing resource files and other
 sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
+
+Resource files may contain multiple YAML documents and List objects,
+whose items are injected in order. Documents that aren't workloads are
+output unmodified.
+
+With '--output json-patch', a JSON patch adding the proxy is output for
+each injected document instead, one per line. This leaves the formatting
+of the original config untouched, e.g.
+kubectl patch -f web.yml --type json -p "$(linkerd inject -o json-patch web.yml)"
+
+The proxy configuration that the control plane was installed with, such as
+the version, registry, TLS or trust domain, is read from the cluster and used
+for the flags that aren't given on the command line. Use '--ignore-cluster' to
+inject with the defaults of the CLI without connecting to the cluster.
 	`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
@@ -90,9 +180,21 @@ sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
 				return fmt.Errorf("please specify a kubernetes resource file")
 			}
 
+			if !options.ignoreCluster {
+				if err := setFlagsFromCluster(cmd.PersistentFlags()); err != nil {
+					return err
+				}
+			}
+
 			if err := options.validate(); err != nil {
 				return err
 			}
+			if err := options.readImageLock(); err != nil {
+				return err
+			}
+			if err := options.validateImagesPinned(options.proxyImages()...); err != nil {
+				return err
+			}
 
 			in, err := read(args[0])
 			if err != nil {
@@ -108,14 +210,77 @@ sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
 	addProxyConfigFlags(cmd, options.proxyConfigOptions)
 	cmd.PersistentFlags().UintVar(&options.inboundPort, "inbound-port", options.inboundPort, "Proxy port to use for inbound traffic")
 	cmd.PersistentFlags().UintVar(&options.outboundPort, "outbound-port", options.outboundPort, "Proxy port to use for outbound traffic")
-	cmd.PersistentFlags().UintSliceVar(&options.ignoreInboundPorts, "skip-inbound-ports", options.ignoreInboundPorts, "Ports that should skip the proxy and send directly to the application")
-	cmd.PersistentFlags().UintSliceVar(&options.ignoreOutboundPorts, "skip-outbound-ports", options.ignoreOutboundPorts, "Outbound ports that should skip the proxy")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, fmt.Sprintf("Output format; one of: \"%s\", \"%s\"", yamlOutput, jsonPatchOutput))
+	cmd.PersistentFlags().StringVar(&options.controlPlaneAddress, "control-plane-address", options.controlPlaneAddress, "Address (host:port) of the proxy API that the proxy connects to; defaults to the proxy-api service of the control plane")
+	cmd.PersistentFlags().UintSliceVar(&options.ignoreInboundPorts, "skip-inbound-ports", options.ignoreInboundPorts, "Ports that should skip the proxy and send directly to the application, such as health check ports or ports of protocols the proxy can't handle")
+	cmd.PersistentFlags().UintSliceVar(&options.ignoreOutboundPorts, "skip-outbound-ports", options.ignoreOutboundPorts, "Outbound ports that should skip the proxy, such as the ports of databases whose servers speak first")
+	cmd.PersistentFlags().BoolVar(&options.readinessGate, "readiness-gate", options.readinessGate, "Add a readiness gate that keeps pods out of service endpoints until their proxy is ready (requires Kubernetes 1.11+)")
+	cmd.PersistentFlags().BoolVar(&options.failIfNoneInjected, "fail-if-none-injected", options.failIfNoneInjected, "Exit with a non-zero code if none of the resources were injected")
+	cmd.PersistentFlags().BoolVar(&options.ignoreCluster, "ignore-cluster", options.ignoreCluster, "Don't read the configuration of the control plane from the cluster")
 
 	return cmd
 }
 
+// setFlagsFromCluster reads the install config of the control plane, and sets
+// the flags that weren't given on the command line to the values it records.
+// The defaults of the CLI are kept if the cluster can't be reached or Linkerd
+// isn't installed.
+func setFlagsFromCluster(flags *pflag.FlagSet) error {
+	installConfigMap, err := getInstallConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Using the default proxy configuration, as the install config can't be read from the cluster: %s\n", err)
+		return nil
+	}
+	if installConfigMap == nil {
+		return nil
+	}
+
+	return setFlagsFromInstallConfig(flags, installConfigMap)
+}
+
+// getInstallConfig returns the install config of the control plane, or nil if
+// Linkerd isn't installed.
+func getInstallConfig() (*v1.ConfigMap, error) {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubeAPI.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return kubeAPI.GetConfigMap(client, controlPlaneNamespace, k8s.InstallConfigMapName)
+}
+
+// setFlagsFromInstallConfig sets the flags that weren't given on the command
+// line to the version and the flags recorded in the install config. The flags
+// of the install that don't configure the proxy, such as --ha, are ignored.
+func setFlagsFromInstallConfig(flags *pflag.FlagSet, installConfigMap *v1.ConfigMap) error {
+	source := fmt.Sprintf("the %s ConfigMap", k8s.InstallConfigMapName)
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(installConfigMap.Data[installValuesKey]), &values); err != nil {
+		return fmt.Errorf("failed to parse %s: %s", source, err)
+	}
+
+	proxyValues := map[string]interface{}{}
+	for name, value := range values {
+		if flags.Lookup(name) != nil {
+			proxyValues[name] = value
+		}
+	}
+	if version := installConfigMap.Data[installVersionKey]; version != "" && flags.Lookup("linkerd-version") != nil {
+		proxyValues["linkerd-version"] = version
+	}
+
+	b, err := yaml.Marshal(proxyValues)
+	if err != nil {
+		return err
+	}
+	return setFlagsFromValues(flags, b, source)
+}
+
 // Read all the resource files found in path into a slice of readers.
-// path can be either a file, directory or stdin.
+// path can be either a file, directory, HTTP(S) URL or stdin.
 func read(path string) ([]io.Reader, error) {
 	var (
 		in  []io.Reader
@@ -123,6 +288,22 @@ func read(path string) ([]io.Reader, error) {
 	)
 	if path == "-" {
 		in = append(in, os.Stdin)
+	} else if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		resp, err := http.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to read %s: %s", path, resp.Status)
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		in = append(in, bytes.NewReader(b))
 	} else {
 		in, err = walk(path)
 		if err != nil {
@@ -137,13 +318,20 @@ func read(path string) ([]io.Reader, error) {
 func runInjectCmd(inputs []io.Reader, errWriter, outWriter io.Writer, options *injectOptions) int {
 	postInjectBuf := &bytes.Buffer{}
 	reportBuf := &bytes.Buffer{}
+	injected := 0
 
 	for _, input := range inputs {
-		err := InjectYAML(input, postInjectBuf, reportBuf, options)
+		injectReports, err := injectYAML(input, postInjectBuf, options)
 		if err != nil {
 			fmt.Fprintf(errWriter, "Error injecting linkerd proxy: %v\n", err)
 			return 1
 		}
+		generateReport(injectReports, reportBuf)
+		for _, r := range injectReports {
+			if r.injected() {
+				injected++
+			}
+		}
 		_, err = io.Copy(outWriter, postInjectBuf)
 
 		// print error report after yaml output, for better visibility
@@ -154,6 +342,11 @@ func runInjectCmd(inputs []io.Reader, errWriter, outWriter io.Writer, options *i
 			return 1
 		}
 	}
+
+	if options.failIfNoneInjected && injected == 0 {
+		fmt.Fprintln(errWriter, "Error injecting linkerd proxy: none of the resources were injected")
+		return 1
+	}
 	return 0
 }
 
@@ -166,6 +359,12 @@ func injectObjectMeta(t *metaV1.ObjectMeta, k8sLabels map[string]string, options
 	}
 	t.Annotations[k8s.CreatedByAnnotation] = k8s.CreatedByAnnotationValue()
 	t.Annotations[k8s.ProxyVersionAnnotation] = options.linkerdVersion
+	for k, v := range options.proxyConfigOverrides() {
+		t.Annotations[k] = v
+	}
+	if options.enableIdentity() {
+		t.Annotations[k8s.IdentityModeAnnotation] = k8s.IdentityModeServiceAccount
+	}
 
 	if t.Labels == nil {
 		t.Labels = make(map[string]string)
@@ -176,6 +375,35 @@ func injectObjectMeta(t *metaV1.ObjectMeta, k8sLabels map[string]string, options
 	}
 }
 
+// proxyConfigOverrides returns the annotations recording the proxy
+// configuration that differs from the defaults.
+func (options *injectOptions) proxyConfigOverrides() map[string]string {
+	defaults := newInjectOptions()
+	overrides := map[string]string{}
+
+	if options.proxyImage != defaults.proxyImage || options.dockerRegistry != defaults.dockerRegistry {
+		overrides[k8s.ProxyImageAnnotation] = strings.Replace(options.proxyImage, defaultDockerRegistry, options.dockerRegistry, 1)
+	}
+	if options.proxyLogLevel != defaults.proxyLogLevel {
+		overrides[k8s.ProxyLogLevelAnnotation] = options.proxyLogLevel
+	}
+	if options.controlPlaneAddress != "" {
+		overrides[k8s.ProxyControlPlaneAddressAnnotation] = options.controlPlaneAddress
+	}
+	if options.inboundPort != defaults.inboundPort {
+		overrides[k8s.ProxyInboundPortAnnotation] = strconv.Itoa(int(options.inboundPort))
+	}
+	if options.outboundPort != defaults.outboundPort {
+		overrides[k8s.ProxyOutboundPortAnnotation] = strconv.Itoa(int(options.outboundPort))
+	}
+	if options.traceCollector != "" {
+		overrides[k8s.ProxyTraceCollectorAnnotation] = options.traceCollector
+		overrides[k8s.ProxyTracePropagationAnnotation] = options.tracePropagation
+	}
+
+	return overrides
+}
+
 /* Given a PodSpec, update the PodSpec in place with the sidecar
  * and init-container injected. If the pod is unsuitable for having them
  * injected, return false.
@@ -184,13 +412,17 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 	report.hostNetwork = t.HostNetwork
 	report.sidecar = checkSidecars(t)
 	report.udp = checkUDPPorts(t)
+	report.unsupportedArch = checkArchitecture(t, options.proxyArchitectures)
 
 	// Skip injection if:
 	// 1) Pods with `hostNetwork: true` share a network namespace with the host.
 	//    The init-container would destroy the iptables configuration on the host.
 	// OR
 	// 2) Known sidecars already present.
-	if report.hostNetwork || report.sidecar {
+	// OR
+	// 3) Pods are scheduled on nodes whose architecture the proxy images
+	//    aren't published for, on which the proxy couldn't run.
+	if report.hostNetwork || report.sidecar || report.unsupportedArch != "" {
 		return false
 	}
 
@@ -235,9 +467,11 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 			Privileged: &f,
 		},
 	}
-	controlPlaneDNS := fmt.Sprintf("proxy-api.%s.svc.cluster.local", controlPlaneNamespace)
+	controlPlaneAddress := fmt.Sprintf("proxy-api.%s.svc.%s:%d", controlPlaneNamespace, options.clusterDomain, options.proxyAPIPort)
 	if controlPlaneDNSNameOverride != "" {
-		controlPlaneDNS = controlPlaneDNSNameOverride
+		controlPlaneAddress = fmt.Sprintf("%s:%d", controlPlaneDNSNameOverride, options.proxyAPIPort)
+	} else if options.controlPlaneAddress != "" {
+		controlPlaneAddress = options.controlPlaneAddress
 	}
 
 	metricsPort := intstr.IntOrString{
@@ -276,7 +510,7 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 			{Name: "LINKERD2_PROXY_BIND_TIMEOUT", Value: options.proxyBindTimeout},
 			{
 				Name:  "LINKERD2_PROXY_CONTROL_URL",
-				Value: fmt.Sprintf("tcp://%s:%d", controlPlaneDNS, options.proxyAPIPort),
+				Value: fmt.Sprintf("tcp://%s", controlPlaneAddress),
 			},
 			{Name: "LINKERD2_PROXY_CONTROL_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", options.proxyControlPort)},
 			{Name: "LINKERD2_PROXY_METRICS_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", options.proxyMetricsPort)},
@@ -291,6 +525,35 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 		LivenessProbe:  &proxyProbe,
 	}
 
+	if options.restrictedPodSecurity {
+		yes := true
+		sidecar.SecurityContext.RunAsNonRoot = &yes
+		sidecar.SecurityContext.ReadOnlyRootFilesystem = &yes
+		sidecar.SecurityContext.AllowPrivilegeEscalation = &f
+		sidecar.SecurityContext.Capabilities = &v1.Capabilities{
+			Drop: []v1.Capability{v1.Capability("ALL")},
+		}
+	}
+
+	if options.proxyCPURequest != "" || options.proxyMemoryRequest != "" {
+		sidecar.Resources.Requests = v1.ResourceList{}
+		if options.proxyCPURequest != "" {
+			sidecar.Resources.Requests[v1.ResourceCPU] = resource.MustParse(options.proxyCPURequest)
+		}
+		if options.proxyMemoryRequest != "" {
+			sidecar.Resources.Requests[v1.ResourceMemory] = resource.MustParse(options.proxyMemoryRequest)
+		}
+	}
+	if options.proxyCPULimit != "" || options.proxyMemoryLimit != "" {
+		sidecar.Resources.Limits = v1.ResourceList{}
+		if options.proxyCPULimit != "" {
+			sidecar.Resources.Limits[v1.ResourceCPU] = resource.MustParse(options.proxyCPULimit)
+		}
+		if options.proxyMemoryLimit != "" {
+			sidecar.Resources.Limits[v1.ResourceMemory] = resource.MustParse(options.proxyMemoryLimit)
+		}
+	}
+
 	// Special case if the caller specifies that
 	// LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY be set on the pod.
 	// We key off of any container image in the pod. Ideally we would instead key
@@ -309,7 +572,18 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 		}
 	}
 
-	if options.enableTLS() {
+	// The proxies only trace requests when they're given the address of a
+	// collector to send the spans to.
+	if options.traceCollector != "" {
+		sidecar.Env = append(sidecar.Env,
+			v1.EnvVar{Name: "LINKERD2_PROXY_TRACE_COLLECTOR_SVC_ADDR", Value: options.traceCollector},
+			v1.EnvVar{Name: "LINKERD2_PROXY_TRACE_PROPAGATION", Value: options.tracePropagation},
+		)
+	}
+
+	if options.enableIdentity() {
+		injectIdentity(t, &sidecar, options)
+	} else if options.enableTLS() {
 		yes := true
 
 		configMapVolume := v1.Volume{
@@ -322,7 +596,7 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 			},
 		}
 		secretVolume := v1.Volume{
-			Name: "linkerd-secrets",
+			Name: k8s.TLSSecretsVolumeName,
 			VolumeSource: v1.VolumeSource{
 				Secret: &v1.SecretVolumeSource{
 					SecretName: identity.ToSecretName(),
@@ -356,18 +630,101 @@ func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameO
 	}
 
 	t.Containers = append(t.Containers, sidecar)
-	t.InitContainers = append(t.InitContainers, initContainer)
+	// the linkerd CNI plugin configures the iptables of the pod instead
+	if !options.noInitContainer {
+		t.InitContainers = append(t.InitContainers, initContainer)
+	}
+
+	if options.readinessGate {
+		t.ReadinessGates = append(t.ReadinessGates, v1.PodReadinessGate{
+			ConditionType: k8s.ProxyReadyConditionType,
+		})
+	}
 
 	return true
 }
 
+// injectIdentity configures the proxy sidecar to be certified by the identity
+// service, for the identity of the pod's ServiceAccount. The proxy generates
+// its private key in an in-memory volume, and authenticates its certificate
+// signing requests with the ServiceAccount's token. The ServiceAccount and
+// namespace are only known once the pod is created, so the identity is
+// expanded from the variables that hold them.
+func injectIdentity(t *v1.PodSpec, sidecar *v1.Container, options *injectOptions) {
+	yes := true
+
+	configMapVolume := v1.Volume{
+		Name: "linkerd-trust-anchors",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: k8s.TLSTrustAnchorConfigMapName},
+				Optional:             &yes,
+			},
+		},
+	}
+	endEntityVolume := v1.Volume{
+		Name: "linkerd-identity-end-entity",
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory},
+		},
+	}
+
+	identity := k8s.ServiceAccountIdentity{
+		Name:                fmt.Sprintf("$(%s)", PodServiceAccountEnvVarName),
+		Namespace:           fmt.Sprintf("$(%s)", PodNamespaceEnvVarName),
+		ControllerNamespace: controlPlaneNamespace,
+		TrustDomain:         options.trustDomain,
+	}
+
+	base := "/var/linkerd-io"
+	configMapBase := base + "/trust-anchors"
+	endEntityBase := base + "/identity"
+	identityEnvVars := []v1.EnvVar{
+		{
+			Name:      PodServiceAccountEnvVarName,
+			ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.serviceAccountName"}},
+		},
+		{Name: "LINKERD2_PROXY_TLS_TRUST_ANCHORS", Value: configMapBase + "/" + k8s.TLSTrustAnchorFileName},
+		{Name: "LINKERD2_PROXY_IDENTITY_DIR", Value: endEntityBase},
+		{Name: "LINKERD2_PROXY_IDENTITY_TOKEN_FILE", Value: k8s.ServiceAccountTokenPath},
+		{Name: "LINKERD2_PROXY_IDENTITY_LOCAL_NAME", Value: identity.ToDNSName()},
+		{
+			Name:  "LINKERD2_PROXY_IDENTITY_SVC_ADDR",
+			Value: fmt.Sprintf("%s.%s.svc.%s:%d", k8s.IdentityServiceName, controlPlaneNamespace, options.clusterDomain, k8s.IdentityServicePort),
+		},
+		{Name: "LINKERD2_PROXY_IDENTITY_SVC_NAME", Value: identity.ToIdentityServiceIdentity().ToDNSName()},
+		{Name: "LINKERD2_PROXY_CONTROLLER_NAMESPACE", Value: controlPlaneNamespace},
+		{Name: "LINKERD2_PROXY_TLS_CONTROLLER_IDENTITY", Value: identity.ToControllerIdentity().ToDNSName()},
+	}
+
+	sidecar.Env = append(sidecar.Env, identityEnvVars...)
+	sidecar.VolumeMounts = []v1.VolumeMount{
+		{Name: configMapVolume.Name, MountPath: configMapBase, ReadOnly: true},
+		{Name: endEntityVolume.Name, MountPath: endEntityBase},
+	}
+
+	t.Volumes = append(t.Volumes, configMapVolume, endEntityVolume)
+}
+
 // InjectYAML takes an input stream of YAML, outputting injected YAML to out.
 func InjectYAML(in io.Reader, out io.Writer, report io.Writer, options *injectOptions) error {
-	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(in, 4096))
+	injectReports, err := injectYAML(in, out, options)
+	if err != nil {
+		return err
+	}
 
-	injectReports := []injectReport{}
+	generateReport(injectReports, report)
+	return nil
+}
+
+// injectYAML outputs the injected YAML of each object of the input stream to
+// out, and returns the report of each object.
+func injectYAML(in io.Reader, out io.Writer, options *injectOptions) ([]injectReport, error) {
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(in, 4096))
 
-	// Iterate over all YAML objects in the input
+	// Read all YAML objects in the input first, so that the namespaces that
+	// disable injection are known before injecting the objects in them
+	documents := [][]byte{}
 	for {
 		// Read a single YAML object
 		bytes, err := reader.Read()
@@ -375,40 +732,99 @@ func InjectYAML(in io.Reader, out io.Writer, report io.Writer, options *injectOp
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
+		documents = append(documents, bytes)
+	}
 
+	disabledNamespaces, err := injectDisabledNamespaces(documents)
+	if err != nil {
+		return nil, err
+	}
+
+	injectReports := []injectReport{}
+
+	for _, bytes := range documents {
 		ir := injectReport{}
-		result, err := injectResource(bytes, options, &ir)
+		result, err := injectResource(bytes, options, disabledNamespaces, &ir)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		out.Write(result)
-		out.Write([]byte("---\n"))
+		// patches are output one per line, and only for injected documents
+		if options.output != jsonPatchOutput {
+			out.Write([]byte("---\n"))
+		}
 
 		injectReports = append(injectReports, ir)
 	}
 
-	generateReport(injectReports, report)
+	return injectReports, nil
+}
 
-	return nil
+// injectDisabledNamespaces returns the namespaces defined in the documents
+// that disable injection with the linkerd.io/inject annotation.
+func injectDisabledNamespaces(documents [][]byte) (map[string]bool, error) {
+	namespaces := map[string]bool{}
+	for _, bytes := range documents {
+		var meta metaV1.TypeMeta
+		if err := yaml.Unmarshal(bytes, &meta); err != nil {
+			return nil, err
+		}
+		if meta.Kind != "Namespace" {
+			continue
+		}
+
+		var om objMeta
+		if err := yaml.Unmarshal(bytes, &om); err != nil {
+			return nil, err
+		}
+		if om.Annotations[k8s.ProxyInjectAnnotation] == k8s.ProxyInjectDisabled {
+			namespaces[om.Name] = true
+		}
+	}
+	return namespaces, nil
+}
+
+// injectDisabled returns true if injection is disabled by the linkerd.io/inject
+// annotation of a pod template, of the workload it belongs to, or of the
+// namespace of the workload.
+func injectDisabled(workload, template *metaV1.ObjectMeta, disabledNamespaces map[string]bool) bool {
+	return template.Annotations[k8s.ProxyInjectAnnotation] == k8s.ProxyInjectDisabled ||
+		workload.Annotations[k8s.ProxyInjectAnnotation] == k8s.ProxyInjectDisabled ||
+		disabledNamespaces[workload.Namespace]
 }
 
-func injectList(b []byte, options *injectOptions, report *injectReport) ([]byte, error) {
+func injectList(b []byte, options *injectOptions, disabledNamespaces map[string]bool, report *injectReport) ([]byte, error) {
 	var sourceList v1.List
 	if err := yaml.Unmarshal(b, &sourceList); err != nil {
 		return nil, err
 	}
 
 	items := []runtime.RawExtension{}
+	patch := []patchOperation{}
 
-	for _, item := range sourceList.Items {
-		result, err := injectResource(item.Raw, options, report)
+	for i, item := range sourceList.Items {
+		result, err := injectResource(item.Raw, options, disabledNamespaces, report)
 		if err != nil {
 			return nil, err
 		}
 
+		if options.output == jsonPatchOutput {
+			var itemPatch []patchOperation
+			if len(result) > 0 {
+				if err := json.Unmarshal(result, &itemPatch); err != nil {
+					return nil, err
+				}
+			}
+			for _, op := range itemPatch {
+				op.Path = fmt.Sprintf("/items/%d%s", i, op.Path)
+				patch = append(patch, op)
+			}
+			continue
+		}
+
 		// At this point, we have yaml. The kubernetes internal representation is
 		// json. Because we're building a list from RawExtensions, the yaml needs
 		// to be converted to json.
@@ -420,11 +836,18 @@ func injectList(b []byte, options *injectOptions, report *injectReport) ([]byte,
 		items = append(items, runtime.RawExtension{Raw: injected})
 	}
 
+	if options.output == jsonPatchOutput {
+		if len(patch) == 0 {
+			return nil, nil
+		}
+		return marshalPatch(patch)
+	}
+
 	sourceList.Items = items
 	return yaml.Marshal(sourceList)
 }
 
-func injectResource(bytes []byte, options *injectOptions, report *injectReport) ([]byte, error) {
+func injectResource(bytes []byte, options *injectOptions, disabledNamespaces map[string]bool, report *injectReport) ([]byte, error) {
 	// The Kubernetes API is versioned and each version has an API modeled
 	// with its own distinct Go types. If we tell `yaml.Unmarshal()` which
 	// version we support then it will provide a representation of that
@@ -454,6 +877,8 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 	var obj interface{}
 	var podSpec *v1.PodSpec
 	var objectMeta *metaV1.ObjectMeta
+	// templatePath is the JSON pointer to the pod template of the object
+	templatePath := "/spec/template"
 	var DNSNameOverride string
 	k8sLabels := map[string]string{}
 
@@ -518,6 +943,20 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 		podSpec = &job.Spec.Template.Spec
 		objectMeta = &job.Spec.Template.ObjectMeta
 
+	case "CronJob":
+		var cronJob batchV1beta1.CronJob
+		if err := yaml.Unmarshal(bytes, &cronJob); err != nil {
+			return nil, err
+		}
+
+		// the pod template is nested in the template of the jobs the CronJob
+		// creates
+		obj = &cronJob
+		k8sLabels[k8s.ProxyCronJobLabel] = cronJob.Name
+		podSpec = &cronJob.Spec.JobTemplate.Spec.Template.Spec
+		objectMeta = &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta
+		templatePath = "/spec/jobTemplate/spec/template"
+
 	case "DaemonSet":
 		var ds v1beta1.DaemonSet
 		if err := yaml.Unmarshal(bytes, &ds); err != nil {
@@ -549,6 +988,7 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 		obj = &pod
 		podSpec = &pod.Spec
 		objectMeta = &pod.ObjectMeta
+		templatePath = ""
 
 	case "List":
 		// Lists are a little different than the other types. There's no immediate
@@ -556,7 +996,7 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 		// in the list (instead of just marshaling the injected pod template).
 
 		// TODO: generate an injectReport per list item
-		return injectList(bytes, options, report)
+		return injectList(bytes, options, disabledNamespaces, report)
 
 	}
 
@@ -564,7 +1004,12 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 	// original serialization of the original object. Otherwise, output the
 	// serialization of the modified object.
 	output := bytes
-	if podSpec != nil {
+	if options.output == jsonPatchOutput {
+		output = nil
+	}
+	if podSpec != nil && injectDisabled(&om.ObjectMeta, objectMeta, disabledNamespaces) {
+		report.injectDisabled = true
+	} else if podSpec != nil {
 		metaAccessor, err := k8sMeta.Accessor(obj)
 		if err != nil {
 			return nil, err
@@ -578,12 +1023,32 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 			Kind:                strings.ToLower(meta.Kind),
 			Namespace:           "$" + PodNamespaceEnvVarName,
 			ControllerNamespace: controlPlaneNamespace,
+			TrustDomain:         options.trustDomain,
 		}
 
 		if injectPodSpec(podSpec, identity, DNSNameOverride, options, report) {
 			injectObjectMeta(objectMeta, k8sLabels, options)
+			containersPath := templatePath + "/spec/containers"
 			var err error
-			output, err = yaml.Marshal(obj)
+			if options.output == jsonPatchOutput {
+				patch := podTemplatePatch(templatePath, objectMeta, podSpec)
+				for i := range patch {
+					if options.restrictedPodSecurity && patch[i].Path == containersPath {
+						patch[i].Value, err = withProxySeccompProfile(patch[i].Value, "")
+					}
+				}
+				if err == nil {
+					output, err = marshalPatch(patch)
+				}
+			} else {
+				var injected interface{} = obj
+				if options.restrictedPodSecurity {
+					injected, err = withProxySeccompProfile(obj, containersPath)
+				}
+				if err == nil {
+					output, err = yaml.Marshal(injected)
+				}
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -595,6 +1060,82 @@ func injectResource(bytes []byte, options *injectOptions, report *injectReport)
 	return output, nil
 }
 
+// podTemplatePatch returns the JSON patch operations that set the injected
+// fields of a pod template. "add" operations replace the values of existing
+// fields.
+func podTemplatePatch(templatePath string, t *metaV1.ObjectMeta, spec *v1.PodSpec) []patchOperation {
+	patch := []patchOperation{
+		{Op: "add", Path: templatePath + "/metadata/labels", Value: t.Labels},
+		{Op: "add", Path: templatePath + "/metadata/annotations", Value: t.Annotations},
+		{Op: "add", Path: templatePath + "/spec/containers", Value: spec.Containers},
+	}
+	if len(spec.InitContainers) > 0 {
+		patch = append(patch, patchOperation{Op: "add", Path: templatePath + "/spec/initContainers", Value: spec.InitContainers})
+	}
+	if len(spec.Volumes) > 0 {
+		patch = append(patch, patchOperation{Op: "add", Path: templatePath + "/spec/volumes", Value: spec.Volumes})
+	}
+	if len(spec.ReadinessGates) > 0 {
+		patch = append(patch, patchOperation{Op: "add", Path: templatePath + "/spec/readinessGates", Value: spec.ReadinessGates})
+	}
+	return patch
+}
+
+// withProxySeccompProfile returns obj as unstructured JSON, with the proxy
+// container among the containers at path setting the runtime's default
+// seccomp profile. The securityContext.seccompProfile field is newer than the
+// Kubernetes API types the CLI is built with, so it can't be set on the
+// container itself. path is a JSON pointer, or empty if obj is the list of
+// containers.
+func withProxySeccompProfile(obj interface{}, path string) (interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var unstructured interface{}
+	if err := json.Unmarshal(b, &unstructured); err != nil {
+		return nil, err
+	}
+
+	containers := unstructured
+	if path != "" {
+		for _, field := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+			fields, ok := containers.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("no containers at %s", path)
+			}
+			containers = fields[field]
+		}
+	}
+	list, ok := containers.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no containers at %s", path)
+	}
+	for _, c := range list {
+		container, ok := c.(map[string]interface{})
+		if !ok || container["name"] != k8s.ProxyContainerName {
+			continue
+		}
+		securityContext, ok := container["securityContext"].(map[string]interface{})
+		if !ok {
+			securityContext = map[string]interface{}{}
+			container["securityContext"] = securityContext
+		}
+		securityContext["seccompProfile"] = map[string]interface{}{"type": k8s.SeccompProfileTypeRuntimeDefault}
+	}
+
+	return unstructured, nil
+}
+
+// marshalPatch returns a JSON patch on a single line.
+func marshalPatch(patch []patchOperation) ([]byte, error) {
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
 // walk walks the file tree rooted at path. path may be a file or a directory.
 // Creates a reader for each file found.
 func walk(path string) ([]io.Reader, error) {
@@ -638,16 +1179,20 @@ func walk(path string) ([]io.Reader, error) {
 	return in, nil
 }
 
-func generateReport(injectReports []injectReport, output io.Writer) {
+func generateReport(injectReports []injectReport, w io.Writer) {
 
 	injected := []string{}
 	hostNetwork := []string{}
 	sidecar := []string{}
 	udp := []string{}
 
+	skipped := []string{}
+
 	for _, r := range injectReports {
-		if !r.hostNetwork && !r.sidecar && !r.unsupportedResource {
+		if r.injected() {
 			injected = append(injected, r.name)
+		} else {
+			skipped = append(skipped, fmt.Sprintf("%s: %s", r.name, r.skipReason()))
 		}
 
 		if r.hostNetwork {
@@ -668,38 +1213,34 @@ func generateReport(injectReports []injectReport, output io.Writer) {
 	//
 
 	// leading newline to separate from yaml output on stdout
-	output.Write([]byte("\n"))
+	w.Write([]byte("\n"))
 
-	hostNetworkPrefix := fmt.Sprintf("%s%s", hostNetworkDesc, getFiller(hostNetworkDesc))
 	if len(hostNetwork) == 0 {
-		output.Write([]byte(fmt.Sprintf("%s%s\n", hostNetworkPrefix, okStatus)))
+		output.StatusLine(w, hostNetworkDesc, output.Ok, "")
 	} else {
-		output.Write([]byte(fmt.Sprintf("%s%s -- \"hostNetwork: true\" detected in %s\n", hostNetworkPrefix, warnStatus, strings.Join(hostNetwork, ", "))))
+		output.StatusLine(w, hostNetworkDesc, output.Warn, fmt.Sprintf("\"hostNetwork: true\" detected in %s", strings.Join(hostNetwork, ", ")))
 	}
 
-	sidecarPrefix := fmt.Sprintf("%s%s", sidecarDesc, getFiller(sidecarDesc))
 	if len(sidecar) == 0 {
-		output.Write([]byte(fmt.Sprintf("%s%s\n", sidecarPrefix, okStatus)))
+		output.StatusLine(w, sidecarDesc, output.Ok, "")
 	} else {
-		output.Write([]byte(fmt.Sprintf("%s%s -- known sidecar detected in %s\n", sidecarPrefix, warnStatus, strings.Join(sidecar, ", "))))
+		output.StatusLine(w, sidecarDesc, output.Warn, fmt.Sprintf("known sidecar detected in %s", strings.Join(sidecar, ", ")))
 	}
 
-	unsupportedPrefix := fmt.Sprintf("%s%s", unsupportedDesc, getFiller(unsupportedDesc))
 	if len(injected) > 0 {
-		output.Write([]byte(fmt.Sprintf("%s%s\n", unsupportedPrefix, okStatus)))
+		output.StatusLine(w, unsupportedDesc, output.Ok, "")
 	} else {
-		output.Write([]byte(fmt.Sprintf("%s%s -- no supported objects found\n", unsupportedPrefix, warnStatus)))
+		output.StatusLine(w, unsupportedDesc, output.Warn, "no supported objects found")
 	}
 
-	udpPrefix := fmt.Sprintf("%s%s", udpDesc, getFiller(udpDesc))
 	if len(udp) == 0 {
-		output.Write([]byte(fmt.Sprintf("%s%s\n", udpPrefix, okStatus)))
+		output.StatusLine(w, udpDesc, output.Ok, "")
 	} else {
 		verb := "uses"
 		if len(udp) > 1 {
 			verb = "use"
 		}
-		output.Write([]byte(fmt.Sprintf("%s%s -- %s %s \"protocol: UDP\"\n", udpPrefix, warnStatus, strings.Join(udp, ", "), verb)))
+		output.StatusLine(w, udpDesc, output.Warn, fmt.Sprintf("%s %s \"protocol: UDP\"", strings.Join(udp, ", "), verb))
 	}
 
 	//
@@ -707,23 +1248,21 @@ func generateReport(injectReports []injectReport, output io.Writer) {
 	//
 
 	summary := fmt.Sprintf("Summary: %d of %d YAML document(s) injected", len(injected), len(injectReports))
-	output.Write([]byte(fmt.Sprintf("\n%s\n", summary)))
+	w.Write([]byte(fmt.Sprintf("\n%s\n", summary)))
 
 	for _, i := range injected {
-		output.Write([]byte(fmt.Sprintf("  %s\n", i)))
+		w.Write([]byte(fmt.Sprintf("  %s\n", i)))
 	}
 
-	// trailing newline to separate from kubectl output if piping
-	output.Write([]byte("\n"))
-}
-
-func getFiller(text string) string {
-	filler := ""
-	for i := 0; i < lineWidth-len(text)-len(okStatus)-len("\n"); i++ {
-		filler = filler + "."
+	if len(skipped) > 0 {
+		w.Write([]byte(fmt.Sprintf("\nSkipped %d YAML document(s)\n", len(skipped))))
+		for _, s := range skipped {
+			w.Write([]byte(fmt.Sprintf("  %s\n", s)))
+		}
 	}
 
-	return filler
+	// trailing newline to separate from kubectl output if piping
+	w.Write([]byte("\n"))
 }
 
 func checkUDPPorts(t *v1.PodSpec) bool {
@@ -738,6 +1277,87 @@ func checkUDPPorts(t *v1.PodSpec) bool {
 	return false
 }
 
+// checkArchitecture returns the architectures that the pods are scheduled on by
+// their node selector and required node affinity, if none of them is one that
+// the proxy images are published for. The node selector terms of the affinity
+// are alternatives, so the pods are injected if any term allows a supported
+// architecture, or doesn't restrict the architecture with the In operator.
+func checkArchitecture(t *v1.PodSpec, architectures []string) string {
+	supported := map[string]bool{}
+	for _, arch := range architectures {
+		supported[arch] = true
+	}
+
+	terms := []v1.NodeSelectorTerm{{}}
+	if affinity := t.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		terms = affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	}
+
+	unsupported := map[string]bool{}
+	for _, term := range terms {
+		archs := termArchitectures(t.NodeSelector, term)
+		if archs == nil {
+			return ""
+		}
+		for arch := range archs {
+			if supported[arch] {
+				return ""
+			}
+			unsupported[arch] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(unsupported))
+	for arch := range unsupported {
+		sorted = append(sorted, arch)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, " or ")
+}
+
+// termArchitectures returns the architectures of the nodes that match both a
+// node selector and a node selector term, or nil if they don't restrict the
+// architecture.
+func termArchitectures(nodeSelector map[string]string, term v1.NodeSelectorTerm) map[string]bool {
+	var archs map[string]bool
+	restrict := func(values ...string) {
+		allowed := map[string]bool{}
+		for _, value := range values {
+			if archs == nil || archs[value] {
+				allowed[value] = true
+			}
+		}
+		archs = allowed
+	}
+	isArchLabel := func(key string) bool {
+		return key == k8s.NodeArchLabel || key == k8s.NodeArchBetaLabel
+	}
+
+	for key, value := range nodeSelector {
+		if isArchLabel(key) {
+			restrict(value)
+		}
+	}
+	excluded := []string{}
+	for _, expr := range term.MatchExpressions {
+		if !isArchLabel(expr.Key) {
+			continue
+		}
+		switch expr.Operator {
+		case v1.NodeSelectorOpIn:
+			restrict(expr.Values...)
+		case v1.NodeSelectorOpNotIn:
+			excluded = append(excluded, expr.Values...)
+		}
+	}
+	for _, arch := range excluded {
+		if archs != nil {
+			delete(archs, arch)
+		}
+	}
+	return archs
+}
+
 func checkSidecars(t *v1.PodSpec) bool {
 	// check for known proxies and initContainers
 	for _, container := range t.Containers {
@@ -3,12 +3,23 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
 )
 
 func TestInjectYAML(t *testing.T) {
@@ -19,6 +30,39 @@ func TestInjectYAML(t *testing.T) {
 	tlsOptions.linkerdVersion = "testinjectversion"
 	tlsOptions.tls = "optional"
 
+	readinessGateOptions := newInjectOptions()
+	readinessGateOptions.linkerdVersion = "testinjectversion"
+	readinessGateOptions.readinessGate = true
+
+	skipPortsOptions := newInjectOptions()
+	skipPortsOptions.linkerdVersion = "testinjectversion"
+	skipPortsOptions.ignoreInboundPorts = []uint{3306}
+	skipPortsOptions.ignoreOutboundPorts = []uint{3306, 5432}
+
+	resourcesOptions := newInjectOptions()
+	resourcesOptions.linkerdVersion = "testinjectversion"
+	resourcesOptions.proxyCPURequest = "100m"
+	resourcesOptions.proxyMemoryRequest = "64Mi"
+	resourcesOptions.proxyCPULimit = "500m"
+	resourcesOptions.proxyMemoryLimit = "256Mi"
+
+	overridesOptions := newInjectOptions()
+	overridesOptions.linkerdVersion = "testinjectversion"
+	overridesOptions.proxyImage = "example.com/linkerd/proxy"
+	overridesOptions.proxyLogLevel = "debug"
+	overridesOptions.controlPlaneAddress = "proxy-api.linkerd-prod.svc.cluster.local:8086"
+	overridesOptions.inboundPort = 5143
+	overridesOptions.outboundPort = 5140
+
+	tracingOptions := newInjectOptions()
+	tracingOptions.linkerdVersion = "testinjectversion"
+	tracingOptions.traceCollector = "oc-collector.tracing:55678"
+	tracingOptions.tracePropagation = "w3c"
+
+	cniOptions := newInjectOptions()
+	cniOptions.linkerdVersion = "testinjectversion"
+	cniOptions.noInitContainer = true
+
 	testCases := []struct {
 		inputFileName     string
 		goldenFileName    string
@@ -37,6 +81,12 @@ func TestInjectYAML(t *testing.T) {
 			reportFileName:    "inject_emojivoto_list.report",
 			testInjectOptions: defaultOptions,
 		},
+		{
+			inputFileName:     "inject_emojivoto_multidoc.input.yml",
+			goldenFileName:    "inject_emojivoto_multidoc.golden.yml",
+			reportFileName:    "inject_emojivoto_multidoc.report",
+			testInjectOptions: defaultOptions,
+		},
 		{
 			inputFileName:     "inject_emojivoto_deployment_hostNetwork_false.input.yml",
 			goldenFileName:    "inject_emojivoto_deployment_hostNetwork_false.golden.yml",
@@ -61,6 +111,30 @@ func TestInjectYAML(t *testing.T) {
 			reportFileName:    "inject_emojivoto_statefulset.report",
 			testInjectOptions: defaultOptions,
 		},
+		{
+			inputFileName:     "inject_emojivoto_replicaset.input.yml",
+			goldenFileName:    "inject_emojivoto_replicaset.golden.yml",
+			reportFileName:    "inject_emojivoto_replicaset.report",
+			testInjectOptions: defaultOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_daemonset.input.yml",
+			goldenFileName:    "inject_emojivoto_daemonset.golden.yml",
+			reportFileName:    "inject_emojivoto_daemonset.report",
+			testInjectOptions: defaultOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_job.input.yml",
+			goldenFileName:    "inject_emojivoto_job.golden.yml",
+			reportFileName:    "inject_emojivoto_job.report",
+			testInjectOptions: defaultOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_cronjob.input.yml",
+			goldenFileName:    "inject_emojivoto_cronjob.golden.yml",
+			reportFileName:    "inject_emojivoto_cronjob.report",
+			testInjectOptions: defaultOptions,
+		},
 		{
 			inputFileName:     "inject_emojivoto_pod.input.yml",
 			goldenFileName:    "inject_emojivoto_pod.golden.yml",
@@ -73,12 +147,48 @@ func TestInjectYAML(t *testing.T) {
 			reportFileName:    "inject_emojivoto_deployment.report",
 			testInjectOptions: tlsOptions,
 		},
+		{
+			inputFileName:     "inject_emojivoto_deployment.input.yml",
+			goldenFileName:    "inject_emojivoto_deployment_skip_ports.golden.yml",
+			reportFileName:    "inject_emojivoto_deployment.report",
+			testInjectOptions: skipPortsOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_deployment.input.yml",
+			goldenFileName:    "inject_emojivoto_deployment_resources.golden.yml",
+			reportFileName:    "inject_emojivoto_deployment.report",
+			testInjectOptions: resourcesOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_deployment.input.yml",
+			goldenFileName:    "inject_emojivoto_deployment_overrides.golden.yml",
+			reportFileName:    "inject_emojivoto_deployment.report",
+			testInjectOptions: overridesOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_deployment.input.yml",
+			goldenFileName:    "inject_emojivoto_deployment_tracing.golden.yml",
+			reportFileName:    "inject_emojivoto_deployment.report",
+			testInjectOptions: tracingOptions,
+		},
+		{
+			inputFileName:     "inject_emojivoto_deployment.input.yml",
+			goldenFileName:    "inject_emojivoto_deployment_cni.golden.yml",
+			reportFileName:    "inject_emojivoto_deployment.report",
+			testInjectOptions: cniOptions,
+		},
 		{
 			inputFileName:     "inject_emojivoto_pod.input.yml",
 			goldenFileName:    "inject_emojivoto_pod_tls.golden.yml",
 			reportFileName:    "inject_emojivoto_pod.report",
 			testInjectOptions: tlsOptions,
 		},
+		{
+			inputFileName:     "inject_emojivoto_pod.input.yml",
+			goldenFileName:    "inject_emojivoto_pod_readiness_gate.golden.yml",
+			reportFileName:    "inject_emojivoto_pod.report",
+			testInjectOptions: readinessGateOptions,
+		},
 		{
 			inputFileName:     "inject_emojivoto_deployment_udp.input.yml",
 			goldenFileName:    "inject_emojivoto_deployment_udp.golden.yml",
@@ -91,6 +201,12 @@ func TestInjectYAML(t *testing.T) {
 			reportFileName:    "inject_emojivoto_already_injected.report",
 			testInjectOptions: defaultOptions,
 		},
+		{
+			inputFileName:     "inject_emojivoto_disabled.input.yml",
+			goldenFileName:    "inject_emojivoto_disabled.golden.yml",
+			reportFileName:    "inject_emojivoto_disabled.report",
+			testInjectOptions: defaultOptions,
+		},
 		{
 			inputFileName:     "inject_emojivoto_istio.input.yml",
 			goldenFileName:    "inject_emojivoto_istio.input.yml",
@@ -137,6 +253,91 @@ func TestInjectYAML(t *testing.T) {
 	}
 }
 
+func TestInjectYAMLJSONPatch(t *testing.T) {
+	options := newInjectOptions()
+	options.linkerdVersion = "testinjectversion"
+	options.output = jsonPatchOutput
+
+	testCases := []struct {
+		inputFileName  string
+		goldenFileName string
+		pathPrefix     string
+	}{
+		{
+			inputFileName:  "inject_emojivoto_multidoc.input.yml",
+			goldenFileName: "inject_emojivoto_deployment.golden.yml",
+			pathPrefix:     "/spec/template",
+		},
+		{
+			inputFileName:  "inject_emojivoto_list.input.yml",
+			goldenFileName: "inject_emojivoto_deployment.golden.yml",
+			pathPrefix:     "/items/0/spec/template",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d: %s", i, tc.inputFileName), func(t *testing.T) {
+			file, err := os.Open("testdata/" + tc.inputFileName)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer file.Close()
+
+			output := new(bytes.Buffer)
+			if err := InjectYAML(file, output, ioutil.Discard, options); err != nil {
+				t.Fatalf("Unexpected error injecting YAML: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("Expected a single patch, got %d lines:\n%s", len(lines), output.String())
+			}
+
+			var patch []struct {
+				Op    string
+				Path  string
+				Value json.RawMessage
+			}
+			if err := json.Unmarshal([]byte(lines[0]), &patch); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var golden v1beta1.Deployment
+			if err := yaml.Unmarshal([]byte(readOptionalTestFile(t, tc.goldenFileName)), &golden); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var patched v1.PodTemplateSpec
+			values := map[string]interface{}{
+				"/metadata/labels":      &patched.Labels,
+				"/metadata/annotations": &patched.Annotations,
+				"/spec/containers":      &patched.Spec.Containers,
+				"/spec/initContainers":  &patched.Spec.InitContainers,
+			}
+			if len(patch) != len(values) {
+				t.Fatalf("Expected %d patch operations, got %d", len(values), len(patch))
+			}
+			for _, op := range patch {
+				value, ok := values[strings.TrimPrefix(op.Path, tc.pathPrefix)]
+				if op.Op != "add" || !ok {
+					t.Fatalf("Unexpected patch operation: %s %s", op.Op, op.Path)
+				}
+				if err := json.Unmarshal(op.Value, value); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+
+			expected := golden.Spec.Template
+			if !reflect.DeepEqual(patched.Labels, expected.Labels) ||
+				!reflect.DeepEqual(patched.Annotations, expected.Annotations) ||
+				!reflect.DeepEqual(patched.Spec.Containers, expected.Spec.Containers) ||
+				!reflect.DeepEqual(patched.Spec.InitContainers, expected.Spec.InitContainers) {
+				t.Fatalf("Patched pod template mismatch.\nExpected: %+v\nActual: %+v", expected, patched)
+			}
+		})
+	}
+}
+
 func TestRunInjectCmd(t *testing.T) {
 	testInjectOptions := newInjectOptions()
 	testInjectOptions.linkerdVersion = "testinjectversion"
@@ -144,6 +345,7 @@ func TestRunInjectCmd(t *testing.T) {
 		inputFileName        string
 		stdErrGoldenFileName string
 		stdOutGoldenFileName string
+		failIfNoneInjected   bool
 		exitCode             int
 	}{
 		{
@@ -157,6 +359,13 @@ func TestRunInjectCmd(t *testing.T) {
 			stdErrGoldenFileName: "inject_gettest_deployment.good.golden.stderr",
 			exitCode:             0,
 		},
+		{
+			inputFileName:        "inject_emojivoto_istio.input.yml",
+			stdOutGoldenFileName: "inject_emojivoto_istio.input.yml",
+			stdErrGoldenFileName: "inject_emojivoto_istio.fail.golden.stderr",
+			failIfNoneInjected:   true,
+			exitCode:             1,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -169,7 +378,10 @@ func TestRunInjectCmd(t *testing.T) {
 				t.Fatalf("Unexpected error: %v", err)
 			}
 
-			exitCode := runInjectCmd([]io.Reader{in}, errBuffer, outBuffer, testInjectOptions)
+			options := *testInjectOptions
+			options.failIfNoneInjected = tc.failIfNoneInjected
+
+			exitCode := runInjectCmd([]io.Reader{in}, errBuffer, outBuffer, &options)
 			if exitCode != tc.exitCode {
 				t.Fatalf("Expected exit code to be %d but got: %d", tc.exitCode, exitCode)
 			}
@@ -189,6 +401,237 @@ func TestRunInjectCmd(t *testing.T) {
 	}
 }
 
+func TestInjectOptionsValidate(t *testing.T) {
+	t.Run("Accepts ports to skip", func(t *testing.T) {
+		options := newInjectOptions()
+		options.ignoreInboundPorts = []uint{8080}
+		options.ignoreOutboundPorts = []uint{3306, 65535}
+
+		if err := options.validate(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejects invalid proxy resources", func(t *testing.T) {
+		options := newInjectOptions()
+		options.proxyMemoryLimit = "lots"
+
+		if err := options.validate(); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects invalid control plane addresses", func(t *testing.T) {
+		options := newInjectOptions()
+		options.controlPlaneAddress = "proxy-api.linkerd-prod.svc.cluster.local"
+
+		if err := options.validate(); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects invalid trace collectors and propagation formats", func(t *testing.T) {
+		for _, collector := range []string{"oc-collector.tracing", "oc-collector.tracing:http", "oc_collector:55678"} {
+			options := newInjectOptions()
+			options.traceCollector = collector
+
+			if err := options.validate(); err == nil {
+				t.Fatalf("Expected an error for collector %s, got none", collector)
+			}
+		}
+
+		options := newInjectOptions()
+		options.traceCollector = "oc-collector.tracing:55678"
+		options.tracePropagation = "jaeger"
+
+		if err := options.validate(); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects invalid ports to skip", func(t *testing.T) {
+		for _, port := range []uint{0, 65536} {
+			options := newInjectOptions()
+			options.ignoreOutboundPorts = []uint{port}
+
+			if err := options.validate(); err == nil {
+				t.Fatalf("Expected an error for port %d, got none", port)
+			}
+		}
+	})
+}
+
+func TestInjectRestrictedPodSecurity(t *testing.T) {
+	options := newInjectOptions()
+	options.noInitContainer = true
+	options.restrictedPodSecurity = true
+
+	pod := &v1.Pod{}
+	injectPodSpec(&pod.Spec, k8s.TLSIdentity{}, "", options, &injectReport{})
+	injectObjectMeta(&pod.ObjectMeta, nil, options)
+
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Fatalf("Expected no init containers, got %d", len(pod.Spec.InitContainers))
+	}
+	sc := pod.Spec.Containers[0].SecurityContext
+	if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+		t.Fatal("Expected the proxy to run as non-root")
+	}
+	if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+		t.Fatal("Expected the proxy to have a read-only root filesystem")
+	}
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Fatal("Expected the proxy not to allow privilege escalation")
+	}
+	if sc.Capabilities == nil || !reflect.DeepEqual(sc.Capabilities.Drop, []v1.Capability{"ALL"}) {
+		t.Fatalf("Expected the proxy to drop all capabilities, got %v", sc.Capabilities)
+	}
+
+	unstructured, err := withProxySeccompProfile(pod, "/spec/containers")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	proxy := unstructured.(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+	expected := map[string]interface{}{"type": k8s.SeccompProfileTypeRuntimeDefault}
+	if profile := proxy["securityContext"].(map[string]interface{})["seccompProfile"]; !reflect.DeepEqual(profile, expected) {
+		t.Fatalf("Expected the proxy's seccomp profile to be %v, got %v", expected, profile)
+	}
+	if _, ok := pod.Annotations[k8s.ContainerSeccompAnnotationPrefix+k8s.ProxyContainerName]; ok {
+		t.Fatal("Expected the proxy not to set the deprecated seccomp annotation")
+	}
+}
+
+func TestInjectArchitectures(t *testing.T) {
+	archAffinity := func(terms ...[]v1.NodeSelectorRequirement) *v1.Affinity {
+		nodeSelector := &v1.NodeSelector{}
+		for _, expressions := range terms {
+			nodeSelector.NodeSelectorTerms = append(nodeSelector.NodeSelectorTerms, v1.NodeSelectorTerm{MatchExpressions: expressions})
+		}
+		return &v1.Affinity{NodeAffinity: &v1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: nodeSelector}}
+	}
+	archIn := func(values ...string) v1.NodeSelectorRequirement {
+		return v1.NodeSelectorRequirement{Key: k8s.NodeArchLabel, Operator: v1.NodeSelectorOpIn, Values: values}
+	}
+	archNotIn := func(values ...string) v1.NodeSelectorRequirement {
+		return v1.NodeSelectorRequirement{Key: k8s.NodeArchLabel, Operator: v1.NodeSelectorOpNotIn, Values: values}
+	}
+	hostname := v1.NodeSelectorRequirement{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}}
+
+	testCases := []struct {
+		name         string
+		nodeSelector map[string]string
+		affinity     *v1.Affinity
+		unsupported  string
+	}{
+		{"no restriction", nil, nil, ""},
+		{"supported node selector", map[string]string{k8s.NodeArchLabel: "arm64"}, nil, ""},
+		{"supported beta node selector", map[string]string{k8s.NodeArchBetaLabel: "amd64"}, nil, ""},
+		{"unsupported node selector", map[string]string{k8s.NodeArchLabel: "s390x"}, nil, "s390x"},
+		{"unsupported beta node selector", map[string]string{k8s.NodeArchBetaLabel: "ppc64le"}, nil, "ppc64le"},
+		{"affinity without architecture", nil, archAffinity([]v1.NodeSelectorRequirement{hostname}), ""},
+		{"supported affinity", nil, archAffinity([]v1.NodeSelectorRequirement{archIn("s390x", "arm64")}), ""},
+		{"unsupported affinity", nil, archAffinity([]v1.NodeSelectorRequirement{archIn("s390x", "ppc64le")}), "ppc64le or s390x"},
+		{"affinity excluding the supported architectures", nil, archAffinity([]v1.NodeSelectorRequirement{archIn("amd64", "s390x"), archNotIn("amd64")}), "s390x"},
+		{"unsupported affinity term", nil, archAffinity([]v1.NodeSelectorRequirement{archIn("s390x")}, []v1.NodeSelectorRequirement{hostname}), ""},
+		{"unsupported affinity terms", nil, archAffinity([]v1.NodeSelectorRequirement{archIn("s390x")}, []v1.NodeSelectorRequirement{archIn("ppc64le"), hostname}), "ppc64le or s390x"},
+		{"affinity narrowing the node selector", map[string]string{k8s.NodeArchLabel: "amd64"}, archAffinity([]v1.NodeSelectorRequirement{archIn("s390x")}), ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := newInjectOptions()
+			report := &injectReport{}
+
+			pod := &v1.Pod{Spec: v1.PodSpec{NodeSelector: tc.nodeSelector, Affinity: tc.affinity}}
+			injected := injectPodSpec(&pod.Spec, k8s.TLSIdentity{}, "", options, report)
+
+			expectedInjected := tc.unsupported == ""
+			if injected != expectedInjected || report.injected() != expectedInjected {
+				t.Fatalf("Expected injected to be %t, got %t and a report with reason [%s]", expectedInjected, injected, report.skipReason())
+			}
+			if report.unsupportedArch != tc.unsupported {
+				t.Fatalf("Expected unsupported architecture [%s], got [%s]", tc.unsupported, report.unsupportedArch)
+			}
+		})
+	}
+
+	t.Run("Reports the unsupported architecture", func(t *testing.T) {
+		report := injectReport{unsupportedArch: "s390x"}
+		expected := "pods are scheduled on the s390x architecture, which the proxy images aren't published for"
+		if report.skipReason() != expected {
+			t.Fatalf("Expected skip reason [%s], got [%s]", expected, report.skipReason())
+		}
+	})
+}
+
+func TestInjectIdentity(t *testing.T) {
+	options := newInjectOptions()
+	options.tls = identityTLS
+	options.proxyVersion = "identity-dev"
+	options.trustDomain = "prod.example.com"
+	options.clusterDomain = "prod.internal"
+
+	pod := &v1.Pod{}
+	injectPodSpec(&pod.Spec, k8s.TLSIdentity{}, "", options, &injectReport{})
+	injectObjectMeta(&pod.ObjectMeta, nil, options)
+
+	if mode := pod.Annotations[k8s.IdentityModeAnnotation]; mode != k8s.IdentityModeServiceAccount {
+		t.Fatalf("Expected the identity mode to be %s, got %s", k8s.IdentityModeServiceAccount, mode)
+	}
+
+	env := map[string]string{}
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		env[envVar.Name] = envVar.Value
+	}
+	expected := map[string]string{
+		"LINKERD2_PROXY_IDENTITY_LOCAL_NAME":     "$(LINKERD2_PROXY_POD_SERVICE_ACCOUNT).$(LINKERD2_PROXY_POD_NAMESPACE).serviceaccount.identity." + controlPlaneNamespace + ".prod.example.com",
+		"LINKERD2_PROXY_IDENTITY_TOKEN_FILE":     k8s.ServiceAccountTokenPath,
+		"LINKERD2_PROXY_IDENTITY_SVC_ADDR":       "linkerd-identity." + controlPlaneNamespace + ".svc.prod.internal:8083",
+		"LINKERD2_PROXY_IDENTITY_SVC_NAME":       "linkerd-ca." + controlPlaneNamespace + ".serviceaccount.identity." + controlPlaneNamespace + ".prod.example.com",
+		"LINKERD2_PROXY_TLS_CONTROLLER_IDENTITY": "linkerd-controller." + controlPlaneNamespace + ".serviceaccount.identity." + controlPlaneNamespace + ".prod.example.com",
+	}
+	for name, value := range expected {
+		if env[name] != value {
+			t.Fatalf("Expected %s to be [%s], got [%s]", name, value, env[name])
+		}
+	}
+	if _, ok := env["LINKERD2_PROXY_TLS_CERT"]; ok {
+		t.Fatal("Expected the proxy not to be configured with a certificate file")
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == k8s.TLSSecretsVolumeName {
+			t.Fatalf("Expected the pod not to mount the %s volume", k8s.TLSSecretsVolumeName)
+		}
+	}
+}
+
+func TestSetFlagsFromInstallConfig(t *testing.T) {
+	cmd := &cobra.Command{}
+	options := newInjectOptions()
+	addProxyConfigFlags(cmd, options.proxyConfigOptions)
+	if err := cmd.ParseFlags([]string{"--registry", "registry.example.com/linkerd"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	installConfigMap := &v1.ConfigMap{Data: map[string]string{
+		installVersionKey: "stable-2.0.0",
+		installValuesKey:  "ha: \"true\"\nregistry: gcr.io/example\ntls: optional\ntrust-domain: example.com\n",
+	}}
+	if err := setFlagsFromInstallConfig(cmd.Flags(), installConfigMap); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if options.linkerdVersion != "stable-2.0.0" {
+		t.Fatalf("Expected version stable-2.0.0, got %s", options.linkerdVersion)
+	}
+	if options.dockerRegistry != "registry.example.com/linkerd" {
+		t.Fatalf("Expected the registry of the command line, got %s", options.dockerRegistry)
+	}
+	if !options.enableTLS() || options.trustDomain != "example.com" {
+		t.Fatalf("Expected TLS with the example.com trust domain, got %q and %s", options.tls, options.trustDomain)
+	}
+}
+
 func TestInjectFilePath(t *testing.T) {
 	var (
 		resourceFolder = filepath.Join("testdata", "inject-filepath", "resources")
@@ -265,6 +708,31 @@ func TestInjectFilePath(t *testing.T) {
 			t.Errorf("Result mismatch.\nExpected: %s\nActual: %s", stdErr, errBuf.String())
 		}
 	})
+
+	t.Run("read from URL", func(t *testing.T) {
+		server := httptest.NewServer(http.FileServer(http.Dir(resourceFolder)))
+		defer server.Close()
+
+		in, err := read(server.URL + "/nginx.yaml")
+		if err != nil {
+			t.Fatal("Unexpected error: ", err)
+		}
+
+		errBuf := &bytes.Buffer{}
+		actual := &bytes.Buffer{}
+		if exitCode := runInjectCmd(in, errBuf, actual, options); exitCode != 0 {
+			t.Fatal("Unexpected error. Exit code from runInjectCmd: ", exitCode)
+		}
+
+		expected := readOptionalTestFile(t, filepath.Join(expectedFolder, "injected_nginx.yaml"))
+		if expected != actual.String() {
+			t.Errorf("Result mismatch.\nExpected: %s\nActual: %s", expected, actual.String())
+		}
+
+		if _, err := read(server.URL + "/missing.yaml"); err == nil {
+			t.Fatal("Expected an error reading a missing URL, got none")
+		}
+	})
 }
 
 func TestWalk(t *testing.T) {
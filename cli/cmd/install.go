@@ -1,18 +1,37 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"text/template"
+	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/linkerd/linkerd2/cli/install"
+	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/controller/grafana"
+	injector "github.com/linkerd/linkerd2/controller/proxy-injector"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/prometheus"
 	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 type installConfig struct {
@@ -26,33 +45,241 @@ type installConfig struct {
 	PrometheusReplicas          uint
 	ImagePullPolicy             string
 	UUID                        string
+	LinkerdVersion              string
 	CliVersion                  string
 	ControllerLogLevel          string
+	LatencyBuckets              string
 	ControllerComponentLabel    string
 	CreatedByAnnotation         string
+	ProxyInjectAnnotation       string
+	ProxyInjectDisabled         string
 	ProxyAPIPort                uint
 	EnableTLS                   bool
+	EnableIdentity              bool
+	IdentityServiceName         string
+	IdentityServicePort         uint
+	TLSMode                     string
 	TLSTrustAnchorConfigMapName string
 	ProxyContainerName          string
+	TrustDomain                 string
+	TLSFederationConfigMapName  string
+	FederatedTrustAnchors       string
+	IdentityIssuerSecret        string
+	IdentityIssuerCertificate   string
+	IdentityIssuerKey           string
+	IdentityTrustAnchors        string
+	TapRBAC                     bool
+	TapAPIServerTLSCert         string
+	TapAPIServerTLSKey          string
+	TapAPIServerCABundle        string
+	APIRBAC                     bool
+	MetricsAdapter              bool
+	MetricsAdapterTLSCert       string
+	MetricsAdapterTLSKey        string
+	MetricsAdapterCABundle      string
+	ProxyAutoInject             bool
+	ProxyInjectorTLSCert        string
+	ProxyInjectorTLSKey         string
+	ProxyInjectorCABundle       string
+	ProxyInjectorSidecarConfig  string
+	ProfileValidation           bool
+	ProfileValidatorTLSCert     string
+	ProfileValidatorTLSKey      string
+	ProfileValidatorCABundle    string
+	WebhookAPIVersion           string
+	EnableHA                    bool
+	DockerRegistry              string
+	ProxyImage                  string
+	ProxyInitImage              string
+	InstallConfigMapName        string
+	PrometheusURL               string
+	ExternalPrometheus          bool
+	PrometheusQueryTimeout      string
+	PrometheusMaxSamples        int
+	PrometheusRemoteWriteURLs   []string
+	PrometheusExternalLabels    map[string]string
+	GrafanaURL                  string
+	ExternalGrafana             bool
+	GrafanaProvisionerSecret    string
+	EnforcedHost                string
+	DashboardPathPrefix         string
+	InstallValues               string
+	WatchNamespaces             string
+	WatchNamespaceList          []string
+	RestrictedPodSecurity       bool
+	ControlPlaneUID             int64
+	ControllerResources         *resources
+	WebResources                *resources
+	PrometheusResources         *resources
+	GrafanaResources            *resources
+	ControllerScheduling        *scheduling
+	WebScheduling               *scheduling
+	PrometheusScheduling        *scheduling
+	GrafanaScheduling           *scheduling
+	ProxyArchitectures          string
+}
+
+// identityIssuerFiles are the paths of the PEM files with the issuer
+// credentials of the CA and the trust anchors they chain to, which are
+// provided at install time instead of being generated by the CA.
+type identityIssuerFiles struct {
+	TrustAnchors string
+	Certificate  string
+	Key          string
+}
+
+// resources are the CPU and memory requests and limits of the containers of a
+// control plane component.
+type resources struct {
+	CPURequest    string
+	MemoryRequest string
+	CPULimit      string
+	MemoryLimit   string
+}
+
+// scheduling are the node selector and tolerations of the pods of a control
+// plane component, e.g. to run them on the nodes of a given architecture or
+// on dedicated, tainted nodes.
+type scheduling struct {
+	NodeSelector map[string]string
+	Tolerations  []v1.Toleration
+}
+
+// schedulingOptions are the node selector, as key=value pairs, and the
+// tolerations, as key[=value][:effect] taints, of the pods of a control plane
+// component.
+type schedulingOptions struct {
+	nodeSelector []string
+	tolerations  []string
 }
 
 type installOptions struct {
-	controllerReplicas uint
-	webReplicas        uint
-	prometheusReplicas uint
-	controllerLogLevel string
+	controllerReplicas    uint
+	webReplicas           uint
+	prometheusReplicas    uint
+	controllerLogLevel    string
+	latencyBuckets        string
+	federatedTrustAnchors string
+	identityIssuerSecret  string
+	identityIssuerFiles   identityIssuerFiles
+	tlsMode               string
+	tapRBAC               bool
+	apiRBAC               bool
+	metricsAdapter        bool
+	proxyAutoInject       bool
+	profileValidation     bool
+	highAvailability      bool
+	controllerImage       string
+	webImage              string
+	prometheusImage       string
+	grafanaImage          string
+	prometheusURL         string
+	promQueryTimeout      time.Duration
+	promMaxSamples        int
+	remoteWriteURLs       []string
+	externalLabels        []string
+	grafanaURL            string
+	enforcedHost          string
+	dashboardPathPrefix   string
+	valuesFile            string
+	watchNamespaces       []string
+	controllerResources   resources
+	webResources          resources
+	prometheusResources   resources
+	grafanaResources      resources
+	controllerScheduling  schedulingOptions
+	webScheduling         schedulingOptions
+	prometheusScheduling  schedulingOptions
+	grafanaScheduling     schedulingOptions
+	diff                  bool
 	*proxyConfigOptions
 }
 
-const prometheusProxyOutboundCapacity = 10000
+const (
+	prometheusProxyOutboundCapacity = 10000
+
+	// configStage and controlPlaneStage are the stages in which Linkerd can be
+	// installed: the cluster-scoped and RBAC resources that require cluster
+	// admin privileges, then the control plane in its namespace.
+	configStage       = "config"
+	controlPlaneStage = "control-plane"
+
+	// haMinReplicas is the minimum number of replicas of the controller and
+	// web deployments in high availability mode, which are spread across nodes
+	// so that the control plane survives node failures.
+	haMinReplicas = 3
+
+	// defaultWebhookAPIVersion is the version of the admissionregistration.k8s.io
+	// API that the proxy injector's webhook configuration is rendered with
+	// when the cluster's capabilities aren't known.
+	defaultWebhookAPIVersion = "v1beta1"
+
+	// haProxyCPURequest and haProxyMemoryRequest are the resources requested
+	// by the proxies of the control plane in high availability mode, unless
+	// overridden.
+	haProxyCPURequest    = "10m"
+	haProxyMemoryRequest = "20Mi"
+
+	// haCPURequest and haMemoryRequest are the resources requested by the
+	// control plane components in high availability mode, unless overridden,
+	// and haPrometheusCPURequest and haPrometheusMemoryRequest those requested
+	// by Prometheus.
+	haCPURequest              = "20m"
+	haMemoryRequest           = "50Mi"
+	haPrometheusCPURequest    = "300m"
+	haPrometheusMemoryRequest = "300Mi"
+
+	// controlPlaneUID is the user ID that the control plane components run
+	// as when installed with --restricted-pod-security.
+	controlPlaneUID = 2103
+
+	// grafanaProvisionerSecret is the name of the Secret with the API key
+	// that the Linkerd dashboards are provisioned to an existing Grafana
+	// server with, under the api-key key.
+	grafanaProvisionerSecret = "linkerd-grafana-provisioner"
+)
 
 func newInstallOptions() *installOptions {
 	return &installOptions{
-		controllerReplicas: 1,
-		webReplicas:        1,
-		prometheusReplicas: 1,
-		controllerLogLevel: "info",
-		proxyConfigOptions: newProxyConfigOptions(),
+		controllerReplicas:    1,
+		webReplicas:           1,
+		prometheusReplicas:    1,
+		controllerLogLevel:    "info",
+		latencyBuckets:        "",
+		federatedTrustAnchors: "",
+		identityIssuerSecret:  "",
+		identityIssuerFiles:   identityIssuerFiles{},
+		tlsMode:               k8s.TLSModePermissive,
+		tapRBAC:               false,
+		apiRBAC:               false,
+		metricsAdapter:        false,
+		proxyAutoInject:       false,
+		profileValidation:     false,
+		highAvailability:      false,
+		controllerImage:       defaultDockerRegistry + "/controller",
+		webImage:              defaultDockerRegistry + "/web",
+		prometheusImage:       "prom/prometheus:v2.4.0",
+		grafanaImage:          defaultDockerRegistry + "/grafana",
+		prometheusURL:         "",
+		promQueryTimeout:      30 * time.Second,
+		promMaxSamples:        0,
+		remoteWriteURLs:       nil,
+		externalLabels:        nil,
+		grafanaURL:            "",
+		enforcedHost:          "",
+		dashboardPathPrefix:   "",
+		valuesFile:            "",
+		watchNamespaces:       nil,
+		controllerResources:   resources{},
+		webResources:          resources{},
+		prometheusResources:   resources{},
+		grafanaResources:      resources{},
+		controllerScheduling:  schedulingOptions{},
+		webScheduling:         schedulingOptions{},
+		prometheusScheduling:  schedulingOptions{},
+		grafanaScheduling:     schedulingOptions{},
+		diff:                  false,
+		proxyConfigOptions:    newProxyConfigOptions(),
 	}
 }
 
@@ -62,54 +289,763 @@ func newCmdInstall() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install [flags]",
 		Short: "Output Kubernetes configs to install Linkerd",
-		Long:  "Output Kubernetes configs to install Linkerd.",
+		Long: `Output Kubernetes configs to install Linkerd.
+
+The configuration can be read from a YAML values file, whose keys are the
+names of the flags of this command. Flags given on the command line take
+precedence over the values file.
+
+The install can be split in two stages, so that cluster admins apply the
+privileged configs and application teams the control plane itself; both stages
+must be given the same flags.
+
+With --restricted-pod-security, the control plane runs as a non-root user with
+read-only root filesystems, no privilege escalation, no capabilities and the
+runtime's default seccomp profile, so that it's admitted in a namespace that
+enforces the restricted pod security level. The proxy-init container can't
+run at that level, so the linkerd CNI plugin must be installed first. The
+seccomp profile is set with the securityContext.seccompProfile field, which
+requires Kubernetes 1.19 or later.
+
+With --tls identity, the CA serves the identity service, which certifies each
+proxy for the identity of its pod's ServiceAccount, authenticated by the
+ServiceAccount's token. The certificates are short-lived, and all traffic
+between meshed pods is mutually authenticated with them. The proxy released
+along with the control plane isn't certified by the identity service, so the
+tag of a proxy image that is must be given with --proxy-version. The CA isn't
+meshed, and serves the identity service over TLS with a certificate it issues
+for itself.
+
+The CA generates its own issuer certificate, unless one is provided with
+--identity-issuer-certificate-file and --identity-issuer-key-file, along with
+the trust anchors it chains to, or is maintained in a Secret by an external
+system such as cert-manager, with --identity-issuer-secret.
+
+With --tls-mode strict, the proxy injector only admits the pods it injects if
+their proxies are certified by the identity service, so that they have an mTLS
+identity. The proxies themselves still accept plaintext inbound connections.
+The mode of a namespace can be overridden with its linkerd.io/tls-mode
+annotation.
+
+With --image-lock-file, all the images are pinned by the digests of the lock
+file, and the configs are rendered without any network access, as required by
+air-gapped clusters. Use 'linkerd check --pre --image-lock-file' to check that
+the pinned images can be pulled from the mirror that they're listed with.`,
+		Example: `  # Install Linkerd with the configuration checked into values.yaml,
+  # which contains e.g.:
+  #   registry: registry.example.com/linkerd
+  #   controller-replicas: 3
+  #   proxy-log-level: debug
+  #   prometheus-memory-limit: 2Gi
+  linkerd install -f values.yaml | kubectl apply -f -
+
+  # Preview the changes that installing Linkerd would make to the cluster.
+  linkerd install --diff
+
+  # Install Linkerd in two stages.
+  linkerd install config | kubectl apply -f -
+  linkerd install control-plane | kubectl apply -f -
+
+  # Install Linkerd from a mirror, with the images pinned by the digests of
+  # images.lock, which contains e.g.:
+  #   images:
+  #     registry.example.com/linkerd/proxy:stable-2.1.0: sha256:...
+  linkerd install --registry registry.example.com/linkerd --image-lock-file images.lock | kubectl apply -f -
+
+  # Install Linkerd at the restricted pod security level.
+  linkerd install --linkerd-cni-enabled --restricted-pod-security | kubectl apply -f -
+
+  # Ship the metrics of the installed Prometheus to a long-term store,
+  # labeled with the name of the cluster.
+  linkerd install --prometheus-remote-write-urls https://metrics.example.com/api/v1/write --prometheus-external-labels cluster=prod | kubectl apply -f -`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			config, err := validateAndBuildConfig(options)
-			if err != nil {
-				return err
-			}
+			return runInstall(cmd.PersistentFlags(), options, "")
+		},
+	}
 
-			return render(*config, os.Stdout, options)
+	addInstallFlags(cmd, options)
+	cmd.AddCommand(newCmdInstallStage(configStage, "Output the namespace, RBAC and webhook configs of Linkerd, which require cluster admin privileges", cmd, options))
+	cmd.AddCommand(newCmdInstallStage(controlPlaneStage, "Output the configs of the Linkerd control plane, once the config stage is installed", cmd, options))
+	cmd.AddCommand(newCmdInstallHelmChart(cmd, options))
+
+	return cmd
+}
+
+// newCmdInstallStage returns a subcommand of install that only outputs the
+// configs of a stage. The flags of the install command are shared by the
+// stages.
+func newCmdInstallStage(stage, short string, installCmd *cobra.Command, options *installOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   stage + " [flags]",
+		Short: short,
+		Long:  short + ".",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInstall(installCmd.PersistentFlags(), options, stage)
 		},
 	}
+}
+
+// runInstall outputs the configs of a stage of the install, or of the whole
+// install if stage is empty.
+func runInstall(flags *pflag.FlagSet, options *installOptions, stage string) error {
+	if options.valuesFile != "" {
+		if err := setFlagsFromValuesFile(flags, options.valuesFile); err != nil {
+			return err
+		}
+	}
+
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		return err
+	}
+	config.InstallValues, err = installValues(flags)
+	if err != nil {
+		return err
+	}
+	// air-gapped installs, pinned by an image lock file, are rendered without
+	// any network access, with the webhook configurations that every
+	// supported version of Kubernetes serves
+	if options.imageLockFile == "" {
+		if err := applyClusterCapabilities(config); err != nil {
+			return err
+		}
+	}
+
+	if options.diff {
+		buf := &bytes.Buffer{}
+		if err := renderStage(*config, buf, options, stage); err != nil {
+			return err
+		}
+		return diffWithCluster(buf.Bytes(), os.Stdout)
+	}
+
+	if err := renderStage(*config, os.Stdout, options, stage); err != nil {
+		return err
+	}
+	if stage == configStage {
+		return nil
+	}
+	return renderExternalConfigs(*config, os.Stderr)
+}
 
+// applyClusterCapabilities renders the webhook configurations of the proxy
+// injector and ServiceProfile validator with the most recent version of the
+// admissionregistration.k8s.io API served by the cluster, and checks that the
+// cluster sets seccomp profiles with the securityContext.seccompProfile field
+// that --restricted-pod-security renders. If the cluster can't be reached, the
+// manifests are rendered with the versions that every supported version of
+// Kubernetes serves.
+func applyClusterCapabilities(config *installConfig) error {
+	if !config.ProxyAutoInject && !config.ProfileValidation && !config.RestrictedPodSecurity {
+		return nil
+	}
+
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		log.Debugf("not detecting the capabilities of the cluster: %s", err)
+		return nil
+	}
+	// the install doesn't wait for clusters that can't be reached
+	kubeAPI.Backoff = k8s.Backoff{}
+	client, err := kubeAPI.NewClient()
+	if err != nil {
+		log.Debugf("not detecting the capabilities of the cluster: %s", err)
+		return nil
+	}
+	capabilities, err := kubeAPI.GetCapabilities(client)
+	if err != nil {
+		log.Debugf("not detecting the capabilities of the cluster: %s", err)
+		return nil
+	}
+
+	if config.RestrictedPodSecurity && !capabilities.HasSeccompProfileField() {
+		return fmt.Errorf("--restricted-pod-security requires Kubernetes 1.19 or later, which sets seccomp profiles with the securityContext.seccompProfile field")
+	}
+	if !config.ProxyAutoInject && !config.ProfileValidation {
+		return nil
+	}
+
+	version := capabilities.AdmissionRegistrationVersion()
+	if version == "" {
+		return fmt.Errorf("--proxy-auto-inject and --profile-validation require the admissionregistration.k8s.io API, which the cluster doesn't serve")
+	}
+	config.WebhookAPIVersion = version
+	return nil
+}
+
+// addInstallFlags adds the flags of the install command, which the upgrade
+// command shares.
+func addInstallFlags(cmd *cobra.Command, options *installOptions) {
 	addProxyConfigFlags(cmd, options.proxyConfigOptions)
 	cmd.PersistentFlags().UintVar(&options.controllerReplicas, "controller-replicas", options.controllerReplicas, "Replicas of the controller to deploy")
 	cmd.PersistentFlags().UintVar(&options.webReplicas, "web-replicas", options.webReplicas, "Replicas of the web server to deploy")
 	cmd.PersistentFlags().UintVar(&options.prometheusReplicas, "prometheus-replicas", options.prometheusReplicas, "Replicas of prometheus to deploy")
 	cmd.PersistentFlags().StringVar(&options.controllerLogLevel, "controller-log-level", options.controllerLogLevel, "Log level for the controller and web components")
+	cmd.PersistentFlags().StringVar(&options.latencyBuckets, "latency-buckets", options.latencyBuckets, "Comma-separated upper bounds, in milliseconds, of the latency histogram buckets of the control plane's metrics, from which the latency quantiles of the proxies' metrics are computed as well; the default buckets are used if empty")
+	cmd.PersistentFlags().StringVar(&options.federatedTrustAnchors, "federated-trust-anchors", options.federatedTrustAnchors, "Path to a PEM file with the trust anchors of other trust domains whose identities should be accepted by meshed pods (requires --tls)")
+	cmd.PersistentFlags().StringVar(&options.tlsMode, "tls-mode", options.tlsMode, fmt.Sprintf("Whether the proxy injector rejects the pods it injects unless their proxies are certified by the identity service (%s) or admits them (%s), unless overridden by the %s annotation of their namespace (requires --tls=%s)", k8s.TLSModeStrict, k8s.TLSModePermissive, k8s.TLSModeAnnotation, identityTLS))
+	cmd.PersistentFlags().StringVar(&options.identityIssuerSecret, "identity-issuer-secret", options.identityIssuerSecret, "Name of a Secret in the control plane's namespace with the issuer certificate, private key and trust anchors of the CA, maintained by an external system such as cert-manager; the CA reloads them whenever the Secret changes (requires --tls)")
+	cmd.PersistentFlags().StringVar(&options.identityIssuerFiles.TrustAnchors, "identity-trust-anchors-file", options.identityIssuerFiles.TrustAnchors, "Path to a PEM file with the trust anchors that the issuer certificate chains to; the issuer certificate must be self-signed if omitted (requires --identity-issuer-certificate-file)")
+	cmd.PersistentFlags().StringVar(&options.identityIssuerFiles.Certificate, "identity-issuer-certificate-file", options.identityIssuerFiles.Certificate, "Path to a PEM file with the certificate that the CA issues certificates with, followed by its chain to the trust anchors, instead of a generated one (requires --tls and --identity-issuer-key-file)")
+	cmd.PersistentFlags().StringVar(&options.identityIssuerFiles.Key, "identity-issuer-key-file", options.identityIssuerFiles.Key, "Path to a PEM file with the ECDSA P-256 private key of the issuer certificate (requires --identity-issuer-certificate-file)")
+	cmd.PersistentFlags().BoolVar(&options.tapRBAC, "tap-rbac", options.tapRBAC, "Serve tap through the Kubernetes API server, and only allow users to tap namespaces in which they are granted the linkerd-<namespace>-tap ClusterRole (experimental)")
+	cmd.PersistentFlags().BoolVar(&options.apiRBAC, "api-rbac", options.apiRBAC, "Require the callers of the public API to bear a Kubernetes token, and only allow them to query namespaces in which they can list pods, and to tap namespaces in which they are granted the linkerd-<namespace>-tap ClusterRole (experimental)")
+	cmd.PersistentFlags().BoolVar(&options.metricsAdapter, "metrics-adapter", options.metricsAdapter, "Serve the request rate and latency of meshed deployments and pods through the Kubernetes custom metrics API, so that HorizontalPodAutoscalers can scale on them (experimental)")
+	cmd.PersistentFlags().StringVar(&options.controllerImage, "controller-image", options.controllerImage, "Controller image name, with an optional tag that defaults to --linkerd-version")
+	cmd.PersistentFlags().StringVar(&options.webImage, "web-image", options.webImage, "Web image name, with an optional tag that defaults to --linkerd-version")
+	cmd.PersistentFlags().StringVar(&options.prometheusImage, "prometheus-image", options.prometheusImage, "Prometheus image name, with an optional tag that defaults to --linkerd-version")
+	cmd.PersistentFlags().StringVar(&options.grafanaImage, "grafana-image", options.grafanaImage, "Grafana image name, with an optional tag that defaults to --linkerd-version")
+	cmd.PersistentFlags().StringVar(&options.prometheusURL, "prometheus-url", options.prometheusURL, "URL of an existing Prometheus server to query instead of installing one; the scrape configs it needs are printed to stderr")
+	cmd.PersistentFlags().DurationVar(&options.promQueryTimeout, "prometheus-query-timeout", options.promQueryTimeout, "Maximum duration of each query of the public API to Prometheus, after which the query fails with a timeout error; 0 for no timeout")
+	cmd.PersistentFlags().IntVar(&options.promMaxSamples, "prometheus-max-samples", options.promMaxSamples, "Maximum number of samples that a query of the public API to Prometheus may return; 0 for no maximum")
+	cmd.PersistentFlags().StringSliceVar(&options.remoteWriteURLs, "prometheus-remote-write-urls", options.remoteWriteURLs, "URLs of the remote storage endpoints that the installed Prometheus sends its samples to, such as a long-term metrics store")
+	cmd.PersistentFlags().StringSliceVar(&options.externalLabels, "prometheus-external-labels", options.externalLabels, "Labels, as name=value pairs, that the installed Prometheus adds to the samples sent to remote storage or federated Prometheus servers, such as the name of the cluster")
+	cmd.PersistentFlags().StringVar(&options.grafanaURL, "grafana-url", options.grafanaURL, fmt.Sprintf("URL of an existing Grafana server to provision the Linkerd dashboards and Prometheus data source to instead of installing one, with the API key of the optional %s Secret", grafanaProvisionerSecret))
+	cmd.PersistentFlags().StringVar(&options.enforcedHost, "enforced-host", options.enforcedHost, "Regexp of the additional hosts at which the dashboard is served, e.g. the host of an ingress; the dashboard rejects the requests for hosts other than localhost, IP addresses and the web service, to prevent DNS rebinding attacks")
+	cmd.PersistentFlags().StringVar(&options.dashboardPathPrefix, "dashboard-path-prefix", options.dashboardPathPrefix, "Path under which the dashboard and the Grafana it proxies are served, e.g. \"/linkerd\" behind an ingress; Grafana is then no longer served through kubectl proxy")
+	cmd.PersistentFlags().BoolVar(&options.highAvailability, "ha", options.highAvailability, fmt.Sprintf("Run at least %d replicas of the controller and web components, spread across nodes, with disruption budgets and resource requests; the CA always runs a single replica", haMinReplicas))
+	cmd.PersistentFlags().BoolVar(&options.proxyAutoInject, "proxy-auto-inject", options.proxyAutoInject, "Inject the proxy into the pods created in namespaces, or with pod annotations, that set linkerd.io/inject to enabled (experimental)")
+	cmd.PersistentFlags().BoolVar(&options.profileValidation, "profile-validation", options.profileValidation, "Reject the ServiceProfiles with invalid route regexes, duplicate routes, or malformed timeouts or retry budgets when they're applied, instead of letting the proxies ignore them (experimental)")
+	cmd.PersistentFlags().StringSliceVar(&options.watchNamespaces, "watch-namespaces", options.watchNamespaces, "Namespaces to which the control plane is restricted, with Roles in each of them instead of ClusterRoles; the control plane's namespace is always included")
+	addResourcesFlags(cmd, &options.controllerResources, "controller", "the controller containers")
+	addResourcesFlags(cmd, &options.webResources, "web", "the web container")
+	addResourcesFlags(cmd, &options.prometheusResources, "prometheus", "the Prometheus container")
+	addResourcesFlags(cmd, &options.grafanaResources, "grafana", "the Grafana container")
+	addSchedulingFlags(cmd, &options.controllerScheduling, "controller", "the controller, CA, proxy injector and ServiceProfile validator pods")
+	addSchedulingFlags(cmd, &options.webScheduling, "web", "the web pods")
+	addSchedulingFlags(cmd, &options.prometheusScheduling, "prometheus", "the Prometheus pods")
+	addSchedulingFlags(cmd, &options.grafanaScheduling, "grafana", "the Grafana pods")
+	cmd.PersistentFlags().BoolVar(&options.diff, "diff", options.diff, "Show the differences between the configs and the objects in the cluster instead of outputting the configs")
+	cmd.PersistentFlags().StringVarP(&options.valuesFile, "values", "f", options.valuesFile, "Path to a YAML file that sets the values of the flags of this command")
+}
 
-	return cmd
+// addResourcesFlags adds the flags that set the resources of the containers of
+// a control plane component, e.g. --controller-cpu-request.
+func addResourcesFlags(cmd *cobra.Command, r *resources, component, containers string) {
+	cmd.PersistentFlags().StringVar(&r.CPURequest, component+"-cpu-request", r.CPURequest, fmt.Sprintf("Amount of CPU units that %s request", containers))
+	cmd.PersistentFlags().StringVar(&r.MemoryRequest, component+"-memory-request", r.MemoryRequest, fmt.Sprintf("Amount of memory that %s request", containers))
+	cmd.PersistentFlags().StringVar(&r.CPULimit, component+"-cpu-limit", r.CPULimit, fmt.Sprintf("Maximum amount of CPU units that %s can use", containers))
+	cmd.PersistentFlags().StringVar(&r.MemoryLimit, component+"-memory-limit", r.MemoryLimit, fmt.Sprintf("Maximum amount of memory that %s can use", containers))
+}
+
+// addSchedulingFlags adds the flags that set the node selector and tolerations
+// of the pods of a control plane component, e.g. --controller-node-selector.
+func addSchedulingFlags(cmd *cobra.Command, s *schedulingOptions, component, pods string) {
+	cmd.PersistentFlags().StringSliceVar(&s.nodeSelector, component+"-node-selector", s.nodeSelector, fmt.Sprintf("Labels, as key=value pairs, of the nodes that %s are scheduled on, e.g. kubernetes.io/arch=arm64", pods))
+	cmd.PersistentFlags().StringSliceVar(&s.tolerations, component+"-tolerations", s.tolerations, fmt.Sprintf("Taints, as key[=value][:effect], of the nodes that %s tolerate; all the effects of a taint are tolerated if none is given", pods))
+}
+
+// setFlagsFromValuesFile sets the flags that weren't given on the command line
+// to the values of a YAML file, whose keys are flag names. Lists are joined
+// with commas, like the values of flags that can be repeated.
+func setFlagsFromValuesFile(flags *pflag.FlagSet, path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return setFlagsFromValues(flags, b, path)
+}
+
+// setFlagsFromValues sets the flags that weren't given on the command line to
+// the values of a YAML document read from source.
+func setFlagsFromValues(flags *pflag.FlagSet, b []byte, source string) error {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("failed to parse %s: %s", source, err)
+	}
+
+	// set the flags in a stable order, so that errors are deterministic
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "values" {
+			return fmt.Errorf("%s sets an unknown flag: %s", source, name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		var value string
+		switch v := values[name].(type) {
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		case map[string]interface{}:
+			return fmt.Errorf("%s sets %s to a map, which isn't a valid flag value", source, name)
+		default:
+			value = fmt.Sprint(v)
+		}
+
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("%s sets an invalid value for %s: %s", source, name, err)
+		}
+	}
+
+	return nil
+}
+
+// installValues returns the flags that were set when installing, in the format
+// of a values file, so that they can be recorded in the install config and
+// carried over by upgrades. The version, which upgrades change, and the paths
+// of files, which only exist where the CLI ran, aren't recorded.
+func installValues(flags *pflag.FlagSet) (string, error) {
+	values := map[string]string{}
+	flags.Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "linkerd-version", "values", "federated-trust-anchors", "identity-trust-anchors-file", "identity-issuer-certificate-file", "identity-issuer-key-file", "image-lock-file", "diff":
+			return
+		}
+		value := flag.Value.String()
+		if strings.HasSuffix(flag.Value.Type(), "Slice") {
+			// slices are printed as [a,b], but set as a,b
+			value = strings.Trim(value, "[]")
+		}
+		values[flag.Name] = value
+	})
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	b, err := yaml.Marshal(values)
+	return string(b), err
 }
 
 func validateAndBuildConfig(options *installOptions) (*installConfig, error) {
 	if err := validate(options); err != nil {
 		return nil, err
 	}
-	return &installConfig{
+
+	if options.highAvailability {
+		applyHADefaults(options)
+	}
+
+	if err := options.readImageLock(); err != nil {
+		return nil, err
+	}
+
+	federatedTrustAnchors := ""
+	if options.federatedTrustAnchors != "" {
+		content, err := ioutil.ReadFile(options.federatedTrustAnchors)
+		if err != nil {
+			return nil, err
+		}
+		federatedTrustAnchors = string(content)
+		if err := ca.ValidateTrustAnchorsPEM(federatedTrustAnchors); err != nil {
+			return nil, fmt.Errorf("--federated-trust-anchors is invalid: %s", err)
+		}
+	}
+
+	config := &installConfig{
 		Namespace:                   controlPlaneNamespace,
-		ControllerImage:             fmt.Sprintf("%s/controller:%s", options.dockerRegistry, options.linkerdVersion),
-		WebImage:                    fmt.Sprintf("%s/web:%s", options.dockerRegistry, options.linkerdVersion),
-		PrometheusImage:             "prom/prometheus:v2.4.0",
-		GrafanaImage:                fmt.Sprintf("%s/grafana:%s", options.dockerRegistry, options.linkerdVersion),
+		ControllerImage:             options.taggedImage(options.controllerImage),
+		WebImage:                    options.taggedImage(options.webImage),
+		PrometheusImage:             options.taggedImage(options.prometheusImage),
+		GrafanaImage:                options.taggedImage(options.grafanaImage),
 		ControllerReplicas:          options.controllerReplicas,
 		WebReplicas:                 options.webReplicas,
 		PrometheusReplicas:          options.prometheusReplicas,
 		ImagePullPolicy:             options.imagePullPolicy,
 		UUID:                        uuid.NewV4().String(),
+		LinkerdVersion:              options.linkerdVersion,
 		CliVersion:                  k8s.CreatedByAnnotationValue(),
 		ControllerLogLevel:          options.controllerLogLevel,
+		LatencyBuckets:              options.latencyBuckets,
 		ControllerComponentLabel:    k8s.ControllerComponentLabel,
 		CreatedByAnnotation:         k8s.CreatedByAnnotation,
+		ProxyInjectAnnotation:       k8s.ProxyInjectAnnotation,
+		ProxyInjectDisabled:         k8s.ProxyInjectDisabled,
 		ProxyAPIPort:                options.proxyAPIPort,
 		EnableTLS:                   options.enableTLS(),
+		EnableIdentity:              options.enableIdentity(),
+		IdentityServiceName:         k8s.IdentityServiceName,
+		IdentityServicePort:         k8s.IdentityServicePort,
+		TLSMode:                     options.tlsMode,
 		TLSTrustAnchorConfigMapName: k8s.TLSTrustAnchorConfigMapName,
 		ProxyContainerName:          k8s.ProxyContainerName,
-	}, nil
+		TrustDomain:                 options.trustDomain,
+		TLSFederationConfigMapName:  k8s.TLSFederationConfigMapName,
+		FederatedTrustAnchors:       federatedTrustAnchors,
+		IdentityIssuerSecret:        options.identityIssuerSecret,
+		TapRBAC:                     options.tapRBAC,
+		APIRBAC:                     options.apiRBAC,
+		MetricsAdapter:              options.metricsAdapter,
+		ProxyAutoInject:             options.proxyAutoInject,
+		ProfileValidation:           options.profileValidation,
+		WebhookAPIVersion:           defaultWebhookAPIVersion,
+		EnableHA:                    options.highAvailability,
+		DockerRegistry:              options.dockerRegistry,
+		ProxyImage:                  options.taggedProxyImage(),
+		ProxyInitImage:              options.taggedProxyInitImage(),
+		InstallConfigMapName:        k8s.InstallConfigMapName,
+		PrometheusURL:               fmt.Sprintf("http://prometheus.%s.svc.cluster.local:9090", controlPlaneNamespace),
+		ExternalPrometheus:          options.prometheusURL != "",
+		PrometheusQueryTimeout:      options.promQueryTimeout.String(),
+		PrometheusMaxSamples:        options.promMaxSamples,
+		PrometheusRemoteWriteURLs:   options.remoteWriteURLs,
+		PrometheusExternalLabels:    parseExternalLabels(options.externalLabels),
+		GrafanaURL:                  fmt.Sprintf("http://grafana.%s.svc.cluster.local:3000", controlPlaneNamespace),
+		ExternalGrafana:             options.grafanaURL != "",
+		GrafanaProvisionerSecret:    grafanaProvisionerSecret,
+		EnforcedHost:                options.enforcedHost,
+		DashboardPathPrefix:         strings.TrimSuffix("/"+strings.Trim(options.dashboardPathPrefix, "/"), "/"),
+		RestrictedPodSecurity:       options.restrictedPodSecurity,
+		ControlPlaneUID:             controlPlaneUID,
+		ControllerResources:         options.controllerResources.orNil(),
+		WebResources:                options.webResources.orNil(),
+		PrometheusResources:         options.prometheusResources.orNil(),
+		GrafanaResources:            options.grafanaResources.orNil(),
+		ControllerScheduling:        options.controllerScheduling.orNil(),
+		WebScheduling:               options.webScheduling.orNil(),
+		PrometheusScheduling:        options.prometheusScheduling.orNil(),
+		GrafanaScheduling:           options.grafanaScheduling.orNil(),
+		ProxyArchitectures:          strings.Join(options.proxyArchitectures, ","),
+	}
+
+	images := append(options.proxyImages(), config.ControllerImage, config.WebImage, config.GrafanaImage)
+	if config.ExternalPrometheus {
+		config.PrometheusURL = options.prometheusURL
+	} else {
+		images = append(images, config.PrometheusImage)
+	}
+	if err := options.validateImagesPinned(images...); err != nil {
+		return nil, err
+	}
+	if config.ExternalGrafana {
+		config.GrafanaURL = strings.TrimSuffix(options.grafanaURL, "/")
+	}
+
+	if len(options.watchNamespaces) > 0 {
+		config.WatchNamespaceList = []string{controlPlaneNamespace}
+		for _, ns := range options.watchNamespaces {
+			if ns != controlPlaneNamespace {
+				config.WatchNamespaceList = append(config.WatchNamespaceList, ns)
+			}
+		}
+		config.WatchNamespaces = strings.Join(config.WatchNamespaceList, ",")
+	}
+
+	if options.identityIssuerFiles.Certificate != "" {
+		if err := buildIdentityIssuerConfig(config, options.identityIssuerFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.tapRBAC {
+		if err := buildTapAPIServerConfig(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.metricsAdapter {
+		if err := buildMetricsAdapterConfig(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.proxyAutoInject {
+		if err := buildProxyInjectorConfig(config, options); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.profileValidation {
+		if err := buildProfileValidatorConfig(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// buildIdentityIssuerConfig reads the issuer credentials and trust anchors
+// provided at install time, which are stored in a Secret that the CA reads
+// its issuer from, and checks that the CA can issue certificates with them.
+// They're base64-encoded in the config, as the data of the Secret.
+func buildIdentityIssuerConfig(config *installConfig, files identityIssuerFiles) error {
+	paths := []string{files.Certificate, files.Key, files.TrustAnchors}
+	contents := make([][]byte, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		contents[i] = content
+	}
+
+	if _, err := ca.NewIssuerCA(string(contents[0]), string(contents[1]), string(contents[2])); err != nil {
+		return fmt.Errorf("the identity issuer is invalid: %s", err)
+	}
+	config.IdentityIssuerSecret = k8s.IdentityIssuerSecretName
+	config.IdentityIssuerCertificate = base64.StdEncoding.EncodeToString(contents[0])
+	config.IdentityIssuerKey = base64.StdEncoding.EncodeToString(contents[1])
+	if contents[2] != nil {
+		config.IdentityTrustAnchors = base64.StdEncoding.EncodeToString(contents[2])
+	}
+	return nil
+}
+
+// taggedImage returns the image of a control plane component, pulled from
+// the configured registry if the image is in the default one, tagged with the
+// Linkerd version unless a tag was given, and pinned by the digest of the
+// image lock file, if any.
+func (options *installOptions) taggedImage(image string) string {
+	image = strings.Replace(image, defaultDockerRegistry, options.dockerRegistry, 1)
+	if !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+		image = fmt.Sprintf("%s:%s", image, options.linkerdVersion)
+	}
+	return options.pinnedImage(image)
+}
+
+// applyHADefaults raises the replicas of the control plane components to
+// haMinReplicas, and sets the resources they request unless overridden.
+func applyHADefaults(options *installOptions) {
+	for _, replicas := range []*uint{&options.controllerReplicas, &options.webReplicas} {
+		if *replicas < haMinReplicas {
+			*replicas = haMinReplicas
+		}
+	}
+	for _, r := range []*resources{&options.controllerResources, &options.webResources, &options.grafanaResources} {
+		r.setDefaultRequests(haCPURequest, haMemoryRequest)
+	}
+	options.prometheusResources.setDefaultRequests(haPrometheusCPURequest, haPrometheusMemoryRequest)
+}
+
+// setDefaultRequests sets the CPU and memory requests that weren't set.
+func (r *resources) setDefaultRequests(cpu, memory string) {
+	if r.CPURequest == "" {
+		r.CPURequest = cpu
+	}
+	if r.MemoryRequest == "" {
+		r.MemoryRequest = memory
+	}
+}
+
+// orNil returns the resources, or nil if none are set, so that the template
+// omits the resources of the containers.
+func (r resources) orNil() *resources {
+	if r == (resources{}) {
+		return nil
+	}
+	return &r
+}
+
+// validate returns an error if the node selector or the tolerations of a
+// control plane component are malformed, or if its pods are scheduled on an
+// architecture that the proxy images aren't published for, on which they
+// couldn't be injected.
+func (s schedulingOptions) validate(component string, architectures []string) error {
+	for _, pair := range s.nodeSelector {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || len(validation.IsQualifiedName(parts[0])) > 0 || len(validation.IsValidLabelValue(parts[1])) > 0 {
+			return fmt.Errorf("--%s-node-selector must be key=value pairs with valid label keys and values, got %s", component, pair)
+		}
+		if parts[0] != k8s.NodeArchLabel && parts[0] != k8s.NodeArchBetaLabel {
+			continue
+		}
+		supported := false
+		for _, arch := range architectures {
+			supported = supported || arch == parts[1]
+		}
+		if !supported {
+			return fmt.Errorf("--%s-node-selector schedules the pods on the %s architecture, which isn't one of the --proxy-architectures", component, parts[1])
+		}
+	}
+	for _, taint := range s.tolerations {
+		if _, err := parseToleration(taint); err != nil {
+			return fmt.Errorf("--%s-tolerations is invalid: %s", component, err)
+		}
+	}
+	return nil
+}
+
+// orNil returns the validated scheduling of the pods, or nil if neither a node
+// selector nor tolerations are set, so that the template omits them.
+func (s schedulingOptions) orNil() *scheduling {
+	if len(s.nodeSelector) == 0 && len(s.tolerations) == 0 {
+		return nil
+	}
+
+	parsed := &scheduling{}
+	if len(s.nodeSelector) > 0 {
+		parsed.NodeSelector = make(map[string]string, len(s.nodeSelector))
+		for _, pair := range s.nodeSelector {
+			parts := strings.SplitN(pair, "=", 2)
+			parsed.NodeSelector[parts[0]] = parts[1]
+		}
+	}
+	for _, taint := range s.tolerations {
+		toleration, _ := parseToleration(taint)
+		parsed.Tolerations = append(parsed.Tolerations, toleration)
+	}
+	return parsed
+}
+
+// parseToleration returns the toleration of a taint given in the format of
+// `kubectl taint`, key[=value][:effect]. The taint is tolerated whatever its
+// value if none is given, and whatever its effect if none is given.
+func parseToleration(taint string) (v1.Toleration, error) {
+	toleration := v1.Toleration{Operator: v1.TolerationOpExists}
+
+	if i := strings.LastIndex(taint, ":"); i >= 0 {
+		toleration.Effect = v1.TaintEffect(taint[i+1:])
+		taint = taint[:i]
+		switch toleration.Effect {
+		case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return toleration, fmt.Errorf("the effect of a taint must be one of %s, %s, %s, got %s",
+				v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute, toleration.Effect)
+		}
+	}
+
+	parts := strings.SplitN(taint, "=", 2)
+	toleration.Key = parts[0]
+	if len(parts) == 2 {
+		toleration.Operator = v1.TolerationOpEqual
+		toleration.Value = parts[1]
+		if len(validation.IsValidLabelValue(toleration.Value)) > 0 {
+			return toleration, fmt.Errorf("%s is not a valid taint value", toleration.Value)
+		}
+	}
+	if len(validation.IsQualifiedName(toleration.Key)) > 0 {
+		return toleration, fmt.Errorf("%s is not a valid taint key", toleration.Key)
+	}
+	return toleration, nil
+}
+
+// buildTapAPIServerConfig issues the certificate of the tap API server, which
+// the Kubernetes API server verifies with the CA bundle of the tap APIService.
+func buildTapAPIServerConfig(config *installConfig) error {
+	cert, key, caBundle, err := issueWebhookCertificate("api")
+	if err != nil {
+		return err
+	}
+
+	config.TapAPIServerTLSCert = cert
+	config.TapAPIServerTLSKey = key
+	config.TapAPIServerCABundle = caBundle
+	return nil
+}
+
+// buildMetricsAdapterConfig issues the certificate of the metrics adapter,
+// which the Kubernetes API server verifies with the CA bundle of the custom
+// metrics APIService.
+func buildMetricsAdapterConfig(config *installConfig) error {
+	cert, key, caBundle, err := issueWebhookCertificate("linkerd-metrics-adapter")
+	if err != nil {
+		return err
+	}
+
+	config.MetricsAdapterTLSCert = cert
+	config.MetricsAdapterTLSKey = key
+	config.MetricsAdapterCABundle = caBundle
+	return nil
+}
+
+// buildProxyInjectorConfig issues the certificate of the proxy injector's
+// webhook, which the Kubernetes API server verifies with the CA bundle of the
+// MutatingWebhookConfiguration, and renders the sidecar config that the proxy
+// injector adds to pods.
+func buildProxyInjectorConfig(config *installConfig, options *installOptions) error {
+	cert, key, caBundle, err := issueWebhookCertificate("proxy-injector")
+	if err != nil {
+		return err
+	}
+	sidecarConfig, err := proxyInjectorSidecarConfig(options)
+	if err != nil {
+		return err
+	}
+
+	config.ProxyInjectorTLSCert = cert
+	config.ProxyInjectorTLSKey = key
+	config.ProxyInjectorCABundle = caBundle
+	config.ProxyInjectorSidecarConfig = sidecarConfig
+	return nil
+}
+
+// buildProfileValidatorConfig issues the certificate of the ServiceProfile
+// validator's webhook, which the Kubernetes API server verifies with the CA
+// bundle of the ValidatingWebhookConfiguration.
+func buildProfileValidatorConfig(config *installConfig) error {
+	cert, key, caBundle, err := issueWebhookCertificate("sp-validator")
+	if err != nil {
+		return err
+	}
+
+	config.ProfileValidatorTLSCert = cert
+	config.ProfileValidatorTLSKey = key
+	config.ProfileValidatorCABundle = caBundle
+	return nil
+}
+
+// issueWebhookCertificate issues the certificate of the webhook, or extension
+// API server, served by a service of the control plane from a CA of its own, and returns the
+// base64-encoded PEM certificate, private key and trust anchor of the CA.
+func issueWebhookCertificate(service string) (string, string, string, error) {
+	webhookCA, err := ca.NewCA()
+	if err != nil {
+		return "", "", "", err
+	}
+	cert, err := webhookCA.IssueEndEntityCertificate(fmt.Sprintf("%s.%s.svc", service, controlPlaneNamespace))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: cert.PrivateKey})
+	return base64.StdEncoding.EncodeToString(certPEM),
+		base64.StdEncoding.EncodeToString(keyPEM),
+		base64.StdEncoding.EncodeToString([]byte(webhookCA.TrustAnchorPEM())),
+		nil
+}
+
+// proxyInjectorSidecarConfig returns a pod with the proxy and init container
+// injected by the proxy injector. The identity of the pod's owner is only
+// known at admission time, so its name and kind are left as placeholders.
+func proxyInjectorSidecarConfig(options *installOptions) (string, error) {
+	injectOptions := newInjectOptions()
+	injectOptions.proxyConfigOptions = options.proxyConfigOptions
+
+	identity := k8s.TLSIdentity{
+		Name:                injector.OwnerNamePlaceholder,
+		Kind:                injector.OwnerKindPlaceholder,
+		Namespace:           "$" + PodNamespaceEnvVarName,
+		ControllerNamespace: controlPlaneNamespace,
+		TrustDomain:         options.trustDomain,
+	}
+
+	pod := &v1.Pod{}
+	injectPodSpec(&pod.Spec, identity, "", injectOptions, &injectReport{})
+	injectObjectMeta(&pod.ObjectMeta, nil, injectOptions)
+
+	b, err := yaml.Marshal(pod)
+	return string(b), err
+}
+
+// parseTemplate parses one of the templates of the install package, along
+// with the partials that it uses.
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Parse(install.Partials)
+	if err != nil {
+		return nil, err
+	}
+	return t.Parse(text)
 }
 
 func render(config installConfig, w io.Writer, options *installOptions) error {
-	template, err := template.New("linkerd").Parse(install.Template)
+	template, err := parseTemplate("linkerd", install.Template)
 	if err != nil {
 		return err
 	}
@@ -119,7 +1055,7 @@ func render(config installConfig, w io.Writer, options *installOptions) error {
 		return err
 	}
 	if config.EnableTLS {
-		tlsTemplate, err := template.New("linkerd").Parse(install.TlsTemplate)
+		tlsTemplate, err := parseTemplate("linkerd", install.TlsTemplate)
 		if err != nil {
 			return err
 		}
@@ -128,18 +1064,318 @@ func render(config installConfig, w io.Writer, options *installOptions) error {
 			return err
 		}
 	}
+	if config.ProxyAutoInject {
+		proxyInjectorTemplate, err := parseTemplate("linkerd", install.ProxyInjectorTemplate)
+		if err != nil {
+			return err
+		}
+		err = proxyInjectorTemplate.Execute(buf, config)
+		if err != nil {
+			return err
+		}
+	}
+	if config.ProfileValidation {
+		profileValidatorTemplate, err := parseTemplate("linkerd", install.ProfileValidatorTemplate)
+		if err != nil {
+			return err
+		}
+		err = profileValidatorTemplate.Execute(buf, config)
+		if err != nil {
+			return err
+		}
+	}
 	injectOptions := newInjectOptions()
 	injectOptions.proxyConfigOptions = options.proxyConfigOptions
 
+	// The proxies of the control plane request resources in high availability
+	// mode; the proxies injected into applications aren't affected.
+	if config.EnableHA {
+		haProxyConfig := *options.proxyConfigOptions
+		if haProxyConfig.proxyCPURequest == "" {
+			haProxyConfig.proxyCPURequest = haProxyCPURequest
+		}
+		if haProxyConfig.proxyMemoryRequest == "" {
+			haProxyConfig.proxyMemoryRequest = haProxyMemoryRequest
+		}
+		injectOptions.proxyConfigOptions = &haProxyConfig
+	}
+
 	// Special case for linkerd-proxy running in the Prometheus pod.
 	injectOptions.proxyOutboundCapacity[config.PrometheusImage] = prometheusProxyOutboundCapacity
 
 	return InjectYAML(buf, w, ioutil.Discard, injectOptions)
 }
 
+// configStageKinds are the kinds of the resources installed by the config
+// stage. The Secrets of the webhooks are installed along with their webhook
+// configurations, since both contain the webhook's certificate, which is
+// issued anew each time the configs are rendered. The CRDs are installed
+// before the control plane, which watches their resources.
+var configStageKinds = map[string]bool{
+	"Namespace":                      true,
+	"CustomResourceDefinition":       true,
+	"ServiceAccount":                 true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"Role":                           true,
+	"RoleBinding":                    true,
+	"APIService":                     true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+	"Secret":                         true,
+}
+
+// renderStage renders the configs of the given stage of the install, or of the
+// whole install if stage is empty.
+func renderStage(config installConfig, w io.Writer, options *installOptions, stage string) error {
+	if stage == "" {
+		return render(config, w, options)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := render(config, buf, options); err != nil {
+		return err
+	}
+
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReader(buf))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var meta metav1.TypeMeta
+		if err := yaml.Unmarshal(doc, &meta); err != nil {
+			return err
+		}
+		if meta.Kind == "" || configStageKinds[meta.Kind] != (stage == configStage) {
+			continue
+		}
+		w.Write(doc)
+		w.Write([]byte("---\n"))
+	}
+}
+
+// renderPrometheusScrapeConfigs writes the scrape configs that an existing
+// Prometheus server needs in order to collect the metrics of the control plane
+// and of the meshed pods.
+func renderPrometheusScrapeConfigs(config installConfig, w io.Writer) error {
+	template, err := parseTemplate("linkerd", install.Template)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	err = template.ExecuteTemplate(buf, "linkerd-scrape-configs", config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Add the following scrape configs to the Prometheus server at %s, whose service account must be allowed to list pods:\n\n", config.PrometheusURL)
+	fmt.Fprintln(w, "scrape_configs:")
+	for _, line := range strings.Split(strings.TrimLeft(buf.String(), "\n"), "\n") {
+		// the scrape configs are indented for the prometheus-config ConfigMap
+		fmt.Fprintln(w, strings.TrimPrefix(line, "    "))
+	}
+	return nil
+}
+
+// renderGrafanaProvisioning writes how the dashboards of Linkerd are
+// provisioned to an existing Grafana server, and how to authenticate to it.
+func renderGrafanaProvisioning(config installConfig, w io.Writer) {
+	fmt.Fprintf(w, "The Linkerd dashboards and the %s data source, which queries %s, are provisioned to the Grafana server at %s by the linkerd-grafana-provisioner Job.\n", grafana.DataSourceName, config.PrometheusURL, config.GrafanaURL)
+	fmt.Fprintf(w, "If the server requires authentication, create the %s Secret with the API key of a Grafana admin:\n\n", config.GrafanaProvisionerSecret)
+	fmt.Fprintf(w, "  kubectl -n %s create secret generic %s --from-literal=api-key=<API key>\n", config.Namespace, config.GrafanaProvisionerSecret)
+}
+
+// renderExternalConfigs writes the configs of the existing Prometheus and
+// Grafana servers that the control plane uses instead of installing its own.
+func renderExternalConfigs(config installConfig, w io.Writer) error {
+	if config.ExternalPrometheus {
+		if err := renderPrometheusScrapeConfigs(config, w); err != nil {
+			return err
+		}
+	}
+	if config.ExternalGrafana {
+		if config.ExternalPrometheus {
+			fmt.Fprintln(w)
+		}
+		renderGrafanaProvisioning(config, w)
+	}
+	return nil
+}
+
+// prometheusLabelName matches the names of Prometheus labels, excluding the
+// names starting with "__", which are reserved for internal use.
+var prometheusLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validateExternalLabels returns an error if the external labels of the
+// installed Prometheus aren't name=value pairs with distinct valid names.
+func validateExternalLabels(labels []string) error {
+	names := map[string]bool{}
+	for _, label := range labels {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || !prometheusLabelName.MatchString(parts[0]) || strings.HasPrefix(parts[0], "__") {
+			return fmt.Errorf("--prometheus-external-labels must be name=value pairs with valid Prometheus label names, got %s", label)
+		}
+		if names[parts[0]] {
+			return fmt.Errorf("--prometheus-external-labels sets the %s label more than once", parts[0])
+		}
+		names[parts[0]] = true
+	}
+	return nil
+}
+
+// parseExternalLabels returns the validated external labels of the installed
+// Prometheus by name, or nil if there are none.
+func parseExternalLabels(labels []string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	parsed := make(map[string]string, len(labels))
+	for _, label := range labels {
+		parts := strings.SplitN(label, "=", 2)
+		parsed[parts[0]] = parts[1]
+	}
+	return parsed
+}
+
 func validate(options *installOptions) error {
 	if _, err := log.ParseLevel(options.controllerLogLevel); err != nil {
 		return fmt.Errorf("--controller-log-level must be one of: panic, fatal, error, warn, info, debug")
 	}
+	if options.latencyBuckets != "" {
+		if _, err := prometheus.ParseLatencyBuckets(options.latencyBuckets); err != nil {
+			return fmt.Errorf("--latency-buckets must be increasing positive numbers of milliseconds: %s", err)
+		}
+	}
+	for _, image := range []struct{ flag, image string }{
+		{"--controller-image", options.controllerImage},
+		{"--web-image", options.webImage},
+		{"--prometheus-image", options.prometheusImage},
+		{"--grafana-image", options.grafanaImage},
+	} {
+		if !alphaNumDashDotSlashColon.MatchString(image.image) {
+			return fmt.Errorf("%s is not a valid image for the %s flag", image.image, image.flag)
+		}
+	}
+	for _, u := range []struct{ flag, url string }{
+		{"--prometheus-url", options.prometheusURL},
+		{"--grafana-url", options.grafanaURL},
+	} {
+		if u.url == "" {
+			continue
+		}
+		parsed, err := url.Parse(u.url)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s must be an http or https URL, got %s", u.flag, u.url)
+		}
+	}
+	if options.promQueryTimeout < 0 {
+		return fmt.Errorf("--prometheus-query-timeout must not be negative")
+	}
+	if options.promMaxSamples < 0 {
+		return fmt.Errorf("--prometheus-max-samples must not be negative")
+	}
+	for _, remoteWriteURL := range options.remoteWriteURLs {
+		parsed, err := url.Parse(remoteWriteURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("--prometheus-remote-write-urls must be http or https URLs, got %s", remoteWriteURL)
+		}
+	}
+	if err := validateExternalLabels(options.externalLabels); err != nil {
+		return err
+	}
+	if options.prometheusURL != "" && (len(options.remoteWriteURLs) > 0 || len(options.externalLabels) > 0) {
+		return fmt.Errorf("--prometheus-remote-write-urls and --prometheus-external-labels configure the installed Prometheus, and can't be used with --prometheus-url")
+	}
+	if options.enforcedHost != "" {
+		if _, err := regexp.Compile(options.enforcedHost); err != nil {
+			return fmt.Errorf("--enforced-host must be a valid regexp: %s", err)
+		}
+		if strings.Contains(options.enforcedHost, "'") {
+			return fmt.Errorf("--enforced-host can't contain quotes")
+		}
+	}
+	if options.dashboardPathPrefix != "" && !alphaNumDashDotSlashColon.MatchString(options.dashboardPathPrefix) {
+		return fmt.Errorf("%s is not a valid path for the --dashboard-path-prefix flag", options.dashboardPathPrefix)
+	}
+	for _, q := range []struct{ flag, quantity string }{
+		{"--controller-cpu-request", options.controllerResources.CPURequest},
+		{"--controller-memory-request", options.controllerResources.MemoryRequest},
+		{"--controller-cpu-limit", options.controllerResources.CPULimit},
+		{"--controller-memory-limit", options.controllerResources.MemoryLimit},
+		{"--web-cpu-request", options.webResources.CPURequest},
+		{"--web-memory-request", options.webResources.MemoryRequest},
+		{"--web-cpu-limit", options.webResources.CPULimit},
+		{"--web-memory-limit", options.webResources.MemoryLimit},
+		{"--prometheus-cpu-request", options.prometheusResources.CPURequest},
+		{"--prometheus-memory-request", options.prometheusResources.MemoryRequest},
+		{"--prometheus-cpu-limit", options.prometheusResources.CPULimit},
+		{"--prometheus-memory-limit", options.prometheusResources.MemoryLimit},
+		{"--grafana-cpu-request", options.grafanaResources.CPURequest},
+		{"--grafana-memory-request", options.grafanaResources.MemoryRequest},
+		{"--grafana-cpu-limit", options.grafanaResources.CPULimit},
+		{"--grafana-memory-limit", options.grafanaResources.MemoryLimit},
+	} {
+		if q.quantity == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q.quantity); err != nil {
+			return fmt.Errorf("Invalid quantity '%s' for %s flag", q.quantity, q.flag)
+		}
+	}
+	for _, s := range []struct {
+		component  string
+		scheduling schedulingOptions
+	}{
+		{"controller", options.controllerScheduling},
+		{"web", options.webScheduling},
+		{"prometheus", options.prometheusScheduling},
+		{"grafana", options.grafanaScheduling},
+	} {
+		if err := s.scheduling.validate(s.component, options.proxyArchitectures); err != nil {
+			return err
+		}
+	}
+	for _, ns := range options.watchNamespaces {
+		if !alphaNumDash.MatchString(ns) {
+			return fmt.Errorf("%s is not a valid namespace for the --watch-namespaces flag", ns)
+		}
+	}
+	if options.federatedTrustAnchors != "" && !options.enableTLS() {
+		return fmt.Errorf("--federated-trust-anchors requires --tls=%s or --tls=%s", optionalTLS, identityTLS)
+	}
+	switch options.tlsMode {
+	case k8s.TLSModePermissive:
+	case k8s.TLSModeStrict:
+		if !options.enableIdentity() {
+			return fmt.Errorf("--tls-mode=%s requires --tls=%s", k8s.TLSModeStrict, identityTLS)
+		}
+	default:
+		return fmt.Errorf("--tls-mode must be one of: %s, %s", k8s.TLSModePermissive, k8s.TLSModeStrict)
+	}
+	files := options.identityIssuerFiles
+	if files != (identityIssuerFiles{}) {
+		if !options.enableTLS() {
+			return fmt.Errorf("--identity-issuer-certificate-file requires --tls=%s or --tls=%s", optionalTLS, identityTLS)
+		}
+		if files.Certificate == "" || files.Key == "" {
+			return fmt.Errorf("--identity-issuer-certificate-file and --identity-issuer-key-file must be given together")
+		}
+		if options.identityIssuerSecret != "" {
+			return fmt.Errorf("--identity-issuer-certificate-file and --identity-issuer-secret are mutually exclusive")
+		}
+	}
+	if options.identityIssuerSecret != "" {
+		if !options.enableTLS() {
+			return fmt.Errorf("--identity-issuer-secret requires --tls=%s or --tls=%s", optionalTLS, identityTLS)
+		}
+		if !alphaNumDashDot.MatchString(options.identityIssuerSecret) {
+			return fmt.Errorf("%s is not a valid Secret name for the --identity-issuer-secret flag", options.identityIssuerSecret)
+		}
+	}
 	return options.validate()
 }
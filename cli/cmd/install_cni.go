@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/linkerd/linkerd2/cli/install"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+type installCNIConfig struct {
+	Namespace                string
+	CNIPluginImage           string
+	ImagePullPolicy          string
+	CliVersion               string
+	ControllerComponentLabel string
+	CreatedByAnnotation      string
+	InboundPort              uint
+	OutboundPort             uint
+	ProxyUID                 int64
+	InboundPortsToIgnore     string
+	OutboundPortsToIgnore    string
+	DestCNINetDir            string
+	DestCNIBinDir            string
+}
+
+type installCNIOptions struct {
+	linkerdVersion      string
+	dockerRegistry      string
+	imagePullPolicy     string
+	inboundPort         uint
+	outboundPort        uint
+	proxyUID            int64
+	proxyControlPort    uint
+	proxyMetricsPort    uint
+	ignoreInboundPorts  []uint
+	ignoreOutboundPorts []uint
+	destCNINetDir       string
+	destCNIBinDir       string
+}
+
+func newInstallCNIOptions() *installCNIOptions {
+	return &installCNIOptions{
+		linkerdVersion:      version.Version,
+		dockerRegistry:      defaultDockerRegistry,
+		imagePullPolicy:     "IfNotPresent",
+		inboundPort:         4143,
+		outboundPort:        4140,
+		proxyUID:            2102,
+		proxyControlPort:    4190,
+		proxyMetricsPort:    4191,
+		ignoreInboundPorts:  nil,
+		ignoreOutboundPorts: nil,
+		destCNINetDir:       "/etc/cni/net.d",
+		destCNIBinDir:       "/opt/cni/bin",
+	}
+}
+
+func newCmdInstallCNIPlugin() *cobra.Command {
+	options := newInstallCNIOptions()
+
+	cmd := &cobra.Command{
+		Use:   "install-cni [flags]",
+		Short: "Output Kubernetes configs to install the Linkerd CNI plugin",
+		Long: `Output Kubernetes configs to install the Linkerd CNI plugin.
+
+The plugin configures the iptables of meshed pods when they're created, instead
+of the proxy-init container, which requires the NET_ADMIN capability. Once the
+plugin is installed, use the --linkerd-cni-enabled flag of the install and
+inject commands to omit proxy-init from the pods.`,
+		Example: `  # Install the CNI plugin, then the control plane.
+  linkerd install-cni | kubectl apply -f -
+  linkerd install --linkerd-cni-enabled | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := validateAndBuildCNIConfig(options)
+			if err != nil {
+				return err
+			}
+
+			return renderCNIPlugin(os.Stdout, config)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.linkerdVersion, "linkerd-version", "v", options.linkerdVersion, "Tag to be used for the CNI plugin image")
+	cmd.PersistentFlags().StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "Docker registry to pull the CNI plugin image from")
+	cmd.PersistentFlags().StringVar(&options.imagePullPolicy, "image-pull-policy", options.imagePullPolicy, "Docker image pull policy")
+	cmd.PersistentFlags().UintVar(&options.inboundPort, "inbound-port", options.inboundPort, "Proxy port to use for inbound traffic")
+	cmd.PersistentFlags().UintVar(&options.outboundPort, "outbound-port", options.outboundPort, "Proxy port to use for outbound traffic")
+	cmd.PersistentFlags().Int64Var(&options.proxyUID, "proxy-uid", options.proxyUID, "User ID that the proxy runs under")
+	cmd.PersistentFlags().UintVar(&options.proxyControlPort, "control-port", options.proxyControlPort, "Proxy port to use for control")
+	cmd.PersistentFlags().UintVar(&options.proxyMetricsPort, "metrics-port", options.proxyMetricsPort, "Proxy port to serve metrics on")
+	cmd.PersistentFlags().UintSliceVar(&options.ignoreInboundPorts, "skip-inbound-ports", options.ignoreInboundPorts, "Ports that should skip the proxy and send directly to the application")
+	cmd.PersistentFlags().UintSliceVar(&options.ignoreOutboundPorts, "skip-outbound-ports", options.ignoreOutboundPorts, "Outbound ports that should skip the proxy")
+	cmd.PersistentFlags().StringVar(&options.destCNINetDir, "dest-cni-net-dir", options.destCNINetDir, "Directory on the host where the CNI network configuration is located")
+	cmd.PersistentFlags().StringVar(&options.destCNIBinDir, "dest-cni-bin-dir", options.destCNIBinDir, "Directory on the host where the CNI plugin binaries are located")
+
+	return cmd
+}
+
+func (options *installCNIOptions) validate() error {
+	if !alphaNumDashDot.MatchString(options.linkerdVersion) {
+		return fmt.Errorf("%s is not a valid version", options.linkerdVersion)
+	}
+
+	for _, ports := range [][]uint{{options.inboundPort, options.outboundPort}, options.ignoreInboundPorts, options.ignoreOutboundPorts} {
+		for _, port := range ports {
+			if port == 0 || port > 65535 {
+				return fmt.Errorf("%d is not a valid port", port)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateAndBuildCNIConfig(options *installCNIOptions) (*installCNIConfig, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	// like proxy-init, the plugin doesn't redirect the traffic sent to the
+	// proxy's own control and metrics ports
+	inboundPortsToIgnore := append(options.ignoreInboundPorts, options.proxyControlPort, options.proxyMetricsPort)
+
+	return &installCNIConfig{
+		Namespace:                controlPlaneNamespace,
+		CNIPluginImage:           fmt.Sprintf("%s/cni-plugin:%s", options.dockerRegistry, options.linkerdVersion),
+		ImagePullPolicy:          options.imagePullPolicy,
+		CliVersion:               k8s.CreatedByAnnotationValue(),
+		ControllerComponentLabel: k8s.ControllerComponentLabel,
+		CreatedByAnnotation:      k8s.CreatedByAnnotation,
+		InboundPort:              options.inboundPort,
+		OutboundPort:             options.outboundPort,
+		ProxyUID:                 options.proxyUID,
+		InboundPortsToIgnore:     joinPorts(inboundPortsToIgnore),
+		OutboundPortsToIgnore:    joinPorts(options.ignoreOutboundPorts),
+		DestCNINetDir:            options.destCNINetDir,
+		DestCNIBinDir:            options.destCNIBinDir,
+	}, nil
+}
+
+func renderCNIPlugin(w io.Writer, config *installCNIConfig) error {
+	template, err := parseTemplate("linkerd-cni", install.CNITemplate)
+	if err != nil {
+		return err
+	}
+	return template.Execute(w, config)
+}
+
+func joinPorts(ports []uint) string {
+	strs := make([]string, len(ports))
+	for i, port := range ports {
+		strs[i] = strconv.Itoa(int(port))
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRenderCNIPlugin(t *testing.T) {
+	options := newInstallCNIOptions()
+	options.linkerdVersion = "testcniversion"
+	options.ignoreOutboundPorts = []uint{3306, 5432}
+
+	config, err := validateAndBuildCNIConfig(options)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildCNIConfig(): %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderCNIPlugin(&buf, config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	goldenFileBytes, err := ioutil.ReadFile("testdata/install-cni_output.golden")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	diffCompare(t, buf.String(), string(goldenFileBytes))
+}
+
+func TestInstallCNIOptionsValidate(t *testing.T) {
+	options := newInstallCNIOptions()
+	options.ignoreInboundPorts = []uint{70000}
+
+	if _, err := validateAndBuildCNIConfig(options); err == nil {
+		t.Fatal("Expected an error, got none")
+	}
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// namespacedResources maps the kinds of the namespaced resources created by
+// install to their Kubernetes API resource names. The cluster-scoped ones are
+// listed in clusterScopedResources.
+var namespacedResources = map[string]string{
+	"ConfigMap":           "configmaps",
+	"DaemonSet":           "daemonsets",
+	"Deployment":          "deployments",
+	"Job":                 "jobs",
+	"PodDisruptionBudget": "poddisruptionbudgets",
+	"Role":                "roles",
+	"RoleBinding":         "rolebindings",
+	"Secret":              "secrets",
+	"Service":             "services",
+	"ServiceAccount":      "serviceaccounts",
+}
+
+// diffContextLines is the number of unchanged lines shown around the changed
+// lines of an object.
+const diffContextLines = 3
+
+// lastAppliedConfigAnnotation is the annotation in which `kubectl apply`
+// records the config it applied.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// diffResource identifies a resource rendered by install in the Kubernetes
+// API.
+type diffResource struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+func (resource diffResource) String() string {
+	if resource.Metadata.Namespace == "" {
+		return fmt.Sprintf("%s %s", resource.Kind, resource.Metadata.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", resource.Kind, resource.Metadata.Namespace, resource.Metadata.Name)
+}
+
+// path returns the path of the resource in the Kubernetes API. The resource
+// names of the kinds that aren't listed in clusterScopedResources and
+// namespacedResources are guessed from the kind, and their resources are
+// namespaced if they have a namespace.
+func (resource diffResource) path() string {
+	prefix := "/apis/"
+	if !strings.Contains(resource.APIVersion, "/") {
+		prefix = "/api/"
+	}
+	if name, ok := clusterScopedResources[resource.Kind]; ok {
+		return fmt.Sprintf("%s%s/%s/%s", prefix, resource.APIVersion, name, resource.Metadata.Name)
+	}
+	name, ok := namespacedResources[resource.Kind]
+	if !ok {
+		plural, _ := meta.UnsafeGuessKindToResource(resource.GroupVersionKind())
+		name = plural.Resource
+	}
+	if resource.Metadata.Namespace == "" {
+		return fmt.Sprintf("%s%s/%s/%s", prefix, resource.APIVersion, name, resource.Metadata.Name)
+	}
+	return fmt.Sprintf("%s%s/namespaces/%s/%s/%s", prefix, resource.APIVersion, resource.Metadata.Namespace, name, resource.Metadata.Name)
+}
+
+// diffWithCluster writes the differences between the rendered configs and the
+// objects in the cluster to w.
+func diffWithCluster(configs []byte, w io.Writer) error {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		return err
+	}
+	client, err := kubeAPI.NewClient()
+	if err != nil {
+		return err
+	}
+
+	changed, err := diffConfigs(configs, func(path string) ([]byte, error) {
+		return kubeAPI.GetResource(client, path)
+	}, w)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Fprintln(os.Stderr, "No differences found")
+	}
+	return nil
+}
+
+// diffConfigs writes the differences between each rendered config and the
+// object returned by getLive for its path, which is nil for objects that don't
+// exist, and returns whether any object differs. Objects that exist in the
+// cluster but aren't rendered aren't listed.
+func diffConfigs(configs []byte, getLive func(path string) ([]byte, error), w io.Writer) (bool, error) {
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(bytes.NewReader(configs), 4096))
+	changed := false
+
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+
+		var resource diffResource
+		if err := yaml.Unmarshal(doc, &resource); err != nil {
+			return false, err
+		}
+		if resource.Kind == "" {
+			continue
+		}
+		live, err := getLive(resource.path())
+		if err != nil {
+			return false, fmt.Errorf("failed to get %s: %s", resource, err)
+		}
+
+		before, after, err := diffObjects(resource, live, doc)
+		if err != nil {
+			return false, err
+		}
+		diff := diffLines(before, after)
+		if diff == "" {
+			continue
+		}
+		changed = true
+		fmt.Fprintf(w, "--- %s (live)\n+++ %s (rendered)\n%s", resource, resource, diff)
+	}
+
+	return changed, nil
+}
+
+// diffObjects returns the YAML of the live and rendered objects to compare.
+// The live object is compared by the config last applied by `kubectl apply`
+// if it was recorded, since the API server adds defaults and status to the
+// objects, or else by the object without the fields set by the server. The
+// data of Secrets is compared as base64 data, and masked.
+func diffObjects(resource diffResource, live, rendered []byte) (string, string, error) {
+	renderedObj := map[string]interface{}{}
+	if err := yaml.Unmarshal(rendered, &renderedObj); err != nil {
+		return "", "", err
+	}
+
+	var liveObj map[string]interface{}
+	if live != nil {
+		if err := json.Unmarshal(live, &liveObj); err != nil {
+			return "", "", err
+		}
+		liveObj = liveConfig(liveObj)
+	}
+
+	if resource.Kind == "Secret" {
+		normalizeSecretData(liveObj)
+		normalizeSecretData(renderedObj)
+		maskSecretData(liveObj, renderedObj)
+	}
+
+	after, err := yaml.Marshal(renderedObj)
+	if err != nil {
+		return "", "", err
+	}
+	if liveObj == nil {
+		return "", string(after), nil
+	}
+	before, err := yaml.Marshal(liveObj)
+	if err != nil {
+		return "", "", err
+	}
+	return string(before), string(after), nil
+}
+
+// liveConfig returns the config of a live object: the config last applied by
+// `kubectl apply`, or the object without its status and the metadata set by
+// the server.
+func liveConfig(obj map[string]interface{}) map[string]interface{} {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if applied, ok := annotations[lastAppliedConfigAnnotation].(string); ok {
+		appliedObj := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(applied), &appliedObj); err == nil {
+			return appliedObj
+		}
+	}
+
+	delete(obj, "status")
+	for _, field := range []string{"uid", "resourceVersion", "creationTimestamp", "selfLink", "generation"} {
+		delete(metadata, field)
+	}
+	return obj
+}
+
+// normalizeSecretData moves the stringData of a Secret to its data, encoded
+// in base64 as the API server stores it, so that a Secret rendered with
+// stringData is the same as the live Secret that holds the same data.
+func normalizeSecretData(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		data = map[string]interface{}{}
+		secret["data"] = data
+	}
+	for key, value := range stringData {
+		if s, ok := value.(string); ok {
+			data[key] = base64.StdEncoding.EncodeToString([]byte(s))
+		}
+	}
+	delete(secret, "stringData")
+}
+
+// maskSecretData replaces the values of the data of the live and rendered
+// Secrets, so that they aren't printed, but still shows which values change.
+func maskSecretData(live, rendered map[string]interface{}) {
+	liveData, _ := live["data"].(map[string]interface{})
+	renderedData, _ := rendered["data"].(map[string]interface{})
+
+	for key, value := range renderedData {
+		if liveValue, ok := liveData[key]; ok && liveValue != value {
+			liveData[key] = "*** (before)"
+			renderedData[key] = "*** (after)"
+			continue
+		}
+		renderedData[key] = "***"
+		if _, ok := liveData[key]; ok {
+			liveData[key] = "***"
+		}
+	}
+	for key := range liveData {
+		if _, ok := renderedData[key]; !ok {
+			liveData[key] = "***"
+		}
+	}
+}
+
+// diffLines returns the lines that differ between before and after, prefixed
+// with - or +, and the unchanged lines around them, or an empty string if
+// they're the same.
+func diffLines(before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	type line struct {
+		prefix string
+		text   string
+	}
+	all := []line{}
+	for _, diff := range diffs {
+		prefix := " "
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, text := range strings.SplitAfter(diff.Text, "\n") {
+			if text != "" {
+				all = append(all, line{prefix, strings.TrimSuffix(text, "\n")})
+			}
+		}
+	}
+
+	// only the unchanged lines close to a change are shown
+	shown := make([]bool, len(all))
+	for i, l := range all {
+		if l.prefix == " " {
+			continue
+		}
+		for j := i - diffContextLines; j <= i+diffContextLines; j++ {
+			if j >= 0 && j < len(all) {
+				shown[j] = true
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	for i, l := range all {
+		if !shown[i] {
+			continue
+		}
+		if i == 0 || !shown[i-1] {
+			buf.WriteString("@@\n")
+		}
+		fmt.Fprintf(buf, "%s%s\n", l.prefix, l.text)
+	}
+	return buf.String()
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	configs := []byte(`kind: Namespace
+apiVersion: v1
+metadata:
+  name: linkerd
+---
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: serviceprofiles.linkerd.io
+spec:
+  group: linkerd.io
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: linkerd-config
+  namespace: linkerd
+data:
+  registry: registry.example.com/linkerd
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-proxy-injector-tls
+  namespace: linkerd
+data:
+  tls.crt: bmV3
+`)
+	live := map[string]string{
+		"/api/v1/namespaces/linkerd":                           `{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"linkerd","uid":"2c1b6c0e","resourceVersion":"42"},"status":{"phase":"Active"}}`,
+		"/api/v1/namespaces/linkerd/configmaps/linkerd-config": `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"linkerd-config","namespace":"linkerd","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"kind\":\"ConfigMap\",\"apiVersion\":\"v1\",\"metadata\":{\"name\":\"linkerd-config\",\"namespace\":\"linkerd\"},\"data\":{\"registry\":\"gcr.io/linkerd-io\"}}"}},"data":{"registry":"gcr.io/linkerd-io"}}`,
+	}
+	getLive := func(path string) ([]byte, error) {
+		if obj, ok := live[path]; ok {
+			return []byte(obj), nil
+		}
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	changed, err := diffConfigs(configs, getLive, &buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("Expected the configs to differ")
+	}
+
+	diff := buf.String()
+	if strings.Contains(diff, "Namespace linkerd") {
+		t.Fatalf("Expected the namespace not to differ, got:\n%s", diff)
+	}
+	for _, expected := range []string{
+		"--- ConfigMap linkerd/linkerd-config (live)\n+++ ConfigMap linkerd/linkerd-config (rendered)\n",
+		"-  registry: gcr.io/linkerd-io\n+  registry: registry.example.com/linkerd\n",
+		"+++ CustomResourceDefinition serviceprofiles.linkerd.io (rendered)\n",
+		"+++ Secret linkerd/linkerd-proxy-injector-tls (rendered)\n",
+		"+  tls.crt: '***'\n",
+	} {
+		if !strings.Contains(diff, expected) {
+			t.Fatalf("Expected the diff to contain [%s], got:\n%s", expected, diff)
+		}
+	}
+	if strings.Contains(diff, "bmV3") {
+		t.Fatalf("Expected the data of Secrets to be masked, got:\n%s", diff)
+	}
+}
+
+func TestDiffResourcePath(t *testing.T) {
+	testCases := []struct {
+		config   string
+		expected string
+	}{
+		{
+			"kind: Job\napiVersion: batch/v1\nmetadata:\n  name: linkerd-grafana-provisioner\n  namespace: linkerd\n",
+			"/apis/batch/v1/namespaces/linkerd/jobs/linkerd-grafana-provisioner",
+		},
+		{
+			"kind: PodSecurityPolicy\napiVersion: policy/v1beta1\nmetadata:\n  name: linkerd-control-plane\n",
+			"/apis/policy/v1beta1/podsecuritypolicies/linkerd-control-plane",
+		},
+		{
+			"kind: NetworkPolicy\napiVersion: networking.k8s.io/v1\nmetadata:\n  name: linkerd-web\n  namespace: linkerd\n",
+			"/apis/networking.k8s.io/v1/namespaces/linkerd/networkpolicies/linkerd-web",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			var resource diffResource
+			if err := yaml.Unmarshal([]byte(tc.config), &resource); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if path := resource.path(); path != tc.expected {
+				t.Fatalf("Expected path %s, got %s", tc.expected, path)
+			}
+		})
+	}
+}
+
+func TestDiffSecretStringData(t *testing.T) {
+	configs := []byte(`kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-identity-issuer
+  namespace: linkerd
+stringData:
+  key.pem: old
+`)
+	live := `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"linkerd-identity-issuer","namespace":"linkerd"},"data":{"key.pem":"b2xk"}}`
+	getLive := func(path string) ([]byte, error) {
+		return []byte(live), nil
+	}
+
+	var buf bytes.Buffer
+	changed, err := diffConfigs(configs, getLive, &buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changed {
+		t.Fatalf("Expected the stringData of the Secret to match the live data, got:\n%s", buf.String())
+	}
+
+	configs = bytes.Replace(configs, []byte("key.pem: old"), []byte("key.pem: new"), 1)
+	buf.Reset()
+	if _, err := diffConfigs(configs, getLive, &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "-  key.pem: '*** (before)'\n+  key.pem: '*** (after)'\n"
+	if !strings.Contains(buf.String(), expected) {
+		t.Fatalf("Expected the diff to contain [%s], got:\n%s", expected, buf.String())
+	}
+}
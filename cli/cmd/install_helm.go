@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// helmReplicasBase is added to the index of each replicas value to get the
+// number of replicas rendered in its place, since numbers can't be replaced by
+// placeholder strings before the configs are injected.
+const helmReplicasBase = 2000000000
+
+// helmChartFiles are the files of the generated chart, relative to its
+// directory.
+const (
+	helmChartFile     = "Chart.yaml"
+	helmValuesFile    = "values.yaml"
+	helmTemplatesFile = "templates/linkerd.yaml"
+)
+
+func newCmdInstallHelmChart(installCmd *cobra.Command, options *installOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "helm-chart [flags] DIRECTORY",
+		Short: "Write a Helm chart that installs Linkerd to a directory",
+		Long: `Write a Helm chart that installs Linkerd to a directory.
+
+The chart renders the same configs as install. The registry, version, image
+pull policy, log levels and replicas are chart values, which default to the
+flags of install; the other flags, such as --tls or --ha, are fixed when the
+chart is written.`,
+		Example: `  # Write a chart with TLS enabled, and install it with Helm.
+  linkerd install helm-chart --tls optional ./linkerd2
+  helm install --name linkerd2 ./linkerd2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := installCmd.PersistentFlags()
+			if options.valuesFile != "" {
+				if err := setFlagsFromValuesFile(flags, options.valuesFile); err != nil {
+					return err
+				}
+			}
+			// the images are pinned by the digests of the images rendered with
+			// the registry and version, which are chart values
+			if options.imageLockFile != "" {
+				return fmt.Errorf("--image-lock-file can't be used with helm-chart, whose registry and version are chart values")
+			}
+
+			config, err := validateAndBuildConfig(options)
+			if err != nil {
+				return err
+			}
+			config.InstallValues, err = installValues(flags)
+			if err != nil {
+				return err
+			}
+
+			files, err := renderHelmChart(*config, options)
+			if err != nil {
+				return err
+			}
+
+			dir := args[0]
+			for name, content := range files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return err
+				}
+				if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Helm chart written to %s\n", dir)
+			return nil
+		},
+	}
+}
+
+// renderHelmChart returns the files of a Helm chart that renders the configs
+// of the install, by name. The configs are rendered with placeholders for the
+// values of the chart, which are then replaced by Helm template actions.
+func renderHelmChart(config installConfig, options *installOptions) (map[string]string, error) {
+	// the chart's files are written to disk and usually committed, so they
+	// can't hold the issuer's private key, which is to be kept in a Secret
+	if config.IdentityIssuerKey != "" {
+		return nil, fmt.Errorf("--identity-issuer-key-file can't be used with helm-chart, whose files would hold the private key; create the issuer Secret separately and use --identity-issuer-secret")
+	}
+
+	values := map[string]interface{}{
+		"registry":           options.dockerRegistry,
+		"linkerdVersion":     options.linkerdVersion,
+		"imagePullPolicy":    options.imagePullPolicy,
+		"controllerLogLevel": options.controllerLogLevel,
+		"proxyLogLevel":      options.proxyLogLevel,
+		"controllerReplicas": options.controllerReplicas,
+		"webReplicas":        options.webReplicas,
+		"prometheusReplicas": options.prometheusReplicas,
+	}
+	placeholder := func(name string) string {
+		return fmt.Sprintf("__linkerd_helm_%s__", name)
+	}
+
+	chartOptions := *options
+	proxyConfigOptions := *options.proxyConfigOptions
+	chartOptions.proxyConfigOptions = &proxyConfigOptions
+	chartOptions.dockerRegistry = placeholder("registry")
+	chartOptions.linkerdVersion = placeholder("linkerdVersion")
+	chartOptions.imagePullPolicy = placeholder("imagePullPolicy")
+	chartOptions.controllerLogLevel = placeholder("controllerLogLevel")
+	chartOptions.proxyLogLevel = placeholder("proxyLogLevel")
+
+	config.DockerRegistry = chartOptions.dockerRegistry
+	config.LinkerdVersion = chartOptions.linkerdVersion
+	config.ControllerImage = chartOptions.taggedImage(options.controllerImage)
+	config.WebImage = chartOptions.taggedImage(options.webImage)
+	config.PrometheusImage = chartOptions.taggedImage(options.prometheusImage)
+	config.GrafanaImage = chartOptions.taggedImage(options.grafanaImage)
+	config.ProxyImage = chartOptions.taggedProxyImage()
+	config.ProxyInitImage = chartOptions.taggedProxyInitImage()
+	config.ImagePullPolicy = chartOptions.imagePullPolicy
+	config.ControllerLogLevel = chartOptions.controllerLogLevel
+
+	replicas := []struct {
+		name     string
+		replicas *uint
+	}{
+		{"controllerReplicas", &config.ControllerReplicas},
+		{"webReplicas", &config.WebReplicas},
+		{"prometheusReplicas", &config.PrometheusReplicas},
+	}
+	for i, r := range replicas {
+		*r.replicas = uint(helmReplicasBase + i)
+	}
+
+	// the sidecar config of the proxy injector contains the proxy's image
+	// and log level
+	if config.ProxyAutoInject {
+		if err := buildProxyInjectorConfig(&config, &chartOptions); err != nil {
+			return nil, err
+		}
+	}
+	if config.ProfileValidation {
+		if err := buildProfileValidatorConfig(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := render(config, buf, &chartOptions); err != nil {
+		return nil, err
+	}
+	templates := buf.String()
+	for name := range values {
+		templates = strings.Replace(templates, placeholder(name), fmt.Sprintf("{{ .Values.%s }}", name), -1)
+	}
+	for i, r := range replicas {
+		templates = strings.Replace(templates, fmt.Sprintf("replicas: %d", helmReplicasBase+i), fmt.Sprintf("replicas: {{ .Values.%s }}", r.name), -1)
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	chartYAML, err := yaml.Marshal(map[string]string{
+		"apiVersion":  "v1",
+		"name":        "linkerd2",
+		"description": "The Linkerd control plane",
+		"version":     "0.0.0-" + options.linkerdVersion,
+		"appVersion":  options.linkerdVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		helmChartFile:     string(chartYAML),
+		helmValuesFile:    string(valuesYAML),
+		helmTemplatesFile: templates,
+	}, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestRenderHelmChart(t *testing.T) {
+	options := newInstallOptions()
+	options.linkerdVersion = "stable-2.0.0"
+	options.dockerRegistry = "registry.example.com/linkerd"
+	options.highAvailability = true
+	options.proxyLogLevel = "debug"
+
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	files, err := renderHelmChart(*config, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	templates := files[helmTemplatesFile]
+	for _, expected := range []string{
+		"image: {{ .Values.registry }}/controller:{{ .Values.linkerdVersion }}",
+		"replicas: {{ .Values.controllerReplicas }}",
+		"value: {{ .Values.proxyLogLevel }}",
+	} {
+		if !strings.Contains(templates, expected) {
+			t.Fatalf("Expected the chart templates to contain [%s]", expected)
+		}
+	}
+	if strings.Contains(templates, "__linkerd_helm_") || strings.Contains(templates, "replicas: 200000000") {
+		t.Fatal("Expected all the placeholders to be replaced")
+	}
+
+	// rendering the chart with its default values renders the install
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(files[helmValuesFile]), &values); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tmpl, err := template.New("chart").Parse(templates)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var chart bytes.Buffer
+	if err := tmpl.Execute(&chart, map[string]interface{}{"Values": values}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var install bytes.Buffer
+	if err := render(*config, &install, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	diffCompare(t, chart.String(), install.String())
+
+	if !strings.Contains(files[helmChartFile], "appVersion: stable-2.0.0") {
+		t.Fatalf("Expected the chart to be versioned with Linkerd, got [%s]", files[helmChartFile])
+	}
+}
+
+func TestRenderHelmChartWithIssuerKey(t *testing.T) {
+	options := newInstallOptions()
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config.IdentityIssuerKey = "aXNzdWVyIGtleQ=="
+
+	if _, err := renderHelmChart(*config, options); err == nil {
+		t.Fatal("Expected the chart not to hold the issuer's private key")
+	}
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/linkerd/linkerd2/cli/install"
+	"github.com/linkerd/linkerd2/controller/servicemirror"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/version"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type installGatewayConfig struct {
+	Namespace                 string
+	GatewayName               string
+	GatewayImage              string
+	GatewayReplicas           uint
+	GatewayPort               uint
+	GatewayPortName           string
+	GatewayIdentity           string
+	GatewayIdentityAnnotation string
+	DNSResolver               string
+	ImagePullPolicy           string
+	CliVersion                string
+	ControllerComponentLabel  string
+	CreatedByAnnotation       string
+}
+
+type installGatewayOptions struct {
+	gatewayNamespace string
+	gatewayName      string
+	gatewayImage     string
+	gatewayReplicas  uint
+	gatewayPort      uint
+	dnsResolver      string
+	*proxyConfigOptions
+}
+
+type installServiceMirrorConfig struct {
+	Namespace                 string
+	ClusterName               string
+	RemoteKubeconfigSecret    string
+	GatewayName               string
+	GatewayNamespace          string
+	RemoteControllerNamespace string
+	RemoteClusterDomain       string
+	ControllerImage           string
+	ControllerLogLevel        string
+	ImagePullPolicy           string
+	CliVersion                string
+	ControllerComponentLabel  string
+	CreatedByAnnotation       string
+}
+
+type installServiceMirrorOptions struct {
+	clusterName               string
+	remoteKubeconfigSecret    string
+	gatewayName               string
+	gatewayNamespace          string
+	remoteControllerNamespace string
+	remoteClusterDomain       string
+	linkerdVersion            string
+	dockerRegistry            string
+	imagePullPolicy           string
+	controllerLogLevel        string
+}
+
+func newInstallGatewayOptions() *installGatewayOptions {
+	return &installGatewayOptions{
+		gatewayNamespace:   controlPlaneNamespace,
+		gatewayName:        "linkerd-gateway",
+		gatewayImage:       "nginx:1.17-alpine",
+		gatewayReplicas:    1,
+		gatewayPort:        4143,
+		dnsResolver:        "kube-dns.kube-system.svc.cluster.local",
+		proxyConfigOptions: newProxyConfigOptions(),
+	}
+}
+
+func newInstallServiceMirrorOptions() *installServiceMirrorOptions {
+	return &installServiceMirrorOptions{
+		clusterName:               "",
+		remoteKubeconfigSecret:    "",
+		gatewayName:               "linkerd-gateway",
+		gatewayNamespace:          "linkerd",
+		remoteControllerNamespace: "linkerd",
+		remoteClusterDomain:       "cluster.local",
+		linkerdVersion:            version.Version,
+		dockerRegistry:            defaultDockerRegistry,
+		imagePullPolicy:           "IfNotPresent",
+		controllerLogLevel:        "info",
+	}
+}
+
+func newCmdInstallGateway() *cobra.Command {
+	options := newInstallGatewayOptions()
+
+	cmd := &cobra.Command{
+		Use:   "install-gateway [flags]",
+		Short: "Output Kubernetes configs to install the gateway through which other clusters reach the exported services",
+		Long: `Output Kubernetes configs to install the gateway through which other clusters reach the exported services.
+
+The services labeled with mirror.linkerd.io/exported=true are mirrored into the
+clusters that link to this one with 'linkerd install-service-mirror'. The
+requests to the mirrored services are sent to the gateway's load balancer over
+mTLS, and the gateway forwards them to the exported services.
+
+The service mirrors of the other clusters read the services of this one with
+the credentials of the linkerd-service-mirror-remote-access ServiceAccount,
+which is installed along with the gateway.`,
+		Example: `  # Install the gateway into the control plane's namespace.
+  linkerd install-gateway | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := validateAndBuildGatewayConfig(options)
+			if err != nil {
+				return err
+			}
+
+			return renderGateway(os.Stdout, config, options)
+		},
+	}
+
+	addProxyConfigFlags(cmd, options.proxyConfigOptions)
+	cmd.PersistentFlags().StringVar(&options.gatewayNamespace, "gateway-namespace", options.gatewayNamespace, "Namespace of the gateway")
+	cmd.PersistentFlags().StringVar(&options.gatewayName, "gateway-name", options.gatewayName, "Name of the gateway's Deployment and Service")
+	cmd.PersistentFlags().StringVar(&options.gatewayImage, "gateway-image", options.gatewayImage, "nginx image that forwards the requests to the exported services")
+	cmd.PersistentFlags().UintVar(&options.gatewayReplicas, "gateway-replicas", options.gatewayReplicas, "Replicas of the gateway to deploy")
+	cmd.PersistentFlags().UintVar(&options.gatewayPort, "gateway-port", options.gatewayPort, "Port of the gateway's load balancer")
+	cmd.PersistentFlags().StringVar(&options.dnsResolver, "dns-resolver", options.dnsResolver, "Address of the DNS server that resolves the names of the exported services")
+
+	return cmd
+}
+
+func newCmdInstallServiceMirror() *cobra.Command {
+	options := newInstallServiceMirrorOptions()
+
+	cmd := &cobra.Command{
+		Use:   "install-service-mirror [flags]",
+		Short: "Output Kubernetes configs to mirror the exported services of another cluster",
+		Long: `Output Kubernetes configs to mirror the exported services of another cluster.
+
+The service mirror mirrors the services of the remote cluster labeled with
+mirror.linkerd.io/exported=true into the namespaces of the same names, if they
+exist. The mirrored services are named after the remote services, suffixed with
+the name of the remote cluster, and their requests are sent to the gateway of
+the remote cluster, installed with 'linkerd install-gateway'.
+
+The service mirror reads the services of the remote cluster with the kubeconfig
+under the kubeconfig key of the Secret given with --remote-kubeconfig-secret,
+which must be created in the control plane's namespace, e.g. with the
+credentials of the remote cluster's linkerd-service-mirror-remote-access
+ServiceAccount.`,
+		Example: `  # Mirror the exported services of the east cluster.
+  kubectl -n linkerd create secret generic linkerd-east-kubeconfig --from-file=kubeconfig=east.kubeconfig
+  linkerd install-service-mirror --cluster-name east --remote-kubeconfig-secret linkerd-east-kubeconfig | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := validateAndBuildServiceMirrorConfig(options)
+			if err != nil {
+				return err
+			}
+
+			return renderServiceMirror(os.Stdout, config)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&options.clusterName, "cluster-name", options.clusterName, "Name of the remote cluster, which suffixes the names of the mirrored services")
+	cmd.PersistentFlags().StringVar(&options.remoteKubeconfigSecret, "remote-kubeconfig-secret", options.remoteKubeconfigSecret, "Name of the Secret with the kubeconfig of the remote cluster (default linkerd-<cluster-name>-kubeconfig)")
+	cmd.PersistentFlags().StringVar(&options.gatewayName, "gateway-name", options.gatewayName, "Name of the gateway Service of the remote cluster")
+	cmd.PersistentFlags().StringVar(&options.gatewayNamespace, "gateway-namespace", options.gatewayNamespace, "Namespace of the gateway Service of the remote cluster")
+	cmd.PersistentFlags().StringVar(&options.remoteControllerNamespace, "remote-controller-namespace", options.remoteControllerNamespace, "Namespace in which Linkerd is installed in the remote cluster")
+	cmd.PersistentFlags().StringVar(&options.remoteClusterDomain, "remote-cluster-domain", options.remoteClusterDomain, "DNS domain of the remote cluster")
+	cmd.PersistentFlags().StringVarP(&options.linkerdVersion, "linkerd-version", "v", options.linkerdVersion, "Tag to be used for the controller image")
+	cmd.PersistentFlags().StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "Docker registry to pull the controller image from")
+	cmd.PersistentFlags().StringVar(&options.imagePullPolicy, "image-pull-policy", options.imagePullPolicy, "Docker image pull policy")
+	cmd.PersistentFlags().StringVar(&options.controllerLogLevel, "controller-log-level", options.controllerLogLevel, "Log level for the service mirror")
+
+	return cmd
+}
+
+func validateAndBuildGatewayConfig(options *installGatewayOptions) (*installGatewayConfig, error) {
+	if err := options.proxyConfigOptions.validate(); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{options.gatewayNamespace, options.gatewayName} {
+		if !alphaNumDash.MatchString(name) {
+			return nil, fmt.Errorf("%s is not a valid name for the gateway", name)
+		}
+	}
+	if !alphaNumDashDot.MatchString(options.dnsResolver) {
+		return nil, fmt.Errorf("%s is not a valid address for the --dns-resolver flag", options.dnsResolver)
+	}
+	if options.gatewayPort == 0 || options.gatewayPort > 65535 {
+		return nil, fmt.Errorf("%d is not a valid port", options.gatewayPort)
+	}
+
+	identity := k8s.TLSIdentity{
+		Name:                options.gatewayName,
+		Kind:                k8s.Deployment,
+		Namespace:           options.gatewayNamespace,
+		ControllerNamespace: controlPlaneNamespace,
+		TrustDomain:         options.trustDomain,
+	}
+
+	return &installGatewayConfig{
+		Namespace:                 options.gatewayNamespace,
+		GatewayName:               options.gatewayName,
+		GatewayImage:              options.gatewayImage,
+		GatewayReplicas:           options.gatewayReplicas,
+		GatewayPort:               options.gatewayPort,
+		GatewayPortName:           servicemirror.GatewayPortName,
+		GatewayIdentity:           identity.ToDNSName(),
+		GatewayIdentityAnnotation: k8s.GatewayIdentityAnnotation,
+		DNSResolver:               options.dnsResolver,
+		ImagePullPolicy:           options.imagePullPolicy,
+		CliVersion:                k8s.CreatedByAnnotationValue(),
+		ControllerComponentLabel:  k8s.ControllerComponentLabel,
+		CreatedByAnnotation:       k8s.CreatedByAnnotation,
+	}, nil
+}
+
+// renderGateway renders the gateway, which is injected, so that its proxy
+// terminates the mTLS connections from the other clusters.
+func renderGateway(w io.Writer, config *installGatewayConfig, options *installGatewayOptions) error {
+	template, err := parseTemplate("linkerd-gateway", install.GatewayTemplate)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := template.Execute(buf, config); err != nil {
+		return err
+	}
+
+	injectOptions := newInjectOptions()
+	injectOptions.proxyConfigOptions = options.proxyConfigOptions
+	return InjectYAML(buf, w, ioutil.Discard, injectOptions)
+}
+
+func validateAndBuildServiceMirrorConfig(options *installServiceMirrorOptions) (*installServiceMirrorConfig, error) {
+	if options.clusterName == "" {
+		return nil, fmt.Errorf("--cluster-name must be set")
+	}
+	for _, name := range []string{options.clusterName, options.gatewayName, options.gatewayNamespace, options.remoteControllerNamespace} {
+		if !alphaNumDash.MatchString(name) {
+			return nil, fmt.Errorf("%s is not a valid name", name)
+		}
+	}
+	secret := options.remoteKubeconfigSecret
+	if secret == "" {
+		secret = fmt.Sprintf("linkerd-%s-kubeconfig", options.clusterName)
+	}
+	if !alphaNumDashDot.MatchString(secret) {
+		return nil, fmt.Errorf("%s is not a valid Secret name for the --remote-kubeconfig-secret flag", secret)
+	}
+	if !alphaNumDashDot.MatchString(options.remoteClusterDomain) {
+		return nil, fmt.Errorf("%s is not a valid domain for the --remote-cluster-domain flag", options.remoteClusterDomain)
+	}
+	if !alphaNumDashDot.MatchString(options.linkerdVersion) {
+		return nil, fmt.Errorf("%s is not a valid version", options.linkerdVersion)
+	}
+	if _, err := log.ParseLevel(options.controllerLogLevel); err != nil {
+		return nil, fmt.Errorf("--controller-log-level must be one of: panic, fatal, error, warn, info, debug")
+	}
+
+	return &installServiceMirrorConfig{
+		Namespace:                 controlPlaneNamespace,
+		ClusterName:               options.clusterName,
+		RemoteKubeconfigSecret:    secret,
+		GatewayName:               options.gatewayName,
+		GatewayNamespace:          options.gatewayNamespace,
+		RemoteControllerNamespace: options.remoteControllerNamespace,
+		RemoteClusterDomain:       options.remoteClusterDomain,
+		ControllerImage:           fmt.Sprintf("%s/controller:%s", options.dockerRegistry, options.linkerdVersion),
+		ControllerLogLevel:        options.controllerLogLevel,
+		ImagePullPolicy:           options.imagePullPolicy,
+		CliVersion:                k8s.CreatedByAnnotationValue(),
+		ControllerComponentLabel:  k8s.ControllerComponentLabel,
+		CreatedByAnnotation:       k8s.CreatedByAnnotation,
+	}, nil
+}
+
+func renderServiceMirror(w io.Writer, config *installServiceMirrorConfig) error {
+	template, err := parseTemplate("linkerd-service-mirror", install.ServiceMirrorTemplate)
+	if err != nil {
+		return err
+	}
+	return template.Execute(w, config)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func TestRenderGateway(t *testing.T) {
+	options := newInstallGatewayOptions()
+	options.trustDomain = "east.example.com"
+
+	config, err := validateAndBuildGatewayConfig(options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderGateway(&buf, config, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		k8s.GatewayIdentityAnnotation + ": linkerd-gateway.deployment." + controlPlaneNamespace + ".linkerd-managed." + controlPlaneNamespace + ".svc.east.example.com",
+		"type: LoadBalancer",
+		"name: mc-gateway",
+		"proxy_pass http://$http_host;",
+		"name: " + k8s.ProxyContainerName,
+		"name: linkerd-service-mirror-remote-access",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Fatalf("Expected the config to contain [%s]", expected)
+		}
+	}
+}
+
+func TestRenderServiceMirror(t *testing.T) {
+	t.Run("Mirrors the services of the remote cluster", func(t *testing.T) {
+		options := newInstallServiceMirrorOptions()
+		options.clusterName = "east"
+		options.remoteClusterDomain = "east.example.com"
+
+		config, err := validateAndBuildServiceMirrorConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := renderServiceMirror(&buf, config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"name: linkerd-service-mirror-east",
+			"secretName: linkerd-east-kubeconfig",
+			"-cluster-name=east",
+			"-remote-cluster-domain=east.example.com",
+			"resources: [\"services\", \"endpoints\"]",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Requires the name of the remote cluster", func(t *testing.T) {
+		options := newInstallServiceMirrorOptions()
+
+		if _, err := validateAndBuildServiceMirrorConfig(options); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
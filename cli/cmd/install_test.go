@@ -2,9 +2,25 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/pflag"
+	"k8s.io/api/core/v1"
 )
 
 func TestRender(t *testing.T) {
@@ -31,6 +47,7 @@ func TestRender(t *testing.T) {
 		PrometheusReplicas:          3,
 		ImagePullPolicy:             "ImagePullPolicy",
 		UUID:                        "UUID",
+		LinkerdVersion:              "LinkerdVersion",
 		CliVersion:                  "CliVersion",
 		ControllerLogLevel:          "ControllerLogLevel",
 		ControllerComponentLabel:    "ControllerComponentLabel",
@@ -39,6 +56,20 @@ func TestRender(t *testing.T) {
 		EnableTLS:                   true,
 		TLSTrustAnchorConfigMapName: "TLSTrustAnchorConfigMapName",
 		ProxyContainerName:          "ProxyContainerName",
+		TrustDomain:                 "TrustDomain",
+		TLSFederationConfigMapName:  "TLSFederationConfigMapName",
+		TapRBAC:                     true,
+		TapAPIServerTLSCert:         "TapAPIServerTLSCert",
+		TapAPIServerTLSKey:          "TapAPIServerTLSKey",
+		TapAPIServerCABundle:        "TapAPIServerCABundle",
+		DockerRegistry:              "DockerRegistry",
+		ProxyImage:                  "ProxyImage",
+		ProxyInitImage:              "ProxyInitImage",
+		ProxyArchitectures:          "ProxyArchitectures",
+		InstallConfigMapName:        "InstallConfigMapName",
+		PrometheusURL:               "PrometheusURL",
+		PrometheusQueryTimeout:      "PrometheusQueryTimeout",
+		PrometheusMaxSamples:        50000000,
 	}
 
 	testCases := []struct {
@@ -70,3 +101,1358 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAndBuildConfig(t *testing.T) {
+	t.Run("Sets the trust domain", func(t *testing.T) {
+		options := newInstallOptions()
+		options.trustDomain = "prod.example.com"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.TrustDomain != "prod.example.com" {
+			t.Fatalf("Expected trust domain to be prod.example.com, got %s", config.TrustDomain)
+		}
+	})
+
+	t.Run("Reads the federated trust anchors", func(t *testing.T) {
+		federatedCA, err := ca.NewCA()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		file, err := ioutil.TempFile("", "trust-anchors")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer os.Remove(file.Name())
+		file.WriteString(federatedCA.TrustAnchorPEM())
+		file.Close()
+
+		options := newInstallOptions()
+		options.tls = optionalTLS
+		options.federatedTrustAnchors = file.Name()
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.FederatedTrustAnchors != federatedCA.TrustAnchorPEM() {
+			t.Fatalf("Expected federated trust anchors [%s], got [%s]", federatedCA.TrustAnchorPEM(), config.FederatedTrustAnchors)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "-federated-trust-anchors=/var/linkerd-io/federation/trust-anchors.pem") {
+			t.Fatal("Expected the CA to be configured with the federated trust anchors")
+		}
+	})
+
+	t.Run("Rejects federated trust anchors without TLS", func(t *testing.T) {
+		options := newInstallOptions()
+		options.federatedTrustAnchors = "trust-anchors.pem"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Overrides the registry and images", func(t *testing.T) {
+		options := newInstallOptions()
+		options.linkerdVersion = "stable-2.0.0"
+		options.dockerRegistry = "registry.example.com:5000/linkerd"
+		options.webImage = "registry.example.com:5000/custom/web:patched"
+		options.prometheusImage = "registry.example.com:5000/prom/prometheus:v2.4.0"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, image := range []struct{ actual, expected string }{
+			{config.ControllerImage, "registry.example.com:5000/linkerd/controller:stable-2.0.0"},
+			{config.WebImage, "registry.example.com:5000/custom/web:patched"},
+			{config.PrometheusImage, "registry.example.com:5000/prom/prometheus:v2.4.0"},
+			{config.GrafanaImage, "registry.example.com:5000/linkerd/grafana:stable-2.0.0"},
+			{config.ProxyImage, "registry.example.com:5000/linkerd/proxy:stable-2.0.0"},
+			{config.ProxyInitImage, "registry.example.com:5000/linkerd/proxy-init:stable-2.0.0"},
+		} {
+			if image.actual != image.expected {
+				t.Fatalf("Expected image %s, got %s", image.expected, image.actual)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid images", func(t *testing.T) {
+		options := newInstallOptions()
+		options.controllerImage = "controller image"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Pins the images by the digests of the image lock file", func(t *testing.T) {
+		options := newInstallOptions()
+		options.linkerdVersion = "stable-2.0.0"
+		options.dockerRegistry = "registry.example.com/linkerd"
+		options.prometheusImage = "registry.example.com/prom/prometheus:v2.4.0"
+
+		images := []string{
+			"registry.example.com/linkerd/controller:stable-2.0.0",
+			"registry.example.com/linkerd/web:stable-2.0.0",
+			"registry.example.com/prom/prometheus:v2.4.0",
+			"registry.example.com/linkerd/grafana:stable-2.0.0",
+			"registry.example.com/linkerd/proxy:stable-2.0.0",
+			"registry.example.com/linkerd/proxy-init:stable-2.0.0",
+		}
+		lock := "images:\n"
+		for _, image := range images {
+			lock += fmt.Sprintf("  %s: %s\n", image, testImageDigest)
+		}
+		file, err := ioutil.TempFile("", "images.lock")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer os.Remove(file.Name())
+		file.WriteString(lock)
+		file.Close()
+		options.imageLockFile = file.Name()
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for i, actual := range []string{config.ControllerImage, config.WebImage, config.PrometheusImage, config.GrafanaImage, config.ProxyImage, config.ProxyInitImage} {
+			expected := images[i] + "@" + testImageDigest
+			if actual != expected {
+				t.Fatalf("Expected image %s, got %s", expected, actual)
+			}
+		}
+	})
+
+	t.Run("Rejects image lock files that don't pin all the images", func(t *testing.T) {
+		file, err := ioutil.TempFile("", "images.lock")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer os.Remove(file.Name())
+		file.WriteString("images:\n  gcr.io/linkerd-io/proxy:stable-2.0.0: " + testImageDigest + "\n")
+		file.Close()
+
+		options := newInstallOptions()
+		options.linkerdVersion = "stable-2.0.0"
+		options.imageLockFile = file.Name()
+
+		_, err = validateAndBuildConfig(options)
+		if err == nil || !strings.Contains(err.Error(), "doesn't pin the images: gcr.io/linkerd-io/proxy-init:stable-2.0.0") {
+			t.Fatalf("Expected an error about the unpinned images, got [%v]", err)
+		}
+	})
+
+	t.Run("Uses an existing Prometheus", func(t *testing.T) {
+		options := newInstallOptions()
+		options.prometheusURL = "http://prometheus.monitoring.svc.cluster.local:9090"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(buf.String(), "name: prometheus-config") {
+			t.Fatal("Expected Prometheus not to be installed")
+		}
+		if !strings.Contains(buf.String(), "-prometheus-url=http://prometheus.monitoring.svc.cluster.local:9090") {
+			t.Fatal("Expected the public API to query the existing Prometheus")
+		}
+
+		var scrapeConfigs bytes.Buffer
+		if err := renderPrometheusScrapeConfigs(*config, &scrapeConfigs); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{"\nscrape_configs:\n- job_name: 'grafana'\n", "\n- job_name: 'linkerd-controller'\n", "\n- job_name: 'linkerd-proxy'\n"} {
+			if !strings.Contains(scrapeConfigs.String(), expected) {
+				t.Fatalf("Expected the scrape configs to contain [%s], got [%s]", expected, scrapeConfigs.String())
+			}
+		}
+	})
+
+	t.Run("Rejects invalid Prometheus URLs", func(t *testing.T) {
+		options := newInstallOptions()
+		options.prometheusURL = "prometheus:9090"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Configures remote storage and external labels for the installed Prometheus", func(t *testing.T) {
+		options := newInstallOptions()
+		options.remoteWriteURLs = []string{"https://metrics.example.com/api/v1/write"}
+		options.externalLabels = []string{"region=eu-west-1", "cluster=prod"}
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			`      external_labels:
+        cluster: "prod"
+        region: "eu-west-1"`,
+			`    remote_write:
+    - url: "https://metrics.example.com/api/v1/write"`,
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the Prometheus config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid remote storage and external labels", func(t *testing.T) {
+		invalid := []func(*installOptions){
+			func(options *installOptions) { options.remoteWriteURLs = []string{"metrics.example.com"} },
+			func(options *installOptions) { options.externalLabels = []string{"cluster"} },
+			func(options *installOptions) { options.externalLabels = []string{"cluster-name=prod"} },
+			func(options *installOptions) { options.externalLabels = []string{"__name__=prod"} },
+			func(options *installOptions) { options.externalLabels = []string{"cluster=prod", "cluster=staging"} },
+			func(options *installOptions) {
+				options.prometheusURL = "http://prometheus.monitoring.svc.cluster.local:9090"
+				options.externalLabels = []string{"cluster=prod"}
+			},
+		}
+		for i, configure := range invalid {
+			options := newInstallOptions()
+			configure(options)
+
+			if _, err := validateAndBuildConfig(options); err == nil {
+				t.Fatalf("Expected an error for options %d, got none", i)
+			}
+		}
+	})
+
+	t.Run("Provisions an existing Grafana", func(t *testing.T) {
+		options := newInstallOptions()
+		options.grafanaURL = "https://grafana.example.com/"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(buf.String(), "name: grafana-config") {
+			t.Fatal("Expected Grafana not to be installed")
+		}
+		for _, expected := range []string{
+			"grafana-url: https://grafana.example.com\n",
+			`- "-grafana-url=https://grafana.example.com"`,
+			"name: linkerd-grafana-provisioner",
+			"linkerd.io/inject: disabled",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the configs to contain [%s]", expected)
+			}
+		}
+
+		var scrapeConfigs bytes.Buffer
+		if err := renderPrometheusScrapeConfigs(*config, &scrapeConfigs); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Contains(scrapeConfigs.String(), "job_name: 'grafana'") {
+			t.Fatalf("Expected Grafana not to be scraped, got [%s]", scrapeConfigs.String())
+		}
+
+		var provisioning bytes.Buffer
+		renderGrafanaProvisioning(*config, &provisioning)
+		expected := "kubectl -n linkerd create secret generic linkerd-grafana-provisioner --from-literal=api-key=<API key>"
+		if !strings.Contains(provisioning.String(), expected) {
+			t.Fatalf("Expected [%s], got [%s]", expected, provisioning.String())
+		}
+	})
+
+	t.Run("Rejects invalid Grafana URLs", func(t *testing.T) {
+		options := newInstallOptions()
+		options.grafanaURL = "grafana:3000"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Serves the dashboard at the enforced host", func(t *testing.T) {
+		options := newInstallOptions()
+		options.enforcedHost = `dashboard\.example\.com`
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := `- '-enforced-host=dashboard\.example\.com'`
+		if !strings.Contains(buf.String(), expected) {
+			t.Fatalf("Expected the configs to contain [%s]", expected)
+		}
+	})
+
+	t.Run("Rejects invalid enforced hosts", func(t *testing.T) {
+		for _, host := range []string{"dashboard(", "dashboard'"} {
+			options := newInstallOptions()
+			options.enforcedHost = host
+
+			_, err := validateAndBuildConfig(options)
+			if err == nil {
+				t.Fatalf("Expected an error for [%s], got none", host)
+			}
+		}
+	})
+
+	t.Run("Serves the dashboard and Grafana under the path prefix", func(t *testing.T) {
+		options := newInstallOptions()
+		options.dashboardPathPrefix = "linkerd/"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			`- "-path-prefix=/linkerd"`,
+			"root_url = %(protocol)s://%(domain)s:/linkerd/api/v1/namespaces/" + controlPlaneNamespace + "/services/grafana:http/proxy/",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the configs to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid dashboard path prefixes", func(t *testing.T) {
+		options := newInstallOptions()
+		options.dashboardPathPrefix = "/linkerd\"\n"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Configures the latency buckets of the control plane", func(t *testing.T) {
+		options := newInstallOptions()
+		options.latencyBuckets = "0.5,1,5,10,100,1000,10000"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := `- "-latency-buckets=0.5,1,5,10,100,1000,10000"`
+		if count := strings.Count(buf.String(), expected); count < 2 {
+			t.Fatalf("Expected the control plane components to be configured with [%s], got %d", expected, count)
+		}
+	})
+
+	t.Run("Rejects invalid latency buckets", func(t *testing.T) {
+		for _, buckets := range []string{"1,ms", "0,1", "10,5"} {
+			options := newInstallOptions()
+			options.latencyBuckets = buckets
+
+			_, err := validateAndBuildConfig(options)
+			if err == nil {
+				t.Fatalf("Expected an error for [%s], got none", buckets)
+			}
+		}
+	})
+
+	t.Run("Rejects negative Prometheus query limits", func(t *testing.T) {
+		options := newInstallOptions()
+		options.promQueryTimeout = -time.Second
+		if _, err := validateAndBuildConfig(options); err == nil {
+			t.Fatal("Expected an error for a negative query timeout, got none")
+		}
+
+		options = newInstallOptions()
+		options.promMaxSamples = -1
+		if _, err := validateAndBuildConfig(options); err == nil {
+			t.Fatal("Expected an error for a negative maximum of samples, got none")
+		}
+	})
+
+	t.Run("Configures the Prometheus query limits of the public API", func(t *testing.T) {
+		options := newInstallOptions()
+		options.promQueryTimeout = 10 * time.Second
+		options.promMaxSamples = 1000000
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"-prometheus-query-timeout=10s",
+			"-prometheus-max-samples=1000000",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Configures high availability", func(t *testing.T) {
+		options := newInstallOptions()
+		options.highAvailability = true
+		options.webReplicas = 5
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ControllerReplicas != 3 || config.WebReplicas != 5 {
+			t.Fatalf("Expected 3 controller and 5 web replicas, got %d and %d", config.ControllerReplicas, config.WebReplicas)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"kind: PodDisruptionBudget",
+			"name: linkerd-controller",
+			"podAntiAffinity:",
+			"topologyKey: kubernetes.io/hostname",
+			"memory: 300Mi",
+			"cpu: 10m",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if options.proxyCPURequest != "" {
+			t.Fatalf("Expected the proxies of applications not to request resources, got %s", options.proxyCPURequest)
+		}
+	})
+
+	t.Run("Runs a single CA in high availability mode", func(t *testing.T) {
+		options := newInstallOptions()
+		options.highAvailability = true
+		options.tls = optionalTLS
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "  name: ca\n  namespace: " + controlPlaneNamespace + "\n"
+		ca := strings.Index(buf.String(), expected)
+		if ca < 0 {
+			t.Fatalf("Expected the config to contain [%s]", expected)
+		}
+		if !strings.HasPrefix(buf.String()[ca+len(expected):], "spec:\n  replicas: 1\n") {
+			t.Fatal("Expected a single CA replica")
+		}
+		// only the controller and web have disruption budgets
+		if count := strings.Count(buf.String(), "kind: PodDisruptionBudget"); count != 2 {
+			t.Fatalf("Expected 2 disruption budgets, got %d", count)
+		}
+	})
+
+	t.Run("Sets the resources of the control plane components", func(t *testing.T) {
+		options := newInstallOptions()
+		options.highAvailability = true
+		options.prometheusResources.MemoryRequest = "1Gi"
+		options.prometheusResources.MemoryLimit = "2Gi"
+		options.grafanaResources.CPULimit = "500m"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := resources{CPURequest: "300m", MemoryRequest: "1Gi", MemoryLimit: "2Gi"}
+		if *config.PrometheusResources != expected {
+			t.Fatalf("Expected Prometheus resources %+v, got %+v", expected, *config.PrometheusResources)
+		}
+		expected = resources{CPURequest: "20m", MemoryRequest: "50Mi", CPULimit: "500m"}
+		if *config.GrafanaResources != expected {
+			t.Fatalf("Expected Grafana resources %+v, got %+v", expected, *config.GrafanaResources)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"memory: 1Gi",
+			"memory: 2Gi",
+			"cpu: 500m",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Omits the resources of the control plane components by default", func(t *testing.T) {
+		config, err := validateAndBuildConfig(newInstallOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ControllerResources != nil || config.WebResources != nil || config.PrometheusResources != nil || config.GrafanaResources != nil {
+			t.Fatal("Expected no resources to be set")
+		}
+	})
+
+	t.Run("Rejects invalid control plane resources", func(t *testing.T) {
+		options := newInstallOptions()
+		options.prometheusResources.MemoryLimit = "lots"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Schedules the control plane components on the selected nodes", func(t *testing.T) {
+		options := newInstallOptions()
+		options.controllerScheduling.nodeSelector = []string{"kubernetes.io/arch=arm64"}
+		options.controllerScheduling.tolerations = []string{"dedicated=linkerd:NoSchedule"}
+		options.webScheduling.tolerations = []string{"arm"}
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := &scheduling{
+			NodeSelector: map[string]string{"kubernetes.io/arch": "arm64"},
+			Tolerations: []v1.Toleration{
+				{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "linkerd", Effect: v1.TaintEffectNoSchedule},
+			},
+		}
+		if !reflect.DeepEqual(config.ControllerScheduling, expected) {
+			t.Fatalf("Expected controller scheduling %+v, got %+v", expected, config.ControllerScheduling)
+		}
+		expected = &scheduling{Tolerations: []v1.Toleration{{Key: "arm", Operator: v1.TolerationOpExists}}}
+		if !reflect.DeepEqual(config.WebScheduling, expected) {
+			t.Fatalf("Expected web scheduling %+v, got %+v", expected, config.WebScheduling)
+		}
+		if config.PrometheusScheduling != nil || config.GrafanaScheduling != nil {
+			t.Fatal("Expected Prometheus and Grafana to be scheduled on any node")
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"kubernetes.io/arch: \"arm64\"",
+			"value: \"linkerd\"",
+			"effect: NoSchedule",
+			"operator: Exists",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid node selectors and tolerations", func(t *testing.T) {
+		for _, s := range []schedulingOptions{
+			{nodeSelector: []string{"kubernetes.io/arch"}},
+			{nodeSelector: []string{"kubernetes.io/arch=arm 64"}},
+			{nodeSelector: []string{"kubernetes.io/arch=s390x"}},
+			{tolerations: []string{"dedicated=linkerd:NoRun"}},
+			{tolerations: []string{":NoSchedule"}},
+		} {
+			options := newInstallOptions()
+			options.grafanaScheduling = s
+
+			if _, err := validateAndBuildConfig(options); err == nil {
+				t.Fatalf("Expected an error for %+v, got none", s)
+			}
+		}
+	})
+
+	t.Run("Records the architectures that the proxy images are published for", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyArchitectures = []string{"amd64", "arm64", "s390x"}
+		options.controllerScheduling.nodeSelector = []string{"kubernetes.io/arch=s390x"}
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ProxyArchitectures != "amd64,arm64,s390x" {
+			t.Fatalf("Expected the proxy architectures amd64,arm64,s390x, got %s", config.ProxyArchitectures)
+		}
+	})
+
+	t.Run("Configures the proxy injector", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyAutoInject = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		decodePEM := func(encoded string) []byte {
+			b, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			block, _ := pem.Decode(b)
+			if block == nil {
+				t.Fatalf("Expected PEM, got [%s]", b)
+			}
+			return block.Bytes
+		}
+		cert, err := x509.ParseCertificate(decodePEM(config.ProxyInjectorTLSCert))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		root, err := x509.ParseCertificate(decodePEM(config.ProxyInjectorCABundle))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(root)
+		dnsName := fmt.Sprintf("proxy-injector.%s.svc", controlPlaneNamespace)
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots}); err != nil {
+			t.Fatalf("Expected the webhook certificate to be valid for %s: %v", dnsName, err)
+		}
+
+		if !strings.Contains(config.ProxyInjectorSidecarConfig, "name: linkerd-proxy") {
+			t.Fatalf("Expected the sidecar config to contain the proxy, got [%s]", config.ProxyInjectorSidecarConfig)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "kind: MutatingWebhookConfiguration") {
+			t.Fatal("Expected the proxy injector webhook to be configured")
+		}
+	})
+
+	t.Run("Configures the ServiceProfile validator", func(t *testing.T) {
+		options := newInstallOptions()
+		options.profileValidation = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		certPEM, err := base64.StdEncoding.DecodeString(config.ProfileValidatorTLSCert)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			t.Fatalf("Expected PEM, got [%s]", certPEM)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		dnsName := fmt.Sprintf("sp-validator.%s.svc", controlPlaneNamespace)
+		if err := cert.VerifyHostname(dnsName); err != nil {
+			t.Fatalf("Expected the webhook certificate to be valid for %s: %v", dnsName, err)
+		}
+		if config.ProfileValidatorCABundle == "" {
+			t.Fatal("Expected the CA bundle of the webhook to be set")
+		}
+
+		var buf bytes.Buffer
+		if err := renderStage(*config, &buf, options, configStage); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{"kind: ValidatingWebhookConfiguration", "name: linkerd-sp-validator-tls", `resources: ["serviceprofiles"]`} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config stage to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), "kind: MutatingWebhookConfiguration") {
+			t.Fatal("Expected the proxy injector not to be configured")
+		}
+	})
+
+	t.Run("Renders the webhook with the cluster's admissionregistration version", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyAutoInject = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.WebhookAPIVersion != defaultWebhookAPIVersion {
+			t.Fatalf("Expected the webhook to default to %s, got %s", defaultWebhookAPIVersion, config.WebhookAPIVersion)
+		}
+
+		config.WebhookAPIVersion = "v1"
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{"apiVersion: admissionregistration.k8s.io/v1\n", "sideEffects: NoneOnDryRun\n"} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the webhook configuration to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Renders the install in stages", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyAutoInject = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		countKinds := func(content string) int {
+			count := 0
+			for _, line := range strings.Split(content, "\n") {
+				if strings.HasPrefix(line, "kind: ") {
+					count++
+				}
+			}
+			return count
+		}
+
+		rendered := map[string]string{}
+		for _, stage := range []string{"", configStage, controlPlaneStage} {
+			var buf bytes.Buffer
+			if err := renderStage(*config, &buf, options, stage); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			rendered[stage] = buf.String()
+		}
+
+		for _, expected := range []string{"kind: Namespace", "kind: ClusterRoleBinding", "kind: CustomResourceDefinition", "kind: MutatingWebhookConfiguration"} {
+			if !strings.Contains(rendered[configStage], expected) {
+				t.Fatalf("Expected the config stage to contain [%s]", expected)
+			}
+			if strings.Contains(rendered[controlPlaneStage], expected) {
+				t.Fatalf("Expected the control plane stage not to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(rendered[configStage], "kind: Deployment") || !strings.Contains(rendered[controlPlaneStage], "kind: Deployment") {
+			t.Fatal("Expected the deployments to be installed by the control plane stage")
+		}
+		if countKinds(rendered[configStage])+countKinds(rendered[controlPlaneStage]) != countKinds(rendered[""]) {
+			t.Fatal("Expected the stages to install all the resources of the install")
+		}
+	})
+
+	t.Run("Restricts the control plane to namespaces", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = optionalTLS
+		options.watchNamespaces = []string{"emojivoto", controlPlaneNamespace}
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := controlPlaneNamespace + ",emojivoto"
+		if config.WatchNamespaces != expected {
+			t.Fatalf("Expected watched namespaces %s, got %s", expected, config.WatchNamespaces)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"-watch-namespaces=" + expected,
+			"kind: Role\napiVersion: rbac.authorization.k8s.io/v1beta1\nmetadata:\n  name: linkerd-" + controlPlaneNamespace + "-ca\n  namespace: emojivoto\n",
+			"names: ['" + controlPlaneNamespace + "', 'emojivoto']",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), "name: linkerd-"+controlPlaneNamespace+"-prometheus\nrules:") {
+			t.Fatal("Expected Prometheus not to be granted a ClusterRole")
+		}
+	})
+
+	t.Run("Serves the tap API with a certificate verified by the Kubernetes API server", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tapRBAC = true
+		options.watchNamespaces = []string{"emojivoto"}
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		certPEM, err := base64.StdEncoding.DecodeString(config.TapAPIServerTLSCert)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			t.Fatalf("Expected PEM, got [%s]", certPEM)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		dnsName := fmt.Sprintf("api.%s.svc", controlPlaneNamespace)
+		if err := cert.VerifyHostname(dnsName); err != nil {
+			t.Fatalf("Expected the tap API certificate to be valid for %s: %v", dnsName, err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"caBundle: " + config.TapAPIServerCABundle + "\n",
+			"name: linkerd-tap-apiserver-tls",
+			"kind: RoleBinding\napiVersion: rbac.authorization.k8s.io/v1beta1\nmetadata:\n  name: linkerd-" + controlPlaneNamespace + "-web-tap\n  namespace: emojivoto\n",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), "name: linkerd-"+controlPlaneNamespace+"-web-tap\nroleRef:") {
+			t.Fatal("Expected the web not to be allowed to tap every namespace")
+		}
+	})
+
+	t.Run("Rejects invalid watched namespaces", func(t *testing.T) {
+		options := newInstallOptions()
+		options.watchNamespaces = []string{"not/a/namespace"}
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Renders manifests for the restricted pod security level", func(t *testing.T) {
+		options := newInstallOptions()
+		options.noInitContainer = true
+		options.restrictedPodSecurity = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"seccompProfile:\n          type: " + k8s.SeccompProfileTypeRuntimeDefault,
+			"runAsNonRoot: true",
+			"readOnlyRootFilesystem: true",
+			"allowPrivilegeEscalation: false",
+			"--storage.tsdb.path=/data",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), k8s.InitContainerName) {
+			t.Fatalf("Expected the config not to contain the %s container", k8s.InitContainerName)
+		}
+		if strings.Contains(buf.String(), k8s.PodSeccompAnnotation) {
+			t.Fatalf("Expected the config not to contain the deprecated %s annotation", k8s.PodSeccompAnnotation)
+		}
+	})
+
+	t.Run("Rejects the restricted pod security level without the CNI plugin", func(t *testing.T) {
+		options := newInstallOptions()
+		options.restrictedPodSecurity = true
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Registers the metrics adapter with the Kubernetes API server", func(t *testing.T) {
+		options := newInstallOptions()
+		options.metricsAdapter = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"name: v1beta1.custom.metrics.k8s.io",
+			"name: linkerd-metrics-adapter",
+			"name: linkerd-linkerd-controller-auth-reader",
+			"-metrics-adapter-addr=:8444",
+			"caBundle: " + config.MetricsAdapterCABundle + "\n",
+			"secretName: linkerd-metrics-adapter-tls",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), "insecureSkipTLSVerify") {
+			t.Fatal("Expected the Kubernetes API server to verify the metrics adapter's certificate")
+		}
+
+		certPEM, err := base64.StdEncoding.DecodeString(config.MetricsAdapterTLSCert)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			t.Fatalf("Expected PEM, got [%s]", certPEM)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		dnsName := fmt.Sprintf("linkerd-metrics-adapter.%s.svc", controlPlaneNamespace)
+		if err := cert.VerifyHostname(dnsName); err != nil {
+			t.Fatalf("Expected the metrics adapter certificate to be valid for %s: %v", dnsName, err)
+		}
+		if strings.Contains(buf.String(), "v1alpha1.tap.linkerd.io") {
+			t.Fatal("Expected the config not to register the tap API")
+		}
+	})
+
+	t.Run("Traces the requests of the control plane's proxies", func(t *testing.T) {
+		options := newInstallOptions()
+		options.traceCollector = "oc-collector.tracing:55678"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"linkerd.io/trace-collector: oc-collector.tracing:55678",
+			"linkerd.io/trace-propagation: b3",
+			"name: LINKERD2_PROXY_TRACE_COLLECTOR_SVC_ADDR",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid trace collectors", func(t *testing.T) {
+		options := newInstallOptions()
+		options.traceCollector = "oc-collector.tracing"
+
+		if _, err := validateAndBuildConfig(options); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Serves the identity service and certifies the proxies with it", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"-identity-addr=:8083",
+			"kind: Service\napiVersion: v1\nmetadata:\n  name: " + k8s.IdentityServiceName + "\n",
+			"resources: [\"tokenreviews\"]",
+			k8s.IdentityModeAnnotation + ": " + k8s.IdentityModeServiceAccount,
+			"value: linkerd-identity." + controlPlaneNamespace + ".svc.cluster.local:8083",
+			"image: gcr.io/linkerd-io/proxy:identity-dev",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(buf.String(), k8s.TLSSecretsVolumeName) {
+			t.Fatalf("Expected the proxies not to mount the %s volume", k8s.TLSSecretsVolumeName)
+		}
+	})
+
+	t.Run("Doesn't inject the CA that serves the identity service", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "  name: ca\n  namespace: " + controlPlaneNamespace + "\n"
+		start := strings.Index(buf.String(), expected)
+		if start < 0 {
+			t.Fatalf("Expected the config to contain [%s]", expected)
+		}
+		ca := buf.String()[start:]
+		if end := strings.Index(ca, "\n---"); end >= 0 {
+			ca = ca[:end]
+		}
+		if !strings.Contains(ca, k8s.ProxyInjectAnnotation+": "+k8s.ProxyInjectDisabled) {
+			t.Fatalf("Expected the CA's pods to be annotated with %s: %s", k8s.ProxyInjectAnnotation, k8s.ProxyInjectDisabled)
+		}
+		if strings.Contains(ca, "name: "+k8s.ProxyContainerName) {
+			t.Fatal("Expected the CA not to be injected")
+		}
+	})
+
+	t.Run("Rejects the identity service without a proxy version", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil || !strings.Contains(err.Error(), "--proxy-version") {
+			t.Fatalf("Expected an error about --proxy-version, got: %v", err)
+		}
+	})
+
+	t.Run("Configures the proxy injector with the TLS mode", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.tlsMode = k8s.TLSModeStrict
+		options.proxyAutoInject = true
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if count := strings.Count(buf.String(), "- \"-tls-mode=strict\"\n"); count != 1 {
+			t.Fatalf("Expected the proxy injector to be configured with the strict TLS mode, got %d", count)
+		}
+	})
+
+	t.Run("Rejects the strict TLS mode without the identity service", func(t *testing.T) {
+		for _, tls := range []string{"", optionalTLS} {
+			options := newInstallOptions()
+			options.tls = tls
+			options.tlsMode = k8s.TLSModeStrict
+
+			if _, err := validateAndBuildConfig(options); err == nil {
+				t.Fatalf("Expected error with --tls=%s, got nothing", tls)
+			}
+		}
+	})
+
+	t.Run("Rejects unknown TLS modes", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.tlsMode = "mandatory"
+
+		if _, err := validateAndBuildConfig(options); err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+	})
+
+	t.Run("Sources the issuer credentials from a Secret", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.identityIssuerSecret = "linkerd-identity-issuer"
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"-issuer-secret=linkerd-identity-issuer",
+			"kind: Role\napiVersion: rbac.authorization.k8s.io/v1beta1\nmetadata:\n  name: linkerd-" + controlPlaneNamespace + "-identity-issuer\n",
+			"  resources: [\"secrets\"]\n  resourceNames: [linkerd-identity-issuer]\n  verbs: [\"get\", \"list\", \"watch\"]\n",
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects issuer Secrets without TLS", func(t *testing.T) {
+		options := newInstallOptions()
+		options.identityIssuerSecret = "linkerd-identity-issuer"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+	})
+
+	t.Run("Issues certificates with the provided issuer", func(t *testing.T) {
+		anchorCert, anchorKey := newTestIssuer(t, nil, nil)
+		issuerCert, issuerKey := newTestIssuer(t, anchorCert, anchorKey)
+
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.identityIssuerFiles = identityIssuerFiles{
+			TrustAnchors: writeTempFile(t, "trust-anchors", encodeTestCertificate(anchorCert)),
+			Certificate:  writeTempFile(t, "issuer-certificate", encodeTestCertificate(issuerCert)),
+			Key:          writeTempFile(t, "issuer-key", encodeTestKey(t, issuerKey)),
+		}
+		defer os.Remove(options.identityIssuerFiles.TrustAnchors)
+		defer os.Remove(options.identityIssuerFiles.Certificate)
+		defer os.Remove(options.identityIssuerFiles.Key)
+
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.IdentityIssuerSecret != k8s.IdentityIssuerSecretName {
+			t.Fatalf("Expected the issuer to be read from the %s Secret, got [%s]", k8s.IdentityIssuerSecretName, config.IdentityIssuerSecret)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			"-issuer-secret=" + k8s.IdentityIssuerSecretName,
+			"kind: Secret\napiVersion: v1\nmetadata:\n  name: " + k8s.IdentityIssuerSecretName + "\n",
+			"tls.key: " + base64.StdEncoding.EncodeToString([]byte(encodeTestKey(t, issuerKey))),
+			"ca.crt: " + base64.StdEncoding.EncodeToString([]byte(encodeTestCertificate(anchorCert))),
+		} {
+			if !strings.Contains(buf.String(), expected) {
+				t.Fatalf("Expected the config to contain [%s]", expected)
+			}
+		}
+	})
+
+	t.Run("Rejects issuers that don't chain to the trust anchors", func(t *testing.T) {
+		anchorCert, _ := newTestIssuer(t, nil, nil)
+		issuerCert, issuerKey := newTestIssuer(t, nil, nil)
+
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.identityIssuerFiles = identityIssuerFiles{
+			TrustAnchors: writeTempFile(t, "trust-anchors", encodeTestCertificate(anchorCert)),
+			Certificate:  writeTempFile(t, "issuer-certificate", encodeTestCertificate(issuerCert)),
+			Key:          writeTempFile(t, "issuer-key", encodeTestKey(t, issuerKey)),
+		}
+		defer os.Remove(options.identityIssuerFiles.TrustAnchors)
+		defer os.Remove(options.identityIssuerFiles.Certificate)
+		defer os.Remove(options.identityIssuerFiles.Key)
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil || !strings.Contains(err.Error(), "doesn't chain to the trust anchors") {
+			t.Fatalf("Expected an error about the trust anchors, got: %v", err)
+		}
+	})
+
+	t.Run("Rejects issuer certificates without their key", func(t *testing.T) {
+		options := newInstallOptions()
+		options.tls = identityTLS
+		options.proxyVersion = "identity-dev"
+		options.identityIssuerFiles.Certificate = "issuer.crt"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+	})
+
+	t.Run("Rejects invalid trust domains", func(t *testing.T) {
+		options := newInstallOptions()
+		options.trustDomain = "not/a/domain"
+
+		_, err := validateAndBuildConfig(options)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+// newTestIssuer returns a CA certificate and its key, signed by the parent, or
+// self-signed if there's no parent.
+func newTestIssuer(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return crt, key
+}
+
+func encodeTestCertificate(crt *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw}))
+}
+
+func encodeTestKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	file, err := ioutil.TempFile("", name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return file.Name()
+}
+
+func TestSetFlagsFromValuesFile(t *testing.T) {
+	writeValues := func(values string) string {
+		file, err := ioutil.TempFile("", "values")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		file.WriteString(values)
+		file.Close()
+		return file.Name()
+	}
+
+	var skipPorts []uint
+	newFlags := func(options *installOptions) *pflag.FlagSet {
+		flags := pflag.NewFlagSet("install", pflag.ContinueOnError)
+		flags.StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "")
+		flags.UintVar(&options.controllerReplicas, "controller-replicas", options.controllerReplicas, "")
+		flags.StringVar(&options.proxyLogLevel, "proxy-log-level", options.proxyLogLevel, "")
+		flags.UintSliceVar(&skipPorts, "skip-ports", nil, "")
+		flags.StringVarP(&options.valuesFile, "values", "f", options.valuesFile, "")
+		return flags
+	}
+
+	t.Run("Sets the flags that weren't given on the command line", func(t *testing.T) {
+		path := writeValues(`
+registry: registry.example.com/linkerd
+controller-replicas: 3
+proxy-log-level: debug
+skip-ports: [25, 3306]
+`)
+		defer os.Remove(path)
+
+		options := newInstallOptions()
+		flags := newFlags(options)
+		if err := flags.Parse([]string{"--proxy-log-level", "info"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := setFlagsFromValuesFile(flags, path); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if options.dockerRegistry != "registry.example.com/linkerd" {
+			t.Fatalf("Expected registry registry.example.com/linkerd, got %s", options.dockerRegistry)
+		}
+		if options.controllerReplicas != 3 {
+			t.Fatalf("Expected 3 controller replicas, got %d", options.controllerReplicas)
+		}
+		if options.proxyLogLevel != "info" {
+			t.Fatalf("Expected the command line to take precedence, got proxy log level %s", options.proxyLogLevel)
+		}
+		if len(skipPorts) != 2 || skipPorts[0] != 25 || skipPorts[1] != 3306 {
+			t.Fatalf("Expected ports [25 3306] to be skipped, got %v", skipPorts)
+		}
+	})
+
+	for _, values := range []string{
+		"unknown-flag: true",
+		"values: other-values.yaml",
+		"controller-replicas: three",
+		"registry:\n  host: registry.example.com",
+	} {
+		values := values
+		t.Run(fmt.Sprintf("Rejects %q", values), func(t *testing.T) {
+			path := writeValues(values)
+			defer os.Remove(path)
+
+			options := newInstallOptions()
+			if err := setFlagsFromValuesFile(newFlags(options), path); err == nil {
+				t.Fatal("Expected an error, got none")
+			}
+		})
+	}
+}
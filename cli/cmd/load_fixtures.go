@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// fixtureLabel is set on every namespace created by load-fixtures, with
+	// the namespace prefix as its value, so that fixtures can be torn down.
+	fixtureLabel = "linkerd.io/load-fixture"
+
+	fixtureServerImage  = "buoyantio/bb:v0.0.1"
+	fixtureTrafficImage = "buoyantio/slow_cooker:1.1.1"
+	fixturePort         = 8080
+
+	// every fixture pod runs a server and a traffic generator container
+	fixtureContainersPerPod = 2
+)
+
+type loadFixturesOptions struct {
+	namespacePrefix string
+	namespaces      uint
+	workloads       uint
+	replicas        uint
+	qps             uint
+	cpuRequest      string
+	memoryRequest   string
+	maxCPU          string
+	maxMemory       string
+	teardown        bool
+	*injectOptions
+}
+
+func newLoadFixturesOptions() *loadFixturesOptions {
+	return &loadFixturesOptions{
+		namespacePrefix: "linkerd-fixture",
+		namespaces:      1,
+		workloads:       3,
+		replicas:        1,
+		qps:             1,
+		cpuRequest:      "10m",
+		memoryRequest:   "16Mi",
+		maxCPU:          "4",
+		maxMemory:       "8Gi",
+		teardown:        false,
+		injectOptions:   newInjectOptions(),
+	}
+}
+
+func newCmdLoadFixtures() *cobra.Command {
+	options := newLoadFixturesOptions()
+
+	cmd := &cobra.Command{
+		Use:   "load-fixtures [flags]",
+		Short: "Output Kubernetes configs for meshed workloads generating synthetic traffic",
+		Long: `Output Kubernetes configs for meshed workloads generating synthetic traffic.
+
+The load-fixtures command outputs the configs for a number of namespaces, each
+containing a number of meshed workloads. Every workload serves HTTP requests
+and sends requests to the next workload of its namespace, so that check, stat,
+tap and the dashboard can be exercised at scale.
+
+The resources requested by the fixtures are checked against a budget before
+any config is output. The fixtures can be removed from the cluster with the
+"--teardown" flag.`,
+		Example: `  # Create 10 namespaces with 20 workloads each
+  linkerd alpha load-fixtures --namespaces 10 --workloads 20 | kubectl apply -f -
+
+  # Delete the namespaces created above
+  linkerd alpha load-fixtures --teardown`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.teardown {
+				kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+				if err != nil {
+					return err
+				}
+
+				clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+				if err != nil {
+					return err
+				}
+
+				return teardownFixtures(os.Stdout, clientset, options.namespacePrefix)
+			}
+
+			if err := options.validate(); err != nil {
+				return err
+			}
+
+			cpu, memory, err := options.budget()
+			if err != nil {
+				return err
+			}
+
+			if err := renderFixtures(os.Stdout, options); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "%d pods requesting %s CPU and %s of memory\n", options.pods(), cpu.String(), memory.String())
+			return nil
+		},
+	}
+
+	addProxyConfigFlags(cmd, options.proxyConfigOptions)
+	cmd.PersistentFlags().StringVar(&options.namespacePrefix, "namespace-prefix", options.namespacePrefix, "Prefix of the fixture namespaces")
+	cmd.PersistentFlags().UintVar(&options.namespaces, "namespaces", options.namespaces, "Number of fixture namespaces")
+	cmd.PersistentFlags().UintVar(&options.workloads, "workloads", options.workloads, "Number of workloads in each namespace")
+	cmd.PersistentFlags().UintVar(&options.replicas, "replicas", options.replicas, "Number of replicas of each workload")
+	cmd.PersistentFlags().UintVar(&options.qps, "qps", options.qps, "Requests per second sent by each replica")
+	cmd.PersistentFlags().StringVar(&options.cpuRequest, "cpu-request", options.cpuRequest, "CPU requested by each fixture container")
+	cmd.PersistentFlags().StringVar(&options.memoryRequest, "memory-request", options.memoryRequest, "Memory requested by each fixture container")
+	cmd.PersistentFlags().StringVar(&options.maxCPU, "max-cpu", options.maxCPU, "Maximum CPU requested by all the fixtures")
+	cmd.PersistentFlags().StringVar(&options.maxMemory, "max-memory", options.maxMemory, "Maximum memory requested by all the fixtures")
+	cmd.PersistentFlags().BoolVar(&options.teardown, "teardown", options.teardown, "Delete the fixture namespaces with the given prefix from the cluster, instead of outputting configs")
+
+	return cmd
+}
+
+func (options *loadFixturesOptions) validate() error {
+	if !alphaNumDash.MatchString(options.namespacePrefix) {
+		return fmt.Errorf("%s is not a valid namespace prefix", options.namespacePrefix)
+	}
+	if options.namespaces == 0 || options.workloads == 0 || options.replicas == 0 {
+		return fmt.Errorf("--namespaces, --workloads and --replicas must be at least 1")
+	}
+	return options.injectOptions.validate()
+}
+
+func (options *loadFixturesOptions) pods() uint {
+	return options.namespaces * options.workloads * options.replicas
+}
+
+// budget returns the CPU and memory requested by all the fixture containers,
+// or an error if they exceed the maximums. The proxies don't request any
+// resources, so they are not accounted for.
+func (options *loadFixturesOptions) budget() (*resource.Quantity, *resource.Quantity, error) {
+	quantities := map[string]resource.Quantity{}
+	for flag, value := range map[string]string{
+		"cpu-request":    options.cpuRequest,
+		"memory-request": options.memoryRequest,
+		"max-cpu":        options.maxCPU,
+		"max-memory":     options.maxMemory,
+	} {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --%s [%s]: %s", flag, value, err)
+		}
+		quantities[flag] = q
+	}
+
+	containers := int64(options.pods() * fixtureContainersPerPod)
+	cpuRequest, memoryRequest := quantities["cpu-request"], quantities["memory-request"]
+	cpu := resource.NewMilliQuantity(cpuRequest.MilliValue()*containers, resource.DecimalSI)
+	memory := resource.NewQuantity(memoryRequest.Value()*containers, resource.BinarySI)
+
+	if maxCPU := quantities["max-cpu"]; cpu.Cmp(maxCPU) > 0 {
+		return nil, nil, fmt.Errorf("the fixtures request %s CPU, which exceeds --max-cpu [%s]", cpu.String(), options.maxCPU)
+	}
+	if maxMemory := quantities["max-memory"]; memory.Cmp(maxMemory) > 0 {
+		return nil, nil, fmt.Errorf("the fixtures request %s of memory, which exceeds --max-memory [%s]", memory.String(), options.maxMemory)
+	}
+
+	return cpu, memory, nil
+}
+
+// renderFixtures writes the configs of every fixture namespace to w, with the
+// proxy injected into every workload.
+func renderFixtures(w io.Writer, options *loadFixturesOptions) error {
+	for i := uint(0); i < options.namespaces; i++ {
+		namespace := fmt.Sprintf("%s-%d", options.namespacePrefix, i)
+
+		err := writeFixture(w, &v1.Namespace{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: map[string]string{fixtureLabel: options.namespacePrefix},
+			},
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		for j := uint(0); j < options.workloads; j++ {
+			name := fixtureWorkloadName(j)
+			target := fixtureWorkloadName((j + 1) % options.workloads)
+
+			err := writeFixture(w, fixtureService(namespace, name), nil)
+			if err != nil {
+				return err
+			}
+
+			err = writeFixture(w, fixtureDeployment(namespace, name, target, options), options.injectOptions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeFixture writes obj to w as YAML, injecting the proxy into it if
+// injectOptions is set.
+func writeFixture(w io.Writer, obj interface{}, injectOptions *injectOptions) error {
+	bytes, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	if injectOptions != nil {
+		bytes, err = injectResource(bytes, injectOptions, &injectReport{})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Write(bytes)
+	w.Write([]byte("---\n"))
+	return nil
+}
+
+func fixtureWorkloadName(i uint) string {
+	return fmt.Sprintf("workload-%d", i)
+}
+
+func fixtureService(namespace, name string) *v1.Service {
+	return &v1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": name},
+			Ports: []v1.ServicePort{
+				{
+					Name:       "http",
+					Port:       fixturePort,
+					TargetPort: intstr.FromInt(fixturePort),
+				},
+			},
+		},
+	}
+}
+
+func fixtureDeployment(namespace, name, target string, options *loadFixturesOptions) *v1beta1.Deployment {
+	replicas := int32(options.replicas)
+	requests := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(options.cpuRequest),
+		v1.ResourceMemory: resource.MustParse(options.memoryRequest),
+	}
+
+	return &v1beta1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "extensions/v1beta1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": name},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "server",
+							Image: fixtureServerImage,
+							Args: []string{
+								"terminus",
+								fmt.Sprintf("--h1-server-port=%d", fixturePort),
+								fmt.Sprintf("--response-text=%s", name),
+							},
+							Ports:     []v1.ContainerPort{{ContainerPort: fixturePort}},
+							Resources: v1.ResourceRequirements{Requests: requests},
+						},
+						{
+							Name:    "traffic",
+							Image:   fixtureTrafficImage,
+							Command: []string{"slow_cooker"},
+							Args: []string{
+								"-qps", strconv.FormatUint(uint64(options.qps), 10),
+								"-concurrency", "1",
+								fmt.Sprintf("http://%s:%d", target, fixturePort),
+							},
+							Resources: v1.ResourceRequirements{Requests: requests},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// teardownFixtures deletes the fixture namespaces created with prefix, along
+// with all their workloads.
+func teardownFixtures(w io.Writer, clientset kubernetes.Interface, prefix string) error {
+	namespaces, err := clientset.CoreV1().Namespaces().List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", fixtureLabel, prefix),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(namespaces.Items) == 0 {
+		fmt.Fprintf(w, "No fixture namespaces found with prefix %s\n", prefix)
+		return nil
+	}
+
+	for _, namespace := range namespaces.Items {
+		err := clientset.CoreV1().Namespaces().Delete(namespace.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "namespace \"%s\" deleted\n", namespace.Name)
+	}
+
+	return nil
+}
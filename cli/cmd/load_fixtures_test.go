@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRenderFixtures(t *testing.T) {
+	options := newLoadFixturesOptions()
+	options.linkerdVersion = "testinjectversion"
+	options.namespaces = 2
+	options.workloads = 2
+
+	var buf bytes.Buffer
+	if err := renderFixtures(&buf, options); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kinds := []string{}
+	deployments := []v1beta1.Deployment{}
+	for _, doc := range strings.Split(buf.String(), "---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+
+		var meta metav1.TypeMeta
+		if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		kinds = append(kinds, meta.Kind)
+
+		if meta.Kind == "Deployment" {
+			var deployment v1beta1.Deployment
+			if err := yaml.Unmarshal([]byte(doc), &deployment); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	expectedKinds := "Namespace,Service,Deployment,Service,Deployment,Namespace,Service,Deployment,Service,Deployment"
+	if strings.Join(kinds, ",") != expectedKinds {
+		t.Fatalf("Expected kinds %s, got %s", expectedKinds, strings.Join(kinds, ","))
+	}
+
+	for _, deployment := range deployments {
+		containers := map[string]v1.Container{}
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			containers[container.Name] = container
+		}
+		if _, ok := containers[k8s.ProxyContainerName]; !ok {
+			t.Fatalf("Expected the proxy to be injected into %s/%s", deployment.Namespace, deployment.Name)
+		}
+	}
+
+	target := deployments[0].Spec.Template.Spec.Containers[1].Args
+	if url := target[len(target)-1]; url != "http://workload-1:8080" {
+		t.Fatalf("Expected workload-0 to send requests to workload-1, got %s", url)
+	}
+	target = deployments[1].Spec.Template.Spec.Containers[1].Args
+	if url := target[len(target)-1]; url != "http://workload-0:8080" {
+		t.Fatalf("Expected workload-1 to send requests to workload-0, got %s", url)
+	}
+}
+
+func TestLoadFixturesBudget(t *testing.T) {
+	t.Run("Returns the requested resources", func(t *testing.T) {
+		options := newLoadFixturesOptions()
+		options.namespaces = 2
+		options.workloads = 5
+
+		cpu, memory, err := options.budget()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cpu.String() != "200m" || memory.String() != "320Mi" {
+			t.Fatalf("Expected 200m CPU and 320Mi of memory, got %s and %s", cpu.String(), memory.String())
+		}
+	})
+
+	t.Run("Rejects fixtures over budget", func(t *testing.T) {
+		options := newLoadFixturesOptions()
+		options.namespaces = 100
+		options.workloads = 10
+
+		_, _, err := options.budget()
+		expected := "the fixtures request 20 CPU, which exceeds --max-cpu [4]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Rejects invalid quantities", func(t *testing.T) {
+		options := newLoadFixturesOptions()
+		options.maxMemory = "lots"
+
+		if _, _, err := options.budget(); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestTeardownFixtures(t *testing.T) {
+	namespace := func(name, prefix string) *v1.Namespace {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if prefix != "" {
+			ns.Labels = map[string]string{fixtureLabel: prefix}
+		}
+		return ns
+	}
+
+	clientset := fake.NewSimpleClientset(
+		namespace("linkerd-fixture-0", "linkerd-fixture"),
+		namespace("other-0", "other"),
+		namespace("emojivoto", ""),
+	)
+
+	var buf bytes.Buffer
+	if err := teardownFixtures(&buf, clientset, "linkerd-fixture"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "namespace \"linkerd-fixture-0\" deleted\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected output [%s], got [%s]", expected, buf.String())
+	}
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(namespaces.Items) != 2 {
+		t.Fatalf("Expected 2 namespaces to remain, got %d", len(namespaces.Items))
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type logsOptions struct {
+	container string
+	since     time.Duration
+	follow    bool
+}
+
+// podContainer identifies a single container in a control plane pod whose
+// logs should be streamed.
+type podContainer struct {
+	pod       string
+	container string
+}
+
+func (pc podContainer) String() string {
+	return fmt.Sprintf("%s %s", pc.pod, pc.container)
+}
+
+func newLogsOptions() *logsOptions {
+	return &logsOptions{
+		container: "",
+		since:     0,
+		follow:    true,
+	}
+}
+
+func newCmdLogs() *cobra.Command {
+	options := newLogsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "logs [flags]",
+		Short: "Tail logs from the Linkerd control plane containers",
+		Long: `Tail logs from the Linkerd control plane containers.
+
+Logs from every container in every control plane pod (controller, prometheus,
+grafana, web, and their proxies) are streamed and interleaved line by line.
+Each line is prefixed with the pod and container it came from.`,
+		Example: `  # tail logs from all control plane containers
+  linkerd logs
+
+  # tail the last 10 minutes of logs from the proxies in the control plane
+  linkerd logs --container linkerd-proxy --since 10m
+
+  # print the destination container logs and exit
+  linkerd logs --container destination --follow=false`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.since < 0 {
+				return fmt.Errorf("--since must be greater than or equal to zero, was %s", options.since)
+			}
+
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+			if err != nil {
+				return err
+			}
+
+			return streamControlPlaneLogs(os.Stdout, os.Stderr, clientset, options)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.container, "container", "c", options.container, "Only show logs from containers with this name (for example: \"destination\", \"linkerd-proxy\")")
+	cmd.PersistentFlags().DurationVar(&options.since, "since", options.since, "Only show logs newer than a relative duration like 5s, 2m, or 3h (when set to 0, all logs are shown)")
+	cmd.PersistentFlags().BoolVarP(&options.follow, "follow", "f", options.follow, "Keep streaming logs as they are written")
+
+	return cmd
+}
+
+// streamControlPlaneLogs streams the logs of every matching control plane
+// container to w, one line at a time. It returns once all streams have ended,
+// which only happens without --follow or when the pods go away. The streams
+// that fail are reported to errW, and an error is returned if they all fail.
+func streamControlPlaneLogs(w, errW io.Writer, clientset kubernetes.Interface, options *logsOptions) error {
+	podList, err := clientset.CoreV1().Pods(controlPlaneNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	targets := logTargets(podList.Items, options.container)
+	if len(targets) == 0 {
+		if options.container != "" {
+			return fmt.Errorf("no \"%s\" containers found in the \"%s\" namespace", options.container, controlPlaneNamespace)
+		}
+		return fmt.Errorf("no pods found in the \"%s\" namespace", controlPlaneNamespace)
+	}
+
+	lines := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target podContainer) {
+			defer wg.Done()
+			err := streamContainerLogs(clientset, target, options, lines)
+			if err != nil {
+				mu.Lock()
+				failed++
+				fmt.Fprintf(errW, "Failed to stream the logs of %s: %s\n", target, err)
+				mu.Unlock()
+			}
+		}(target)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	for line := range lines {
+		fmt.Fprintln(w, line)
+	}
+
+	if failed == len(targets) {
+		return fmt.Errorf("failed to stream the logs of any container in the \"%s\" namespace", controlPlaneNamespace)
+	}
+	return nil
+}
+
+func streamContainerLogs(clientset kubernetes.Interface, target podContainer, options *logsOptions, lines chan<- string) error {
+	logOptions := &v1.PodLogOptions{
+		Container: target.container,
+		Follow:    options.follow,
+	}
+	if options.since > 0 {
+		sinceSeconds := int64(options.since.Seconds())
+		logOptions.SinceSeconds = &sinceSeconds
+	}
+
+	stream, err := clientset.CoreV1().Pods(controlPlaneNamespace).GetLogs(target.pod, logOptions).Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		lines <- fmt.Sprintf("%s %s", target, scanner.Text())
+	}
+
+	return scanner.Err()
+}
+
+// logTargets returns the containers whose logs should be streamed, sorted by
+// pod and container name. If container is not empty, only containers with
+// that name are returned.
+func logTargets(pods []v1.Pod, container string) []podContainer {
+	targets := make([]podContainer, 0)
+
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		for _, c := range pod.Spec.Containers {
+			if container == "" || c.Name == container {
+				targets = append(targets, podContainer{pod: pod.Name, container: c.Name})
+			}
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].pod != targets[j].pod {
+			return targets[i].pod < targets[j].pod
+		}
+		return targets[i].container < targets[j].container
+	})
+
+	return targets
+}
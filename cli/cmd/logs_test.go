@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLogTargets(t *testing.T) {
+	newPod := func(name string, phase v1.PodPhase, containers ...string) v1.Pod {
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+		for _, c := range containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: c})
+		}
+		return pod
+	}
+
+	pods := []v1.Pod{
+		newPod("web-1", v1.PodRunning, "web", "linkerd-proxy"),
+		newPod("controller-1", v1.PodRunning, "public-api", "destination", "linkerd-proxy"),
+		newPod("grafana-1", v1.PodPending, "grafana", "linkerd-proxy"),
+	}
+
+	t.Run("Returns all containers of running pods, sorted", func(t *testing.T) {
+		expected := []podContainer{
+			{pod: "controller-1", container: "destination"},
+			{pod: "controller-1", container: "linkerd-proxy"},
+			{pod: "controller-1", container: "public-api"},
+			{pod: "web-1", container: "linkerd-proxy"},
+			{pod: "web-1", container: "web"},
+		}
+
+		actual := logTargets(pods, "")
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Expected targets %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("Filters containers by name", func(t *testing.T) {
+		expected := []podContainer{
+			{pod: "controller-1", container: "linkerd-proxy"},
+			{pod: "web-1", container: "linkerd-proxy"},
+		}
+
+		actual := logTargets(pods, "linkerd-proxy")
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Expected targets %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("Returns no targets when no container matches", func(t *testing.T) {
+		actual := logTargets(pods, "nonexistent")
+		if len(actual) != 0 {
+			t.Fatalf("Expected no targets, got %v", actual)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	"github.com/spf13/cobra"
+)
+
+type profileOptions struct {
+	namespace string
+	template  bool
+	validate  string
+}
+
+func newProfileOptions() *profileOptions {
+	return &profileOptions{
+		namespace: "default",
+	}
+}
+
+func (options *profileOptions) validateOptions(args []string) error {
+	if options.template == (options.validate != "") {
+		return errors.New("exactly one of --template or --validate must be specified")
+	}
+	if options.template && len(args) != 1 {
+		return errors.New("--template requires the name of the service")
+	}
+	if options.validate != "" && len(args) != 0 {
+		return errors.New("--validate doesn't take a service")
+	}
+	return nil
+}
+
+func newCmdProfile() *cobra.Command {
+	options := newProfileOptions()
+
+	cmd := &cobra.Command{
+		Use:   "profile [flags] (--template SERVICE | --validate FILE)",
+		Short: "Output or validate the ServiceProfile of a service",
+		Long: `Output or validate the ServiceProfile of a service.
+
+  A ServiceProfile describes the routes of a service, so that the proxies
+  report metrics by route and classify the responses of each route. It's named
+  after the fully-qualified domain name of the service, and lives in the
+  namespace of the service.`,
+		Example: `  # Output a template of the ServiceProfile of the web service in the emojivoto namespace.
+  linkerd profile --template web -n emojivoto > web-profile.yaml
+
+  # Validate a ServiceProfile before applying it.
+  linkerd profile --validate web-profile.yaml`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validateOptions(args); err != nil {
+				return err
+			}
+
+			if options.template {
+				return profiles.RenderTemplate(args[0], options.namespace, os.Stdout)
+			}
+
+			config, err := ioutil.ReadFile(options.validate)
+			if err != nil {
+				return err
+			}
+			if err := profiles.Validate(config); err != nil {
+				return fmt.Errorf("%s is not a valid ServiceProfile: %s", options.validate, err)
+			}
+			fmt.Printf("%s is a valid ServiceProfile\n", options.validate)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the service")
+	cmd.PersistentFlags().BoolVar(&options.template, "template", options.template, "Output a ServiceProfile template")
+	cmd.PersistentFlags().StringVar(&options.validate, "validate", options.validate, "Validate the ServiceProfile in the file")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/profiles"
+)
+
+func TestRenderProfileTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	err := profiles.RenderTemplate("web", "emojivoto", &buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	diffCompare(t, buf.String(), readOptionalTestFile(t, "profile_template.golden"))
+
+	if err := profiles.Validate(buf.Bytes()); err != nil {
+		t.Fatalf("Expected the template to be a valid ServiceProfile, got: %s", err)
+	}
+}
+
+func TestValidateProfileOptions(t *testing.T) {
+	expectations := map[string]struct {
+		options *profileOptions
+		args    []string
+	}{
+		"exactly one of --template or --validate must be specified": {
+			options: &profileOptions{},
+		},
+		"--template requires the name of the service": {
+			options: &profileOptions{template: true},
+		},
+		"--validate doesn't take a service": {
+			options: &profileOptions{validate: "web.yaml"},
+			args:    []string{"web"},
+		},
+	}
+
+	for msg, tc := range expectations {
+		err := tc.options.validateOptions(tc.args)
+		if err == nil || err.Error() != msg {
+			t.Fatalf("Expected error [%s], got [%v]", msg, err)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/cli/output"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	proxyControlURLEnvVar  = "LINKERD2_PROXY_CONTROL_URL"
+	proxyPodIdentityEnvVar = "LINKERD2_PROXY_TLS_POD_IDENTITY"
+)
+
+type proxyBootstrapOptions struct {
+	namespace string
+}
+
+// proxyBootstrap replays the steps a proxy goes through when it starts: it
+// reads its configuration, resolves the control plane, loads its TLS
+// identity, and opens destination streams. Each step records what it found
+// for the steps that follow.
+type proxyBootstrap struct {
+	clientset kubernetes.Interface
+	apiClient pb.ApiClient
+
+	pod         *v1.Pod
+	proxy       *v1.Container
+	controlURL  *url.URL
+	controlNS   string
+	dnsZone     string
+	podIdentity string
+}
+
+type proxyBootstrapStep struct {
+	description string
+	run         func() (string, error)
+}
+
+func newProxyBootstrapOptions() *proxyBootstrapOptions {
+	return &proxyBootstrapOptions{
+		namespace: "default",
+	}
+}
+
+func newCmdProxyBootstrap() *cobra.Command {
+	options := newProxyBootstrapOptions()
+
+	cmd := &cobra.Command{
+		Use:   "proxy-bootstrap [flags] POD",
+		Short: "Replay the bootstrap sequence of a pod's proxy",
+		Long: `Replay the bootstrap sequence of a pod's proxy.
+
+The proxy-bootstrap command goes through the same steps as the proxy in the
+given pod when it starts: it reads the proxy's configuration, resolves the
+control plane address, checks that the TLS identity issued for the pod's
+service account is valid, and opens destination streams for the services that
+select the pod. The result of each step is printed, and the sequence stops at
+the first step that fails.`,
+		Example: `  # Replay the bootstrap sequence of the proxy in the web-1 pod
+  linkerd diagnostics proxy-bootstrap web-1 --namespace emojivoto`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+
+			clientset, err := kubernetes.NewForConfig(kubeAPI.Config)
+			if err != nil {
+				return err
+			}
+
+			bootstrap := &proxyBootstrap{
+				clientset: clientset,
+				apiClient: validatedPublicAPIClient(false),
+			}
+
+			if !bootstrap.run(os.Stdout, options.namespace, args[0]) {
+				os.Exit(2)
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the pod")
+
+	return cmd
+}
+
+// run executes every bootstrap step in order, writing the result of each one
+// to w. It returns false as soon as a step fails.
+func (b *proxyBootstrap) run(w io.Writer, namespace, podName string) bool {
+	steps := []proxyBootstrapStep{
+		{
+			description: "read proxy configuration",
+			run:         func() (string, error) { return b.readConfig(namespace, podName) },
+		},
+		{
+			description: "resolve control plane address",
+			run:         b.resolveControlPlane,
+		},
+		{
+			description: "load TLS identity",
+			run:         b.loadIdentity,
+		},
+		{
+			description: "open destination streams",
+			run:         b.openDestinationStreams,
+		},
+	}
+
+	for _, step := range steps {
+		detail, err := step.run()
+		if err != nil {
+			output.StatusLine(w, step.description, output.Fail, err.Error())
+			return false
+		}
+		output.StatusLine(w, step.description, output.Ok, detail)
+	}
+
+	return true
+}
+
+func (b *proxyBootstrap) readConfig(namespace, podName string) (string, error) {
+	pod, err := b.clientset.CoreV1().Pods(namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	b.pod = pod
+
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == k8s.ProxyContainerName {
+			b.proxy = &pod.Spec.Containers[i]
+		}
+	}
+	if b.proxy == nil {
+		return "", fmt.Errorf("pod %s/%s does not have a %s container", namespace, podName, k8s.ProxyContainerName)
+	}
+
+	controlURL := proxyEnv(b.proxy, proxyControlURLEnvVar)
+	if controlURL == "" {
+		return "", fmt.Errorf("%s is not set", proxyControlURLEnvVar)
+	}
+	b.controlURL, err = url.Parse(controlURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %s", proxyControlURLEnvVar, err)
+	}
+
+	b.podIdentity = proxyEnv(b.proxy, proxyPodIdentityEnvVar)
+	b.controlNS = pod.Labels[k8s.ControllerNSLabel]
+
+	return fmt.Sprintf("control plane is %s", b.controlURL.Host), nil
+}
+
+// resolveControlPlane resolves the control plane address the way cluster DNS
+// would, by looking up the ready endpoints of the service it names.
+func (b *proxyBootstrap) resolveControlPlane() (string, error) {
+	host := b.controlURL.Hostname()
+	if host == "localhost" || host == "127.0.0.1" {
+		return "control plane is local to the pod", nil
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) < 4 || parts[2] != "svc" {
+		return "", fmt.Errorf("%s is not a Kubernetes service address", host)
+	}
+	service, namespace := parts[0], parts[1]
+	b.dnsZone = strings.Join(parts[3:], ".")
+
+	endpoints, err := b.clientset.CoreV1().Endpoints(namespace).Get(service, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	ips := []string{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ips = append(ips, address.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("%s has no ready endpoints", host)
+	}
+
+	return fmt.Sprintf("%s resolves to %s", host, strings.Join(ips, ", ")), nil
+}
+
+// loadIdentity checks the TLS identity that is mounted into the proxy, in
+// the same way the proxy validates it before it starts serving.
+func (b *proxyBootstrap) loadIdentity() (string, error) {
+	if b.podIdentity == "" {
+		return "TLS is disabled", nil
+	}
+
+	serviceAccount := b.pod.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	_, err := b.clientset.CoreV1().ServiceAccounts(b.pod.Namespace).Get(serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("service account of the pod is not available: %s", err)
+	}
+
+	secretName := ""
+	for _, volume := range b.pod.Spec.Volumes {
+		if volume.Name == k8s.TLSSecretsVolumeName && volume.Secret != nil {
+			secretName = volume.Secret.SecretName
+		}
+	}
+	if secretName == "" {
+		return "", fmt.Errorf("pod does not mount the %s volume", k8s.TLSSecretsVolumeName)
+	}
+
+	secret, err := b.clientset.CoreV1().Secrets(b.pod.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	controlNS := b.controlNS
+	if controlNS == "" {
+		controlNS = controlPlaneNamespace
+	}
+	trustAnchors, err := b.clientset.CoreV1().ConfigMaps(controlNS).Get(k8s.TLSTrustAnchorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	expiry, err := verifyIdentity(
+		secret.Data[k8s.TLSCertFileName],
+		[]byte(trustAnchors.Data[k8s.TLSTrustAnchorFileName]),
+		b.podIdentity,
+		time.Now(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s is valid until %s", b.podIdentity, expiry.UTC().Format(time.RFC3339)), nil
+}
+
+// openDestinationStreams asks the destination service to resolve each of the
+// services selecting the pod, like the proxy does for its outbound traffic.
+// Pods that no service selects resolve the control plane address instead.
+func (b *proxyBootstrap) openDestinationStreams() (string, error) {
+	authorities, err := b.podAuthorities()
+	if err != nil {
+		return "", err
+	}
+	if len(authorities) == 0 {
+		authorities = []string{b.controlURL.Host}
+	}
+
+	results := []string{}
+	for _, authority := range authorities {
+		rsp, err := b.apiClient.ResolveDestination(context.Background(), &pb.ResolveDestinationRequest{Authority: authority})
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %s", authority, err)
+		}
+		if !rsp.GetExists() {
+			return "", fmt.Errorf("destination service does not know about %s", authority)
+		}
+		results = append(results, fmt.Sprintf("%s has %d endpoints", authority, len(rsp.GetAddresses())))
+	}
+
+	return strings.Join(results, ", "), nil
+}
+
+func (b *proxyBootstrap) podAuthorities() ([]string, error) {
+	services, err := b.clientset.CoreV1().Services(b.pod.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dnsZone := b.dnsZone
+	if dnsZone == "" {
+		dnsZone = "cluster.local"
+	}
+
+	authorities := []string{}
+	for _, svc := range services.Items {
+		if len(svc.Spec.Selector) == 0 || len(svc.Spec.Ports) == 0 {
+			continue
+		}
+		if !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(b.pod.Labels)) {
+			continue
+		}
+		authorities = append(authorities, fmt.Sprintf("%s.%s.svc.%s:%d", svc.Name, svc.Namespace, dnsZone, svc.Spec.Ports[0].Port))
+	}
+	return authorities, nil
+}
+
+// verifyIdentity checks that the DER-encoded certificate was issued by one of
+// the PEM-encoded trust anchors for the given identity, and returns its
+// expiry.
+func verifyIdentity(certDER, trustAnchorsPEM []byte, identity string, now time.Time) (time.Time, error) {
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse certificate: %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(trustAnchorsPEM) {
+		return time.Time{}, fmt.Errorf("no trust anchors found in %s", k8s.TLSTrustAnchorConfigMapName)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     identity,
+		Roots:       roots,
+		CurrentTime: now,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return cert.NotAfter, nil
+}
+
+func proxyEnv(container *v1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
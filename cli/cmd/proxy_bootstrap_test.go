@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/linkerd/linkerd2/controller/ca"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProxyBootstrap(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-1",
+			Namespace: "emojivoto",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "web"},
+				{
+					Name: "linkerd-proxy",
+					Env: []v1.EnvVar{
+						{Name: "LINKERD2_PROXY_CONTROL_URL", Value: "tcp://proxy-api.linkerd.svc.cluster.local:8086"},
+					},
+				},
+			},
+		},
+	}
+
+	webService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-svc", Namespace: "emojivoto"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports:    []v1.ServicePort{{Port: 80}},
+		},
+	}
+
+	proxyAPIEndpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy-api", Namespace: "linkerd"},
+		Subsets: []v1.EndpointSubset{
+			{Addresses: []v1.EndpointAddress{{IP: "10.1.0.5"}}},
+		},
+	}
+
+	t.Run("Prints the result of every step", func(t *testing.T) {
+		bootstrap := &proxyBootstrap{
+			clientset: fake.NewSimpleClientset(pod, webService, proxyAPIEndpoints),
+			apiClient: &public.MockApiClient{
+				ResolveDestinationToReturn: &pb.ResolveDestinationResponse{
+					Exists:    true,
+					Addresses: []string{"10.1.0.7:80", "10.1.0.8:80"},
+				},
+			},
+		}
+
+		output := bytes.NewBufferString("")
+		if !bootstrap.run(output, "emojivoto", "web-1") {
+			t.Fatalf("Expected bootstrap to succeed, got:\n%s", output)
+		}
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/proxy_bootstrap_output.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		diffCompare(t, output.String(), string(goldenFileBytes))
+	})
+
+	t.Run("Stops at the first step that fails", func(t *testing.T) {
+		bootstrap := &proxyBootstrap{
+			clientset: fake.NewSimpleClientset(pod, webService),
+			apiClient: &public.MockApiClient{},
+		}
+
+		output := bytes.NewBufferString("")
+		if bootstrap.run(output, "emojivoto", "web-1") {
+			t.Fatalf("Expected bootstrap to fail, got:\n%s", output)
+		}
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/proxy_bootstrap_output_fail.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		diffCompare(t, output.String(), string(goldenFileBytes))
+	})
+}
+
+func TestVerifyIdentity(t *testing.T) {
+	identity := "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"
+
+	issuer, err := ca.NewCA()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	crt, err := issuer.IssueEndEntityCertificate(identity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	otherIssuer, err := ca.NewCA()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Accepts a certificate issued for the identity", func(t *testing.T) {
+		_, err := verifyIdentity(crt.Certificate, []byte(issuer.TrustAnchorPEM()), identity, time.Now())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejects a certificate issued for another identity", func(t *testing.T) {
+		_, err := verifyIdentity(crt.Certificate, []byte(issuer.TrustAnchorPEM()), "other."+identity, time.Now())
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects a certificate issued by another CA", func(t *testing.T) {
+		_, err := verifyIdentity(crt.Certificate, []byte(otherIssuer.TrustAnchorPEM()), identity, time.Now())
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
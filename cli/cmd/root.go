@@ -2,55 +2,74 @@ package cmd
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/linkerd/linkerd2/cli/output"
+	"github.com/linkerd/linkerd2/controller/api/public"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/linkerd/linkerd2/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
 	defaultNamespace = "linkerd"
-
-	lineWidth  = 80
-	okStatus   = "[ok]"
-	warnStatus = "[warn]"
 )
 
 var controlPlaneNamespace string
 var apiAddr string // An empty value means "use the Kubernetes configuration"
 var kubeconfigPath string
+var kubeContext string
+var impersonate string
+var impersonateGroup []string
 var verbose bool
+var logLevel string
+var noColor bool
 
 var (
 	// These regexs are not as strict as they could be, but are a quick and dirty
 	// sanity check against illegal characters.
-	alphaNumDash         = regexp.MustCompile("^[a-zA-Z0-9-]+$")
-	alphaNumDashDot      = regexp.MustCompile("^[\\.a-zA-Z0-9-]+$")
-	alphaNumDashDotSlash = regexp.MustCompile("^[\\./a-zA-Z0-9-]+$")
+	alphaNumDash              = regexp.MustCompile("^[a-zA-Z0-9-]+$")
+	alphaNumDashDot           = regexp.MustCompile("^[\\.a-zA-Z0-9-]+$")
+	alphaNumDashDotSlashColon = regexp.MustCompile("^[\\./:a-zA-Z0-9-]+$")
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "linkerd",
 	Short: "linkerd manages the Linkerd service mesh",
 	Long:  `linkerd manages the Linkerd service mesh.`,
+	// the names of namespaces and resources are completed from the cluster
+	BashCompletionFunction: bashCompletionFunc,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// enable / disable logging
+		// logging is disabled by default, and --verbose is a shorthand for
+		// --log-level=debug
+		level, err := log.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("--log-level must be one of: panic, fatal, error, warn, info, debug")
+		}
 		if verbose {
-			log.SetLevel(log.DebugLevel)
-		} else {
-			log.SetLevel(log.PanicLevel)
+			level = log.DebugLevel
 		}
+		log.SetLevel(level)
+
+		output.Configure(os.Stdout, noColor)
 
 		if !alphaNumDash.MatchString(controlPlaneNamespace) {
 			return fmt.Errorf("%s is not a valid namespace", controlPlaneNamespace)
 		}
 
+		if len(impersonateGroup) > 0 && impersonate == "" {
+			return fmt.Errorf("--as-group requires --as")
+		}
+
 		return nil
 	},
 }
@@ -58,18 +77,36 @@ var RootCmd = &cobra.Command{
 func init() {
 	RootCmd.PersistentFlags().StringVarP(&controlPlaneNamespace, "linkerd-namespace", "l", defaultNamespace, "Namespace in which Linkerd is installed")
 	RootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file to use for CLI requests")
+	RootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	RootCmd.PersistentFlags().StringVar(&impersonate, "as", "", "Username to impersonate for Kubernetes operations")
+	RootCmd.PersistentFlags().StringArrayVar(&impersonateGroup, "as-group", []string{}, "Group to impersonate for Kubernetes operations; requires --as")
 	RootCmd.PersistentFlags().StringVar(&apiAddr, "api-addr", "", "Override kubeconfig and communicate directly with the control plane at host:port (mostly for testing)")
-	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Turn on debug logging")
+	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Turn on debug logging; same as --log-level=debug")
+	RootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, fmt.Sprintf("Disable the colors and status symbols of the output, which are otherwise enabled on a terminal unless the %s environment variable is set", output.NoColorEnv))
+	RootCmd.PersistentFlags().StringVar(&logLevel, "log-level", log.PanicLevel.String(), "Level of the logs written to stderr, such as the requests to the Kubernetes and Linkerd APIs and their retries at the debug level: panic, fatal, error, warn, info, debug")
 
+	RootCmd.AddCommand(newCmdAlpha())
 	RootCmd.AddCommand(newCmdCheck())
 	RootCmd.AddCommand(newCmdCompletion())
 	RootCmd.AddCommand(newCmdDashboard())
+	RootCmd.AddCommand(newCmdDiagnostics())
+	RootCmd.AddCommand(newCmdEndpoints())
 	RootCmd.AddCommand(newCmdGet())
+	RootCmd.AddCommand(newCmdIdentity())
 	RootCmd.AddCommand(newCmdInject())
 	RootCmd.AddCommand(newCmdInstall())
+	RootCmd.AddCommand(newCmdInstallCNIPlugin())
+	RootCmd.AddCommand(newCmdInstallGateway())
+	RootCmd.AddCommand(newCmdInstallServiceMirror())
+	RootCmd.AddCommand(newCmdLogs())
+	RootCmd.AddCommand(newCmdProfile())
+	RootCmd.AddCommand(newCmdRoutes())
 	RootCmd.AddCommand(newCmdStat())
 	RootCmd.AddCommand(newCmdTap())
+	RootCmd.AddCommand(newCmdTapAnalyze())
 	RootCmd.AddCommand(newCmdTop())
+	RootCmd.AddCommand(newCmdUninstall())
+	RootCmd.AddCommand(newCmdUpgrade())
 	RootCmd.AddCommand(newCmdVersion())
 }
 
@@ -86,6 +123,9 @@ func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
 	hc := healthcheck.NewHealthChecker(checks, &healthcheck.HealthCheckOptions{
 		ControlPlaneNamespace: controlPlaneNamespace,
 		KubeConfig:            kubeconfigPath,
+		KubeContext:           kubeContext,
+		Impersonate:           impersonate,
+		ImpersonateGroup:      impersonateGroup,
 		APIAddr:               apiAddr,
 		ShouldRetry:           shouldRetry,
 	})
@@ -120,8 +160,27 @@ func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
 	return hc.PublicAPIClient()
 }
 
+// validatedTapClient builds a public API client like validatedPublicAPIClient,
+// whose tap requests are made through the tap API if it is registered with
+// the Kubernetes API server, so that they are authorized against the caller's
+// RBAC permissions.
+func validatedTapClient() (pb.ApiClient, error) {
+	client := validatedPublicAPIClient(false)
+	if apiAddr != "" {
+		return client, nil
+	}
+
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return public.NewTapAPIClient(client, kubeAPI)
+}
+
 type proxyConfigOptions struct {
 	linkerdVersion        string
+	proxyVersion          string
 	proxyImage            string
 	initImage             string
 	dockerRegistry        string
@@ -133,17 +192,37 @@ type proxyConfigOptions struct {
 	proxyControlPort      uint
 	proxyMetricsPort      uint
 	proxyOutboundCapacity map[string]uint
+	proxyCPURequest       string
+	proxyMemoryRequest    string
+	proxyCPULimit         string
+	proxyMemoryLimit      string
 	tls                   string
+	trustDomain           string
+	clusterDomain         string
+	noInitContainer       bool
+	restrictedPodSecurity bool
+	traceCollector        string
+	tracePropagation      string
+	proxyArchitectures    []string
+	imageLockFile         string
+	imageDigests          map[string]string
 }
 
 const (
 	optionalTLS           = "optional"
+	identityTLS           = "identity"
 	defaultDockerRegistry = "gcr.io/linkerd-io"
+
+	// the formats of the trace context that the proxies read from, and add
+	// to, the headers of the requests they proxy
+	b3TracePropagation  = k8s.TracePropagationB3
+	w3cTracePropagation = k8s.TracePropagationW3C
 )
 
 func newProxyConfigOptions() *proxyConfigOptions {
 	return &proxyConfigOptions{
 		linkerdVersion:        version.Version,
+		proxyVersion:          "",
 		proxyImage:            defaultDockerRegistry + "/proxy",
 		initImage:             defaultDockerRegistry + "/proxy-init",
 		dockerRegistry:        defaultDockerRegistry,
@@ -155,7 +234,20 @@ func newProxyConfigOptions() *proxyConfigOptions {
 		proxyControlPort:      4190,
 		proxyMetricsPort:      4191,
 		proxyOutboundCapacity: map[string]uint{},
+		proxyCPURequest:       "",
+		proxyMemoryRequest:    "",
+		proxyCPULimit:         "",
+		proxyMemoryLimit:      "",
 		tls: "",
+		trustDomain:           k8s.DefaultTrustDomain,
+		clusterDomain:         k8s.DefaultClusterDomain,
+		noInitContainer:       false,
+		restrictedPodSecurity: false,
+		traceCollector:        "",
+		tracePropagation:      b3TracePropagation,
+		proxyArchitectures:    []string{"amd64", "arm64"},
+		imageLockFile:         "",
+		imageDigests:          map[string]string{},
 	}
 }
 
@@ -163,7 +255,15 @@ func (options *proxyConfigOptions) validate() error {
 	if !alphaNumDashDot.MatchString(options.linkerdVersion) {
 		return fmt.Errorf("%s is not a valid version", options.linkerdVersion)
 	}
-	if !alphaNumDashDotSlash.MatchString(options.dockerRegistry) {
+	if options.proxyVersion != "" && !alphaNumDashDot.MatchString(options.proxyVersion) {
+		return fmt.Errorf("%s is not a valid version for the --proxy-version flag", options.proxyVersion)
+	}
+	// the proxy released along with the control plane doesn't implement the
+	// identity service's API, so a proxy that does must be chosen explicitly
+	if options.enableIdentity() && options.proxyVersion == "" {
+		return fmt.Errorf("--tls=%s requires --proxy-version, the tag of a proxy image that is certified by the identity service; the %s proxy isn't", identityTLS, options.linkerdVersion)
+	}
+	if !alphaNumDashDotSlashColon.MatchString(options.dockerRegistry) {
 		return fmt.Errorf("%s is not a valid Docker registry", options.dockerRegistry)
 	}
 	if options.imagePullPolicy != "Always" && options.imagePullPolicy != "IfNotPresent" && options.imagePullPolicy != "Never" {
@@ -172,31 +272,95 @@ func (options *proxyConfigOptions) validate() error {
 	if _, err := time.ParseDuration(options.proxyBindTimeout); err != nil {
 		return fmt.Errorf("Invalid duration '%s' for --proxy-bind-timeout flag", options.proxyBindTimeout)
 	}
-	if options.tls != "" && options.tls != optionalTLS {
-		return fmt.Errorf("--tls must be blank or set to \"%s\"", optionalTLS)
+	if options.tls != "" && options.tls != optionalTLS && options.tls != identityTLS {
+		return fmt.Errorf("--tls must be blank or set to \"%s\" or \"%s\"", optionalTLS, identityTLS)
+	}
+	if !alphaNumDashDot.MatchString(options.trustDomain) {
+		return fmt.Errorf("%s is not a valid trust domain", options.trustDomain)
+	}
+	if !alphaNumDashDot.MatchString(options.clusterDomain) {
+		return fmt.Errorf("%s is not a valid cluster domain", options.clusterDomain)
+	}
+	if options.restrictedPodSecurity && !options.noInitContainer {
+		return fmt.Errorf("--restricted-pod-security requires --linkerd-cni-enabled, as the proxy-init container needs the NET_ADMIN capability")
+	}
+	if options.traceCollector != "" {
+		if err := validateTraceCollector(options.traceCollector); err != nil {
+			return err
+		}
+	}
+	if options.tracePropagation != b3TracePropagation && options.tracePropagation != w3cTracePropagation {
+		return fmt.Errorf("--trace-propagation must be set to \"%s\" or \"%s\"", b3TracePropagation, w3cTracePropagation)
+	}
+	if len(options.proxyArchitectures) == 0 {
+		return fmt.Errorf("--proxy-architectures must list at least one architecture")
+	}
+	for _, arch := range options.proxyArchitectures {
+		if !alphaNumDash.MatchString(arch) {
+			return fmt.Errorf("%s is not a valid architecture for the --proxy-architectures flag", arch)
+		}
+	}
+	for _, q := range []struct{ flag, quantity string }{
+		{"--proxy-cpu-request", options.proxyCPURequest},
+		{"--proxy-memory-request", options.proxyMemoryRequest},
+		{"--proxy-cpu-limit", options.proxyCPULimit},
+		{"--proxy-memory-limit", options.proxyMemoryLimit},
+	} {
+		if q.quantity == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(q.quantity); err != nil {
+			return fmt.Errorf("Invalid quantity '%s' for %s flag", q.quantity, q.flag)
+		}
+	}
+	return nil
+}
+
+// validateTraceCollector checks that the address of the trace collector is a
+// host and port, such as the address of the collector's service.
+func validateTraceCollector(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || !alphaNumDashDot.MatchString(host) {
+		return fmt.Errorf("Invalid address '%s' for --trace-collector flag: must be a host and port, e.g. oc-collector.tracing:55678", addr)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("Invalid port '%s' for --trace-collector flag", port)
 	}
 	return nil
 }
 
 func (options *proxyConfigOptions) enableTLS() bool {
-	return options.tls == optionalTLS
+	return options.tls == optionalTLS || options.tls == identityTLS
+}
+
+// enableIdentity returns true if the proxies are certified by the identity
+// service for the identities of their ServiceAccounts, instead of mounting the
+// certificates issued by the CA for their pods' owners.
+func (options *proxyConfigOptions) enableIdentity() bool {
+	return options.tls == identityTLS
 }
 
 func (options *proxyConfigOptions) taggedProxyImage() string {
 	image := strings.Replace(options.proxyImage, defaultDockerRegistry, options.dockerRegistry, 1)
-	return fmt.Sprintf("%s:%s", image, options.linkerdVersion)
+	tag := options.linkerdVersion
+	if options.proxyVersion != "" {
+		tag = options.proxyVersion
+	}
+	return options.pinnedImage(fmt.Sprintf("%s:%s", image, tag))
 }
 
 func (options *proxyConfigOptions) taggedProxyInitImage() string {
 	image := strings.Replace(options.initImage, defaultDockerRegistry, options.dockerRegistry, 1)
-	return fmt.Sprintf("%s:%s", image, options.linkerdVersion)
+	return options.pinnedImage(fmt.Sprintf("%s:%s", image, options.linkerdVersion))
 }
 
 func addProxyConfigFlags(cmd *cobra.Command, options *proxyConfigOptions) {
 	cmd.PersistentFlags().StringVarP(&options.linkerdVersion, "linkerd-version", "v", options.linkerdVersion, "Tag to be used for Linkerd images")
 	cmd.PersistentFlags().StringVar(&options.initImage, "init-image", options.initImage, "Linkerd init container image name")
+	cmd.PersistentFlags().StringVar(&options.proxyVersion, "proxy-version", options.proxyVersion, "Tag to be used for the Linkerd proxy image, instead of --linkerd-version; required with --tls=identity")
 	cmd.PersistentFlags().StringVar(&options.proxyImage, "proxy-image", options.proxyImage, "Linkerd proxy container image name")
 	cmd.PersistentFlags().StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "Docker registry to pull images from")
+	cmd.PersistentFlags().StringVar(&options.imageLockFile, "image-lock-file", options.imageLockFile, "Path to a YAML file that pins the images by their digests, under an images key that maps each image, as rendered with the registry and version, to its sha256 digest; all the images must be pinned")
 	cmd.PersistentFlags().StringVar(&options.imagePullPolicy, "image-pull-policy", options.imagePullPolicy, "Docker image pull policy")
 	cmd.PersistentFlags().Int64Var(&options.proxyUID, "proxy-uid", options.proxyUID, "Run the proxy under this user ID")
 	cmd.PersistentFlags().StringVar(&options.proxyLogLevel, "proxy-log-level", options.proxyLogLevel, "Log level for the proxy")
@@ -204,5 +368,16 @@ func addProxyConfigFlags(cmd *cobra.Command, options *proxyConfigOptions) {
 	cmd.PersistentFlags().UintVar(&options.proxyAPIPort, "api-port", options.proxyAPIPort, "Port where the Linkerd controller is running")
 	cmd.PersistentFlags().UintVar(&options.proxyControlPort, "control-port", options.proxyControlPort, "Proxy port to use for control")
 	cmd.PersistentFlags().UintVar(&options.proxyMetricsPort, "metrics-port", options.proxyMetricsPort, "Proxy port to serve metrics on")
-	cmd.PersistentFlags().StringVar(&options.tls, "tls", options.tls, "Enable TLS; valid settings: \"optional\"")
+	cmd.PersistentFlags().StringVar(&options.proxyCPURequest, "proxy-cpu-request", options.proxyCPURequest, "Amount of CPU units that the proxy sidecar requests")
+	cmd.PersistentFlags().StringVar(&options.proxyMemoryRequest, "proxy-memory-request", options.proxyMemoryRequest, "Amount of memory that the proxy sidecar requests")
+	cmd.PersistentFlags().StringVar(&options.proxyCPULimit, "proxy-cpu-limit", options.proxyCPULimit, "Maximum amount of CPU units that the proxy sidecar can use")
+	cmd.PersistentFlags().StringVar(&options.proxyMemoryLimit, "proxy-memory-limit", options.proxyMemoryLimit, "Maximum amount of memory that the proxy sidecar can use")
+	cmd.PersistentFlags().StringVar(&options.tls, "tls", options.tls, "Enable TLS; valid settings: \"optional\", or \"identity\" to certify proxies for the identities of their ServiceAccounts with the identity service (requires --proxy-version)")
+	cmd.PersistentFlags().BoolVar(&options.noInitContainer, "linkerd-cni-enabled", options.noInitContainer, "Omit the proxy-init container when the iptables rules of pods are configured by the linkerd CNI plugin (see `linkerd install-cni`)")
+	cmd.PersistentFlags().BoolVar(&options.restrictedPodSecurity, "restricted-pod-security", options.restrictedPodSecurity, "Run the proxy as a non-root user with a read-only root filesystem, no privilege escalation, no capabilities and the runtime's default seccomp profile, as required by the restricted pod security level; requires --linkerd-cni-enabled")
+	cmd.PersistentFlags().StringVar(&options.trustDomain, "trust-domain", options.trustDomain, "Trust domain of the TLS identities of meshed pods; must match the trust domain the control plane was installed with")
+	cmd.PersistentFlags().StringVar(&options.clusterDomain, "cluster-domain", options.clusterDomain, "DNS domain of the cluster's services, through which the proxies reach the control plane")
+	cmd.PersistentFlags().StringVar(&options.traceCollector, "trace-collector", options.traceCollector, "Address of the collector that the proxies send the spans of the requests they proxy to, e.g. oc-collector.tracing:55678; tracing is disabled if empty")
+	cmd.PersistentFlags().StringSliceVar(&options.proxyArchitectures, "proxy-architectures", options.proxyArchitectures, "Architectures, such as amd64 and arm64, that the multi-arch proxy and init images are published for; pods scheduled on other architectures with a kubernetes.io/arch node selector aren't injected")
+	cmd.PersistentFlags().StringVar(&options.tracePropagation, "trace-propagation", options.tracePropagation, "Format of the trace context propagated in the headers of the traced requests; valid settings: \"b3\", \"w3c\"")
 }
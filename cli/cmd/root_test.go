@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestLogLevel(t *testing.T) {
+	defer func() {
+		logLevel = log.PanicLevel.String()
+		verbose = false
+		log.SetLevel(log.PanicLevel)
+	}()
+
+	testCases := []struct {
+		logLevel      string
+		verbose       bool
+		expectedLevel log.Level
+	}{
+		{log.PanicLevel.String(), false, log.PanicLevel},
+		{"info", false, log.InfoLevel},
+		{log.PanicLevel.String(), true, log.DebugLevel},
+		{"warn", true, log.DebugLevel},
+	}
+
+	for _, tc := range testCases {
+		logLevel = tc.logLevel
+		verbose = tc.verbose
+		if err := RootCmd.PersistentPreRunE(RootCmd, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if log.GetLevel() != tc.expectedLevel {
+			t.Fatalf("Expected log level %s for --log-level=%s --verbose=%t, got %s", tc.expectedLevel, tc.logLevel, tc.verbose, log.GetLevel())
+		}
+	}
+
+	logLevel = "loud"
+	verbose = false
+	if err := RootCmd.PersistentPreRunE(RootCmd, nil); err == nil {
+		t.Fatal("Expected an error for an invalid log level, got none")
+	}
+}
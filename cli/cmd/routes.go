@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type routesOptions struct {
+	namespace  string
+	timeWindow string
+}
+
+func newRoutesOptions() *routesOptions {
+	return &routesOptions{
+		namespace:  "default",
+		timeWindow: "1m",
+	}
+}
+
+func newCmdRoutes() *cobra.Command {
+	options := newRoutesOptions()
+
+	cmd := &cobra.Command{
+		Use:   "routes [flags] (RESOURCE)",
+		Short: "Display route stats about a resource",
+		Long: `Display route stats about a resource.
+
+  The RESOURCE argument specifies the target resource to display the route stats of:
+  (TYPE [NAME] | TYPE/NAME)
+
+  The stats are broken down by the routes of the ServiceProfile of each
+  authority that the resource received requests for. Requests that didn't match
+  any route are displayed as the [DEFAULT] route.`,
+		Example: `  # Get the route stats of the webapp deployment in the test namespace.
+  linkerd routes deploy/webapp -n test
+
+  # Get the route stats of all the pods in the default namespace, over the last 10 minutes.
+  linkerd routes pods -t 10m`,
+		Args:       cobra.RangeArgs(1, 2),
+		ValidArgs:  util.ValidTargets,
+		ArgAliases: resourceTypeAliases,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := buildTopRoutesRequest(args, options)
+			if err != nil {
+				return fmt.Errorf("error creating metrics request while making routes request: %v", err)
+			}
+
+			output, err := requestRouteStatsFromAPI(validatedPublicAPIClient(false), req)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				fmt.Fprintln(os.Stderr, "No traffic found.")
+				os.Exit(0)
+			}
+
+			_, err = fmt.Print(output)
+
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the specified resource")
+	cmd.PersistentFlags().StringVarP(&options.timeWindow, "time-window", "t", options.timeWindow, "Stat window (for example: \"10s\", \"1m\", \"10m\", \"1h\")")
+
+	markNamespaceFlagCompletion(cmd, "namespace")
+
+	return cmd
+}
+
+func buildTopRoutesRequest(resource []string, options *routesOptions) (*pb.TopRoutesRequest, error) {
+	target, err := util.BuildResource(options.namespace, resource...)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.BuildTopRoutesRequest(util.TopRoutesRequestParams{
+		TimeWindow:   options.timeWindow,
+		Namespace:    options.namespace,
+		ResourceType: target.Type,
+		ResourceName: target.Name,
+	})
+}
+
+func requestRouteStatsFromAPI(client pb.ApiClient, req *pb.TopRoutesRequest) (string, error) {
+	resp, err := client.TopRoutes(context.Background(), req)
+	if err != nil {
+		return "", fmt.Errorf("TopRoutes API error: %v", err)
+	}
+	if e := resp.GetError(); e != nil {
+		return "", fmt.Errorf("TopRoutes API response error: %v", e.Error)
+	}
+
+	return renderRouteStats(resp.GetOk().GetRouteTable()), nil
+}
+
+func renderRouteStats(table *pb.RouteTable) string {
+	rows := table.GetRows()
+	if len(rows) == 0 {
+		return ""
+	}
+
+	routeHeader := "ROUTE"
+	authorityHeader := "AUTHORITY"
+	maxRouteLength := len(routeHeader)
+	maxAuthorityLength := len(authorityHeader)
+	for _, r := range rows {
+		if len(r.Route) > maxRouteLength {
+			maxRouteLength = len(r.Route)
+		}
+		if len(r.Authority) > maxAuthorityLength {
+			maxAuthorityLength = len(r.Authority)
+		}
+	}
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 0, padding, ' ', tabwriter.AlignRight)
+
+	headers := []string{
+		routeHeader + strings.Repeat(" ", maxRouteLength-len(routeHeader)),
+		authorityHeader + strings.Repeat(" ", maxAuthorityLength-len(authorityHeader)),
+		"SUCCESS",
+		"RPS",
+		"LATENCY_P50",
+		"LATENCY_P95",
+		"LATENCY_P99\t", // trailing \t is required to format last column
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, r := range rows {
+		route := r.Route + strings.Repeat(" ", maxRouteLength-len(r.Route))
+		authority := r.Authority + strings.Repeat(" ", maxAuthorityLength-len(r.Authority))
+
+		if r.Stats == nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t-\t\n", route, authority)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%.1frps\t%dms\t%dms\t%dms\t\n",
+			route,
+			authority,
+			getRouteSuccessRate(r)*100,
+			getRouteRequestRate(r),
+			r.Stats.LatencyMsP50,
+			r.Stats.LatencyMsP95,
+			r.Stats.LatencyMsP99,
+		)
+	}
+	w.Flush()
+
+	// strip left padding on the first column
+	out := string(buffer.Bytes()[padding:])
+	out = strings.Replace(out, "\n"+strings.Repeat(" ", padding), "\n", -1)
+
+	return out
+}
+
+func getRouteRequestRate(r *pb.RouteTable_Row) float64 {
+	windowLength, err := time.ParseDuration(r.TimeWindow)
+	if err != nil {
+		log.Error(err.Error())
+		return 0.0
+	}
+	return float64(r.Stats.SuccessCount+r.Stats.FailureCount) / windowLength.Seconds()
+}
+
+func getRouteSuccessRate(r *pb.RouteTable_Row) float64 {
+	success := r.Stats.SuccessCount
+	failure := r.Stats.FailureCount
+
+	if success+failure == 0 {
+		return 0.0
+	}
+	return float64(success) / float64(success+failure)
+}
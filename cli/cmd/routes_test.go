@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestRoutes(t *testing.T) {
+	t.Run("Returns the route stats of a resource", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+
+		authority := "books.default.svc.cluster.local:7000"
+		mockClient.TopRoutesResponseToReturn = &pb.TopRoutesResponse{
+			Response: &pb.TopRoutesResponse_Ok_{
+				Ok: &pb.TopRoutesResponse_Ok{
+					RouteTable: &pb.RouteTable{
+						Rows: []*pb.RouteTable_Row{
+							&pb.RouteTable_Row{
+								Route:      "GET /books",
+								Authority:  authority,
+								TimeWindow: "1m",
+								Stats: &pb.BasicStats{
+									SuccessCount: 120,
+									LatencyMsP50: 10,
+									LatencyMsP95: 20,
+									LatencyMsP99: 30,
+								},
+							},
+							&pb.RouteTable_Row{
+								Route:      "[DEFAULT]",
+								Authority:  authority,
+								TimeWindow: "1m",
+								Stats: &pb.BasicStats{
+									SuccessCount: 30,
+									FailureCount: 30,
+									LatencyMsP50: 123,
+									LatencyMsP95: 456,
+									LatencyMsP99: 789,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		expectedOutput := `ROUTE        AUTHORITY                              SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99
+GET /books   books.default.svc.cluster.local:7000   100.00%   2.0rps          10ms          20ms          30ms
+[DEFAULT]    books.default.svc.cluster.local:7000    50.00%   1.0rps         123ms         456ms         789ms
+`
+
+		req, err := buildTopRoutesRequest([]string{"deploy/books"}, newRoutesOptions())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestRouteStatsFromAPI(mockClient, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Returns an error for requests across all resource types", func(t *testing.T) {
+		_, err := buildTopRoutesRequest([]string{"all"}, newRoutesOptions())
+		if err == nil {
+			t.Fatal("Expected an error, got nothing")
+		}
+	})
+}
@@ -4,17 +4,20 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/linkerd/linkerd2/cli/output"
+	"github.com/linkerd/linkerd2/controller/api/public"
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
 )
 
 type statOptions struct {
@@ -25,8 +28,15 @@ type statOptions struct {
 	fromNamespace string
 	fromResource  string
 	allNamespaces bool
+	watch         bool
+	watchInterval time.Duration
+	outputFormat  string
 }
 
+// clearScreen moves the cursor to the top-left corner of the terminal and
+// clears it, so that watch mode can redraw the table in place.
+const clearScreen = "\033[H\033[2J"
+
 func newStatOptions() *statOptions {
 	return &statOptions{
 		namespace:     "default",
@@ -36,6 +46,9 @@ func newStatOptions() *statOptions {
 		fromNamespace: "",
 		fromResource:  "",
 		allNamespaces: false,
+		watch:         false,
+		watchInterval: 5 * time.Second,
+		outputFormat:  "",
 	}
 }
 
@@ -96,20 +109,38 @@ If no resource name is specified, displays stats about all resources of the spec
   linkerd stat namespaces --from ns/default
 
   # Get all inbound stats to the test namespace.
-  linkerd stat ns/test`,
-		Args:      cobra.RangeArgs(1, 2),
-		ValidArgs: util.ValidTargets,
+  linkerd stat ns/test
+
+  # Watch the web deployment, refreshing the stats every 2 seconds.
+  linkerd stat deploy/web --watch --interval 2s
+
+  # Break down the traffic of all deployments by TLS status, to find out which
+  # of their requests aren't sent over mTLS yet.
+  linkerd stat deploy -o wide`,
+		Args:       cobra.RangeArgs(1, 2),
+		ValidArgs:  util.ValidTargets,
+		ArgAliases: resourceTypeAliases,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			req, err := buildStatSummaryRequest(args, options)
 			if err != nil {
 				return fmt.Errorf("error creating metrics request while making stats request: %v", err)
 			}
 
-			output, err := requestStatsFromAPI(validatedPublicAPIClient(false), req, options)
+			client := validatedPublicAPIClient(false)
+			if options.watch {
+				return watchStats(os.Stdout, client, req, options)
+			}
+
+			output, err := requestStatsFromAPI(client, req, options)
 			if err != nil {
 				return err
 			}
 
+			if output == "" {
+				fmt.Fprintln(os.Stderr, "No traffic found.")
+				os.Exit(0)
+			}
+
 			_, err = fmt.Print(output)
 
 			return err
@@ -123,14 +154,53 @@ If no resource name is specified, displays stats about all resources of the spec
 	cmd.PersistentFlags().StringVar(&options.fromResource, "from", options.fromResource, "If present, restricts outbound stats from the specified resource name")
 	cmd.PersistentFlags().StringVar(&options.fromNamespace, "from-namespace", options.fromNamespace, "Sets the namespace used from lookup the \"--from\" resource; by default the current \"--namespace\" is used")
 	cmd.PersistentFlags().BoolVar(&options.allNamespaces, "all-namespaces", options.allNamespaces, "If present, returns stats across all namespaces, ignoring the \"--namespace\" flag")
+	cmd.PersistentFlags().BoolVarP(&options.watch, "watch", "w", options.watch, "After displaying the stats, keep refreshing them every \"--interval\"")
+	cmd.PersistentFlags().DurationVar(&options.watchInterval, "interval", options.watchInterval, "Refresh interval used with \"--watch\"")
+	cmd.PersistentFlags().StringVarP(&options.outputFormat, "output", "o", options.outputFormat, "Output format. One of: wide")
+
+	markNamespaceFlagCompletion(cmd, "namespace")
+	markNamespaceFlagCompletion(cmd, "to-namespace")
+	markNamespaceFlagCompletion(cmd, "from-namespace")
 
 	return cmd
 }
 
+// watchStats streams stats from the API, clearing the terminal and
+// re-rendering the table every time the API sends them, which it does every
+// options.watchInterval. It only returns if the stream fails.
+func watchStats(w io.Writer, client pb.ApiClient, req *pb.StatSummaryRequest, options *statOptions) error {
+	stream, err := client.StatSummaryStream(context.Background(), &pb.StatSummaryStreamRequest{
+		Request:  req,
+		Interval: options.watchInterval.String(),
+	})
+	if err != nil {
+		return statSummaryAPIError(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return statSummaryAPIError(err)
+		}
+		if e := resp.GetError(); e != nil {
+			return fmt.Errorf("StatSummary API response error: %v", e.Error)
+		}
+
+		output := renderStats(resp, req.Selector.Resource.Type, options)
+		if output == "" {
+			output = "No traffic found.\n"
+		}
+
+		fmt.Fprint(w, clearScreen)
+		fmt.Fprintf(w, "Every %s, last updated %s\n\n", options.watchInterval, time.Now().Format(time.Stamp))
+		fmt.Fprint(w, output)
+	}
+}
+
 func requestStatsFromAPI(client pb.ApiClient, req *pb.StatSummaryRequest, options *statOptions) (string, error) {
 	resp, err := client.StatSummary(context.Background(), req)
 	if err != nil {
-		return "", fmt.Errorf("StatSummary API error: %v", err)
+		return "", statSummaryAPIError(err)
 	}
 	if e := resp.GetError(); e != nil {
 		return "", fmt.Errorf("StatSummary API response error: %v", e.Error)
@@ -139,12 +209,23 @@ func requestStatsFromAPI(client pb.ApiClient, req *pb.StatSummaryRequest, option
 	return renderStats(resp, req.Selector.Resource.Type, options), nil
 }
 
+func statSummaryAPIError(err error) error {
+	if public.ErrorCode(err) == codes.DeadlineExceeded {
+		return fmt.Errorf("StatSummary API error: %v\nTry a shorter --time-window, or a more specific resource", err)
+	}
+	return fmt.Errorf("StatSummary API error: %v", err)
+}
+
 func renderStats(resp *pb.StatSummaryResponse, resourceType string, options *statOptions) string {
 	var buffer bytes.Buffer
 	w := tabwriter.NewWriter(&buffer, 0, 0, padding, ' ', tabwriter.AlignRight)
 	writeStatsToBuffer(resp, resourceType, w, options)
 	w.Flush()
 
+	if buffer.Len() == 0 {
+		return ""
+	}
+
 	// strip left padding on the first column
 	out := string(buffer.Bytes()[padding:])
 	out = strings.Replace(out, "\n"+strings.Repeat(" ", padding), "\n", -1)
@@ -155,12 +236,14 @@ func renderStats(resp *pb.StatSummaryResponse, resourceType string, options *sta
 const padding = 3
 
 type rowStats struct {
-	requestRate float64
-	successRate float64
-	tlsPercent  float64
-	latencyP50  uint64
-	latencyP95  uint64
-	latencyP99  uint64
+	requestRate        float64
+	successRate        float64
+	tlsPercent         float64
+	noIdentityPercent  float64
+	tlsDisabledPercent float64
+	latencyP50         uint64
+	latencyP95         uint64
+	latencyP99         uint64
 }
 
 type row struct {
@@ -214,20 +297,21 @@ func writeStatsToBuffer(resp *pb.StatSummaryResponse, reqResourceType string, w
 
 			if r.Stats != nil {
 				statTables[resourceKey][key].rowStats = &rowStats{
-					requestRate: getRequestRate(*r),
-					successRate: getSuccessRate(*r),
-					tlsPercent:  getPercentTls(*r),
-					latencyP50:  r.Stats.LatencyMsP50,
-					latencyP95:  r.Stats.LatencyMsP95,
-					latencyP99:  r.Stats.LatencyMsP99,
+					requestRate:        util.GetRequestRate(r),
+					successRate:        util.GetSuccessRate(r),
+					tlsPercent:         util.GetPercentOfRequests(r, r.Stats.TlsRequestCount),
+					noIdentityPercent:  util.GetPercentOfRequests(r, r.Stats.NoIdentityRequestCount),
+					tlsDisabledPercent: util.GetPercentOfRequests(r, r.Stats.TlsDisabledRequestCount),
+					latencyP50:         r.Stats.LatencyMsP50,
+					latencyP95:         r.Stats.LatencyMsP95,
+					latencyP99:         r.Stats.LatencyMsP99,
 				}
 			}
 		}
 	}
 
 	if len(statTables) == 0 {
-		fmt.Fprintln(os.Stderr, "No traffic found.")
-		os.Exit(0)
+		return
 	}
 
 	switch reqResourceType {
@@ -258,13 +342,17 @@ func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Wri
 	headers = append(headers, []string{
 		nameHeader + strings.Repeat(" ", maxNameLength-len(nameHeader)),
 		"MESHED",
-		"SUCCESS",
+		output.Colorize(output.Default, "SUCCESS"),
 		"RPS",
 		"LATENCY_P50",
 		"LATENCY_P95",
 		"LATENCY_P99",
-		"TLS\t", // trailing \t is required to format last column
 	}...)
+	if options.outputFormat == wideOutput {
+		headers = append(headers, "TLS", "NO_IDENTITY", "TLS_DISABLED\t")
+	} else {
+		headers = append(headers, "TLS\t") // trailing \t is required to format last column
+	}
 
 	fmt.Fprintln(w, strings.Join(headers, "\t"))
 
@@ -276,8 +364,12 @@ func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Wri
 		namespace := parts[0]
 		name := namePrefix + parts[1]
 		values := make([]interface{}, 0)
-		templateString := "%s\t%s\t%.2f%%\t%.1frps\t%dms\t%dms\t%dms\t%.f%%\t\n"
-		templateStringEmpty := "%s\t%s\t-\t-\t-\t-\t-\t-\t\n"
+		templateString := "%s\t%s\t%s\t%.1frps\t%dms\t%dms\t%dms\t%.f%%\t\n"
+		templateStringEmpty := "%s\t%s\t%s\t-\t-\t-\t-\t-\t\n"
+		if options.outputFormat == wideOutput {
+			templateString = "%s\t%s\t%s\t%.1frps\t%dms\t%dms\t%dms\t%.f%%\t%.f%%\t%.f%%\t\n"
+			templateStringEmpty = "%s\t%s\t%s\t-\t-\t-\t-\t-\t-\t-\t\n"
+		}
 
 		if options.allNamespaces {
 			values = append(values,
@@ -292,21 +384,38 @@ func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Wri
 
 		if stats[key].rowStats != nil {
 			values = append(values, []interface{}{
-				stats[key].successRate * 100,
+				renderSuccessRate(stats[key].successRate),
 				stats[key].requestRate,
 				stats[key].latencyP50,
 				stats[key].latencyP95,
 				stats[key].latencyP99,
 				stats[key].tlsPercent * 100,
 			}...)
+			if options.outputFormat == wideOutput {
+				values = append(values, stats[key].noIdentityPercent*100, stats[key].tlsDisabledPercent*100)
+			}
 
 			fmt.Fprintf(w, templateString, values...)
 		} else {
+			values = append(values, output.Colorize(output.Default, "-"))
 			fmt.Fprintf(w, templateStringEmpty, values...)
 		}
 	}
 }
 
+// renderSuccessRate renders a success rate as a percentage, colored with the
+// same thresholds as the dashboard: red below 90%, yellow below 95%, and green
+// otherwise.
+func renderSuccessRate(successRate float64) string {
+	color := output.Green
+	if successRate < 0.9 {
+		color = output.Red
+	} else if successRate < 0.95 {
+		color = output.Yellow
+	}
+	return output.Colorize(color, fmt.Sprintf("%.2f%%", successRate*100))
+}
+
 func getNamePrefix(resourceType string) string {
 	if resourceType == "" {
 		return ""
@@ -358,35 +467,6 @@ func buildStatSummaryRequest(resource []string, options *statOptions) (*pb.StatS
 	return util.BuildStatSummaryRequest(requestParams)
 }
 
-func getRequestRate(r pb.StatTable_PodGroup_Row) float64 {
-	success := r.Stats.SuccessCount
-	failure := r.Stats.FailureCount
-	windowLength, err := time.ParseDuration(r.TimeWindow)
-	if err != nil {
-		log.Error(err.Error())
-		return 0.0
-	}
-	return float64(success+failure) / windowLength.Seconds()
-}
-
-func getSuccessRate(r pb.StatTable_PodGroup_Row) float64 {
-	success := r.Stats.SuccessCount
-	failure := r.Stats.FailureCount
-
-	if success+failure == 0 {
-		return 0.0
-	}
-	return float64(success) / float64(success+failure)
-}
-
-func getPercentTls(r pb.StatTable_PodGroup_Row) float64 {
-	reqTotal := r.Stats.SuccessCount + r.Stats.FailureCount
-	if reqTotal == 0 {
-		return 0.0
-	}
-	return float64(r.Stats.TlsRequestCount) / float64(reqTotal)
-}
-
 func sortStatsKeys(stats map[string]*row) []string {
 	var sortedKeys []string
 	for key := range stats {
@@ -411,6 +491,14 @@ func (o *statOptions) validate(resourceType string) error {
 		}
 	}
 
+	if o.watch && o.watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	if o.outputFormat != "" && o.outputFormat != wideOutput {
+		return fmt.Errorf("output format \"%s\" not recognized", o.outputFormat)
+	}
+
 	return nil
 }
 
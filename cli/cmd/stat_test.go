@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestStat(t *testing.T) {
@@ -42,6 +49,104 @@ emoji      1/2   100.00%   2.0rps         123ms         123ms         123ms   10
 		}
 	})
 
+	t.Run("Breaks down the traffic by TLS status with -o wide", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+
+		counts := &public.PodCounts{
+			MeshedPods:  1,
+			RunningPods: 2,
+			FailedPods:  0,
+		}
+
+		response := public.GenStatSummaryResponse("emoji", k8s.Namespace, "emojivoto", counts)
+
+		mockClient.StatSummaryResponseToReturn = &response
+
+		expectedOutput := `NAME    MESHED   SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99    TLS   NO_IDENTITY   TLS_DISABLED
+emoji      1/2   100.00%   2.0rps         123ms         123ms         123ms   100%            0%             0%
+`
+
+		options := newStatOptions()
+		options.outputFormat = wideOutput
+		args := []string{"ns"}
+		req, err := buildStatSummaryRequest(args, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Suggests narrowing the query when it times out", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		mockClient.ErrorToReturn = status.Error(codes.DeadlineExceeded, "Prometheus query timed out")
+
+		options := newStatOptions()
+		req, err := buildStatSummaryRequest([]string{"ns"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = requestStatsFromAPI(mockClient, req, options)
+		if err == nil || !strings.Contains(err.Error(), "--time-window") {
+			t.Fatalf("Expected an error suggesting a shorter time window, got: %v", err)
+		}
+	})
+
+	t.Run("Redraws the stats every time they're streamed with --watch", func(t *testing.T) {
+		counts := &public.PodCounts{
+			MeshedPods:  1,
+			RunningPods: 2,
+			FailedPods:  0,
+		}
+		response := public.GenStatSummaryResponse("emoji", k8s.Namespace, "emojivoto", counts)
+
+		mockClient := &public.MockApiClient{
+			Api_StatSummaryStreamToReturn: &public.MockApi_StatSummaryStreamClient{
+				ResponsesToReturn: []*pb.StatSummaryResponse{&response, &response},
+			},
+		}
+
+		options := newStatOptions()
+		options.watch = true
+		req, err := buildStatSummaryRequest([]string{"ns"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		err = watchStats(&buf, mockClient, req, options)
+		if err == nil || !strings.Contains(err.Error(), io.EOF.Error()) {
+			t.Fatalf("Expected the stream to end with EOF, got: %v", err)
+		}
+
+		if n := strings.Count(buf.String(), clearScreen); n != 2 {
+			t.Fatalf("Expected the stats to be drawn 2 times, got %d times:\n%s", n, buf.String())
+		}
+		if !strings.Contains(buf.String(), "emoji      1/2   100.00%") {
+			t.Fatalf("Expected the stats in the output, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Rejects unknown output formats", func(t *testing.T) {
+		options := newStatOptions()
+		options.outputFormat = "yaml"
+		args := []string{"deploy"}
+		expectedError := "output format \"yaml\" not recognized"
+
+		_, err := buildStatSummaryRequest(args, options)
+		if err == nil || err.Error() != expectedError {
+			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
+		}
+	})
+
 	t.Run("Returns an error for named resource queries with the --all-namespaces flag", func(t *testing.T) {
 		options := newStatOptions()
 		options.allNamespaces = true
@@ -103,4 +208,17 @@ emoji      1/2   100.00%   2.0rps         123ms         123ms         123ms   10
 			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
 		}
 	})
+
+	t.Run("Rejects --watch with an --interval under a second", func(t *testing.T) {
+		options := newStatOptions()
+		options.watch = true
+		options.watchInterval = 500 * time.Millisecond
+		args := []string{"deploy"}
+		expectedError := "--interval must be at least 1s"
+
+		_, err := buildStatSummaryRequest(args, options)
+		if err == nil || err.Error() != expectedError {
+			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
+		}
+	})
 }
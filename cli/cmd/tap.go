@@ -2,41 +2,63 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/linkerd/linkerd2/cli/output"
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/addr"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	wideOutput = "wide"
+	jsonOutput = "json"
 )
 
 type tapOptions struct {
-	namespace   string
-	toResource  string
-	toNamespace string
-	maxRps      float32
-	scheme      string
-	method      string
-	authority   string
-	path        string
-	output      string
+	namespace     string
+	toResource    string
+	toNamespace   string
+	fromResource  string
+	fromNamespace string
+	maxRps        float32
+	duration      time.Duration
+	scheme        string
+	method        string
+	authority     string
+	path          string
+	status        string
+	output        string
+	outputFile    string
 }
 
 func newTapOptions() *tapOptions {
 	return &tapOptions{
-		namespace:   "default",
-		toResource:  "",
-		toNamespace: "",
-		maxRps:      100.0,
-		scheme:      "",
-		method:      "",
-		authority:   "",
-		path:        "",
-		output:      "",
+		namespace:     "default",
+		toResource:    "",
+		toNamespace:   "",
+		fromResource:  "",
+		fromNamespace: "",
+		maxRps:        100.0,
+		duration:      0,
+		scheme:        "",
+		method:        "",
+		authority:     "",
+		path:          "",
+		status:        "",
+		output:        "",
+		outputFile:    "",
 	}
 }
 
@@ -71,20 +93,43 @@ func newCmdTap() *cobra.Command {
   linkerd tap pod/web-dlbvj
 
   # tap the test namespace, filter by request to prod namespace
-  linkerd tap ns/test --to ns/prod`,
-		Args:      cobra.RangeArgs(1, 2),
-		ValidArgs: util.ValidTargets,
+  linkerd tap ns/test --to ns/prod
+
+  # tap the web deployment, only showing requests to the voting deployment
+  linkerd tap deploy/web --to deploy/voting
+
+  # tap the voting deployment, only showing requests from the web deployment
+  linkerd tap deploy/voting --from deploy/web
+
+  # tap the web deployment, only showing failed POST requests under /api
+  linkerd tap deploy/web --method POST --path /api --status 5xx
+
+  # tap the web deployment for 30 seconds, sampling at most 10 requests per second
+  linkerd tap deploy/web --max-rps 10 --duration 30s
+
+  # tap the web deployment, printing one JSON object per event
+  linkerd tap deploy/web -o json | jq .
+
+  # tap the web deployment for 5 minutes, saving the events for "linkerd tap-analyze"
+  linkerd tap deploy/web --duration 5m --output-file events.pb`,
+		Args:       cobra.RangeArgs(1, 2),
+		ValidArgs:  util.ValidTargets,
+		ArgAliases: resourceTypeAliases,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			requestParams := util.TapRequestParams{
-				Resource:    strings.Join(args, "/"),
-				Namespace:   options.namespace,
-				ToResource:  options.toResource,
-				ToNamespace: options.toNamespace,
-				MaxRps:      options.maxRps,
-				Scheme:      options.scheme,
-				Method:      options.method,
-				Authority:   options.authority,
-				Path:        options.path,
+				Resource:      strings.Join(args, "/"),
+				Namespace:     options.namespace,
+				ToResource:    options.toResource,
+				ToNamespace:   options.toNamespace,
+				FromResource:  options.fromResource,
+				FromNamespace: options.fromNamespace,
+				MaxRps:        options.maxRps,
+				Duration:      options.duration,
+				Scheme:        options.scheme,
+				Method:        options.method,
+				Authority:     options.authority,
+				Path:          options.path,
+				Status:        options.status,
 			}
 
 			req, err := util.BuildTapByResourceRequest(requestParams)
@@ -92,18 +137,28 @@ func newCmdTap() *cobra.Command {
 				return err
 			}
 
-			wide := false
 			switch options.output {
-			// TODO: support more output formats?
-			case "":
-				// default output format.
-			case "wide":
-				wide = true
+			case "", wideOutput, jsonOutput:
 			default:
 				return fmt.Errorf("output format \"%s\" not recognized", options.output)
 			}
 
-			return requestTapByResourceFromAPI(os.Stdout, validatedPublicAPIClient(false), req, wide)
+			client, err := validatedTapClient()
+			if err != nil {
+				return err
+			}
+
+			var record io.Writer
+			if options.outputFile != "" {
+				file, err := os.Create(options.outputFile)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				record = file
+			}
+
+			return requestTapByResourceFromAPI(os.Stdout, client, req, options.output, record)
 		},
 	}
 
@@ -113,8 +168,14 @@ func newCmdTap() *cobra.Command {
 		"Display requests to this resource")
 	cmd.PersistentFlags().StringVar(&options.toNamespace, "to-namespace", options.toNamespace,
 		"Sets the namespace used to lookup the \"--to\" resource; by default the current \"--namespace\" is used")
+	cmd.PersistentFlags().StringVar(&options.fromResource, "from", options.fromResource,
+		"Display requests from this resource")
+	cmd.PersistentFlags().StringVar(&options.fromNamespace, "from-namespace", options.fromNamespace,
+		"Sets the namespace used to lookup the \"--from\" resource; by default the current \"--namespace\" is used")
 	cmd.PersistentFlags().Float32Var(&options.maxRps, "max-rps", options.maxRps,
 		"Maximum requests per second to tap.")
+	cmd.PersistentFlags().DurationVar(&options.duration, "duration", options.duration,
+		"Stop tapping after this duration, like 30s or 5m (when set to 0, tap until interrupted)")
 	cmd.PersistentFlags().StringVar(&options.scheme, "scheme", options.scheme,
 		"Display requests with this scheme")
 	cmd.PersistentFlags().StringVar(&options.method, "method", options.method,
@@ -123,28 +184,44 @@ func newCmdTap() *cobra.Command {
 		"Display requests with this :authority")
 	cmd.PersistentFlags().StringVar(&options.path, "path", options.path,
 		"Display requests with paths that start with this prefix")
+	cmd.PersistentFlags().StringVar(&options.status, "status", options.status,
+		"Display requests with a response status in this range; a status code (\"404\"), a class (\"5xx\"), or a range (\"500-504\")")
 	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output,
-		"Output format. One of: wide")
+		"Output format. One of: wide, json")
+	cmd.PersistentFlags().StringVar(&options.outputFile, "output-file", options.outputFile,
+		"Also save the tapped events to this file, to be analyzed with \"linkerd tap-analyze\"")
+
+	markNamespaceFlagCompletion(cmd, "namespace")
+	markNamespaceFlagCompletion(cmd, "to-namespace")
+	markNamespaceFlagCompletion(cmd, "from-namespace")
 
 	return cmd
 }
 
-func requestTapByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, wide bool) error {
-	var resource string
-	if wide {
-		resource = req.Target.Resource.GetType()
+// requestTapByResourceFromAPI renders the events of a tap request to w. If
+// record is not nil, the events are also written to it as they are received,
+// in the format read by "linkerd tap-analyze".
+func requestTapByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, output string, record io.Writer) error {
+	var formatter tapEventFormatter
+	switch output {
+	case jsonOutput:
+		formatter = jsonTapEventFormatter
+	case wideOutput:
+		formatter = textTapEventFormatter(req.Target.Resource.GetType())
+	default:
+		formatter = textTapEventFormatter("")
 	}
 
 	rsp, err := client.TapByResource(context.Background(), req)
 	if err != nil {
 		return err
 	}
-	return renderTap(w, rsp, resource)
+	return renderTap(w, rsp, formatter, record)
 }
 
-func renderTap(w io.Writer, tapClient pb.Api_TapByResourceClient, resource string) error {
+func renderTap(w io.Writer, tapClient pb.Api_TapByResourceClient, formatter tapEventFormatter, record io.Writer) error {
 	tableWriter := tabwriter.NewWriter(w, 0, 0, 0, ' ', tabwriter.AlignRight)
-	err := writeTapEventsToBuffer(tapClient, tableWriter, resource)
+	err := writeTapEventsToBuffer(tapClient, tableWriter, formatter, record)
 	if err != nil {
 		return err
 	}
@@ -153,7 +230,7 @@ func renderTap(w io.Writer, tapClient pb.Api_TapByResourceClient, resource strin
 	return nil
 }
 
-func writeTapEventsToBuffer(tapClient pb.Api_TapByResourceClient, w *tabwriter.Writer, resource string) error {
+func writeTapEventsToBuffer(tapClient pb.Api_TapByResourceClient, w *tabwriter.Writer, formatter tapEventFormatter, record io.Writer) error {
 	for {
 		log.Debug("Waiting for data...")
 		event, err := tapClient.Recv()
@@ -164,7 +241,16 @@ func writeTapEventsToBuffer(tapClient pb.Api_TapByResourceClient, w *tabwriter.W
 			fmt.Fprintln(os.Stderr, err)
 			break
 		}
-		_, err = fmt.Fprintln(w, util.RenderTapEvent(event, resource))
+		if record != nil {
+			if err = writeRecordedTapEvent(record, event); err != nil {
+				return err
+			}
+		}
+		line, err := formatter(event)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, line)
 		if err != nil {
 			return err
 		}
@@ -172,3 +258,198 @@ func writeTapEventsToBuffer(tapClient pb.Api_TapByResourceClient, w *tabwriter.W
 
 	return nil
 }
+
+// tapEventFormatter renders a single tap event as one line of output.
+type tapEventFormatter func(event *pb.TapEvent) (string, error)
+
+// textTapEventFormatter renders events in the default, human readable format.
+// If resource is not empty, events are labeled with the source and
+// destination resources of that type. With colored output, failed responses
+// are red and successful ones green.
+func textTapEventFormatter(resource string) tapEventFormatter {
+	return func(event *pb.TapEvent) (string, error) {
+		line := util.RenderTapEvent(event, resource)
+
+		switch ev := event.GetHttp().GetEvent().(type) {
+		case *pb.TapEvent_Http_ResponseInit_:
+			if ev.ResponseInit.GetHttpStatus() >= 500 {
+				line = output.Colorize(output.Red, line)
+			}
+		case *pb.TapEvent_Http_ResponseEnd_:
+			if responseSucceeded(nil, ev.ResponseEnd) {
+				line = output.Colorize(output.Green, line)
+			} else {
+				line = output.Colorize(output.Red, line)
+			}
+		}
+
+		return line, nil
+	}
+}
+
+// tapEventJSON is the representation of a tap event written by "--output
+// json". Exactly one of RequestInit, ResponseInit and ResponseEnd is set,
+// matching Type. TLS describes the connection between the proxy that reported
+// the event and its peer, and ExpectedPeerIdentity the identity the peer is
+// expected to have, which isn't validated against the connection.
+type tapEventJSON struct {
+	Type                 string            `json:"type"`
+	ID                   string            `json:"id"`
+	ProxyDirection       string            `json:"proxyDirection"`
+	Source               *tapPeerJSON      `json:"source"`
+	Destination          *tapPeerJSON      `json:"destination"`
+	TLS                  string            `json:"tls,omitempty"`
+	ExpectedPeerIdentity string            `json:"expectedPeerIdentity,omitempty"`
+	RequestInit          *requestInitJSON  `json:"requestInit,omitempty"`
+	ResponseInit         *responseInitJSON `json:"responseInit,omitempty"`
+	ResponseEnd          *responseEndJSON  `json:"responseEnd,omitempty"`
+}
+
+// tapPeerJSON describes one end of a tapped request. Labels carries the
+// peer's identity as known to the proxy, such as its pod, namespace, owning
+// resources and whether the connection was secured with TLS.
+type tapPeerJSON struct {
+	Address string            `json:"address"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+type requestInitJSON struct {
+	Method    string `json:"method"`
+	Scheme    string `json:"scheme,omitempty"`
+	Authority string `json:"authority"`
+	Path      string `json:"path"`
+}
+
+type responseInitJSON struct {
+	HTTPStatus    uint32 `json:"httpStatus"`
+	LatencyMicros int64  `json:"latencyMicros"`
+}
+
+type responseEndJSON struct {
+	GRPCStatus     string  `json:"grpcStatus,omitempty"`
+	ResetErrorCode *uint32 `json:"resetErrorCode,omitempty"`
+	DurationMicros int64   `json:"durationMicros"`
+	ResponseBytes  uint64  `json:"responseBytes"`
+	Classification string  `json:"classification,omitempty"`
+}
+
+// jsonTapEventFormatter renders each event as a single line JSON object.
+func jsonTapEventFormatter(event *pb.TapEvent) (string, error) {
+	out := tapEventJSON{
+		ProxyDirection: event.GetProxyDirection().String(),
+		Source: &tapPeerJSON{
+			Address: addr.PublicAddressToString(event.GetSource()),
+			Labels:  event.GetSourceMeta().GetLabels(),
+		},
+		Destination: &tapPeerJSON{
+			Address: addr.PublicAddressToString(event.GetDestination()),
+			Labels:  event.GetDestinationMeta().GetLabels(),
+		},
+	}
+	out.TLS, out.ExpectedPeerIdentity = util.TapEventTLS(event)
+
+	streamID := func(id *pb.TapEvent_Http_StreamId) string {
+		return fmt.Sprintf("%d:%d", id.GetBase(), id.GetStream())
+	}
+
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *pb.TapEvent_Http_RequestInit_:
+		out.Type = "requestInit"
+		out.ID = streamID(ev.RequestInit.GetId())
+		out.RequestInit = &requestInitJSON{
+			Method:    formatMethod(ev.RequestInit.GetMethod()),
+			Scheme:    formatScheme(ev.RequestInit.GetScheme()),
+			Authority: ev.RequestInit.GetAuthority(),
+			Path:      ev.RequestInit.GetPath(),
+		}
+
+	case *pb.TapEvent_Http_ResponseInit_:
+		out.Type = "responseInit"
+		out.ID = streamID(ev.ResponseInit.GetId())
+		out.ResponseInit = &responseInitJSON{
+			HTTPStatus:    ev.ResponseInit.GetHttpStatus(),
+			LatencyMicros: toMicros(ev.ResponseInit.GetSinceRequestInit()),
+		}
+
+	case *pb.TapEvent_Http_ResponseEnd_:
+		out.Type = "responseEnd"
+		out.ID = streamID(ev.ResponseEnd.GetId())
+		out.ResponseEnd = &responseEndJSON{
+			DurationMicros: toMicros(ev.ResponseEnd.GetSinceResponseInit()),
+			ResponseBytes:  ev.ResponseEnd.GetResponseBytes(),
+		}
+
+		if c := ev.ResponseEnd.GetClassification(); c != pb.TapEvent_Http_ResponseEnd_UNCLASSIFIED {
+			out.ResponseEnd.Classification = strings.ToLower(c.String())
+		}
+
+		switch eos := ev.ResponseEnd.GetEos().GetEnd().(type) {
+		case *pb.Eos_GrpcStatusCode:
+			out.ResponseEnd.GRPCStatus = codes.Code(eos.GrpcStatusCode).String()
+		case *pb.Eos_ResetErrorCode:
+			resetErrorCode := eos.ResetErrorCode
+			out.ResponseEnd.ResetErrorCode = &resetErrorCode
+		}
+
+	default:
+		out.Type = "unknown"
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func formatMethod(method *pb.HttpMethod) string {
+	if method.GetUnregistered() != "" {
+		return method.GetUnregistered()
+	}
+	return method.GetRegistered().String()
+}
+
+func formatScheme(scheme *pb.Scheme) string {
+	if scheme == nil {
+		return ""
+	}
+	if scheme.GetUnregistered() != "" {
+		return scheme.GetUnregistered()
+	}
+	return strings.ToLower(scheme.GetRegistered().String())
+}
+
+func toMicros(d *duration.Duration) int64 {
+	if d == nil {
+		return 0
+	}
+	dur, err := ptypes.Duration(d)
+	if err != nil {
+		return 0
+	}
+	return dur.Nanoseconds() / 1000
+}
+
+// responseSucceeded reports whether a response counts as a success, as
+// classified by the tap server. Responses from tap servers that don't
+// classify them are failures if their status is a 5xx, their gRPC status is
+// not OK, or their stream was reset.
+func responseSucceeded(rspInit *pb.TapEvent_Http_ResponseInit, rspEnd *pb.TapEvent_Http_ResponseEnd) bool {
+	switch rspEnd.GetClassification() {
+	case pb.TapEvent_Http_ResponseEnd_SUCCESS:
+		return true
+	case pb.TapEvent_Http_ResponseEnd_FAILURE:
+		return false
+	}
+
+	if rspInit.GetHttpStatus() >= 500 {
+		return false
+	}
+	switch eos := rspEnd.GetEos().GetEnd().(type) {
+	case *pb.Eos_GrpcStatusCode:
+		return eos.GrpcStatusCode == 0
+	case *pb.Eos_ResetErrorCode:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+)
+
+// maxRecordedEventSize bounds the size of a single event read from a
+// recording, so that a corrupt file doesn't cause a huge allocation.
+const maxRecordedEventSize = 1 << 20
+
+// latencyBuckets are the upper bounds of the latency histogram rendered by
+// "linkerd tap-analyze"; slower requests are counted in a final bucket.
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// pathStats summarizes the completed requests to a single path.
+type pathStats struct {
+	path      string
+	requests  int
+	latencies []time.Duration
+	failures  map[string]int
+}
+
+type analyzedStreamKey struct {
+	base   uint32
+	stream uint64
+}
+
+type analyzedStream struct {
+	path    string
+	rspInit *pb.TapEvent_Http_ResponseInit
+}
+
+func newCmdTapAnalyze() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tap-analyze [flags] FILE",
+		Short: "Analyze a traffic stream saved by \"linkerd tap\"",
+		Long: `Analyze a traffic stream saved by "linkerd tap".
+
+  Reads the events saved with "linkerd tap --output-file" and summarizes the
+  completed requests by path: their success rate and latency percentiles, a
+  histogram of their latencies, and a breakdown of their failures.`,
+		Example: `  # save 5 minutes of requests to the web deployment, then analyze them
+  linkerd tap deploy/web --duration 5m --output-file events.pb
+  linkerd tap-analyze events.pb`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			stats, err := analyzeTapEvents(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %s", args[0], err)
+			}
+
+			renderTapAnalysis(os.Stdout, stats)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// writeRecordedTapEvent appends an event to a recording, prefixed with its
+// size as a varint.
+func writeRecordedTapEvent(w io.Writer, event *pb.TapEvent) error {
+	b, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	size := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(size, uint64(len(b)))
+	if _, err = w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// readRecordedTapEvents calls handle with each event of a recording written
+// by writeRecordedTapEvent.
+func readRecordedTapEvents(r io.Reader, handle func(*pb.TapEvent)) error {
+	reader := bufio.NewReader(r)
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if size > maxRecordedEventSize {
+			return fmt.Errorf("event of %d bytes exceeds the maximum of %d bytes", size, maxRecordedEventSize)
+		}
+
+		b := make([]byte, size)
+		if _, err = io.ReadFull(reader, b); err != nil {
+			return err
+		}
+
+		var event pb.TapEvent
+		if err = proto.Unmarshal(b, &event); err != nil {
+			return err
+		}
+		handle(&event)
+	}
+}
+
+// analyzeTapEvents reads a recording and returns the stats of each path that
+// completed requests, sorted by path.
+func analyzeTapEvents(r io.Reader) ([]*pathStats, error) {
+	streams := make(map[analyzedStreamKey]*analyzedStream)
+	byPath := make(map[string]*pathStats)
+	keyFor := func(id *pb.TapEvent_Http_StreamId) analyzedStreamKey {
+		return analyzedStreamKey{base: id.GetBase(), stream: id.GetStream()}
+	}
+
+	err := readRecordedTapEvents(r, func(event *pb.TapEvent) {
+		switch ev := event.GetHttp().GetEvent().(type) {
+		case *pb.TapEvent_Http_RequestInit_:
+			streams[keyFor(ev.RequestInit.GetId())] = &analyzedStream{path: ev.RequestInit.GetPath()}
+
+		case *pb.TapEvent_Http_ResponseInit_:
+			if stream, ok := streams[keyFor(ev.ResponseInit.GetId())]; ok {
+				stream.rspInit = ev.ResponseInit
+			}
+
+		case *pb.TapEvent_Http_ResponseEnd_:
+			id := keyFor(ev.ResponseEnd.GetId())
+			stream, ok := streams[id]
+			if !ok {
+				// the request started before the recording
+				return
+			}
+			delete(streams, id)
+
+			stats, ok := byPath[stream.path]
+			if !ok {
+				stats = &pathStats{path: stream.path, failures: make(map[string]int)}
+				byPath[stream.path] = stats
+			}
+
+			stats.requests++
+			latency, err := ptypes.Duration(ev.ResponseEnd.GetSinceRequestInit())
+			if err == nil {
+				stats.latencies = append(stats.latencies, latency)
+			}
+			if !responseSucceeded(stream.rspInit, ev.ResponseEnd) {
+				stats.failures[describeFailure(stream.rspInit, ev.ResponseEnd)]++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*pathStats, 0, len(byPath))
+	for _, s := range byPath {
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].path < stats[j].path })
+	return stats, nil
+}
+
+// describeFailure names the reason a response is counted as a failure.
+func describeFailure(rspInit *pb.TapEvent_Http_ResponseInit, rspEnd *pb.TapEvent_Http_ResponseEnd) string {
+	switch eos := rspEnd.GetEos().GetEnd().(type) {
+	case *pb.Eos_GrpcStatusCode:
+		if eos.GrpcStatusCode != 0 {
+			return fmt.Sprintf("grpc-status=%s", codes.Code(eos.GrpcStatusCode))
+		}
+	case *pb.Eos_ResetErrorCode:
+		return fmt.Sprintf("reset-error=%d", eos.ResetErrorCode)
+	}
+	return fmt.Sprintf(":status=%d", rspInit.GetHttpStatus())
+}
+
+func (s *pathStats) successRate() float64 {
+	failures := 0
+	for _, count := range s.failures {
+		failures += count
+	}
+	return 100.0 * float64(s.requests-failures) / float64(s.requests)
+}
+
+// percentile returns the nearest-rank percentile of the path's latencies.
+func (s *pathStats) percentile(p int) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	rank := (p*len(s.latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return s.latencies[rank-1]
+}
+
+// histogram returns the number of latencies in each of latencyBuckets, and
+// the number of latencies above the last bucket.
+func (s *pathStats) histogram() []int {
+	counts := make([]int, len(latencyBuckets)+1)
+	for _, latency := range s.latencies {
+		i := sort.Search(len(latencyBuckets), func(i int) bool { return latency <= latencyBuckets[i] })
+		counts[i]++
+	}
+	return counts
+}
+
+func renderTapAnalysis(w io.Writer, stats []*pathStats) {
+	if len(stats) == 0 {
+		fmt.Fprintln(w, "No completed requests found")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tREQUESTS\tSUCCESS\tLATENCY_P50\tLATENCY_P95\tLATENCY_P99")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f%%\t%s\t%s\t%s\n",
+			s.path,
+			s.requests,
+			s.successRate(),
+			formatDuration(s.percentile(50)),
+			formatDuration(s.percentile(95)),
+			formatDuration(s.percentile(99)),
+		)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nLATENCY HISTOGRAM")
+	tw = tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+	headers := []string{"PATH"}
+	for _, bucket := range latencyBuckets {
+		headers = append(headers, "<="+formatDuration(bucket))
+	}
+	headers = append(headers, ">"+formatDuration(latencyBuckets[len(latencyBuckets)-1]))
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, s := range stats {
+		row := []string{s.path}
+		for _, count := range s.histogram() {
+			row = append(row, strconv.Itoa(count))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nFAILURES")
+	rows := []string{}
+	for _, s := range stats {
+		reasons := make([]string, 0, len(s.failures))
+		for reason := range s.failures {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for _, reason := range reasons {
+			rows = append(rows, fmt.Sprintf("%s\t%s\t%d", s.path, reason, s.failures[reason]))
+		}
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No failed requests found")
+		return
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tFAILURE\tCOUNT")
+	for _, row := range rows {
+		fmt.Fprintln(tw, row)
+	}
+	tw.Flush()
+}
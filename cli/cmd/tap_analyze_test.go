@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"google.golang.org/grpc/codes"
+)
+
+func recordedStream(t *testing.T, w *bytes.Buffer, stream uint64, path string, status uint32, latency time.Duration, eos *pb.Eos) {
+	id := &pb.TapEvent_Http_StreamId{Base: 1, Stream: stream}
+	events := []*pb.TapEvent_Http{
+		{Event: &pb.TapEvent_Http_RequestInit_{RequestInit: &pb.TapEvent_Http_RequestInit{Id: id, Path: path}}},
+		{Event: &pb.TapEvent_Http_ResponseInit_{ResponseInit: &pb.TapEvent_Http_ResponseInit{Id: id, HttpStatus: status}}},
+		{Event: &pb.TapEvent_Http_ResponseEnd_{ResponseEnd: &pb.TapEvent_Http_ResponseEnd{
+			Id:               id,
+			SinceRequestInit: ptypes.DurationProto(latency),
+			Eos:              eos,
+		}}},
+	}
+
+	for _, event := range events {
+		err := writeRecordedTapEvent(w, &pb.TapEvent{Event: &pb.TapEvent_Http_{Http: event}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestTapAnalyze(t *testing.T) {
+	t.Run("Summarizes recorded requests by path", func(t *testing.T) {
+		recording := &bytes.Buffer{}
+		recordedStream(t, recording, 1, "/api", 200, 5*time.Millisecond, nil)
+		recordedStream(t, recording, 2, "/api", 503, 20*time.Millisecond, nil)
+		recordedStream(t, recording, 3, "/api", 200, 200*time.Millisecond, &pb.Eos{
+			End: &pb.Eos_GrpcStatusCode{GrpcStatusCode: uint32(codes.Unavailable)},
+		})
+		recordedStream(t, recording, 4, "/health", 200, time.Millisecond, nil)
+
+		// a request that started before the recording is ignored
+		err := writeRecordedTapEvent(recording, &pb.TapEvent{Event: &pb.TapEvent_Http_{Http: &pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseEnd_{ResponseEnd: &pb.TapEvent_Http_ResponseEnd{
+				Id: &pb.TapEvent_Http_StreamId{Base: 1, Stream: 99},
+			}},
+		}}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		stats, err := analyzeTapEvents(recording)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output := &bytes.Buffer{}
+		renderTapAnalysis(output, stats)
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/tap_analyze_output.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expectedContent := string(goldenFileBytes)
+		if expectedContent != output.String() {
+			t.Fatalf("Expected function to render:\n%s\nbut got:\n%s", expectedContent, output.String())
+		}
+	})
+
+	t.Run("Returns an error for truncated recordings", func(t *testing.T) {
+		recording := &bytes.Buffer{}
+		recordedStream(t, recording, 1, "/api", 200, time.Millisecond, nil)
+		recording.Truncate(recording.Len() - 1)
+
+		_, err := analyzeTapEvents(recording)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
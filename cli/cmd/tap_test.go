@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/golang/protobuf/ptypes/duration"
@@ -16,7 +17,7 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
-func busyTest(t *testing.T, wide bool) {
+func busyTest(t *testing.T, output string) {
 	resourceType := k8s.Pod
 	targetName := "pod-666"
 	params := util.TapRequestParams{
@@ -77,15 +78,18 @@ func busyTest(t *testing.T, wide bool) {
 	}
 
 	writer := bytes.NewBufferString("")
-	err = requestTapByResourceFromAPI(writer, mockApiClient, req, wide)
+	err = requestTapByResourceFromAPI(writer, mockApiClient, req, output, nil)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
 	var goldenFilePath string
-	if wide {
+	switch output {
+	case wideOutput:
 		goldenFilePath = "testdata/tap_busy_output_wide.golden"
-	} else {
+	case jsonOutput:
+		goldenFilePath = "testdata/tap_busy_output_json.golden"
+	default:
 		goldenFilePath = "testdata/tap_busy_output.golden"
 	}
 
@@ -94,19 +98,23 @@ func busyTest(t *testing.T, wide bool) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 	expectedContent := string(goldenFileBytes)
-	output := writer.String()
-	if expectedContent != output {
-		t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
+	actual := writer.String()
+	if expectedContent != actual {
+		t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, actual)
 	}
 }
 
 func TestRequestTapByResourceFromAPI(t *testing.T) {
 	t.Run("Should render busy response if everything went well", func(t *testing.T) {
-		busyTest(t, false)
+		busyTest(t, "")
 	})
 
 	t.Run("Should render wide busy response if everything went well", func(t *testing.T) {
-		busyTest(t, true)
+		busyTest(t, wideOutput)
+	})
+
+	t.Run("Should render JSON busy response if everything went well", func(t *testing.T) {
+		busyTest(t, jsonOutput)
 	})
 
 	t.Run("Should render empty response if no events returned", func(t *testing.T) {
@@ -131,7 +139,7 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		}
 
 		writer := bytes.NewBufferString("")
-		err = requestTapByResourceFromAPI(writer, mockApiClient, req, false)
+		err = requestTapByResourceFromAPI(writer, mockApiClient, req, "", nil)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -170,7 +178,7 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		}
 
 		writer := bytes.NewBufferString("")
-		err = requestTapByResourceFromAPI(writer, mockApiClient, req, false)
+		err = requestTapByResourceFromAPI(writer, mockApiClient, req, "", nil)
 		if err == nil {
 			t.Fatalf("Expecting error, got nothing but output [%s]", writer.String())
 		}
@@ -272,6 +280,37 @@ func TestEventToString(t *testing.T) {
 		}
 	})
 
+	t.Run("Converts classified gRPC response end event to string", func(t *testing.T) {
+		event := toTapEvent(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseEnd_{
+				ResponseEnd: &pb.TapEvent_Http_ResponseEnd{
+					SinceRequestInit:  &duration.Duration{Nanos: 999000},
+					SinceResponseInit: &duration.Duration{Nanos: 888000},
+					ResponseBytes:     111,
+					Eos: &pb.Eos{
+						End: &pb.Eos_GrpcStatusCode{GrpcStatusCode: uint32(codes.Unavailable)},
+					},
+					Classification: pb.TapEvent_Http_ResponseEnd_FAILURE,
+				},
+			},
+		})
+
+		expectedOutput := "end id=7:8 proxy=out src=1.2.3.4:5555 dst=2.3.4.5:6666 tls= grpc-status=Unavailable duration=888µs response-length=111B classification=failure"
+		output := util.RenderTapEvent(event, "")
+		if output != expectedOutput {
+			t.Fatalf("Expecting command output to be [%s], got [%s]", expectedOutput, output)
+		}
+
+		expectedJSON := `{"grpcStatus":"Unavailable","durationMicros":888,"responseBytes":111,"classification":"failure"}`
+		jsonOutput, err := jsonTapEventFormatter(event)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(jsonOutput, expectedJSON) {
+			t.Fatalf("Expecting JSON output to contain [%s], got [%s]", expectedJSON, jsonOutput)
+		}
+	})
+
 	t.Run("Converts HTTP response end event with reset error code to string", func(t *testing.T) {
 		event := toTapEvent(&pb.TapEvent_Http{
 			Event: &pb.TapEvent_Http_ResponseEnd_{
@@ -330,6 +369,38 @@ func TestEventToString(t *testing.T) {
 		}
 	})
 
+	t.Run("Includes the TLS status and identity of the peer", func(t *testing.T) {
+		event := toTapEvent(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseInit_{
+				ResponseInit: &pb.TapEvent_Http_ResponseInit{
+					SinceRequestInit: &duration.Duration{Nanos: 999000},
+					HttpStatus:       http.StatusOK,
+				},
+			},
+		})
+		event.DestinationMeta = &pb.TapEvent_EndpointMeta{
+			Labels: map[string]string{
+				"tls":                   "true",
+				"expected_tls_identity": "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local",
+			},
+		}
+
+		expectedOutput := "rsp id=7:8 proxy=out src=1.2.3.4:5555 dst=2.3.4.5:6666 tls=true expected-peer-identity=web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local :status=200 latency=999µs"
+		output := util.RenderTapEvent(event, "")
+		if output != expectedOutput {
+			t.Fatalf("Expecting command output to be [%s], got [%s]", expectedOutput, output)
+		}
+
+		expectedJSON := `"tls":"true","expectedPeerIdentity":"web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"`
+		jsonOutput, err := jsonTapEventFormatter(event)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(jsonOutput, expectedJSON) {
+			t.Fatalf("Expecting JSON output to contain [%s], got [%s]", expectedJSON, jsonOutput)
+		}
+	})
+
 	t.Run("Handles unknown event types", func(t *testing.T) {
 		event := toTapEvent(&pb.TapEvent_Http{})
 
@@ -111,8 +111,9 @@ func newCmdTop() *cobra.Command {
 
   # display traffic for the web-dlbvj pod in the default namespace
   linkerd top pod/web-dlbvj`,
-		Args:      cobra.RangeArgs(1, 2),
-		ValidArgs: util.ValidTargets,
+		Args:       cobra.RangeArgs(1, 2),
+		ValidArgs:  util.ValidTargets,
+		ArgAliases: resourceTypeAliases,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			requestParams := util.TapRequestParams{
 				Resource:    strings.Join(args, "/"),
@@ -131,7 +132,12 @@ func newCmdTop() *cobra.Command {
 				return err
 			}
 
-			return getTrafficByResourceFromAPI(os.Stdout, validatedPublicAPIClient(false), req, options)
+			client, err := validatedTapClient()
+			if err != nil {
+				return err
+			}
+
+			return getTrafficByResourceFromAPI(os.Stdout, client, req, options)
 		},
 	}
 
@@ -153,6 +159,9 @@ func newCmdTop() *cobra.Command {
 		"Display requests with paths that start with this prefix")
 	cmd.PersistentFlags().BoolVar(&options.hideSources, "hide-sources", options.hideSources, "Hide the source column")
 
+	markNamespaceFlagCompletion(cmd, "namespace")
+	markNamespaceFlagCompletion(cmd, "to-namespace")
+
 	return cmd
 }
 
@@ -273,16 +282,7 @@ func tableInsert(table *[]tableRow, req topRequest, withSource bool) {
 		log.Errorf("error parsing duration %v: %s", req.rspEnd.GetSinceRequestInit(), err)
 		return
 	}
-	success := req.rspInit.GetHttpStatus() < 500
-	if success {
-		switch eos := req.rspEnd.GetEos().GetEnd().(type) {
-		case *pb.Eos_GrpcStatusCode:
-			success = eos.GrpcStatusCode == 0
-
-		case *pb.Eos_ResetErrorCode:
-			success = false
-		}
-	}
+	success := responseSucceeded(req.rspInit, req.rspEnd)
 
 	found := false
 	for i, row := range *table {
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/cli/install"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterScopedResources maps the kinds of the resources created by install
+// that aren't removed along with the control plane namespace, and the
+// namespace itself, to their Kubernetes API resource names.
+var clusterScopedResources = map[string]string{
+	"Namespace":                      "namespaces",
+	"CustomResourceDefinition":       "customresourcedefinitions",
+	"ClusterRole":                    "clusterroles",
+	"ClusterRoleBinding":             "clusterrolebindings",
+	"APIService":                     "apiservices",
+	"MutatingWebhookConfiguration":   "mutatingwebhookconfigurations",
+	"ValidatingWebhookConfiguration": "validatingwebhookconfigurations",
+}
+
+type uninstallOptions struct {
+	confirm         bool
+	watchNamespaces []string
+}
+
+// uninstallResource identifies a resource to be deleted by uninstall.
+type uninstallResource struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"metadata"`
+}
+
+func newUninstallOptions() *uninstallOptions {
+	return &uninstallOptions{
+		confirm:         false,
+		watchNamespaces: nil,
+	}
+}
+
+func newCmdUninstall() *cobra.Command {
+	options := newUninstallOptions()
+
+	cmd := &cobra.Command{
+		Use:   "uninstall [flags]",
+		Short: "Output Kubernetes configs to uninstall Linkerd",
+		Long: `Output Kubernetes configs to uninstall Linkerd.
+
+The configs list the control plane namespace and the cluster-scoped resources
+that install creates, including those of optional features such as TLS, the
+proxy injector and the CNI plugin, so that no resources are left behind. The
+resources in the control plane namespace are deleted along with the namespace,
+and those in other namespaces, such as the Roles and RoleBindings of the
+watched namespaces, are listed by namespace and name. The watched namespaces
+are read from the install config of the cluster, unless --watch-namespaces is
+given. Deleting the CRDs of install deletes the TrafficSplits and
+ServiceProfiles of all namespaces.`,
+		Example: `  # Output the resources to delete, and delete them with kubectl.
+  linkerd uninstall | kubectl delete --ignore-not-found -f -
+
+  # Delete the resources directly.
+  linkerd uninstall --confirm`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.PersistentFlags().Changed("watch-namespaces") {
+				installConfigMap, err := getInstallConfig()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Not deleting the resources of the watched namespaces, as the install config can't be read from the cluster: %s\n", err)
+				} else if installConfigMap != nil {
+					if err := setFlagsFromInstallConfig(cmd.PersistentFlags(), installConfigMap); err != nil {
+						return err
+					}
+				}
+			}
+
+			resources, err := uninstallResources(options.watchNamespaces)
+			if err != nil {
+				return err
+			}
+
+			if !options.confirm {
+				return renderUninstall(os.Stdout, resources)
+			}
+
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+			client, err := kubeAPI.NewClient()
+			if err != nil {
+				return err
+			}
+			for _, resource := range resources {
+				if err := kubeAPI.DeleteResource(client, resource.path()); err != nil {
+					return fmt.Errorf("failed to delete %s %s: %s", resource.Kind, resource.name(), err)
+				}
+				fmt.Printf("%s \"%s\" deleted\n", strings.ToLower(resource.Kind), resource.name())
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&options.confirm, "confirm", options.confirm, "Delete the resources instead of outputting them")
+	cmd.PersistentFlags().StringSliceVar(&options.watchNamespaces, "watch-namespaces", options.watchNamespaces, "Namespaces to which the control plane was restricted when installing, whose Roles and RoleBindings are deleted as well")
+
+	return cmd
+}
+
+// uninstallResources returns the resources to delete, found by rendering the
+// install and CNI plugin configs with every optional feature enabled. They're
+// returned in the reverse order of their creation, so that the namespace is
+// deleted last. The namespaced resources of the control plane namespace are
+// left to be deleted along with it. Every rendered kind must be known, as
+// either cluster-scoped or namespaced, so that no kind that install creates
+// goes unnoticed.
+func uninstallResources(watchNamespaces []string) ([]uninstallResource, error) {
+	options := newInstallOptions()
+	options.watchNamespaces = watchNamespaces
+	options.tls = identityTLS
+	options.tapRBAC = true
+	options.metricsAdapter = true
+	options.proxyAutoInject = true
+	options.profileValidation = true
+	// the Grafana provisioner Job is rendered in place of the installed
+	// Grafana, whose resources are all namespaced
+	options.grafanaURL = "http://grafana.example.com"
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	cniConfig, err := validateAndBuildCNIConfig(newInstallCNIOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	for _, t := range []struct {
+		text   string
+		config interface{}
+	}{
+		{install.Template, config},
+		{install.TlsTemplate, config},
+		{install.ProxyInjectorTemplate, config},
+		{install.ProfileValidatorTemplate, config},
+		{install.CNITemplate, cniConfig},
+	} {
+		tmpl, err := parseTemplate("linkerd", t.text)
+		if err != nil {
+			return nil, err
+		}
+		if err := tmpl.Execute(buf, t.config); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n---\n")
+	}
+
+	resources := []uninstallResource{}
+	seen := map[string]bool{}
+	for _, doc := range strings.Split(buf.String(), "\n---\n") {
+		var resource uninstallResource
+		if err := yaml.Unmarshal([]byte(doc), &resource); err != nil {
+			return nil, err
+		}
+		if resource.Kind == "" {
+			continue
+		}
+		if _, ok := namespacedResources[resource.Kind]; ok {
+			ns := resource.Metadata.Namespace
+			if ns == "" || ns == controlPlaneNamespace {
+				continue
+			}
+		} else if _, ok := clusterScopedResources[resource.Kind]; !ok {
+			return nil, fmt.Errorf("unsupported kind %s", resource.Kind)
+		}
+		key := resource.Kind + "/" + resource.name()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		resources = append([]uninstallResource{resource}, resources...)
+	}
+
+	return resources, nil
+}
+
+// name returns the name of the resource, qualified by its namespace if it's
+// namespaced.
+func (resource uninstallResource) name() string {
+	if resource.Metadata.Namespace == "" {
+		return resource.Metadata.Name
+	}
+	return resource.Metadata.Namespace + "/" + resource.Metadata.Name
+}
+
+// path returns the path of the resource in the Kubernetes API.
+func (resource uninstallResource) path() string {
+	prefix := "/apis/"
+	if !strings.Contains(resource.APIVersion, "/") {
+		prefix = "/api/"
+	}
+	if resource.Metadata.Namespace != "" {
+		return fmt.Sprintf("%s%s/namespaces/%s/%s/%s", prefix, resource.APIVersion, resource.Metadata.Namespace, namespacedResources[resource.Kind], resource.Metadata.Name)
+	}
+	return fmt.Sprintf("%s%s/%s/%s", prefix, resource.APIVersion, clusterScopedResources[resource.Kind], resource.Metadata.Name)
+}
+
+func renderUninstall(w io.Writer, resources []uninstallResource) error {
+	for _, resource := range resources {
+		b, err := yaml.Marshal(resource)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "---\n%s", b)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUninstallResources(t *testing.T) {
+	resources, err := uninstallResources(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, resource := range resources {
+		if paths[resource.path()] {
+			t.Fatalf("Expected %s to be deleted once", resource.path())
+		}
+		paths[resource.path()] = true
+	}
+
+	for _, expected := range []string{
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/clusterroles/linkerd-%s-controller", controlPlaneNamespace),
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/clusterrolebindings/linkerd-%s-ca", controlPlaneNamespace),
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/clusterrolebindings/linkerd-%s-identity", controlPlaneNamespace),
+		fmt.Sprintf("/apis/admissionregistration.k8s.io/v1beta1/mutatingwebhookconfigurations/linkerd-%s-proxy-injector", controlPlaneNamespace),
+		fmt.Sprintf("/apis/admissionregistration.k8s.io/v1beta1/validatingwebhookconfigurations/linkerd-%s-sp-validator", controlPlaneNamespace),
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/namespaces/kube-system/rolebindings/linkerd-%s-controller-auth-reader", controlPlaneNamespace),
+		"/apis/apiregistration.k8s.io/v1beta1/apiservices/v1alpha1.tap.linkerd.io",
+		"/apis/apiregistration.k8s.io/v1beta1/apiservices/v1beta1.custom.metrics.k8s.io",
+		"/apis/rbac.authorization.k8s.io/v1beta1/clusterroles/linkerd-cni",
+		"/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions/trafficsplits.split.linkerd.io",
+		"/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions/serviceprofiles.linkerd.io",
+	} {
+		if !paths[expected] {
+			t.Fatalf("Expected %s to be deleted, got %v", expected, paths)
+		}
+	}
+
+	last := resources[len(resources)-1]
+	if last.path() != "/api/v1/namespaces/"+controlPlaneNamespace {
+		t.Fatalf("Expected the namespace to be deleted last, got %s", last.path())
+	}
+
+	var buf bytes.Buffer
+	if err := renderUninstall(&buf, resources); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), fmt.Sprintf("---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", controlPlaneNamespace)) {
+		t.Fatalf("Expected the namespace to be output last, got [%s]", buf.String())
+	}
+}
+
+func TestUninstallResourcesWithWatchNamespaces(t *testing.T) {
+	resources, err := uninstallResources([]string{"emojivoto"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, resource := range resources {
+		if resource.Metadata.Namespace == controlPlaneNamespace {
+			t.Fatalf("Expected %s to be deleted along with the namespace", resource.path())
+		}
+		paths[resource.path()] = true
+	}
+
+	for _, expected := range []string{
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/namespaces/emojivoto/roles/linkerd-%s-controller", controlPlaneNamespace),
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/namespaces/emojivoto/rolebindings/linkerd-%s-controller", controlPlaneNamespace),
+		fmt.Sprintf("/apis/rbac.authorization.k8s.io/v1beta1/namespaces/emojivoto/rolebindings/linkerd-%s-web-tap", controlPlaneNamespace),
+	} {
+		if !paths[expected] {
+			t.Fatalf("Expected %s to be deleted, got %v", expected, paths)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := renderUninstall(&buf, resources); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := fmt.Sprintf("kind: RoleBinding\nmetadata:\n  name: linkerd-%s-web-tap\n  namespace: emojivoto\n", controlPlaneNamespace)
+	if !strings.Contains(buf.String(), expected) {
+		t.Fatalf("Expected the RoleBinding to be output with its namespace, got [%s]", buf.String())
+	}
+}
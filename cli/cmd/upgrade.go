@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// installValuesKey, installUUIDKey and installVersionKey are the keys of
+	// the install config that record the flags set when installing, the UUID
+	// of the install and the version of the control plane.
+	installValuesKey  = "values"
+	installUUIDKey    = "uuid"
+	installVersionKey = "linkerd-version"
+
+	// installGrafanaURLKey is the key of the install config that records the
+	// URL of the existing Grafana server used instead of installing one.
+	installGrafanaURLKey = "grafana-url"
+)
+
+func newCmdUpgrade() *cobra.Command {
+	options := newInstallOptions()
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [flags]",
+		Short: "Output Kubernetes configs to upgrade an existing Linkerd control plane",
+		Long: `Output Kubernetes configs to upgrade an existing Linkerd control plane.
+
+The configuration of the existing control plane is read from the cluster, so
+that the flags it was installed with, such as --ha, --tls or --registry, are
+carried over to the new version. Flags given on the command line, or in a
+values file, take precedence over the existing configuration.`,
+		Example: `  # Upgrade the control plane to the version of the CLI.
+  linkerd upgrade | kubectl apply -f -
+
+  # Preview the changes that the upgrade would make to the cluster.
+  linkerd upgrade --diff`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+			if err != nil {
+				return err
+			}
+			client, err := kubeAPI.NewClient()
+			if err != nil {
+				return err
+			}
+
+			installConfigMap, err := kubeAPI.GetConfigMap(client, controlPlaneNamespace, k8s.InstallConfigMapName)
+			if err != nil {
+				return err
+			}
+			federationConfigMap, err := kubeAPI.GetConfigMap(client, controlPlaneNamespace, k8s.TLSFederationConfigMapName)
+			if err != nil {
+				return err
+			}
+
+			issuerSecret, err := kubeAPI.GetSecret(client, controlPlaneNamespace, k8s.IdentityIssuerSecretName)
+			if err != nil {
+				return err
+			}
+
+			config, err := buildUpgradeConfig(cmd.LocalFlags(), options, installConfigMap, federationConfigMap, issuerSecret)
+			if err != nil {
+				return err
+			}
+			if err := applyClusterCapabilities(config); err != nil {
+				return err
+			}
+
+			if options.diff {
+				buf := &bytes.Buffer{}
+				if err := render(*config, buf, options); err != nil {
+					return err
+				}
+				return diffWithCluster(buf.Bytes(), os.Stdout)
+			}
+
+			return renderUpgrade(*config, options, os.Stdout, os.Stderr)
+		},
+	}
+
+	addInstallFlags(cmd, options)
+
+	return cmd
+}
+
+// buildUpgradeConfig merges the flags recorded in the install config of the
+// existing control plane into the flags of the upgrade, and builds the config
+// of the new version. The UUID of the install, and the federated trust anchors
+// and the identity issuer, which were read from files when installing, are
+// preserved.
+func buildUpgradeConfig(flags *pflag.FlagSet, options *installOptions, installConfigMap, federationConfigMap *v1.ConfigMap, issuerSecret *v1.Secret) (*installConfig, error) {
+	if installConfigMap == nil {
+		return nil, fmt.Errorf("the %s ConfigMap wasn't found in the %s namespace; Linkerd must be installed with this version of the CLI or reinstalled before it can be upgraded",
+			k8s.InstallConfigMapName, controlPlaneNamespace)
+	}
+
+	// the values file given to the upgrade takes precedence over the flags
+	// of the existing install
+	if options.valuesFile != "" {
+		if err := setFlagsFromValuesFile(flags, options.valuesFile); err != nil {
+			return nil, err
+		}
+	}
+	source := fmt.Sprintf("the %s ConfigMap", k8s.InstallConfigMapName)
+	if err := setFlagsFromValues(flags, []byte(installConfigMap.Data[installValuesKey]), source); err != nil {
+		return nil, err
+	}
+
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	config.InstallValues, err = installValues(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if uuid := installConfigMap.Data[installUUIDKey]; uuid != "" {
+		config.UUID = uuid
+	}
+	if config.EnableTLS && config.FederatedTrustAnchors == "" && federationConfigMap != nil {
+		config.FederatedTrustAnchors = federationConfigMap.Data[k8s.TLSTrustAnchorFileName]
+	}
+	if config.EnableTLS && config.IdentityIssuerSecret == "" && issuerSecret != nil {
+		config.IdentityIssuerSecret = issuerSecret.Name
+		config.IdentityIssuerCertificate = base64.StdEncoding.EncodeToString(issuerSecret.Data[k8s.IdentityIssuerCertificateKey])
+		config.IdentityIssuerKey = base64.StdEncoding.EncodeToString(issuerSecret.Data[k8s.IdentityIssuerPrivateKeyKey])
+		if anchors := issuerSecret.Data[k8s.IdentityIssuerTrustAnchorsKey]; len(anchors) > 0 {
+			config.IdentityTrustAnchors = base64.StdEncoding.EncodeToString(anchors)
+		}
+	}
+
+	return config, nil
+}
+
+// renderUpgrade writes the configs of the new version to w, and lists the
+// flags carried over from the existing install on stderr, so that they can be
+// reviewed before applying.
+func renderUpgrade(config installConfig, options *installOptions, w, stderr io.Writer) error {
+	if config.InstallValues != "" {
+		fmt.Fprintf(stderr, "Upgrading to %s with the following flags:\n\n%s\n", options.linkerdVersion, config.InstallValues)
+	}
+
+	if err := render(config, w, options); err != nil {
+		return err
+	}
+	return renderExternalConfigs(config, stderr)
+}
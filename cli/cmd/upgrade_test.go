@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildUpgradeConfig(t *testing.T) {
+	parseFlags := func(args ...string) (*cobra.Command, *installOptions) {
+		cmd := &cobra.Command{}
+		options := newInstallOptions()
+		addInstallFlags(cmd, options)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return cmd, options
+	}
+
+	installCmd, installOptions := parseFlags("--ha", "--tls", "optional", "--registry", "registry.example.com/linkerd", "--linkerd-version", "stable-2.0.0")
+	installed, err := validateAndBuildConfig(installOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	values, err := installValues(installCmd.LocalFlags())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	installConfigMap := &v1.ConfigMap{Data: map[string]string{
+		installValuesKey: values,
+		installUUIDKey:   installed.UUID,
+	}}
+
+	t.Run("Preserves the flags of the existing install", func(t *testing.T) {
+		cmd, options := parseFlags("--linkerd-version", "stable-2.1.0", "--controller-log-level", "debug")
+
+		config, err := buildUpgradeConfig(cmd.LocalFlags(), options, installConfigMap, nil, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !config.EnableHA || !config.EnableTLS {
+			t.Fatal("Expected high availability and TLS to be preserved")
+		}
+		if config.ControllerImage != "registry.example.com/linkerd/controller:stable-2.1.0" {
+			t.Fatalf("Expected the controller to be upgraded from the existing registry, got %s", config.ControllerImage)
+		}
+		if config.ControllerLogLevel != "debug" {
+			t.Fatalf("Expected the controller log level to be debug, got %s", config.ControllerLogLevel)
+		}
+		if config.UUID != installed.UUID {
+			t.Fatalf("Expected the UUID %s to be preserved, got %s", installed.UUID, config.UUID)
+		}
+		for _, expected := range []string{"ha: \"true\"", "controller-log-level: debug"} {
+			if !strings.Contains(config.InstallValues, expected) {
+				t.Fatalf("Expected the recorded flags to contain [%s], got [%s]", expected, config.InstallValues)
+			}
+		}
+		if strings.Contains(config.InstallValues, "linkerd-version") {
+			t.Fatalf("Expected the version not to be recorded, got [%s]", config.InstallValues)
+		}
+	})
+
+	t.Run("Gives precedence to the command line", func(t *testing.T) {
+		cmd, options := parseFlags("--registry", "gcr.io/linkerd-io")
+
+		config, err := buildUpgradeConfig(cmd.LocalFlags(), options, installConfigMap, nil, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.DockerRegistry != "gcr.io/linkerd-io" {
+			t.Fatalf("Expected registry gcr.io/linkerd-io, got %s", config.DockerRegistry)
+		}
+	})
+
+	t.Run("Preserves the federated trust anchors", func(t *testing.T) {
+		federatedCA, err := ca.NewCA()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		federationConfigMap := &v1.ConfigMap{Data: map[string]string{
+			k8s.TLSTrustAnchorFileName: federatedCA.TrustAnchorPEM(),
+		}}
+		cmd, options := parseFlags()
+
+		config, err := buildUpgradeConfig(cmd.LocalFlags(), options, installConfigMap, federationConfigMap, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.FederatedTrustAnchors != federatedCA.TrustAnchorPEM() {
+			t.Fatalf("Expected federated trust anchors [%s], got [%s]", federatedCA.TrustAnchorPEM(), config.FederatedTrustAnchors)
+		}
+	})
+
+	t.Run("Preserves the identity issuer", func(t *testing.T) {
+		issuerSecret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: k8s.IdentityIssuerSecretName},
+			Data: map[string][]byte{
+				k8s.IdentityIssuerCertificateKey: []byte("issuer certificate"),
+				k8s.IdentityIssuerPrivateKeyKey:  []byte("issuer key"),
+			},
+		}
+		cmd, options := parseFlags()
+
+		config, err := buildUpgradeConfig(cmd.LocalFlags(), options, installConfigMap, nil, issuerSecret)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.IdentityIssuerSecret != k8s.IdentityIssuerSecretName || config.IdentityIssuerCertificate != base64.StdEncoding.EncodeToString([]byte("issuer certificate")) || config.IdentityIssuerKey != base64.StdEncoding.EncodeToString([]byte("issuer key")) {
+			t.Fatalf("Expected the identity issuer to be preserved, got [%s] [%s]", config.IdentityIssuerSecret, config.IdentityIssuerCertificate)
+		}
+	})
+
+	t.Run("Rejects control planes without an install config", func(t *testing.T) {
+		cmd, options := parseFlags()
+
+		if _, err := buildUpgradeConfig(cmd.LocalFlags(), options, nil, nil, nil); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
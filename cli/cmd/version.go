@@ -3,26 +3,46 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
-	"github.com/linkerd/linkerd2/controller/api/public"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/publicapi"
 	"github.com/linkerd/linkerd2/pkg/version"
 	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
 )
 
-const DefaultVersionString = "unavailable"
+const (
+	DefaultVersionString = "unavailable"
+
+	// maxProxyVersionNamespaces is the number of namespaces listed as
+	// examples of where a proxy version runs
+	maxProxyVersionNamespaces = 3
+)
 
 type versionOptions struct {
 	shortVersion      bool
 	onlyClientVersion bool
+	proxyVersions     bool
+}
+
+// proxyVersion is a version of the proxies of the meshed pods, with the number
+// of pods running it and the sorted namespaces of these pods.
+type proxyVersion struct {
+	version    string
+	pods       int
+	namespaces []string
 }
 
 func newVersionOptions() *versionOptions {
 	return &versionOptions{
 		shortVersion:      false,
 		onlyClientVersion: false,
+		proxyVersions:     false,
 	}
 }
 
@@ -52,6 +72,18 @@ func newCmdVersion() *cobra.Command {
 					fmt.Println(serverVersion)
 				} else {
 					fmt.Printf("Server version: %s\n", serverVersion)
+					for _, skew := range getComponentVersionSkew(client) {
+						fmt.Fprintf(os.Stderr, "Warning: %s\n", skew)
+					}
+				}
+
+				if options.proxyVersions {
+					versions, err := getProxyVersions()
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error listing the meshed pods: %s\n", err)
+						os.Exit(1)
+					}
+					renderProxyVersions(versions, os.Stdout, options.shortVersion)
 				}
 			}
 		},
@@ -60,6 +92,7 @@ func newCmdVersion() *cobra.Command {
 	cmd.Args = cobra.NoArgs
 	cmd.PersistentFlags().BoolVar(&options.shortVersion, "short", options.shortVersion, "Print the version number(s) only, with no additional output")
 	cmd.PersistentFlags().BoolVar(&options.onlyClientVersion, "client", options.onlyClientVersion, "Print the client version only")
+	cmd.PersistentFlags().BoolVar(&options.proxyVersions, "proxy", options.proxyVersions, "Also print the versions of the proxies of the meshed pods, e.g. to follow the roll of the data plane after an upgrade")
 
 	return cmd
 }
@@ -73,14 +106,136 @@ func getServerVersion(client pb.ApiClient) string {
 	return resp.GetReleaseVersion()
 }
 
+// getComponentVersionSkew describes the control plane components whose
+// version differs from the server version, e.g. during an upgrade. The
+// versions of images pinned by a digest alone can't be compared, so they're
+// not reported.
+func getComponentVersionSkew(client pb.ApiClient) []string {
+	resp, err := client.Version(context.Background(), &pb.Empty{})
+	if err != nil {
+		return nil
+	}
+
+	skew := make([]string, 0)
+	for _, c := range resp.GetComponents() {
+		if c.Version != resp.GetReleaseVersion() && !strings.HasPrefix(c.Version, "sha256:") {
+			skew = append(skew, fmt.Sprintf("%s container of %s pod of the %s component is running version %s",
+				c.Container, c.Pod, c.Component, c.Version))
+		}
+	}
+
+	return skew
+}
+
+// getProxyVersions returns the versions of the proxies of the pods meshed with
+// the control plane, in all namespaces.
+func getProxyVersions() ([]proxyVersion, error) {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubeAPI.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	pods, _, err := kubeAPI.GetMeshedPods(client, controlPlaneNamespace, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return groupProxyVersions(pods), nil
+}
+
+// groupProxyVersions groups the pods by the version of their proxy, sorted by
+// decreasing number of pods. The version is the one recorded when the pod was
+// injected, or else the tag of the proxy image.
+func groupProxyVersions(pods []v1.Pod) []proxyVersion {
+	byVersion := make(map[string]*proxyVersion)
+	namespaces := make(map[string]map[string]struct{})
+	for _, pod := range pods {
+		version := pod.Annotations[k8s.ProxyVersionAnnotation]
+		if version == "" {
+			version = proxyImageTag(pod)
+		}
+
+		if byVersion[version] == nil {
+			byVersion[version] = &proxyVersion{version: version}
+			namespaces[version] = make(map[string]struct{})
+		}
+		byVersion[version].pods++
+		namespaces[version][pod.Namespace] = struct{}{}
+	}
+
+	versions := make([]proxyVersion, 0, len(byVersion))
+	for version, v := range byVersion {
+		for ns := range namespaces[version] {
+			v.namespaces = append(v.namespaces, ns)
+		}
+		sort.Strings(v.namespaces)
+		versions = append(versions, *v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].pods != versions[j].pods {
+			return versions[i].pods > versions[j].pods
+		}
+		return versions[i].version < versions[j].version
+	})
+
+	return versions
+}
+
+func proxyImageTag(pod v1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == k8s.ProxyContainerName {
+			if tag := k8s.ImageTag(container.Image); tag != "" {
+				return tag
+			}
+		}
+	}
+	return DefaultVersionString
+}
+
+// renderProxyVersions writes a line per proxy version, with the number of pods
+// running it and up to maxProxyVersionNamespaces of their namespaces, or only
+// the versions if short is true.
+func renderProxyVersions(versions []proxyVersion, w io.Writer, short bool) {
+	if short {
+		for _, v := range versions {
+			fmt.Fprintln(w, v.version)
+		}
+		return
+	}
+
+	if len(versions) == 0 {
+		fmt.Fprintln(w, "Proxy versions: no meshed pods found")
+		return
+	}
+
+	fmt.Fprintln(w, "Proxy versions:")
+	for _, v := range versions {
+		namespaces := v.namespaces
+		if len(namespaces) > maxProxyVersionNamespaces {
+			namespaces = append(namespaces[:maxProxyVersionNamespaces:maxProxyVersionNamespaces], "...")
+		}
+
+		pods := "pods"
+		if v.pods == 1 {
+			pods = "pod"
+		}
+		fmt.Fprintf(w, "  %s: %d %s in %s\n", v.version, v.pods, pods, strings.Join(namespaces, ", "))
+	}
+}
+
 // This client does not do any validation
 func newVersionClient() (pb.ApiClient, error) {
 	if apiAddr != "" {
-		return public.NewInternalClient(controlPlaneNamespace, apiAddr)
+		return publicapi.NewAPIClient(controlPlaneNamespace, apiAddr, nil)
 	}
-	kubeAPI, err := k8s.NewAPI(kubeconfigPath)
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeContext, impersonate, impersonateGroup)
 	if err != nil {
 		return nil, err
 	}
-	return public.NewExternalClient(controlPlaneNamespace, kubeAPI)
+	return publicapi.NewAPIClient(controlPlaneNamespace, "", kubeAPI)
 }
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetServerVersion(t *testing.T) {
@@ -37,3 +42,85 @@ func TestGetServerVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestGetComponentVersionSkew(t *testing.T) {
+	t.Run("Returns the components running another version than the server", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		mockClient.VersionInfoToReturn = &pb.VersionInfo{
+			ReleaseVersion: "1.2.3",
+			Components: []*pb.ComponentVersion{
+				&pb.ComponentVersion{Component: "controller", Pod: "controller-1", Container: "public-api", Version: "1.2.3"},
+				&pb.ComponentVersion{Component: "web", Pod: "web-1", Container: "web", Version: "1.2.2"},
+				&pb.ComponentVersion{Component: "web", Pod: "web-1", Container: "linkerd-proxy", Version: "sha256:abcd"},
+			},
+		}
+
+		expectedSkew := []string{"web container of web-1 pod of the web component is running version 1.2.2"}
+
+		skew := getComponentVersionSkew(mockClient)
+
+		if !reflect.DeepEqual(skew, expectedSkew) {
+			t.Fatalf("Expected skew to be %v, was %v", expectedSkew, skew)
+		}
+	})
+}
+
+func TestProxyVersions(t *testing.T) {
+	pod := func(namespace, version, image string) v1.Pod {
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: k8s.ProxyContainerName, Image: image}},
+			},
+		}
+		if version != "" {
+			pod.Annotations = map[string]string{k8s.ProxyVersionAnnotation: version}
+		}
+		return pod
+	}
+
+	versions := groupProxyVersions([]v1.Pod{
+		pod("linkerd", "stable-2.1.0", "gcr.io/linkerd-io/proxy:stable-2.1.0"),
+		pod("emojivoto", "stable-2.0.0", "gcr.io/linkerd-io/proxy:stable-2.0.0"),
+		pod("books", "stable-2.0.0", "gcr.io/linkerd-io/proxy:stable-2.0.0"),
+		pod("emojivoto", "stable-2.0.0", "gcr.io/linkerd-io/proxy:stable-2.0.0"),
+		pod("default", "stable-2.0.0", "gcr.io/linkerd-io/proxy:stable-2.0.0"),
+		pod("vote-bot", "stable-2.0.0", "gcr.io/linkerd-io/proxy:stable-2.0.0"),
+		pod("linkerd", "", "localhost:5000/linkerd/proxy:dev-1234"),
+	})
+
+	t.Run("Groups the pods by proxy version", func(t *testing.T) {
+		expected := []proxyVersion{
+			{version: "stable-2.0.0", pods: 5, namespaces: []string{"books", "default", "emojivoto", "vote-bot"}},
+			{version: "dev-1234", pods: 1, namespaces: []string{"linkerd"}},
+			{version: "stable-2.1.0", pods: 1, namespaces: []string{"linkerd"}},
+		}
+		if !reflect.DeepEqual(versions, expected) {
+			t.Fatalf("Expected proxy versions %+v, got %+v", expected, versions)
+		}
+	})
+
+	t.Run("Renders the proxy versions", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderProxyVersions(versions, &buf, false)
+
+		expected := `Proxy versions:
+  stable-2.0.0: 5 pods in books, default, emojivoto, ...
+  dev-1234: 1 pod in linkerd
+  stable-2.1.0: 1 pod in linkerd
+`
+		if buf.String() != expected {
+			t.Fatalf("Expected output:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("Renders only the proxy versions with --short", func(t *testing.T) {
+		var buf bytes.Buffer
+		renderProxyVersions(versions, &buf, true)
+
+		expected := "stable-2.0.0\ndev-1234\nstable-2.1.0\n"
+		if buf.String() != expected {
+			t.Fatalf("Expected output:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+}
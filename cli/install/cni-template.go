@@ -0,0 +1,137 @@
+package install
+
+// CNITemplate provides the template for the `linkerd install-cni` command.
+const CNITemplate = `### Namespace ###
+kind: Namespace
+apiVersion: v1
+metadata:
+  name: {{.Namespace}}
+
+### Service Account CNI ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-cni
+  namespace: {{.Namespace}}
+
+### CNI RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-cni
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get"]
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-cni
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-cni
+subjects:
+- kind: ServiceAccount
+  name: linkerd-cni
+  namespace: {{.Namespace}}
+
+### CNI Config ###
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: linkerd-cni-config
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: cni
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  dest_cni_net_dir: "{{.DestCNINetDir}}"
+  dest_cni_bin_dir: "{{.DestCNIBinDir}}"
+  # The plugin is chained to the network configuration of the cluster. The
+  # __KUBECONFIG_FILEPATH__ placeholder is replaced by the installer with the
+  # path of the kubeconfig of the linkerd-cni service account.
+  cni_network_config: |-
+    {
+      "name": "linkerd-cni",
+      "type": "linkerd-cni",
+      "kubernetes": {
+        "kubeconfig": "__KUBECONFIG_FILEPATH__"
+      },
+      "linkerd": {
+        "incoming-proxy-port": {{.InboundPort}},
+        "outgoing-proxy-port": {{.OutboundPort}},
+        "proxy-uid": {{.ProxyUID}},
+        "inbound-ports-to-ignore": [{{.InboundPortsToIgnore}}],
+        "outbound-ports-to-ignore": [{{.OutboundPortsToIgnore}}]
+      }
+    }
+
+### CNI Plugin ###
+---
+kind: DaemonSet
+apiVersion: extensions/v1beta1
+metadata:
+  name: linkerd-cni
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: cni
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  selector:
+    matchLabels:
+      {{.ControllerComponentLabel}}: cni
+  updateStrategy:
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: cni
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+    spec:
+      serviceAccountName: linkerd-cni
+      # the plugin must be installed on every node, including the ones that
+      # are tainted to only run some workloads
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: install-cni
+        image: {{.CNIPluginImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        env:
+        - name: DEST_CNI_NET_DIR
+          valueFrom:
+            configMapKeyRef:
+              name: linkerd-cni-config
+              key: dest_cni_net_dir
+        - name: DEST_CNI_BIN_DIR
+          valueFrom:
+            configMapKeyRef:
+              name: linkerd-cni-config
+              key: dest_cni_bin_dir
+        - name: CNI_NETWORK_CONFIG
+          valueFrom:
+            configMapKeyRef:
+              name: linkerd-cni-config
+              key: cni_network_config
+        volumeMounts:
+        - mountPath: /host/opt/cni/bin
+          name: cni-bin-dir
+        - mountPath: /host/etc/cni/net.d
+          name: cni-net-dir
+      volumes:
+      - name: cni-bin-dir
+        hostPath:
+          path: {{.DestCNIBinDir}}
+      - name: cni-net-dir
+        hostPath:
+          path: {{.DestCNINetDir}}
+`
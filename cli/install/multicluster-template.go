@@ -0,0 +1,234 @@
+package install
+
+// GatewayTemplate provides the template for the `linkerd install-gateway`
+// command.
+const GatewayTemplate = `### Gateway Config ###
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: {{.GatewayName}}-config
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: gateway
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  # the requests from the other clusters are proxied to their authority, which
+  # is the fully qualified name of the exported service, through the outbound
+  # proxy of the gateway
+  nginx.conf: |-
+    events {
+    }
+    http {
+      resolver {{.DNSResolver}} valid=10s;
+      server {
+        listen 8081;
+        location = /ready {
+          return 200;
+        }
+      }
+      server {
+        listen 8080;
+        location / {
+          proxy_http_version 1.1;
+          proxy_pass http://$http_host;
+        }
+      }
+    }
+
+### Gateway ###
+---
+kind: Deployment
+apiVersion: extensions/v1beta1
+metadata:
+  name: {{.GatewayName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: gateway
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  replicas: {{.GatewayReplicas}}
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: gateway
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+    spec:
+      volumes:
+      - name: config
+        configMap:
+          name: {{.GatewayName}}-config
+      containers:
+      - name: nginx
+        image: {{.GatewayImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        ports:
+        - name: {{.GatewayPortName}}
+          containerPort: 8080
+        - name: mc-probe
+          containerPort: 8081
+        volumeMounts:
+        - name: config
+          mountPath: /etc/nginx/nginx.conf
+          subPath: nginx.conf
+          readOnly: true
+        readinessProbe:
+          httpGet:
+            path: /ready
+            port: 8081
+
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: {{.GatewayName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: gateway
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+    {{.GatewayIdentityAnnotation}}: {{.GatewayIdentity}}
+spec:
+  type: LoadBalancer
+  selector:
+    {{.ControllerComponentLabel}}: gateway
+  ports:
+  # the proxy of the gateway terminates the mTLS connections from the other
+  # clusters, and forwards their requests to nginx
+  - name: {{.GatewayPortName}}
+    port: {{.GatewayPort}}
+    targetPort: 8080
+
+### Service Mirror Remote Access ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-service-mirror-remote-access
+  namespace: {{.Namespace}}
+
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-service-mirror-remote-access
+rules:
+- apiGroups: [""]
+  resources: ["services"]
+  verbs: ["get", "list", "watch"]
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-service-mirror-remote-access
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-service-mirror-remote-access
+subjects:
+- kind: ServiceAccount
+  name: linkerd-service-mirror-remote-access
+  namespace: {{.Namespace}}
+`
+
+// ServiceMirrorTemplate provides the template for the `linkerd
+// install-service-mirror` command.
+const ServiceMirrorTemplate = `### Service Account Service Mirror ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-service-mirror-{{.ClusterName}}
+  namespace: {{.Namespace}}
+
+### Service Mirror RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-service-mirror-{{.ClusterName}}
+rules:
+- apiGroups: [""]
+  resources: ["namespaces"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["services", "endpoints"]
+  verbs: ["get", "list", "watch", "create", "update", "delete"]
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-service-mirror-{{.ClusterName}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-service-mirror-{{.ClusterName}}
+subjects:
+- kind: ServiceAccount
+  name: linkerd-service-mirror-{{.ClusterName}}
+  namespace: {{.Namespace}}
+
+### Service Mirror ###
+---
+kind: Deployment
+apiVersion: extensions/v1beta1
+metadata:
+  name: linkerd-service-mirror-{{.ClusterName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: service-mirror
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  replicas: 1
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: service-mirror
+        mirror.linkerd.io/cluster-name: {{.ClusterName}}
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+    spec:
+      serviceAccount: linkerd-service-mirror-{{.ClusterName}}
+      # the kubeconfig of the remote cluster, with the credentials of its
+      # linkerd-service-mirror-remote-access ServiceAccount
+      volumes:
+      - name: remote-kubeconfig
+        secret:
+          secretName: {{.RemoteKubeconfigSecret}}
+      containers:
+      - name: service-mirror
+        ports:
+        - name: admin-http
+          containerPort: 9994
+        image: {{.ControllerImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        args:
+        - "service-mirror"
+        - "-remote-kubeconfig=/var/run/linkerd/remote/kubeconfig"
+        - "-cluster-name={{.ClusterName}}"
+        - "-gateway-name={{.GatewayName}}"
+        - "-gateway-namespace={{.GatewayNamespace}}"
+        - "-remote-controller-namespace={{.RemoteControllerNamespace}}"
+        - "-remote-cluster-domain={{.RemoteClusterDomain}}"
+        - "-log-level={{.ControllerLogLevel}}"
+        volumeMounts:
+        - name: remote-kubeconfig
+          mountPath: /var/run/linkerd/remote
+          readOnly: true
+        livenessProbe:
+          httpGet:
+            path: /ping
+            port: 9994
+          initialDelaySeconds: 10
+        readinessProbe:
+          httpGet:
+            path: /ready
+            port: 9994
+          failureThreshold: 7
+`
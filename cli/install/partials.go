@@ -0,0 +1,55 @@
+package install
+
+// Partials provides the templates shared by the other templates, which are
+// parsed along with each of them.
+const Partials = `
+{{- /* the node selector and tolerations of a pod spec */}}
+{{- define "scheduling"}}
+{{- with .}}
+{{- with .NodeSelector}}
+      nodeSelector:
+        {{- range $key, $value := .}}
+        {{$key}}: {{printf "%q" $value}}
+        {{- end}}
+{{- end}}
+{{- with .Tolerations}}
+      tolerations:
+      {{- range .}}
+      - key: {{.Key}}
+        operator: {{.Operator}}
+        {{- if .Value}}
+        value: {{printf "%q" .Value}}
+        {{- end}}
+        {{- if .Effect}}
+        effect: {{.Effect}}
+        {{- end}}
+      {{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- /* the resource requests and limits of a container */}}
+{{- define "resources"}}
+{{- with .}}
+        resources:
+          {{- if or .CPURequest .MemoryRequest}}
+          requests:
+            {{- if .CPURequest}}
+            cpu: {{.CPURequest}}
+            {{- end}}
+            {{- if .MemoryRequest}}
+            memory: {{.MemoryRequest}}
+            {{- end}}
+          {{- end}}
+          {{- if or .CPULimit .MemoryLimit}}
+          limits:
+            {{- if .CPULimit}}
+            cpu: {{.CPULimit}}
+            {{- end}}
+            {{- if .MemoryLimit}}
+            memory: {{.MemoryLimit}}
+            {{- end}}
+          {{- end}}
+{{- end}}
+{{- end}}
+`
@@ -7,6 +7,42 @@ apiVersion: v1
 metadata:
   name: {{.Namespace}}
 
+### Install Config ###
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: {{.InstallConfigMapName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  registry: {{.DockerRegistry}}
+  controller-image: {{.ControllerImage}}
+  web-image: {{.WebImage}}
+  prometheus-image: {{.PrometheusImage}}
+  grafana-image: {{.GrafanaImage}}
+  proxy-image: {{.ProxyImage}}
+  proxy-init-image: {{.ProxyInitImage}}
+  linkerd-version: {{.LinkerdVersion}}
+  proxy-architectures: {{.ProxyArchitectures}}
+  uuid: {{.UUID}}
+  trust-domain: {{.TrustDomain}}
+  {{- if .EnableTLS}}
+  trust-anchors-config-map: {{.TLSTrustAnchorConfigMapName}}
+  {{- end}}
+  {{- if .ExternalPrometheus}}
+  prometheus-url: {{.PrometheusURL}}
+  {{- end}}
+  {{- if .ExternalGrafana}}
+  grafana-url: {{.GrafanaURL}}
+  {{- end}}
+  {{- if .InstallValues}}
+  values: {{printf "%q" .InstallValues}}
+  {{- end}}
+
 ### Service Account Controller ###
 ---
 kind: ServiceAccount
@@ -22,12 +58,33 @@ apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-controller
 rules:
+{{- if .WatchNamespaceList}}
+- apiGroups: [""]
+  resources: ["namespaces", "nodes"]
+  verbs: ["list", "get", "watch"]
+{{- else}}
 - apiGroups: ["extensions", "apps"]
   resources: ["deployments", "replicasets"]
   verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["pods", "endpoints", "services", "namespaces", "nodes", "replicationcontrollers"]
+  verbs: ["list", "get", "watch"]
 - apiGroups: [""]
-  resources: ["pods", "endpoints", "services", "namespaces", "replicationcontrollers"]
+  resources: ["pods/status"]
+  verbs: ["update"]
+- apiGroups: ["split.linkerd.io"]
+  resources: ["trafficsplits"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["linkerd.io"]
+  resources: ["serviceprofiles"]
   verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+{{- end}}
 
 ---
 kind: ClusterRoleBinding
@@ -42,6 +99,246 @@ subjects:
 - kind: ServiceAccount
   name: linkerd-controller
   namespace: {{.Namespace}}
+{{- range .WatchNamespaceList}}
+
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-controller
+  namespace: {{.}}
+rules:
+- apiGroups: ["extensions", "apps"]
+  resources: ["deployments", "replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["pods", "endpoints", "services", "replicationcontrollers"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["pods/status"]
+  verbs: ["update"]
+- apiGroups: ["split.linkerd.io"]
+  resources: ["trafficsplits"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["linkerd.io"]
+  resources: ["serviceprofiles"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-controller
+  namespace: {{.}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-{{$.Namespace}}-controller
+subjects:
+- kind: ServiceAccount
+  name: linkerd-controller
+  namespace: {{$.Namespace}}
+{{- end}}
+
+### Traffic Split CRD ###
+---
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: trafficsplits.split.linkerd.io
+  labels:
+    {{.ControllerComponentLabel}}: controller
+spec:
+  group: split.linkerd.io
+  version: v1alpha1
+  scope: Namespaced
+  names:
+    plural: trafficsplits
+    singular: trafficsplit
+    kind: TrafficSplit
+    shortNames:
+    - ts
+
+### Service Profile CRD ###
+---
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: serviceprofiles.linkerd.io
+  labels:
+    {{.ControllerComponentLabel}}: controller
+spec:
+  group: linkerd.io
+  version: v1alpha1
+  scope: Namespaced
+  names:
+    plural: serviceprofiles
+    singular: serviceprofile
+    kind: ServiceProfile
+    shortNames:
+    - sp
+{{- if or .TapRBAC .APIRBAC}}
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-controller-auth-delegator
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: system:auth-delegator
+subjects:
+- kind: ServiceAccount
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+{{- end}}
+{{- if or .TapRBAC .MetricsAdapter}}
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-controller-auth-reader
+  namespace: kube-system
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: extension-apiserver-authentication-reader
+subjects:
+- kind: ServiceAccount
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+{{- end}}
+{{- if or .TapRBAC .APIRBAC}}
+
+### Tap RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-tap
+rules:
+- apiGroups: ["tap.linkerd.io"]
+  resources: ["tap"]
+  verbs: ["watch"]
+{{- end}}
+{{- if .TapRBAC}}
+
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-tap-apiserver-tls
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.TapAPIServerTLSCert}}
+  tls.key: {{.TapAPIServerTLSKey}}
+
+---
+kind: APIService
+apiVersion: apiregistration.k8s.io/v1beta1
+metadata:
+  name: v1alpha1.tap.linkerd.io
+  labels:
+    {{.ControllerComponentLabel}}: controller
+spec:
+  group: tap.linkerd.io
+  version: v1alpha1
+  groupPriorityMinimum: 1000
+  versionPriority: 100
+  caBundle: {{.TapAPIServerCABundle}}
+  service:
+    name: api
+    namespace: {{.Namespace}}
+
+### Service Account Web ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-web
+  namespace: {{.Namespace}}
+{{- range .WatchNamespaceList}}
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-web-tap
+  namespace: {{.}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{$.Namespace}}-tap
+subjects:
+- kind: ServiceAccount
+  name: linkerd-web
+  namespace: {{$.Namespace}}
+{{- else}}
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-web-tap
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-tap
+subjects:
+- kind: ServiceAccount
+  name: linkerd-web
+  namespace: {{.Namespace}}
+{{- end}}
+{{- end}}
+{{- if .MetricsAdapter}}
+
+### Metrics Adapter ###
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-metrics-adapter-tls
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.MetricsAdapterTLSCert}}
+  tls.key: {{.MetricsAdapterTLSKey}}
+
+---
+kind: APIService
+apiVersion: apiregistration.k8s.io/v1beta1
+metadata:
+  name: v1beta1.custom.metrics.k8s.io
+  labels:
+    {{.ControllerComponentLabel}}: controller
+spec:
+  group: custom.metrics.k8s.io
+  version: v1beta1
+  groupPriorityMinimum: 100
+  versionPriority: 100
+  caBundle: {{.MetricsAdapterCABundle}}
+  service:
+    name: linkerd-metrics-adapter
+    namespace: {{.Namespace}}
+{{- end}}
+{{- if not .ExternalPrometheus}}
 
 ### Service Account Prometheus ###
 ---
@@ -52,6 +349,34 @@ metadata:
   namespace: {{.Namespace}}
 
 ### Prometheus RBAC ###
+{{- range .WatchNamespaceList}}
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-prometheus
+  namespace: {{.}}
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get", "list", "watch"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-prometheus
+  namespace: {{.}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-{{$.Namespace}}-prometheus
+subjects:
+- kind: ServiceAccount
+  name: linkerd-prometheus
+  namespace: {{$.Namespace}}
+
+{{- else}}
 ---
 kind: ClusterRole
 apiVersion: rbac.authorization.k8s.io/v1beta1
@@ -75,6 +400,8 @@ subjects:
 - kind: ServiceAccount
   name: linkerd-prometheus
   namespace: {{.Namespace}}
+{{- end}}
+{{- end}}
 
 ### Controller ###
 ---
@@ -95,6 +422,32 @@ spec:
   - name: http
     port: 8085
     targetPort: 8085
+  {{- if .TapRBAC}}
+  - name: apiserver
+    port: 443
+    targetPort: 8443
+  {{- end}}
+{{- if .MetricsAdapter}}
+
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: linkerd-metrics-adapter
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  type: ClusterIP
+  selector:
+    {{.ControllerComponentLabel}}: controller
+  ports:
+  - name: metrics-adapter
+    port: 443
+    targetPort: 8444
+{{- end}}
 
 ---
 kind: Service
@@ -134,7 +487,42 @@ spec:
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
+      {{- template "scheduling" .ControllerScheduling}}
       serviceAccount: linkerd-controller
+      {{- if or .TapRBAC .MetricsAdapter}}
+      volumes:
+      {{- if .TapRBAC}}
+      - name: tap-apiserver-tls
+        secret:
+          secretName: linkerd-tap-apiserver-tls
+      {{- end}}
+      {{- if .MetricsAdapter}}
+      - name: metrics-adapter-tls
+        secret:
+          secretName: linkerd-metrics-adapter-tls
+      {{- end}}
+      {{- end}}
+      {{- if .EnableHA}}
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              labelSelector:
+                matchExpressions:
+                - key: {{.ControllerComponentLabel}}
+                  operator: In
+                  values:
+                  - controller
+              topologyKey: kubernetes.io/hostname
+      {{- end}}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
       containers:
       - name: public-api
         ports:
@@ -142,13 +530,62 @@ spec:
           containerPort: 8085
         - name: admin-http
           containerPort: 9995
+        {{- if .TapRBAC}}
+        - name: apiserver
+          containerPort: 8443
+        {{- end}}
+        {{- if .MetricsAdapter}}
+        - name: metrics-adapter
+          containerPort: 8444
+        {{- end}}
         image: {{.ControllerImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "public-api"
-        - "-prometheus-url=http://prometheus.{{.Namespace}}.svc.cluster.local:9090"
+        - "-prometheus-url={{.PrometheusURL}}"
+        - "-prometheus-query-timeout={{.PrometheusQueryTimeout}}"
+        {{- if .PrometheusMaxSamples}}
+        - "-prometheus-max-samples={{.PrometheusMaxSamples}}"
+        {{- end}}
         - "-controller-namespace={{.Namespace}}"
+        {{- if .WatchNamespaces}}
+        - "-watch-namespaces={{.WatchNamespaces}}"
+        {{- end}}
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        {{- if .TapRBAC}}
+        - "-apiserver-addr=:8443"
+        {{- end}}
+        {{- if .MetricsAdapter}}
+        - "-metrics-adapter-addr=:8444"
+        {{- end}}
+        {{- if .APIRBAC}}
+        - "-enforce-rbac=true"
+        {{- end}}
+        {{- if or .TapRBAC .MetricsAdapter}}
+        volumeMounts:
+        {{- if .TapRBAC}}
+        - name: tap-apiserver-tls
+          mountPath: /var/linkerd-io/tap-apiserver/tls
+          readOnly: true
+        {{- end}}
+        {{- if .MetricsAdapter}}
+        - name: metrics-adapter-tls
+          mountPath: /var/linkerd-io/metrics-adapter/tls
+          readOnly: true
+        {{- end}}
+        {{- end}}
+        {{- template "resources" .ControllerResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -167,10 +604,26 @@ spec:
           containerPort: 9999
         image: {{.ControllerImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "destination"
         - "-enable-tls={{.EnableTLS}}"
+        - "-trust-domain={{.TrustDomain}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        {{- if .WatchNamespaces}}
+        - "-watch-namespaces={{.WatchNamespaces}}"
+        {{- end}}
+        {{- template "resources" .ControllerResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -189,10 +642,22 @@ spec:
           containerPort: 9996
         image: {{.ControllerImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "proxy-api"
         - "-addr=:{{.ProxyAPIPort}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        {{- template "resources" .ControllerResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -205,16 +670,33 @@ spec:
           failureThreshold: 7
       - name: tap
         ports:
-        - name: grpc
-          containerPort: 8088
         - name: admin-http
           containerPort: 9998
         image: {{.ControllerImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "tap"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
         - "-controller-namespace={{.Namespace}}"
+        - "-trust-domain={{.TrustDomain}}"
+        {{- if .WatchNamespaces}}
+        - "-watch-namespaces={{.WatchNamespaces}}"
+        {{- end}}
+        {{- if or .TapRBAC .APIRBAC}}
+        - "-enforce-rbac=true"
+        {{- end}}
+        {{- template "resources" .ControllerResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -225,6 +707,24 @@ spec:
             path: /ready
             port: 9998
           failureThreshold: 7
+{{- if .EnableHA}}
+
+---
+kind: PodDisruptionBudget
+apiVersion: policy/v1beta1
+metadata:
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      {{.ControllerComponentLabel}}: controller
+{{- end}}
 
 ### Web ###
 ---
@@ -268,6 +768,31 @@ spec:
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
+      {{- template "scheduling" .WebScheduling}}
+      {{- if .TapRBAC}}
+      serviceAccount: linkerd-web
+      {{- end}}
+      {{- if .EnableHA}}
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              labelSelector:
+                matchExpressions:
+                - key: {{.ControllerComponentLabel}}
+                  operator: In
+                  values:
+                  - web
+              topologyKey: kubernetes.io/hostname
+      {{- end}}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
       containers:
       - name: web
         ports:
@@ -277,6 +802,14 @@ spec:
           containerPort: 9994
         image: {{.WebImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "-api-addr=api.{{.Namespace}}.svc.cluster.local:8085"
         - "-static-dir=/dist"
@@ -284,6 +817,24 @@ spec:
         - "-uuid={{.UUID}}"
         - "-controller-namespace={{.Namespace}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        {{- if .TapRBAC}}
+        - "-tap-api=true"
+        {{- end}}
+        {{- if .ExternalGrafana}}
+        - "-grafana-url={{.GrafanaURL}}"
+        {{- end}}
+        {{- if .DashboardPathPrefix}}
+        - "-path-prefix={{.DashboardPathPrefix}}"
+        {{- end}}
+        {{- if .EnforcedHost}}
+        # single-quoted, as the backslashes of the regexp are escapes in
+        # double-quoted YAML
+        - '-enforced-host={{.EnforcedHost}}'
+        {{- end}}
+        {{- template "resources" .WebResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -294,6 +845,25 @@ spec:
             path: /ready
             port: 9994
           failureThreshold: 7
+{{- if .EnableHA}}
+
+---
+kind: PodDisruptionBudget
+apiVersion: policy/v1beta1
+metadata:
+  name: linkerd-web
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: web
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      {{.ControllerComponentLabel}}: web
+{{- end}}
+{{- if not .ExternalPrometheus}}
 
 ### Prometheus ###
 ---
@@ -334,11 +904,23 @@ spec:
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
+      {{- template "scheduling" .PrometheusScheduling}}
       serviceAccount: linkerd-prometheus
       volumes:
       - name: prometheus-config
         configMap:
           name: prometheus-config
+      {{- if .RestrictedPodSecurity}}
+      - name: prometheus-data
+        emptyDir: {}
+      {{- end}}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
       containers:
       - name: prometheus
         ports:
@@ -348,11 +930,27 @@ spec:
         - name: prometheus-config
           mountPath: /etc/prometheus
           readOnly: true
+        {{- if .RestrictedPodSecurity}}
+        - name: prometheus-data
+          mountPath: /data
+        {{- end}}
         image: {{.PrometheusImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "--storage.tsdb.retention=6h"
         - "--config.file=/etc/prometheus/prometheus.yml"
+        {{- if .RestrictedPodSecurity}}
+        - "--storage.tsdb.path=/data"
+        {{- end}}
+        {{- template "resources" .PrometheusResources}}
         readinessProbe:
           httpGet:
             path: /-/ready
@@ -382,72 +980,75 @@ data:
       scrape_interval: 10s
       scrape_timeout: 10s
       evaluation_interval: 10s
+      {{- if .PrometheusExternalLabels}}
+      external_labels:
+        {{- range $name, $value := .PrometheusExternalLabels}}
+        {{$name}}: {{printf "%q" $value}}
+        {{- end}}
+      {{- end}}
+    {{- if .PrometheusRemoteWriteURLs}}
+
+    remote_write:
+    {{- range .PrometheusRemoteWriteURLs}}
+    - url: {{printf "%q" .}}
+    {{- end}}
+    {{- end}}
+
+    rule_files:
+    - /etc/prometheus/recording_rules.yml
 
     scrape_configs:
     - job_name: 'prometheus'
       static_configs:
       - targets: ['localhost:9090']
+{{template "linkerd-scrape-configs" .}}
 
-    - job_name: 'grafana'
-      kubernetes_sd_configs:
-      - role: pod
-        namespaces:
-          names: ['{{.Namespace}}']
-      relabel_configs:
-      - source_labels:
-        - __meta_kubernetes_pod_container_name
-        action: keep
-        regex: ^grafana$
+  recording_rules.yml: |-
+    # the inbound request volumes and latencies of the resources queried by
+    # linkerd stat and the dashboard, pre-aggregated from the series of
+    # every pod
+    groups:
+    - name: linkerd-stat
+      rules:
+      - record: namespace:response_total:rate1m
+        expr: sum(rate(response_total{direction="inbound"}[1m])) by (namespace, direction, classification, tls)
+      - record: namespace:response_latency_ms_bucket:rate1m
+        expr: sum(rate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, direction)
+      - record: namespace_deployment:response_total:rate1m
+        expr: sum(rate(response_total{direction="inbound"}[1m])) by (namespace, deployment, direction, classification, tls)
+      - record: namespace_deployment:response_latency_ms_bucket:rate1m
+        expr: sum(rate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, deployment, direction)
+      - record: namespace_replicationcontroller:response_total:rate1m
+        expr: sum(rate(response_total{direction="inbound"}[1m])) by (namespace, replicationcontroller, direction, classification, tls)
+      - record: namespace_replicationcontroller:response_latency_ms_bucket:rate1m
+        expr: sum(rate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, replicationcontroller, direction)
+      - record: namespace_pod:response_total:rate1m
+        expr: sum(rate(response_total{direction="inbound"}[1m])) by (namespace, pod, direction, classification, tls)
+      - record: namespace_pod:response_latency_ms_bucket:rate1m
+        expr: sum(rate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, pod, direction)
+    # the inbound request volumes and latencies of the routes of the
+    # ServiceProfiles, by resource and authority, queried by linkerd routes
+    - name: linkerd-routes
+      rules:
+      - record: namespace:route_response_total:rate1m
+        expr: sum(rate(route_response_total{direction="inbound"}[1m])) by (namespace, direction, rt_route, dst, classification, tls)
+      - record: namespace:route_response_latency_ms_bucket:rate1m
+        expr: sum(rate(route_response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, direction, rt_route, dst)
+      - record: namespace_deployment:route_response_total:rate1m
+        expr: sum(rate(route_response_total{direction="inbound"}[1m])) by (namespace, deployment, direction, rt_route, dst, classification, tls)
+      - record: namespace_deployment:route_response_latency_ms_bucket:rate1m
+        expr: sum(rate(route_response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, deployment, direction, rt_route, dst)
+      - record: namespace_replicationcontroller:route_response_total:rate1m
+        expr: sum(rate(route_response_total{direction="inbound"}[1m])) by (namespace, replicationcontroller, direction, rt_route, dst, classification, tls)
+      - record: namespace_replicationcontroller:route_response_latency_ms_bucket:rate1m
+        expr: sum(rate(route_response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, replicationcontroller, direction, rt_route, dst)
+      - record: namespace_pod:route_response_total:rate1m
+        expr: sum(rate(route_response_total{direction="inbound"}[1m])) by (namespace, pod, direction, rt_route, dst, classification, tls)
+      - record: namespace_pod:route_response_latency_ms_bucket:rate1m
+        expr: sum(rate(route_response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace, pod, direction, rt_route, dst)
+{{- end}}
 
-    - job_name: 'linkerd-controller'
-      kubernetes_sd_configs:
-      - role: pod
-        namespaces:
-          names: ['{{.Namespace}}']
-      relabel_configs:
-      - source_labels:
-        - __meta_kubernetes_pod_label_linkerd_io_control_plane_component
-        - __meta_kubernetes_pod_container_port_name
-        action: keep
-        regex: (.*);admin-http$
-      - source_labels: [__meta_kubernetes_pod_container_name]
-        action: replace
-        target_label: component
-
-    - job_name: 'linkerd-proxy'
-      kubernetes_sd_configs:
-      - role: pod
-      relabel_configs:
-      - source_labels:
-        - __meta_kubernetes_pod_container_name
-        - __meta_kubernetes_pod_container_port_name
-        - __meta_kubernetes_pod_label_linkerd_io_control_plane_ns
-        action: keep
-        regex: ^{{.ProxyContainerName}};linkerd-metrics;{{.Namespace}}$
-      - source_labels: [__meta_kubernetes_namespace]
-        action: replace
-        target_label: namespace
-      - source_labels: [__meta_kubernetes_pod_name]
-        action: replace
-        target_label: pod
-      # special case k8s' "job" label, to not interfere with prometheus' "job"
-      # label
-      # __meta_kubernetes_pod_label_linkerd_io_proxy_job=foo =>
-      # k8s_job=foo
-      - source_labels: [__meta_kubernetes_pod_label_linkerd_io_proxy_job]
-        action: replace
-        target_label: k8s_job
-      # __meta_kubernetes_pod_label_linkerd_io_proxy_deployment=foo =>
-      # deployment=foo
-      - action: labelmap
-        regex: __meta_kubernetes_pod_label_linkerd_io_proxy_(.+)
-      # drop all labels that we just made copies of in the previous labelmap
-      - action: labeldrop
-        regex: __meta_kubernetes_pod_label_linkerd_io_proxy_(.+)
-      # __meta_kubernetes_pod_label_linkerd_io_foo=bar =>
-      # foo=bar
-      - action: labelmap
-        regex: __meta_kubernetes_pod_label_linkerd_io_(.+)
+{{- if not .ExternalGrafana}}
 
 ### Grafana ###
 ---
@@ -488,6 +1089,7 @@ spec:
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
+      {{- template "scheduling" .GrafanaScheduling}}
       volumes:
       - name: grafana-config
         configMap:
@@ -499,6 +1101,17 @@ spec:
             path: provisioning/datasources/datasources.yaml
           - key: dashboards.yaml
             path: provisioning/dashboards/dashboards.yaml
+      {{- if .RestrictedPodSecurity}}
+      - name: grafana-data
+        emptyDir: {}
+      {{- end}}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
       containers:
       - name: grafana
         ports:
@@ -508,8 +1121,21 @@ spec:
         - name: grafana-config
           mountPath: /etc/grafana
           readOnly: true
+        {{- if .RestrictedPodSecurity}}
+        - name: grafana-data
+          mountPath: /var/lib/grafana
+        {{- end}}
         image: {{.GrafanaImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
+        {{- template "resources" .GrafanaResources}}
         livenessProbe:
           httpGet:
             path: /api/health
@@ -538,7 +1164,9 @@ data:
     instance_name = linkerd-grafana
 
     [server]
-    root_url = %(protocol)s://%(domain)s:/api/v1/namespaces/{{.Namespace}}/services/grafana:http/proxy/
+    # Grafana is served under the path of its service under the Kubernetes
+    # API's service proxy, by both kubectl proxy and the dashboard
+    root_url = %(protocol)s://%(domain)s:{{.DashboardPathPrefix}}/api/v1/namespaces/{{.Namespace}}/services/grafana:http/proxy/
 
     [auth]
     disable_login_form = true
@@ -560,7 +1188,7 @@ data:
       type: prometheus
       access: proxy
       orgId: 1
-      url: http://prometheus.{{.Namespace}}.svc.cluster.local:9090
+      url: {{.PrometheusURL}}
       isDefault: true
       jsonData:
         timeInterval: "5s"
@@ -579,7 +1207,153 @@ data:
       options:
         path: /var/lib/grafana/dashboards
         homeDashboardId: linkerd-top-line
-`
+{{- else}}
+
+### Grafana Provisioner ###
+---
+kind: Job
+apiVersion: batch/v1
+metadata:
+  name: linkerd-grafana-provisioner
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: grafana-provisioner
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  backoffLimit: 3
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: grafana-provisioner
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+        {{.ProxyInjectAnnotation}}: {{.ProxyInjectDisabled}}
+    spec:
+      {{- template "scheduling" .GrafanaScheduling}}
+      restartPolicy: OnFailure
+      volumes:
+      - name: dashboards
+        emptyDir: {}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
+      initContainers:
+      # the dashboards are copied from the Grafana image that they're built into
+      - name: dashboards
+        image: {{.GrafanaImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        command: ["sh", "-c", "cp /var/lib/grafana/dashboards/*.json /dashboards/"]
+        volumeMounts:
+        - name: dashboards
+          mountPath: /dashboards
+      containers:
+      - name: grafana-provisioner
+        image: {{.ControllerImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
+        args:
+        - "grafana-provisioner"
+        - "-grafana-url={{.GrafanaURL}}"
+        - "-prometheus-url={{.PrometheusURL}}"
+        - "-dashboards-dir=/dashboards"
+        - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        env:
+        - name: GRAFANA_API_KEY
+          valueFrom:
+            secretKeyRef:
+              name: {{.GrafanaProvisionerSecret}}
+              key: api-key
+              optional: true
+        volumeMounts:
+        - name: dashboards
+          mountPath: /dashboards
+          readOnly: true
+{{- end}}
+{{define "linkerd-scrape-configs"}}
+    {{- if not .ExternalGrafana}}
+    - job_name: 'grafana'
+      kubernetes_sd_configs:
+      - role: pod
+        namespaces:
+          names: ['{{.Namespace}}']
+      relabel_configs:
+      - source_labels:
+        - __meta_kubernetes_pod_container_name
+        action: keep
+        regex: ^grafana$
+    {{- end}}
+
+    - job_name: 'linkerd-controller'
+      kubernetes_sd_configs:
+      - role: pod
+        namespaces:
+          names: ['{{.Namespace}}']
+      relabel_configs:
+      - source_labels:
+        - __meta_kubernetes_pod_label_linkerd_io_control_plane_component
+        - __meta_kubernetes_pod_container_port_name
+        action: keep
+        regex: (.*);admin-http$
+      - source_labels: [__meta_kubernetes_pod_container_name]
+        action: replace
+        target_label: component
+      - source_labels: [__meta_kubernetes_pod_name]
+        action: replace
+        target_label: pod
+
+    - job_name: 'linkerd-proxy'
+      kubernetes_sd_configs:
+      - role: pod
+        {{- if .WatchNamespaceList}}
+        namespaces:
+          names: [{{range $i, $ns := .WatchNamespaceList}}{{if $i}}, {{end}}'{{$ns}}'{{end}}]
+        {{- end}}
+      relabel_configs:
+      - source_labels:
+        - __meta_kubernetes_pod_container_name
+        - __meta_kubernetes_pod_container_port_name
+        - __meta_kubernetes_pod_label_linkerd_io_control_plane_ns
+        action: keep
+        regex: ^{{.ProxyContainerName}};linkerd-metrics;{{.Namespace}}$
+      - source_labels: [__meta_kubernetes_namespace]
+        action: replace
+        target_label: namespace
+      - source_labels: [__meta_kubernetes_pod_name]
+        action: replace
+        target_label: pod
+      # special case k8s' "job" label, to not interfere with prometheus' "job"
+      # label
+      # __meta_kubernetes_pod_label_linkerd_io_proxy_job=foo =>
+      # k8s_job=foo
+      - source_labels: [__meta_kubernetes_pod_label_linkerd_io_proxy_job]
+        action: replace
+        target_label: k8s_job
+      # __meta_kubernetes_pod_label_linkerd_io_proxy_deployment=foo =>
+      # deployment=foo
+      - action: labelmap
+        regex: __meta_kubernetes_pod_label_linkerd_io_proxy_(.+)
+      # drop all labels that we just made copies of in the previous labelmap
+      - action: labeldrop
+        regex: __meta_kubernetes_pod_label_linkerd_io_proxy_(.+)
+      # __meta_kubernetes_pod_label_linkerd_io_foo=bar =>
+      # foo=bar
+      - action: labelmap
+        regex: __meta_kubernetes_pod_label_linkerd_io_(.+){{end}}`
 
 const TlsTemplate = `
 ### Service Account CA ###
@@ -590,7 +1364,98 @@ metadata:
   name: linkerd-ca
   namespace: {{.Namespace}}
 
+{{- if .FederatedTrustAnchors}}
+### Federation ###
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: {{.TLSFederationConfigMapName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: ca
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  trust-anchors.pem: {{printf "%q" .FederatedTrustAnchors}}
+{{- end}}
+{{- if .IdentityIssuerCertificate}}
+
+### Identity Issuer ###
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: {{.IdentityIssuerSecret}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: ca
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.IdentityIssuerCertificate}}
+  tls.key: {{.IdentityIssuerKey}}
+  {{- if .IdentityTrustAnchors}}
+  ca.crt: {{.IdentityTrustAnchors}}
+  {{- end}}
+{{- end}}
+
 ### CA RBAC ###
+{{- range .WatchNamespaceList}}
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-ca
+  namespace: {{.}}
+rules:
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["create"]
+- apiGroups: [""]
+  resources: ["configmaps"]
+  resourceNames: [{{$.TLSTrustAnchorConfigMapName}}]
+  verbs: ["update"]
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["extensions", "apps"]
+  resources: ["replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["create", "update"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+# the CA grants the controller access to the secrets it issues
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["roles", "rolebindings"]
+  verbs: ["create"]
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["roles"]
+  resourceNames: [linkerd-{{$.Namespace}}-controller-identity]
+  verbs: ["get", "update"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-ca
+  namespace: {{.}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-{{$.Namespace}}-ca
+subjects:
+- kind: ServiceAccount
+  name: linkerd-ca
+  namespace: {{$.Namespace}}
+{{- else}}
 ---
 kind: ClusterRole
 apiVersion: rbac.authorization.k8s.io/v1beta1
@@ -610,9 +1475,23 @@ rules:
 - apiGroups: ["extensions", "apps"]
   resources: ["replicasets"]
   verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
 - apiGroups: [""]
   resources: ["secrets"]
   verbs: ["create", "update"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+# the CA grants the controller access to the secrets it issues
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["roles", "rolebindings"]
+  verbs: ["create"]
+- apiGroups: ["rbac.authorization.k8s.io"]
+  resources: ["roles"]
+  resourceNames: [linkerd-{{.Namespace}}-controller-identity]
+  verbs: ["get", "update"]
 
 ---
 kind: ClusterRoleBinding
@@ -627,6 +1506,67 @@ subjects:
 - kind: ServiceAccount
   name: linkerd-ca
   namespace: {{.Namespace}}
+{{- end}}
+{{- if .EnableIdentity}}
+
+### Identity Service RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-identity
+rules:
+- apiGroups: ["authentication.k8s.io"]
+  resources: ["tokenreviews"]
+  verbs: ["create"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-identity
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-identity
+subjects:
+- kind: ServiceAccount
+  name: linkerd-ca
+  namespace: {{.Namespace}}
+{{- end}}
+{{- if .IdentityIssuerSecret}}
+
+### Identity Issuer RBAC ###
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-identity-issuer
+  namespace: {{.Namespace}}
+rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  resourceNames: [{{.IdentityIssuerSecret}}]
+  verbs: ["get", "list", "watch"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-identity-issuer
+  namespace: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-{{.Namespace}}-identity-issuer
+subjects:
+- kind: ServiceAccount
+  name: linkerd-ca
+  namespace: {{.Namespace}}
+{{- end}}
 
 ### CA ###
 ---
@@ -640,26 +1580,82 @@ metadata:
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
 spec:
-  replicas: {{.ControllerReplicas}}
+  # a single CA runs, even in high availability mode, as each CA generates its
+  # own root, and the trust anchors would change with the CA that answers
+  replicas: 1
+  strategy:
+    type: Recreate
   template:
     metadata:
       labels:
         {{.ControllerComponentLabel}}: ca
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
+        {{- if .EnableIdentity}}
+        # the CA serves the identity service, which its own proxy would have to
+        # be certified by before it could accept any connection
+        {{.ProxyInjectAnnotation}}: {{.ProxyInjectDisabled}}
+        {{- end}}
     spec:
+      {{- template "scheduling" .ControllerScheduling}}
       serviceAccount: linkerd-ca
+      {{- if .FederatedTrustAnchors}}
+      volumes:
+      - name: linkerd-federation
+        configMap:
+          name: {{.TLSFederationConfigMapName}}
+      {{- end}}
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
       containers:
       - name: ca
         ports:
         - name: admin-http
           containerPort: 9997
+        {{- if .EnableIdentity}}
+        - name: identity-grpc
+          containerPort: {{.IdentityServicePort}}
+        {{- end}}
         image: {{.ControllerImage}}
         imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
         args:
         - "ca"
         - "-controller-namespace={{.Namespace}}"
+        {{- if .WatchNamespaces}}
+        - "-watch-namespaces={{.WatchNamespaces}}"
+        {{- end}}
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        - "-trust-domain={{.TrustDomain}}"
+        {{- if .EnableIdentity}}
+        - "-identity-addr=:{{.IdentityServicePort}}"
+        {{- end}}
+        {{- if .IdentityIssuerSecret}}
+        - "-issuer-secret={{.IdentityIssuerSecret}}"
+        {{- end}}
+        {{- if .FederatedTrustAnchors}}
+        - "-federated-trust-anchors=/var/linkerd-io/federation/trust-anchors.pem"
+        volumeMounts:
+        - name: linkerd-federation
+          mountPath: /var/linkerd-io/federation
+          readOnly: true
+        {{- end}}
+        {{- template "resources" .ControllerResources}}
         livenessProbe:
           httpGet:
             path: /ping
@@ -670,4 +1666,413 @@ spec:
             path: /ready
             port: 9997
           failureThreshold: 7
+{{- if .EnableIdentity}}
+
+### Identity Service ###
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: {{.IdentityServiceName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: ca
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  type: ClusterIP
+  selector:
+    {{.ControllerComponentLabel}}: ca
+  ports:
+  - name: grpc
+    port: {{.IdentityServicePort}}
+    targetPort: {{.IdentityServicePort}}
+{{- end}}
+`
+
+const ProxyInjectorTemplate = `
+### Service Account Proxy Injector ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-proxy-injector
+  namespace: {{.Namespace}}
+
+### Proxy Injector RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-proxy-injector
+rules:
+- apiGroups: [""]
+  resources: ["namespaces"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create"]
+{{- if not .WatchNamespaceList}}
+- apiGroups: [""]
+  resources: ["replicationcontrollers"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["extensions", "apps"]
+  resources: ["deployments", "replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+{{- end}}
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-proxy-injector
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-proxy-injector
+subjects:
+- kind: ServiceAccount
+  name: linkerd-proxy-injector
+  namespace: {{.Namespace}}
+{{- range .WatchNamespaceList}}
+
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-proxy-injector
+  namespace: {{.}}
+rules:
+- apiGroups: [""]
+  resources: ["replicationcontrollers"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["extensions", "apps"]
+  resources: ["deployments", "replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{$.Namespace}}-proxy-injector
+  namespace: {{.}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-{{$.Namespace}}-proxy-injector
+subjects:
+- kind: ServiceAccount
+  name: linkerd-proxy-injector
+  namespace: {{$.Namespace}}
+{{- end}}
+
+### Proxy Injector Config ###
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-proxy-injector-tls
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.ProxyInjectorTLSCert}}
+  tls.key: {{.ProxyInjectorTLSKey}}
+
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: linkerd-proxy-injector-sidecar-config
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  sidecar.yaml: {{printf "%q" .ProxyInjectorSidecarConfig}}
+
+### Proxy Injector ###
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: proxy-injector
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  type: ClusterIP
+  selector:
+    {{.ControllerComponentLabel}}: proxy-injector
+  ports:
+  - name: proxy-injector
+    port: 443
+    targetPort: 8443
+
+---
+kind: Deployment
+apiVersion: extensions/v1beta1
+metadata:
+  name: proxy-injector
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  replicas: {{.ControllerReplicas}}
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: proxy-injector
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+    spec:
+      {{- template "scheduling" .ControllerScheduling}}
+      serviceAccount: linkerd-proxy-injector
+      volumes:
+      - name: sidecar-config
+        configMap:
+          name: linkerd-proxy-injector-sidecar-config
+      - name: tls
+        secret:
+          secretName: linkerd-proxy-injector-tls
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
+      containers:
+      - name: proxy-injector
+        ports:
+        - name: proxy-injector
+          containerPort: 8443
+        - name: admin-http
+          containerPort: 9993
+        image: {{.ControllerImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
+        args:
+        - "proxy-injector"
+        - "-controller-namespace={{.Namespace}}"
+        - "-tls-mode={{.TLSMode}}"
+        {{- if .WatchNamespaces}}
+        - "-watch-namespaces={{.WatchNamespaces}}"
+        {{- end}}
+        - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        volumeMounts:
+        - name: sidecar-config
+          mountPath: /var/linkerd-io/proxy-injector/config
+          readOnly: true
+        - name: tls
+          mountPath: /var/linkerd-io/proxy-injector/tls
+          readOnly: true
+        livenessProbe:
+          httpGet:
+            path: /ping
+            port: 9993
+          initialDelaySeconds: 10
+        readinessProbe:
+          httpGet:
+            path: /ready
+            port: 9993
+          failureThreshold: 7
+
+### Proxy Injector Webhook ###
+---
+kind: MutatingWebhookConfiguration
+apiVersion: admissionregistration.k8s.io/{{.WebhookAPIVersion}}
+metadata:
+  name: linkerd-{{.Namespace}}-proxy-injector
+  labels:
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+webhooks:
+- name: proxy-injector.linkerd.io
+  clientConfig:
+    service:
+      name: proxy-injector
+      namespace: {{.Namespace}}
+      path: "/"
+    caBundle: {{.ProxyInjectorCABundle}}
+  rules:
+  - operations: ["CREATE"]
+    apiGroups: [""]
+    apiVersions: ["v1"]
+    resources: ["pods"]
+  failurePolicy: Ignore
+  {{- if eq .WebhookAPIVersion "v1"}}
+  sideEffects: NoneOnDryRun
+  admissionReviewVersions: ["v1beta1"]
+  {{- end}}
+`
+
+const ProfileValidatorTemplate = `
+### Service Account Profile Validator ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-sp-validator
+  namespace: {{.Namespace}}
+
+### Profile Validator Config ###
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: linkerd-sp-validator-tls
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: sp-validator
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.ProfileValidatorTLSCert}}
+  tls.key: {{.ProfileValidatorTLSKey}}
+
+### Profile Validator ###
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: sp-validator
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: sp-validator
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  type: ClusterIP
+  selector:
+    {{.ControllerComponentLabel}}: sp-validator
+  ports:
+  - name: sp-validator
+    port: 443
+    targetPort: 8443
+
+---
+kind: Deployment
+apiVersion: extensions/v1beta1
+metadata:
+  name: sp-validator
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerComponentLabel}}: sp-validator
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  replicas: {{.ControllerReplicas}}
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: sp-validator
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+    spec:
+      {{- template "scheduling" .ControllerScheduling}}
+      serviceAccount: linkerd-sp-validator
+      volumes:
+      - name: tls
+        secret:
+          secretName: linkerd-sp-validator-tls
+      {{- if .RestrictedPodSecurity}}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: {{.ControlPlaneUID}}
+        seccompProfile:
+          type: RuntimeDefault
+      {{- end}}
+      containers:
+      - name: sp-validator
+        ports:
+        - name: sp-validator
+          containerPort: 8443
+        - name: admin-http
+          containerPort: 9992
+        image: {{.ControllerImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        {{- if .RestrictedPodSecurity}}
+        securityContext:
+          readOnlyRootFilesystem: true
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        {{- end}}
+        args:
+        - "sp-validator"
+        - "-log-level={{.ControllerLogLevel}}"
+        {{- if .LatencyBuckets}}
+        - "-latency-buckets={{.LatencyBuckets}}"
+        {{- end}}
+        volumeMounts:
+        - name: tls
+          mountPath: /var/linkerd-io/sp-validator/tls
+          readOnly: true
+        livenessProbe:
+          httpGet:
+            path: /ping
+            port: 9992
+          initialDelaySeconds: 10
+        readinessProbe:
+          httpGet:
+            path: /ready
+            port: 9992
+          failureThreshold: 7
+
+### Profile Validator Webhook ###
+---
+kind: ValidatingWebhookConfiguration
+apiVersion: admissionregistration.k8s.io/{{.WebhookAPIVersion}}
+metadata:
+  name: linkerd-{{.Namespace}}-sp-validator
+  labels:
+    {{.ControllerComponentLabel}}: sp-validator
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+webhooks:
+- name: sp-validator.linkerd.io
+  clientConfig:
+    service:
+      name: sp-validator
+      namespace: {{.Namespace}}
+      path: "/"
+    caBundle: {{.ProfileValidatorCABundle}}
+  rules:
+  - operations: ["CREATE", "UPDATE"]
+    apiGroups: ["linkerd.io"]
+    apiVersions: ["v1alpha1"]
+    resources: ["serviceprofiles"]
+  failurePolicy: Ignore
+  {{- if eq .WebhookAPIVersion "v1"}}
+  sideEffects: None
+  admissionReviewVersions: ["v1beta1"]
+  {{- end}}
 `
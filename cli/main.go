@@ -7,6 +7,8 @@ import (
 )
 
 func main() {
+	defer cmd.RecoverPanic()
+
 	if err := cmd.RootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// NoColorEnv is the environment variable that disables colored output when
+// set, whatever its value, see https://no-color.org.
+const NoColorEnv = "NO_COLOR"
+
+// LineWidth is the width of the lines written by StatusLine.
+const LineWidth = 80
+
+// Color is the ANSI code of a foreground color. The escape sequences of all
+// the colors have the same length, so that a column of a tabwriter stays
+// aligned as long as all its cells are colored, with Default if need be.
+type Color int
+
+const (
+	Red     Color = 31
+	Green   Color = 32
+	Yellow  Color = 33
+	Default Color = 39
+)
+
+const reset = "\x1b[0m"
+
+var colorEnabled = false
+
+// Configure enables colored output if f is a terminal, unless noColor is true
+// or the NO_COLOR environment variable is set.
+func Configure(f *os.File, noColor bool) {
+	_, noColorEnv := os.LookupEnv(NoColorEnv)
+	SetColorEnabled(!noColor && !noColorEnv && terminal.IsTerminal(int(f.Fd())))
+}
+
+// SetColorEnabled enables or disables colored output.
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// ColorEnabled reports whether colored output is enabled.
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// Colorize returns text in color c, or text unchanged if colored output is
+// disabled.
+func Colorize(c Color, text string) string {
+	if !colorEnabled {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s%s", c, text, reset)
+}
+
+// Status is the outcome of a check or of a step of a command.
+type Status int
+
+const (
+	Ok Status = iota
+	Warn
+	Fail
+	Retry
+)
+
+type statusStyle struct {
+	text   string
+	symbol string
+	color  Color
+}
+
+var statusStyles = map[Status]statusStyle{
+	Ok:    {text: "[ok]", symbol: "√", color: Green},
+	Warn:  {text: "[warn]", symbol: "‼", color: Yellow},
+	Fail:  {text: "[FAIL]", symbol: "×", color: Red},
+	Retry: {text: "[retry]", symbol: "…", color: Yellow},
+}
+
+// String returns the status as written without color, e.g. "[ok]".
+func (s Status) String() string {
+	return statusStyles[s].text
+}
+
+// Render returns the status as written to the output: a colored symbol if
+// colored output is enabled, or else its String.
+func (s Status) Render() string {
+	style := statusStyles[s]
+	if !colorEnabled {
+		return style.text
+	}
+	return Colorize(style.color, style.symbol)
+}
+
+// StatusLine writes label, padded with dots up to the status, followed by
+// detail if it isn't empty.
+func StatusLine(w io.Writer, label string, status Status, detail string) {
+	filler := ""
+	if width := LineWidth - len(label) - len(Ok.String()) - len("\n"); width > 0 {
+		filler = strings.Repeat(".", width)
+	}
+
+	if detail == "" {
+		fmt.Fprintf(w, "%s%s%s\n", label, filler, status.Render())
+		return
+	}
+	fmt.Fprintf(w, "%s%s%s -- %s\n", label, filler, status.Render(), detail)
+}
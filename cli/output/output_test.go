@@ -0,0 +1,73 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStatusLine(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+
+	t.Run("Renders the status text without color", func(t *testing.T) {
+		SetColorEnabled(false)
+
+		var buf bytes.Buffer
+		StatusLine(&buf, "kubernetes-api: can query the Kubernetes API", Ok, "")
+		StatusLine(&buf, "kubernetes-api: can query the Kubernetes API", Fail, "connection refused")
+
+		expected := "kubernetes-api: can query the Kubernetes API...............................[ok]\n" +
+			"kubernetes-api: can query the Kubernetes API...............................[FAIL] -- connection refused\n"
+		if buf.String() != expected {
+			t.Fatalf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("Renders a colored status symbol with color", func(t *testing.T) {
+		SetColorEnabled(true)
+
+		var buf bytes.Buffer
+		StatusLine(&buf, "linkerd-api: control plane pods are ready", Warn, "1 pod is pending")
+
+		expected := "linkerd-api: control plane pods are ready..................................\x1b[33m‼\x1b[0m -- 1 pod is pending\n"
+		if buf.String() != expected {
+			t.Fatalf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+		}
+	})
+}
+
+func TestColorize(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+
+	SetColorEnabled(false)
+	if text := Colorize(Red, "100.00%"); text != "100.00%" {
+		t.Fatalf("Expected uncolored text, got %q", text)
+	}
+
+	SetColorEnabled(true)
+	red := Colorize(Red, "-")
+	if red != "\x1b[31m-\x1b[0m" {
+		t.Fatalf("Expected red text, got %q", red)
+	}
+	if len(Colorize(Default, "-")) != len(red) {
+		t.Fatalf("Expected the escape sequences of all the colors to have the same length")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer SetColorEnabled(ColorEnabled())
+
+	// the tests don't write to a terminal
+	Configure(os.Stdout, false)
+	if ColorEnabled() {
+		t.Fatalf("Expected colored output to be disabled when not writing to a terminal")
+	}
+
+	os.Setenv(NoColorEnv, "")
+	defer os.Unsetenv(NoColorEnv)
+	SetColorEnabled(true)
+	Configure(os.Stdout, false)
+	if ColorEnabled() {
+		t.Fatalf("Expected colored output to be disabled by %s", NoColorEnv)
+	}
+}
@@ -0,0 +1,83 @@
+#!/bin/bash
+
+# Installs the linkerd-cni plugin on the node of the DaemonSet pod that runs
+# this script, then waits until the pod is deleted to uninstall it.
+#
+# The plugin is chained to the first network configuration found in
+# $DEST_CNI_NET_DIR, so that it runs once the pod network is set up.
+
+set -eu
+
+HOST_CNI_BIN_DIR=/host/opt/cni/bin
+HOST_CNI_NET_DIR=/host/etc/cni/net.d
+KUBECONFIG_FILE_NAME=linkerd-cni-kubeconfig
+SERVICE_ACCOUNT_PATH=/var/run/secrets/kubernetes.io/serviceaccount
+
+conf_file=$(find "$HOST_CNI_NET_DIR" -maxdepth 1 \( -name '*.conflist' -o -name '*.conf' \) | sort | head -n 1)
+if [ -z "$conf_file" ]; then
+    echo "no CNI network configuration found in $DEST_CNI_NET_DIR" >&2
+    exit 1
+fi
+
+cleanup() {
+    echo "removing linkerd-cni from $conf_file"
+    tmp=$(mktemp)
+    jq 'del(.plugins[]? | select(.type == "linkerd-cni"))' "$conf_file" > "$tmp" && mv "$tmp" "$conf_file"
+    rm -f "$HOST_CNI_BIN_DIR/linkerd-cni" "$HOST_CNI_NET_DIR/$KUBECONFIG_FILE_NAME"
+    exit 0
+}
+trap cleanup EXIT
+trap "exit 0" TERM INT
+
+echo "installing linkerd-cni in $DEST_CNI_BIN_DIR"
+cp /opt/cni/bin/linkerd-cni "$HOST_CNI_BIN_DIR/linkerd-cni"
+
+# the plugin reads pods with the credentials of the linkerd-cni service account
+cat > "$HOST_CNI_NET_DIR/$KUBECONFIG_FILE_NAME" <<EOF
+apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}
+    certificate-authority-data: $(base64 -w 0 < "$SERVICE_ACCOUNT_PATH/ca.crt")
+users:
+- name: linkerd-cni
+  user:
+    token: $(cat "$SERVICE_ACCOUNT_PATH/token")
+contexts:
+- name: linkerd-cni-context
+  context:
+    cluster: local
+    user: linkerd-cni
+current-context: linkerd-cni-context
+EOF
+chmod 600 "$HOST_CNI_NET_DIR/$KUBECONFIG_FILE_NAME"
+
+plugin=$(echo "$CNI_NETWORK_CONFIG" | sed "s|__KUBECONFIG_FILEPATH__|$DEST_CNI_NET_DIR/$KUBECONFIG_FILE_NAME|")
+
+echo "adding linkerd-cni to $conf_file"
+tmp=$(mktemp)
+case "$conf_file" in
+    *.conflist)
+        jq --argjson plugin "$plugin" \
+            '.plugins |= (map(select(.type != "linkerd-cni")) + [$plugin])' \
+            "$conf_file" > "$tmp"
+        ;;
+    *)
+        # a single plugin configuration is turned into a list, as chaining
+        # requires one
+        jq --argjson plugin "$plugin" \
+            '{cniVersion: .cniVersion, name: .name, plugins: [del(.cniVersion, .name), $plugin]}' \
+            "$conf_file" > "$tmp"
+        rm "$conf_file"
+        conf_file="${conf_file}list"
+        ;;
+esac
+mv "$tmp" "$conf_file"
+
+echo "linkerd-cni installed"
+while true; do
+    sleep 3600 &
+    wait $!
+done
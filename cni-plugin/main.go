@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/proxy-init/iptables"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// supportedVersions lists the versions of the CNI spec implemented by the
+// plugin.
+var supportedVersions = []string{"0.1.0", "0.2.0", "0.3.0", "0.3.1"}
+
+// netConf is the network configuration passed by the container runtime to
+// the plugin, which is chained after the plugin that sets up the pod network.
+type netConf struct {
+	CNIVersion string          `json:"cniVersion"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	PrevResult json.RawMessage `json:"prevResult,omitempty"`
+	Kubernetes struct {
+		Kubeconfig string `json:"kubeconfig"`
+	} `json:"kubernetes"`
+	Linkerd proxyInitConf `json:"linkerd"`
+}
+
+// proxyInitConf mirrors the flags of proxy-init, which configures the pods'
+// iptables when the CNI plugin isn't installed.
+type proxyInitConf struct {
+	IncomingProxyPort     int   `json:"incoming-proxy-port"`
+	OutgoingProxyPort     int   `json:"outgoing-proxy-port"`
+	ProxyUID              int   `json:"proxy-uid"`
+	InboundPortsToIgnore  []int `json:"inbound-ports-to-ignore"`
+	OutboundPortsToIgnore []int `json:"outbound-ports-to-ignore"`
+	Simulate              bool  `json:"simulate"`
+}
+
+// cniError is written to stdout when the plugin fails, as required by the
+// CNI spec.
+type cniError struct {
+	CNIVersion string `json:"cniVersion"`
+	Code       uint   `json:"code"`
+	Msg        string `json:"msg"`
+}
+
+func main() {
+	// stdout is reserved for the result of the plugin
+	log.SetOutput(os.Stderr)
+
+	var err error
+	switch os.Getenv("CNI_COMMAND") {
+	case "ADD":
+		err = cmdAdd()
+	case "DEL", "CHECK":
+		// the rules are removed along with the network namespace of the pod
+		err = cmdPassThrough()
+	case "VERSION":
+		err = json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+			"cniVersion":        supportedVersions[len(supportedVersions)-1],
+			"supportedVersions": supportedVersions,
+		})
+	default:
+		err = fmt.Errorf("unsupported CNI_COMMAND: %q", os.Getenv("CNI_COMMAND"))
+	}
+
+	if err != nil {
+		json.NewEncoder(os.Stdout).Encode(cniError{
+			CNIVersion: supportedVersions[len(supportedVersions)-1],
+			Code:       100,
+			Msg:        err.Error(),
+		})
+		os.Exit(1)
+	}
+}
+
+func cmdAdd() error {
+	conf, err := readNetConf()
+	if err != nil {
+		return err
+	}
+
+	args := parseArgs(os.Getenv("CNI_ARGS"))
+	namespace, name := args["K8S_POD_NAMESPACE"], args["K8S_POD_NAME"]
+	if namespace == "" || name == "" {
+		// not a Kubernetes pod
+		return writeResult(conf)
+	}
+
+	client, err := k8s.NewClientSet(conf.Kubernetes.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	pod, err := client.CoreV1().Pods(namespace).Get(name, metaV1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if !needsFirewall(pod) {
+		log.Printf("skipping pod %s/%s", namespace, name)
+		return writeResult(conf)
+	}
+
+	log.Printf("configuring the iptables of pod %s/%s", namespace, name)
+	err = iptables.ConfigureFirewall(iptables.FirewallConfiguration{
+		Mode:                  iptables.RedirectAllMode,
+		ProxyInboundPort:      conf.Linkerd.IncomingProxyPort,
+		ProxyOutgoingPort:     conf.Linkerd.OutgoingProxyPort,
+		ProxyUid:              conf.Linkerd.ProxyUID,
+		InboundPortsToIgnore:  conf.Linkerd.InboundPortsToIgnore,
+		OutboundPortsToIgnore: conf.Linkerd.OutboundPortsToIgnore,
+		SimulateOnly:          conf.Linkerd.Simulate,
+		NetNs:                 os.Getenv("CNI_NETNS"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeResult(conf)
+}
+
+func cmdPassThrough() error {
+	conf, err := readNetConf()
+	if err != nil {
+		return err
+	}
+	return writeResult(conf)
+}
+
+func readNetConf() (*netConf, error) {
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	conf := &netConf{}
+	if err := json.Unmarshal(b, conf); err != nil {
+		return nil, fmt.Errorf("failed to parse network configuration: %s", err)
+	}
+	return conf, nil
+}
+
+// writeResult passes the result of the previous plugin in the chain on to the
+// container runtime, since the plugin doesn't change the pod's interfaces.
+func writeResult(conf *netConf) error {
+	if len(conf.PrevResult) == 0 {
+		return json.NewEncoder(os.Stdout).Encode(map[string]string{"cniVersion": conf.CNIVersion})
+	}
+	_, err := os.Stdout.Write(conf.PrevResult)
+	return err
+}
+
+// parseArgs parses the semicolon-separated key=value pairs of CNI_ARGS.
+func parseArgs(cniArgs string) map[string]string {
+	args := map[string]string{}
+	for _, pair := range strings.Split(cniArgs, ";") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			args[kv[0]] = kv[1]
+		}
+	}
+	return args
+}
+
+// needsFirewall returns true for the pods that were injected with
+// `--linkerd-cni-enabled`, which have a proxy but no proxy-init container.
+func needsFirewall(pod *v1.Pod) bool {
+	if pod.Spec.HostNetwork {
+		return false
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == pkgK8s.InitContainerName {
+			return false
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == pkgK8s.ProxyContainerName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type mockDestinationClient struct {
+	profilesToReturn []*destination.DestinationProfile
+}
+
+func (c *mockDestinationClient) Get(ctx context.Context, in *destination.GetDestination, opts ...grpc.CallOption) (destination.Destination_GetClient, error) {
+	return nil, nil
+}
+
+func (c *mockDestinationClient) GetProfile(ctx context.Context, in *destination.GetDestination, opts ...grpc.CallOption) (destination.Destination_GetProfileClient, error) {
+	return &mockDestination_GetProfileClient{profilesToReturn: c.profilesToReturn}, nil
+}
+
+type mockDestination_GetProfileClient struct {
+	profilesToReturn []*destination.DestinationProfile
+	grpc.ClientStream
+}
+
+func (c *mockDestination_GetProfileClient) Recv() (*destination.DestinationProfile, error) {
+	if len(c.profilesToReturn) == 0 {
+		return nil, io.EOF
+	}
+	profile := c.profilesToReturn[0]
+	c.profilesToReturn = c.profilesToReturn[1:]
+	return profile, nil
+}
+
+type mockDestination_GetProfileServer struct {
+	profilesReceived []*destination.DestinationProfile
+}
+
+func (m *mockDestination_GetProfileServer) Send(profile *destination.DestinationProfile) error {
+	m.profilesReceived = append(m.profilesReceived, profile)
+	return nil
+}
+
+func (m *mockDestination_GetProfileServer) SetHeader(metadata.MD) error  { return nil }
+func (m *mockDestination_GetProfileServer) SendHeader(metadata.MD) error { return nil }
+func (m *mockDestination_GetProfileServer) SetTrailer(metadata.MD)       {}
+func (m *mockDestination_GetProfileServer) Context() context.Context     { return context.Background() }
+func (m *mockDestination_GetProfileServer) SendMsg(x interface{}) error  { return nil }
+func (m *mockDestination_GetProfileServer) RecvMsg(x interface{}) error  { return nil }
+
+func TestGetProfile(t *testing.T) {
+	t.Run("Forwards the profile updates of the destination service", func(t *testing.T) {
+		profiles := []*destination.DestinationProfile{
+			&destination.DestinationProfile{
+				Routes: []*destination.Route{
+					&destination.Route{MetricsLabels: map[string]string{"route": "GET /books"}},
+				},
+			},
+			&destination.DestinationProfile{},
+		}
+
+		srv := server{destinationClient: &mockDestinationClient{profilesToReturn: profiles}}
+		stream := &mockDestination_GetProfileServer{}
+
+		err := srv.GetProfile(&destination.GetDestination{Scheme: "k8s", Path: "books.booksapp.svc.cluster.local:7000"}, stream)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if !reflect.DeepEqual(stream.profilesReceived, profiles) {
+			t.Fatalf("Expected profiles %v, got %v", profiles, stream.profilesReceived)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package public
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	tapPb "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/controller/tap"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// extensionAPIServerAuthentication is the ConfigMap in which the
+	// Kubernetes API server publishes how the requests it proxies to extension
+	// API servers can be authenticated.
+	extensionAPIServerAuthentication = "extension-apiserver-authentication"
+
+	requestHeaderClientCAKey        = "requestheader-client-ca-file"
+	requestHeaderAllowedNamesKey    = "requestheader-allowed-names"
+	requestHeaderUsernameHeadersKey = "requestheader-username-headers"
+	requestHeaderGroupHeadersKey    = "requestheader-group-headers"
+)
+
+var tapAPIPrefix = fmt.Sprintf("/apis/%s/%s", pkgK8s.TapAPIGroup, pkgK8s.TapAPIVersion)
+
+// requestHeaderAuthenticator authenticates the requests proxied by the
+// Kubernetes API server, which presents a client certificate signed by its
+// request header CA and identifies the caller in request headers.
+type requestHeaderAuthenticator struct {
+	allowedNames    []string
+	usernameHeaders []string
+	groupHeaders    []string
+}
+
+type apiServerHandler struct {
+	authenticator *requestHeaderAuthenticator
+	grpcServer    *grpcServer
+}
+
+// NewAPIServer returns a TLS server for the tap API, which is registered with
+// the Kubernetes API server as an APIService so that tap requests are
+// authenticated by the Kubernetes API server, and authorized against RBAC
+// rules by the tap server. The server's certificate is verified by the
+// Kubernetes API server with the CA bundle of the APIService, and the server
+// trusts the request header CA published in the kube-system namespace.
+func NewAPIServer(
+	addr string,
+	cert tls.Certificate,
+	tapClient tapPb.TapClient,
+	k8sAPI *k8s.API,
+	controllerNamespace string,
+) (*http.Server, error) {
+	tlsConfig, authenticator, err := extensionAPIServerTLS(k8sAPI, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := &apiServerHandler{
+		authenticator: authenticator,
+		grpcServer:    newGrpcServer(nil, tapClient, nil, k8sAPI, controllerNamespace, nil),
+	}
+
+	return &http.Server{
+		Addr:      addr,
+		Handler:   prometheus.WithTelemetry(handler),
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+// extensionAPIServerTLS returns the TLS config of an extension API server
+// serving cert, and the authenticator of the requests that the Kubernetes API
+// server proxies to it, as published in the kube-system namespace.
+func extensionAPIServerTLS(k8sAPI *k8s.API, cert tls.Certificate) (*tls.Config, *requestHeaderAuthenticator, error) {
+	cm, err := k8sAPI.Client.CoreV1().ConfigMaps("kube-system").Get(extensionAPIServerAuthentication, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM([]byte(cm.Data[requestHeaderClientCAKey])) {
+		return nil, nil, fmt.Errorf("no request header CA found in %s", extensionAPIServerAuthentication)
+	}
+
+	authenticator, err := newRequestHeaderAuthenticator(cm.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientCAs,
+	}, authenticator, nil
+}
+
+func newRequestHeaderAuthenticator(data map[string]string) (*requestHeaderAuthenticator, error) {
+	authenticator := &requestHeaderAuthenticator{
+		usernameHeaders: []string{"X-Remote-User"},
+		groupHeaders:    []string{"X-Remote-Group"},
+	}
+
+	for key, field := range map[string]*[]string{
+		requestHeaderAllowedNamesKey:    &authenticator.allowedNames,
+		requestHeaderUsernameHeadersKey: &authenticator.usernameHeaders,
+		requestHeaderGroupHeadersKey:    &authenticator.groupHeaders,
+	} {
+		if value := data[key]; value != "" {
+			if err := json.Unmarshal([]byte(value), field); err != nil {
+				return nil, fmt.Errorf("invalid %s in %s: %s", key, extensionAPIServerAuthentication, err)
+			}
+		}
+	}
+
+	return authenticator, nil
+}
+
+// authenticate returns the user and groups of the caller of a request proxied
+// by the Kubernetes API server.
+func (a *requestHeaderAuthenticator) authenticate(req *http.Request) (string, []string, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+		return "", nil, errors.New("requests must be proxied by the Kubernetes API server")
+	}
+
+	if len(a.allowedNames) > 0 {
+		name := req.TLS.VerifiedChains[0][0].Subject.CommonName
+		allowed := false
+		for _, allowedName := range a.allowedNames {
+			if name == allowedName {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", nil, fmt.Errorf("client certificate %s is not allowed to proxy requests", name)
+		}
+	}
+
+	user := ""
+	for _, header := range a.usernameHeaders {
+		if user = req.Header.Get(header); user != "" {
+			break
+		}
+	}
+	if user == "" {
+		return "", nil, errors.New("request does not identify the caller")
+	}
+
+	groups := []string{}
+	for _, header := range a.groupHeaders {
+		groups = append(groups, req.Header[http.CanonicalHeaderKey(header)]...)
+	}
+
+	return user, groups, nil
+}
+
+func (h *apiServerHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	log.WithFields(log.Fields{
+		"req.Method": req.Method, "req.URL": req.URL, "req.Form": req.Form,
+	}).Debugf("Serving %s %s", req.Method, req.URL.Path)
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	namespacesPrefix := tapAPIPrefix + "/watch/namespaces/"
+
+	switch {
+	case path == tapAPIPrefix:
+		h.handleDiscovery(w)
+	case strings.HasPrefix(path, namespacesPrefix) && strings.HasSuffix(path, "/tap"):
+		namespace := strings.TrimSuffix(strings.TrimPrefix(path, namespacesPrefix), "/tap")
+		h.handleTap(w, req, namespace)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// handleDiscovery describes the tap API to the Kubernetes API server.
+func (h *apiServerHandler) handleDiscovery(w http.ResponseWriter) {
+	list := metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{APIVersion: "v1", Kind: "APIResourceList"},
+		GroupVersion: fmt.Sprintf("%s/%s", pkgK8s.TapAPIGroup, pkgK8s.TapAPIVersion),
+		APIResources: []metav1.APIResource{
+			{Name: "tap", Namespaced: true, Kind: "Tap", Verbs: []string{"watch"}},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Errorf("Error writing discovery response: %v", err)
+	}
+}
+
+// handleTap streams the events of a TapByResource request, whose target must
+// be in the namespace named in the request's path, forwarding the identity of
+// the caller to the tap server.
+func (h *apiServerHandler) handleTap(w http.ResponseWriter, req *http.Request, namespace string) {
+	user, groups, err := h.authenticator.authenticate(req)
+	if err != nil {
+		writeErrorToHttpResponse(w, httpError{Code: http.StatusUnauthorized, WrappedError: err})
+		return
+	}
+
+	flushableWriter, err := newStreamingWriter(w)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	var protoRequest pb.TapByResourceRequest
+	err = httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	target := protoRequest.GetTarget().GetResource()
+	targetNamespace := target.GetNamespace()
+	if target.GetType() == pkgK8s.Namespace {
+		targetNamespace = target.GetName()
+	}
+	if targetNamespace != namespace {
+		writeErrorToHttpResponse(w, httpError{
+			Code:         http.StatusBadRequest,
+			WrappedError: fmt.Errorf("tap target must be in namespace %s", namespace),
+		})
+		return
+	}
+
+	md := metadata.Pairs(tap.UserMetadataKey, user)
+	for _, group := range groups {
+		md.Append(tap.GroupMetadataKey, group)
+	}
+	ctx := metadata.NewOutgoingContext(req.Context(), md)
+
+	server := tapServer{w: flushableWriter, req: req.WithContext(ctx)}
+	err = h.grpcServer.TapByResource(&protoRequest, server)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
@@ -0,0 +1,201 @@
+package public
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	tapPb "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/tap"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/client-go/rest"
+)
+
+type mockTapClient struct {
+	tapPb.TapClient
+	md metadata.MD
+}
+
+func (c *mockTapClient) TapByResource(ctx context.Context, req *pb.TapByResourceRequest, _ ...grpc.CallOption) (tapPb.Tap_TapByResourceClient, error) {
+	c.md, _ = metadata.FromOutgoingContext(ctx)
+	return nil, errors.New("tap server unavailable")
+}
+
+// proxiedRequest returns a request as proxied by the Kubernetes API server,
+// with a verified client certificate named clientName.
+func proxiedRequest(t *testing.T, path, clientName string, body proto.Message) *http.Request {
+	content, err := proto.Marshal(body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(content))
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: clientName}}}},
+	}
+	req.Header.Set("X-Remote-User", "alice")
+	req.Header.Add("X-Remote-Group", "developers")
+	req.Header.Add("X-Remote-Group", "system:authenticated")
+	return req
+}
+
+func TestRequestHeaderAuthenticator(t *testing.T) {
+	authenticator, err := newRequestHeaderAuthenticator(map[string]string{
+		requestHeaderAllowedNamesKey:    `["front-proxy-client"]`,
+		requestHeaderUsernameHeadersKey: `["X-Remote-User"]`,
+		requestHeaderGroupHeadersKey:    `["X-Remote-Group"]`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Returns the identity of the caller", func(t *testing.T) {
+		user, groups, err := authenticator.authenticate(proxiedRequest(t, "/", "front-proxy-client", &pb.Empty{}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if user != "alice" || !reflect.DeepEqual(groups, []string{"developers", "system:authenticated"}) {
+			t.Fatalf("Unexpected identity: %s %v", user, groups)
+		}
+	})
+
+	t.Run("Rejects client certificates that aren't allowed", func(t *testing.T) {
+		_, _, err := authenticator.authenticate(proxiedRequest(t, "/", "mallory", &pb.Empty{}))
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects requests without a verified client certificate", func(t *testing.T) {
+		req := proxiedRequest(t, "/", "front-proxy-client", &pb.Empty{})
+		req.TLS = nil
+		_, _, err := authenticator.authenticate(req)
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects invalid configurations", func(t *testing.T) {
+		_, err := newRequestHeaderAuthenticator(map[string]string{requestHeaderAllowedNamesKey: "front-proxy-client"})
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestAPIServerHandler(t *testing.T) {
+	tapClient := &mockTapClient{}
+	handler := &apiServerHandler{
+		authenticator: &requestHeaderAuthenticator{
+			usernameHeaders: []string{"X-Remote-User"},
+			groupHeaders:    []string{"X-Remote-Group"},
+		},
+		grpcServer: newGrpcServer(nil, tapClient, nil, nil, "linkerd", nil),
+	}
+
+	tapRequest := &pb.TapByResourceRequest{
+		Target: &pb.ResourceSelection{
+			Resource: &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+		},
+	}
+
+	t.Run("Serves discovery", func(t *testing.T) {
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, httptest.NewRequest(http.MethodGet, "/apis/tap.linkerd.io/v1alpha1", nil))
+
+		if rsp.Code != http.StatusOK || !strings.Contains(rsp.Body.String(), `"groupVersion":"tap.linkerd.io/v1alpha1"`) {
+			t.Fatalf("Unexpected discovery response: %d %s", rsp.Code, rsp.Body.String())
+		}
+	})
+
+	t.Run("Forwards the caller's identity to the tap server", func(t *testing.T) {
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, proxiedRequest(t, "/apis/tap.linkerd.io/v1alpha1/watch/namespaces/emojivoto/tap", "front-proxy-client", tapRequest))
+
+		if !reflect.DeepEqual(tapClient.md[tap.UserMetadataKey], []string{"alice"}) {
+			t.Fatalf("Expected user alice to be forwarded, got %v", tapClient.md)
+		}
+		if !reflect.DeepEqual(tapClient.md[tap.GroupMetadataKey], []string{"developers", "system:authenticated"}) {
+			t.Fatalf("Expected groups to be forwarded, got %v", tapClient.md)
+		}
+	})
+
+	t.Run("Rejects targets outside of the request's namespace", func(t *testing.T) {
+		tapClient.md = nil
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, proxiedRequest(t, "/apis/tap.linkerd.io/v1alpha1/watch/namespaces/linkerd/tap", "front-proxy-client", tapRequest))
+
+		if rsp.Header().Get(errorHeader) != http.StatusText(http.StatusBadRequest) {
+			t.Fatalf("Expected a bad request error, got %v", rsp.Header())
+		}
+		if tapClient.md != nil {
+			t.Fatal("Expected the request not to be forwarded to the tap server")
+		}
+	})
+
+	t.Run("Rejects unauthenticated requests", func(t *testing.T) {
+		req := proxiedRequest(t, "/apis/tap.linkerd.io/v1alpha1/watch/namespaces/emojivoto/tap", "front-proxy-client", tapRequest)
+		req.TLS = nil
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+
+		if rsp.Header().Get(errorHeader) != http.StatusText(http.StatusUnauthorized) {
+			t.Fatalf("Expected an unauthorized error, got %v", rsp.Header())
+		}
+	})
+}
+
+func TestNewTapAPIClient(t *testing.T) {
+	t.Run("Returns the client as is when the tap API isn't registered", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		client := &MockApiClient{}
+		tapAPIClient, err := NewTapAPIClient(client, &pkgK8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tapAPIClient != client {
+			t.Fatalf("Expected the client to be returned as is, got %+v", tapAPIClient)
+		}
+	})
+
+	t.Run("Taps through the tap API when it is registered", func(t *testing.T) {
+		var tapPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost {
+				tapPath = req.URL.Path
+				w.Header().Set(errorHeader, "tap server unavailable")
+			}
+		}))
+		defer server.Close()
+
+		client, err := NewTapAPIClient(&MockApiClient{}, &pkgK8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		client.TapByResource(context.Background(), &pb.TapByResourceRequest{
+			Target: &pb.ResourceSelection{
+				Resource: &pb.Resource{Type: pkgK8s.Namespace, Name: "emojivoto"},
+			},
+		})
+
+		expectedPath := "/apis/tap.linkerd.io/v1alpha1/watch/namespaces/emojivoto/tap"
+		if tapPath != expectedPath {
+			t.Fatalf("Expected tap request to %s, got %s", expectedPath, tapPath)
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package public
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/tap"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	authnV1 "k8s.io/api/authentication/v1"
+	authzV1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	// preservedAuthorizationHeader carries the Authorization header of the
+	// callers through the service proxy of the Kubernetes API server, which
+	// authenticates the callers with their Authorization header and strips it
+	// from the requests it proxies
+	preservedAuthorizationHeader = "Linkerd-Authorization"
+
+	// authorizationMetadataKey is the key of the outgoing gRPC metadata whose
+	// value is sent as the preserved Authorization header of the requests of
+	// the client
+	authorizationMetadataKey = "authorization"
+)
+
+// WithAuthorization returns a context whose API requests bear the given
+// Authorization header, such as the one of a dashboard user.
+func WithAuthorization(ctx context.Context, authorization string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = metadata.Join(md, metadata.Pairs(authorizationMetadataKey, authorization))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// preserveAuthorization copies the Authorization header that the Kubernetes
+// client sets on the requests to the preserved Authorization header, so that
+// the callers reaching the API through the Kubernetes API server are
+// authenticated with the same credentials. The header set by WithAuthorization
+// takes precedence.
+type preserveAuthorization struct {
+	rt http.RoundTripper
+}
+
+func (p preserveAuthorization) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization := req.Header.Get(authorizationHeader)
+	if authorization == "" || req.Header.Get(preservedAuthorizationHeader) != "" {
+		return p.rt.RoundTrip(req)
+	}
+
+	req2 := new(http.Request)
+	*req2 = *req
+	req2.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		req2.Header[k] = v
+	}
+	req2.Header.Set(preservedAuthorizationHeader, authorization)
+	return p.rt.RoundTrip(req2)
+}
+
+// caller is the identity of the caller of a request, as authenticated by the
+// Kubernetes API server.
+type caller struct {
+	user   string
+	groups []string
+}
+
+type callerKey struct{}
+
+func withCaller(ctx context.Context, user string, groups []string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{user: user, groups: groups})
+}
+
+// tokenReviewAuthenticator authenticates the requests bearing a Kubernetes
+// token, such as a ServiceAccount token or an OIDC ID token, with the
+// TokenReview API.
+type tokenReviewAuthenticator struct {
+	client kubernetes.Interface
+}
+
+// authenticate returns the user and groups of the bearer of the request's
+// token, from its Authorization header, or else from the header preserving it
+// through the Kubernetes API server.
+func (a *tokenReviewAuthenticator) authenticate(req *http.Request) (string, []string, error) {
+	header := req.Header.Get(authorizationHeader)
+	if header == "" {
+		header = req.Header.Get(preservedAuthorizationHeader)
+	}
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", nil, errors.New("requests must bear a Kubernetes token")
+	}
+
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(&authnV1.TokenReview{
+		Spec: authnV1.TokenReviewSpec{Token: strings.TrimPrefix(header, bearerPrefix)},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if review.Status.Error != "" {
+		return "", nil, errors.New(review.Status.Error)
+	}
+	if !review.Status.Authenticated {
+		return "", nil, errors.New("the token isn't authenticated")
+	}
+
+	return review.Status.User.Username, review.Status.User.Groups, nil
+}
+
+// rbacServer serves the requests of the callers allowed to list the pods of
+// the namespaces they query, by submitting a SubjectAccessReview for each
+// request. Requests that aren't limited to a namespace require the permission
+// in every namespace. Tap requests require the permission to watch the tap
+// resource of the tap.linkerd.io API group instead, and the identity of their
+// caller is forwarded to the tap server, which authorizes them as well.
+type rbacServer struct {
+	server pb.ApiServer
+	client kubernetes.Interface
+}
+
+func newRBACServer(server pb.ApiServer, client kubernetes.Interface) *rbacServer {
+	return &rbacServer{server: server, client: client}
+}
+
+// authorize checks that the caller is allowed to list the pods of each of the
+// namespaces.
+func (s *rbacServer) authorize(ctx context.Context, namespaces ...string) error {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "the caller isn't authenticated")
+	}
+
+	for _, namespace := range namespaces {
+		err := s.review(c, "query", authzV1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "list",
+			Resource:  "pods",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// authorizeTap checks that the caller is allowed to tap the namespace.
+func (s *rbacServer) authorizeTap(ctx context.Context, namespace string) error {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "the caller isn't authenticated")
+	}
+
+	return s.review(c, "tap", authzV1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "watch",
+		Group:     pkgK8s.TapAPIGroup,
+		Version:   pkgK8s.TapAPIVersion,
+		Resource:  "tap",
+	})
+}
+
+// review submits a SubjectAccessReview of the caller's access to a resource,
+// and returns an error naming the action if it isn't allowed.
+func (s *rbacServer) review(c caller, action string, attributes authzV1.ResourceAttributes) error {
+	review := &authzV1.SubjectAccessReview{
+		Spec: authzV1.SubjectAccessReviewSpec{
+			User:               c.user,
+			Groups:             c.groups,
+			ResourceAttributes: &attributes,
+		},
+	}
+
+	rsp, err := s.client.AuthorizationV1().SubjectAccessReviews().Create(review)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to authorize request: %s", err)
+	}
+
+	if !rsp.Status.Allowed {
+		if attributes.Namespace == "" {
+			return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s all namespaces", c.user, action)
+		}
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s namespace %s", c.user, action, attributes.Namespace)
+	}
+
+	return nil
+}
+
+// resourceNamespace returns the namespace of a resource, or the namespace
+// itself for namespace resources.
+func resourceNamespace(resource *pb.Resource) string {
+	if resource.GetType() == pkgK8s.Namespace {
+		return resource.GetName()
+	}
+	return resource.GetNamespace()
+}
+
+// statSummaryNamespaces returns the namespaces of the resources of a
+// StatSummary request.
+func statSummaryNamespaces(req *pb.StatSummaryRequest) []string {
+	namespaces := []string{resourceNamespace(req.GetSelector().GetResource())}
+	if to := req.GetToResource(); to != nil {
+		namespaces = append(namespaces, resourceNamespace(to))
+	}
+	if from := req.GetFromResource(); from != nil {
+		namespaces = append(namespaces, resourceNamespace(from))
+	}
+	return namespaces
+}
+
+func (s *rbacServer) StatSummary(ctx context.Context, req *pb.StatSummaryRequest) (*pb.StatSummaryResponse, error) {
+	if err := s.authorize(ctx, statSummaryNamespaces(req)...); err != nil {
+		return nil, err
+	}
+	return s.server.StatSummary(ctx, req)
+}
+
+func (s *rbacServer) StatSummaryStream(req *pb.StatSummaryStreamRequest, stream pb.Api_StatSummaryStreamServer) error {
+	if err := s.authorize(stream.Context(), statSummaryNamespaces(req.GetRequest())...); err != nil {
+		return err
+	}
+	return s.server.StatSummaryStream(req, stream)
+}
+
+func (s *rbacServer) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest) (*pb.TopRoutesResponse, error) {
+	if err := s.authorize(ctx, resourceNamespace(req.GetSelector().GetResource())); err != nil {
+		return nil, err
+	}
+	return s.server.TopRoutes(ctx, req)
+}
+
+func (s *rbacServer) Edges(ctx context.Context, req *pb.EdgesRequest) (*pb.EdgesResponse, error) {
+	if err := s.authorize(ctx, resourceNamespace(req.GetSelector().GetResource())); err != nil {
+		return nil, err
+	}
+	return s.server.Edges(ctx, req)
+}
+
+func (s *rbacServer) ListPods(ctx context.Context, req *pb.ListPodsRequest) (*pb.ListPodsResponse, error) {
+	if err := s.authorize(ctx, req.GetNamespace()); err != nil {
+		return nil, err
+	}
+	return s.server.ListPods(ctx, req)
+}
+
+func (s *rbacServer) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	if err := s.authorize(ctx, req.GetNamespace()); err != nil {
+		return nil, err
+	}
+	return s.server.ListServices(ctx, req)
+}
+
+// ResolveDestination may resolve authorities to pods of any namespace.
+func (s *rbacServer) ResolveDestination(ctx context.Context, req *pb.ResolveDestinationRequest) (*pb.ResolveDestinationResponse, error) {
+	if err := s.authorize(ctx, ""); err != nil {
+		return nil, err
+	}
+	return s.server.ResolveDestination(ctx, req)
+}
+
+// Version and SelfCheck don't reveal anything about the namespaces, and only
+// require the caller to be authenticated.
+func (s *rbacServer) Version(ctx context.Context, req *pb.Empty) (*pb.VersionInfo, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return s.server.Version(ctx, req)
+}
+
+func (s *rbacServer) SelfCheck(ctx context.Context, req *healthcheckPb.SelfCheckRequest) (*healthcheckPb.SelfCheckResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return s.server.SelfCheck(ctx, req)
+}
+
+// Tap targets aren't limited to a namespace, and require the permission to
+// tap every namespace.
+func (s *rbacServer) Tap(req *pb.TapRequest, stream pb.Api_TapServer) error {
+	if err := s.authorizeTap(stream.Context(), ""); err != nil {
+		return err
+	}
+	return s.server.Tap(req, stream)
+}
+
+func (s *rbacServer) TapByResource(req *pb.TapByResourceRequest, stream pb.Api_TapByResourceServer) error {
+	tapStream, ok := stream.(tapServer)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected tap stream %T", stream)
+	}
+	ctx := tapStream.Context()
+
+	if err := s.authorizeTap(ctx, resourceNamespace(req.GetTarget().GetResource())); err != nil {
+		return err
+	}
+	c := ctx.Value(callerKey{}).(caller)
+
+	md := metadata.Pairs(tap.UserMetadataKey, c.user)
+	for _, group := range c.groups {
+		md.Append(tap.GroupMetadataKey, group)
+	}
+	tapStream.req = tapStream.req.WithContext(metadata.NewOutgoingContext(ctx, md))
+
+	return s.server.TapByResource(req, tapStream)
+}
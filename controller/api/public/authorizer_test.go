@@ -0,0 +1,267 @@
+package public
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	authnV1 "k8s.io/api/authentication/v1"
+	authzV1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authnV1.TokenReview).DeepCopy()
+		if review.Spec.Token == "alice-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authnV1.UserInfo{Username: "alice", Groups: []string{"developers"}}
+		}
+		return true, review, nil
+	})
+	authenticator := &tokenReviewAuthenticator{client: client}
+
+	t.Run("Authenticates the bearers of valid tokens", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/StatSummary", nil)
+		req.Header.Set(authorizationHeader, "Bearer alice-token")
+
+		user, groups, err := authenticator.authenticate(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user != "alice" || len(groups) != 1 || groups[0] != "developers" {
+			t.Fatalf("Unexpected identity: %s %v", user, groups)
+		}
+	})
+
+	t.Run("Authenticates the bearers of tokens preserved through the Kubernetes API server", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/StatSummary", nil)
+		req.Header.Set(preservedAuthorizationHeader, "Bearer alice-token")
+
+		user, _, err := authenticator.authenticate(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user != "alice" {
+			t.Fatalf("Expected alice, got %s", user)
+		}
+	})
+
+	t.Run("Rejects invalid tokens", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/StatSummary", nil)
+		req.Header.Set(authorizationHeader, "Bearer mallory-token")
+
+		if _, _, err := authenticator.authenticate(req); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+
+	t.Run("Rejects requests without a token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/StatSummary", nil)
+
+		if _, _, err := authenticator.authenticate(req); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
+
+func TestRBACServer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	var reviewed []*authzV1.SubjectAccessReview
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authzV1.SubjectAccessReview).DeepCopy()
+		reviewed = append(reviewed, review)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "emojivoto"
+		return true, review, nil
+	})
+
+	mockServer := &mockGrpcServer{ResponseToReturn: &pb.StatSummaryResponse{}}
+	s := newRBACServer(mockServer, client)
+	ctx := withCaller(context.Background(), "alice", []string{"developers"})
+
+	t.Run("Serves callers allowed to list the pods of the queried namespace", func(t *testing.T) {
+		reviewed = nil
+		req := &pb.StatSummaryRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment},
+			},
+		}
+
+		if _, err := s.StatSummary(ctx, req); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if mockServer.LastRequestReceived != req {
+			t.Fatalf("Expected the request to be served, got: %+v", mockServer.LastRequestReceived)
+		}
+
+		if len(reviewed) != 1 {
+			t.Fatalf("Expected 1 review, got: %d", len(reviewed))
+		}
+		spec := reviewed[0].Spec
+		if spec.User != "alice" || len(spec.Groups) != 1 || spec.ResourceAttributes.Verb != "list" || spec.ResourceAttributes.Resource != "pods" {
+			t.Fatalf("Unexpected review: %+v", spec)
+		}
+	})
+
+	t.Run("Denies callers not allowed to query one of the namespaces", func(t *testing.T) {
+		req := &pb.StatSummaryRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment},
+			},
+			Outbound: &pb.StatSummaryRequest_ToResource{
+				ToResource: &pb.Resource{Type: pkgK8s.Namespace, Name: "linkerd"},
+			},
+		}
+
+		_, err := s.StatSummary(ctx, req)
+		expected := "rpc error: code = PermissionDenied desc = alice is not allowed to query namespace linkerd"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Denies callers not allowed to query all namespaces", func(t *testing.T) {
+		req := &pb.StatSummaryRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Type: pkgK8s.Deployment},
+			},
+		}
+
+		_, err := s.StatSummary(ctx, req)
+		expected := "rpc error: code = PermissionDenied desc = alice is not allowed to query all namespaces"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Denies unauthenticated callers", func(t *testing.T) {
+		_, err := s.StatSummary(context.Background(), &pb.StatSummaryRequest{})
+		expected := "rpc error: code = Unauthenticated desc = the caller isn't authenticated"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestRBACServerTap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authzV1.SubjectAccessReview).DeepCopy()
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes.Namespace == "emojivoto" &&
+			attributes.Verb == "watch" && attributes.Group == pkgK8s.TapAPIGroup && attributes.Resource == "tap"
+		return true, review, nil
+	})
+
+	newTapServer := func() tapServer {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/TapByResource", nil)
+		req = req.WithContext(withCaller(req.Context(), "alice", []string{"developers"}))
+		w, _ := newStreamingWriter(httptest.NewRecorder())
+		return tapServer{w: w, req: req}
+	}
+
+	t.Run("Forwards the identity of callers allowed to tap the target's namespace", func(t *testing.T) {
+		mockServer := &mockGrpcServer{}
+		s := newRBACServer(mockServer, client)
+		req := &pb.TapByResourceRequest{
+			Target: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment},
+			},
+		}
+
+		if err := s.TapByResource(req, newTapServer()); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if mockServer.LastRequestReceived != req {
+			t.Fatalf("Expected the request to be served, got: %+v", mockServer.LastRequestReceived)
+		}
+	})
+
+	t.Run("Denies callers not allowed to tap the target's namespace", func(t *testing.T) {
+		s := newRBACServer(&mockGrpcServer{}, client)
+		req := &pb.TapByResourceRequest{
+			Target: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "linkerd", Type: pkgK8s.Deployment},
+			},
+		}
+
+		err := s.TapByResource(req, newTapServer())
+		expected := "rpc error: code = PermissionDenied desc = alice is not allowed to tap namespace linkerd"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Denies Tap requests of callers not allowed to tap all namespaces", func(t *testing.T) {
+		s := newRBACServer(&mockGrpcServer{}, client)
+
+		err := s.Tap(&pb.TapRequest{}, newTapServer())
+		expected := "rpc error: code = PermissionDenied desc = alice is not allowed to tap all namespaces"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Rejects unexpected streams", func(t *testing.T) {
+		s := newRBACServer(&mockGrpcServer{}, client)
+		req := &pb.TapByResourceRequest{
+			Target: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment},
+			},
+		}
+
+		if err := s.TapByResource(req, &otherTapServer{tapServer: newTapServer()}); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
+
+// otherTapServer is a tap stream of another type than the one served by the
+// handler.
+type otherTapServer struct {
+	tapServer
+}
+
+func TestPreserveAuthorization(t *testing.T) {
+	var received *http.Request
+	rt := preserveAuthorization{rt: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		received = req
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})}
+
+	t.Run("Preserves the Authorization header", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "https://kubernetes/api/v1/namespaces/linkerd/services/http:api:http/proxy/api/v1/Version", nil)
+		req.Header.Set(authorizationHeader, "Bearer alice-token")
+
+		rt.RoundTrip(req)
+		if header := received.Header.Get(preservedAuthorizationHeader); header != "Bearer alice-token" {
+			t.Fatalf("Expected the preserved header to be [Bearer alice-token], got [%s]", header)
+		}
+		if header := req.Header.Get(preservedAuthorizationHeader); header != "" {
+			t.Fatalf("Expected the original request to be unchanged, got [%s]", header)
+		}
+	})
+
+	t.Run("Keeps the preserved header set by the caller", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "https://kubernetes/api/v1/namespaces/linkerd/services/http:api:http/proxy/api/v1/Version", nil)
+		req.Header.Set(authorizationHeader, "Bearer alice-token")
+		req.Header.Set(preservedAuthorizationHeader, "Bearer bob-token")
+
+		rt.RoundTrip(req)
+		if header := received.Header.Get(preservedAuthorizationHeader); header != "Bearer bob-token" {
+			t.Fatalf("Expected the preserved header to be [Bearer bob-token], got [%s]", header)
+		}
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
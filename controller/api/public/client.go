@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
@@ -17,6 +18,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -50,6 +52,30 @@ func (c *grpcOverHttpClient) SelfCheck(ctx context.Context, req *healthcheckPb.S
 	return &msg, err
 }
 
+func (c *grpcOverHttpClient) ResolveDestination(ctx context.Context, req *pb.ResolveDestinationRequest, _ ...grpc.CallOption) (*pb.ResolveDestinationResponse, error) {
+	var msg pb.ResolveDestinationResponse
+	err := c.apiRequest(ctx, "ResolveDestination", req, &msg)
+	return &msg, err
+}
+
+func (c *grpcOverHttpClient) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest, _ ...grpc.CallOption) (*pb.TopRoutesResponse, error) {
+	var msg pb.TopRoutesResponse
+	err := c.apiRequest(ctx, "TopRoutes", req, &msg)
+	return &msg, err
+}
+
+func (c *grpcOverHttpClient) ListServices(ctx context.Context, req *pb.ListServicesRequest, _ ...grpc.CallOption) (*pb.ListServicesResponse, error) {
+	var msg pb.ListServicesResponse
+	err := c.apiRequest(ctx, "ListServices", req, &msg)
+	return &msg, err
+}
+
+func (c *grpcOverHttpClient) Edges(ctx context.Context, req *pb.EdgesRequest, _ ...grpc.CallOption) (*pb.EdgesResponse, error) {
+	var msg pb.EdgesResponse
+	err := c.apiRequest(ctx, "Edges", req, &msg)
+	return &msg, err
+}
+
 func (c *grpcOverHttpClient) ListPods(ctx context.Context, req *pb.ListPodsRequest, _ ...grpc.CallOption) (*pb.ListPodsResponse, error) {
 	var msg pb.ListPodsResponse
 	err := c.apiRequest(ctx, "ListPods", req, &msg)
@@ -61,7 +87,31 @@ func (c *grpcOverHttpClient) Tap(ctx context.Context, req *pb.TapRequest, _ ...g
 }
 
 func (c *grpcOverHttpClient) TapByResource(ctx context.Context, req *pb.TapByResourceRequest, _ ...grpc.CallOption) (pb.Api_TapByResourceClient, error) {
-	url := c.endpointNameToPublicApiUrl("TapByResource")
+	return c.tap(ctx, c.endpointNameToPublicApiUrl("TapByResource"), req)
+}
+
+func (c *grpcOverHttpClient) StatSummaryStream(ctx context.Context, req *pb.StatSummaryStreamRequest, _ ...grpc.CallOption) (pb.Api_StatSummaryStreamClient, error) {
+	reader, err := c.streamRequest(ctx, c.endpointNameToPublicApiUrl("StatSummaryStream"), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statSummaryStreamClient{tapClient{ctx: ctx, reader: reader}}, nil
+}
+
+func (c *grpcOverHttpClient) tap(ctx context.Context, url *url.URL, req *pb.TapByResourceRequest) (pb.Api_TapByResourceClient, error) {
+	reader, err := c.streamRequest(ctx, url, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tapClient{ctx: ctx, reader: reader}, nil
+}
+
+// streamRequest makes a request to a streaming endpoint, returning a reader of
+// the stream of messages of the response, which is closed when the context
+// is done.
+func (c *grpcOverHttpClient) streamRequest(ctx context.Context, url *url.URL, req proto.Message) (*bufio.Reader, error) {
 	httpRsp, err := c.post(ctx, url, req)
 	if err != nil {
 		return nil, err
@@ -78,19 +128,26 @@ func (c *grpcOverHttpClient) TapByResource(ctx context.Context, req *pb.TapByRes
 		httpRsp.Body.Close()
 	}()
 
-	return &tapClient{ctx: ctx, reader: bufio.NewReader(httpRsp.Body)}, nil
+	return bufio.NewReader(httpRsp.Body), nil
 }
 
 func (c *grpcOverHttpClient) apiRequest(ctx context.Context, endpoint string, req proto.Message, protoResponse proto.Message) error {
 	url := c.endpointNameToPublicApiUrl(endpoint)
 
-	log.Debugf("Making gRPC-over-HTTP call to [%s] [%+v]", url.String(), req)
+	logger := log.WithFields(log.Fields{"rpc": endpoint, "url": url.String()})
+	logger.Debugf("Making gRPC-over-HTTP call [%+v]", req)
+	start := time.Now()
 	httpRsp, err := c.post(ctx, url, req)
 	if err != nil {
+		logger.WithFields(log.Fields{"duration": time.Since(start), "error": err}).Debug("gRPC-over-HTTP call failed")
 		return err
 	}
 	defer httpRsp.Body.Close()
-	log.Debugf("gRPC-over-HTTP call returned status [%s] and content length [%d]", httpRsp.Status, httpRsp.ContentLength)
+	logger.WithFields(log.Fields{
+		"duration":       time.Since(start),
+		"status":         httpRsp.StatusCode,
+		"content-length": httpRsp.ContentLength,
+	}).Debug("gRPC-over-HTTP call returned")
 
 	if err := checkIfResponseHasError(httpRsp); err != nil {
 		return err
@@ -114,6 +171,11 @@ func (c *grpcOverHttpClient) post(ctx context.Context, url *url.URL, req proto.M
 	if err != nil {
 		return nil, err
 	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for _, authorization := range md[authorizationMetadataKey] {
+			httpReq.Header.Set(preservedAuthorizationHeader, authorization)
+		}
+	}
 
 	rsp, err := c.httpClient.Do(httpReq.WithContext(ctx))
 	if err != nil {
@@ -129,6 +191,28 @@ func (c *grpcOverHttpClient) endpointNameToPublicApiUrl(endpoint string) *url.UR
 	return c.serverURL.ResolveReference(&url.URL{Path: endpoint})
 }
 
+// tapAPIClient makes TapByResource requests through the tap API registered
+// with the Kubernetes API server, and every other request through the wrapped
+// client.
+type tapAPIClient struct {
+	pb.ApiClient
+	tapAPI *grpcOverHttpClient
+}
+
+func (c *tapAPIClient) TapByResource(ctx context.Context, req *pb.TapByResourceRequest, _ ...grpc.CallOption) (pb.Api_TapByResourceClient, error) {
+	resource := req.GetTarget().GetResource()
+	namespace := resource.GetNamespace()
+	if resource.GetType() == k8s.Namespace {
+		namespace = resource.GetName()
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("tap targets must be in a namespace when tap is served by the %s API", k8s.TapAPIGroup)
+	}
+
+	path := fmt.Sprintf("watch/namespaces/%s/tap", namespace)
+	return c.tapAPI.tap(ctx, c.tapAPI.serverURL.ResolveReference(&url.URL{Path: path}), req)
+}
+
 type tapClient struct {
 	ctx    context.Context
 	reader *bufio.Reader
@@ -148,6 +232,18 @@ func (c tapClient) Context() context.Context     { return c.ctx }
 func (c tapClient) SendMsg(interface{}) error    { return nil }
 func (c tapClient) RecvMsg(interface{}) error    { return nil }
 
+// statSummaryStreamClient reads StatSummary responses the way tapClient reads
+// tap events.
+type statSummaryStreamClient struct {
+	tapClient
+}
+
+func (c statSummaryStreamClient) Recv() (*pb.StatSummaryResponse, error) {
+	var msg pb.StatSummaryResponse
+	err := fromByteStreamToProtocolBuffers(c.reader, &msg)
+	return &msg, err
+}
+
 func fromByteStreamToProtocolBuffers(byteStreamContainingMessage *bufio.Reader, out proto.Message) error {
 	messageAsBytes, err := deserializePayloadFromReader(byteStreamContainingMessage)
 	if err != nil {
@@ -187,16 +283,70 @@ func NewInternalClient(controlPlaneNamespace string, kubeAPIHost string) (pb.Api
 	return newClient(apiURL, http.DefaultClient, controlPlaneNamespace)
 }
 
+// NewExternalClient returns a client of the public API reached through the
+// service proxy of the Kubernetes API server. The bearer token of the
+// kubeconfig, if any, is sent to the public API as well, which authenticates
+// its callers with it when it enforces RBAC.
 func NewExternalClient(controlPlaneNamespace string, kubeAPI *k8s.KubernetesAPI) (pb.ApiClient, error) {
 	apiURL, err := kubeAPI.UrlFor(controlPlaneNamespace, "/services/http:api:http/proxy/")
 	if err != nil {
 		return nil, err
 	}
 
+	// the transports wrapped by the config are wrapped in turn by the ones
+	// setting the credentials, so the requests they see bear them
+	config := rest.CopyConfig(kubeAPI.Config)
+	wrap := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return preserveAuthorization{rt: rt}
+	}
+
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("error instantiating Kubernetes API client: %v", err)
+	}
+
+	return newClient(apiURL, &http.Client{Transport: transport}, controlPlaneNamespace)
+}
+
+// NewTapAPIClient wraps client so that its TapByResource requests are made
+// through the tap API, if it is registered with the Kubernetes API server. In
+// that case, the Kubernetes API server authenticates the caller, and the tap
+// server authorizes the caller's requests against RBAC rules. Otherwise,
+// client is returned as is.
+func NewTapAPIClient(client pb.ApiClient, kubeAPI *k8s.KubernetesAPI) (pb.ApiClient, error) {
+	serverURL, err := url.Parse(fmt.Sprintf("%s/apis/%s/%s/", kubeAPI.Host, k8s.TapAPIGroup, k8s.TapAPIVersion))
+	if err != nil {
+		return nil, err
+	}
+
 	httpClientToUse, err := kubeAPI.NewClient()
 	if err != nil {
 		return nil, err
 	}
 
-	return newClient(apiURL, httpClientToUse, controlPlaneNamespace)
+	rsp, err := httpClientToUse.Get(serverURL.String())
+	if err != nil {
+		return nil, err
+	}
+	rsp.Body.Close()
+
+	switch {
+	case rsp.StatusCode == http.StatusNotFound:
+		log.Debugf("%s API is not registered, tapping through the public API", k8s.TapAPIGroup)
+		return client, nil
+	case rsp.StatusCode < 200 || rsp.StatusCode >= 300:
+		return nil, fmt.Errorf("%s API is unavailable: %s", k8s.TapAPIGroup, rsp.Status)
+	}
+
+	return &tapAPIClient{
+		ApiClient: client,
+		tapAPI: &grpcOverHttpClient{
+			serverURL:  serverURL,
+			httpClient: httpClientToUse,
+		},
+	}, nil
 }
@@ -0,0 +1,193 @@
+package public
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/model"
+)
+
+// edgesQuery counts the response series of the selected resources, grouped
+// by the given labels. Both directions are queried at once: the outbound
+// series hold the identities of the servers that the source proxies
+// validated, and the inbound ones the identities of the clients that the
+// destination proxies validated.
+const edgesQuery = "count(response_total%s) by (%s)"
+
+const (
+	directionLabel = model.LabelName("direction")
+	tlsLabel       = model.LabelName("tls")
+	clientIDLabel  = model.LabelName("client_id")
+	serverIDLabel  = model.LabelName("server_id")
+)
+
+// noIdentityMsgs explains, for each value of the tls label other than "true",
+// why requests weren't sent over mTLS.
+var noIdentityMsgs = map[string]string{
+	"no_identity": "a peer has no TLS identity",
+	"disabled":    "TLS is disabled",
+}
+
+const defaultNoIdentityMsg = "the requests weren't sent over mTLS"
+
+type resourceKey struct {
+	namespace, name string
+}
+
+type edgeKey struct {
+	src, dst resourceKey
+}
+
+func (s *grpcServer) Edges(ctx context.Context, req *pb.EdgesRequest) (*pb.EdgesResponse, error) {
+	resource := req.GetSelector().GetResource()
+	if resource == nil {
+		return edgesError(req, "Edges request missing Selector Resource"), nil
+	}
+	switch resource.Type {
+	case k8s.Deployment, k8s.Pod, k8s.ReplicationController:
+	default:
+		return edgesError(req, fmt.Sprintf("edges are not supported for resource type '%s'", resource.Type)), nil
+	}
+
+	groupBy := append(model.LabelNames{directionLabel}, promGroupByLabelNames(resource)...)
+	groupBy = append(groupBy, promDstGroupByLabelNames(resource)...)
+	groupBy = append(groupBy, tlsLabel, clientIDLabel, serverIDLabel)
+
+	query := fmt.Sprintf(edgesQuery, promQueryLabels(resource), groupBy)
+	samples, err := s.queryProm(ctx, query)
+	if err != nil {
+		return nil, util.GRPCError(err)
+	}
+
+	return &pb.EdgesResponse{
+		Response: &pb.EdgesResponse_Ok_{
+			Ok: &pb.EdgesResponse_Ok{
+				Edges: buildEdges(resource.Type, samples),
+			},
+		},
+	}, nil
+}
+
+func edgesError(req *pb.EdgesRequest, message string) *pb.EdgesResponse {
+	return &pb.EdgesResponse{
+		Response: &pb.EdgesResponse_Error{
+			Error: &pb.ResourceError{
+				Resource: req.GetSelector().GetResource(),
+				Error:    message,
+			},
+		},
+	}
+}
+
+// buildEdges returns an edge per pair of source and destination resources of
+// the outbound samples, sorted by source and then destination. The identities
+// of the proxies are only set on the edges whose requests were sent over mTLS,
+// as reported by the proxies themselves: the server's is the one its client
+// validated, and the client's is the one it presented as a server to its own
+// clients, or else the one validated by the server if all its clients share
+// it.
+func buildEdges(resourceType string, samples model.Vector) []*pb.Edge {
+	resourceLabel := model.LabelName(resourceType)
+	tlsStatuses := make(map[edgeKey]map[string]struct{})
+	serverIDs := make(map[edgeKey]string)
+	// the identities of the resources, as validated by their clients
+	identities := make(map[resourceKey]string)
+	// the identities of the clients of the resources, as validated by them
+	clientIDs := make(map[resourceKey]map[string]struct{})
+
+	for _, sample := range samples {
+		src := resourceKey{
+			namespace: string(sample.Metric[namespaceLabel]),
+			name:      string(sample.Metric[resourceLabel]),
+		}
+
+		if sample.Metric[directionLabel] == "inbound" {
+			if clientID := string(sample.Metric[clientIDLabel]); src.name != "" && clientID != "" {
+				if clientIDs[src] == nil {
+					clientIDs[src] = make(map[string]struct{})
+				}
+				clientIDs[src][clientID] = struct{}{}
+			}
+			continue
+		}
+
+		key := edgeKey{
+			src: src,
+			dst: resourceKey{
+				namespace: string(sample.Metric[dstNamespaceLabel]),
+				name:      string(sample.Metric["dst_"+resourceLabel]),
+			},
+		}
+		// requests to destinations outside of Kubernetes, or sent by
+		// unlabelled sources, can't be attributed to a pair of resources
+		if key.src.name == "" || key.dst.name == "" {
+			continue
+		}
+
+		tls := string(sample.Metric[tlsLabel])
+		if tlsStatuses[key] == nil {
+			tlsStatuses[key] = make(map[string]struct{})
+		}
+		tlsStatuses[key][tls] = struct{}{}
+
+		if serverID := string(sample.Metric[serverIDLabel]); tls == "true" && serverID != "" {
+			serverIDs[key] = serverID
+			identities[key.dst] = serverID
+		}
+	}
+
+	clientIdentity := func(key edgeKey) string {
+		if id, ok := identities[key.src]; ok {
+			return id
+		}
+		if ids := clientIDs[key.dst]; len(ids) == 1 {
+			for id := range ids {
+				return id
+			}
+		}
+		return ""
+	}
+
+	edges := make([]*pb.Edge, 0)
+	for key, statuses := range tlsStatuses {
+		edge := &pb.Edge{
+			Src: &pb.Resource{Namespace: key.src.namespace, Type: resourceType, Name: key.src.name},
+			Dst: &pb.Resource{Namespace: key.dst.namespace, Type: resourceType, Name: key.dst.name},
+		}
+
+		if _, ok := statuses["true"]; ok {
+			edge.ClientId = clientIdentity(key)
+			edge.ServerId = serverIDs[key]
+		} else {
+			edge.NoIdentityMsg = defaultNoIdentityMsg
+			for status := range statuses {
+				if msg, ok := noIdentityMsgs[status]; ok {
+					edge.NoIdentityMsg = msg
+					break
+				}
+			}
+		}
+
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Src.Namespace != b.Src.Namespace {
+			return a.Src.Namespace < b.Src.Namespace
+		}
+		if a.Src.Name != b.Src.Name {
+			return a.Src.Name < b.Src.Name
+		}
+		if a.Dst.Namespace != b.Dst.Namespace {
+			return a.Dst.Namespace < b.Dst.Namespace
+		}
+		return a.Dst.Name < b.Dst.Name
+	})
+
+	return edges
+}
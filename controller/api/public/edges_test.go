@@ -0,0 +1,192 @@
+package public
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/model"
+)
+
+func genEdgeSample(srcNs, src, dstNs, dst, tls, serverID string) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			"direction":      "outbound",
+			"namespace":      model.LabelValue(srcNs),
+			"deployment":     model.LabelValue(src),
+			"dst_namespace":  model.LabelValue(dstNs),
+			"dst_deployment": model.LabelValue(dst),
+			"tls":            model.LabelValue(tls),
+			"server_id":      model.LabelValue(serverID),
+		},
+		Value:     1,
+		Timestamp: 456,
+	}
+}
+
+func genInboundEdgeSample(ns, name, clientID string) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			"direction":  "inbound",
+			"namespace":  model.LabelValue(ns),
+			"deployment": model.LabelValue(name),
+			"tls":        "true",
+			"client_id":  model.LabelValue(clientID),
+		},
+		Value:     1,
+		Timestamp: 456,
+	}
+}
+
+func TestEdges(t *testing.T) {
+	webID := "web.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+	emojiID := "emoji.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+	votingID := "voting.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+
+	edgesTests := []struct {
+		desc     string
+		samples  model.Vector
+		expected []*pb.Edge
+	}{
+		{
+			desc: "Returns the edges between resources with the identities validated by their proxies",
+			samples: model.Vector{
+				genEdgeSample("emojivoto", "web", "emojivoto", "emoji", "true", emojiID),
+				genEdgeSample("emojivoto", "web", "emojivoto", "emoji", "no_identity", ""),
+				genEdgeSample("emojivoto", "web", "emojivoto", "voting", "true", votingID),
+				genEdgeSample("emojivoto", "vote-bot", "emojivoto", "web", "disabled", ""),
+				genEdgeSample("emojivoto", "web", "", "", "no_identity", ""),
+				genInboundEdgeSample("emojivoto", "emoji", webID),
+			},
+			expected: []*pb.Edge{
+				&pb.Edge{
+					Src:           &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "vote-bot"},
+					Dst:           &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+					NoIdentityMsg: "TLS is disabled",
+				},
+				&pb.Edge{
+					Src:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+					Dst:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "emoji"},
+					ClientId: webID,
+					ServerId: emojiID,
+				},
+				&pb.Edge{
+					Src:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+					Dst:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "voting"},
+					ServerId: votingID,
+				},
+			},
+		},
+		{
+			desc: "Returns the identity of the client as validated by its own clients",
+			samples: model.Vector{
+				genEdgeSample("emojivoto", "vote-bot", "emojivoto", "web", "true", webID),
+				genEdgeSample("emojivoto", "web", "emojivoto", "emoji", "true", emojiID),
+				genInboundEdgeSample("emojivoto", "emoji", webID),
+				genInboundEdgeSample("emojivoto", "emoji", votingID),
+			},
+			expected: []*pb.Edge{
+				&pb.Edge{
+					Src:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "vote-bot"},
+					Dst:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+					ServerId: webID,
+				},
+				&pb.Edge{
+					Src:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"},
+					Dst:      &pb.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "emoji"},
+					ClientId: webID,
+					ServerId: emojiID,
+				},
+			},
+		},
+	}
+
+	for _, tt := range edgesTests {
+		tt := tt // pin
+		t.Run(tt.desc, func(t *testing.T) {
+			k8sAPI, err := k8s.NewFakeAPI()
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+
+			mockProm := &MockProm{Res: tt.samples}
+			fakeGrpcServer := newGrpcServer(
+				mockProm,
+				tap.NewTapClient(nil),
+				destination.NewDestinationClient(nil),
+				k8sAPI,
+				"linkerd",
+				[]string{},
+			)
+
+			rsp, err := fakeGrpcServer.Edges(context.TODO(), &pb.EdgesRequest{
+				Selector: &pb.ResourceSelection{
+					Resource: &pb.Resource{
+						Namespace: "emojivoto",
+						Type:      pkgK8s.Deployment,
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			expectedQueries := []string{
+				`count(response_total{namespace="emojivoto"}) by (direction, namespace, deployment, dst_namespace, dst_deployment, tls, client_id, server_id)`,
+			}
+			if !reflect.DeepEqual(expectedQueries, mockProm.QueriesExecuted) {
+				t.Fatalf("Prometheus queries incorrect. \nExpected:\n%+v \nGot:\n%+v", expectedQueries, mockProm.QueriesExecuted)
+			}
+
+			expected := &pb.EdgesResponse{
+				Response: &pb.EdgesResponse_Ok_{
+					Ok: &pb.EdgesResponse_Ok{Edges: tt.expected},
+				},
+			}
+			if !proto.Equal(expected, rsp) {
+				t.Fatalf("Expected: %+v\n Got: %+v", expected, rsp)
+			}
+		})
+	}
+
+	t.Run("Returns an error for unsupported resources", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI()
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{Res: model.Vector{}},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		invalidRequests := []pb.EdgesRequest{
+			pb.EdgesRequest{},
+			pb.EdgesRequest{
+				Selector: &pb.ResourceSelection{
+					Resource: &pb.Resource{Type: pkgK8s.Authority},
+				},
+			},
+		}
+
+		for _, req := range invalidRequests {
+			rsp, err := fakeGrpcServer.Edges(context.TODO(), &req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if rsp.GetError() == nil {
+				t.Fatalf("Expected a resource error for %+v, got: %+v", req, rsp)
+			}
+		}
+	})
+}
@@ -2,16 +2,22 @@ package public
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/golang/protobuf/ptypes/duration"
+	destinationPb "github.com/linkerd/linkerd2-proxy-api/go/destination"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
 	tapPb "github.com/linkerd/linkerd2/controller/gen/controller/tap"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	pkgprom "github.com/linkerd/linkerd2/pkg/prometheus"
 	"github.com/linkerd/linkerd2/pkg/version"
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	log "github.com/sirupsen/logrus"
@@ -25,9 +31,19 @@ type (
 	grpcServer struct {
 		prometheusAPI       promv1.API
 		tapClient           tapPb.TapClient
+		destinationClient   destinationPb.DestinationClient
 		k8sAPI              *k8s.API
 		controllerNamespace string
 		ignoredNamespaces   []string
+
+		// promQueryTimeout bounds the duration of each Prometheus query, and
+		// promMaxSamples the number of samples it may return, if non-zero
+		promQueryTimeout time.Duration
+		promMaxSamples   int
+
+		// recordedSeries is set when stat queries may use the series of the
+		// recording rules of the installed Prometheus
+		recordedSeries *recordedSeries
 	}
 )
 
@@ -42,11 +58,13 @@ const (
 	K8sClientCheckDescription  = "control plane can talk to Kubernetes"
 	PromClientSubsystemName    = "prometheus"
 	PromClientCheckDescription = "control plane can talk to Prometheus"
+	PromDataCheckDescription   = "Prometheus has metrics from the control plane"
 )
 
 func newGrpcServer(
 	promAPI promv1.API,
 	tapClient tapPb.TapClient,
+	destinationClient destinationPb.DestinationClient,
 	k8sAPI *k8s.API,
 	controllerNamespace string,
 	ignoredNamespaces []string,
@@ -54,14 +72,81 @@ func newGrpcServer(
 	return &grpcServer{
 		prometheusAPI:       promAPI,
 		tapClient:           tapClient,
+		destinationClient:   destinationClient,
 		k8sAPI:              k8sAPI,
 		controllerNamespace: controllerNamespace,
 		ignoredNamespaces:   ignoredNamespaces,
 	}
 }
 
-func (*grpcServer) Version(ctx context.Context, req *pb.Empty) (*pb.VersionInfo, error) {
-	return &pb.VersionInfo{GoVersion: runtime.Version(), ReleaseVersion: version.Version, BuildDate: "1970-01-01T00:00:00Z"}, nil
+func (s *grpcServer) Version(ctx context.Context, req *pb.Empty) (*pb.VersionInfo, error) {
+	components, err := s.componentVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.VersionInfo{
+		GoVersion:      runtime.Version(),
+		ReleaseVersion: version.Version,
+		BuildDate:      "1970-01-01T00:00:00Z",
+		Components:     components,
+	}, nil
+}
+
+// componentVersions returns the versions of the containers of the control
+// plane pods that run the images built by Linkerd, which are released with the
+// control plane, sorted by component, pod and container.
+func (s *grpcServer) componentVersions() ([]*pb.ComponentVersion, error) {
+	pods, err := s.k8sAPI.Pod().Lister().Pods(s.controllerNamespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]*pb.ComponentVersion, 0)
+	for _, pod := range pods {
+		component := pod.Labels[pkgK8s.ControllerComponentLabel]
+		if component == "" {
+			continue
+		}
+
+		for _, container := range pod.Spec.Containers {
+			if !pkgK8s.IsLinkerdImage(container.Image) {
+				continue
+			}
+			components = append(components, &pb.ComponentVersion{
+				Component: component,
+				Pod:       pod.Name,
+				Container: container.Name,
+				Version:   imageVersion(container.Image),
+			})
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Pod != b.Pod {
+			return a.Pod < b.Pod
+		}
+		return a.Container < b.Container
+	})
+
+	return components, nil
+}
+
+// imageVersion returns the version of a container image: its tag, which the
+// images pinned by the digests of an image lock file keep, or else its digest,
+// or "latest" if it has neither.
+func imageVersion(image string) string {
+	if tag := pkgK8s.ImageTag(image); tag != "" {
+		return tag
+	}
+	if digest := pkgK8s.ImageDigest(image); digest != "" {
+		return digest
+	}
+	return "latest"
 }
 
 func (s *grpcServer) ListPods(ctx context.Context, req *pb.ListPodsRequest) (*pb.ListPodsResponse, error) {
@@ -71,60 +156,42 @@ func (s *grpcServer) ListPods(ctx context.Context, req *pb.ListPodsRequest) (*pb
 	// report from that instance and its process start time
 	reports := make(map[string]podReport)
 
-	nsQuery := ""
-	if req.GetNamespace() != "" {
-		nsQuery = fmt.Sprintf("namespace=\"%s\"", req.GetNamespace())
-	}
-	processStartTimeQuery := fmt.Sprintf(podQuery, nsQuery)
-
-	// Query Prometheus for all pods present
-	vec, err := s.queryProm(ctx, processStartTimeQuery)
-	if err != nil {
-		return nil, err
-	}
-	for _, sample := range vec {
-		pod := string(sample.Metric["pod"])
-		timestamp := sample.Timestamp
+	if !req.GetExcludeStatus() {
+		nsQuery := ""
+		if req.GetNamespace() != "" {
+			nsQuery = fmt.Sprintf("namespace=\"%s\"", req.GetNamespace())
+		}
+		processStartTimeQuery := fmt.Sprintf(podQuery, nsQuery)
 
-		reports[pod] = podReport{
-			lastReport:              time.Unix(0, int64(timestamp)*int64(time.Millisecond)),
-			processStartTimeSeconds: time.Unix(0, int64(sample.Value)*int64(time.Second)),
+		// Query Prometheus for all pods present
+		vec, err := s.queryProm(ctx, processStartTimeQuery)
+		if err != nil {
+			return nil, err
 		}
-	}
+		for _, sample := range vec {
+			pod := string(sample.Metric["pod"])
+			timestamp := sample.Timestamp
 
-	var pods []*k8sV1.Pod
-	namespace := req.GetNamespace()
-	if namespace != "" {
-		pods, err = s.k8sAPI.Pod().Lister().Pods(namespace).List(labels.Everything())
-	} else {
-		pods, err = s.k8sAPI.Pod().Lister().List(labels.Everything())
+			reports[pod] = podReport{
+				lastReport:              time.Unix(0, int64(timestamp)*int64(time.Millisecond)),
+				processStartTimeSeconds: time.Unix(0, int64(sample.Value)*int64(time.Second)),
+			}
+		}
 	}
 
+	pods, continueToken, err := s.listPodsPage(req)
 	if err != nil {
 		return nil, err
 	}
 	podList := make([]*pb.Pod, 0)
 
 	for _, pod := range pods {
-		if s.shouldIgnore(pod) {
-			continue
-		}
-
-		updated, added := reports[pod.Name]
-
-		status := string(pod.Status.Phase)
-		if pod.DeletionTimestamp != nil {
-			status = "Terminating"
-		}
-
 		controllerComponent := pod.Labels[pkgK8s.ControllerComponentLabel]
 		controllerNS := pod.Labels[pkgK8s.ControllerNSLabel]
 
 		item := &pb.Pod{
 			Name:                pod.Namespace + "/" + pod.Name,
-			Status:              status,
 			PodIP:               pod.Status.PodIP,
-			Added:               added,
 			ControllerNamespace: controllerNS,
 			ControlPlane:        controllerComponent != "",
 		}
@@ -147,30 +214,153 @@ func (s *grpcServer) ListPods(ctx context.Context, req *pb.ListPodsRequest) (*pb
 			item.Owner = &pb.Pod_Job{Job: namespacedOwnerName}
 		}
 
-		if added {
-			since := time.Since(updated.lastReport)
-			item.SinceLastReport = &duration.Duration{
-				Seconds: int64(since / time.Second),
-				Nanos:   int32(since % time.Second),
+		if !req.GetExcludeStatus() {
+			item.Status = string(pod.Status.Phase)
+			if pod.DeletionTimestamp != nil {
+				item.Status = "Terminating"
 			}
-			sinceStarting := time.Since(updated.processStartTimeSeconds)
-			item.Uptime = &duration.Duration{
-				Seconds: int64(sinceStarting / time.Second),
-				Nanos:   int32(sinceStarting % time.Second),
+
+			updated, added := reports[pod.Name]
+			item.Added = added
+			if added {
+				since := time.Since(updated.lastReport)
+				item.SinceLastReport = &duration.Duration{
+					Seconds: int64(since / time.Second),
+					Nanos:   int32(since % time.Second),
+				}
+				sinceStarting := time.Since(updated.processStartTimeSeconds)
+				item.Uptime = &duration.Duration{
+					Seconds: int64(sinceStarting / time.Second),
+					Nanos:   int32(sinceStarting % time.Second),
+				}
 			}
 		}
 
 		podList = append(podList, item)
 	}
 
-	rsp := pb.ListPodsResponse{Pods: podList}
+	rsp := pb.ListPodsResponse{Pods: podList, ContinueToken: continueToken}
 
 	log.Debugf("ListPods response: %+v", rsp)
 
 	return &rsp, nil
 }
 
+// listPodsPage returns the pods matching the request, sorted by namespace and
+// name, starting after the request's continue token and up to its limit. It
+// also returns the continue token of the next page, if there is one.
+func (s *grpcServer) listPodsPage(req *pb.ListPodsRequest) ([]*k8sV1.Pod, string, error) {
+	after := ""
+	if req.GetContinueToken() != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(req.GetContinueToken())
+		if err != nil {
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid continue token: %s", req.GetContinueToken())
+		}
+		after = string(decoded)
+	}
+
+	var pods []*k8sV1.Pod
+	var err error
+	namespace := req.GetNamespace()
+	if namespace != "" {
+		pods, err = s.k8sAPI.Pod().Lister().Pods(namespace).List(labels.Everything())
+	} else {
+		pods, err = s.k8sAPI.Pod().Lister().List(labels.Everything())
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	owner := req.GetOwner()
+	matching := make([]*k8sV1.Pod, 0)
+	for _, pod := range pods {
+		if s.shouldIgnore(pod.Namespace) {
+			continue
+		}
+		if after != "" && podKey(pod) <= after {
+			continue
+		}
+		if owner != nil {
+			ownerKind, ownerName := s.k8sAPI.GetOwnerKindAndName(pod)
+			if ownerKind != owner.GetType() || ownerName != owner.GetName() ||
+				(owner.GetNamespace() != "" && pod.Namespace != owner.GetNamespace()) {
+				continue
+			}
+		}
+		matching = append(matching, pod)
+	}
+
+	sort.Slice(matching, func(i, j int) bool { return podKey(matching[i]) < podKey(matching[j]) })
+
+	limit := int(req.GetLimit())
+	if limit == 0 || len(matching) <= limit {
+		return matching, "", nil
+	}
+
+	matching = matching[:limit]
+	continueToken := base64.RawURLEncoding.EncodeToString([]byte(podKey(matching[limit-1])))
+	return matching, continueToken, nil
+}
+
+// podKey orders pods by namespace and then name, which can't contain "/".
+func podKey(pod *k8sV1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+func (s *grpcServer) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	log.Debugf("ListServices request: %+v", req)
+
+	var services []*k8sV1.Service
+	var err error
+	namespace := req.GetNamespace()
+	if namespace != "" {
+		services, err = s.k8sAPI.Svc().Lister().Services(namespace).List(labels.Everything())
+	} else {
+		services, err = s.k8sAPI.Svc().Lister().List(labels.Everything())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	serviceList := make([]*pb.Service, 0)
+
+	for _, svc := range services {
+		if s.shouldIgnore(svc.Namespace) {
+			continue
+		}
+
+		item := &pb.Service{
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+			Selector:  svc.Spec.Selector,
+		}
+
+		// services without a selector don't select any pods, their endpoints
+		// are managed separately
+		if len(svc.Spec.Selector) > 0 {
+			podStats, err := s.getPodStats(svc)
+			if err != nil {
+				return nil, err
+			}
+			item.MeshedPodCount = podStats.inMesh
+			item.RunningPodCount = podStats.total
+		}
+
+		serviceList = append(serviceList, item)
+	}
+
+	rsp := pb.ListServicesResponse{Services: serviceList}
+
+	log.Debugf("ListServices response: %+v", rsp)
+
+	return &rsp, nil
+}
+
 func (s *grpcServer) SelfCheck(ctx context.Context, in *healthcheckPb.SelfCheckRequest) (*healthcheckPb.SelfCheckResponse, error) {
+	// the checks of each subsystem are recorded in the metrics of the public
+	// API, so that alerts can be built on the health of the control plane
+	start := time.Now()
 	k8sClientCheck := &healthcheckPb.CheckResult{
 		SubsystemName:    K8sClientSubsystemName,
 		CheckDescription: K8sClientCheckDescription,
@@ -181,7 +371,9 @@ func (s *grpcServer) SelfCheck(ctx context.Context, in *healthcheckPb.SelfCheckR
 		k8sClientCheck.Status = healthcheckPb.CheckStatus_ERROR
 		k8sClientCheck.FriendlyMessageToUser = fmt.Sprintf("Error calling the Kubernetes API: %s", err)
 	}
+	pkgprom.ObserveHealthCheck(K8sClientSubsystemName, start, k8sClientCheck.Status == healthcheckPb.CheckStatus_OK)
 
+	start = time.Now()
 	promClientCheck := &healthcheckPb.CheckResult{
 		SubsystemName:    PromClientSubsystemName,
 		CheckDescription: PromClientCheckDescription,
@@ -193,15 +385,84 @@ func (s *grpcServer) SelfCheck(ctx context.Context, in *healthcheckPb.SelfCheckR
 		promClientCheck.FriendlyMessageToUser = fmt.Sprintf("Error calling Prometheus from the control plane: %s", err)
 	}
 
+	// Prometheus may be reachable without scraping the control plane, e.g. when
+	// Linkerd was installed with an existing Prometheus server that lacks the
+	// scrape configs printed by `linkerd install --prometheus-url`
+	promDataCheck := &healthcheckPb.CheckResult{
+		SubsystemName:    PromClientSubsystemName,
+		CheckDescription: PromDataCheckDescription,
+		Status:           healthcheckPb.CheckStatus_OK,
+	}
+	if promClientCheck.Status == healthcheckPb.CheckStatus_OK {
+		controlPlanePods, err := s.queryProm(ctx, fmt.Sprintf(podQuery, fmt.Sprintf("namespace=\"%s\"", s.controllerNamespace)))
+		if err != nil {
+			promDataCheck.Status = healthcheckPb.CheckStatus_ERROR
+			promDataCheck.FriendlyMessageToUser = fmt.Sprintf("Error calling Prometheus from the control plane: %s", err)
+		} else if len(controlPlanePods) == 0 {
+			promDataCheck.Status = healthcheckPb.CheckStatus_ERROR
+			promDataCheck.FriendlyMessageToUser = "Prometheus has no metrics from the control plane's proxies; check that it's configured with Linkerd's scrape configs"
+		}
+	} else {
+		promDataCheck.Status = healthcheckPb.CheckStatus_ERROR
+		promDataCheck.FriendlyMessageToUser = "Prometheus is unreachable"
+	}
+	pkgprom.ObserveHealthCheck(PromClientSubsystemName, start, promDataCheck.Status == healthcheckPb.CheckStatus_OK)
+
 	response := &healthcheckPb.SelfCheckResponse{
 		Results: []*healthcheckPb.CheckResult{
 			k8sClientCheck,
 			promClientCheck,
+			promDataCheck,
 		},
 	}
 	return response, nil
 }
 
+func (s *grpcServer) ResolveDestination(ctx context.Context, req *pb.ResolveDestinationRequest) (*pb.ResolveDestinationResponse, error) {
+	if req.GetAuthority() == "" {
+		return nil, status.Error(codes.InvalidArgument, "ResolveDestination request must specify an authority")
+	}
+
+	// the stream is only read until its first update, so cancel it afterwards
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.destinationClient.Get(ctx, &destinationPb.GetDestination{
+		Scheme: "k8s",
+		Path:   req.GetAuthority(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	switch u := update.GetUpdate().(type) {
+	case *destinationPb.Update_Add:
+		addresses := []string{}
+		endpoints := []*pb.DestinationEndpoint{}
+		for _, weightedAddr := range u.Add.GetAddrs() {
+			address := addr.ProxyAddressToString(weightedAddr.GetAddr())
+			addresses = append(addresses, address)
+			endpoints = append(endpoints, &pb.DestinationEndpoint{
+				Address:     address,
+				Pod:         weightedAddr.GetMetricLabels()["pod"],
+				Weight:      weightedAddr.GetWeight(),
+				TlsIdentity: weightedAddr.GetTlsIdentity().GetK8SPodIdentity().GetPodIdentity(),
+				H2:          weightedAddr.GetProtocolHint().GetH2() != nil,
+			})
+		}
+		return &pb.ResolveDestinationResponse{Exists: true, Addresses: addresses, Endpoints: endpoints}, nil
+	case *destinationPb.Update_NoEndpoints:
+		return &pb.ResolveDestinationResponse{Exists: u.NoEndpoints.GetExists()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected destination update for %s: %+v", req.GetAuthority(), update)
+	}
+}
+
 func (s *grpcServer) Tap(req *pb.TapRequest, stream pb.Api_TapServer) error {
 	return status.Error(codes.Unimplemented, "Tap is deprecated, use TapByResource")
 }
@@ -220,6 +481,10 @@ func (s *grpcServer) TapByResource(req *pb.TapByResourceRequest, stream pb.Api_T
 			return nil
 		default:
 			event, err := tapClient.Recv()
+			if err == io.EOF {
+				// the tap server terminated the tap, e.g. once its duration elapsed
+				return nil
+			}
 			if err != nil {
 				return err
 			}
@@ -228,9 +493,9 @@ func (s *grpcServer) TapByResource(req *pb.TapByResourceRequest, stream pb.Api_T
 	}
 }
 
-func (s *grpcServer) shouldIgnore(pod *k8sV1.Pod) bool {
-	for _, namespace := range s.ignoredNamespaces {
-		if pod.Namespace == namespace {
+func (s *grpcServer) shouldIgnore(namespace string) bool {
+	for _, ignored := range s.ignoredNamespaces {
+		if namespace == ignored {
 			return true
 		}
 	}
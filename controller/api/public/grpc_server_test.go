@@ -2,23 +2,59 @@ package public
 
 import (
 	"context"
+	"io"
+	"reflect"
 	"sort"
 	"testing"
 
 	"github.com/golang/protobuf/ptypes/duration"
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	"github.com/linkerd/linkerd2-proxy-api/go/net"
+	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
 	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
 	"github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type listPodsExpected struct {
 	err     error
 	k8sRes  []string
 	promRes model.Value
+	req     pb.ListPodsRequest
 	res     pb.ListPodsResponse
 }
 
+type mockDestinationClient struct {
+	updatesToReturn []*destination.Update
+}
+
+func (c *mockDestinationClient) Get(ctx context.Context, in *destination.GetDestination, opts ...grpc.CallOption) (destination.Destination_GetClient, error) {
+	return &mockDestination_GetClient{updatesToReturn: c.updatesToReturn}, nil
+}
+
+func (c *mockDestinationClient) GetProfile(ctx context.Context, in *destination.GetDestination, opts ...grpc.CallOption) (destination.Destination_GetProfileClient, error) {
+	return nil, nil
+}
+
+type mockDestination_GetClient struct {
+	updatesToReturn []*destination.Update
+	grpc.ClientStream
+}
+
+func (a *mockDestination_GetClient) Recv() (*destination.Update, error) {
+	if len(a.updatesToReturn) == 0 {
+		return nil, io.EOF
+	}
+	var update *destination.Update
+	update, a.updatesToReturn = a.updatesToReturn[0], a.updatesToReturn[1:]
+	return update, nil
+}
+
 // sort Pods in ListPodResponses for easier comparison
 type ByPod []*pb.Pod
 
@@ -27,7 +63,7 @@ func (bp ByPod) Swap(i, j int)      { bp[i], bp[j] = bp[j], bp[i] }
 func (bp ByPod) Less(i, j int) bool { return bp[i].Name <= bp[j].Name }
 
 func listPodResponsesEqual(a pb.ListPodsResponse, b pb.ListPodsResponse) bool {
-	if len(a.Pods) != len(b.Pods) {
+	if len(a.Pods) != len(b.Pods) || a.ContinueToken != b.ContinueToken {
 		return false
 	}
 
@@ -55,18 +91,7 @@ func listPodResponsesEqual(a pb.ListPodsResponse, b pb.ListPodsResponse) bool {
 	return true
 }
 
-func TestListPods(t *testing.T) {
-	t.Run("Successfully performs a query based on resource type", func(t *testing.T) {
-		expectations := []listPodsExpected{
-			listPodsExpected{
-				err: nil,
-				promRes: model.Vector{
-					&model.Sample{
-						Metric:    model.Metric{"pod": "emojivoto-meshed"},
-						Timestamp: 456,
-					},
-				},
-				k8sRes: []string{`
+var listPodsK8sConfigs = []string{`
 apiVersion: v1
 kind: Pod
 metadata:
@@ -125,7 +150,20 @@ spec:
     matchLabels:
       pod-template-hash: hash-not-meshed
 `,
+}
+
+func TestListPods(t *testing.T) {
+	t.Run("Successfully performs a query based on resource type", func(t *testing.T) {
+		expectations := []listPodsExpected{
+			listPodsExpected{
+				err: nil,
+				promRes: model.Vector{
+					&model.Sample{
+						Metric:    model.Metric{"pod": "emojivoto-meshed"},
+						Timestamp: 456,
+					},
 				},
+				k8sRes: listPodsK8sConfigs,
 				res: pb.ListPodsResponse{
 					Pods: []*pb.Pod{
 						&pb.Pod{
@@ -145,6 +183,68 @@ spec:
 					},
 				},
 			},
+			listPodsExpected{
+				err: nil,
+				promRes: model.Vector{
+					&model.Sample{
+						Metric:    model.Metric{"pod": "emojivoto-meshed"},
+						Timestamp: 456,
+					},
+				},
+				k8sRes: listPodsK8sConfigs,
+				req:    pb.ListPodsRequest{Limit: 1},
+				res: pb.ListPodsResponse{
+					Pods: []*pb.Pod{
+						&pb.Pod{
+							Name:            "emojivoto/emojivoto-meshed",
+							Added:           true,
+							SinceLastReport: &duration.Duration{},
+							Status:          "Running",
+							PodIP:           "1.2.3.4",
+							Owner:           &pb.Pod_Deployment{Deployment: "emojivoto/meshed-deployment"},
+						},
+					},
+					ContinueToken: "ZW1vaml2b3RvL2Vtb2ppdm90by1tZXNoZWQ",
+				},
+			},
+			listPodsExpected{
+				err:     nil,
+				promRes: model.Vector{},
+				k8sRes:  listPodsK8sConfigs,
+				req:     pb.ListPodsRequest{Limit: 1, ContinueToken: "ZW1vaml2b3RvL2Vtb2ppdm90by1tZXNoZWQ"},
+				res: pb.ListPodsResponse{
+					Pods: []*pb.Pod{
+						&pb.Pod{
+							Name:   "emojivoto/emojivoto-not-meshed",
+							Status: "Pending",
+							PodIP:  "4.3.2.1",
+							Owner:  &pb.Pod_Deployment{Deployment: "emojivoto/not-meshed-deployment"},
+						},
+					},
+				},
+			},
+			listPodsExpected{
+				err:     nil,
+				promRes: model.Vector{},
+				k8sRes:  listPodsK8sConfigs,
+				req: pb.ListPodsRequest{
+					Owner: &pb.Resource{
+						Namespace: "emojivoto",
+						Type:      "deployment",
+						Name:      "meshed-deployment",
+					},
+					ExcludeStatus: true,
+				},
+				res: pb.ListPodsResponse{
+					Pods: []*pb.Pod{
+						&pb.Pod{
+							Name:  "emojivoto/emojivoto-meshed",
+							PodIP: "1.2.3.4",
+							Owner: &pb.Pod_Deployment{Deployment: "emojivoto/meshed-deployment"},
+						},
+					},
+				},
+			},
 		}
 
 		for _, exp := range expectations {
@@ -156,6 +256,7 @@ spec:
 			fakeGrpcServer := newGrpcServer(
 				&MockProm{Res: exp.promRes},
 				tap.NewTapClient(nil),
+				destination.NewDestinationClient(nil),
 				k8sAPI,
 				"linkerd",
 				[]string{},
@@ -163,7 +264,7 @@ spec:
 
 			k8sAPI.Sync(nil)
 
-			rsp, err := fakeGrpcServer.ListPods(context.TODO(), &pb.ListPodsRequest{})
+			rsp, err := fakeGrpcServer.ListPods(context.TODO(), &exp.req)
 			if err != exp.err {
 				t.Fatalf("Expected error: %s, Got: %s", exp.err, err)
 			}
@@ -174,3 +275,382 @@ spec:
 		}
 	})
 }
+
+func TestListPodsInvalidContinueToken(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(listPodsK8sConfigs...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	fakeGrpcServer := newGrpcServer(
+		&MockProm{Res: model.Vector{}},
+		tap.NewTapClient(nil),
+		destination.NewDestinationClient(nil),
+		k8sAPI,
+		"linkerd",
+		[]string{},
+	)
+
+	k8sAPI.Sync(nil)
+
+	_, err = fakeGrpcServer.ListPods(context.TODO(), &pb.ListPodsRequest{ContinueToken: "not base64!"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected an InvalidArgument error, got: %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Run("Returns the versions of the control plane components", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: controller-1
+  namespace: linkerd
+  labels:
+    linkerd.io/control-plane-component: controller
+spec:
+  containers:
+  - name: public-api
+    image: gcr.io/linkerd-io/controller:stable-2.0.0
+  - name: linkerd-proxy
+    image: localhost:5000/linkerd-io/proxy@sha256:abcd
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: prometheus-1
+  namespace: linkerd
+  labels:
+    linkerd.io/control-plane-component: prometheus
+spec:
+  containers:
+  - name: prometheus
+    image: prom/prometheus:v2.4.0
+  - name: linkerd-proxy
+    image: localhost:5000/linkerd-io/proxy:stable-2.0.0@sha256:abcd
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: linkerd
+  labels:
+    linkerd.io/control-plane-component: web
+spec:
+  containers:
+  - name: web
+    image: localhost:5000/linkerd-io/web
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emoji-1
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-component: controller
+spec:
+  containers:
+  - name: emoji
+    image: buoyantio/emojivoto-emoji-svc:v5
+`,
+		)
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		k8sAPI.Sync(nil)
+
+		rsp, err := fakeGrpcServer.Version(context.TODO(), &pb.Empty{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := []*pb.ComponentVersion{
+			&pb.ComponentVersion{Component: "controller", Pod: "controller-1", Container: "linkerd-proxy", Version: "sha256:abcd"},
+			&pb.ComponentVersion{Component: "controller", Pod: "controller-1", Container: "public-api", Version: "stable-2.0.0"},
+			&pb.ComponentVersion{Component: "prometheus", Pod: "prometheus-1", Container: "linkerd-proxy", Version: "stable-2.0.0"},
+			&pb.ComponentVersion{Component: "web", Pod: "web-1", Container: "web", Version: "latest"},
+		}
+		if !reflect.DeepEqual(expected, rsp.GetComponents()) {
+			t.Fatalf("Expected: %+v, Got: %+v", expected, rsp.GetComponents())
+		}
+	})
+}
+
+func TestListServices(t *testing.T) {
+	t.Run("Lists the services with their meshed pods", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web-svc
+  namespace: emojivoto
+spec:
+  selector:
+    app: web-svc
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: external
+  namespace: emojivoto
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: kube-dns
+  namespace: kube-system
+spec:
+  selector:
+    k8s-app: kube-dns
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-meshed
+  namespace: emojivoto
+  labels:
+    app: web-svc
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Running
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-not-meshed
+  namespace: emojivoto
+  labels:
+    app: web-svc
+status:
+  phase: Pending
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-failed
+  namespace: emojivoto
+  labels:
+    app: web-svc
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Failed
+`,
+		)
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{"kube-system"},
+		)
+
+		k8sAPI.Sync(nil)
+
+		rsp, err := fakeGrpcServer.ListServices(context.TODO(), &pb.ListServicesRequest{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := []*pb.Service{
+			&pb.Service{
+				Name:      "external",
+				Namespace: "emojivoto",
+			},
+			&pb.Service{
+				Name:            "web-svc",
+				Namespace:       "emojivoto",
+				Selector:        map[string]string{"app": "web-svc"},
+				MeshedPodCount:  1,
+				RunningPodCount: 2,
+			},
+		}
+
+		sort.Slice(rsp.Services, func(i, j int) bool { return rsp.Services[i].Name < rsp.Services[j].Name })
+		if !reflect.DeepEqual(expected, rsp.Services) {
+			t.Fatalf("Expected: %+v, Got: %+v", expected, rsp.Services)
+		}
+	})
+}
+
+func TestResolveDestination(t *testing.T) {
+	t.Run("Returns the endpoints of the first destination update", func(t *testing.T) {
+		expectations := []struct {
+			updates []*destination.Update
+			res     pb.ResolveDestinationResponse
+		}{
+			{
+				updates: []*destination.Update{
+					&destination.Update{
+						Update: &destination.Update_Add{
+							Add: &destination.WeightedAddrSet{
+								Addrs: []*destination.WeightedAddr{
+									&destination.WeightedAddr{
+										Addr:         &net.TcpAddress{Ip: addr.ProxyIPV4(10, 0, 0, 1), Port: 8080},
+										Weight:       1,
+										MetricLabels: map[string]string{"pod": "web-1"},
+										TlsIdentity: &destination.TlsIdentity{
+											Strategy: &destination.TlsIdentity_K8SPodIdentity_{
+												K8SPodIdentity: &destination.TlsIdentity_K8SPodIdentity{
+													PodIdentity:  "web.deployment.ns.linkerd-managed.linkerd.svc.cluster.local",
+													ControllerNs: "linkerd",
+												},
+											},
+										},
+										ProtocolHint: &destination.ProtocolHint{
+											Protocol: &destination.ProtocolHint_H2_{H2: &destination.ProtocolHint_H2{}},
+										},
+									},
+									&destination.WeightedAddr{Addr: &net.TcpAddress{Ip: addr.ProxyIPV4(10, 0, 0, 2), Port: 8080}, Weight: 1},
+								},
+							},
+						},
+					},
+				},
+				res: pb.ResolveDestinationResponse{
+					Exists:    true,
+					Addresses: []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+					Endpoints: []*pb.DestinationEndpoint{
+						{
+							Address:     "10.0.0.1:8080",
+							Pod:         "web-1",
+							Weight:      1,
+							TlsIdentity: "web.deployment.ns.linkerd-managed.linkerd.svc.cluster.local",
+							H2:          true,
+						},
+						{Address: "10.0.0.2:8080", Weight: 1},
+					},
+				},
+			},
+			{
+				updates: []*destination.Update{
+					&destination.Update{
+						Update: &destination.Update_NoEndpoints{
+							NoEndpoints: &destination.NoEndpoints{Exists: true},
+						},
+					},
+				},
+				res: pb.ResolveDestinationResponse{Exists: true},
+			},
+		}
+
+		for _, exp := range expectations {
+			k8sAPI, err := k8s.NewFakeAPI()
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+
+			fakeGrpcServer := newGrpcServer(
+				&MockProm{},
+				tap.NewTapClient(nil),
+				&mockDestinationClient{updatesToReturn: exp.updates},
+				k8sAPI,
+				"linkerd",
+				[]string{},
+			)
+
+			rsp, err := fakeGrpcServer.ResolveDestination(context.TODO(), &pb.ResolveDestinationRequest{Authority: "web.ns.svc.cluster.local:8080"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			if exp.res.Exists != rsp.Exists || !reflect.DeepEqual(exp.res.Addresses, rsp.Addresses) || !reflect.DeepEqual(exp.res.Endpoints, rsp.Endpoints) {
+				t.Fatalf("Expected: %+v, Got: %+v", &exp.res, rsp)
+			}
+		}
+	})
+
+	t.Run("Returns an error when the stream ends without an update", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI()
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{},
+			tap.NewTapClient(nil),
+			&mockDestinationClient{},
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		_, err = fakeGrpcServer.ResolveDestination(context.TODO(), &pb.ResolveDestinationRequest{Authority: "web.ns.svc.cluster.local:8080"})
+		if err != io.EOF {
+			t.Fatalf("Expected error: %s, Got: %s", io.EOF, err)
+		}
+	})
+}
+
+func TestSelfCheck(t *testing.T) {
+	expectations := []struct {
+		promRes        model.Value
+		expectedStatus healthcheckPb.CheckStatus
+	}{
+		{
+			promRes: model.Vector{
+				&model.Sample{
+					Metric:    model.Metric{"pod": "controller-6f78cbd47-bc557", "namespace": "linkerd"},
+					Timestamp: 456,
+				},
+			},
+			expectedStatus: healthcheckPb.CheckStatus_OK,
+		},
+		{
+			promRes:        model.Vector{},
+			expectedStatus: healthcheckPb.CheckStatus_ERROR,
+		},
+	}
+
+	for _, exp := range expectations {
+		k8sAPI, err := k8s.NewFakeAPI()
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		mockProm := &MockProm{Res: exp.promRes}
+		fakeGrpcServer := newGrpcServer(
+			mockProm,
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		rsp, err := fakeGrpcServer.SelfCheck(context.TODO(), &healthcheckPb.SelfCheckRequest{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		var promDataCheck *healthcheckPb.CheckResult
+		for _, result := range rsp.Results {
+			if result.CheckDescription == PromDataCheckDescription {
+				promDataCheck = result
+			}
+		}
+		if promDataCheck == nil || promDataCheck.Status != exp.expectedStatus {
+			t.Fatalf("Expected the Prometheus data check to be %s, got %+v", exp.expectedStatus, promDataCheck)
+		}
+
+		expectedQuery := `max(process_start_time_seconds{namespace="linkerd"}) by (pod, namespace)`
+		if !reflect.DeepEqual(mockProm.QueriesExecuted[len(mockProm.QueriesExecuted)-1:], []string{expectedQuery}) {
+			t.Fatalf("Expected query %s, got %v", expectedQuery, mockProm.QueriesExecuted)
+		}
+	}
+}
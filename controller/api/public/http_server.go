@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	destinationPb "github.com/linkerd/linkerd2-proxy-api/go/destination"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
 	tapPb "github.com/linkerd/linkerd2/controller/gen/controller/tap"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
@@ -13,31 +15,58 @@ import (
 	promApi "github.com/prometheus/client_golang/api"
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var (
-	statSummaryPath   = fullUrlPathFor("StatSummary")
-	versionPath       = fullUrlPathFor("Version")
-	listPodsPath      = fullUrlPathFor("ListPods")
-	tapByResourcePath = fullUrlPathFor("TapByResource")
-	selfCheckPath     = fullUrlPathFor("SelfCheck")
+	statSummaryPath       = fullUrlPathFor("StatSummary")
+	versionPath           = fullUrlPathFor("Version")
+	listPodsPath          = fullUrlPathFor("ListPods")
+	listServicesPath      = fullUrlPathFor("ListServices")
+	tapByResourcePath     = fullUrlPathFor("TapByResource")
+	selfCheckPath         = fullUrlPathFor("SelfCheck")
+	resolveDestPath       = fullUrlPathFor("ResolveDestination")
+	topRoutesPath         = fullUrlPathFor("TopRoutes")
+	edgesPath             = fullUrlPathFor("Edges")
+	statSummaryStreamPath = fullUrlPathFor("StatSummaryStream")
 )
 
 type handler struct {
 	grpcServer pb.ApiServer
+
+	// authenticator identifies the callers of the API, if set
+	authenticator *tokenReviewAuthenticator
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	withInstrumentation(w, req, h.serveHTTP)
+}
+
+func (h *handler) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	log.WithFields(log.Fields{
 		"req.Method": req.Method, "req.URL": req.URL, "req.Form": req.Form,
 	}).Debugf("Serving %s %s", req.Method, req.URL.Path)
+	if isJsonRequest(req) {
+		w.Header().Set(contentTypeHeader, jsonContentType)
+	}
+
 	// Validate request method
 	if req.Method != http.MethodPost {
 		writeErrorToHttpResponse(w, fmt.Errorf("POST required"))
 		return
 	}
 
+	if h.authenticator != nil {
+		user, groups, err := h.authenticator.authenticate(req)
+		if err != nil {
+			writeErrorToHttpResponse(w, status.Error(codes.Unauthenticated, err.Error()))
+			return
+		}
+		req = req.WithContext(withCaller(req.Context(), user, groups))
+	}
+
 	// Serve request
 	switch req.URL.Path {
 	case statSummaryPath:
@@ -46,10 +75,20 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		h.handleVersion(w, req)
 	case listPodsPath:
 		h.handleListPods(w, req)
+	case listServicesPath:
+		h.handleListServices(w, req)
 	case tapByResourcePath:
 		h.handleTapByResource(w, req)
 	case selfCheckPath:
 		h.handleSelfCheck(w, req)
+	case resolveDestPath:
+		h.handleResolveDestination(w, req)
+	case topRoutesPath:
+		h.handleTopRoutes(w, req)
+	case edgesPath:
+		h.handleEdges(w, req)
+	case statSummaryStreamPath:
+		h.handleStatSummaryStream(w, req)
 	default:
 		http.NotFound(w, req)
 	}
@@ -119,6 +158,69 @@ func (h *handler) handleSelfCheck(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func (h *handler) handleResolveDestination(w http.ResponseWriter, req *http.Request) {
+	var protoRequest pb.ResolveDestinationRequest
+	err := httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	rsp, err := h.grpcServer.ResolveDestination(req.Context(), &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	err = writeProtoToHttpResponse(w, rsp)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
+
+func (h *handler) handleTopRoutes(w http.ResponseWriter, req *http.Request) {
+	var protoRequest pb.TopRoutesRequest
+	err := httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	rsp, err := h.grpcServer.TopRoutes(req.Context(), &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	err = writeProtoToHttpResponse(w, rsp)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
+
+func (h *handler) handleEdges(w http.ResponseWriter, req *http.Request) {
+	var protoRequest pb.EdgesRequest
+	err := httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	rsp, err := h.grpcServer.Edges(req.Context(), &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	err = writeProtoToHttpResponse(w, rsp)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
+
 func (h *handler) handleListPods(w http.ResponseWriter, req *http.Request) {
 	var protoRequest pb.ListPodsRequest
 	err := httpRequestToProto(req, &protoRequest)
@@ -140,6 +242,27 @@ func (h *handler) handleListPods(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func (h *handler) handleListServices(w http.ResponseWriter, req *http.Request) {
+	var protoRequest pb.ListServicesRequest
+	err := httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	rsp, err := h.grpcServer.ListServices(req.Context(), &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	err = writeProtoToHttpResponse(w, rsp)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
+
 func (h *handler) handleTapByResource(w http.ResponseWriter, req *http.Request) {
 	flushableWriter, err := newStreamingWriter(w)
 	if err != nil {
@@ -162,6 +285,28 @@ func (h *handler) handleTapByResource(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+func (h *handler) handleStatSummaryStream(w http.ResponseWriter, req *http.Request) {
+	flushableWriter, err := newStreamingWriter(w)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	var protoRequest pb.StatSummaryStreamRequest
+	err = httpRequestToProto(req, &protoRequest)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+
+	server := statSummaryStreamServer{tapServer{w: flushableWriter, req: req}}
+	err = h.grpcServer.StatSummaryStream(&protoRequest, server)
+	if err != nil {
+		writeErrorToHttpResponse(w, err)
+		return
+	}
+}
+
 type tapServer struct {
 	w   flushableResponseWriter
 	req *http.Request
@@ -186,6 +331,23 @@ func (s tapServer) Context() context.Context     { return s.req.Context() }
 func (s tapServer) SendMsg(interface{}) error    { return nil }
 func (s tapServer) RecvMsg(interface{}) error    { return nil }
 
+// statSummaryStreamServer streams StatSummary responses the way tapServer
+// streams tap events.
+type statSummaryStreamServer struct {
+	tapServer
+}
+
+func (s statSummaryStreamServer) Send(msg *pb.StatSummaryResponse) error {
+	err := writeProtoToHttpResponse(s.w, msg)
+	if err != nil {
+		writeErrorToHttpResponse(s.w, err)
+		return err
+	}
+
+	s.w.Flush()
+	return nil
+}
+
 func fullUrlPathFor(method string) string {
 	return apiRoot + apiPrefix + method
 }
@@ -194,20 +356,35 @@ func NewServer(
 	addr string,
 	prometheusClient promApi.Client,
 	tapClient tapPb.TapClient,
+	destinationClient destinationPb.DestinationClient,
 	k8sAPI *k8s.API,
 	controllerNamespace string,
 	ignoredNamespaces []string,
+	promQueryTimeout time.Duration,
+	promMaxSamples int,
+	enforceRBAC bool,
 ) *http.Server {
+	grpcServer := newGrpcServer(
+		promv1.NewAPI(prometheusClient),
+		tapClient,
+		destinationClient,
+		k8sAPI,
+		controllerNamespace,
+		ignoredNamespaces,
+	)
+	grpcServer.promQueryTimeout = promQueryTimeout
+	grpcServer.promMaxSamples = promMaxSamples
+	grpcServer.recordedSeries = newRecordedSeries()
+
 	baseHandler := &handler{
-		grpcServer: newGrpcServer(
-			promv1.NewAPI(prometheusClient),
-			tapClient,
-			k8sAPI,
-			controllerNamespace,
-			ignoredNamespaces,
-		),
+		grpcServer: grpcServer,
+	}
+	if enforceRBAC {
+		baseHandler.grpcServer = newRBACServer(grpcServer, k8sAPI.Client)
+		baseHandler.authenticator = &tokenReviewAuthenticator{client: k8sAPI.Client}
 	}
 
+	registerMetrics()
 	instrumentedHandler := prometheus.WithTelemetry(baseHandler)
 
 	return &http.Server{
@@ -39,6 +39,26 @@ func (m *mockGrpcServer) SelfCheck(ctx context.Context, req *healcheckPb.SelfChe
 	return m.ResponseToReturn.(*healcheckPb.SelfCheckResponse), m.ErrorToReturn
 }
 
+func (m *mockGrpcServer) ResolveDestination(ctx context.Context, req *pb.ResolveDestinationRequest) (*pb.ResolveDestinationResponse, error) {
+	m.LastRequestReceived = req
+	return m.ResponseToReturn.(*pb.ResolveDestinationResponse), m.ErrorToReturn
+}
+
+func (m *mockGrpcServer) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest) (*pb.TopRoutesResponse, error) {
+	m.LastRequestReceived = req
+	return m.ResponseToReturn.(*pb.TopRoutesResponse), m.ErrorToReturn
+}
+
+func (m *mockGrpcServer) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	m.LastRequestReceived = req
+	return m.ResponseToReturn.(*pb.ListServicesResponse), m.ErrorToReturn
+}
+
+func (m *mockGrpcServer) Edges(ctx context.Context, req *pb.EdgesRequest) (*pb.EdgesResponse, error) {
+	m.LastRequestReceived = req
+	return m.ResponseToReturn.(*pb.EdgesResponse), m.ErrorToReturn
+}
+
 func (m *mockGrpcServer) Tap(req *pb.TapRequest, tapServer pb.Api_TapServer) error {
 	m.LastRequestReceived = req
 	if m.ErrorToReturn == nil {
@@ -61,6 +81,15 @@ func (m *mockGrpcServer) TapByResource(req *pb.TapByResourceRequest, tapServer p
 	return m.ErrorToReturn
 }
 
+func (m *mockGrpcServer) StatSummaryStream(req *pb.StatSummaryStreamRequest, stream pb.Api_StatSummaryStreamServer) error {
+	m.LastRequestReceived = req
+	if m.ErrorToReturn == nil {
+		stream.Send(m.ResponseToReturn.(*pb.StatSummaryResponse))
+	}
+
+	return m.ErrorToReturn
+}
+
 type grpcCallTestCase struct {
 	expectedRequest  proto.Message
 	expectedResponse proto.Message
@@ -118,7 +147,88 @@ func TestServer(t *testing.T) {
 			functionCall: func() (proto.Message, error) { return client.Version(context.TODO(), versionReq) },
 		}
 
-		for _, testCase := range []grpcCallTestCase{testListPods, testStatSummary, testVersion} {
+		resolveDestinationReq := &pb.ResolveDestinationRequest{Authority: "web.ns.svc.cluster.local:8080"}
+		testResolveDestination := grpcCallTestCase{
+			expectedRequest: resolveDestinationReq,
+			expectedResponse: &pb.ResolveDestinationResponse{
+				Exists:    true,
+				Addresses: []string{"10.0.0.1:8080"},
+			},
+			functionCall: func() (proto.Message, error) {
+				return client.ResolveDestination(context.TODO(), resolveDestinationReq)
+			},
+		}
+
+		topRoutesReq := &pb.TopRoutesRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: "deployments", Name: "web"},
+			},
+			TimeWindow: "1m",
+		}
+		testTopRoutes := grpcCallTestCase{
+			expectedRequest: topRoutesReq,
+			expectedResponse: &pb.TopRoutesResponse{
+				Response: &pb.TopRoutesResponse_Ok_{
+					Ok: &pb.TopRoutesResponse_Ok{
+						RouteTable: &pb.RouteTable{
+							Rows: []*pb.RouteTable_Row{
+								{Route: "GET /api/list", Authority: "web.emojivoto.svc.cluster.local:80", TimeWindow: "1m"},
+							},
+						},
+					},
+				},
+			},
+			functionCall: func() (proto.Message, error) {
+				return client.TopRoutes(context.TODO(), topRoutesReq)
+			},
+		}
+
+		edgesReq := &pb.EdgesRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "emojivoto", Type: "deployment"},
+			},
+		}
+		testEdges := grpcCallTestCase{
+			expectedRequest: edgesReq,
+			expectedResponse: &pb.EdgesResponse{
+				Response: &pb.EdgesResponse_Ok_{
+					Ok: &pb.EdgesResponse_Ok{
+						Edges: []*pb.Edge{
+							{
+								Src:      &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+								Dst:      &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "emoji"},
+								ClientId: "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local",
+								ServerId: "emoji.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local",
+							},
+						},
+					},
+				},
+			},
+			functionCall: func() (proto.Message, error) {
+				return client.Edges(context.TODO(), edgesReq)
+			},
+		}
+
+		listServicesReq := &pb.ListServicesRequest{Namespace: "emojivoto"}
+		testListServices := grpcCallTestCase{
+			expectedRequest: listServicesReq,
+			expectedResponse: &pb.ListServicesResponse{
+				Services: []*pb.Service{
+					{
+						Name:            "web-svc",
+						Namespace:       "emojivoto",
+						Selector:        map[string]string{"app": "web-svc"},
+						MeshedPodCount:  1,
+						RunningPodCount: 2,
+					},
+				},
+			},
+			functionCall: func() (proto.Message, error) {
+				return client.ListServices(context.TODO(), listServicesReq)
+			},
+		}
+
+		for _, testCase := range []grpcCallTestCase{testListPods, testListServices, testStatSummary, testVersion, testResolveDestination, testTopRoutes, testEdges} {
 			assertCallWasForwarded(t, mockGrpcServer, testCase.expectedRequest, testCase.expectedResponse, testCase.functionCall)
 		}
 	})
@@ -183,6 +293,59 @@ func TestServer(t *testing.T) {
 		}
 	})
 
+	t.Run("Delegates streaming StatSummary RPC messages to the underlying grpc server", func(t *testing.T) {
+		mockGrpcServer := &mockGrpcServer{}
+
+		listener, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatalf("Could not start listener: %v", err)
+		}
+
+		go func() {
+			handler := &handler{
+				grpcServer: mockGrpcServer,
+			}
+			err := http.Serve(listener, handler)
+			if err != nil {
+				t.Fatalf("Could not start server: %v", err)
+			}
+		}()
+
+		client, err := NewInternalClient("linkerd", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedResponse := &pb.StatSummaryResponse{
+			Response: &pb.StatSummaryResponse_Ok_{
+				Ok: &pb.StatSummaryResponse_Ok{
+					StatTables: []*pb.StatTable{},
+				},
+			},
+		}
+		mockGrpcServer.ResponseToReturn = expectedResponse
+
+		expectedRequest := &pb.StatSummaryStreamRequest{
+			Request:  &pb.StatSummaryRequest{TimeWindow: "1m"},
+			Interval: "5s",
+		}
+		stream, err := client.StatSummaryStream(context.TODO(), expectedRequest)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		actualResponse, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !proto.Equal(actualResponse, expectedResponse) {
+			t.Fatalf("Expecting response to be [%v], but was [%v]", expectedResponse, actualResponse)
+		}
+		if !proto.Equal(mockGrpcServer.LastRequestReceived, expectedRequest) {
+			t.Fatalf("Expecting server call to be [%v], but got [%v]", expectedRequest, mockGrpcServer.LastRequestReceived)
+		}
+	})
+
 	t.Run("Handles errors before opening keep-alive response", func(t *testing.T) {
 		mockGrpcServer := &mockGrpcServer{}
 
@@ -0,0 +1,312 @@
+package public
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	promApi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// the custom metrics served to HorizontalPodAutoscalers: the rate of the
+	// inbound requests of a resource, and the 99th percentile of their latency
+	requestPerSecondMetric = "request_per_second"
+	latencyP99Metric       = "response_latency_ms_p99"
+
+	// metricsAdapterWindow is the time window of the custom metrics
+	metricsAdapterWindow = "1m"
+
+	adapterRequestQuery = "sum(rate(response_total{%s}[%s])) by (%s)"
+	adapterLatencyQuery = "histogram_quantile(0.99, sum(rate(response_latency_ms_bucket{%s}[%s])) by (le, %s))"
+)
+
+var customMetricsAPIPrefix = fmt.Sprintf("/apis/%s/%s", pkgK8s.CustomMetricsAPIGroup, pkgK8s.CustomMetricsAPIVersion)
+
+var adapterQueries = map[string]string{
+	requestPerSecondMetric: adapterRequestQuery,
+	latencyP99Metric:       adapterLatencyQuery,
+}
+
+// adapterResource is a resource of the custom metrics API, which is named
+// after the group resource of the objects it describes.
+type adapterResource struct {
+	// resourceType is the type of the objects, which is also the Prometheus
+	// label of their metrics
+	resourceType string
+	kind         string
+	apiVersion   string
+}
+
+var adapterResources = map[string]adapterResource{
+	"deployments.apps":       {resourceType: pkgK8s.Deployment, kind: "Deployment", apiVersion: "apps/v1"},
+	"deployments.extensions": {resourceType: pkgK8s.Deployment, kind: "Deployment", apiVersion: "extensions/v1beta1"},
+	"pods":                   {resourceType: pkgK8s.Pod, kind: "Pod", apiVersion: "v1"},
+}
+
+// metricValueList and metricValue are the MetricValueList and MetricValue
+// types of the custom metrics API.
+type metricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        metav1.ListMeta `json:"metadata"`
+	Items           []metricValue   `json:"items"`
+}
+
+type metricValue struct {
+	DescribedObject v1.ObjectReference `json:"describedObject"`
+	MetricName      string             `json:"metricName"`
+	Timestamp       metav1.Time        `json:"timestamp"`
+	Value           resource.Quantity  `json:"value"`
+}
+
+type metricsAdapterHandler struct {
+	authenticator *requestHeaderAuthenticator
+	grpcServer    *grpcServer
+}
+
+// NewMetricsAdapterServer returns a TLS server for the custom metrics API,
+// which is registered with the Kubernetes API server as an APIService so that
+// HorizontalPodAutoscalers can scale deployments on the request rate and
+// latency observed by their proxies. Requests are authorized by the
+// Kubernetes API server, which grants access to the custom metrics API to the
+// HorizontalPodAutoscaler controller by default. The server's certificate is
+// verified by the Kubernetes API server with the CA bundle of the APIService.
+func NewMetricsAdapterServer(
+	addr string,
+	cert tls.Certificate,
+	prometheusClient promApi.Client,
+	k8sAPI *k8s.API,
+	controllerNamespace string,
+) (*http.Server, error) {
+	tlsConfig, authenticator, err := extensionAPIServerTLS(k8sAPI, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := &metricsAdapterHandler{
+		authenticator: authenticator,
+		grpcServer:    newGrpcServer(promv1.NewAPI(prometheusClient), nil, nil, k8sAPI, controllerNamespace, nil),
+	}
+
+	return &http.Server{
+		Addr:      addr,
+		Handler:   prometheus.WithTelemetry(handler),
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+func (h *metricsAdapterHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	log.WithFields(log.Fields{
+		"req.Method": req.Method, "req.URL": req.URL,
+	}).Debugf("Serving %s %s", req.Method, req.URL.Path)
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if path == customMetricsAPIPrefix {
+		h.handleDiscovery(w)
+		return
+	}
+
+	// only the metrics of namespaced objects are served:
+	// /namespaces/{namespace}/{resource}/{name}/{metric}
+	parts := strings.Split(strings.TrimPrefix(path, customMetricsAPIPrefix+"/"), "/")
+	if !strings.HasPrefix(path, customMetricsAPIPrefix+"/namespaces/") || len(parts) != 5 {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		writeStatusError(w, apierrors.NewMethodNotSupported(schema.GroupResource{Group: pkgK8s.CustomMetricsAPIGroup, Resource: parts[2]}, req.Method))
+		return
+	}
+	if _, _, err := h.authenticator.authenticate(req); err != nil {
+		writeStatusError(w, apierrors.NewUnauthorized(err.Error()))
+		return
+	}
+
+	list, err := h.getMetricValues(req, parts[1], parts[2], parts[3], parts[4])
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Errorf("Error writing metric values: %v", err)
+	}
+}
+
+// handleDiscovery describes the custom metrics API to the Kubernetes API
+// server, with a resource for each metric of each type of object.
+func (h *metricsAdapterHandler) handleDiscovery(w http.ResponseWriter) {
+	list := metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{APIVersion: "v1", Kind: "APIResourceList"},
+		GroupVersion: fmt.Sprintf("%s/%s", pkgK8s.CustomMetricsAPIGroup, pkgK8s.CustomMetricsAPIVersion),
+		APIResources: []metav1.APIResource{},
+	}
+	for _, name := range sortedKeys(adapterResources) {
+		for _, metric := range []string{requestPerSecondMetric, latencyP99Metric} {
+			list.APIResources = append(list.APIResources, metav1.APIResource{
+				Name:       fmt.Sprintf("%s/%s", name, metric),
+				Namespaced: true,
+				Kind:       "MetricValueList",
+				Verbs:      []string{"get"},
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Errorf("Error writing discovery response: %v", err)
+	}
+}
+
+// getMetricValues returns the values of the metric of the named object, or of
+// the objects matching the request's labelSelector if the name is "*".
+// Objects without traffic have a value of zero.
+func (h *metricsAdapterHandler) getMetricValues(req *http.Request, namespace, resourceName, name, metric string) (*metricValueList, error) {
+	res, ok := adapterResources[resourceName]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: pkgK8s.CustomMetricsAPIGroup, Resource: resourceName}, name)
+	}
+	query, ok := adapterQueries[metric]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: pkgK8s.CustomMetricsAPIGroup, Resource: resourceName + "/" + metric}, name)
+	}
+
+	names, err := h.objectNames(res.resourceType, namespace, name, req.URL.Query().Get("labelSelector"))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &metricValueList{
+		TypeMeta: metav1.TypeMeta{APIVersion: fmt.Sprintf("%s/%s", pkgK8s.CustomMetricsAPIGroup, pkgK8s.CustomMetricsAPIVersion), Kind: "MetricValueList"},
+		Items:    []metricValue{},
+	}
+	if len(names) == 0 {
+		return list, nil
+	}
+
+	quotedNames := make([]string, len(names))
+	for i, name := range names {
+		quotedNames[i] = regexp.QuoteMeta(name)
+	}
+	selector := fmt.Sprintf("direction=\"inbound\", namespace=%q, %s=~%q", namespace, res.resourceType, strings.Join(quotedNames, "|"))
+
+	vec, err := h.grpcServer.queryProm(req.Context(), fmt.Sprintf(query, selector, metricsAdapterWindow, res.resourceType))
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	values := make(map[string]float64)
+	for _, sample := range vec {
+		value := float64(sample.Value)
+		if math.IsNaN(value) {
+			// there's no latency without requests
+			value = 0
+		}
+		values[string(sample.Metric[model.LabelName(res.resourceType)])] = value
+	}
+
+	now := metav1.Now()
+	for _, name := range names {
+		list.Items = append(list.Items, metricValue{
+			DescribedObject: v1.ObjectReference{
+				APIVersion: res.apiVersion,
+				Kind:       res.kind,
+				Namespace:  namespace,
+				Name:       name,
+			},
+			MetricName: metric,
+			Timestamp:  now,
+			Value:      *resource.NewMilliQuantity(int64(math.Round(values[name]*1000)), resource.DecimalSI),
+		})
+	}
+	return list, nil
+}
+
+// objectNames returns the sorted names of the objects of the given type whose
+// metrics are requested.
+func (h *metricsAdapterHandler) objectNames(resourceType, namespace, name, labelSelector string) ([]string, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid labelSelector: %s", err))
+	}
+
+	k8sAPI := h.grpcServer.k8sAPI
+	names := []string{}
+	switch resourceType {
+	case pkgK8s.Deployment:
+		if name != "*" {
+			if _, err := k8sAPI.Deploy().Lister().Deployments(namespace).Get(name); err != nil {
+				return nil, err
+			}
+			return []string{name}, nil
+		}
+		deploys, err := k8sAPI.Deploy().Lister().Deployments(namespace).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, deploy := range deploys {
+			names = append(names, deploy.Name)
+		}
+	case pkgK8s.Pod:
+		if name != "*" {
+			if _, err := k8sAPI.Pod().Lister().Pods(namespace).Get(name); err != nil {
+				return nil, err
+			}
+			return []string{name}, nil
+		}
+		pods, err := k8sAPI.Pod().Lister().Pods(namespace).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// writeStatusError writes an error as a Status of the Kubernetes API.
+func writeStatusError(w http.ResponseWriter, err error) {
+	statusErr, ok := err.(apierrors.APIStatus)
+	if !ok {
+		statusErr = apierrors.NewInternalError(err)
+	}
+	status := statusErr.Status()
+	status.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(status.Code))
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Errorf("Error writing status: %v", err)
+	}
+}
+
+func sortedKeys(resources map[string]adapterResource) []string {
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,172 @@
+package public
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/prometheus/common/model"
+)
+
+func TestMetricsAdapterHandler(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: web
+  namespace: emojivoto
+  labels:
+    app: web-svc
+spec:
+  selector:
+    matchLabels:
+      app: web-svc
+  template:
+    spec:
+      containers:
+      - image: buoyantio/emojivoto-web:v3
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: emojivoto
+  labels:
+    app: web-svc
+status:
+  phase: Running
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-2
+  namespace: emojivoto
+  labels:
+    app: web-svc
+status:
+  phase: Running
+`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+
+	mockProm := &MockProm{}
+	handler := &metricsAdapterHandler{
+		authenticator: &requestHeaderAuthenticator{
+			usernameHeaders: []string{"X-Remote-User"},
+			groupHeaders:    []string{"X-Remote-Group"},
+		},
+		grpcServer: newGrpcServer(mockProm, nil, nil, k8sAPI, "linkerd", nil),
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := proxiedRequest(t, path, "front-proxy-client", &pb.Empty{})
+		req.Method = http.MethodGet
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+		return rsp
+	}
+
+	t.Run("Serves discovery", func(t *testing.T) {
+		rsp := get("/apis/custom.metrics.k8s.io/v1beta1")
+
+		if rsp.Code != http.StatusOK || !strings.Contains(rsp.Body.String(), `"name":"deployments.apps/request_per_second"`) {
+			t.Fatalf("Unexpected discovery response: %d %s", rsp.Code, rsp.Body.String())
+		}
+	})
+
+	t.Run("Serves the request rate of a deployment", func(t *testing.T) {
+		mockProm.QueriesExecuted = nil
+		mockProm.Res = model.Vector{
+			&model.Sample{Metric: model.Metric{"deployment": "web"}, Value: 1.5},
+		}
+
+		rsp := get("/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/deployments.apps/web/request_per_second")
+		if rsp.Code != http.StatusOK {
+			t.Fatalf("Unexpected response: %d %s", rsp.Code, rsp.Body.String())
+		}
+
+		expectedQueries := []string{
+			`sum(rate(response_total{direction="inbound", namespace="emojivoto", deployment=~"web"}[1m])) by (deployment)`,
+		}
+		if !reflect.DeepEqual(mockProm.QueriesExecuted, expectedQueries) {
+			t.Fatalf("Expected queries %v, got %v", expectedQueries, mockProm.QueriesExecuted)
+		}
+
+		var list metricValueList
+		if err := json.Unmarshal(rsp.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(list.Items) != 1 {
+			t.Fatalf("Expected 1 metric value, got %+v", list.Items)
+		}
+		item := list.Items[0]
+		if item.DescribedObject.Kind != "Deployment" || item.DescribedObject.Name != "web" || item.MetricName != "request_per_second" {
+			t.Fatalf("Unexpected metric value: %+v", item)
+		}
+		if value := item.Value.String(); value != "1500m" {
+			t.Fatalf("Expected a value of 1500m, got %s", value)
+		}
+	})
+
+	t.Run("Serves the latency of the pods matching a label selector", func(t *testing.T) {
+		mockProm.QueriesExecuted = nil
+		mockProm.Res = model.Vector{
+			&model.Sample{Metric: model.Metric{"pod": "web-1"}, Value: 250},
+		}
+
+		rsp := get("/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/pods/*/response_latency_ms_p99?labelSelector=app%3Dweb-svc")
+		if rsp.Code != http.StatusOK {
+			t.Fatalf("Unexpected response: %d %s", rsp.Code, rsp.Body.String())
+		}
+
+		expectedQueries := []string{
+			`histogram_quantile(0.99, sum(rate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod=~"web-1|web-2"}[1m])) by (le, pod))`,
+		}
+		if !reflect.DeepEqual(mockProm.QueriesExecuted, expectedQueries) {
+			t.Fatalf("Expected queries %v, got %v", expectedQueries, mockProm.QueriesExecuted)
+		}
+
+		var list metricValueList
+		if err := json.Unmarshal(rsp.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		values := map[string]string{}
+		for _, item := range list.Items {
+			values[item.DescribedObject.Name] = item.Value.String()
+		}
+		// pods without traffic have no latency
+		expectedValues := map[string]string{"web-1": "250", "web-2": "0"}
+		if !reflect.DeepEqual(values, expectedValues) {
+			t.Fatalf("Expected values %v, got %v", expectedValues, values)
+		}
+	})
+
+	t.Run("Returns not found for unknown objects and metrics", func(t *testing.T) {
+		for _, path := range []string{
+			"/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/deployments.apps/vote/request_per_second",
+			"/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/deployments.apps/web/cpu_usage",
+			"/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/services/web/request_per_second",
+		} {
+			if rsp := get(path); rsp.Code != http.StatusNotFound {
+				t.Fatalf("Expected %s not to be found, got %d %s", path, rsp.Code, rsp.Body.String())
+			}
+		}
+	})
+
+	t.Run("Rejects unauthenticated requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/apis/custom.metrics.k8s.io/v1beta1/namespaces/emojivoto/deployments.apps/web/request_per_second", nil)
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+
+		if rsp.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected an unauthorized error, got %d %s", rsp.Code, rsp.Body.String())
+		}
+	})
+}
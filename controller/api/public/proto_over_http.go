@@ -2,16 +2,20 @@ package public
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"time"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -20,6 +24,7 @@ const (
 	defaultHttpErrorStatusCode = http.StatusInternalServerError
 	contentTypeHeader          = "Content-Type"
 	protobufContentType        = "application/octet-stream"
+	jsonContentType            = "application/json"
 	numBytesForMessageLength   = 4
 )
 
@@ -28,17 +33,59 @@ type httpError struct {
 	WrappedError error
 }
 
+// apiErr is the error returned by the client when the API responds with an
+// error, with the gRPC status code of the error.
+type apiErr struct {
+	code    codes.Code
+	message string
+}
+
+func (e apiErr) Error() string {
+	return e.message
+}
+
+// ErrorCode returns the gRPC status code of an error returned by a public API
+// client, e.g. codes.DeadlineExceeded if a Prometheus query timed out.
+func ErrorCode(err error) codes.Code {
+	if e, ok := err.(apiErr); ok {
+		return e.code
+	}
+	return status.Code(err)
+}
+
+// errorCodeOf returns the gRPC status code of an error of the server.
+func errorCodeOf(err error) codes.Code {
+	if httpErr, ok := err.(httpError); ok && httpErr.Code == http.StatusBadRequest {
+		return codes.InvalidArgument
+	}
+	return status.Code(err)
+}
+
 type flushableResponseWriter interface {
 	http.ResponseWriter
 	http.Flusher
 }
 
+var jsonMarshaler = jsonpb.Marshaler{EmitDefaults: true}
+
 func (e httpError) Error() string {
 	return fmt.Sprintf("HTTP error, status Code [%d], wrapped error is: %v", e.Code, e.WrappedError)
 }
 
+// isJsonRequest returns true if the request body is the JSON encoding of the
+// protobuf request, in which case the response is JSON encoded as well, so
+// that the API can be called without the protobuf-over-HTTP framing.
+func isJsonRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get(contentTypeHeader))
+	return err == nil && mediaType == jsonContentType
+}
+
+func isJsonResponse(w http.ResponseWriter) bool {
+	return w.Header().Get(contentTypeHeader) == jsonContentType
+}
+
 func httpRequestToProto(req *http.Request, protoRequestOut proto.Message) error {
-	bytes, err := ioutil.ReadAll(req.Body)
+	body, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return httpError{
 			Code:         http.StatusBadRequest,
@@ -46,7 +93,13 @@ func httpRequestToProto(req *http.Request, protoRequestOut proto.Message) error
 		}
 	}
 
-	err = proto.Unmarshal(bytes, protoRequestOut)
+	if isJsonRequest(req) {
+		if len(body) > 0 {
+			err = jsonpb.Unmarshal(bytes.NewReader(body), protoRequestOut)
+		}
+	} else {
+		err = proto.Unmarshal(body, protoRequestOut)
+	}
 	if err != nil {
 		return httpError{
 			Code:         http.StatusBadRequest,
@@ -66,14 +119,21 @@ func writeErrorToHttpResponse(w http.ResponseWriter, errorObtained error) {
 		errorToReturn = httpErr.WrappedError
 	}
 
+	if iw, ok := w.(*instrumentedResponseWriter); ok {
+		iw.setError(errorObtained)
+	}
+
 	w.Header().Set(errorHeader, http.StatusText(statusCode))
+	if isJsonResponse(w) {
+		w.WriteHeader(statusCode)
+	}
 
 	errorMessageToReturn := errorToReturn.Error()
 	if grpcError, ok := status.FromError(errorObtained); ok {
 		errorMessageToReturn = grpcError.Message()
 	}
 
-	errorAsProto := &pb.ApiError{Error: errorMessageToReturn}
+	errorAsProto := &pb.ApiError{Error: errorMessageToReturn, Code: uint32(errorCodeOf(errorObtained))}
 
 	err := writeProtoToHttpResponse(w, errorAsProto)
 	if err != nil {
@@ -83,6 +143,14 @@ func writeErrorToHttpResponse(w http.ResponseWriter, errorObtained error) {
 }
 
 func writeProtoToHttpResponse(w http.ResponseWriter, msg proto.Message) error {
+	if iw, ok := w.(*instrumentedResponseWriter); ok {
+		defer iw.timings.observe(serializationPhase, time.Now())
+	}
+
+	if isJsonResponse(w) {
+		return writeJsonToHttpResponse(w, msg)
+	}
+
 	w.Header().Set(contentTypeHeader, protobufContentType)
 	marshalledProtobufMessage, err := proto.Marshal(msg)
 	if err != nil {
@@ -97,6 +165,18 @@ func writeProtoToHttpResponse(w http.ResponseWriter, msg proto.Message) error {
 	return err
 }
 
+// writeJsonToHttpResponse writes the message as a line of JSON, so that
+// streamed messages are newline-delimited.
+func writeJsonToHttpResponse(w http.ResponseWriter, msg proto.Message) error {
+	marshalledJsonMessage, err := jsonMarshaler.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, marshalledJsonMessage+"\n")
+	return err
+}
+
 func newStreamingWriter(w http.ResponseWriter) (flushableResponseWriter, error) {
 	flushableWriter, ok := w.(flushableResponseWriter)
 	if !ok {
@@ -146,7 +226,7 @@ func checkIfResponseHasError(rsp *http.Response) error {
 			return fmt.Errorf("Response has %s header [%s], but response body didn't contain protobuf error: %v", errorHeader, errorMsg, err)
 		}
 
-		return errors.New(apiError.Error)
+		return apiErr{code: codes.Code(apiError.Code), message: apiError.Error}
 	}
 
 	if rsp.StatusCode != http.StatusOK {
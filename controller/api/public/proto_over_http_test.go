@@ -86,6 +86,29 @@ func TestHttpRequestToProto(t *testing.T) {
 		}
 	})
 
+	t.Run("Given a JSON request, deserializes its contents into protobuf object", func(t *testing.T) {
+		expectedProtoMessage := pb.ListPodsRequest{
+			Namespace: "emojivoto",
+			Limit:     10,
+		}
+
+		req, err := http.NewRequest(someMethod, someUrl, strings.NewReader(`{"namespace": "emojivoto", "limit": 10}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		req.Header.Set(contentTypeHeader, "application/json; charset=utf-8")
+
+		var actualProtoMessage pb.ListPodsRequest
+		err = httpRequestToProto(req, &actualProtoMessage)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !proto.Equal(&actualProtoMessage, &expectedProtoMessage) {
+			t.Fatalf("Expected request to be [%v], but got [%v]", expectedProtoMessage, actualProtoMessage)
+		}
+	})
+
 	t.Run("Given a broken request, returns http error", func(t *testing.T) {
 		var actualProtoMessage pb.Pod
 
@@ -131,7 +154,7 @@ func TestWriteErrorToHttpResponse(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		expectedErrorPayload := pb.ApiError{Error: genericError.Error()}
+		expectedErrorPayload := pb.ApiError{Error: genericError.Error(), Code: uint32(codes.Unknown)}
 		var actualErrorPayload pb.ApiError
 		err = proto.Unmarshal(payloadRead, &actualErrorPayload)
 		if err != nil {
@@ -165,7 +188,7 @@ func TestWriteErrorToHttpResponse(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		expectedErrorPayload := pb.ApiError{Error: httpError.WrappedError.Error()}
+		expectedErrorPayload := pb.ApiError{Error: httpError.WrappedError.Error(), Code: uint32(codes.Unknown)}
 		var actualErrorPayload pb.ApiError
 		err = proto.Unmarshal(payloadRead, &actualErrorPayload)
 		if err != nil {
@@ -198,7 +221,7 @@ func TestWriteErrorToHttpResponse(t *testing.T) {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		expectedErrorPayload := pb.ApiError{Error: expectedErrorMessage}
+		expectedErrorPayload := pb.ApiError{Error: expectedErrorMessage, Code: uint32(codes.AlreadyExists)}
 		var actualErrorPayload pb.ApiError
 		err = proto.Unmarshal(payloadRead, &actualErrorPayload)
 		if err != nil {
@@ -242,6 +265,26 @@ func TestWriteProtoToHttpResponse(t *testing.T) {
 			t.Fatalf("Expected response body to contain message [%v], but got [%v]", expectedMessage, actualMessage)
 		}
 	})
+
+	t.Run("Writes JSON when the response is JSON", func(t *testing.T) {
+		expectedMessage := pb.VersionInfo{
+			ReleaseVersion: "0.0.1",
+			BuildDate:      "02/21/1983",
+			GoVersion:      "10.2.45",
+		}
+
+		responseWriter := newStubResponseWriter()
+		responseWriter.Header().Set(contentTypeHeader, jsonContentType)
+		err := writeProtoToHttpResponse(responseWriter, &expectedMessage)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedBody := `{"goVersion":"10.2.45","buildDate":"02/21/1983","releaseVersion":"0.0.1"}` + "\n"
+		if responseWriter.body.String() != expectedBody {
+			t.Fatalf("Expected response body to be [%s], but got [%s]", expectedBody, responseWriter.body.String())
+		}
+	})
 }
 
 func TestDeserializePayloadFromReader(t *testing.T) {
@@ -477,6 +520,30 @@ func TestCheckIfResponseHasError(t *testing.T) {
 		}
 	})
 
+	t.Run("returns the gRPC status code of the error in body", func(t *testing.T) {
+		protoInBytes, err := proto.Marshal(&pb.ApiError{Error: "query timed out", Code: uint32(codes.DeadlineExceeded)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		message, err := serializeAsPayload(protoInBytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		response := &http.Response{
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(bytes.NewReader(message)),
+			StatusCode: http.StatusInternalServerError,
+		}
+		response.Header.Set(errorHeader, "error")
+
+		err = checkIfResponseHasError(response)
+		if code := ErrorCode(err); code != codes.DeadlineExceeded {
+			t.Fatalf("Expected error code to be [%s], but it was [%s]", codes.DeadlineExceeded, code)
+		}
+	})
+
 	t.Run("returns error if response contains linkerd-error header but body isn't error message", func(t *testing.T) {
 		protoInBytes, err := proto.Marshal(&pb.VersionInfo{ReleaseVersion: "0.0.1"})
 		if err != nil {
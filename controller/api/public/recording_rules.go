@@ -0,0 +1,128 @@
+package public
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// recordedWindow is the window of the rates recorded by the recording
+	// rules of the Prometheus installed by `linkerd install`. Stat queries for
+	// shorter windows can't be answered from the recorded series.
+	recordedWindow = time.Minute
+
+	// the recorded rates are averaged over the time window of the request,
+	// and the average request rate is multiplied by the length of the window
+	// to get the number of requests
+	recordedReqQuery             = "sum(avg_over_time(%s%%s[%%s])) by (%%s, classification, tls) * %v"
+	recordedLatencyQuantileQuery = "histogram_quantile(%%s, sum(avg_over_time(%s%%s[%%s])) by (le, %%s))"
+
+	recordedSeriesQuery = "count(%s)"
+
+	// recordedSeriesCheckInterval is how long whether Prometheus has the
+	// recorded series of a resource type is cached for.
+	recordedSeriesCheckInterval = time.Minute
+)
+
+// recordedMetrics are the formats of the names of the recorded request volume
+// and latency series, which are prefixed with the labels they're aggregated by.
+type recordedMetrics struct {
+	requests string
+	latency  string
+}
+
+var (
+	// the series of the requests of the resources, queried by StatSummary
+	recordedStats = recordedMetrics{
+		requests: "%s:response_total:rate1m",
+		latency:  "%s:response_latency_ms_bucket:rate1m",
+	}
+
+	// the series of the requests of the resources by route and authority,
+	// queried by TopRoutes
+	recordedRoutes = recordedMetrics{
+		requests: "%s:route_response_total:rate1m",
+		latency:  "%s:route_response_latency_ms_bucket:rate1m",
+	}
+)
+
+// recordedResourceTypes are the resource types whose inbound requests are
+// pre-aggregated by the recording rules, keyed by the prefix of the names of
+// their recorded series, which are aggregated by namespace and by the label
+// of the resource type.
+var recordedResourceTypes = map[string]string{
+	k8s.Namespace:             "namespace",
+	k8s.Deployment:            "namespace_deployment",
+	k8s.ReplicationController: "namespace_replicationcontroller",
+	k8s.Pod:                   "namespace_pod",
+}
+
+// recordedSeries caches whether Prometheus has the series of the recording
+// rules, which it doesn't when the control plane was installed with an
+// existing Prometheus server, or right after the rules were added.
+type recordedSeries struct {
+	sync.Mutex
+	present map[string]bool
+	checked map[string]time.Time
+}
+
+func newRecordedSeries() *recordedSeries {
+	return &recordedSeries{
+		present: make(map[string]bool),
+		checked: make(map[string]time.Time),
+	}
+}
+
+// queries returns the request volume and latency quantile query templates of
+// getPrometheusResults for the recorded series of the resource type, for the
+// given time window.
+func (m recordedMetrics) queries(resourceType string, window time.Duration) (string, string) {
+	prefix := recordedResourceTypes[resourceType]
+	requestsMetric := fmt.Sprintf(m.requests, prefix)
+	latencyMetric := fmt.Sprintf(m.latency, prefix)
+
+	return fmt.Sprintf(recordedReqQuery, requestsMetric, window.Seconds()),
+		fmt.Sprintf(recordedLatencyQuantileQuery, latencyMetric)
+}
+
+// useRecordedSeries returns true if the inbound requests of the resource type
+// can be queried from the recorded series: they're only recorded for some
+// resource types, over windows of at least recordedWindow, and only if
+// Prometheus is configured with the recording rules.
+func (s *grpcServer) useRecordedSeries(ctx context.Context, metrics recordedMetrics, resourceType string, window time.Duration) bool {
+	if s.recordedSeries == nil || window < recordedWindow {
+		return false
+	}
+	prefix, ok := recordedResourceTypes[resourceType]
+	if !ok {
+		return false
+	}
+	return s.recordedSeries.has(ctx, s, fmt.Sprintf(metrics.requests, prefix))
+}
+
+// has returns true if Prometheus has the recorded series, checking again at
+// most once per recordedSeriesCheckInterval.
+func (r *recordedSeries) has(ctx context.Context, s *grpcServer, metric string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	if time.Since(r.checked[metric]) < recordedSeriesCheckInterval {
+		return r.present[metric]
+	}
+
+	vec, err := s.queryProm(ctx, fmt.Sprintf(recordedSeriesQuery, metric))
+	if err != nil {
+		// fall back to the raw series, which are queried with their own errors
+		log.Errorf("failed to check for the recorded series %s: %s", metric, err)
+		return false
+	}
+
+	r.present[metric] = len(vec) > 0
+	r.checked[metric] = time.Now()
+	return r.present[metric]
+}
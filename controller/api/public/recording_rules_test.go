@@ -0,0 +1,161 @@
+package public
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/model"
+)
+
+func TestRecordedSeries(t *testing.T) {
+	podStatRequest := func(timeWindow string) *pb.StatSummaryRequest {
+		return &pb.StatSummaryRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{
+					Name:      "emojivoto-1",
+					Namespace: "emojivoto",
+					Type:      pkgK8s.Pod,
+				},
+			},
+			TimeWindow: timeWindow,
+		}
+	}
+
+	rawQueries := []string{
+		`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[10s])) by (le, namespace, pod))`,
+		`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[10s])) by (le, namespace, pod))`,
+		`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[10s])) by (le, namespace, pod))`,
+		`sum(increase(response_total{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[10s])) by (namespace, pod, classification, tls)`,
+	}
+
+	recordedQueries := []string{
+		`count(namespace_pod:response_total:rate1m)`,
+		`histogram_quantile(0.5, sum(avg_over_time(namespace_pod:response_latency_ms_bucket:rate1m{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[5m])) by (le, namespace, pod))`,
+		`histogram_quantile(0.95, sum(avg_over_time(namespace_pod:response_latency_ms_bucket:rate1m{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[5m])) by (le, namespace, pod))`,
+		`histogram_quantile(0.99, sum(avg_over_time(namespace_pod:response_latency_ms_bucket:rate1m{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[5m])) by (le, namespace, pod))`,
+		`sum(avg_over_time(namespace_pod:response_total:rate1m{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[5m])) by (namespace, pod, classification, tls) * 300`,
+	}
+
+	testCases := []struct {
+		name            string
+		promResponse    model.Value
+		requests        []*pb.StatSummaryRequest
+		expectedQueries []string
+	}{
+		{
+			name:            "queries the recorded series when Prometheus has them",
+			promResponse:    prometheusMetric("emojivoto-1", "pod", "emojivoto", "success", false),
+			requests:        []*pb.StatSummaryRequest{podStatRequest("5m")},
+			expectedQueries: recordedQueries,
+		},
+		{
+			name:            "queries the raw series for windows shorter than the recorded rates",
+			promResponse:    prometheusMetric("emojivoto-1", "pod", "emojivoto", "success", false),
+			requests:        []*pb.StatSummaryRequest{podStatRequest("10s")},
+			expectedQueries: rawQueries,
+		},
+		{
+			name:         "queries the raw series when Prometheus doesn't have the recorded series, and caches their absence",
+			promResponse: model.Vector{},
+			requests:     []*pb.StatSummaryRequest{podStatRequest("1m"), podStatRequest("1m")},
+			expectedQueries: []string{
+				`count(namespace_pod:response_total:rate1m)`,
+				`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (le, namespace, pod))`,
+				`sum(increase(response_total{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (namespace, pod, classification, tls)`,
+				`sum(increase(response_total{direction="inbound", namespace="emojivoto", pod="emojivoto-1"}[1m])) by (namespace, pod, classification, tls)`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k8sAPI, err := k8s.NewFakeAPI()
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+
+			mockProm := &MockProm{Res: tc.promResponse}
+			fakeGrpcServer := newGrpcServer(
+				mockProm,
+				tap.NewTapClient(nil),
+				destination.NewDestinationClient(nil),
+				k8sAPI,
+				"linkerd",
+				[]string{},
+			)
+			fakeGrpcServer.recordedSeries = newRecordedSeries()
+
+			k8sAPI.Sync(nil)
+
+			for _, req := range tc.requests {
+				if _, err := fakeGrpcServer.StatSummary(context.TODO(), req); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+			}
+
+			sort.Strings(tc.expectedQueries)
+			sort.Strings(mockProm.QueriesExecuted)
+			if !reflect.DeepEqual(tc.expectedQueries, mockProm.QueriesExecuted) {
+				t.Fatalf("Prometheus queries incorrect. \nExpected:\n%+v \nGot:\n%+v", tc.expectedQueries, mockProm.QueriesExecuted)
+			}
+		})
+	}
+}
+
+func TestRecordedRouteSeries(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI()
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	mockProm := &MockProm{Res: model.Vector{genRouteSample("GET /books", "books.default.svc.cluster.local:7000", "success")}}
+	fakeGrpcServer := newGrpcServer(
+		mockProm,
+		tap.NewTapClient(nil),
+		destination.NewDestinationClient(nil),
+		k8sAPI,
+		"linkerd",
+		[]string{},
+	)
+	fakeGrpcServer.recordedSeries = newRecordedSeries()
+
+	k8sAPI.Sync(nil)
+
+	req := &pb.TopRoutesRequest{
+		Selector: &pb.ResourceSelection{
+			Resource: &pb.Resource{
+				Namespace: "default",
+				Type:      pkgK8s.Deployment,
+				Name:      "books",
+			},
+		},
+		TimeWindow: "5m",
+	}
+	if _, err := fakeGrpcServer.TopRoutes(context.TODO(), req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expectedQueries := []string{
+		`count(namespace_deployment:route_response_total:rate1m)`,
+		`histogram_quantile(0.5, sum(avg_over_time(namespace_deployment:route_response_latency_ms_bucket:rate1m{deployment="books", direction="inbound", namespace="default"}[5m])) by (le, rt_route, dst))`,
+		`histogram_quantile(0.95, sum(avg_over_time(namespace_deployment:route_response_latency_ms_bucket:rate1m{deployment="books", direction="inbound", namespace="default"}[5m])) by (le, rt_route, dst))`,
+		`histogram_quantile(0.99, sum(avg_over_time(namespace_deployment:route_response_latency_ms_bucket:rate1m{deployment="books", direction="inbound", namespace="default"}[5m])) by (le, rt_route, dst))`,
+		`sum(avg_over_time(namespace_deployment:route_response_total:rate1m{deployment="books", direction="inbound", namespace="default"}[5m])) by (rt_route, dst, classification, tls) * 300`,
+	}
+	sort.Strings(mockProm.QueriesExecuted)
+	if !reflect.DeepEqual(expectedQueries, mockProm.QueriesExecuted) {
+		t.Fatalf("Prometheus queries incorrect. \nExpected:\n%+v \nGot:\n%+v", expectedQueries, mockProm.QueriesExecuted)
+	}
+}
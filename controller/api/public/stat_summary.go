@@ -4,14 +4,19 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	proto "github.com/golang/protobuf/proto"
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	pkgprom "github.com/linkerd/linkerd2/pkg/prometheus"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,6 +57,9 @@ const (
 
 	namespaceLabel    = model.LabelName("namespace")
 	dstNamespaceLabel = model.LabelName("dst_namespace")
+
+	defaultStatStreamInterval = 10 * time.Second
+	minStatStreamInterval     = time.Second
 )
 
 var promTypes = []promType{promRequests, promLatencyP50, promLatencyP95, promLatencyP99}
@@ -130,6 +138,45 @@ func (s *grpcServer) StatSummary(ctx context.Context, req *pb.StatSummaryRequest
 	return &rsp, nil
 }
 
+// StatSummaryStream sends the response of StatSummary every interval, until
+// the client cancels the stream. Invalid requests are answered with a single
+// error response.
+func (s *grpcServer) StatSummaryStream(req *pb.StatSummaryStreamRequest, stream pb.Api_StatSummaryStreamServer) error {
+	interval := defaultStatStreamInterval
+	if req.GetInterval() != "" {
+		var err error
+		interval, err = time.ParseDuration(req.GetInterval())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid interval: %s", err)
+		}
+		if interval < minStatStreamInterval {
+			return status.Errorf(codes.InvalidArgument, "interval must be at least %s", minStatStreamInterval)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		rsp, err := s.StatSummary(stream.Context(), req.GetRequest())
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+		if rsp.GetError() != nil {
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 func statSummaryError(req *pb.StatSummaryRequest, message string) *pb.StatSummaryResponse {
 	return &pb.StatSummaryResponse{
 		Response: &pb.StatSummaryResponse_Error{
@@ -176,7 +223,9 @@ func (s *grpcServer) getKubernetesObjectStats(req *pb.StatSummaryRequest) (map[r
 }
 
 func (s *grpcServer) k8sResourceQuery(ctx context.Context, req *pb.StatSummaryRequest) resourceResult {
+	start := time.Now()
 	k8sObjects, err := s.getKubernetesObjectStats(req)
+	observePhase(ctx, k8sPhase, start)
 	if err != nil {
 		return resourceResult{res: nil, err: err}
 	}
@@ -383,12 +432,34 @@ func buildRequestLabels(req *pb.StatSummaryRequest) (labels model.LabelSet, labe
 
 func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSummaryRequest, timeWindow string) (map[rKey]*pb.BasicStats, error) {
 	reqLabels, groupBy := buildRequestLabels(req)
+
+	// the pre-aggregated series of the recording rules are much cheaper to
+	// query than the series of every pod, on meshes with many pods; they're
+	// only recorded for inbound requests
+	requestQuery, latencyQuery := reqQuery, latencyQuantileQuery
+	resourceType := req.GetSelector().GetResource().GetType()
+	inbound := req.GetOutbound() == nil || req.GetNone() != nil
+	if window, err := time.ParseDuration(timeWindow); err == nil && inbound && s.useRecordedSeries(ctx, recordedStats, resourceType, window) {
+		requestQuery, latencyQuery = recordedStats.queries(resourceType, window)
+	}
+
+	results, err := s.getPrometheusResults(ctx, requestQuery, latencyQuery, reqLabels, timeWindow, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return processPrometheusMetrics(req, results, groupBy), nil
+}
+
+// getPrometheusResults runs the request volume query and the latency quantile
+// queries for the labels, grouped by groupBy.
+func (s *grpcServer) getPrometheusResults(ctx context.Context, requestQueryTemplate, latencyQueryTemplate string, reqLabels model.LabelSet, timeWindow string, groupBy model.LabelNames) ([]promResult, error) {
 	resultChan := make(chan promResult)
 
 	// kick off 4 asynchronous queries: 1 request volume + 3 latency
 	go func() {
 		// success/failure counts
-		requestsQuery := fmt.Sprintf(reqQuery, reqLabels, timeWindow, groupBy)
+		requestsQuery := fmt.Sprintf(requestQueryTemplate, reqLabels, timeWindow, groupBy)
 		resultVector, err := s.queryProm(ctx, requestsQuery)
 
 		resultChan <- promResult{
@@ -400,7 +471,7 @@ func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSumma
 
 	for _, quantile := range []promType{promLatencyP50, promLatencyP95, promLatencyP99} {
 		go func(quantile promType) {
-			latencyQuery := fmt.Sprintf(latencyQuantileQuery, quantile, reqLabels, timeWindow, groupBy)
+			latencyQuery := fmt.Sprintf(latencyQueryTemplate, quantile, latencySelector(reqLabels), timeWindow, groupBy)
 			latencyResult, err := s.queryProm(ctx, latencyQuery)
 
 			resultChan <- promResult{
@@ -427,7 +498,23 @@ func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSumma
 		return nil, err
 	}
 
-	return processPrometheusMetrics(req, results, groupBy), nil
+	return results, nil
+}
+
+// latencySelector returns the label selector of the latency quantile queries:
+// the labels of the requests, restricted to the latency buckets configured
+// with the -latency-buckets flag, if any.
+func latencySelector(labels model.LabelSet) string {
+	matcher := pkgprom.LatencyBucketMatcher()
+	if matcher == "" {
+		return labels.String()
+	}
+
+	selector := strings.TrimSuffix(labels.String(), "}")
+	if len(labels) > 0 {
+		selector += ", "
+	}
+	return fmt.Sprintf("%sle=~%q}", selector, matcher)
 }
 
 func processPrometheusMetrics(req *pb.StatSummaryRequest, results []promResult, groupBy model.LabelNames) map[rKey]*pb.BasicStats {
@@ -441,33 +528,43 @@ func processPrometheusMetrics(req *pb.StatSummaryRequest, results []promResult,
 				basicStats[resource] = &pb.BasicStats{}
 			}
 
-			value := extractSampleValue(sample)
-
-			switch result.prom {
-			case promRequests:
-				switch string(sample.Metric[model.LabelName("classification")]) {
-				case "success":
-					basicStats[resource].SuccessCount += value
-				case "failure":
-					basicStats[resource].FailureCount += value
-				}
-				switch string(sample.Metric[model.LabelName("tls")]) {
-				case "true":
-					basicStats[resource].TlsRequestCount += value
-				}
-			case promLatencyP50:
-				basicStats[resource].LatencyMsP50 = value
-			case promLatencyP95:
-				basicStats[resource].LatencyMsP95 = value
-			case promLatencyP99:
-				basicStats[resource].LatencyMsP99 = value
-			}
+			addSampleToBasicStats(basicStats[resource], result.prom, sample)
 		}
 	}
 
 	return basicStats
 }
 
+// addSampleToBasicStats adds the value of a sample returned by the query of
+// the given type to the stats.
+func addSampleToBasicStats(stats *pb.BasicStats, prom promType, sample *model.Sample) {
+	value := extractSampleValue(sample)
+
+	switch prom {
+	case promRequests:
+		switch string(sample.Metric[model.LabelName("classification")]) {
+		case "success":
+			stats.SuccessCount += value
+		case "failure":
+			stats.FailureCount += value
+		}
+		switch string(sample.Metric[model.LabelName("tls")]) {
+		case "true":
+			stats.TlsRequestCount += value
+		case "no_identity":
+			stats.NoIdentityRequestCount += value
+		case "disabled":
+			stats.TlsDisabledRequestCount += value
+		}
+	case promLatencyP50:
+		stats.LatencyMsP50 = value
+	case promLatencyP95:
+		stats.LatencyMsP95 = value
+	case promLatencyP99:
+		stats.LatencyMsP99 = value
+	}
+}
+
 func extractSampleValue(sample *model.Sample) uint64 {
 	value := uint64(0)
 	if !math.IsNaN(float64(sample.Value)) {
@@ -569,11 +666,21 @@ func isInvalidServiceRequest(req *pb.StatSummaryRequest) bool {
 
 func (s *grpcServer) queryProm(ctx context.Context, query string) (model.Vector, error) {
 	log.Debugf("Query request:\n\t%+v", query)
+	defer observePhase(ctx, prometheusPhase, time.Now())
+
+	if s.promQueryTimeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.promQueryTimeout)
+		defer cancel()
+	}
 
 	// single data point (aka summary) query
 	res, err := s.prometheusAPI.Query(ctx, query, time.Time{})
 	if err != nil {
 		log.Errorf("Query(%+v) failed with: %+v", query, err)
+		if isPromTimeout(ctx, err) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "Prometheus query timed out: %s", query)
+		}
 		return nil, err
 	}
 	log.Debugf("Query response:\n\t%+v", res)
@@ -584,5 +691,22 @@ func (s *grpcServer) queryProm(ctx context.Context, query string) (model.Vector,
 		return nil, err
 	}
 
-	return res.(model.Vector), nil
+	vec := res.(model.Vector)
+	if s.promMaxSamples != 0 && len(vec) > s.promMaxSamples {
+		err = status.Errorf(codes.ResourceExhausted, "Prometheus query returned %d samples, more than the maximum of %d: %s", len(vec), s.promMaxSamples, query)
+		log.Error(err)
+		return nil, err
+	}
+
+	return vec, nil
+}
+
+// isPromTimeout returns true if a Prometheus query failed because either the
+// query context or Prometheus itself timed out.
+func isPromTimeout(ctx context.Context, err error) bool {
+	if ctx.Err() == context.DeadlineExceeded {
+		return true
+	}
+	promErr, ok := err.(*promv1.Error)
+	return ok && promErr.Type == promv1.ErrTimeout
 }
@@ -8,11 +8,17 @@ import (
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
 	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	pkgprom "github.com/linkerd/linkerd2/pkg/prometheus"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type statSumExpected struct {
@@ -78,6 +84,7 @@ func testStatSummary(t *testing.T, expectations []statSumExpected) {
 		fakeGrpcServer := newGrpcServer(
 			mockProm,
 			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
 			k8sAPI,
 			"linkerd",
 			[]string{},
@@ -770,6 +777,7 @@ status:
 			fakeGrpcServer := newGrpcServer(
 				&MockProm{Res: exp.mockPromResponse},
 				tap.NewTapClient(nil),
+				destination.NewDestinationClient(nil),
 				k8sAPI,
 				"linkerd",
 				[]string{},
@@ -794,6 +802,7 @@ status:
 		fakeGrpcServer := newGrpcServer(
 			&MockProm{Res: model.Vector{}},
 			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
 			k8sAPI,
 			"linkerd",
 			[]string{},
@@ -1154,3 +1163,232 @@ status:
 		testStatSummary(t, expectations)
 	})
 }
+
+func TestProcessPrometheusMetrics(t *testing.T) {
+	t.Run("Breaks requests down by TLS status", func(t *testing.T) {
+		req := &pb.StatSummaryRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Type: pkgK8s.Deployment},
+			},
+		}
+		sample := func(tls string, value model.SampleValue) *model.Sample {
+			return &model.Sample{
+				Metric: model.Metric{
+					"namespace":      "emojivoto",
+					"deployment":     "web",
+					"classification": "success",
+					"tls":            model.LabelValue(tls),
+				},
+				Value: value,
+			}
+		}
+		results := []promResult{
+			{
+				prom: promRequests,
+				vec:  model.Vector{sample("true", 6), sample("no_identity", 3), sample("disabled", 1)},
+			},
+		}
+
+		stats := processPrometheusMetrics(req, results, model.LabelNames{"namespace", "deployment"})
+
+		expected := &pb.BasicStats{
+			SuccessCount:            10,
+			TlsRequestCount:         6,
+			NoIdentityRequestCount:  3,
+			TlsDisabledRequestCount: 1,
+		}
+		actual := stats[rKey{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"}]
+		if !proto.Equal(actual, expected) {
+			t.Fatalf("Expected stats %+v, got %+v", expected, actual)
+		}
+	})
+}
+
+func TestQueryProm(t *testing.T) {
+	newServer := func(mockProm *MockProm) *grpcServer {
+		k8sAPI, err := k8s.NewFakeAPI()
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		return newGrpcServer(
+			mockProm,
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+	}
+
+	t.Run("Returns a DeadlineExceeded error when Prometheus times out", func(t *testing.T) {
+		fakeGrpcServer := newServer(&MockProm{
+			Res: model.Vector{},
+			Err: &promv1.Error{Type: promv1.ErrTimeout, Msg: "query timed out"},
+		})
+
+		_, err := fakeGrpcServer.queryProm(context.TODO(), "up")
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("Expected a DeadlineExceeded error, got: %v", err)
+		}
+	})
+
+	t.Run("Returns a ResourceExhausted error for results over the maximum number of samples", func(t *testing.T) {
+		fakeGrpcServer := newServer(&MockProm{
+			Res: model.Vector{&model.Sample{}, &model.Sample{}},
+		})
+		fakeGrpcServer.promMaxSamples = 1
+
+		_, err := fakeGrpcServer.queryProm(context.TODO(), "up")
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("Expected a ResourceExhausted error, got: %v", err)
+		}
+
+		fakeGrpcServer.promMaxSamples = 2
+		vec, err := fakeGrpcServer.queryProm(context.TODO(), "up")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(vec) != 2 {
+			t.Fatalf("Expected 2 samples, got: %v", vec)
+		}
+	})
+}
+
+type mockStatSummaryStream struct {
+	ctx      context.Context
+	cancel   func()
+	sent     []*pb.StatSummaryResponse
+	maxSends int
+	grpc.ServerStream
+}
+
+// Send records the response, and cancels the stream after maxSends responses.
+func (m *mockStatSummaryStream) Send(rsp *pb.StatSummaryResponse) error {
+	m.sent = append(m.sent, rsp)
+	if len(m.sent) == m.maxSends {
+		m.cancel()
+	}
+	return nil
+}
+
+func (m *mockStatSummaryStream) Context() context.Context {
+	return m.ctx
+}
+
+func TestStatSummaryStream(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI()
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	newStream := func(maxSends int) *mockStatSummaryStream {
+		ctx, cancel := context.WithCancel(context.Background())
+		return &mockStatSummaryStream{ctx: ctx, cancel: cancel, maxSends: maxSends}
+	}
+
+	t.Run("Sends the stats every interval until the stream is cancelled", func(t *testing.T) {
+		mockProm := &MockProm{Res: model.Vector{}}
+		fakeGrpcServer := newGrpcServer(
+			mockProm,
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		stream := newStream(2)
+		err := fakeGrpcServer.StatSummaryStream(&pb.StatSummaryStreamRequest{
+			Request: &pb.StatSummaryRequest{
+				Selector: &pb.ResourceSelection{
+					Resource: &pb.Resource{Namespace: "books", Type: pkgK8s.Authority},
+				},
+				TimeWindow: "1m",
+			},
+			Interval: "1s",
+		}, stream)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if len(stream.sent) != 2 {
+			t.Fatalf("Expected 2 responses, got: %v", stream.sent)
+		}
+		for _, rsp := range stream.sent {
+			if rsp.GetOk() == nil {
+				t.Fatalf("Expected an Ok response, got: %v", rsp)
+			}
+		}
+		if len(mockProm.QueriesExecuted) != 2*len(promTypes) {
+			t.Fatalf("Expected the stats to be queried twice, got queries: %v", mockProm.QueriesExecuted)
+		}
+	})
+
+	t.Run("Sends a single response for invalid requests", func(t *testing.T) {
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{Res: model.Vector{}},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		stream := newStream(0)
+		err := fakeGrpcServer.StatSummaryStream(&pb.StatSummaryStreamRequest{
+			Request: &pb.StatSummaryRequest{},
+		}, stream)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if len(stream.sent) != 1 || stream.sent[0].GetError() == nil {
+			t.Fatalf("Expected a single error response, got: %v", stream.sent)
+		}
+	})
+
+	t.Run("Rejects invalid intervals", func(t *testing.T) {
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{Res: model.Vector{}},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		for _, interval := range []string{"10", "-1s", "100ms"} {
+			err := fakeGrpcServer.StatSummaryStream(&pb.StatSummaryStreamRequest{
+				Request:  &pb.StatSummaryRequest{},
+				Interval: interval,
+			}, newStream(0))
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Expected an InvalidArgument error for interval %q, got: %v", interval, err)
+			}
+		}
+	})
+}
+
+func TestLatencySelector(t *testing.T) {
+	labels := model.LabelSet{"namespace": "emojivoto", "direction": "inbound"}
+
+	if selector := latencySelector(labels); selector != `{direction="inbound", namespace="emojivoto"}` {
+		t.Fatalf("Expected the request labels only with the default buckets, got %s", selector)
+	}
+
+	defaultBuckets := pkgprom.RequestDurationBucketsSeconds
+	defer func() {
+		pkgprom.LatencyBucketsMs = nil
+		pkgprom.RequestDurationBucketsSeconds = defaultBuckets
+	}()
+	pkgprom.SetLatencyBuckets([]float64{0.5, 10, 1000})
+
+	expected := `{direction="inbound", namespace="emojivoto", le=~"0\\.5|10|1000|\\+Inf"}`
+	if selector := latencySelector(labels); selector != expected {
+		t.Fatalf("Expected selector %s, got %s", expected, selector)
+	}
+	if selector := latencySelector(model.LabelSet{}); selector != `{le=~"0\\.5|10|1000|\\+Inf"}` {
+		t.Fatalf("Expected only the buckets to be selected, got %s", selector)
+	}
+}
@@ -0,0 +1,195 @@
+package public
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	pkgprom "github.com/linkerd/linkerd2/pkg/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	requestIDHeader = "X-Request-Id"
+
+	// the phases of a request whose time is recorded separately, to tell
+	// whether a slow request is waiting on Prometheus, on the Kubernetes
+	// caches, or on writing the response
+	prometheusPhase    = "prometheus"
+	k8sPhase           = "k8s"
+	serializationPhase = "serialization"
+
+	unknownMethod = "unknown"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "public_api_requests_total",
+			Help: "A counter of the requests to the public API, by method and gRPC status code.",
+		},
+		[]string{"method", "code"},
+	)
+
+	requestDuration = newRequestDurationHistogram()
+
+	requestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "public_api_requests_in_flight",
+			Help: "The number of requests to the public API being served, by method.",
+		},
+		[]string{"method"},
+	)
+
+	requestPhaseDuration = newRequestPhaseDurationHistogram()
+
+	registerMetricsOnce sync.Once
+)
+
+func newRequestDurationHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "public_api_request_duration_seconds",
+			Help:    "A histogram of the latencies of the requests to the public API, by method and gRPC status code.",
+			Buckets: pkgprom.RequestDurationBucketsSeconds,
+		},
+		[]string{"method", "code"},
+	)
+}
+
+func newRequestPhaseDurationHistogram() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "public_api_request_phase_duration_seconds",
+			Help:    "A histogram of the time spent by the requests to the public API in each phase, by method. Concurrent work within a phase is summed.",
+			Buckets: pkgprom.RequestDurationBucketsSeconds,
+		},
+		[]string{"method", "phase"},
+	)
+}
+
+// registerMetrics registers the metrics of the public API with the default
+// Prometheus registry, once for all the servers of the process.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		// the histograms are created again with the latency buckets
+		// configured by the flags, which are parsed after the package is
+		// initialized
+		requestDuration = newRequestDurationHistogram()
+		requestPhaseDuration = newRequestPhaseDurationHistogram()
+		prometheus.MustRegister(requestsTotal, requestDuration, requestsInFlight, requestPhaseDuration)
+	})
+}
+
+// requestTimings accumulates the time spent by a request in each phase.
+type requestTimings struct {
+	sync.Mutex
+	phases map[string]time.Duration
+}
+
+type requestTimingsKey struct{}
+
+func withRequestTimings(ctx context.Context) (context.Context, *requestTimings) {
+	timings := &requestTimings{phases: make(map[string]time.Duration)}
+	return context.WithValue(ctx, requestTimingsKey{}, timings), timings
+}
+
+// observePhase adds the time elapsed since start to the phase of the request
+// of the context. It's a no-op for contexts without request timings, such as
+// in tests calling the gRPC server directly.
+func observePhase(ctx context.Context, phase string, start time.Time) {
+	if timings, ok := ctx.Value(requestTimingsKey{}).(*requestTimings); ok {
+		timings.observe(phase, start)
+	}
+}
+
+func (t *requestTimings) observe(phase string, start time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.phases[phase] += time.Since(start)
+}
+
+func (t *requestTimings) get(phase string) time.Duration {
+	t.Lock()
+	defer t.Unlock()
+	return t.phases[phase]
+}
+
+// instrumentedResponseWriter records the status of the response and the time
+// spent serializing it.
+type instrumentedResponseWriter struct {
+	http.ResponseWriter
+	timings    *requestTimings
+	statusCode int
+	code       codes.Code
+}
+
+func (w *instrumentedResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *instrumentedResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *instrumentedResponseWriter) setError(err error) {
+	w.code = errorCodeOf(err)
+}
+
+// withInstrumentation serves the request with the handler, recording the
+// metrics of the public API method it's for, and logging the time it spent in
+// each phase under its request ID.
+func withInstrumentation(w http.ResponseWriter, req *http.Request, handler func(http.ResponseWriter, *http.Request)) {
+	requestID := req.Header.Get(requestIDHeader)
+	if requestID == "" {
+		requestID = uuid.NewV4().String()
+	}
+	w.Header().Set(requestIDHeader, requestID)
+
+	ctx, timings := withRequestTimings(req.Context())
+	iw := &instrumentedResponseWriter{ResponseWriter: w, timings: timings, code: codes.OK}
+
+	// invalid requests to arbitrary paths are recorded as unknown, so that
+	// they don't create new series
+	method := unknownMethod
+	if req.Method == http.MethodPost {
+		method = strings.TrimPrefix(req.URL.Path, apiRoot+apiPrefix)
+	}
+	requestsInFlight.WithLabelValues(method).Inc()
+
+	start := time.Now()
+	handler(iw, req.WithContext(ctx))
+	elapsed := time.Since(start)
+
+	requestsInFlight.WithLabelValues(method).Dec()
+	if iw.statusCode == http.StatusNotFound {
+		requestsInFlight.DeleteLabelValues(method)
+		method = unknownMethod
+		iw.code = codes.NotFound
+	}
+
+	code := iw.code.String()
+	requestsTotal.WithLabelValues(method, code).Inc()
+	requestDuration.WithLabelValues(method, code).Observe(elapsed.Seconds())
+
+	fields := log.Fields{
+		"request_id": requestID,
+		"method":     method,
+		"code":       code,
+		"duration":   elapsed,
+	}
+	for _, phase := range []string{prometheusPhase, k8sPhase, serializationPhase} {
+		phaseDuration := timings.get(phase)
+		requestPhaseDuration.WithLabelValues(method, phase).Observe(phaseDuration.Seconds())
+		fields[phase+"_duration"] = phaseDuration
+	}
+	log.WithFields(fields).Debug("Served public API request")
+}
@@ -0,0 +1,71 @@
+package public
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func counterValue(t *testing.T, method, code string) float64 {
+	var metric dto.Metric
+	err := requestsTotal.WithLabelValues(method, code).Write(&metric)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestWithInstrumentation(t *testing.T) {
+	t.Run("Records the requests by method and code, with a request ID", func(t *testing.T) {
+		mockGrpcServer := &mockGrpcServer{ResponseToReturn: &pb.VersionInfo{}}
+		handler := &handler{grpcServer: mockGrpcServer}
+
+		payload, err := proto.Marshal(&pb.Empty{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		before := counterValue(t, "Version", "OK")
+
+		req := httptest.NewRequest(http.MethodPost, versionPath, bytes.NewReader(payload))
+		req.Header.Set(requestIDHeader, "some-request-id")
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+
+		if after := counterValue(t, "Version", "OK"); after != before+1 {
+			t.Fatalf("Expected the request to be counted, got %f requests before and %f after", before, after)
+		}
+		if id := rsp.Header().Get(requestIDHeader); id != "some-request-id" {
+			t.Fatalf("Expected the request ID to be returned, got [%s]", id)
+		}
+	})
+
+	t.Run("Records requests to unknown paths as unknown", func(t *testing.T) {
+		handler := &handler{grpcServer: &mockGrpcServer{}}
+
+		before := counterValue(t, unknownMethod, "NotFound")
+
+		req := httptest.NewRequest(http.MethodPost, fullUrlPathFor("Unknown"), nil)
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+
+		if after := counterValue(t, unknownMethod, "NotFound"); after != before+1 {
+			t.Fatalf("Expected the request to be counted, got %f requests before and %f after", before, after)
+		}
+		if rsp.Header().Get(requestIDHeader) == "" {
+			t.Fatal("Expected a request ID to be generated")
+		}
+	})
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	t.Run("Registers the metrics once for all servers", func(t *testing.T) {
+		registerMetrics()
+		registerMetrics()
+	})
+}
@@ -19,8 +19,13 @@ type MockApiClient struct {
 	ListPodsResponseToReturn        *pb.ListPodsResponse
 	StatSummaryResponseToReturn     *pb.StatSummaryResponse
 	SelfCheckResponseToReturn       *healthcheckPb.SelfCheckResponse
+	ResolveDestinationToReturn      *pb.ResolveDestinationResponse
+	TopRoutesResponseToReturn       *pb.TopRoutesResponse
+	EdgesResponseToReturn           *pb.EdgesResponse
+	ListServicesResponseToReturn    *pb.ListServicesResponse
 	Api_TapClientToReturn           pb.Api_TapClient
 	Api_TapByResourceClientToReturn pb.Api_TapByResourceClient
+	Api_StatSummaryStreamToReturn   pb.Api_StatSummaryStreamClient
 }
 
 func (c *MockApiClient) StatSummary(ctx context.Context, in *pb.StatSummaryRequest, opts ...grpc.CallOption) (*pb.StatSummaryResponse, error) {
@@ -47,6 +52,26 @@ func (c *MockApiClient) SelfCheck(ctx context.Context, in *healthcheckPb.SelfChe
 	return c.SelfCheckResponseToReturn, c.ErrorToReturn
 }
 
+func (c *MockApiClient) ResolveDestination(ctx context.Context, in *pb.ResolveDestinationRequest, _ ...grpc.CallOption) (*pb.ResolveDestinationResponse, error) {
+	return c.ResolveDestinationToReturn, c.ErrorToReturn
+}
+
+func (c *MockApiClient) TopRoutes(ctx context.Context, in *pb.TopRoutesRequest, _ ...grpc.CallOption) (*pb.TopRoutesResponse, error) {
+	return c.TopRoutesResponseToReturn, c.ErrorToReturn
+}
+
+func (c *MockApiClient) Edges(ctx context.Context, in *pb.EdgesRequest, _ ...grpc.CallOption) (*pb.EdgesResponse, error) {
+	return c.EdgesResponseToReturn, c.ErrorToReturn
+}
+
+func (c *MockApiClient) ListServices(ctx context.Context, in *pb.ListServicesRequest, _ ...grpc.CallOption) (*pb.ListServicesResponse, error) {
+	return c.ListServicesResponseToReturn, c.ErrorToReturn
+}
+
+func (c *MockApiClient) StatSummaryStream(ctx context.Context, in *pb.StatSummaryStreamRequest, _ ...grpc.CallOption) (pb.Api_StatSummaryStreamClient, error) {
+	return c.Api_StatSummaryStreamToReturn, c.ErrorToReturn
+}
+
 type MockApi_TapClient struct {
 	TapEventsToReturn []pb.TapEvent
 	ErrorsToReturn    []error
@@ -91,12 +116,35 @@ func (a *MockApi_TapByResourceClient) Recv() (*pb.TapEvent, error) {
 	return &eventPopped, errorPopped
 }
 
+type MockApi_StatSummaryStreamClient struct {
+	ResponsesToReturn []*pb.StatSummaryResponse
+	ErrorsToReturn    []error
+	grpc.ClientStream
+}
+
+func (a *MockApi_StatSummaryStreamClient) Recv() (*pb.StatSummaryResponse, error) {
+	var rspPopped *pb.StatSummaryResponse
+	var errorPopped error
+	if len(a.ResponsesToReturn) == 0 && len(a.ErrorsToReturn) == 0 {
+		return nil, io.EOF
+	}
+	if len(a.ResponsesToReturn) != 0 {
+		rspPopped, a.ResponsesToReturn = a.ResponsesToReturn[0], a.ResponsesToReturn[1:]
+	}
+	if len(a.ErrorsToReturn) != 0 {
+		errorPopped, a.ErrorsToReturn = a.ErrorsToReturn[0], a.ErrorsToReturn[1:]
+	}
+
+	return rspPopped, errorPopped
+}
+
 //
 // Prometheus client
 //
 
 type MockProm struct {
 	Res             model.Value
+	Err             error
 	QueriesExecuted []string // expose the queries our Mock Prometheus receives, to test query generation
 	rwLock          sync.Mutex
 }
@@ -112,7 +160,7 @@ func (m *MockProm) Query(ctx context.Context, query string, ts time.Time) (model
 	m.rwLock.Lock()
 	defer m.rwLock.Unlock()
 	m.QueriesExecuted = append(m.QueriesExecuted, query)
-	return m.Res, nil
+	return m.Res, m.Err
 }
 func (m *MockProm) QueryRange(ctx context.Context, query string, r v1.Range) (model.Value, error) {
 	m.rwLock.Lock()
@@ -0,0 +1,115 @@
+package public
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	routeReqQuery             = "sum(increase(route_response_total%s[%s])) by (%s, classification, tls)"
+	routeLatencyQuantileQuery = "histogram_quantile(%s, sum(irate(route_response_latency_ms_bucket%s[%s])) by (le, %s))"
+
+	// the proxies label the requests that matched a route of the
+	// ServiceProfile of their authority with the name of the route
+	routeLabel     = model.LabelName("rt_route")
+	authorityLabel = model.LabelName("dst")
+
+	// DefaultRouteName is the name of the row of the requests that didn't
+	// match any route.
+	DefaultRouteName = "[DEFAULT]"
+)
+
+type routeKey struct {
+	route     string
+	authority string
+}
+
+func (s *grpcServer) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest) (*pb.TopRoutesResponse, error) {
+	resource := req.GetSelector().GetResource()
+	if resource == nil {
+		return topRoutesError(req, "TopRoutes request missing Selector Resource"), nil
+	}
+	if resource.Type == k8s.All {
+		return topRoutesError(req, "resource type 'all' is not supported"), nil
+	}
+
+	reqLabels := promQueryLabels(resource).Merge(promDirectionLabels("inbound"))
+	groupBy := model.LabelNames{routeLabel, authorityLabel}
+
+	// the route series are recorded like the series of StatSummary
+	requestQuery, latencyQuery := routeReqQuery, routeLatencyQuantileQuery
+	if window, err := time.ParseDuration(req.TimeWindow); err == nil && s.useRecordedSeries(ctx, recordedRoutes, resource.Type, window) {
+		requestQuery, latencyQuery = recordedRoutes.queries(resource.Type, window)
+	}
+
+	results, err := s.getPrometheusResults(ctx, requestQuery, latencyQuery, reqLabels, req.TimeWindow, groupBy)
+	if err != nil {
+		return nil, util.GRPCError(err)
+	}
+
+	return &pb.TopRoutesResponse{
+		Response: &pb.TopRoutesResponse_Ok_{
+			Ok: &pb.TopRoutesResponse_Ok{
+				RouteTable: buildRouteTable(results, req.TimeWindow),
+			},
+		},
+	}, nil
+}
+
+func topRoutesError(req *pb.TopRoutesRequest, message string) *pb.TopRoutesResponse {
+	return &pb.TopRoutesResponse{
+		Response: &pb.TopRoutesResponse_Error{
+			Error: &pb.ResourceError{
+				Resource: req.GetSelector().GetResource(),
+				Error:    message,
+			},
+		},
+	}
+}
+
+// buildRouteTable returns a row per route and authority of the results,
+// sorted by authority and then route.
+func buildRouteTable(results []promResult, timeWindow string) *pb.RouteTable {
+	stats := make(map[routeKey]*pb.BasicStats)
+
+	for _, result := range results {
+		for _, sample := range result.vec {
+			key := routeKey{
+				route:     string(sample.Metric[routeLabel]),
+				authority: string(sample.Metric[authorityLabel]),
+			}
+			if key.route == "" {
+				key.route = DefaultRouteName
+			}
+
+			if stats[key] == nil {
+				stats[key] = &pb.BasicStats{}
+			}
+			addSampleToBasicStats(stats[key], result.prom, sample)
+		}
+	}
+
+	rows := make([]*pb.RouteTable_Row, 0)
+	for key, basicStats := range stats {
+		rows = append(rows, &pb.RouteTable_Row{
+			Route:      key.route,
+			Authority:  key.authority,
+			TimeWindow: timeWindow,
+			Stats:      basicStats,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Authority != rows[j].Authority {
+			return rows[i].Authority < rows[j].Authority
+		}
+		return rows[i].Route < rows[j].Route
+	})
+
+	return &pb.RouteTable{Rows: rows}
+}
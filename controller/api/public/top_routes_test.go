@@ -0,0 +1,172 @@
+package public
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	destination "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/prometheus/common/model"
+)
+
+type topRoutesExpected struct {
+	mockPromResponse          model.Value
+	expectedPrometheusQueries []string
+	req                       pb.TopRoutesRequest
+	expectedResponse          pb.TopRoutesResponse
+}
+
+func genRouteSample(route, authority, classification string) *model.Sample {
+	metric := model.Metric{
+		"dst":            model.LabelValue(authority),
+		"classification": model.LabelValue(classification),
+		"tls":            model.LabelValue("true"),
+	}
+	if route != "" {
+		metric["rt_route"] = model.LabelValue(route)
+	}
+
+	return &model.Sample{
+		Metric:    metric,
+		Value:     123,
+		Timestamp: 456,
+	}
+}
+
+func TestTopRoutes(t *testing.T) {
+	t.Run("Successfully performs a query based on resource type", func(t *testing.T) {
+		authority := "books.default.svc.cluster.local:7000"
+
+		expectations := []topRoutesExpected{
+			topRoutesExpected{
+				mockPromResponse: model.Vector{
+					genRouteSample("GET /books", authority, "success"),
+					genRouteSample("", authority, "failure"),
+				},
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(route_response_latency_ms_bucket{deployment="books", direction="inbound", namespace="default"}[1m])) by (le, rt_route, dst))`,
+					`histogram_quantile(0.95, sum(irate(route_response_latency_ms_bucket{deployment="books", direction="inbound", namespace="default"}[1m])) by (le, rt_route, dst))`,
+					`histogram_quantile(0.99, sum(irate(route_response_latency_ms_bucket{deployment="books", direction="inbound", namespace="default"}[1m])) by (le, rt_route, dst))`,
+					`sum(increase(route_response_total{deployment="books", direction="inbound", namespace="default"}[1m])) by (rt_route, dst, classification, tls)`,
+				},
+				req: pb.TopRoutesRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "default",
+							Type:      "deployment",
+							Name:      "books",
+						},
+					},
+					TimeWindow: "1m",
+				},
+				expectedResponse: pb.TopRoutesResponse{
+					Response: &pb.TopRoutesResponse_Ok_{
+						Ok: &pb.TopRoutesResponse_Ok{
+							RouteTable: &pb.RouteTable{
+								Rows: []*pb.RouteTable_Row{
+									&pb.RouteTable_Row{
+										Route:      "GET /books",
+										Authority:  authority,
+										TimeWindow: "1m",
+										Stats: &pb.BasicStats{
+											SuccessCount:    123,
+											LatencyMsP50:    123,
+											LatencyMsP95:    123,
+											LatencyMsP99:    123,
+											TlsRequestCount: 123,
+										},
+									},
+									&pb.RouteTable_Row{
+										Route:      DefaultRouteName,
+										Authority:  authority,
+										TimeWindow: "1m",
+										Stats: &pb.BasicStats{
+											FailureCount:    123,
+											LatencyMsP50:    123,
+											LatencyMsP95:    123,
+											LatencyMsP99:    123,
+											TlsRequestCount: 123,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for _, exp := range expectations {
+			k8sAPI, err := k8s.NewFakeAPI()
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+
+			mockProm := &MockProm{Res: exp.mockPromResponse}
+			fakeGrpcServer := newGrpcServer(
+				mockProm,
+				tap.NewTapClient(nil),
+				destination.NewDestinationClient(nil),
+				k8sAPI,
+				"linkerd",
+				[]string{},
+			)
+
+			rsp, err := fakeGrpcServer.TopRoutes(context.TODO(), &exp.req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			sort.Strings(exp.expectedPrometheusQueries)
+			sort.Strings(mockProm.QueriesExecuted)
+			if !reflect.DeepEqual(exp.expectedPrometheusQueries, mockProm.QueriesExecuted) {
+				t.Fatalf("Prometheus queries incorrect. \nExpected:\n%+v \nGot:\n%+v",
+					exp.expectedPrometheusQueries, mockProm.QueriesExecuted)
+			}
+
+			if !proto.Equal(&exp.expectedResponse, rsp) {
+				t.Fatalf("Expected: %+v\n Got: %+v", &exp.expectedResponse, rsp)
+			}
+		}
+	})
+
+	t.Run("Returns an error for invalid resources", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI()
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+
+		fakeGrpcServer := newGrpcServer(
+			&MockProm{Res: model.Vector{}},
+			tap.NewTapClient(nil),
+			destination.NewDestinationClient(nil),
+			k8sAPI,
+			"linkerd",
+			[]string{},
+		)
+
+		invalidRequests := []pb.TopRoutesRequest{
+			pb.TopRoutesRequest{},
+			pb.TopRoutesRequest{
+				Selector: &pb.ResourceSelection{
+					Resource: &pb.Resource{Type: "all"},
+				},
+			},
+		}
+
+		for _, req := range invalidRequests {
+			rsp, err := fakeGrpcServer.TopRoutes(context.TODO(), &req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if rsp.GetError() == nil {
+				t.Fatalf("Expected a resource error for %+v, got: %+v", req, rsp)
+			}
+		}
+	})
+}
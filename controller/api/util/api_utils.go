@@ -3,12 +3,15 @@ package util
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/addr"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"k8s.io/api/core/v1"
@@ -61,16 +64,27 @@ type StatSummaryRequestParams struct {
 	AllNamespaces bool
 }
 
+type TopRoutesRequestParams struct {
+	TimeWindow   string
+	Namespace    string
+	ResourceType string
+	ResourceName string
+}
+
 type TapRequestParams struct {
-	Resource    string
-	Namespace   string
-	ToResource  string
-	ToNamespace string
-	MaxRps      float32
-	Scheme      string
-	Method      string
-	Authority   string
-	Path        string
+	Resource      string
+	Namespace     string
+	ToResource    string
+	ToNamespace   string
+	FromResource  string
+	FromNamespace string
+	MaxRps        float32
+	Duration      time.Duration
+	Scheme        string
+	Method        string
+	Authority     string
+	Path          string
+	Status        string
 }
 
 // GRPCError generates a gRPC error code, as defined in
@@ -191,6 +205,79 @@ func BuildStatSummaryRequest(p StatSummaryRequestParams) (*pb.StatSummaryRequest
 	return statRequest, nil
 }
 
+// GetRequestRate returns the requests per second of a row of a StatSummary
+// response, over the row's time window.
+func GetRequestRate(r *pb.StatTable_PodGroup_Row) float64 {
+	success := r.Stats.SuccessCount
+	failure := r.Stats.FailureCount
+	windowLength, err := time.ParseDuration(r.TimeWindow)
+	if err != nil {
+		log.Error(err.Error())
+		return 0.0
+	}
+	return float64(success+failure) / windowLength.Seconds()
+}
+
+// GetSuccessRate returns the fraction of the requests of a row of a
+// StatSummary response that succeeded.
+func GetSuccessRate(r *pb.StatTable_PodGroup_Row) float64 {
+	success := r.Stats.SuccessCount
+	failure := r.Stats.FailureCount
+
+	if success+failure == 0 {
+		return 0.0
+	}
+	return float64(success) / float64(success+failure)
+}
+
+// GetPercentOfRequests returns the fraction of the requests of a row of a
+// StatSummary response that count represents, e.g. the requests of a given TLS
+// status.
+func GetPercentOfRequests(r *pb.StatTable_PodGroup_Row, count uint64) float64 {
+	reqTotal := r.Stats.SuccessCount + r.Stats.FailureCount
+	if reqTotal == 0 {
+		return 0.0
+	}
+	return float64(count) / float64(reqTotal)
+}
+
+// BuildTopRoutesRequest builds a TopRoutes request for the resource, in the
+// default namespace and time window if none are given.
+func BuildTopRoutesRequest(p TopRoutesRequestParams) (*pb.TopRoutesRequest, error) {
+	window := defaultMetricTimeWindow
+	if p.TimeWindow != "" {
+		_, err := time.ParseDuration(p.TimeWindow)
+		if err != nil {
+			return nil, err
+		}
+		window = p.TimeWindow
+	}
+
+	targetNamespace := p.Namespace
+	if targetNamespace == "" {
+		targetNamespace = v1.NamespaceDefault
+	}
+
+	resourceType, err := k8s.CanonicalResourceNameFromFriendlyName(p.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+	if resourceType == k8s.All {
+		return nil, errors.New("routes cannot be retrieved for all resource types")
+	}
+
+	return &pb.TopRoutesRequest{
+		Selector: &pb.ResourceSelection{
+			Resource: &pb.Resource{
+				Namespace: targetNamespace,
+				Name:      p.ResourceName,
+				Type:      resourceType,
+			},
+		},
+		TimeWindow: window,
+	}, nil
+}
+
 // An authority can only receive traffic, not send it, so it can't be a --from
 func validateFromResourceType(resourceType string) (string, error) {
 	name, err := k8s.CanonicalResourceNameFromFriendlyName(resourceType)
@@ -204,44 +291,21 @@ func validateFromResourceType(resourceType string) (string, error) {
 }
 
 // BuildResource parses input strings, typically from CLI flags, to build a
-// Resource object for use in the protobuf API.
+// Resource object for use in the protobuf API. The strings are parsed by
+// k8s.ParseResource.
 func BuildResource(namespace string, args ...string) (pb.Resource, error) {
-	switch len(args) {
-	case 0:
-		return pb.Resource{}, errors.New("No resource arguments provided")
-	case 1:
-		elems := strings.Split(args[0], "/")
-		switch len(elems) {
-		case 1:
-			// --namespace my-ns deploy
-			return buildResource(namespace, elems[0], "")
-		case 2:
-			// --namespace my-ns deploy/foo
-			return buildResource(namespace, elems[0], elems[1])
-		default:
-			return pb.Resource{}, errors.New("Invalid resource string: " + args[0])
-		}
-	case 2:
-		// --namespace my-ns deploy foo
-		return buildResource(namespace, args[0], args[1])
-	default:
-		return pb.Resource{}, errors.New("Too many arguments provided for resource: " + strings.Join(args, "/"))
-	}
-}
-
-func buildResource(namespace string, resType string, name string) (pb.Resource, error) {
-	canonicalType, err := k8s.CanonicalResourceNameFromFriendlyName(resType)
+	resType, name, err := k8s.ParseResource(args...)
 	if err != nil {
 		return pb.Resource{}, err
 	}
-	if canonicalType == k8s.Namespace {
+	if resType == k8s.Namespace {
 		// ignore --namespace flags if type is namespace
 		namespace = ""
 	}
 
 	return pb.Resource{
 		Namespace: namespace,
-		Type:      canonicalType,
+		Type:      resType,
 		Name:      name,
 	}, nil
 }
@@ -263,7 +327,7 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 			return nil, fmt.Errorf("destination resource invalid: %s", err)
 		}
 		if !contains(ValidDestinations, destination.Type) {
-			return nil, fmt.Errorf("unsupported resource type [%s]", target.Type)
+			return nil, fmt.Errorf("unsupported resource type [%s]", destination.Type)
 		}
 
 		match := pb.TapByResourceRequest_Match{
@@ -276,6 +340,27 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 		matches = append(matches, &match)
 	}
 
+	if params.FromResource != "" {
+		source, err := BuildResource(params.FromNamespace, params.FromResource)
+		if err != nil {
+			return nil, fmt.Errorf("source resource invalid: %s", err)
+		}
+		// sources are matched against the labels of the pods sending requests,
+		// which don't identify their authority
+		if !contains(ValidTargets, source.Type) || source.Type == k8s.Authority {
+			return nil, fmt.Errorf("unsupported resource type [%s]", source.Type)
+		}
+
+		match := pb.TapByResourceRequest_Match{
+			Match: &pb.TapByResourceRequest_Match_Sources{
+				Sources: &pb.ResourceSelection{
+					Resource: &source,
+				},
+			},
+		}
+		matches = append(matches, &match)
+	}
+
 	if params.Scheme != "" {
 		match := buildMatchHTTP(&pb.TapByResourceRequest_Match_Http{
 			Match: &pb.TapByResourceRequest_Match_Http_Scheme{Scheme: params.Scheme},
@@ -300,8 +385,18 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 		})
 		matches = append(matches, &match)
 	}
+	if params.Status != "" {
+		statusRange, err := BuildStatusRange(params.Status)
+		if err != nil {
+			return nil, err
+		}
+		match := buildMatchHTTP(&pb.TapByResourceRequest_Match_Http{
+			Match: &pb.TapByResourceRequest_Match_Http_Status{Status: statusRange},
+		})
+		matches = append(matches, &match)
+	}
 
-	return &pb.TapByResourceRequest{
+	req := &pb.TapByResourceRequest{
 		Target: &pb.ResourceSelection{
 			Resource: &target,
 		},
@@ -313,7 +408,58 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 				},
 			},
 		},
-	}, nil
+	}
+
+	if params.Duration < 0 {
+		return nil, fmt.Errorf("invalid duration [%s]: must not be negative", params.Duration)
+	}
+	if params.Duration > 0 {
+		req.Duration = ptypes.DurationProto(params.Duration)
+	}
+
+	return req, nil
+}
+
+// BuildStatusRange parses an HTTP response status filter, typically from a CLI
+// flag. It accepts a single status code ("404"), a class of status codes
+// ("5xx"), or an inclusive range of status codes ("500-504").
+func BuildStatusRange(s string) (*pb.TapByResourceRequest_Match_Http_StatusRange, error) {
+	invalid := fmt.Errorf("invalid status filter [%s]: must be a status code, a class like 5xx, or a range like 500-504", s)
+
+	parseCode := func(code string) (uint32, error) {
+		n, err := strconv.ParseUint(code, 10, 32)
+		if err != nil || n < 100 || n > 599 {
+			return 0, invalid
+		}
+		return uint32(n), nil
+	}
+
+	lower := strings.ToLower(s)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") {
+		class, err := parseCode(lower[:1] + "00")
+		if err != nil {
+			return nil, invalid
+		}
+		return &pb.TapByResourceRequest_Match_Http_StatusRange{Min: class, Max: class + 99}, nil
+	}
+
+	bounds := strings.SplitN(s, "-", 2)
+	min, err := parseCode(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	max := min
+	if len(bounds) == 2 {
+		max, err = parseCode(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, invalid
+		}
+	}
+
+	return &pb.TapByResourceRequest_Match_Http_StatusRange{Min: min, Max: max}, nil
 }
 
 func buildMatchHTTP(match *pb.TapByResourceRequest_Match_Http) pb.TapByResourceRequest_Match {
@@ -400,29 +546,51 @@ func (p *peer) tlsStatus() string {
 	return p.labels["tls"]
 }
 
+func (p *peer) expectedTLSIdentity() string {
+	return p.labels[k8s.ExpectedTLSIdentityLabel]
+}
+
+// TapEventTLS returns whether the tapped request was sent over mTLS, as the
+// TLS status reported by the proxy (e.g. "true" or "no_identity"), along with
+// the TLS identity the proxy's peer is expected to have, if it's known. The
+// peer is the source of inbound requests and the destination of outbound
+// ones.
+func TapEventTLS(event *pb.TapEvent) (string, string) {
+	var p peer
+	switch event.GetProxyDirection() {
+	case pb.TapEvent_INBOUND:
+		p = src(event)
+	case pb.TapEvent_OUTBOUND:
+		p = dst(event)
+	default:
+		// Too old for TLS.
+		return "", ""
+	}
+	return p.tlsStatus(), p.expectedTLSIdentity()
+}
+
 func RenderTapEvent(event *pb.TapEvent, resource string) string {
 	dst := dst(event)
 	src := src(event)
 
 	proxy := "???"
-	tls := ""
 	switch event.GetProxyDirection() {
 	case pb.TapEvent_INBOUND:
 		proxy = "in " // A space is added so it aligns with `out`.
-		tls = src.tlsStatus()
 	case pb.TapEvent_OUTBOUND:
 		proxy = "out"
-		tls = dst.tlsStatus()
-	default:
-		// Too old for TLS.
 	}
 
+	tls, identity := TapEventTLS(event)
 	flow := fmt.Sprintf("proxy=%s %s %s tls=%s",
 		proxy,
 		src.formatAddr(),
 		dst.formatAddr(),
 		tls,
 	)
+	if identity != "" {
+		flow += fmt.Sprintf(" expected-peer-identity=%s", identity)
+	}
 
 	resources := ""
 	if resource != "" {
@@ -456,38 +624,46 @@ func RenderTapEvent(event *pb.TapEvent, resource string) string {
 		)
 
 	case *pb.TapEvent_Http_ResponseEnd_:
+		classification := ""
+		if c := ev.ResponseEnd.GetClassification(); c != pb.TapEvent_Http_ResponseEnd_UNCLASSIFIED {
+			classification = fmt.Sprintf(" classification=%s", strings.ToLower(c.String()))
+		}
+
 		switch eos := ev.ResponseEnd.GetEos().GetEnd().(type) {
 		case *pb.Eos_GrpcStatusCode:
 			return fmt.Sprintf(
-				"end id=%d:%d %s grpc-status=%s duration=%dµs response-length=%dB%s",
+				"end id=%d:%d %s grpc-status=%s duration=%dµs response-length=%dB%s%s",
 				ev.ResponseEnd.GetId().GetBase(),
 				ev.ResponseEnd.GetId().GetStream(),
 				flow,
 				codes.Code(eos.GrpcStatusCode),
 				ev.ResponseEnd.GetSinceResponseInit().GetNanos()/1000,
 				ev.ResponseEnd.GetResponseBytes(),
+				classification,
 				resources,
 			)
 
 		case *pb.Eos_ResetErrorCode:
 			return fmt.Sprintf(
-				"end id=%d:%d %s reset-error=%+v duration=%dµs response-length=%dB%s",
+				"end id=%d:%d %s reset-error=%+v duration=%dµs response-length=%dB%s%s",
 				ev.ResponseEnd.GetId().GetBase(),
 				ev.ResponseEnd.GetId().GetStream(),
 				flow,
 				eos.ResetErrorCode,
 				ev.ResponseEnd.GetSinceResponseInit().GetNanos()/1000,
 				ev.ResponseEnd.GetResponseBytes(),
+				classification,
 				resources,
 			)
 
 		default:
-			return fmt.Sprintf("end id=%d:%d %s duration=%dµs response-length=%dB%s",
+			return fmt.Sprintf("end id=%d:%d %s duration=%dµs response-length=%dB%s%s",
 				ev.ResponseEnd.GetId().GetBase(),
 				ev.ResponseEnd.GetId().GetStream(),
 				flow,
 				ev.ResponseEnd.GetSinceResponseInit().GetNanos()/1000,
 				ev.ResponseEnd.GetResponseBytes(),
+				classification,
 				resources,
 			)
 		}
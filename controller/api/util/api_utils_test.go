@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
@@ -129,6 +130,151 @@ func TestBuildStatSummaryRequest(t *testing.T) {
 	})
 }
 
+func TestBuildTopRoutesRequest(t *testing.T) {
+	t.Run("Builds a request for the resource", func(t *testing.T) {
+		req, err := BuildTopRoutesRequest(
+			TopRoutesRequestParams{
+				ResourceType: "deploy",
+				ResourceName: "books",
+			},
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error from BuildTopRoutesRequest: %s", err)
+		}
+
+		expected := &pb.Resource{
+			Namespace: "default",
+			Type:      k8s.Deployment,
+			Name:      "books",
+		}
+		if !reflect.DeepEqual(req.Selector.Resource, expected) {
+			t.Fatalf("Unexpected resource from BuildTopRoutesRequest: %+v", req.Selector.Resource)
+		}
+		if req.TimeWindow != defaultMetricTimeWindow {
+			t.Fatalf("Unexpected TimeWindow from BuildTopRoutesRequest: %s", req.TimeWindow)
+		}
+	})
+
+	t.Run("Rejects invalid requests", func(t *testing.T) {
+		expectations := map[string]TopRoutesRequestParams{
+			"time: missing unit in duration 1":                               TopRoutesRequestParams{TimeWindow: "1", ResourceType: k8s.Deployment},
+			"cannot find Kubernetes canonical name from friendly name [foo]": TopRoutesRequestParams{ResourceType: "foo"},
+			"routes cannot be retrieved for all resource types":              TopRoutesRequestParams{ResourceType: k8s.All},
+		}
+
+		for msg, params := range expectations {
+			_, err := BuildTopRoutesRequest(params)
+			if err == nil {
+				t.Fatalf("BuildTopRoutesRequest(%+v) unexpectedly succeeded, should have returned %s", params, msg)
+			}
+			if err.Error() != msg {
+				t.Fatalf("BuildTopRoutesRequest(%+v) should have returned: %s but got unexpected message: %s", params, msg, err)
+			}
+		}
+	})
+}
+
+func TestBuildTapByResourceRequest(t *testing.T) {
+	t.Run("Builds source and destination matches", func(t *testing.T) {
+		req, err := BuildTapByResourceRequest(TapRequestParams{
+			Resource:      "deploy/web",
+			Namespace:     "emojivoto",
+			ToResource:    "deploy/voting",
+			FromResource:  "deploy/vote-bot",
+			FromNamespace: "bots",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		matches := req.GetMatch().GetAll().GetMatches()
+		if len(matches) != 2 {
+			t.Fatalf("Expected 2 matches, got %d", len(matches))
+		}
+
+		expectedDst := &pb.Resource{Namespace: "emojivoto", Type: k8s.Deployment, Name: "voting"}
+		if dst := matches[0].GetDestinations().GetResource(); !reflect.DeepEqual(dst, expectedDst) {
+			t.Fatalf("Expected destination [%+v] but was [%+v]", expectedDst, dst)
+		}
+
+		expectedSrc := &pb.Resource{Namespace: "bots", Type: k8s.Deployment, Name: "vote-bot"}
+		if src := matches[1].GetSources().GetResource(); !reflect.DeepEqual(src, expectedSrc) {
+			t.Fatalf("Expected source [%+v] but was [%+v]", expectedSrc, src)
+		}
+	})
+
+	t.Run("Sets the duration of the tap", func(t *testing.T) {
+		req, err := BuildTapByResourceRequest(TapRequestParams{Resource: "deploy/web", Duration: 30 * time.Second})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if req.GetDuration().GetSeconds() != 30 {
+			t.Fatalf("Expected a 30s duration, got %v", req.GetDuration())
+		}
+
+		req, err = BuildTapByResourceRequest(TapRequestParams{Resource: "deploy/web"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if req.GetDuration() != nil {
+			t.Fatalf("Expected no duration, got %v", req.GetDuration())
+		}
+
+		_, err = BuildTapByResourceRequest(TapRequestParams{Resource: "deploy/web", Duration: -time.Second})
+		if err == nil {
+			t.Fatal("Expected a negative duration to be rejected")
+		}
+	})
+
+	t.Run("Rejects unsupported source and destination types", func(t *testing.T) {
+		expectations := map[TapRequestParams]string{
+			TapRequestParams{Resource: "deploy/web", ToResource: "au/foo.com"}:   "unsupported resource type [authority]",
+			TapRequestParams{Resource: "deploy/web", FromResource: "au/foo.com"}: "unsupported resource type [authority]",
+			TapRequestParams{Resource: "deploy/web", FromResource: "svc/web"}:    "unsupported resource type [service]",
+		}
+
+		for params, msg := range expectations {
+			_, err := BuildTapByResourceRequest(params)
+			if err == nil {
+				t.Fatalf("BuildTapByResourceRequest(%+v) unexpectedly succeeded, should have returned %s", params, msg)
+			}
+			if err.Error() != msg {
+				t.Fatalf("BuildTapByResourceRequest(%+v) should have returned: %s but got unexpected message: %s", params, msg, err)
+			}
+		}
+	})
+}
+
+func TestBuildStatusRange(t *testing.T) {
+	t.Run("Parses valid status filters", func(t *testing.T) {
+		expectations := map[string][2]uint32{
+			"404":     {404, 404},
+			"5xx":     {500, 599},
+			"2XX":     {200, 299},
+			"500-504": {500, 504},
+		}
+
+		for input, expected := range expectations {
+			statusRange, err := BuildStatusRange(input)
+			if err != nil {
+				t.Fatalf("Unexpected error from BuildStatusRange [%s]: %s", input, err)
+			}
+			if statusRange.Min != expected[0] || statusRange.Max != expected[1] {
+				t.Fatalf("BuildStatusRange(%s) should have returned %d-%d but got %d-%d", input, expected[0], expected[1], statusRange.Min, statusRange.Max)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid status filters", func(t *testing.T) {
+		for _, input := range []string{"", "foo", "42", "9xx", "504-500", "500-", "500-600"} {
+			_, err := BuildStatusRange(input)
+			if err == nil {
+				t.Fatalf("BuildStatusRange(%s) unexpectedly succeeded", input)
+			}
+		}
+	})
+}
+
 func TestBuildResource(t *testing.T) {
 	type resourceExp struct {
 		namespace string
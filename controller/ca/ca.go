@@ -1,17 +1,20 @@
 package ca
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"math/big"
+	"sync"
 	"time"
 )
 
-// Issuing certificates concurrently is not supported.
 type CA struct {
 	// validity is the duration for which issued certificates are valid. This
 	// is approximately cert.NotAfter - cert.NotBefore with some additional
@@ -27,26 +30,36 @@ type CA struct {
 	// more than this allowance in either direction.
 	clockSkewAllocance time.Duration
 
+	// The issuer's credentials may be replaced while certificates are being
+	// issued, when they're maintained by an external system, so they're
+	// guarded by credentialsMutex.
+	credentialsMutex sync.RWMutex
+
 	// The CA's private key.
 	privateKey *ecdsa.PrivateKey
 
 	// The CA's certificate.
 	root *x509.Certificate
 
-	// The PEM X.509 encoding of `root`
+	// The PEM X.509 encoding of the trust anchors that `root` chains to,
+	// followed by `root` and its chain unless it's a trust anchor itself.
 	rootPEM string
-
-	// nextSerialNumber is the serial number of the next certificate to issue.
-	// Serial numbers must not be reused.
-	//
-	// It is assumed there is only one instance of CA and it is assumed that a
-	// given CA object isn't requested to issue certificates concurrently.
-	//
-	// For now we do not attempt to meet CABForum requirements (e.g. regarding
-	// randomness).
-	nextSerialNumber uint64
 }
 
+// Issued certificates are renewed once renewalRatio of their lifetime has
+// elapsed, well before they expire, so that there's plenty of time to retry
+// failed renewals. A certificate that still hasn't been renewed once
+// renewalFailureRatio of its lifetime has elapsed is within its
+// renewal-failure window, and is about to expire.
+const (
+	renewalRatio        = 2.0 / 3.0
+	renewalFailureRatio = 5.0 / 6.0
+)
+
+// serialNumberLimit is the exclusive upper bound of the serial numbers of
+// issued certificates, which are at most 20 octets long.
+var serialNumberLimit = new(big.Int).Lsh(big.NewInt(1), 128)
+
 type CertificateAndPrivateKey struct {
 	// The ASN.1 DER-encoded (binary, not PEM) certificate.
 	Certificate []byte
@@ -55,32 +68,21 @@ type CertificateAndPrivateKey struct {
 	PrivateKey []byte
 }
 
-// NewCA is the only way to create a CA.
+// NewCA creates a CA with a new self-signed certificate, which is its own
+// trust anchor.
 func NewCA() (*CA, error) {
-	// Initially all certificates will be valid for one year. TODO: Shorten the
-	// validity duration of CA and end-entity certificates downward.
-	validity := (24 * 365) * time.Hour
-
-	// Allow half a day of clock skew. TODO: decrease the default value of this
-	// and make it tunable. TODO: Reconsider how this interacts with the
-	// similar logic in the webpki verifier; since both are trying to account
-	// for clock skew, there is somewhat of an over-correction.
-	clockSkewAllocance := 12 * time.Hour
+	ca := newCA()
 
 	privateKey, err := generateKeyPair()
 	if err != nil {
 		return nil, err
 	}
 
-	ca := CA{
-		validity:           validity,
-		clockSkewAllocance: clockSkewAllocance,
-		privateKey:         privateKey,
-		nextSerialNumber:   1,
+	template, err := ca.createTemplate(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
 	}
 
-	template := ca.createTemplate(&ca.privateKey.PublicKey)
-
 	template.Subject = pkix.Name{CommonName: "Cluster-local Managed Pod CA"}
 
 	// basicConstraints.cA = true
@@ -98,18 +100,246 @@ func NewCA() (*CA, error) {
 	if err != nil {
 		return nil, err
 	}
-
+	ca.privateKey = privateKey
 	ca.rootPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.root.Raw}))
 
-	return &ca, nil
+	return ca, nil
+}
+
+// NewIssuerCA creates a CA that issues certificates with the given issuer
+// credentials, such as the ones maintained by an external system like
+// cert-manager, instead of generating its own. See SetIssuer.
+func NewIssuerCA(issuerCertificatePEM, issuerKeyPEM, trustAnchorsPEM string) (*CA, error) {
+	ca := newCA()
+	if err := ca.SetIssuer(issuerCertificatePEM, issuerKeyPEM, trustAnchorsPEM); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+func newCA() *CA {
+	// Initially all certificates will be valid for one year. TODO: Shorten the
+	// validity duration of CA and end-entity certificates downward.
+	validity := (24 * 365) * time.Hour
+
+	// Allow half a day of clock skew. TODO: decrease the default value of this
+	// and make it tunable. TODO: Reconsider how this interacts with the
+	// similar logic in the webpki verifier; since both are trying to account
+	// for clock skew, there is somewhat of an over-correction.
+	clockSkewAllocance := 12 * time.Hour
+
+	return &CA{
+		validity:           validity,
+		clockSkewAllocance: clockSkewAllocance,
+	}
+}
+
+// SetIssuer replaces the credentials that the CA issues certificates with.
+// The PEM-encoded issuer certificate may be followed by the certificates that
+// chain it to one of the PEM-encoded trust anchors; if there are no trust
+// anchors, the issuer certificate must be self-signed, and is its own trust
+// anchor. Only ECDSA P-256 issuer keys are supported.
+//
+// The certificates that were issued with the previous credentials should be
+// reissued, since the previous issuer may no longer be distributed along with
+// the trust anchors.
+func (ca *CA) SetIssuer(issuerCertificatePEM, issuerKeyPEM, trustAnchorsPEM string) error {
+	chain, err := parseCertificatesPEM(issuerCertificatePEM)
+	if err != nil {
+		return fmt.Errorf("invalid issuer certificate: %s", err)
+	}
+	issuer := chain[0]
+
+	privateKey, err := parsePrivateKeyPEM(issuerKeyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid issuer key: %s", err)
+	}
+	publicKey, ok := issuer.PublicKey.(*ecdsa.PublicKey)
+	if !ok || publicKey.X.Cmp(privateKey.X) != 0 || publicKey.Y.Cmp(privateKey.Y) != 0 {
+		return errors.New("the issuer key doesn't match the issuer certificate")
+	}
+	if !issuer.IsCA {
+		return errors.New("the issuer certificate isn't a CA certificate")
+	}
+
+	anchors := chain[:1]
+	if trustAnchorsPEM != "" {
+		anchors, err = parseCertificatesPEM(trustAnchorsPEM)
+		if err != nil {
+			return fmt.Errorf("invalid trust anchors: %s", err)
+		}
+	}
+	if err := verifyIssuer(chain, anchors); err != nil {
+		return err
+	}
+
+	// the certificates issued to pod owners don't carry the issuer's chain,
+	// so the chain is distributed along with the trust anchors, unless the
+	// issuer is a trust anchor itself
+	rootPEM := ""
+	for _, crt := range anchors {
+		rootPEM += string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw}))
+	}
+	if !containsCertificate(anchors, issuer) {
+		for _, crt := range chain {
+			rootPEM += string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw}))
+		}
+	}
+
+	ca.credentialsMutex.Lock()
+	defer ca.credentialsMutex.Unlock()
+	ca.root = issuer
+	ca.privateKey = privateKey
+	ca.rootPEM = rootPEM
+	return nil
 }
 
 // TrustAnchorDER returns the PEM-encoded X.509 certificate of the trust anchor
 // (root CA).
+// hasIssuer returns whether the CA issues certificates with the PEM-encoded
+// issuer certificate and key.
+func (ca *CA) hasIssuer(issuerCertificatePEM, issuerKeyPEM string) bool {
+	chain, err := parseCertificatesPEM(issuerCertificatePEM)
+	if err != nil {
+		return false
+	}
+	privateKey, err := parsePrivateKeyPEM(issuerKeyPEM)
+	if err != nil {
+		return false
+	}
+
+	ca.credentialsMutex.RLock()
+	defer ca.credentialsMutex.RUnlock()
+	return chain[0].Equal(ca.root) && privateKey.D.Cmp(ca.privateKey.D) == 0
+}
+
 func (ca *CA) TrustAnchorPEM() string {
+	ca.credentialsMutex.RLock()
+	defer ca.credentialsMutex.RUnlock()
 	return ca.rootPEM
 }
 
+// Certificate returns the CA's own certificate.
+func (ca *CA) Certificate() *x509.Certificate {
+	ca.credentialsMutex.RLock()
+	defer ca.credentialsMutex.RUnlock()
+	return ca.root
+}
+
+// RenewalTime returns the time at which the certificate should be renewed.
+func RenewalTime(crt *x509.Certificate) time.Time {
+	return lifetimeFraction(crt, renewalRatio)
+}
+
+// RenewalFailureTime returns the time at which the certificate enters its
+// renewal-failure window: if it still hasn't been renewed by then, its
+// renewal has been failing for a sixth of its lifetime.
+func RenewalFailureTime(crt *x509.Certificate) time.Time {
+	return lifetimeFraction(crt, renewalFailureRatio)
+}
+
+func lifetimeFraction(crt *x509.Certificate, ratio float64) time.Time {
+	lifetime := crt.NotAfter.Sub(crt.NotBefore)
+	return crt.NotBefore.Add(time.Duration(float64(lifetime) * ratio))
+}
+
+// ValidateTrustAnchorsPEM checks that trustAnchorsPEM is a non-empty bundle of
+// PEM-encoded X.509 certificates, such as the trust anchors of another trust
+// domain.
+func ValidateTrustAnchorsPEM(trustAnchorsPEM string) error {
+	_, err := parseCertificatesPEM(trustAnchorsPEM)
+	return err
+}
+
+// parseCertificatesPEM parses a non-empty bundle of PEM-encoded X.509
+// certificates.
+func parseCertificatesPEM(certificatesPEM string) ([]*x509.Certificate, error) {
+	rest := []byte(certificatesPEM)
+	certificates := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %s", block.Type)
+		}
+		crt, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, crt)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certificates, nil
+}
+
+// parsePrivateKeyPEM parses a PEM-encoded ECDSA P-256 private key, in either
+// the SEC 1 or the PKCS#8 encoding.
+func parsePrivateKeyPEM(keyPEM string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block of type %s, only ECDSA P-256 keys are supported", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("unsupported key type, only ECDSA P-256 keys are supported")
+	}
+	return ecKey, nil
+}
+
+// verifyIssuer checks that the first certificate of the chain chains to one of
+// the trust anchors through the rest of the chain, and that it's currently
+// valid.
+func verifyIssuer(chain, anchors []*x509.Certificate) error {
+	roots := x509.NewCertPool()
+	for _, crt := range anchors {
+		roots.AddCert(crt)
+	}
+	intermediates := x509.NewCertPool()
+	for _, crt := range chain[1:] {
+		intermediates.AddCert(crt)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("the issuer certificate doesn't chain to the trust anchors: %s", err)
+	}
+	return nil
+}
+
+func containsCertificate(certificates []*x509.Certificate, crt *x509.Certificate) bool {
+	for _, c := range certificates {
+		if c.Equal(crt) {
+			return true
+		}
+	}
+	return false
+}
+
 // IssueEndEntityCertificate creates a new certificate that is valid for the
 // given DNS name, generating a new keypair for it.
 func (ca *CA) IssueEndEntityCertificate(dnsName string) (*CertificateAndPrivateKey, error) {
@@ -122,9 +352,12 @@ func (ca *CA) IssueEndEntityCertificate(dnsName string) (*CertificateAndPrivateK
 		return nil, err
 	}
 
-	template := ca.createTemplate(&privateKey.PublicKey)
+	template, err := ca.createTemplate(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
 	template.DNSNames = []string{dnsName}
-	crt, err := x509.CreateCertificate(rand.Reader, &template, ca.root, &privateKey.PublicKey, ca.privateKey)
+	crt, err := ca.sign(&template)
 	if err != nil {
 		return nil, err
 	}
@@ -134,10 +367,46 @@ func (ca *CA) IssueEndEntityCertificate(dnsName string) (*CertificateAndPrivateK
 	}, nil
 }
 
+// IssueCertificateForRequest creates a new certificate that is valid for the
+// given DNS name, for the public key of a certificate signing request, and
+// returns it ASN.1 DER-encoded. Unlike the certificates issued by
+// IssueEndEntityCertificate, the certificate expires after validity, so that
+// the requester has to renew it. The caller is responsible for checking that
+// the request is authorized to be issued a certificate for the DNS name.
+func (ca *CA) IssueCertificateForRequest(dnsName string, csr *x509.CertificateRequest, validity time.Duration) ([]byte, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid certificate signing request signature: %s", err)
+	}
+
+	template, err := ca.createTemplate(csr.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	template.DNSNames = []string{dnsName}
+	// NotBefore is already set back by the clock skew allowance
+	template.NotAfter = template.NotBefore.Add(2 * ca.clockSkewAllocance).Add(validity)
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	return ca.sign(&template)
+}
+
+// sign creates the certificate of the template, signed by the issuer. The
+// certificate doesn't outlive the issuer, which would make it invalid before
+// it expires.
+func (ca *CA) sign(template *x509.Certificate) ([]byte, error) {
+	ca.credentialsMutex.RLock()
+	defer ca.credentialsMutex.RUnlock()
+
+	if template.NotAfter.After(ca.root.NotAfter) {
+		template.NotAfter = ca.root.NotAfter
+	}
+	return x509.CreateCertificate(rand.Reader, template, ca.root, template.PublicKey, ca.privateKey)
+}
+
 // createTemplate returns a certificate template for a non-CA certificate with
 // no subject name, no subjectAltNames. The template can then be modified into
 // a (root) CA template or an end-entity template by the caller.
-func (ca *CA) createTemplate(publicKey *ecdsa.PublicKey) x509.Certificate {
+func (ca *CA) createTemplate(publicKey crypto.PublicKey) (x509.Certificate, error) {
 	// ECDSA is used instead of RSA because ECDSA key generation is
 	// straightforward and fast whereas RSA key generation is extremely slow
 	// and error-prone.
@@ -151,8 +420,14 @@ func (ca *CA) createTemplate(publicKey *ecdsa.PublicKey) x509.Certificate {
 	// anyway since a P-256 scalar is only 256 bits long.
 	const SignatureAlgorithm = x509.ECDSAWithSHA256
 
-	serialNumber := big.NewInt(int64(ca.nextSerialNumber))
-	ca.nextSerialNumber += 1
+	// Serial numbers must not be reused. They're random, since the issuer's
+	// credentials may outlive this process when they're maintained by an
+	// external system, so a counter could start over. For now we do not
+	// attempt to meet CABForum requirements.
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return x509.Certificate{}, err
+	}
 
 	notBefore := time.Now()
 
@@ -162,7 +437,7 @@ func (ca *CA) createTemplate(publicKey *ecdsa.PublicKey) x509.Certificate {
 		NotBefore:          notBefore.Add(-ca.clockSkewAllocance),
 		NotAfter:           notBefore.Add(ca.validity).Add(ca.clockSkewAllocance),
 		PublicKey:          publicKey,
-	}
+	}, nil
 }
 
 func generateKeyPair() (*ecdsa.PrivateKey, error) {
@@ -0,0 +1,162 @@
+package ca
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testCredentials are PEM-encoded credentials, as maintained by an external
+// system.
+type testCredentials struct {
+	certificatePEM string
+	keyPEM         string
+	certificate    *x509.Certificate
+	key            interface{}
+}
+
+func newTestCredentials(t *testing.T, name string, key interface{}, parent *testCredentials) *testCredentials {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(90 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	var publicKey interface{}
+	var keyDER []byte
+	var keyType string
+	var err error
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		publicKey = &k.PublicKey
+		keyDER, err = x509.MarshalECPrivateKey(k)
+		keyType = "EC PRIVATE KEY"
+	case *rsa.PrivateKey:
+		publicKey = &k.PublicKey
+		keyDER = x509.MarshalPKCS1PrivateKey(k)
+		keyType = "RSA PRIVATE KEY"
+	}
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	parentCertificate, parentKey := template, key
+	if parent != nil {
+		parentCertificate, parentKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCertificate, publicKey, parentKey)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	return &testCredentials{
+		certificatePEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:         string(pem.EncodeToMemory(&pem.Block{Type: keyType, Bytes: keyDER})),
+		certificate:    crt,
+		key:            key,
+	}
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := generateKeyPair()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return key
+}
+
+func TestNewIssuerCA(t *testing.T) {
+	anchor := newTestCredentials(t, "Trust Anchor", newTestKey(t), nil)
+	issuer := newTestCredentials(t, "Issuer", newTestKey(t), anchor)
+
+	t.Run("issues certificates that chain to the trust anchors", func(t *testing.T) {
+		ca, err := NewIssuerCA(issuer.certificatePEM, issuer.keyPEM, anchor.certificatePEM)
+		if err != nil {
+			t.Fatalf("NewIssuerCA returned an error: %s", err)
+		}
+
+		crt, err := ca.IssueEndEntityCertificate("web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		leaf, err := x509.ParseCertificate(crt.Certificate)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if leaf.NotAfter.After(issuer.certificate.NotAfter) {
+			t.Fatalf("expected the certificate not to outlive the issuer, but it expires at %s", leaf.NotAfter)
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(ca.TrustAnchorPEM())) {
+			t.Fatal("failed to parse the trust anchors")
+		}
+		if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots}); err != nil {
+			t.Fatalf("expected the certificate to be verified by the distributed trust anchors, got: %s", err)
+		}
+	})
+
+	t.Run("uses self-signed issuers as their own trust anchor", func(t *testing.T) {
+		ca, err := NewIssuerCA(anchor.certificatePEM, anchor.keyPEM, "")
+		if err != nil {
+			t.Fatalf("NewIssuerCA returned an error: %s", err)
+		}
+		if ca.TrustAnchorPEM() != anchor.certificatePEM {
+			t.Fatalf("expected trust anchors [%s], got [%s]", anchor.certificatePEM, ca.TrustAnchorPEM())
+		}
+	})
+
+	t.Run("replaces the issuer", func(t *testing.T) {
+		ca, err := NewIssuerCA(anchor.certificatePEM, anchor.keyPEM, "")
+		if err != nil {
+			t.Fatalf("NewIssuerCA returned an error: %s", err)
+		}
+		if err := ca.SetIssuer(issuer.certificatePEM, issuer.keyPEM, anchor.certificatePEM); err != nil {
+			t.Fatalf("SetIssuer returned an error: %s", err)
+		}
+		if !ca.Certificate().Equal(issuer.certificate) {
+			t.Fatalf("expected the issuer to be replaced, got [%s]", ca.Certificate().Subject.CommonName)
+		}
+	})
+
+	otherAnchor := newTestCredentials(t, "Other Trust Anchor", newTestKey(t), nil)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	rsaIssuer := newTestCredentials(t, "RSA Issuer", rsaKey, anchor)
+
+	testCases := []struct {
+		desc            string
+		certificatePEM  string
+		keyPEM          string
+		trustAnchorsPEM string
+	}{
+		{"rejects issuers that don't chain to the trust anchors", issuer.certificatePEM, issuer.keyPEM, otherAnchor.certificatePEM},
+		{"rejects keys that don't match the issuer", issuer.certificatePEM, otherAnchor.keyPEM, anchor.certificatePEM},
+		{"rejects unsupported key types", rsaIssuer.certificatePEM, rsaIssuer.keyPEM, anchor.certificatePEM},
+		{"rejects invalid certificates", "not a certificate", issuer.keyPEM, anchor.certificatePEM},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := NewIssuerCA(tc.certificatePEM, tc.keyPEM, tc.trustAnchorsPEM); err == nil {
+				t.Fatal("expected an error, got none")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package ca
 
 import (
+	"crypto/x509"
 	"fmt"
 	"strings"
 	"time"
@@ -9,8 +10,10 @@ import (
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	rbacV1beta1 "k8s.io/api/rbac/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
@@ -19,10 +22,23 @@ import (
 
 type CertificateController struct {
 	namespace   string
+	trustDomain string
 	k8sAPI      *k8s.API
+	recorder    *k8s.EventRecorder
 	ca          *CA
 	syncHandler func(key string) error
 
+	// expirations records the certificates of the identities whose secrets
+	// are maintained by the controller, so that their expirations are
+	// exported.
+	expirations *Expirations
+
+	// federatedTrustAnchors holds the PEM-encoded trust anchors of the other
+	// trust domains that are federated with this one. They are distributed
+	// along with the CA's own trust anchor, so that proxies accept the
+	// identities issued in those trust domains.
+	federatedTrustAnchors string
+
 	// The queue is keyed on a string. If the string doesn't contain any dots
 	// then it is a namespace name and the task is to create the CA bundle
 	// configmap in that namespace. Otherwise the string must be of the form
@@ -31,16 +47,21 @@ type CertificateController struct {
 	queue workqueue.RateLimitingInterface
 }
 
-func NewCertificateController(controllerNamespace string, k8sAPI *k8s.API) (*CertificateController, error) {
-	ca, err := NewCA()
-	if err != nil {
-		return nil, err
+func NewCertificateController(controllerNamespace, trustDomain, federatedTrustAnchors string, ca *CA, expirations *Expirations, k8sAPI *k8s.API) (*CertificateController, error) {
+	if federatedTrustAnchors != "" {
+		if err := ValidateTrustAnchorsPEM(federatedTrustAnchors); err != nil {
+			return nil, fmt.Errorf("invalid federated trust anchors: %s", err)
+		}
 	}
 
 	c := &CertificateController{
-		namespace: controllerNamespace,
-		k8sAPI:    k8sAPI,
-		ca:        ca,
+		namespace:             controllerNamespace,
+		trustDomain:           trustDomain,
+		federatedTrustAnchors: federatedTrustAnchors,
+		k8sAPI:                k8sAPI,
+		recorder:              k8s.NewEventRecorder(k8sAPI.Client, "linkerd-ca"),
+		ca:                    ca,
+		expirations:           expirations,
 		queue: workqueue.NewNamedRateLimitingQueue(
 			workqueue.DefaultControllerRateLimiter(), "certificates"),
 	}
@@ -54,6 +75,9 @@ func NewCertificateController(controllerNamespace string, k8sAPI *k8s.API) (*Cer
 
 	c.syncHandler = c.syncObject
 
+	issuer := ca.Certificate()
+	expirations.Set(IssuerCertificate, issuer.Subject.CommonName, issuer)
+
 	return c, nil
 }
 
@@ -67,6 +91,7 @@ func (c *CertificateController) Run(readyCh <-chan struct{}, stopCh <-chan struc
 	defer log.Info("shutting down certificate controller")
 
 	go wait.Until(c.worker, time.Second, stopCh)
+	go wait.Until(c.pruneExpirations, time.Minute, stopCh)
 
 	<-stopCh
 }
@@ -104,23 +129,48 @@ func (c *CertificateController) syncObject(key string) error {
 
 func (c *CertificateController) syncNamespace(ns string) error {
 	log.Debugf("syncNamespace(%s)", ns)
+	trustAnchors := c.trustAnchors()
 	configMap := &v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{Name: pkgK8s.TLSTrustAnchorConfigMapName},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pkgK8s.TLSTrustAnchorConfigMapName,
+			Annotations: map[string]string{
+				pkgK8s.TrustAnchorsUpdatedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
 		Data: map[string]string{
-			pkgK8s.TLSTrustAnchorFileName: c.ca.TrustAnchorPEM(),
+			pkgK8s.TLSTrustAnchorFileName: trustAnchors,
 		},
 	}
 
 	log.Debugf("adding configmap [%s] to namespace [%s]",
 		pkgK8s.TLSTrustAnchorConfigMapName, ns)
 	_, err := c.k8sAPI.Client.CoreV1().ConfigMaps(ns).Create(configMap)
-	if apierrors.IsAlreadyExists(err) {
-		_, err = c.k8sAPI.Client.CoreV1().ConfigMaps(ns).Update(configMap)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
 	}
 
+	// the time of the update is only recorded when the trust anchors change,
+	// since the proxies that started before then need to be restarted
+	existing, err := c.k8sAPI.Client.CoreV1().ConfigMaps(ns).Get(pkgK8s.TLSTrustAnchorConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Data[pkgK8s.TLSTrustAnchorFileName] == trustAnchors {
+		return nil
+	}
+	_, err = c.k8sAPI.Client.CoreV1().ConfigMaps(ns).Update(configMap)
 	return err
 }
 
+// trustAnchors returns the bundle of trust anchors distributed to every
+// namespace: the CA's own trust anchor, followed by the federated ones.
+func (c *CertificateController) trustAnchors() string {
+	if c.federatedTrustAnchors == "" {
+		return c.ca.TrustAnchorPEM()
+	}
+	return c.ca.TrustAnchorPEM() + strings.TrimSpace(c.federatedTrustAnchors) + "\n"
+}
+
 func (c *CertificateController) syncSecret(key string) error {
 	log.Debugf("syncSecret(%s)", key)
 	parts := strings.Split(key, ".")
@@ -133,35 +183,214 @@ func (c *CertificateController) syncSecret(key string) error {
 		Kind:                parts[1],
 		Namespace:           parts[2],
 		ControllerNamespace: c.namespace,
+		TrustDomain:         c.trustDomain,
 	}
 	dnsName := identity.ToDNSName()
 	secretName := identity.ToSecretName()
+	secrets := c.k8sAPI.Client.CoreV1().Secrets(identity.Namespace)
+
+	// the secret is only rewritten once the certificate issued for the
+	// identity is due for renewal, or wasn't issued by the current issuer. The
+	// issued certificates are recorded rather than read back from the secrets,
+	// which would require access to every secret of the meshed namespaces, so
+	// they're reissued once when the CA restarts.
+	issued := c.expirations.Certificate(EndEntityCertificate, dnsName)
+	if issued != nil {
+		if c.isCurrent(issued) {
+			c.queue.AddAfter(key, time.Until(RenewalTime(issued)))
+			return nil
+		}
+
+		// the owner's pods may all be gone by the time its certificate is
+		// due for renewal, in which case it's left to expire
+		identities, err := c.meshedIdentities()
+		if err != nil {
+			return err
+		}
+		if _, ok := identities[dnsName]; !ok {
+			log.Debugf("not renewing certificate of %s, which has no meshed pods", dnsName)
+			c.expirations.Delete(EndEntityCertificate, dnsName)
+			return nil
+		}
+	}
+
 	certAndPrivateKey, err := c.ca.IssueEndEntityCertificate(dnsName)
 	if err != nil {
 		log.Errorf("Failed to issue certificate for %s", dnsName)
 		return err
 	}
+	crt, err := x509.ParseCertificate(certAndPrivateKey.Certificate)
+	if err != nil {
+		return err
+	}
 	secret := &v1.Secret{
-		ObjectMeta: metav1.ObjectMeta{Name: secretName},
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: identity.Namespace},
 		Data: map[string][]byte{
 			pkgK8s.TLSCertFileName:       certAndPrivateKey.Certificate,
 			pkgK8s.TLSPrivateKeyFileName: certAndPrivateKey.PrivateKey,
 		},
 	}
-	_, err = c.k8sAPI.Client.CoreV1().Secrets(identity.Namespace).Create(secret)
+	reason := "IssuedCertificate"
+	if issued != nil {
+		reason = "RenewedCertificate"
+	}
+	written, err := secrets.Create(secret)
 	if apierrors.IsAlreadyExists(err) {
-		_, err = c.k8sAPI.Client.CoreV1().Secrets(identity.Namespace).Update(secret)
+		written, err = secrets.Update(secret)
 	}
+	if err != nil {
+		return err
+	}
+	if err := c.grantSecretAccess(identity.Namespace, secretName); err != nil {
+		return err
+	}
+	c.recorder.Eventf(written, v1.EventTypeNormal, reason, "Issued certificate for %s, valid until %s",
+		dnsName, crt.NotAfter.UTC().Format(time.RFC3339))
+
+	log.Debugf("issued certificate for %s, to be renewed at %s", dnsName, RenewalTime(crt))
+	c.expirations.Set(EndEntityCertificate, dnsName, crt)
+	c.queue.AddAfter(key, time.Until(RenewalTime(crt)))
+	return nil
+}
 
+// grantSecretAccess grants the controller, whose readiness controller waits for
+// the secrets of the proxies' identities, access to the secret. The controller
+// may only get the secrets issued by the CA, which are listed in a Role of
+// their namespace.
+func (c *CertificateController) grantSecretAccess(ns, secretName string) error {
+	name := fmt.Sprintf("linkerd-%s-controller-identity", c.namespace)
+	roles := c.k8sAPI.Client.RbacV1beta1().Roles(ns)
+
+	role, err := roles.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		role = &rbacV1beta1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Rules: []rbacV1beta1.PolicyRule{{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{secretName},
+				Verbs:         []string{"get"},
+			}},
+		}
+		if _, err := roles.Create(role); err != nil {
+			return err
+		}
+
+		binding := &rbacV1beta1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			RoleRef: rbacV1beta1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     name,
+			},
+			Subjects: []rbacV1beta1.Subject{{
+				Kind:      "ServiceAccount",
+				Name:      "linkerd-controller",
+				Namespace: c.namespace,
+			}},
+		}
+		_, err = c.k8sAPI.Client.RbacV1beta1().RoleBindings(ns).Create(binding)
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(role.Rules) != 1 {
+		return fmt.Errorf("unexpected rules in role %s/%s: %+v", ns, name, role.Rules)
+	}
+	for _, resourceName := range role.Rules[0].ResourceNames {
+		if resourceName == secretName {
+			return nil
+		}
+	}
+	role = role.DeepCopy()
+	role.Rules[0].ResourceNames = append(role.Rules[0].ResourceNames, secretName)
+	_, err = roles.Update(role)
 	return err
 }
 
+// isCurrent returns whether the certificate was issued by the current issuer,
+// and isn't due for renewal yet.
+func (c *CertificateController) isCurrent(crt *x509.Certificate) bool {
+	return crt.CheckSignatureFrom(c.ca.Certificate()) == nil && time.Now().Before(RenewalTime(crt))
+}
+
+// meshedIdentities returns the DNS names of the identities of all the meshed
+// pods.
+func (c *CertificateController) meshedIdentities() (map[string]struct{}, error) {
+	pods, err := c.k8sAPI.Pod().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make(map[string]struct{})
+	for _, pod := range pods {
+		if !pkgK8s.IsMeshed(pod, c.namespace) {
+			continue
+		}
+
+		if pod.Annotations[pkgK8s.IdentityModeAnnotation] == pkgK8s.IdentityModeServiceAccount {
+			serviceAccount := pod.Spec.ServiceAccountName
+			if serviceAccount == "" {
+				serviceAccount = "default"
+			}
+			identity := pkgK8s.ServiceAccountIdentity{
+				Name:                serviceAccount,
+				Namespace:           pod.Namespace,
+				ControllerNamespace: c.namespace,
+				TrustDomain:         c.trustDomain,
+			}
+			identities[identity.ToDNSName()] = struct{}{}
+			continue
+		}
+
+		ownerKind, ownerName := c.k8sAPI.GetOwnerKindAndName(pod)
+		identity := pkgK8s.TLSIdentity{
+			Name:                ownerName,
+			Kind:                ownerKind,
+			Namespace:           pod.Namespace,
+			ControllerNamespace: c.namespace,
+			TrustDomain:         c.trustDomain,
+		}
+		identities[identity.ToDNSName()] = struct{}{}
+	}
+	return identities, nil
+}
+
+// pruneExpirations forgets the end-entity certificates of the identities that
+// no longer have any meshed pods, so that they aren't reported as failing to
+// renew once they stop being renewed.
+func (c *CertificateController) pruneExpirations() {
+	identities, err := c.meshedIdentities()
+	if err != nil {
+		log.Errorf("failed to list meshed pods: %s", err)
+		return
+	}
+
+	for _, identity := range c.expirations.Identities(EndEntityCertificate) {
+		if _, ok := identities[identity]; !ok {
+			log.Debugf("forgetting certificate of %s, which has no meshed pods", identity)
+			c.expirations.Delete(EndEntityCertificate, identity)
+		}
+	}
+}
+
 func (c *CertificateController) handlePodAdd(obj interface{}) {
 	pod := obj.(*v1.Pod)
 	if pkgK8s.IsMeshed(pod, c.namespace) {
 		log.Debugf("enqueuing update of CA bundle configmap in %s", pod.Namespace)
 		c.queue.Add(pod.Namespace)
 
+		// the proxies of pods in the service account identity mode are
+		// certified by the identity service instead
+		if pod.Annotations[pkgK8s.IdentityModeAnnotation] == pkgK8s.IdentityModeServiceAccount {
+			return
+		}
+
 		ownerKind, ownerName := c.k8sAPI.GetOwnerKindAndName(pod)
 		item := fmt.Sprintf("%s.%s.%s", ownerName, ownerKind, pod.Namespace)
 		log.Debugf("enqueuing secret write for %s", item)
@@ -1,7 +1,12 @@
 package ca
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -61,13 +66,123 @@ func TestCertificateController(t *testing.T) {
 	})
 }
 
+func TestCertificateControllerFederation(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI()
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	t.Run("distributes federated trust anchors along with its own", func(t *testing.T) {
+		federatedCA, err := NewCA()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		ca, err := NewCA()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		controller, err := NewCertificateController(controllerNS, "prod.example.com", federatedCA.TrustAnchorPEM(), ca, NewExpirations(), k8sAPI)
+		if err != nil {
+			t.Fatalf("NewCertificateController returned an error: %s", err)
+		}
+
+		if err := controller.syncNamespace(injectedNS); err != nil {
+			t.Fatalf("syncNamespace returned an error: %s", err)
+		}
+
+		configMap, err := k8sAPI.Client.CoreV1().ConfigMaps(injectedNS).Get(pkgK8s.TLSTrustAnchorConfigMapName, meta.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		expected := controller.ca.TrustAnchorPEM() + federatedCA.TrustAnchorPEM()
+		if bundle := configMap.Data[pkgK8s.TLSTrustAnchorFileName]; bundle != expected {
+			t.Fatalf("expected trust anchors bundle [%s], got [%s]", expected, bundle)
+		}
+	})
+
+	t.Run("rejects invalid federated trust anchors", func(t *testing.T) {
+		ca, err := NewCA()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		_, err = NewCertificateController(controllerNS, pkgK8s.DefaultTrustDomain, "not a certificate", ca, NewExpirations(), k8sAPI)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	})
+}
+
+func TestCertificateControllerTrustAnchorsUpdate(t *testing.T) {
+	previousUpdate := "2018-09-20T12:00:00Z"
+
+	for _, tc := range []struct {
+		desc         string
+		trustAnchors func(*CertificateController) string
+		updated      bool
+	}{
+		{"records when the trust anchors change", func(*CertificateController) string { return "previous anchors" }, true},
+		{"keeps the time of the last change if the trust anchors are unchanged", (*CertificateController).trustAnchors, false},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			k8sAPI, err := k8s.NewFakeAPI()
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+			ca, err := NewCA()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			controller, err := NewCertificateController(controllerNS, pkgK8s.DefaultTrustDomain, "", ca, NewExpirations(), k8sAPI)
+			if err != nil {
+				t.Fatalf("NewCertificateController returned an error: %s", err)
+			}
+
+			_, err = k8sAPI.Client.CoreV1().ConfigMaps(injectedNS).Create(&v1.ConfigMap{
+				ObjectMeta: meta.ObjectMeta{
+					Name:        pkgK8s.TLSTrustAnchorConfigMapName,
+					Annotations: map[string]string{pkgK8s.TrustAnchorsUpdatedAtAnnotation: previousUpdate},
+				},
+				Data: map[string]string{pkgK8s.TLSTrustAnchorFileName: tc.trustAnchors(controller)},
+			})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if err := controller.syncNamespace(injectedNS); err != nil {
+				t.Fatalf("syncNamespace returned an error: %s", err)
+			}
+
+			configMap, err := k8sAPI.Client.CoreV1().ConfigMaps(injectedNS).Get(pkgK8s.TLSTrustAnchorConfigMapName, meta.GetOptions{})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if bundle := configMap.Data[pkgK8s.TLSTrustAnchorFileName]; bundle != controller.trustAnchors() {
+				t.Fatalf("expected trust anchors bundle [%s], got [%s]", controller.trustAnchors(), bundle)
+			}
+			if updated := configMap.Annotations[pkgK8s.TrustAnchorsUpdatedAtAnnotation] != previousUpdate; updated != tc.updated {
+				t.Fatalf("expected the update to be recorded: %t, got annotation [%s]",
+					tc.updated, configMap.Annotations[pkgK8s.TrustAnchorsUpdatedAtAnnotation])
+			}
+		})
+	}
+}
+
 func new(fixtures ...string) (*CertificateController, chan bool, chan struct{}, error) {
 	k8sAPI, err := k8s.NewFakeAPI(fixtures...)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("NewFakeAPI returned an error: %s", err)
 	}
 
-	controller, err := NewCertificateController(controllerNS, k8sAPI)
+	ca, err := NewCA()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	controller, err := NewCertificateController(controllerNS, pkgK8s.DefaultTrustDomain, "", ca, NewExpirations(), k8sAPI)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("NewCertificateController returned an error: %s", err)
 	}
@@ -88,3 +203,272 @@ func new(fixtures ...string) (*CertificateController, chan bool, chan struct{},
 
 	return controller, synced, stopCh, nil
 }
+
+func TestCertificateControllerRotation(t *testing.T) {
+	meshedPod := fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    %s: %s`, injectedPodName, injectedNS, pkgK8s.ControllerNSLabel, controllerNS)
+
+	k8sAPI, err := k8s.NewFakeAPI(meshedPod)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+
+	ca, err := NewCA()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expirations := NewExpirations()
+	controller, err := NewCertificateController(controllerNS, pkgK8s.DefaultTrustDomain, "", ca, expirations, k8sAPI)
+	if err != nil {
+		t.Fatalf("NewCertificateController returned an error: %s", err)
+	}
+
+	identity := pkgK8s.TLSIdentity{
+		Name:                injectedPodName,
+		Kind:                "pod",
+		Namespace:           injectedNS,
+		ControllerNamespace: controllerNS,
+		TrustDomain:         pkgK8s.DefaultTrustDomain,
+	}
+	key := fmt.Sprintf("%s.%s.%s", identity.Name, identity.Kind, identity.Namespace)
+	secrets := k8sAPI.Client.CoreV1().Secrets(injectedNS)
+	secretWrites := func() int {
+		writes := 0
+		for _, action := range k8sAPI.Client.(*fake.Clientset).Actions() {
+			if action.Matches("create", "secrets") || action.Matches("update", "secrets") {
+				writes++
+			}
+		}
+		return writes
+	}
+	eventReasons := func() []string {
+		events, err := k8sAPI.Client.CoreV1().Events(injectedNS).List(meta.ListOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		reasons := []string{}
+		for _, event := range events.Items {
+			if event.InvolvedObject.Kind != "Secret" || event.InvolvedObject.Name != identity.ToSecretName() {
+				t.Fatalf("expected event on the secret [%s], got %+v", identity.ToSecretName(), event.InvolvedObject)
+			}
+			reasons = append(reasons, event.Reason)
+		}
+		sort.Strings(reasons)
+		return reasons
+	}
+
+	t.Run("issues a certificate and records its expiration", func(t *testing.T) {
+		if err := controller.syncSecret(key); err != nil {
+			t.Fatalf("syncSecret returned an error: %s", err)
+		}
+		if writes := secretWrites(); writes != 1 {
+			t.Fatalf("expected the secret to be written once, got %d writes", writes)
+		}
+		identities := expirations.Identities(EndEntityCertificate)
+		if len(identities) != 1 || identities[0] != identity.ToDNSName() {
+			t.Fatalf("expected the expiration of [%s] to be recorded, got %v", identity.ToDNSName(), identities)
+		}
+		if reasons := eventReasons(); !reflect.DeepEqual(reasons, []string{"IssuedCertificate"}) {
+			t.Fatalf("expected an IssuedCertificate event, got %v", reasons)
+		}
+	})
+
+	t.Run("grants the controller access to the issued secret only", func(t *testing.T) {
+		name := fmt.Sprintf("linkerd-%s-controller-identity", controllerNS)
+		role, err := k8sAPI.Client.RbacV1beta1().Roles(injectedNS).Get(name, meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected role [%s] to be created, got %s", name, err)
+		}
+		if len(role.Rules) != 1 || !reflect.DeepEqual(role.Rules[0].ResourceNames, []string{identity.ToSecretName()}) {
+			t.Fatalf("expected role [%s] to grant access to [%s] only, got %+v", name, identity.ToSecretName(), role.Rules)
+		}
+
+		binding, err := k8sAPI.Client.RbacV1beta1().RoleBindings(injectedNS).Get(name, meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected role binding [%s] to be created, got %s", name, err)
+		}
+		if len(binding.Subjects) != 1 || binding.Subjects[0].Name != "linkerd-controller" || binding.Subjects[0].Namespace != controllerNS {
+			t.Fatalf("expected role binding [%s] to bind the controller, got %+v", name, binding.Subjects)
+		}
+
+		if err := controller.grantSecretAccess(injectedNS, "other-deployment-tls-linkerd-io"); err != nil {
+			t.Fatalf("grantSecretAccess returned an error: %s", err)
+		}
+		if err := controller.grantSecretAccess(injectedNS, identity.ToSecretName()); err != nil {
+			t.Fatalf("grantSecretAccess returned an error: %s", err)
+		}
+		role, err = k8sAPI.Client.RbacV1beta1().Roles(injectedNS).Get(name, meta.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		expected := []string{identity.ToSecretName(), "other-deployment-tls-linkerd-io"}
+		if !reflect.DeepEqual(role.Rules[0].ResourceNames, expected) {
+			t.Fatalf("expected role [%s] to grant access to %v, got %v", name, expected, role.Rules[0].ResourceNames)
+		}
+	})
+
+	t.Run("doesn't renew certificates before they're due", func(t *testing.T) {
+		if err := controller.syncSecret(key); err != nil {
+			t.Fatalf("syncSecret returned an error: %s", err)
+		}
+		if writes := secretWrites(); writes != 1 {
+			t.Fatalf("expected the secret not to be rewritten, got %d writes", writes)
+		}
+	})
+
+	t.Run("renews certificates that are due", func(t *testing.T) {
+		// a certificate of which three quarters of the lifetime have elapsed
+		template, err := ca.createTemplate(&ca.privateKey.PublicKey)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		template.DNSNames = []string{identity.ToDNSName()}
+		template.NotBefore = time.Now().Add(-3 * time.Hour)
+		template.NotAfter = time.Now().Add(time.Hour)
+		der, err := x509.CreateCertificate(rand.Reader, &template, ca.root, &ca.privateKey.PublicKey, ca.privateKey)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		due, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		expirations.Set(EndEntityCertificate, identity.ToDNSName(), due)
+
+		if err := controller.syncSecret(key); err != nil {
+			t.Fatalf("syncSecret returned an error: %s", err)
+		}
+		// the secret already exists, so it's updated once creating it fails
+		if writes := secretWrites(); writes != 3 {
+			t.Fatalf("expected the secret to be renewed, got %d writes", writes)
+		}
+
+		secret, err := secrets.Get(identity.ToSecretName(), meta.GetOptions{})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		crt, err := x509.ParseCertificate(secret.Data[pkgK8s.TLSCertFileName])
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if !time.Now().Before(RenewalTime(crt)) {
+			t.Fatalf("expected the renewed certificate not to be due for renewal, but it's due at %s", RenewalTime(crt))
+		}
+		if reasons := eventReasons(); !reflect.DeepEqual(reasons, []string{"IssuedCertificate", "RenewedCertificate"}) {
+			t.Fatalf("expected a RenewedCertificate event, got %v", reasons)
+		}
+	})
+
+	t.Run("forgets the certificates of identities without meshed pods", func(t *testing.T) {
+		expirations.Set(EndEntityCertificate, "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local", ca.Certificate())
+
+		controller.pruneExpirations()
+
+		identities := expirations.Identities(EndEntityCertificate)
+		if len(identities) != 1 || identities[0] != identity.ToDNSName() {
+			t.Fatalf("expected only the expiration of [%s] to be kept, got %v", identity.ToDNSName(), identities)
+		}
+		if issuers := expirations.Identities(IssuerCertificate); len(issuers) != 1 {
+			t.Fatalf("expected the expiration of the issuer to be kept, got %v", issuers)
+		}
+	})
+}
+
+func TestCertificateControllerIssuerReload(t *testing.T) {
+	meshedPod := fmt.Sprintf(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    %s: %s`, injectedPodName, injectedNS, pkgK8s.ControllerNSLabel, controllerNS)
+
+	k8sAPI, err := k8s.NewFakeAPI(meshedPod)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+
+	anchor := newTestCredentials(t, "Trust Anchor", newTestKey(t), nil)
+	issuerSecret := func(issuer *testCredentials) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: meta.ObjectMeta{Name: "linkerd-identity-issuer", Namespace: controllerNS},
+			Data: map[string][]byte{
+				pkgK8s.IdentityIssuerCertificateKey:  []byte(issuer.certificatePEM),
+				pkgK8s.IdentityIssuerPrivateKeyKey:   []byte(issuer.keyPEM),
+				pkgK8s.IdentityIssuerTrustAnchorsKey: []byte(anchor.certificatePEM),
+			},
+		}
+	}
+
+	issuer := newTestCredentials(t, "Issuer", newTestKey(t), anchor)
+	ca, err := NewCAFromSecret(issuerSecret(issuer))
+	if err != nil {
+		t.Fatalf("NewCAFromSecret returned an error: %s", err)
+	}
+	expirations := NewExpirations()
+	controller, err := NewCertificateController(controllerNS, pkgK8s.DefaultTrustDomain, "", ca, expirations, k8sAPI)
+	if err != nil {
+		t.Fatalf("NewCertificateController returned an error: %s", err)
+	}
+
+	t.Run("ignores secrets with the current issuer", func(t *testing.T) {
+		if err := controller.reloadIssuer(issuerSecret(issuer)); err != nil {
+			t.Fatalf("reloadIssuer returned an error: %s", err)
+		}
+		if controller.queue.Len() != 0 {
+			t.Fatalf("expected nothing to be enqueued, got %d items", controller.queue.Len())
+		}
+	})
+
+	t.Run("reloads renewed issuers and redistributes the certificates", func(t *testing.T) {
+		renewed := newTestCredentials(t, "Renewed Issuer", newTestKey(t), anchor)
+		if err := controller.reloadIssuer(issuerSecret(renewed)); err != nil {
+			t.Fatalf("reloadIssuer returned an error: %s", err)
+		}
+
+		if !ca.Certificate().Equal(renewed.certificate) {
+			t.Fatalf("expected the issuer to be reloaded, got [%s]", ca.Certificate().Subject.CommonName)
+		}
+		if issuers := expirations.Identities(IssuerCertificate); len(issuers) != 1 || issuers[0] != "Renewed Issuer" {
+			t.Fatalf("expected the expiration of the renewed issuer to be recorded, got %v", issuers)
+		}
+		// the CA bundle of the pod's namespace and the pod's secret
+		if controller.queue.Len() != 2 {
+			t.Fatalf("expected 2 items to be enqueued, got %d", controller.queue.Len())
+		}
+	})
+
+	t.Run("doesn't ignore secrets whose key changed", func(t *testing.T) {
+		current := ca.Certificate()
+		secret := issuerSecret(issuer)
+		secret.Data[pkgK8s.IdentityIssuerCertificateKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: current.Raw})
+		secret.Data[pkgK8s.IdentityIssuerPrivateKeyKey] = []byte(anchor.keyPEM)
+		if err := controller.reloadIssuer(secret); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+		if !ca.Certificate().Equal(current) {
+			t.Fatalf("expected the issuer to be kept, got [%s]", ca.Certificate().Subject.CommonName)
+		}
+	})
+
+	t.Run("keeps the current issuer if the secret is invalid", func(t *testing.T) {
+		current := ca.Certificate()
+		secret := issuerSecret(issuer)
+		secret.Data[pkgK8s.IdentityIssuerPrivateKeyKey] = []byte(anchor.keyPEM)
+		if err := controller.reloadIssuer(secret); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+		if !ca.Certificate().Equal(current) {
+			t.Fatalf("expected the issuer to be kept, got [%s]", ca.Certificate().Subject.CommonName)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package ca
+
+import (
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewCAFromSecret creates a CA that issues certificates with the issuer
+// credentials of the secret, which is maintained by an external system, such
+// as cert-manager.
+func NewCAFromSecret(secret *v1.Secret) (*CA, error) {
+	return NewIssuerCA(issuerCredentials(secret))
+}
+
+// issuerCredentials returns the PEM-encoded issuer certificate, issuer key and
+// trust anchors of the secret.
+func issuerCredentials(secret *v1.Secret) (string, string, string) {
+	return string(secret.Data[pkgK8s.IdentityIssuerCertificateKey]),
+		string(secret.Data[pkgK8s.IdentityIssuerPrivateKeyKey]),
+		string(secret.Data[pkgK8s.IdentityIssuerTrustAnchorsKey])
+}
+
+// WatchIssuerSecret reloads the issuer credentials of the CA from the secret
+// of the given name in the controller namespace whenever it changes, such as
+// when cert-manager renews them, until stopCh is closed. The trust anchors
+// and the certificates of all the meshed pods are then redistributed.
+func (c *CertificateController) WatchIssuerSecret(name string, stopCh <-chan struct{}) {
+	watchList := cache.NewListWatchFromClient(
+		c.k8sAPI.Client.CoreV1().RESTClient(),
+		"secrets",
+		c.namespace,
+		fields.OneTermEqualSelector("metadata.name", name),
+	)
+	_, informer := cache.NewInformer(watchList, &v1.Secret{}, 0,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.handleIssuerSecretUpdate,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				c.handleIssuerSecretUpdate(newObj)
+			},
+		},
+	)
+
+	log.Infof("watching issuer secret %s", name)
+	informer.Run(stopCh)
+}
+
+func (c *CertificateController) handleIssuerSecretUpdate(obj interface{}) {
+	secret := obj.(*v1.Secret)
+	if err := c.reloadIssuer(secret); err != nil {
+		log.Errorf("failed to reload the issuer from secret %s: %s", secret.Name, err)
+	}
+}
+
+// reloadIssuer replaces the issuer credentials of the CA with the ones of the
+// secret, unless neither the issuer certificate nor its key changed, and
+// enqueues the redistribution of the trust anchors and the certificates of all
+// the meshed pods.
+func (c *CertificateController) reloadIssuer(secret *v1.Secret) error {
+	issuerCertificatePEM, issuerKeyPEM, trustAnchorsPEM := issuerCredentials(secret)
+	if c.ca.hasIssuer(issuerCertificatePEM, issuerKeyPEM) {
+		return nil
+	}
+
+	previous := c.ca.Certificate()
+	if err := c.ca.SetIssuer(issuerCertificatePEM, issuerKeyPEM, trustAnchorsPEM); err != nil {
+		return err
+	}
+	issuer := c.ca.Certificate()
+	log.Infof("reloaded issuer %s, which expires at %s", issuer.Subject.CommonName, issuer.NotAfter)
+	c.expirations.Delete(IssuerCertificate, previous.Subject.CommonName)
+	c.expirations.Set(IssuerCertificate, issuer.Subject.CommonName, issuer)
+
+	pods, err := c.k8sAPI.Pod().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		c.handlePodAdd(pod)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package ca
+
+import (
+	"crypto/x509"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The types of the certificates whose expirations are exported.
+const (
+	IssuerCertificate    = "issuer"
+	EndEntityCertificate = "end-entity"
+)
+
+var expirationLabels = []string{"type", "identity"}
+
+var (
+	expirationDesc = prometheus.NewDesc(
+		"certificate_expiration_seconds",
+		"Time at which the certificate expires, in seconds since the epoch.",
+		expirationLabels, nil,
+	)
+	renewalFailureDesc = prometheus.NewDesc(
+		"certificate_renewal_failure_seconds",
+		"Time at which the certificate enters its renewal-failure window, in seconds since the epoch. The certificate should have been renewed well before then.",
+		expirationLabels, nil,
+	)
+)
+
+type expirationKey struct {
+	certType, identity string
+}
+
+// Expirations records the current certificate of every identity, along with
+// the CA's own certificate, and exports their expirations as Prometheus
+// metrics. It implements prometheus.Collector, so that the expirations are
+// served alongside the process' own metrics.
+type Expirations struct {
+	sync.RWMutex
+	certificates map[expirationKey]*x509.Certificate
+}
+
+func NewExpirations() *Expirations {
+	return &Expirations{
+		certificates: make(map[expirationKey]*x509.Certificate),
+	}
+}
+
+// Set records crt as the current certificate of the identity, replacing the
+// one it renews.
+func (e *Expirations) Set(certType, identity string, crt *x509.Certificate) {
+	e.Lock()
+	defer e.Unlock()
+	e.certificates[expirationKey{certType, identity}] = crt
+}
+
+// Delete forgets the certificate of an identity that's no longer in use, so
+// that it isn't reported as failing to renew.
+func (e *Expirations) Delete(certType, identity string) {
+	e.Lock()
+	defer e.Unlock()
+	delete(e.certificates, expirationKey{certType, identity})
+}
+
+// Certificate returns the recorded certificate of an identity, if any.
+func (e *Expirations) Certificate(certType, identity string) *x509.Certificate {
+	e.RLock()
+	defer e.RUnlock()
+	return e.certificates[expirationKey{certType, identity}]
+}
+
+// Identities returns the identities of the recorded certificates of the given
+// type.
+func (e *Expirations) Identities(certType string) []string {
+	e.RLock()
+	defer e.RUnlock()
+
+	identities := make([]string, 0)
+	for key := range e.certificates {
+		if key.certType == certType {
+			identities = append(identities, key.identity)
+		}
+	}
+	return identities
+}
+
+// Describe implements prometheus.Collector.
+func (e *Expirations) Describe(ch chan<- *prometheus.Desc) {
+	ch <- expirationDesc
+	ch <- renewalFailureDesc
+}
+
+// Collect implements prometheus.Collector.
+func (e *Expirations) Collect(ch chan<- prometheus.Metric) {
+	e.RLock()
+	defer e.RUnlock()
+
+	for key, crt := range e.certificates {
+		ch <- prometheus.MustNewConstMetric(expirationDesc, prometheus.GaugeValue,
+			float64(crt.NotAfter.Unix()), key.certType, key.identity)
+		ch <- prometheus.MustNewConstMetric(renewalFailureDesc, prometheus.GaugeValue,
+			float64(RenewalFailureTime(crt).Unix()), key.certType, key.identity)
+	}
+}
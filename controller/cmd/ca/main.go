@@ -2,23 +2,45 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/controller/identity"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func main() {
 	metricsAddr := flag.String("metrics-addr", ":9997", "address to serve scrapable metrics on")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	trustDomain := flag.String("trust-domain", pkgK8s.DefaultTrustDomain, "trust domain that issued identities belong to")
+	federatedTrustAnchorsPath := flag.String("federated-trust-anchors", "", "path to the PEM-encoded trust anchors of federated trust domains")
+	watchNamespaces := flag.String("watch-namespaces", "", "comma-separated namespaces to watch; all namespaces are watched if empty")
+	identityAddr := flag.String("identity-addr", "", "address to serve the identity service on; the identity service is disabled if empty")
+	issuerSecret := flag.String("issuer-secret", "", "name of the secret in the controller namespace that holds the issuer credentials, which are reloaded whenever it changes; a self-signed issuer is generated if empty")
+	identityIssuanceLifetime := flag.Duration("identity-issuance-lifetime", 24*time.Hour, "duration for which the certificates issued by the identity service are valid")
 	flags.ConfigureAndParse()
 
+	federatedTrustAnchors := ""
+	if *federatedTrustAnchorsPath != "" {
+		content, err := ioutil.ReadFile(*federatedTrustAnchorsPath)
+		if err != nil {
+			log.Fatalf("Failed to read federated trust anchors: %s", err)
+		}
+		federatedTrustAnchors = string(content)
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
@@ -26,13 +48,33 @@ func main() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	k8sAPI := k8s.NewAPI(
+	k8sAPI := k8s.NewNamespacedAPI(
 		k8sClient,
+		k8s.ParseNamespaces(*watchNamespaces),
+		k8s.Job,
 		k8s.Pod,
 		k8s.RS,
 	)
 
-	controller, err := ca.NewCertificateController(*controllerNamespace, k8sAPI)
+	var issuer *ca.CA
+	if *issuerSecret != "" {
+		var secret *v1.Secret
+		secret, err = k8sClient.CoreV1().Secrets(*controllerNamespace).Get(*issuerSecret, metav1.GetOptions{})
+		if err != nil {
+			log.Fatalf("Failed to read issuer secret: %v", err)
+		}
+		issuer, err = ca.NewCAFromSecret(secret)
+	} else {
+		issuer, err = ca.NewCA()
+	}
+	if err != nil {
+		log.Fatalf("Failed to create CA: %v", err)
+	}
+
+	expirations := ca.NewExpirations()
+	prometheus.MustRegister(expirations)
+
+	controller, err := ca.NewCertificateController(*controllerNamespace, *trustDomain, federatedTrustAnchors, issuer, expirations, k8sAPI)
 	if err != nil {
 		log.Fatalf("Failed to create CertificateController: %v", err)
 	}
@@ -47,6 +89,25 @@ func main() {
 		controller.Run(ready, stopCh)
 	}()
 
+	if *issuerSecret != "" {
+		go controller.WatchIssuerSecret(*issuerSecret, stopCh)
+	}
+
+	if *identityAddr != "" {
+		validator := identity.NewTokenReviewValidator(k8sClient)
+		server, lis, err := identity.NewServer(*identityAddr, *controllerNamespace, *trustDomain, *identityIssuanceLifetime, issuer, expirations, validator, k8sAPI)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go func() {
+			<-ready
+			log.Infof("starting identity gRPC server on %s", *identityAddr)
+			server.Serve(lis)
+		}()
+		defer server.GracefulStop()
+	}
+
 	go admin.StartServer(*metricsAddr, ready)
 
 	<-stop
@@ -5,11 +5,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/destination"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,6 +21,11 @@ func main() {
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
 	k8sDNSZone := flag.String("kubernetes-dns-zone", "", "The DNS suffix for the local Kubernetes zone.")
 	enableTLS := flag.Bool("enable-tls", false, "Enable TLS connections among pods in the service mesh")
+	trustDomain := flag.String("trust-domain", pkgK8s.DefaultTrustDomain, "Trust domain of the TLS identities of pods in the service mesh")
+	enableTopologyLabels := flag.Bool("enable-topology-labels", false, "Label the addresses of pods with the zone and region of their nodes, which the proxies add to their metrics")
+	watchNamespaces := flag.String("watch-namespaces", "", "comma-separated namespaces to watch; all namespaces are watched if empty")
+	drainDelay := flag.Duration("drain-delay", 5*time.Second, "how long the process reports unready once it's told to stop, before its servers stop accepting new connections")
+	drainPeriod := flag.Duration("drain-period", 20*time.Second, "how long the in-flight requests and streams have to complete once the servers stop accepting new connections")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
@@ -28,34 +35,59 @@ func main() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	k8sAPI := k8s.NewAPI(
-		k8sClient,
-		k8s.Endpoint,
-		k8s.Pod,
-		k8s.RS,
-		k8s.Svc,
-	)
+	splitClient, err := k8s.NewTrafficSplitClient(*kubeConfigPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	profileClient, err := k8s.NewServiceProfileClient(*kubeConfigPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	namespaces := k8s.ParseNamespaces(*watchNamespaces)
+	resources := []k8s.ApiResource{k8s.Endpoint, k8s.Job, k8s.Pod, k8s.RS, k8s.Svc}
+	if *enableTopologyLabels {
+		resources = append(resources, k8s.Node)
+	}
+	k8sAPI := k8s.NewNamespacedAPI(k8sClient, namespaces, resources...)
+	k8sAPI.WithTrafficSplits(k8s.NewTrafficSplitListWatch(splitClient, namespaces))
+	profilesServed, err := k8s.ServiceProfilesServed(k8sClient)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if profilesServed {
+		k8sAPI.WithServiceProfiles(k8s.NewServiceProfileListWatch(profileClient, namespaces))
+	} else {
+		log.Warn("the ServiceProfile CRD isn't installed; services are served without routes until it is installed and this process is restarted")
+	}
 
 	done := make(chan struct{})
 	ready := make(chan struct{})
 
-	server, lis, err := destination.NewServer(*addr, *k8sDNSZone, *enableTLS, k8sAPI, done)
+	server, lis, err := destination.NewServer(*addr, *k8sDNSZone, *trustDomain, *enableTLS, *enableTopologyLabels, k8sAPI, done)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	readinessController := destination.NewReadinessController(*enableTLS, k8sAPI)
+
 	go k8sAPI.Sync(ready)
 
+	stopCh := make(chan struct{})
+	go readinessController.Run(ready, stopCh)
+
 	go func() {
 		log.Infof("starting gRPC server on %s", *addr)
 		server.Serve(lis)
 	}()
 
-	go admin.StartServer(*metricsAddr, ready)
+	drainer := admin.NewDrainer(*drainDelay, *drainPeriod)
+	go admin.StartDrainableServer(*metricsAddr, ready, drainer.Draining())
 
 	<-stop
+	drainer.Start()
 
 	log.Infof("shutting down gRPC server on %s", *addr)
 	close(done)
-	server.GracefulStop()
+	close(stopCh)
+	drainer.DrainGRPC(server)
 }
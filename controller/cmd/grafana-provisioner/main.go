@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/grafana"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	log "github.com/sirupsen/logrus"
+)
+
+// grafana-provisioner provisions the data source and the dashboards of
+// Linkerd to an existing Grafana server. It retries until Grafana is
+// reachable, so that it can run as a Job of the install.
+func main() {
+	grafanaURL := flag.String("grafana-url", "", "URL of the Grafana server to provision")
+	prometheusURL := flag.String("prometheus-url", "", "URL of the Prometheus server queried by the dashboards")
+	dashboardsDir := flag.String("dashboards-dir", "/var/lib/grafana/dashboards", "directory of the JSON dashboards to provision")
+	retryInterval := flag.Duration("retry-interval", 10*time.Second, "interval at which provisioning is retried")
+	maxRetries := flag.Int("max-retries", 30, "number of retries before giving up")
+	flags.ConfigureAndParse()
+
+	if *grafanaURL == "" || *prometheusURL == "" {
+		log.Fatal("-grafana-url and -prometheus-url must be set")
+	}
+
+	// the API key is read from the environment rather than from a flag, so
+	// that it can be provided by a Secret
+	provisioner, err := grafana.NewProvisioner(*grafanaURL, os.Getenv("GRAFANA_API_KEY"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	for i := 0; ; i++ {
+		err = provisioner.ProvisionDataSource(*prometheusURL)
+		if err == nil {
+			err = provisioner.ProvisionDashboards(*dashboardsDir)
+		}
+		if err == nil {
+			break
+		}
+		if i >= *maxRetries {
+			log.Fatalf("failed to provision Grafana at %s: %s", *grafanaURL, err)
+		}
+		log.Warnf("failed to provision Grafana at %s, retrying in %s: %s", *grafanaURL, *retryInterval, err)
+		time.Sleep(*retryInterval)
+	}
+
+	log.Infof("provisioned Grafana at %s", *grafanaURL)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	injector "github.com/linkerd/linkerd2/controller/proxy-injector"
+	"github.com/linkerd/linkerd2/pkg/admin"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8443", "address to serve on")
+	metricsAddr := flag.String("metrics-addr", ":9993", "address to serve scrapable metrics on")
+	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
+	tlsMode := flag.String("tls-mode", pkgK8s.TLSModePermissive, "cluster-wide TLS mode (\"strict\" or \"permissive\"), unless overridden by the linkerd.io/tls-mode annotation of a namespace; pods aren't admitted into strict namespaces unless their proxies are certified by the identity service")
+	sidecarConfigPath := flag.String("sidecar-config", "/var/linkerd-io/proxy-injector/config/sidecar.yaml", "path to the pod whose proxy and init container are injected")
+	tlsCertPath := flag.String("tls-cert", "/var/linkerd-io/proxy-injector/tls/tls.crt", "path to the PEM-encoded certificate of the webhook")
+	tlsKeyPath := flag.String("tls-key", "/var/linkerd-io/proxy-injector/tls/tls.key", "path to the PEM-encoded private key of the webhook")
+	watchNamespaces := flag.String("watch-namespaces", "", "comma-separated namespaces to watch; all namespaces are watched if empty")
+	flags.ConfigureAndParse()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	sidecarConfig, err := ioutil.ReadFile(*sidecarConfigPath)
+	if err != nil {
+		log.Fatalf("failed to read sidecar config: %s", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(*tlsCertPath, *tlsKeyPath)
+	if err != nil {
+		log.Fatalf("failed to load TLS certificate: %s", err)
+	}
+
+	k8sClient, err := k8s.NewClientSet(*kubeConfigPath)
+	if err != nil {
+		log.Fatalf("failed to create Kubernetes client: %s", err)
+	}
+	k8sAPI := k8s.NewNamespacedAPI(
+		k8sClient,
+		k8s.ParseNamespaces(*watchNamespaces),
+		k8s.Deploy,
+		k8s.Job,
+		k8s.NS,
+		k8s.RC,
+		k8s.RS,
+	)
+
+	webhook, err := injector.NewWebhook(k8sAPI, *controllerNamespace, *tlsMode, string(sidecarConfig))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	server := injector.NewWebhookServer(*addr, webhook, cert)
+
+	ready := make(chan struct{})
+
+	go k8sAPI.Sync(ready)
+
+	go func() {
+		<-ready
+		log.Infof("starting webhook server on %s", *addr)
+		if err := server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	go admin.StartServer(*metricsAddr, ready)
+
+	<-stop
+
+	log.Infof("shutting down webhook server on %s", *addr)
+	server.Shutdown(context.Background())
+}
@@ -1,30 +1,53 @@
 package main
 
 import (
-	"context"
+	"crypto/tls"
 	"flag"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/linkerd/linkerd2/controller/destination"
+	"github.com/linkerd/linkerd2/controller/edges"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/controller/tap"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
 	promApi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
 	addr := flag.String("addr", ":8085", "address to serve on")
+	apiServerAddr := flag.String("apiserver-addr", "", "if set, address to serve the tap API registered with the Kubernetes API server on")
+	apiServerCertPath := flag.String("apiserver-tls-cert", "/var/linkerd-io/tap-apiserver/tls/tls.crt", "path to the PEM-encoded certificate of the tap API server")
+	apiServerKeyPath := flag.String("apiserver-tls-key", "/var/linkerd-io/tap-apiserver/tls/tls.key", "path to the PEM-encoded private key of the tap API server")
+	metricsAdapterAddr := flag.String("metrics-adapter-addr", "", "if set, address to serve the custom metrics API registered with the Kubernetes API server on")
+	metricsAdapterCertPath := flag.String("metrics-adapter-tls-cert", "/var/linkerd-io/metrics-adapter/tls/tls.crt", "path to the PEM-encoded certificate of the custom metrics API server")
+	metricsAdapterKeyPath := flag.String("metrics-adapter-tls-key", "/var/linkerd-io/metrics-adapter/tls/tls.key", "path to the PEM-encoded private key of the custom metrics API server")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
 	prometheusUrl := flag.String("prometheus-url", "http://127.0.0.1:9090", "prometheus url")
+	prometheusQueryTimeout := flag.Duration("prometheus-query-timeout", 30*time.Second, "maximum duration of each prometheus query; 0 for no timeout")
+	prometheusMaxSamples := flag.Int("prometheus-max-samples", 0, "maximum number of samples a prometheus query may return; 0 for no maximum")
 	metricsAddr := flag.String("metrics-addr", ":9995", "address to serve scrapable metrics on")
 	tapAddr := flag.String("tap-addr", "127.0.0.1:8088", "address of tap service")
+	destinationAddr := flag.String("destination-addr", "127.0.0.1:8089", "address of destination service")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
 	ignoredNamespaces := flag.String("ignore-namespaces", "kube-system", "comma separated list of namespaces to not list pods from")
+	edgeTimeWindow := flag.String("edge-time-window", "1m", "time window over which edge metrics are aggregated")
+	edgeRefreshInterval := flag.Duration("edge-refresh-interval", 30*time.Second, "interval at which edge metrics are refreshed")
+	edgeSnapshotURL := flag.String("edge-snapshot-url", "", "if set, JSON snapshots of the edge metrics are written to this file:// or http(s):// URL")
+	edgeSnapshotInterval := flag.Duration("edge-snapshot-interval", 5*time.Minute, "interval at which edge metrics snapshots are written")
+	watchNamespaces := flag.String("watch-namespaces", "", "comma-separated namespaces to watch; all namespaces are watched if empty")
+	enforceRBAC := flag.Bool("enforce-rbac", false, "if true, only serve callers bearing a Kubernetes token, querying namespaces in which they are authorized to list pods")
+	drainDelay := flag.Duration("drain-delay", 5*time.Second, "how long the process reports unready once it's told to stop, before its servers stop accepting new connections")
+	drainPeriod := flag.Duration("drain-period", 20*time.Second, "how long the in-flight requests and streams, such as taps, have to complete once the servers stop accepting new connections")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
@@ -36,13 +59,21 @@ func main() {
 	}
 	defer tapConn.Close()
 
+	destinationClient, destinationConn, err := destination.NewClient(*destinationAddr)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	defer destinationConn.Close()
+
 	k8sClient, err := k8s.NewClientSet(*kubeConfigPath)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	k8sAPI := k8s.NewAPI(
+	k8sAPI := k8s.NewNamespacedAPI(
 		k8sClient,
+		k8s.ParseNamespaces(*watchNamespaces),
 		k8s.Deploy,
+		k8s.Job,
 		k8s.NS,
 		k8s.Pod,
 		k8s.RC,
@@ -55,13 +86,31 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
+	edgeExporter := edges.NewExporter(promv1.NewAPI(prometheusClient), *edgeTimeWindow)
+	prometheus.MustRegister(edgeExporter)
+
+	stopCh := make(chan struct{})
+	go edgeExporter.Run(*edgeRefreshInterval, stopCh)
+
+	if *edgeSnapshotURL != "" {
+		snapshotWriter, err := edges.NewSnapshotWriter(edgeExporter, *edgeSnapshotURL)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		go snapshotWriter.Run(*edgeSnapshotInterval, stopCh)
+	}
+
 	server := public.NewServer(
 		*addr,
 		prometheusClient,
 		tapClient,
+		destinationClient,
 		k8sAPI,
 		*controllerNamespace,
 		strings.Split(*ignoredNamespaces, ","),
+		*prometheusQueryTimeout,
+		*prometheusMaxSamples,
+		*enforceRBAC,
 	)
 
 	ready := make(chan struct{})
@@ -72,11 +121,49 @@ func main() {
 		log.Infof("starting HTTP server on %+v", *addr)
 		server.ListenAndServe()
 	}()
+	servers := []*http.Server{server}
+
+	if *apiServerAddr != "" {
+		cert, err := tls.LoadX509KeyPair(*apiServerCertPath, *apiServerKeyPath)
+		if err != nil {
+			log.Fatalf("failed to load the certificate of the tap API server: %s", err)
+		}
+		apiServer, err := public.NewAPIServer(*apiServerAddr, cert, tapClient, k8sAPI, *controllerNamespace)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		go func() {
+			log.Infof("starting tap API server on %+v", *apiServerAddr)
+			apiServer.ListenAndServeTLS("", "")
+		}()
+		servers = append(servers, apiServer)
+	}
+
+	if *metricsAdapterAddr != "" {
+		cert, err := tls.LoadX509KeyPair(*metricsAdapterCertPath, *metricsAdapterKeyPath)
+		if err != nil {
+			log.Fatalf("failed to load the certificate of the custom metrics API server: %s", err)
+		}
+		metricsAdapter, err := public.NewMetricsAdapterServer(*metricsAdapterAddr, cert, prometheusClient, k8sAPI, *controllerNamespace)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		go func() {
+			log.Infof("starting custom metrics API server on %+v", *metricsAdapterAddr)
+			metricsAdapter.ListenAndServeTLS("", "")
+		}()
+		servers = append(servers, metricsAdapter)
+	}
 
-	go admin.StartServer(*metricsAddr, ready)
+	drainer := admin.NewDrainer(*drainDelay, *drainPeriod)
+	go admin.StartDrainableServer(*metricsAddr, ready, drainer.Draining())
 
 	<-stop
+	drainer.Start()
+	close(stopCh)
 
 	log.Infof("shutting down HTTP server on %+v", *addr)
-	server.Shutdown(context.Background())
+	drainer.DrainHTTP(servers...)
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/controller/servicemirror"
+	"github.com/linkerd/linkerd2/pkg/admin"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	metricsAddr := flag.String("metrics-addr", ":9994", "address to serve scrapable metrics on")
+	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config of the local cluster")
+	remoteKubeConfigPath := flag.String("remote-kubeconfig", "", "path to kube config of the remote cluster whose services are mirrored")
+	clusterName := flag.String("cluster-name", "", "name of the remote cluster, which suffixes the names of the mirrored services")
+	gatewayName := flag.String("gateway-name", "linkerd-gateway", "name of the gateway service of the remote cluster")
+	gatewayNamespace := flag.String("gateway-namespace", "linkerd", "namespace of the gateway service of the remote cluster")
+	remoteControllerNamespace := flag.String("remote-controller-namespace", "linkerd", "namespace in which Linkerd is installed in the remote cluster")
+	remoteClusterDomain := flag.String("remote-cluster-domain", "cluster.local", "DNS domain of the remote cluster, which qualifies the names of the remote services")
+	flags.ConfigureAndParse()
+
+	if *remoteKubeConfigPath == "" {
+		log.Fatal("-remote-kubeconfig must be set")
+	}
+	if *clusterName == "" {
+		log.Fatal("-cluster-name must be set")
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	localClient, err := k8s.NewClientSet(*kubeConfigPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	localAPI := k8s.NewAPI(localClient, k8s.Endpoint, k8s.NS, k8s.Svc)
+
+	remoteClient, err := k8s.NewClientSet(*remoteKubeConfigPath)
+	if err != nil {
+		log.Fatalf("Failed to configure the client of cluster %s: %s", *clusterName, err)
+	}
+	remoteAPI := k8s.NewAPI(remoteClient, k8s.Svc)
+
+	mirror := servicemirror.NewServiceMirror(
+		*clusterName,
+		*gatewayName,
+		*gatewayNamespace,
+		*remoteControllerNamespace,
+		*remoteClusterDomain,
+		localAPI,
+		remoteAPI,
+	)
+
+	localReady := make(chan struct{})
+	remoteReady := make(chan struct{})
+	ready := make(chan struct{})
+
+	go localAPI.Sync(localReady)
+	go remoteAPI.Sync(remoteReady)
+	go func() {
+		<-localReady
+		<-remoteReady
+		close(ready)
+	}()
+
+	stopCh := make(chan struct{})
+	go mirror.Run(ready, stopCh)
+
+	go admin.StartServer(*metricsAddr, ready)
+
+	<-stop
+
+	log.Info("shutting down")
+	close(stopCh)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	validator "github.com/linkerd/linkerd2/controller/sp-validator"
+	"github.com/linkerd/linkerd2/controller/webhook"
+	"github.com/linkerd/linkerd2/pkg/admin"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8443", "address to serve on")
+	metricsAddr := flag.String("metrics-addr", ":9992", "address to serve scrapable metrics on")
+	tlsCertPath := flag.String("tls-cert", "/var/linkerd-io/sp-validator/tls/tls.crt", "path to the PEM-encoded certificate of the webhook")
+	tlsKeyPath := flag.String("tls-key", "/var/linkerd-io/sp-validator/tls/tls.key", "path to the PEM-encoded private key of the webhook")
+	flags.ConfigureAndParse()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	cert, err := tls.LoadX509KeyPair(*tlsCertPath, *tlsKeyPath)
+	if err != nil {
+		log.Fatalf("failed to load TLS certificate: %s", err)
+	}
+
+	server := webhook.NewServer(*addr, webhook.Handler(validator.Validate), cert)
+
+	go func() {
+		log.Infof("starting webhook server on %s", *addr)
+		if err := server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	// the validator doesn't watch the Kubernetes API, so it's ready as soon as
+	// it's started
+	ready := make(chan struct{})
+	close(ready)
+	go admin.StartServer(*metricsAddr, ready)
+
+	<-stop
+
+	log.Infof("shutting down webhook server on %s", *addr)
+	server.Shutdown(context.Background())
+}
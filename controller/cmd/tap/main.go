@@ -5,20 +5,29 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/controller/tap"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
-	addr := flag.String("addr", "127.0.0.1:8088", "address to serve on")
+	addr := flag.String("addr", "127.0.0.1:8088", "address to serve on; only the loopback interface must be used, as the callers' identities aren't authenticated")
 	metricsAddr := flag.String("metrics-addr", ":9998", "address to serve scrapable metrics on")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
+	trustDomain := flag.String("trust-domain", pkgK8s.DefaultTrustDomain, "Trust domain of the TLS identities of pods in the service mesh")
 	tapPort := flag.Uint("tap-port", 4190, "proxy tap port to connect to")
+	enforceRBAC := flag.Bool("enforce-rbac", false, "if true, only allow callers authenticated by the Kubernetes API server to tap namespaces they are authorized to tap")
+	maxTapsPerProxy := flag.Uint("max-taps-per-proxy", 10, "maximum number of taps concurrently established on a proxy, by all callers; 0 means no limit")
+	maxTapsPerCaller := flag.Uint("max-taps-per-caller", 5, "maximum number of taps concurrently served to a caller authenticated by the Kubernetes API server; 0 means no limit")
+	watchNamespaces := flag.String("watch-namespaces", "", "comma-separated namespaces to watch; all namespaces are watched if empty")
+	drainDelay := flag.Duration("drain-delay", 5*time.Second, "how long the process reports unready once it's told to stop, before its servers stop accepting new connections")
+	drainPeriod := flag.Duration("drain-period", 20*time.Second, "how long the in-flight requests and streams, such as taps, have to complete once the servers stop accepting new connections")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
@@ -28,9 +37,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create Kubernetes client: %s", err)
 	}
-	k8sAPI := k8s.NewAPI(
+	k8sAPI := k8s.NewNamespacedAPI(
 		clientSet,
+		k8s.ParseNamespaces(*watchNamespaces),
 		k8s.Deploy,
+		k8s.Job,
 		k8s.NS,
 		k8s.Pod,
 		k8s.RC,
@@ -38,7 +49,7 @@ func main() {
 		k8s.RS,
 	)
 
-	server, lis, err := tap.NewServer(*addr, *tapPort, *controllerNamespace, k8sAPI)
+	server, lis, err := tap.NewServer(*addr, *tapPort, *controllerNamespace, *trustDomain, *enforceRBAC, *maxTapsPerProxy, *maxTapsPerCaller, k8sAPI)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -52,10 +63,12 @@ func main() {
 		server.Serve(lis)
 	}()
 
-	go admin.StartServer(*metricsAddr, ready)
+	drainer := admin.NewDrainer(*drainDelay, *drainPeriod)
+	go admin.StartDrainableServer(*metricsAddr, ready, drainer.Draining())
 
 	<-stop
+	drainer.Start()
 
 	log.Println("shutting down gRPC server on", *addr)
-	server.GracefulStop()
+	drainer.DrainGRPC(server)
 }
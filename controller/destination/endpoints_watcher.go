@@ -2,14 +2,17 @@ package destination
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 
 	net "github.com/linkerd/linkerd2-proxy-api/go/net"
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/pkg/addr"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -111,11 +114,10 @@ func (e *endpointsWatcher) subscribe(service *serviceId, port uint32, listener u
 	exists := true
 	if svc == nil || svc.Spec.Type == v1.ServiceTypeExternalName {
 		// XXX: The proxy will use DNS to discover the service if it is told
-		// the service doesn't exist. An external service is represented in DNS
-		// as a CNAME, which the proxy will correctly resolve. Thus, there's no
-		// benefit (yet) to distinguishing between "the service exists but it
-		// is an ExternalName service so use DNS anyway" and "the service does
-		// not exist."
+		// the service doesn't exist. The k8sResolver streams the addresses of
+		// ExternalName services itself, but a service that became an
+		// ExternalName service after being subscribed to is represented in
+		// DNS as a CNAME, which the proxy will correctly resolve.
 		exists = false
 	}
 
@@ -176,7 +178,7 @@ func (e *endpointsWatcher) addService(obj interface{}) {
 
 func (e *endpointsWatcher) updateService(oldObj, newObj interface{}) {
 	service := newObj.(*v1.Service)
-	if service.Namespace == kubeSystem {
+	if service.Namespace == kubeSystem || isResync(oldObj.(*v1.Service).ObjectMeta, service.ObjectMeta) {
 		return
 	}
 	id := serviceId{
@@ -219,7 +221,19 @@ func (e *endpointsWatcher) addEndpoints(obj interface{}) {
 }
 
 func (e *endpointsWatcher) deleteEndpoints(obj interface{}) {
-	endpoints := obj.(*v1.Endpoints)
+	endpoints, ok := obj.(*v1.Endpoints)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorf("Couldn't get object from tombstone %+v", obj)
+			return
+		}
+		endpoints, ok = tombstone.Obj.(*v1.Endpoints)
+		if !ok {
+			log.Errorf("Tombstone contained object that is not an Endpoints %+v", obj)
+			return
+		}
+	}
 	if endpoints.Namespace == kubeSystem {
 		return
 	}
@@ -239,9 +253,18 @@ func (e *endpointsWatcher) deleteEndpoints(obj interface{}) {
 }
 
 func (e *endpointsWatcher) updateEndpoints(oldObj, newObj interface{}) {
+	if isResync(oldObj.(*v1.Endpoints).ObjectMeta, newObj.(*v1.Endpoints).ObjectMeta) {
+		return
+	}
 	e.addEndpoints(newObj)
 }
 
+// isResync returns true if an update of an object was sent by the periodic
+// resync of its informer, which sends updates for the unchanged objects.
+func isResync(oldMeta, newMeta metav1.ObjectMeta) bool {
+	return oldMeta.ResourceVersion != "" && oldMeta.ResourceVersion == newMeta.ResourceVersion
+}
+
 /// servicePort ///
 
 // servicePort represents a service along with a port number.  Multiple
@@ -258,6 +281,14 @@ type servicePort struct {
 	targetPort intstr.IntOrString
 	addresses  []*updateAddress
 	podLister  corelisters.PodLister
+	// portName is the name of the service port, which also names the ports
+	// of the endpoints
+	portName string
+	// publishNotReady is set if the service publishes the addresses of the
+	// pods that aren't ready, like the headless services of StatefulSets
+	publishNotReady bool
+	// gateway is set if the service is mirrored from a remote cluster
+	gateway *remoteGateway
 	// This mutex protects against concurrent modification of the listeners slice
 	// as well as prevents updates for occuring while the listeners slice is being
 	// modified.
@@ -265,32 +296,28 @@ type servicePort struct {
 }
 
 func newServicePort(service *v1.Service, endpoints *v1.Endpoints, port uint32, podLister corelisters.PodLister) *servicePort {
-	// Use the service port as the target port by default.
-	targetPort := intstr.FromInt(int(port))
+	targetPort, portName := getTargetPort(service, port)
 
 	id := serviceId{}
+	publishNotReady := false
 
 	if service != nil {
 		id.namespace = service.Namespace
 		id.name = service.Name
-		// If a port spec exists with a matching service port, use that port spec's
-		// target port.
-		for _, portSpec := range service.Spec.Ports {
-			if portSpec.Port == int32(port) && portSpec.TargetPort != intstr.FromInt(0) {
-				targetPort = portSpec.TargetPort
-				break
-			}
-		}
+		publishNotReady = service.Spec.PublishNotReadyAddresses
 	}
 
 	sp := &servicePort{
-		service:    id,
-		listeners:  make([]updateListener, 0),
-		port:       port,
-		endpoints:  endpoints,
-		targetPort: targetPort,
-		podLister:  podLister,
-		mutex:      sync.Mutex{},
+		service:         id,
+		listeners:       make([]updateListener, 0),
+		port:            port,
+		endpoints:       endpoints,
+		targetPort:      targetPort,
+		podLister:       podLister,
+		portName:        portName,
+		publishNotReady: publishNotReady,
+		gateway:         getRemoteGateway(service, port),
+		mutex:           sync.Mutex{},
 	}
 
 	sp.addresses = sp.endpointsToAddresses(endpoints, targetPort)
@@ -302,6 +329,13 @@ func (sp *servicePort) updateEndpoints(newEndpoints *v1.Endpoints) {
 	sp.mutex.Lock()
 	defer sp.mutex.Unlock()
 
+	// the addresses only change with the subsets of the endpoints, so the
+	// updates of their other fields aren't published
+	if sp.endpoints.ResourceVersion != "" && reflect.DeepEqual(sp.endpoints.Subsets, newEndpoints.Subsets) {
+		sp.endpoints = newEndpoints
+		return
+	}
+
 	sp.updateAddresses(newEndpoints, sp.targetPort)
 	sp.endpoints = newEndpoints
 }
@@ -323,20 +357,65 @@ func (sp *servicePort) updateService(newService *v1.Service) {
 	sp.mutex.Lock()
 	defer sp.mutex.Unlock()
 
+	newTargetPort, newPortName := getTargetPort(newService, sp.port)
+	newPublishNotReady := newService.Spec.PublishNotReadyAddresses
+	newGateway := getRemoteGateway(newService, sp.port)
+	if !reflect.DeepEqual(newGateway, sp.gateway) {
+		// the addresses are sent again, so that they carry the new gateway
+		sp.gateway = newGateway
+		sp.targetPort = newTargetPort
+		sp.portName = newPortName
+		sp.publishNotReady = newPublishNotReady
+		sp.resendAddresses()
+		return
+	}
+	if newTargetPort != sp.targetPort || newPortName != sp.portName || newPublishNotReady != sp.publishNotReady {
+		sp.portName = newPortName
+		sp.publishNotReady = newPublishNotReady
+		sp.updateAddresses(sp.endpoints, newTargetPort)
+		sp.targetPort = newTargetPort
+	}
+}
+
+// getTargetPort returns the target port of the service port, which defaults
+// to the service port itself, and the name of the service port.
+func getTargetPort(service *v1.Service, port uint32) (intstr.IntOrString, string) {
 	// Use the service port as the target port by default.
-	newTargetPort := intstr.FromInt(int(sp.port))
+	targetPort := intstr.FromInt(int(port))
+	if service == nil {
+		return targetPort, ""
+	}
+
 	// If a port spec exists with a matching service port, use that port spec's
 	// target port.
-	for _, portSpec := range newService.Spec.Ports {
-		if portSpec.Port == int32(sp.port) && portSpec.TargetPort != intstr.FromInt(0) {
-			newTargetPort = portSpec.TargetPort
-			break
+	for _, portSpec := range service.Spec.Ports {
+		if portSpec.Port == int32(port) {
+			if portSpec.TargetPort != intstr.FromInt(0) {
+				targetPort = portSpec.TargetPort
+			}
+			return targetPort, portSpec.Name
 		}
 	}
-	if newTargetPort != sp.targetPort {
-		sp.updateAddresses(sp.endpoints, newTargetPort)
-		sp.targetPort = newTargetPort
+	return targetPort, ""
+}
+
+// resendAddresses sends all the current addresses to the listeners, and
+// removes the addresses that no longer exist.
+func (sp *servicePort) resendAddresses() {
+	newAddresses := sp.endpointsToAddresses(sp.endpoints, sp.targetPort)
+	log.Debugf("Resending %s:%d addresses %v", sp.service, sp.port, newAddresses)
+
+	if len(newAddresses) == 0 {
+		for _, listener := range sp.listeners {
+			listener.NoEndpoints(true)
+		}
+	} else {
+		_, remove := diffUpdateAddresses(sp.addresses, newAddresses)
+		for _, listener := range sp.listeners {
+			listener.Update(newAddresses, remove)
+		}
 	}
+	sp.addresses = newAddresses
 }
 
 func (sp *servicePort) updateAddresses(endpoints *v1.Endpoints, port intstr.IntOrString) {
@@ -349,8 +428,10 @@ func (sp *servicePort) updateAddresses(endpoints *v1.Endpoints, port intstr.IntO
 		}
 	} else {
 		add, remove := diffUpdateAddresses(sp.addresses, newAddresses)
-		for _, listener := range sp.listeners {
-			listener.Update(add, remove)
+		if len(add) > 0 || len(remove) > 0 {
+			for _, listener := range sp.listeners {
+				listener.Update(add, remove)
+			}
 		}
 	}
 	sp.addresses = newAddresses
@@ -408,37 +489,34 @@ func (sp *servicePort) unsubscribeAll() {
 func (sp *servicePort) endpointsToAddresses(endpoints *v1.Endpoints, port intstr.IntOrString) []*updateAddress {
 	addrs := make([]*updateAddress, 0)
 
-	var portNum uint32
-	if port.Type == intstr.String {
-	outer:
-		for _, subset := range endpoints.Subsets {
-			for _, p := range subset.Ports {
-				switch p.Name {
-				case port.StrVal:
-					// Found the target port!
-					portNum = uint32(p.Port)
-					break outer
-				case "":
-					// The port is unnamed. That means there's only one port defined
-					// for this subset. If this is the only subset, then we can use
-					// that port.
-					if len(endpoints.Subsets) == 1 {
-						portNum = uint32(p.Port)
-						break outer
-					}
+	for _, subset := range endpoints.Subsets {
+		portNum := sp.subsetPort(subset, port)
+
+		addresses := subset.Addresses
+		if sp.publishNotReady {
+			addresses = append(append([]v1.EndpointAddress{}, addresses...), subset.NotReadyAddresses...)
+		}
+
+		for _, address := range addresses {
+			// the endpoints of mirrored services aren't backed by pods, and
+			// are the addresses of the gateway of the remote cluster
+			if sp.gateway != nil {
+				if portNum == 0 {
+					log.Errorf("Port %s not found", port.StrVal)
+					continue
+				}
+				ip, err := addr.ParseProxyIPV4(address.IP)
+				if err != nil {
+					log.Errorf("[%s] not a valid IPV4 address", address.IP)
+					continue
 				}
+				addrs = append(addrs, &updateAddress{
+					address: &net.TcpAddress{Ip: ip, Port: portNum},
+					gateway: sp.gateway,
+				})
+				continue
 			}
-		}
-		if portNum == 0 {
-			log.Errorf("Port %s not found", port.StrVal)
-			return addrs
-		}
-	} else if port.Type == intstr.Int {
-		portNum = uint32(port.IntVal)
-	}
 
-	for _, subset := range endpoints.Subsets {
-		for _, address := range subset.Addresses {
 			target := address.TargetRef
 			if target == nil {
 				log.Errorf("Target not found for endpoint %v", address)
@@ -459,11 +537,85 @@ func (sp *servicePort) endpointsToAddresses(endpoints *v1.Endpoints, port intstr
 				continue
 			}
 
+			addressPort := portNum
+			if addressPort == 0 {
+				// the named target port isn't listed in the endpoints, so it's
+				// looked up in the containers of the pod
+				addressPort = containerPort(pod, port.StrVal)
+			}
+			if addressPort == 0 {
+				log.Errorf("[%s] port %s not found", idStr, port.StrVal)
+				continue
+			}
+
 			addrs = append(addrs, &updateAddress{
-				address: &net.TcpAddress{Ip: ip, Port: portNum},
-				pod:     pod,
+				address:  &net.TcpAddress{Ip: ip, Port: addressPort},
+				pod:      pod,
+				hostname: address.Hostname,
 			})
 		}
 	}
 	return addrs
 }
+
+// subsetPort returns the number of the target port in the endpoints subset, or
+// 0 if it isn't found. A named target port refers to a named container port,
+// whose number may differ across pods; the pods are then split into several
+// subsets, whose ports are named after the service port and hold the number
+// the container port has in their pods.
+func (sp *servicePort) subsetPort(subset v1.EndpointSubset, port intstr.IntOrString) uint32 {
+	if port.Type == intstr.Int {
+		return uint32(port.IntVal)
+	}
+
+	if sp.portName != "" {
+		for _, p := range subset.Ports {
+			if p.Name == sp.portName {
+				return uint32(p.Port)
+			}
+		}
+	}
+	for _, p := range subset.Ports {
+		if p.Name == port.StrVal {
+			return uint32(p.Port)
+		}
+	}
+
+	// The port is unnamed. That means there's only one port defined for this
+	// subset, so we can use that port.
+	if len(subset.Ports) == 1 && subset.Ports[0].Name == "" {
+		return uint32(subset.Ports[0].Port)
+	}
+	return 0
+}
+
+// containerPort returns the number of the named port of the pod's containers,
+// or 0 if the pod has no such port.
+func containerPort(pod *v1.Pod, name string) uint32 {
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == name {
+				return uint32(p.ContainerPort)
+			}
+		}
+	}
+	return 0
+}
+
+// getRemoteGateway returns the gateway of the remote cluster the service is
+// mirrored from, or nil if the service isn't mirrored. The requests to the
+// port of the service are routed by the gateway to the same port of the remote
+// service.
+func getRemoteGateway(service *v1.Service, port uint32) *remoteGateway {
+	if service == nil || service.Labels[pkgK8s.MirroredServiceLabel] != "true" {
+		return nil
+	}
+	gateway := &remoteGateway{
+		identity:     service.Annotations[pkgK8s.RemoteGatewayIdentityAnnotation],
+		controllerNs: service.Annotations[pkgK8s.RemoteControllerNSAnnotation],
+	}
+	if fqn := service.Annotations[pkgK8s.RemoteServiceFQNAnnotation]; fqn != "" {
+		gateway.authority = fmt.Sprintf("%s:%d", fqn, port)
+	}
+	return gateway
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/linkerd/linkerd2/controller/k8s"
 	"github.com/linkerd/linkerd2/pkg/addr"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestEndpointsWatcher(t *testing.T) {
@@ -249,6 +251,228 @@ spec:
 			expectedNoEndpoints:              true,
 			expectedNoEndpointsServiceExists: false,
 		},
+		{
+			serviceType: "services mirrored from remote clusters",
+			k8sConfigs: []string{`
+apiVersion: v1
+kind: Service
+metadata:
+  name: name5-east
+  namespace: ns
+  labels:
+    mirror.linkerd.io/mirrored-service: "true"
+    mirror.linkerd.io/cluster-name: east
+  annotations:
+    mirror.linkerd.io/remote-gateway-identity: linkerd-gateway.deployment.linkerd.linkerd-managed.linkerd.svc.east.example.com
+spec:
+  ports:
+  - port: 8989
+    targetPort: 4143`,
+				`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: name5-east
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 203.0.113.10
+  ports:
+  - port: 4143`,
+			},
+			service: &serviceId{namespace: "ns", name: "name5-east"},
+			port:    uint32(8989),
+			expectedAddresses: []string{
+				"203.0.113.10:4143",
+			},
+			expectedNoEndpoints:              false,
+			expectedNoEndpointsServiceExists: false,
+		},
+		{
+			serviceType: "services with a named target port",
+			k8sConfigs: []string{`
+apiVersion: v1
+kind: Service
+metadata:
+  name: name6
+  namespace: ns
+spec:
+  ports:
+  - name: grpc
+    port: 8080
+    targetPort: grpc-port`,
+				`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: name6
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 172.17.0.31
+    targetRef:
+      kind: Pod
+      name: name6-1
+      namespace: ns
+  ports:
+  - name: grpc
+    port: 9090
+- addresses:
+  - ip: 172.17.0.32
+    targetRef:
+      kind: Pod
+      name: name6-2
+      namespace: ns
+  ports:
+  - name: grpc
+    port: 9091`,
+				`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name6-1
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.31`,
+				`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name6-2
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.32`,
+			},
+			service: &serviceId{namespace: "ns", name: "name6"},
+			port:    uint32(8080),
+			expectedAddresses: []string{
+				"172.17.0.31:9090",
+				"172.17.0.32:9091",
+			},
+			expectedNoEndpoints:              false,
+			expectedNoEndpointsServiceExists: false,
+		},
+		{
+			serviceType: "services with a named target port missing from the endpoints",
+			k8sConfigs: []string{`
+apiVersion: v1
+kind: Service
+metadata:
+  name: name7
+  namespace: ns
+spec:
+  ports:
+  - name: http
+    port: 80
+    targetPort: web`,
+				`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: name7
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 172.17.0.41
+    targetRef:
+      kind: Pod
+      name: name7-1
+      namespace: ns
+  ports:
+  - name: admin
+    port: 9990
+  - name: metrics
+    port: 9991`,
+				`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name7-1
+  namespace: ns
+spec:
+  containers:
+  - name: app
+    ports:
+    - name: web
+      containerPort: 8080
+status:
+  phase: Running
+  podIP: 172.17.0.41`,
+			},
+			service: &serviceId{namespace: "ns", name: "name7"},
+			port:    uint32(80),
+			expectedAddresses: []string{
+				"172.17.0.41:8080",
+			},
+			expectedNoEndpoints:              false,
+			expectedNoEndpointsServiceExists: false,
+		},
+		{
+			serviceType: "headless services publishing not ready addresses",
+			k8sConfigs: []string{`
+apiVersion: v1
+kind: Service
+metadata:
+  name: name8
+  namespace: ns
+spec:
+  clusterIP: None
+  publishNotReadyAddresses: true
+  ports:
+  - name: db
+    port: 5432`,
+				`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: name8
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 172.17.0.51
+    targetRef:
+      kind: Pod
+      name: name8-0
+      namespace: ns
+  notReadyAddresses:
+  - ip: 172.17.0.52
+    targetRef:
+      kind: Pod
+      name: name8-1
+      namespace: ns
+  ports:
+  - name: db
+    port: 5432`,
+				`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name8-0
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.51`,
+				`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name8-1
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.52`,
+			},
+			service: &serviceId{namespace: "ns", name: "name8"},
+			port:    uint32(5432),
+			expectedAddresses: []string{
+				"172.17.0.51:5432",
+				"172.17.0.52:5432",
+			},
+			expectedNoEndpoints:              false,
+			expectedNoEndpointsServiceExists: false,
+		},
 		{
 			serviceType:                      "services that do not yet exist",
 			k8sConfigs:                       []string{},
@@ -299,3 +523,102 @@ spec:
 		})
 	}
 }
+
+func TestEndpointsWatcherUpdates(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: name1
+  namespace: ns
+spec:
+  ports:
+  - port: 8989`,
+		`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name1-1
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.12`,
+		`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: name1-2
+  namespace: ns
+status:
+  phase: Running
+  podIP: 172.17.0.19`,
+	)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	watcher := newEndpointsWatcher(k8sAPI)
+
+	k8sAPI.Sync(nil)
+
+	listener, cancelFn := newCollectUpdateListener()
+	defer cancelFn()
+
+	service := &serviceId{namespace: "ns", name: "name1"}
+	err = watcher.subscribe(service, 8989, listener)
+	if err != nil {
+		t.Fatalf("subscribe returned an error: %s", err)
+	}
+
+	endpoints := func(resourceVersion string, pods ...string) *v1.Endpoints {
+		addresses := make([]v1.EndpointAddress, 0)
+		for _, pod := range pods {
+			ip := map[string]string{"name1-1": "172.17.0.12", "name1-2": "172.17.0.19"}[pod]
+			addresses = append(addresses, v1.EndpointAddress{
+				IP:        ip,
+				TargetRef: &v1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "ns"},
+			})
+		}
+		return &v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "name1", Namespace: "ns", ResourceVersion: resourceVersion},
+			Subsets: []v1.EndpointSubset{
+				{Addresses: addresses, Ports: []v1.EndpointPort{{Port: 8989}}},
+			},
+		}
+	}
+
+	current := endpoints("1", "name1-1", "name1-2")
+	watcher.addEndpoints(current)
+	if len(listener.added) != 2 || len(listener.removed) != 0 {
+		t.Fatalf("Expected 2 addresses to be added, got added %v and removed %v", listener.added, listener.removed)
+	}
+
+	t.Run("Doesn't publish resyncs of the endpoints", func(t *testing.T) {
+		watcher.updateEndpoints(current, current)
+		if len(listener.added) != 2 || len(listener.removed) != 0 {
+			t.Fatalf("Expected no update, got added %v and removed %v", listener.added, listener.removed)
+		}
+	})
+
+	t.Run("Doesn't publish updates that don't change the addresses", func(t *testing.T) {
+		updated := endpoints("2", "name1-1", "name1-2")
+		updated.Annotations = map[string]string{"example.com/updated": "true"}
+		watcher.updateEndpoints(current, updated)
+		current = updated
+		if len(listener.added) != 2 || len(listener.removed) != 0 {
+			t.Fatalf("Expected no update, got added %v and removed %v", listener.added, listener.removed)
+		}
+	})
+
+	t.Run("Publishes the removed addresses", func(t *testing.T) {
+		updated := endpoints("3", "name1-1")
+		watcher.updateEndpoints(current, updated)
+		current = updated
+		if len(listener.added) != 2 || len(listener.removed) != 1 {
+			t.Fatalf("Expected 1 address to be removed, got added %v and removed %v", listener.added, listener.removed)
+		}
+		if removed := addr.ProxyAddressToString(listener.removed[0].address); removed != "172.17.0.19:8989" {
+			t.Fatalf("Expected 172.17.0.19:8989 to be removed, got %s", removed)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	net "github.com/linkerd/linkerd2-proxy-api/go/net"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// externalNameRefreshInterval is how often the external names of
+	// ExternalName services are resolved again, to follow the changes of their
+	// addresses and of the services. The Go resolver doesn't expose the TTLs of
+	// the records, so this bounds how long the addresses of a name are kept
+	// after they expire.
+	externalNameRefreshInterval = 30 * time.Second
+
+	// externalNameLookupTimeout bounds each resolution of an external name,
+	// so that an unresponsive DNS server doesn't hold up the stream.
+	externalNameLookupTimeout = 5 * time.Second
+)
+
+var errNoIPv4Addresses = errors.New("the name has no IPv4 addresses")
+
+// externalNameResolution resolves the external name of an ExternalName
+// service to the addresses that are sent to proxies, since the Destination
+// API can't send them the name itself.
+type externalNameResolution struct {
+	name       string
+	port       uint32
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	// the addresses last sent to the listener, nil until the name is resolved
+	addresses []*updateAddress
+}
+
+// refresh resolves the name again, and sends the changes of its addresses to
+// the listener. If the name has never been resolved to an IPv4 address, it
+// tells the listener that the service doesn't exist, so that the proxy falls
+// back to resolving the name itself, and returns false. Failures to resolve
+// the name after that leave the last addresses in place.
+func (r *externalNameResolution) refresh(listener updateListener) bool {
+	addresses, err := r.resolve()
+	if err != nil {
+		if r.addresses == nil {
+			log.Infof("Not resolving external name %s, letting the proxy resolve it: %s", r.name, err)
+			listener.NoEndpoints(false)
+			return false
+		}
+		log.Errorf("Error resolving external name %s, keeping its last addresses: %s", r.name, err)
+		return true
+	}
+
+	add, remove := diffUpdateAddresses(r.addresses, addresses)
+	if len(add) > 0 || len(remove) > 0 {
+		listener.Update(add, remove)
+	}
+	r.addresses = addresses
+	return true
+}
+
+// clear removes the addresses last sent to the listener.
+func (r *externalNameResolution) clear(listener updateListener) {
+	if len(r.addresses) > 0 {
+		listener.Update(nil, r.addresses)
+	}
+	r.addresses = nil
+}
+
+func (r *externalNameResolution) resolve() ([]*updateAddress, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalNameLookupTimeout)
+	defer cancel()
+
+	hosts, err := r.lookupHost(ctx, r.name)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]*updateAddress, 0)
+	for _, host := range hosts {
+		// the proxies are only sent IPv4 addresses
+		ip, err := addr.ParseProxyIPV4(host)
+		if err != nil {
+			continue
+		}
+		addresses = append(addresses, &updateAddress{
+			address: &net.TcpAddress{Ip: ip, Port: r.port},
+		})
+	}
+
+	if len(addresses) == 0 {
+		return nil, errNoIPv4Addresses
+	}
+	return addresses, nil
+}
@@ -0,0 +1,202 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func addressStrings(addresses []*updateAddress) []string {
+	strs := make([]string, 0)
+	for _, a := range addresses {
+		strs = append(strs, addr.ProxyAddressToString(a.address))
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+func TestExternalNameResolution(t *testing.T) {
+	t.Run("Sends the changes of the IPv4 addresses of the name", func(t *testing.T) {
+		hosts := []string{"10.0.0.1", "10.0.0.2", "2001:db8::1"}
+		resolution := &externalNameResolution{
+			name: "example.com",
+			port: 443,
+			lookupHost: func(_ context.Context, host string) ([]string, error) {
+				if host != "example.com" {
+					t.Fatalf("Unexpected lookup of %s", host)
+				}
+				return hosts, nil
+			},
+		}
+
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		if !resolution.refresh(listener) {
+			t.Fatal("Expected the name to be resolved")
+		}
+		expected := []string{"10.0.0.1:443", "10.0.0.2:443"}
+		if actual := addressStrings(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected added addresses %v, got %v", expected, actual)
+		}
+
+		listener.added = nil
+		hosts = []string{"10.0.0.2", "10.0.0.3"}
+		resolution.refresh(listener)
+
+		expected = []string{"10.0.0.3:443"}
+		if actual := addressStrings(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected added addresses %v, got %v", expected, actual)
+		}
+		expected = []string{"10.0.0.1:443"}
+		if actual := addressStrings(listener.removed); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected removed addresses %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("Keeps the last addresses when the name can't be resolved again", func(t *testing.T) {
+		var lookupErr error
+		resolution := &externalNameResolution{
+			name: "example.com",
+			port: 443,
+			lookupHost: func(_ context.Context, host string) ([]string, error) {
+				return []string{"10.0.0.1"}, lookupErr
+			},
+		}
+
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		resolution.refresh(listener)
+		lookupErr = errors.New("lookup failed")
+		if !resolution.refresh(listener) {
+			t.Fatal("Expected the name to remain resolved")
+		}
+
+		if len(listener.removed) != 0 || listener.noEndpointsCalled {
+			t.Fatalf("Expected the addresses to be kept, got removed %v", addressStrings(listener.removed))
+		}
+	})
+
+	t.Run("Lets the proxy resolve names without IPv4 addresses", func(t *testing.T) {
+		resolution := &externalNameResolution{
+			name: "example.com",
+			port: 443,
+			lookupHost: func(_ context.Context, host string) ([]string, error) {
+				return []string{"2001:db8::1"}, nil
+			},
+		}
+
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		if resolution.refresh(listener) {
+			t.Fatal("Expected the name not to be resolved")
+		}
+		if !listener.noEndpointsCalled || listener.noEndpointsExists {
+			t.Fatalf("Expected the service to be reported as not existing")
+		}
+	})
+}
+
+func TestResolveExternalNameService(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: payments
+  namespace: ns
+spec:
+  type: ExternalName
+  externalName: payments.example.com`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	resolver := newK8sResolver([]string{}, k8sAPI)
+	resolver.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		if host != "payments.example.com" {
+			t.Fatalf("Unexpected lookup of %s", host)
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	k8sAPI.Sync(nil)
+
+	listener, cancelFn := newCollectUpdateListener()
+	// the stream is closed beforehand, so that the resolution returns after
+	// sending the first addresses
+	cancelFn()
+
+	err = resolver.resolveKubernetesService(&serviceId{namespace: "ns", name: "payments"}, 8080, listener)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []string{"10.0.0.1:8080"}
+	if actual := addressStrings(listener.added); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected added addresses %v, got %v", expected, actual)
+	}
+	if listener.noEndpointsCalled {
+		t.Fatal("Expected the service's addresses to be resolved")
+	}
+}
+
+func TestResolveExternalNameServiceTransition(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: payments
+  namespace: ns
+spec:
+  type: ExternalName
+  externalName: payments.example.com`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	resolver := newK8sResolver([]string{}, k8sAPI)
+	resolver.externalNameRefreshInterval = 10 * time.Millisecond
+	resolver.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		// the service is replaced by a ClusterIP service once its external
+		// name is resolved
+		svc, err := k8sAPI.Client.CoreV1().Services("ns").Get("payments", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		svc.Spec.Type = v1.ServiceTypeClusterIP
+		svc.Spec.ExternalName = ""
+		if _, err := k8sAPI.Client.CoreV1().Services("ns").Update(svc); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	k8sAPI.Sync(nil)
+
+	listener, cancelFn := newCollectUpdateListener()
+	defer cancelFn()
+	timeout := time.AfterFunc(5*time.Second, cancelFn)
+	defer timeout.Stop()
+
+	if !resolver.resolveExternalName(&serviceId{namespace: "ns", name: "payments"}, "payments.example.com", 8080, listener) {
+		t.Fatal("Expected the service to be resolved as a ClusterIP service")
+	}
+
+	expected := []string{"10.0.0.1:8080"}
+	if actual := addressStrings(listener.added); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected added addresses %v, got %v", expected, actual)
+	}
+	if actual := addressStrings(listener.removed); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected removed addresses %v, got %v", expected, actual)
+	}
+}
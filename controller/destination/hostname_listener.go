@@ -0,0 +1,62 @@
+package destination
+
+import (
+	"github.com/linkerd/linkerd2/pkg/addr"
+)
+
+// hostnameListener forwards the addresses of the endpoints with the hostname
+// to its listener, i.e. the address of a single pod of a StatefulSet.
+//
+// implements the updateListener interface
+type hostnameListener struct {
+	updateListener
+	hostname string
+	// the addresses of the endpoints with the hostname, keyed by their string
+	// representation
+	addresses map[string]struct{}
+}
+
+func newHostnameListener(hostname string, listener updateListener) *hostnameListener {
+	return &hostnameListener{
+		updateListener: listener,
+		hostname:       hostname,
+		addresses:      make(map[string]struct{}),
+	}
+}
+
+// Update forwards the added and removed addresses with the hostname. The
+// listener is told that there are no endpoints when no address has it.
+func (l *hostnameListener) Update(add, remove []*updateAddress) {
+	add = l.filter(add)
+	remove = l.filter(remove)
+
+	for _, a := range remove {
+		delete(l.addresses, addr.ProxyAddressToString(a.address))
+	}
+	for _, a := range add {
+		l.addresses[addr.ProxyAddressToString(a.address)] = struct{}{}
+	}
+
+	if len(l.addresses) == 0 {
+		l.updateListener.NoEndpoints(true)
+		return
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		l.updateListener.Update(add, remove)
+	}
+}
+
+func (l *hostnameListener) NoEndpoints(exists bool) {
+	l.addresses = make(map[string]struct{})
+	l.updateListener.NoEndpoints(exists)
+}
+
+func (l *hostnameListener) filter(addresses []*updateAddress) []*updateAddress {
+	filtered := make([]*updateAddress, 0)
+	for _, a := range addresses {
+		if a.hostname == l.hostname {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,59 @@
+package destination
+
+import (
+	"testing"
+
+	"github.com/linkerd/linkerd2-proxy-api/go/net"
+)
+
+func TestHostnameListener(t *testing.T) {
+	web0 := &updateAddress{
+		address:  &net.TcpAddress{Ip: &net.IPAddress{Ip: &net.IPAddress_Ipv4{Ipv4: 1}}, Port: 8080},
+		hostname: "web-0",
+	}
+	web1 := &updateAddress{
+		address:  &net.TcpAddress{Ip: &net.IPAddress{Ip: &net.IPAddress_Ipv4{Ipv4: 2}}, Port: 8080},
+		hostname: "web-1",
+	}
+
+	t.Run("Forwards the addresses with the hostname", func(t *testing.T) {
+		collect, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+		listener := newHostnameListener("web-0", collect)
+
+		listener.Update([]*updateAddress{web0, web1}, nil)
+
+		if len(collect.added) != 1 || collect.added[0] != web0 {
+			t.Fatalf("Expected only web-0 to be added, got %v", collect.added)
+		}
+		if collect.noEndpointsCalled {
+			t.Fatal("Expected NoEndpoints not to be called")
+		}
+	})
+
+	t.Run("Sends NoEndpoints when no address has the hostname", func(t *testing.T) {
+		collect, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+		listener := newHostnameListener("web-0", collect)
+
+		listener.Update([]*updateAddress{web0}, nil)
+		listener.Update(nil, []*updateAddress{web0})
+
+		if !collect.noEndpointsCalled || !collect.noEndpointsExists {
+			t.Fatal("Expected NoEndpoints to be called for an existing service")
+		}
+	})
+
+	t.Run("Ignores the updates of other addresses", func(t *testing.T) {
+		collect, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+		listener := newHostnameListener("web-0", collect)
+
+		listener.Update([]*updateAddress{web0}, nil)
+		listener.Update(nil, []*updateAddress{web1})
+
+		if len(collect.added) != 1 || len(collect.removed) != 0 || collect.noEndpointsCalled {
+			t.Fatalf("Expected no update, got added %v and removed %v", collect.added, collect.removed)
+		}
+	})
+}
@@ -1,14 +1,19 @@
 package destination
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/profiles"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
 )
 
 var dnsCharactersRegexp = regexp.MustCompile("^[a-zA-Z0-9_-]{0,63}$")
@@ -16,14 +21,27 @@ var containsAlphaRegexp = regexp.MustCompile("[a-zA-Z]")
 
 // implements the streamingDestinationResolver interface
 type k8sResolver struct {
-	k8sDNSZoneLabels []string
-	endpointsWatcher *endpointsWatcher
+	k8sDNSZoneLabels    []string
+	endpointsWatcher    *endpointsWatcher
+	trafficSplitWatcher *trafficSplitWatcher
+	profileWatcher      *profileWatcher
+	lookupHost          func(ctx context.Context, host string) ([]string, error)
+
+	// externalNameRefreshInterval is how often the external names of
+	// ExternalName services, and the services themselves, are checked again
+	externalNameRefreshInterval time.Duration
 }
 
 func newK8sResolver(k8sDNSZoneLabels []string, k8sAPI *k8s.API) *k8sResolver {
+	endpointsWatcher := newEndpointsWatcher(k8sAPI)
 	return &k8sResolver{
-		k8sDNSZoneLabels: k8sDNSZoneLabels,
-		endpointsWatcher: newEndpointsWatcher(k8sAPI),
+		k8sDNSZoneLabels:    k8sDNSZoneLabels,
+		endpointsWatcher:    endpointsWatcher,
+		trafficSplitWatcher: newTrafficSplitWatcher(k8sAPI, endpointsWatcher),
+		profileWatcher:      newProfileWatcher(k8sAPI),
+		lookupHost:          net.DefaultResolver.LookupHost,
+
+		externalNameRefreshInterval: externalNameRefreshInterval,
 	}
 }
 
@@ -46,7 +64,7 @@ func (k *k8sResolver) canResolve(host string, port int) (bool, error) {
 }
 
 func (k *k8sResolver) streamResolution(host string, port int, listener updateListener) error {
-	id, err := k.localKubernetesServiceIdFromDNSName(host)
+	id, hostname, err := k.localKubernetesHostFromDNSName(host)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -60,32 +78,143 @@ func (k *k8sResolver) streamResolution(host string, port int, listener updateLis
 
 	listener.SetServiceId(id)
 
+	if hostname != "" {
+		return k.resolveKubernetesPod(id, hostname, port, listener)
+	}
 	return k.resolveKubernetesService(id, port, listener)
 }
 
+// streamProfiles streams the ServiceProfile of the service, which is named
+// after its fully-qualified domain name in the Kubernetes DNS zone.
+func (k *k8sResolver) streamProfiles(host string, listener profileUpdateListener) error {
+	id, err := k.localKubernetesServiceIdFromDNSName(host)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if id == nil {
+		err = fmt.Errorf("cannot get profile of service that isn't a local Kubernetes service: %s", host)
+		log.Error(err)
+		return err
+	}
+
+	profile := profileId{
+		namespace: id.namespace,
+		name:      profiles.ProfileName(id.name, id.namespace, strings.Join(k.k8sDNSZoneLabels, ".")),
+	}
+	k.profileWatcher.subscribe(profile, listener)
+	defer k.profileWatcher.unsubscribe(profile, listener)
+
+	if err := listener.Run(); err != nil {
+		log.Errorf("Failed to send profile %s.%s: %s", profile.name, profile.namespace, err)
+		return err
+	}
+	return nil
+}
+
 func (k *k8sResolver) stop() {
 	k.endpointsWatcher.stop()
+	k.profileWatcher.stop()
 }
 
 func (k *k8sResolver) resolveKubernetesService(id *serviceId, port int, listener updateListener) error {
-	k.endpointsWatcher.subscribe(id, uint32(port), listener)
+	svc, err := k.endpointsWatcher.getService(id)
+	if err == nil && svc.Spec.Type == v1.ServiceTypeExternalName {
+		if !k.resolveExternalName(id, svc.Spec.ExternalName, port, listener) {
+			return nil
+		}
+	}
+
+	// the addresses of the service's own endpoints, or of its backends if its
+	// traffic is split
+	resolution := k.trafficSplitWatcher.subscribe(id, uint32(port), listener)
+
+	select {
+	case <-listener.ClientClose():
+	case <-listener.ServerClose():
+	}
+	k.trafficSplitWatcher.unsubscribe(resolution)
+	return nil
+}
+
+// resolveKubernetesPod streams the address of the pod of the service with the
+// hostname, e.g. a replica of a StatefulSet, whose pods are addressed by
+// "$hostname.$service.$namespace.svc.$zone". The traffic splits of the service
+// don't apply to its pods.
+func (k *k8sResolver) resolveKubernetesPod(id *serviceId, hostname string, port int, listener updateListener) error {
+	podListener := newHostnameListener(hostname, listener)
+	err := k.endpointsWatcher.subscribe(id, uint32(port), podListener)
+	if err != nil {
+		return err
+	}
 
 	select {
 	case <-listener.ClientClose():
-		return k.endpointsWatcher.unsubscribe(id, uint32(port), listener)
 	case <-listener.ServerClose():
-		return nil
+	}
+	return k.endpointsWatcher.unsubscribe(id, uint32(port), podListener)
+}
+
+// resolveExternalName streams the addresses of the external name of an
+// ExternalName service, resolving it again every externalNameRefreshInterval,
+// until the stream is closed. The external name of the service is followed as
+// it changes. If the service stops being an ExternalName service, e.g. when
+// it's replaced by a ClusterIP service, the addresses of its external name are
+// removed and true is returned, for the service to be resolved as such.
+func (k *k8sResolver) resolveExternalName(id *serviceId, name string, port int, listener updateListener) bool {
+	resolution := &externalNameResolution{
+		name:       name,
+		port:       uint32(port),
+		lookupHost: k.lookupHost,
+	}
+	resolved := resolution.refresh(listener)
+
+	ticker := time.NewTicker(k.externalNameRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-listener.ClientClose():
+			return false
+		case <-listener.ServerClose():
+			return false
+		case <-ticker.C:
+			// once the proxy has been told to resolve the name itself, it
+			// doesn't expect any more updates, and follows the changes of
+			// the service through DNS
+			if !resolved {
+				continue
+			}
+
+			svc, err := k.endpointsWatcher.getService(id)
+			if err != nil || svc.Spec.Type != v1.ServiceTypeExternalName {
+				log.Infof("Service %s is no longer an ExternalName service, resolving it as such", id)
+				resolution.clear(listener)
+				return true
+			}
+			resolution.name = svc.Spec.ExternalName
+			resolution.refresh(listener)
+		}
 	}
 }
 
 // localKubernetesServiceIdFromDNSName returns the name of the service in
 // "namespace-name/service-name" form if `host` is a DNS name in a form used
-// for local Kubernetes services. It returns nil if `host` isn't in such a
-// form.
+// for local Kubernetes services, or for the pods of those services. It returns
+// nil if `host` isn't in such a form.
 func (k *k8sResolver) localKubernetesServiceIdFromDNSName(host string) (*serviceId, error) {
+	id, _, err := k.localKubernetesHostFromDNSName(host)
+	return id, err
+}
+
+// localKubernetesHostFromDNSName is like localKubernetesServiceIdFromDNSName,
+// but also returns the hostname of the pod if `host` is the DNS name of a pod
+// of the service, in "$hostname.$service.$namespace.svc.$zone" form.
+func (k *k8sResolver) localKubernetesHostFromDNSName(host string) (*serviceId, string, error) {
 	hostLabels, err := splitDNSName(host)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Verify that `host` ends with ".svc.$zone", ".svc.cluster.local," or ".svc".
@@ -114,20 +243,25 @@ func (k *k8sResolver) localKubernetesServiceIdFromDNSName(host string) (*service
 	// workaround until the proxies are configured to know "$zone."
 	hostLabels, matched = maybeStripSuffixLabels(hostLabels, []string{"svc"})
 	if !matched {
-		return nil, nil
+		return nil, "", nil
 	}
 
-	// Extract the service name and namespace. TODO: Federated services have
-	// *three* components before "svc"; see
-	// https://github.com/linkerd/linkerd2/issues/156.
-	if len(hostLabels) != 2 {
-		return nil, fmt.Errorf("not a service: %s", host)
+	// Extract the service name and namespace, and the hostname of the pod if
+	// there are three components before "svc".
+	switch len(hostLabels) {
+	case 2:
+		return &serviceId{
+			namespace: hostLabels[1],
+			name:      hostLabels[0],
+		}, "", nil
+	case 3:
+		return &serviceId{
+			namespace: hostLabels[2],
+			name:      hostLabels[1],
+		}, hostLabels[0], nil
+	default:
+		return nil, "", fmt.Errorf("not a service: %s", host)
 	}
-
-	return &serviceId{
-		namespace: hostLabels[1],
-		name:      hostLabels[0],
-	}, nil
 }
 
 func splitDNSName(dnsName string) ([]string, error) {
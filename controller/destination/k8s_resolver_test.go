@@ -129,10 +129,31 @@ func TestLocalKubernetesServiceIdFromDNSName(t *testing.T) {
 		validServiceNames := map[string]string{"name.ns.svc": "name.ns"}
 		assertIsResolved(t, resolver, validServiceNames)
 
-		invalidServiceNames := []string{"", "something.name.ns.svc.cluster.local", "a.svc", "svc", "a.b.c.svc", "a.b.c.d.svc"}
+		invalidServiceNames := []string{"", "a.svc", "svc", "a.b.c.d.svc", "something.a.b.c.svc.cluster.local"}
 		assertReturnError(t, resolver, invalidServiceNames)
 	})
 
+	t.Run("Resolves names of the pods of services", func(t *testing.T) {
+		resolver := &k8sResolver{k8sDNSZoneLabels: someKubernetesDNSZone}
+		podNames := map[string]string{
+			"web-0.web.ns.svc.cluster.local":  "web-0",
+			"web-1.web.ns.svc.some.namespace": "web-1",
+			"a.b.c.svc":                       "a",
+		}
+
+		for name, expectedHostname := range podNames {
+			id, hostname, err := resolver.localKubernetesHostFromDNSName(name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if id == nil || hostname != expectedHostname {
+				t.Fatalf("Expected name [%s] to resolve to hostname [%s], got [%v] [%s]", name, expectedHostname, id, hostname)
+			}
+		}
+
+		assertIsResolved(t, resolver, map[string]string{"web-0.web.ns.svc.cluster.local": "web.ns"})
+	})
+
 }
 
 func TestSplitDNSName(t *testing.T) {
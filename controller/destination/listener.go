@@ -20,10 +20,40 @@ type updateListener interface {
 
 type ownerKindAndNameFn func(*coreV1.Pod) (string, string)
 
+// nodeTopologyFn returns the zone and region of a node.
+type nodeTopologyFn func(nodeName string) (string, string)
+
 // updateAddress is a pairing of TCP address to Kubernetes pod object
 type updateAddress struct {
 	address *net.TcpAddress
 	pod     *coreV1.Pod
+	// weight is the weight of the address relative to the other addresses of
+	// the service, when its traffic is split across backends. Zero means the
+	// default weight of 1.
+	weight uint32
+	// gateway is set on the addresses of the services mirrored from a remote
+	// cluster, which are the addresses of the gateway of that cluster.
+	gateway *remoteGateway
+	// hostname is the hostname of the endpoint, which is set for the pods of
+	// StatefulSets.
+	hostname string
+}
+
+// remoteGateway identifies the gateway of a remote cluster, through which the
+// services mirrored from that cluster are reached. The gateway routes the
+// requests by their authority, which is rewritten to the authority of the
+// remote service.
+type remoteGateway struct {
+	identity     string
+	controllerNs string
+	authority    string
+}
+
+func (a *updateAddress) getWeight() uint32 {
+	if a.weight == 0 {
+		return 1
+	}
+	return a.weight
 }
 
 func diffUpdateAddresses(oldAddrs, newAddrs []*updateAddress) ([]*updateAddress, []*updateAddress) {
@@ -63,21 +93,27 @@ func diffUpdateAddresses(oldAddrs, newAddrs []*updateAddress) ([]*updateAddress,
 type endpointListener struct {
 	stream           pb.Destination_GetServer
 	ownerKindAndName ownerKindAndNameFn
+	nodeTopology     nodeTopologyFn
 	labels           map[string]string
 	enableTLS        bool
+	trustDomain      string
 	stopCh           chan struct{}
 }
 
 func newEndpointListener(
 	stream pb.Destination_GetServer,
 	ownerKindAndName ownerKindAndNameFn,
+	nodeTopology nodeTopologyFn,
 	enableTLS bool,
+	trustDomain string,
 ) *endpointListener {
 	return &endpointListener{
 		stream:           stream,
 		ownerKindAndName: ownerKindAndName,
+		nodeTopology:     nodeTopology,
 		labels:           make(map[string]string),
 		enableTLS:        enableTLS,
+		trustDomain:      trustDomain,
 		stopCh:           make(chan struct{}),
 	}
 }
@@ -152,17 +188,64 @@ func (l *endpointListener) toWeightedAddrSet(addresses []*updateAddress) *pb.Wei
 }
 
 func (l *endpointListener) toWeightedAddr(address *updateAddress) *pb.WeightedAddr {
+	if address.gateway != nil {
+		return l.toGatewayWeightedAddr(address)
+	}
+
+	// the addresses of ExternalName services aren't backed by pods
+	if address.pod == nil {
+		return &pb.WeightedAddr{
+			Addr:   address.address,
+			Weight: address.getWeight(),
+		}
+	}
+
 	labels, hint, tlsIdentity := l.getAddrMetadata(address.pod)
 
 	return &pb.WeightedAddr{
 		Addr:         address.address,
-		Weight:       1,
+		Weight:       address.getWeight(),
 		MetricLabels: labels,
 		TlsIdentity:  tlsIdentity,
 		ProtocolHint: hint,
 	}
 }
 
+// toGatewayWeightedAddr returns the address of the gateway of a remote cluster.
+// The gateway is meshed, so it's hinted that it knows H2, and the connections
+// to it are secured with its TLS identity. The authority of the requests is
+// overridden with the remote service's, which the gateway routes them to.
+func (l *endpointListener) toGatewayWeightedAddr(address *updateAddress) *pb.WeightedAddr {
+	weightedAddr := &pb.WeightedAddr{
+		Addr:   address.address,
+		Weight: address.getWeight(),
+		ProtocolHint: &pb.ProtocolHint{
+			Protocol: &pb.ProtocolHint_H2_{
+				H2: &pb.ProtocolHint_H2{},
+			},
+		},
+	}
+
+	if address.gateway.authority != "" {
+		weightedAddr.AuthorityOverride = &pb.AuthorityOverride{
+			AuthorityOverride: address.gateway.authority,
+		}
+	}
+
+	if l.enableTLS && address.gateway.identity != "" {
+		weightedAddr.TlsIdentity = &pb.TlsIdentity{
+			Strategy: &pb.TlsIdentity_K8SPodIdentity_{
+				K8SPodIdentity: &pb.TlsIdentity_K8SPodIdentity{
+					PodIdentity:  address.gateway.identity,
+					ControllerNs: address.gateway.controllerNs,
+				},
+			},
+		}
+	}
+
+	return weightedAddr
+}
+
 func (l *endpointListener) toAddrSet(addresses []*updateAddress) *pb.AddrSet {
 	addrs := make([]*net.TcpAddress, 0)
 	for _, a := range addresses {
@@ -175,6 +258,7 @@ func (l *endpointListener) getAddrMetadata(pod *coreV1.Pod) (map[string]string,
 	controllerNs := pod.Labels[pkgK8s.ControllerNSLabel]
 	ownerKind, ownerName := l.ownerKindAndName(pod)
 	labels := pkgK8s.GetPodLabels(ownerKind, ownerName, pod)
+	l.addPodMetadata(labels, pod)
 
 	var hint *pb.ProtocolHint
 
@@ -195,19 +279,38 @@ func (l *endpointListener) getAddrMetadata(pod *coreV1.Pod) (map[string]string,
 		return labels, hint, nil
 	}
 
-	identity := pkgK8s.TLSIdentity{
-		Name:                ownerName,
-		Kind:                ownerKind,
-		Namespace:           pod.Namespace,
-		ControllerNamespace: controllerNs,
-	}
-
 	return labels, hint, &pb.TlsIdentity{
 		Strategy: &pb.TlsIdentity_K8SPodIdentity_{
 			K8SPodIdentity: &pb.TlsIdentity_K8SPodIdentity{
-				PodIdentity:  identity.ToDNSName(),
+				PodIdentity:  l.podIdentity(pod, ownerKind, ownerName, controllerNs),
 				ControllerNs: controllerNs,
 			},
 		},
 	}
 }
+
+// addPodMetadata adds whether the pod is a component of the control plane to
+// its labels, along with the zone and region of its node if the topology
+// labels are enabled. The ServiceAccount of the pod isn't a label: it's sent
+// as the TLS identity of pods certified by the identity service.
+func (l *endpointListener) addPodMetadata(labels map[string]string, pod *coreV1.Pod) {
+	if component := pod.Labels[pkgK8s.ControllerComponentLabel]; component != "" {
+		labels["control_plane_component"] = component
+	}
+
+	if l.nodeTopology == nil || pod.Spec.NodeName == "" {
+		return
+	}
+	zone, region := l.nodeTopology(pod.Spec.NodeName)
+	if zone != "" {
+		labels["zone"] = zone
+	}
+	if region != "" {
+		labels["region"] = region
+	}
+}
+
+// podIdentity returns the DNS name of the TLS identity of the pod's proxy.
+func (l *endpointListener) podIdentity(pod *coreV1.Pod, ownerKind, ownerName, controllerNs string) string {
+	return pkgK8s.PodIdentity(pod, ownerKind, ownerName, controllerNs, l.trustDomain)
+}
@@ -240,6 +240,96 @@ func TestEndpointListener(t *testing.T) {
 		}
 	})
 
+	t.Run("Sends TlsIdentity in the configured trust domain", func(t *testing.T) {
+		expectedTlsIdentity := &pb.TlsIdentity_K8SPodIdentity{
+			PodIdentity:  "pod-deployment.deployment.this-namespace.linkerd-managed.linkerd-namespace.svc.prod.example.com",
+			ControllerNs: "linkerd-namespace",
+		}
+
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "this-namespace",
+				Labels: map[string]string{
+					pkgK8s.ControllerNSLabel:    "linkerd-namespace",
+					pkgK8s.ProxyDeploymentLabel: "pod-deployment",
+				},
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+			},
+		}
+
+		ownerKindAndName := func(pod *v1.Pod) (string, string) {
+			return "deployment", "pod-deployment"
+		}
+
+		mockGetServer := &mockDestination_GetServer{updatesReceived: []*pb.Update{}}
+		listener := newEndpointListener(mockGetServer, ownerKindAndName, nil, true, "prod.example.com")
+
+		listener.Update([]*updateAddress{
+			&updateAddress{address: addedAddress1, pod: pod},
+		}, nil)
+
+		addrs := mockGetServer.updatesReceived[0].GetAdd().GetAddrs()
+		if len(addrs) != 1 {
+			t.Fatalf("Expected [1] address returned, got %v", addrs)
+		}
+
+		actualTlsIdentity := addrs[0].GetTlsIdentity().GetK8SPodIdentity()
+		if !reflect.DeepEqual(actualTlsIdentity, expectedTlsIdentity) {
+			t.Fatalf("Expected TlsIdentity to be [%v] but was [%v]", expectedTlsIdentity, actualTlsIdentity)
+		}
+	})
+
+	t.Run("Sends the ServiceAccount TlsIdentity of pods certified by the identity service", func(t *testing.T) {
+		expectedTlsIdentity := &pb.TlsIdentity_K8SPodIdentity{
+			PodIdentity:  "web.this-namespace.serviceaccount.identity.linkerd-namespace.prod.example.com",
+			ControllerNs: "linkerd-namespace",
+		}
+
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "this-namespace",
+				Labels: map[string]string{
+					pkgK8s.ControllerNSLabel:    "linkerd-namespace",
+					pkgK8s.ProxyDeploymentLabel: "pod-deployment",
+				},
+				Annotations: map[string]string{
+					pkgK8s.IdentityModeAnnotation: pkgK8s.IdentityModeServiceAccount,
+				},
+			},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "web",
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+			},
+		}
+
+		ownerKindAndName := func(pod *v1.Pod) (string, string) {
+			return "deployment", "pod-deployment"
+		}
+
+		mockGetServer := &mockDestination_GetServer{updatesReceived: []*pb.Update{}}
+		listener := newEndpointListener(mockGetServer, ownerKindAndName, nil, true, "prod.example.com")
+
+		listener.Update([]*updateAddress{
+			&updateAddress{address: addedAddress1, pod: pod},
+		}, nil)
+
+		addrs := mockGetServer.updatesReceived[0].GetAdd().GetAddrs()
+		if len(addrs) != 1 {
+			t.Fatalf("Expected [1] address returned, got %v", addrs)
+		}
+
+		actualTlsIdentity := addrs[0].GetTlsIdentity().GetK8SPodIdentity()
+		if !reflect.DeepEqual(actualTlsIdentity, expectedTlsIdentity) {
+			t.Fatalf("Expected TlsIdentity to be [%v] but was [%v]", expectedTlsIdentity, actualTlsIdentity)
+		}
+	})
+
 	t.Run("Does not send TlsIdentity when not enabled", func(t *testing.T) {
 		expectedPodName := "pod1"
 		expectedPodNamespace := "this-namespace"
@@ -284,6 +374,87 @@ func TestEndpointListener(t *testing.T) {
 			t.Fatalf("Expected no TlsIdentity to be sent, but got [%v]", addrs[0].TlsIdentity)
 		}
 	})
+
+	t.Run("Sends the control plane component and topology of pods", func(t *testing.T) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "linkerd-controller-1",
+				Namespace: "linkerd",
+				Labels: map[string]string{
+					pkgK8s.ControllerComponentLabel: "controller",
+				},
+			},
+			Spec: v1.PodSpec{
+				ServiceAccountName: "linkerd-controller",
+				NodeName:           "node-1",
+			},
+		}
+
+		ownerKindAndName := func(pod *v1.Pod) (string, string) {
+			return "deployment", "linkerd-controller"
+		}
+		nodeTopology := func(nodeName string) (string, string) {
+			if nodeName != "node-1" {
+				t.Fatalf("Unexpected node [%s]", nodeName)
+			}
+			return "us-east-1a", "us-east-1"
+		}
+
+		mockGetServer := &mockDestination_GetServer{updatesReceived: []*pb.Update{}}
+		listener := newEndpointListener(mockGetServer, ownerKindAndName, nodeTopology, false, "")
+
+		listener.Update([]*updateAddress{&updateAddress{address: addedAddress1, pod: pod}}, nil)
+
+		actualLabels := mockGetServer.updatesReceived[0].GetAdd().Addrs[0].MetricLabels
+		expectedLabels := map[string]string{
+			"pod":                     "linkerd-controller-1",
+			"deployment":              "linkerd-controller",
+			"control_plane_component": "controller",
+			"zone":                    "us-east-1a",
+			"region":                  "us-east-1",
+		}
+		if !reflect.DeepEqual(actualLabels, expectedLabels) {
+			t.Fatalf("Expected metric labels [%v] but was [%v]", expectedLabels, actualLabels)
+		}
+	})
+
+	t.Run("Sends the TlsIdentity of the gateway for mirrored services", func(t *testing.T) {
+		expectedIdentity := "linkerd-gateway.deployment.linkerd.linkerd-managed.linkerd.svc.east.example.com"
+		expectedControllerNamespace := "linkerd"
+
+		mockGetServer := &mockDestination_GetServer{updatesReceived: []*pb.Update{}}
+		listener := &endpointListener{
+			stream:    mockGetServer,
+			enableTLS: true,
+		}
+
+		add := []*updateAddress{
+			&updateAddress{
+				address: addedAddress1,
+				gateway: &remoteGateway{identity: expectedIdentity, controllerNs: expectedControllerNamespace, authority: "books.booksapp.svc.east.example.com:7000"},
+			},
+		}
+		listener.Update(add, nil)
+
+		addrs := mockGetServer.updatesReceived[0].GetAdd().GetAddrs()
+		if len(addrs) != 1 {
+			t.Fatalf("Expected [1] address returned, got %v", addrs)
+		}
+		checkAddress(t, addrs[0], addedAddress1)
+
+		if addrs[0].GetProtocolHint().GetH2() == nil {
+			t.Fatalf("Expected the gateway to be hinted as H2, got [%v]", addrs[0].ProtocolHint)
+		}
+
+		if authority := addrs[0].GetAuthorityOverride().GetAuthorityOverride(); authority != "books.booksapp.svc.east.example.com:7000" {
+			t.Fatalf("Expected the authority to be overridden with the remote service's, got [%s]", authority)
+		}
+
+		identity := addrs[0].GetTlsIdentity().GetK8SPodIdentity()
+		if identity.GetPodIdentity() != expectedIdentity || identity.GetControllerNs() != expectedControllerNamespace {
+			t.Fatalf("Expected the TlsIdentity of the gateway, got [%v]", addrs[0].TlsIdentity)
+		}
+	})
 }
 
 func checkAddress(t *testing.T, addr *pb.WeightedAddr, expectedAddress *net.TcpAddress) {
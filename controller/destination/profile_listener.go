@@ -0,0 +1,111 @@
+package destination
+
+import (
+	"sync"
+
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	log "github.com/sirupsen/logrus"
+)
+
+type profileUpdateListener interface {
+	Update(profile *sp.ServiceProfile)
+	Run() error
+	ClientClose() <-chan struct{}
+	ServerClose() <-chan struct{}
+	Stop()
+}
+
+// implements the profileUpdateListener interface
+type profileListener struct {
+	stream pb.Destination_GetProfileServer
+	stopCh chan struct{}
+
+	// the latest profile, to be sent by Run whenever updatedCh is signaled
+	profile   *sp.ServiceProfile
+	updatedCh chan struct{}
+	mutex     sync.Mutex
+}
+
+func newProfileListener(stream pb.Destination_GetProfileServer) *profileListener {
+	return &profileListener{
+		stream:    stream,
+		stopCh:    make(chan struct{}),
+		updatedCh: make(chan struct{}, 1),
+	}
+}
+
+func (l *profileListener) ClientClose() <-chan struct{} {
+	return l.stream.Context().Done()
+}
+
+func (l *profileListener) ServerClose() <-chan struct{} {
+	return l.stopCh
+}
+
+func (l *profileListener) Stop() {
+	close(l.stopCh)
+}
+
+// Update records the profile, which Run sends to the proxy. It doesn't block,
+// so that the profile watcher can update its listeners while holding its
+// lock; if the proxy is slower than the updates, only the latest profile is
+// sent.
+func (l *profileListener) Update(profile *sp.ServiceProfile) {
+	l.mutex.Lock()
+	l.profile = profile
+	l.mutex.Unlock()
+
+	select {
+	case l.updatedCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends the profiles recorded by Update to the proxy, until the stream or
+// the server is closed, or a profile can't be sent, in which case the error is
+// returned. A pending profile is sent before Run returns on close.
+func (l *profileListener) Run() error {
+	for {
+		select {
+		case <-l.updatedCh:
+			if err := l.send(); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+
+		select {
+		case <-l.updatedCh:
+			if err := l.send(); err != nil {
+				return err
+			}
+		case <-l.ClientClose():
+			return nil
+		case <-l.ServerClose():
+			return nil
+		}
+	}
+}
+
+// send sends the routes of the latest profile to the proxy. Services without
+// a valid profile have no routes.
+func (l *profileListener) send() error {
+	l.mutex.Lock()
+	profile := l.profile
+	l.mutex.Unlock()
+
+	destinationProfile := &pb.DestinationProfile{}
+	if profile != nil {
+		var err error
+		destinationProfile, err = profiles.ToServiceProfile(&profile.Spec)
+		if err != nil {
+			log.Errorf("Ignoring invalid profile %s.%s: %s", profile.Name, profile.Namespace, err)
+			destinationProfile = &pb.DestinationProfile{}
+		}
+	}
+
+	return l.stream.Send(destinationProfile)
+}
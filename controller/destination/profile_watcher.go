@@ -0,0 +1,156 @@
+package destination
+
+import (
+	"sync"
+
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type profileId struct {
+	namespace string
+	name      string
+}
+
+// profileWatcher watches the ServiceProfiles in the Kubernetes cluster.
+// Listeners can subscribe to a ServiceProfile, and profileWatcher will publish
+// it, and all its future changes, to them.
+type profileWatcher struct {
+	k8sAPI   *k8s.API
+	recorder *k8s.EventRecorder
+	// a map of profile -> listeners of the profile
+	listeners map[profileId][]profileUpdateListener
+	// This mutex protects the listeners map, and serializes the updates of the
+	// listeners, which don't block: the listeners send the profiles to the
+	// proxies on their own streams.
+	mutex sync.Mutex
+}
+
+func newProfileWatcher(k8sAPI *k8s.API) *profileWatcher {
+	watcher := &profileWatcher{
+		k8sAPI:    k8sAPI,
+		recorder:  k8s.NewEventRecorder(k8sAPI.Client, "linkerd-destination"),
+		listeners: make(map[profileId][]profileUpdateListener),
+	}
+
+	// the services have no profile if the ServiceProfiles aren't served
+	if k8sAPI.HasServiceProfiles() {
+		k8sAPI.SP().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    watcher.addProfile,
+				UpdateFunc: watcher.updateProfile,
+				DeleteFunc: watcher.deleteProfile,
+			},
+		)
+	}
+
+	return watcher
+}
+
+// Close all open streams on shutdown
+func (p *profileWatcher) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, listeners := range p.listeners {
+		for _, listener := range listeners {
+			listener.Stop()
+		}
+	}
+}
+
+// subscribe sends the profile to the listener, and then each of its updates.
+// Listeners are sent nil if the profile doesn't exist.
+func (p *profileWatcher) subscribe(id profileId, listener profileUpdateListener) {
+	log.Infof("Establishing watch on profile %s.%s", id.name, id.namespace)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.listeners[id] = append(p.listeners[id], listener)
+
+	profile, err := p.k8sAPI.GetServiceProfile(id.namespace, id.name)
+	if err != nil {
+		log.Errorf("Error getting profile %s.%s: %s", id.name, id.namespace, err)
+	}
+	listener.Update(profile)
+}
+
+func (p *profileWatcher) unsubscribe(id profileId, listener profileUpdateListener) {
+	log.Infof("Stopping watch on profile %s.%s", id.name, id.namespace)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	listeners := p.listeners[id]
+	for i, item := range listeners {
+		if item == listener {
+			// delete the item from the slice
+			listeners[i] = listeners[len(listeners)-1]
+			listeners[len(listeners)-1] = nil
+			listeners = listeners[:len(listeners)-1]
+			break
+		}
+	}
+
+	if len(listeners) == 0 {
+		delete(p.listeners, id)
+	} else {
+		p.listeners[id] = listeners
+	}
+}
+
+func (p *profileWatcher) addProfile(obj interface{}) {
+	profile := obj.(*sp.ServiceProfile)
+	p.validate(profile)
+	p.publish(profileId{namespace: profile.Namespace, name: profile.Name}, profile)
+}
+
+func (p *profileWatcher) updateProfile(oldObj, newObj interface{}) {
+	profile := newObj.(*sp.ServiceProfile)
+	// the periodic resyncs of the informer don't change the profile, which
+	// has already been validated
+	if profile.ResourceVersion != oldObj.(*sp.ServiceProfile).ResourceVersion {
+		p.validate(profile)
+	}
+	p.publish(profileId{namespace: profile.Namespace, name: profile.Name}, profile)
+}
+
+// validate records a warning event on the profile if it's invalid, since
+// invalid profiles are ignored and their services served without routes.
+func (p *profileWatcher) validate(profile *sp.ServiceProfile) {
+	if _, err := profiles.ToServiceProfile(&profile.Spec); err != nil {
+		p.recorder.Eventf(profile, v1.EventTypeWarning, "InvalidServiceProfile",
+			"Ignoring the invalid profile: %s", err)
+	}
+}
+
+func (p *profileWatcher) deleteProfile(obj interface{}) {
+	profile, ok := obj.(*sp.ServiceProfile)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorf("Couldn't get object from tombstone %+v", obj)
+			return
+		}
+		profile, ok = tombstone.Obj.(*sp.ServiceProfile)
+		if !ok {
+			log.Errorf("Tombstone contained object that is not a ServiceProfile %+v", obj)
+			return
+		}
+	}
+	p.publish(profileId{namespace: profile.Namespace, name: profile.Name}, nil)
+}
+
+func (p *profileWatcher) publish(id profileId, profile *sp.ServiceProfile) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, listener := range p.listeners[id] {
+		listener.Update(profile)
+	}
+}
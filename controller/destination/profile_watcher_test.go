@@ -0,0 +1,282 @@
+package destination
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"google.golang.org/grpc/metadata"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// implements the profileUpdateListener interface
+type collectProfileListener struct {
+	profiles []*sp.ServiceProfile
+	context  context.Context
+	stopCh   chan struct{}
+}
+
+func (c *collectProfileListener) Update(profile *sp.ServiceProfile) {
+	c.profiles = append(c.profiles, profile)
+}
+
+func (c *collectProfileListener) Run() error {
+	select {
+	case <-c.ClientClose():
+	case <-c.ServerClose():
+	}
+	return nil
+}
+
+func (c *collectProfileListener) ClientClose() <-chan struct{} {
+	return c.context.Done()
+}
+
+func (c *collectProfileListener) ServerClose() <-chan struct{} {
+	return c.stopCh
+}
+
+func (c *collectProfileListener) Stop() {
+	close(c.stopCh)
+}
+
+type mockDestination_GetProfileServer struct {
+	contextToReturn  context.Context
+	profilesReceived []*pb.DestinationProfile
+	errorToReturn    error
+}
+
+func (m *mockDestination_GetProfileServer) Send(profile *pb.DestinationProfile) error {
+	if m.errorToReturn != nil {
+		return m.errorToReturn
+	}
+	m.profilesReceived = append(m.profilesReceived, profile)
+	return nil
+}
+
+func (m *mockDestination_GetProfileServer) SetHeader(metadata.MD) error  { return nil }
+func (m *mockDestination_GetProfileServer) SendHeader(metadata.MD) error { return nil }
+func (m *mockDestination_GetProfileServer) SetTrailer(metadata.MD)       {}
+func (m *mockDestination_GetProfileServer) Context() context.Context     { return m.contextToReturn }
+func (m *mockDestination_GetProfileServer) SendMsg(x interface{}) error  { return nil }
+func (m *mockDestination_GetProfileServer) RecvMsg(x interface{}) error  { return nil }
+
+const booksProfile = `
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+  namespace: booksapp
+spec:
+  routes:
+  - name: GET /books
+    condition:
+      pathRegex: /books
+      method: GET
+    isRetryable: true
+    timeout: 300ms
+  - name: POST /books
+    condition:
+      pathRegex: /books
+      method: POST`
+
+func TestProfileWatcher(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(booksProfile)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	watcher := newProfileWatcher(k8sAPI)
+
+	k8sAPI.Sync(nil)
+
+	t.Run("Sends the profile to new listeners", func(t *testing.T) {
+		listener := &collectProfileListener{context: context.Background(), stopCh: make(chan struct{})}
+		id := profileId{namespace: "booksapp", name: "books.booksapp.svc.cluster.local"}
+
+		watcher.subscribe(id, listener)
+		defer watcher.unsubscribe(id, listener)
+
+		if len(listener.profiles) != 1 || listener.profiles[0] == nil {
+			t.Fatalf("Expected the profile to be sent, got %v", listener.profiles)
+		}
+		if routes := listener.profiles[0].Spec.Routes; len(routes) != 2 || routes[0].Name != "GET /books" {
+			t.Fatalf("Unexpected routes: %v", routes)
+		}
+	})
+
+	t.Run("Sends nil for profiles that don't exist", func(t *testing.T) {
+		listener := &collectProfileListener{context: context.Background(), stopCh: make(chan struct{})}
+		id := profileId{namespace: "booksapp", name: "authors.booksapp.svc.cluster.local"}
+
+		watcher.subscribe(id, listener)
+		defer watcher.unsubscribe(id, listener)
+
+		if len(listener.profiles) != 1 || listener.profiles[0] != nil {
+			t.Fatalf("Expected nil to be sent, got %v", listener.profiles)
+		}
+	})
+
+	t.Run("Publishes the changes of the profile to its listeners", func(t *testing.T) {
+		listener := &collectProfileListener{context: context.Background(), stopCh: make(chan struct{})}
+		id := profileId{namespace: "booksapp", name: "books.booksapp.svc.cluster.local"}
+
+		watcher.subscribe(id, listener)
+		defer watcher.unsubscribe(id, listener)
+
+		profile, err := k8sAPI.GetServiceProfile(id.namespace, id.name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		watcher.deleteProfile(profile)
+
+		if len(listener.profiles) != 2 || listener.profiles[1] != nil {
+			t.Fatalf("Expected the deletion of the profile to be sent, got %v", listener.profiles)
+		}
+	})
+
+	t.Run("Records invalid profiles in warning events", func(t *testing.T) {
+		profile, err := k8sAPI.GetServiceProfile("booksapp", "books.booksapp.svc.cluster.local")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		invalid := profile.DeepCopy()
+		invalid.Spec.Routes[0].Timeout = "soon"
+		watcher.addProfile(invalid)
+
+		events, err := k8sAPI.Client.CoreV1().Events("booksapp").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(events.Items) != 1 {
+			t.Fatalf("Expected a single event for the invalid profile, got %+v", events.Items)
+		}
+		event := events.Items[0]
+		if event.Reason != "InvalidServiceProfile" || event.InvolvedObject.Kind != "ServiceProfile" || event.InvolvedObject.Name != invalid.Name {
+			t.Fatalf("Expected an InvalidServiceProfile event on the profile, got %s on %+v", event.Reason, event.InvolvedObject)
+		}
+	})
+}
+
+func TestGetProfile(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(booksProfile)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	resolvers, err := buildResolversList("", k8sAPI)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	s := server{k8sAPI: k8sAPI, resolvers: resolvers}
+
+	k8sAPI.Sync(nil)
+
+	// the stream is closed beforehand, so that GetProfile returns after
+	// sending the profile
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+	stream := &mockDestination_GetProfileServer{contextToReturn: ctx}
+
+	err = s.GetProfile(&pb.GetDestination{Scheme: "k8s", Path: "books.booksapp.svc.cluster.local:7000"}, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(stream.profilesReceived) != 1 {
+		t.Fatalf("Expected 1 profile, got %d", len(stream.profilesReceived))
+	}
+	routes := stream.profilesReceived[0].GetRoutes()
+	if len(routes) != 2 || routes[1].GetMetricsLabels()["route"] != "POST /books" {
+		t.Fatalf("Unexpected routes: %v", routes)
+	}
+	if !routes[0].GetIsRetryable() || routes[0].GetTimeout().GetNanos() != 300000000 {
+		t.Fatalf("Expected the GET route to be retryable with a timeout, got %v", routes[0])
+	}
+	if routes[1].GetIsRetryable() || routes[1].GetTimeout() != nil {
+		t.Fatalf("Expected the POST route not to be retryable, got %v", routes[1])
+	}
+	if budget := stream.profilesReceived[0].GetRetryBudget(); budget.GetRetryRatio() != 0.2 {
+		t.Fatalf("Expected the default retry budget, got %v", budget)
+	}
+}
+
+func TestProfileWatcherWithoutServiceProfiles(t *testing.T) {
+	k8sAPI := k8s.NewAPI(fake.NewSimpleClientset(), k8s.Svc)
+	watcher := newProfileWatcher(k8sAPI)
+
+	listener := &collectProfileListener{context: context.Background(), stopCh: make(chan struct{})}
+	id := profileId{namespace: "booksapp", name: "books.booksapp.svc.cluster.local"}
+
+	watcher.subscribe(id, listener)
+	defer watcher.unsubscribe(id, listener)
+
+	if len(listener.profiles) != 1 || listener.profiles[0] != nil {
+		t.Fatalf("Expected nil to be sent, got %v", listener.profiles)
+	}
+}
+
+func TestProfileListener(t *testing.T) {
+	t.Run("Sends the latest profile", func(t *testing.T) {
+		ctx, cancelFn := context.WithCancel(context.Background())
+		cancelFn()
+		stream := &mockDestination_GetProfileServer{contextToReturn: ctx}
+		listener := newProfileListener(stream)
+
+		// the updates don't block, even though the profiles aren't sent yet
+		listener.Update(nil)
+		listener.Update(&sp.ServiceProfile{Spec: sp.ServiceProfileSpec{
+			Routes: []*sp.RouteSpec{{Name: "GET /books", Condition: &sp.RequestMatch{Method: "GET"}}},
+		}})
+
+		if err := listener.Run(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(stream.profilesReceived) != 1 || len(stream.profilesReceived[0].GetRoutes()) != 1 {
+			t.Fatalf("Expected the latest profile to be sent, got %v", stream.profilesReceived)
+		}
+	})
+
+	t.Run("Returns the errors of the stream", func(t *testing.T) {
+		stream := &mockDestination_GetProfileServer{
+			contextToReturn: context.Background(),
+			errorToReturn:   errors.New("stream closed"),
+		}
+		listener := newProfileListener(stream)
+		listener.Update(nil)
+
+		if err := listener.Run(); err == nil || err.Error() != "stream closed" {
+			t.Fatalf("Expected error [stream closed], got [%v]", err)
+		}
+	})
+}
+
+func TestGetProfileSendFailure(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(booksProfile)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	resolver := newK8sResolver([]string{}, k8sAPI)
+	s := server{k8sAPI: k8sAPI, resolvers: []streamingDestinationResolver{resolver}}
+
+	k8sAPI.Sync(nil)
+
+	stream := &mockDestination_GetProfileServer{
+		contextToReturn: context.Background(),
+		errorToReturn:   errors.New("stream closed"),
+	}
+
+	err = s.GetProfile(&pb.GetDestination{Scheme: "k8s", Path: "books.booksapp.svc.cluster.local:7000"}, stream)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	if listeners := resolver.profileWatcher.listeners; len(listeners) != 0 {
+		t.Fatalf("Expected the listener to be unsubscribed, got %v", listeners)
+	}
+}
@@ -0,0 +1,291 @@
+package destination
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// ReadinessController sets the linkerd.io/proxy-ready condition on pods that
+// were injected with the matching readiness gate, which keeps them out of the
+// ready addresses of their services until the condition is true. The
+// condition is set once the pod's proxy container is ready, the proxy's TLS
+// identity has been issued (if TLS is enabled), and every service selecting
+// the pod lists it in its endpoints, so that the destination service is able
+// to resolve it.
+type ReadinessController struct {
+	k8sAPI      *k8s.API
+	enableTLS   bool
+	syncHandler func(key string) error
+
+	// The queue is keyed on "$podNamespace/$podName".
+	queue workqueue.RateLimitingInterface
+}
+
+func NewReadinessController(enableTLS bool, k8sAPI *k8s.API) *ReadinessController {
+	c := &ReadinessController{
+		k8sAPI:    k8sAPI,
+		enableTLS: enableTLS,
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "readiness"),
+	}
+
+	k8sAPI.Pod().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.handlePodAdd,
+			UpdateFunc: c.handlePodUpdate,
+		},
+	)
+
+	k8sAPI.Endpoint().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.handleEndpointsAdd,
+			UpdateFunc: c.handleEndpointsUpdate,
+		},
+	)
+
+	c.syncHandler = c.syncPod
+
+	return c
+}
+
+func (c *ReadinessController) Run(readyCh <-chan struct{}, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	<-readyCh
+
+	log.Info("starting readiness controller")
+	defer log.Info("shutting down readiness controller")
+
+	go wait.Until(c.worker, time.Second, stopCh)
+
+	<-stopCh
+}
+
+func (c *ReadinessController) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *ReadinessController) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(key.(string))
+	if err != nil {
+		log.Debugf("error syncing pod readiness: %s", err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *ReadinessController) syncPod(key string) error {
+	log.Debugf("syncPod(%s)", key)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		log.Errorf("Failed to parse pod readiness sync request %s", key)
+		return nil
+	}
+
+	pod, err := c.k8sAPI.Pod().Lister().Pods(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !needsProxyReadyCondition(pod) {
+		return nil
+	}
+
+	if !proxyContainerReady(pod) {
+		// the pod will be updated, and resynced, once the proxy becomes ready
+		log.Debugf("proxy in pod %s is not ready", key)
+		return nil
+	}
+
+	if c.enableTLS {
+		if secretName := tlsSecretName(pod); secretName != "" {
+			_, err := c.k8sAPI.Client.CoreV1().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+			if err != nil {
+				// identity is issued asynchronously, so retry until it exists
+				return fmt.Errorf("identity for pod %s is not available: %s", key, err)
+			}
+		}
+	}
+
+	acknowledged, err := c.endpointsAcknowledged(pod)
+	if err != nil {
+		return err
+	}
+	if !acknowledged {
+		// the endpoints will be updated, and the pod resynced, once they are
+		log.Debugf("pod %s is not yet in the endpoints of its services", key)
+		return nil
+	}
+
+	log.Debugf("setting %s condition on pod %s", pkgK8s.ProxyReadyConditionType, key)
+	pod = pod.DeepCopy()
+	setProxyReadyCondition(pod)
+	_, err = c.k8sAPI.Client.CoreV1().Pods(namespace).UpdateStatus(pod)
+	return err
+}
+
+// endpointsAcknowledged returns true if every service that selects the pod
+// lists it in its endpoints, either as a ready or a not ready address.
+func (c *ReadinessController) endpointsAcknowledged(pod *v1.Pod) (bool, error) {
+	services, err := c.k8sAPI.Svc().Lister().Services(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+
+	for _, svc := range services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		endpoints, err := c.k8sAPI.Endpoint().Lister().Endpoints(pod.Namespace).Get(svc.Name)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if !endpointsContainPod(endpoints, pod) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (c *ReadinessController) handlePodAdd(obj interface{}) {
+	pod := obj.(*v1.Pod)
+	if needsProxyReadyCondition(pod) {
+		c.enqueuePod(pod.Namespace, pod.Name)
+	}
+}
+
+func (c *ReadinessController) handlePodUpdate(oldObj, newObj interface{}) {
+	c.handlePodAdd(newObj)
+}
+
+func (c *ReadinessController) handleEndpointsAdd(obj interface{}) {
+	endpoints := obj.(*v1.Endpoints)
+	for _, subset := range endpoints.Subsets {
+		for _, addresses := range [][]v1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+					c.enqueuePod(address.TargetRef.Namespace, address.TargetRef.Name)
+				}
+			}
+		}
+	}
+}
+
+func (c *ReadinessController) handleEndpointsUpdate(oldObj, newObj interface{}) {
+	c.handleEndpointsAdd(newObj)
+}
+
+func (c *ReadinessController) enqueuePod(namespace, name string) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	log.Debugf("enqueuing readiness sync for %s", key)
+	c.queue.Add(key)
+}
+
+// needsProxyReadyCondition returns true if the pod has the proxy readiness
+// gate, and the corresponding condition has not been set yet.
+func needsProxyReadyCondition(pod *v1.Pod) bool {
+	hasGate := false
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == pkgK8s.ProxyReadyConditionType {
+			hasGate = true
+			break
+		}
+	}
+	if !hasGate {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == pkgK8s.ProxyReadyConditionType {
+			return condition.Status != v1.ConditionTrue
+		}
+	}
+	return true
+}
+
+func proxyContainerReady(pod *v1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == pkgK8s.ProxyContainerName {
+			return status.Ready
+		}
+	}
+	return false
+}
+
+// tlsSecretName returns the name of the secret holding the proxy's TLS
+// identity, or an empty string if the proxy was injected without TLS.
+func tlsSecretName(pod *v1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == pkgK8s.TLSSecretsVolumeName && volume.Secret != nil {
+			return volume.Secret.SecretName
+		}
+	}
+	return ""
+}
+
+func endpointsContainPod(endpoints *v1.Endpoints, pod *v1.Pod) bool {
+	for _, subset := range endpoints.Subsets {
+		for _, addresses := range [][]v1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" && address.TargetRef.Name == pod.Name {
+					return true
+				}
+				if pod.Status.PodIP != "" && address.IP == pod.Status.PodIP {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func setProxyReadyCondition(pod *v1.Pod) {
+	condition := v1.PodCondition{
+		Type:               pkgK8s.ProxyReadyConditionType,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == pkgK8s.ProxyReadyConditionType {
+			pod.Status.Conditions[i] = condition
+			return
+		}
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+}
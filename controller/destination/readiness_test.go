@@ -0,0 +1,135 @@
+package destination
+
+import (
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const gatedPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: ns
+  labels:
+    app: web
+spec:
+  readinessGates:
+  - conditionType: linkerd.io/proxy-ready
+status:
+  podIP: 172.17.0.12
+  containerStatuses:
+  - name: linkerd-proxy
+    ready: true`
+
+const webService = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: ns
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 8080`
+
+func TestReadinessController(t *testing.T) {
+	for _, tt := range []struct {
+		description     string
+		k8sConfigs      []string
+		expectCondition bool
+	}{
+		{
+			description: "sets the condition once the pod is in its service's endpoints",
+			k8sConfigs: []string{gatedPod, webService, `
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: web
+  namespace: ns
+subsets:
+- notReadyAddresses:
+  - ip: 172.17.0.12
+    targetRef:
+      kind: Pod
+      name: web-1
+      namespace: ns
+  ports:
+  - port: 8080`,
+			},
+			expectCondition: true,
+		},
+		{
+			description:     "waits for the pod to be in its service's endpoints",
+			k8sConfigs:      []string{gatedPod, webService},
+			expectCondition: false,
+		},
+		{
+			description:     "sets the condition on pods that aren't selected by any service",
+			k8sConfigs:      []string{gatedPod},
+			expectCondition: true,
+		},
+		{
+			description: "waits for the proxy to be ready",
+			k8sConfigs: []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: ns
+spec:
+  readinessGates:
+  - conditionType: linkerd.io/proxy-ready
+status:
+  containerStatuses:
+  - name: linkerd-proxy
+    ready: false`,
+			},
+			expectCondition: false,
+		},
+	} {
+		tt := tt // pin
+		t.Run(tt.description, func(t *testing.T) {
+			k8sAPI, err := k8s.NewFakeAPI(tt.k8sConfigs...)
+			if err != nil {
+				t.Fatalf("NewFakeAPI returned an error: %s", err)
+			}
+
+			controller := NewReadinessController(false, k8sAPI)
+
+			k8sAPI.Sync(nil)
+
+			err = controller.syncPod("ns/web-1")
+			if err != nil {
+				t.Fatalf("syncPod returned an error: %s", err)
+			}
+
+			var updated *v1.Pod
+			for _, action := range k8sAPI.Client.(*fake.Clientset).Actions() {
+				if action.Matches("update", "pods") && action.GetSubresource() == "status" {
+					updated = action.(k8stesting.UpdateAction).GetObject().(*v1.Pod)
+				}
+			}
+
+			if !tt.expectCondition {
+				if updated != nil {
+					t.Fatalf("expected pod status to not be updated, got: %+v", updated.Status)
+				}
+				return
+			}
+
+			if updated == nil {
+				t.Fatal("expected pod status to be updated")
+			}
+			if needsProxyReadyCondition(updated) {
+				t.Fatalf("expected %s condition to be true, got: %+v", pkgK8s.ProxyReadyConditionType, updated.Status.Conditions)
+			}
+		})
+	}
+}
@@ -3,5 +3,6 @@ package destination
 type streamingDestinationResolver interface {
 	canResolve(host string, port int) (bool, error)
 	streamResolution(host string, port int, listener updateListener) error
+	streamProfiles(host string, listener profileUpdateListener) error
 	stop()
 }
@@ -14,9 +14,14 @@ import (
 )
 
 type server struct {
-	k8sAPI    *k8s.API
-	resolvers []streamingDestinationResolver
-	enableTLS bool
+	k8sAPI      *k8s.API
+	resolvers   []streamingDestinationResolver
+	enableTLS   bool
+	trustDomain string
+
+	// enableTopologyLabels adds the zone and region of the nodes of the pods
+	// to the labels of their addresses, which the proxies add to their metrics
+	enableTopologyLabels bool
 }
 
 // The Destination service serves service discovery information to the proxy.
@@ -29,16 +34,19 @@ type server struct {
 //
 // Addresses for the given destination are fetched from the Kubernetes Endpoints
 // API.
-func NewServer(addr, k8sDNSZone string, enableTLS bool, k8sAPI *k8s.API, done chan struct{}) (*grpc.Server, net.Listener, error) {
+func NewServer(addr, k8sDNSZone, trustDomain string, enableTLS, enableTopologyLabels bool, k8sAPI *k8s.API, done chan struct{}) (*grpc.Server, net.Listener, error) {
 	resolvers, err := buildResolversList(k8sDNSZone, k8sAPI)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	srv := server{
-		k8sAPI:    k8sAPI,
-		resolvers: resolvers,
-		enableTLS: enableTLS,
+		k8sAPI:      k8sAPI,
+		resolvers:   resolvers,
+		enableTLS:   enableTLS,
+		trustDomain: trustDomain,
+
+		enableTopologyLabels: enableTopologyLabels,
 	}
 
 	lis, err := net.Listen("tcp", addr)
@@ -61,39 +69,41 @@ func NewServer(addr, k8sDNSZone string, enableTLS bool, k8sAPI *k8s.API, done ch
 
 func (s *server) Get(dest *pb.GetDestination, stream pb.Destination_GetServer) error {
 	log.Debugf("Get %v", dest)
-	if dest.Scheme != "k8s" {
-		err := fmt.Errorf("Unsupported scheme %v", dest.Scheme)
-		log.Error(err)
-		return err
-	}
-	hostPort := strings.Split(dest.Path, ":")
-	if len(hostPort) > 2 {
-		err := fmt.Errorf("Invalid destination %s", dest.Path)
-		log.Error(err)
+	host, port, err := getHostAndPort(dest)
+	if err != nil {
 		return err
 	}
-	host := hostPort[0]
-	port := 80
-	if len(hostPort) == 2 {
-		var err error
-		port, err = strconv.Atoi(hostPort[1])
-		if err != nil {
-			err = fmt.Errorf("Invalid port %s", hostPort[1])
-			log.Error(err)
-			return err
-		}
-	}
 
 	return s.streamResolutionUsingCorrectResolverFor(host, port, stream)
 }
 
-// TODO: unimplemented
 func (s *server) GetProfile(dest *pb.GetDestination, stream pb.Destination_GetProfileServer) error {
-	return nil
+	log.Debugf("GetProfile %v", dest)
+	host, port, err := getHostAndPort(dest)
+	if err != nil {
+		return err
+	}
+
+	listener := newProfileListener(stream)
+
+	for _, resolver := range s.resolvers {
+		resolverCanResolve, err := resolver.canResolve(host, port)
+		if err != nil {
+			return fmt.Errorf("resolver [%+v] found error resolving host [%s] port[%d]: %v", resolver, host, port, err)
+		}
+		if resolverCanResolve {
+			return resolver.streamProfiles(host, listener)
+		}
+	}
+	return fmt.Errorf("cannot find resolver for host [%s] port [%d]", host, port)
 }
 
 func (s *server) streamResolutionUsingCorrectResolverFor(host string, port int, stream pb.Destination_GetServer) error {
-	listener := newEndpointListener(stream, s.k8sAPI.GetOwnerKindAndName, s.enableTLS)
+	var nodeTopology nodeTopologyFn
+	if s.enableTopologyLabels {
+		nodeTopology = s.k8sAPI.GetNodeTopology
+	}
+	listener := newEndpointListener(stream, s.k8sAPI.GetOwnerKindAndName, nodeTopology, s.enableTLS, s.trustDomain)
 
 	for _, resolver := range s.resolvers {
 		resolverCanResolve, err := resolver.canResolve(host, port)
@@ -107,6 +117,32 @@ func (s *server) streamResolutionUsingCorrectResolverFor(host string, port int,
 	return fmt.Errorf("cannot find resolver for host [%s] port [%d]", host, port)
 }
 
+func getHostAndPort(dest *pb.GetDestination) (string, int, error) {
+	if dest.Scheme != "k8s" {
+		err := fmt.Errorf("Unsupported scheme %v", dest.Scheme)
+		log.Error(err)
+		return "", 0, err
+	}
+	hostPort := strings.Split(dest.Path, ":")
+	if len(hostPort) > 2 {
+		err := fmt.Errorf("Invalid destination %s", dest.Path)
+		log.Error(err)
+		return "", 0, err
+	}
+	host := hostPort[0]
+	port := 80
+	if len(hostPort) == 2 {
+		var err error
+		port, err = strconv.Atoi(hostPort[1])
+		if err != nil {
+			err = fmt.Errorf("Invalid port %s", hostPort[1])
+			log.Error(err)
+			return "", 0, err
+		}
+	}
+	return host, port, nil
+}
+
 func buildResolversList(k8sDNSZone string, k8sAPI *k8s.API) ([]streamingDestinationResolver, error) {
 	var k8sDNSZoneLabels []string
 	if k8sDNSZone == "" {
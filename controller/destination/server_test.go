@@ -83,6 +83,10 @@ func (m *mockStreamingDestinationResolver) streamResolution(host string, port in
 	return m.errToReturnForResolution
 }
 
+func (m *mockStreamingDestinationResolver) streamProfiles(host string, listener profileUpdateListener) error {
+	return nil
+}
+
 func (m *mockStreamingDestinationResolver) stop() {}
 
 func TestStreamResolutionUsingCorrectResolverFor(t *testing.T) {
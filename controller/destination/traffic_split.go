@@ -0,0 +1,415 @@
+package destination
+
+import (
+	"sync"
+
+	splitv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/trafficsplit/v1alpha1"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/cache"
+)
+
+// splitWeightScale is the sum of the weights of the addresses of a split
+// service. The share of each backend is divided among its addresses, so the
+// scale keeps the weights of the addresses of large backends above 1.
+const splitWeightScale = 10000
+
+// trafficSplitWatcher watches the TrafficSplits of the services being
+// resolved, and updates the backends of their resolutions when the
+// TrafficSplits change.
+type trafficSplitWatcher struct {
+	k8sAPI           *k8s.API
+	endpointsWatcher *endpointsWatcher
+	// a map of service -> resolutions of the service
+	resolutions map[serviceId]map[*splitResolution]struct{}
+	// This mutex protects the resolutions map, and serializes the updates of
+	// the backends of the resolutions.
+	mutex sync.Mutex
+}
+
+func newTrafficSplitWatcher(k8sAPI *k8s.API, endpointsWatcher *endpointsWatcher) *trafficSplitWatcher {
+	watcher := &trafficSplitWatcher{
+		k8sAPI:           k8sAPI,
+		endpointsWatcher: endpointsWatcher,
+		resolutions:      make(map[serviceId]map[*splitResolution]struct{}),
+	}
+
+	k8sAPI.TS().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    watcher.addSplit,
+			UpdateFunc: watcher.updateSplit,
+			DeleteFunc: watcher.deleteSplit,
+		},
+	)
+
+	return watcher
+}
+
+// subscribe streams the addresses of the backends of the service to the
+// listener, weighted by the service's TrafficSplit, until unsubscribed.
+func (w *trafficSplitWatcher) subscribe(service *serviceId, port uint32, listener updateListener) *splitResolution {
+	resolution := newSplitResolution(*service, port, listener, w.endpointsWatcher)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	resolutions, ok := w.resolutions[*service]
+	if !ok {
+		resolutions = make(map[*splitResolution]struct{})
+		w.resolutions[*service] = resolutions
+	}
+	resolutions[resolution] = struct{}{}
+
+	resolution.setBackends(w.getBackends(*service))
+	return resolution
+}
+
+func (w *trafficSplitWatcher) unsubscribe(resolution *splitResolution) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	resolutions := w.resolutions[resolution.service]
+	delete(resolutions, resolution)
+	if len(resolutions) == 0 {
+		delete(w.resolutions, resolution.service)
+	}
+
+	resolution.stop()
+}
+
+// getBackends returns the weights of the backends of the service, in
+// thousandths, and whether they come from a TrafficSplit. Services without a
+// TrafficSplit are their own backend. If a service has several TrafficSplits,
+// the first one by name is used.
+func (w *trafficSplitWatcher) getBackends(service serviceId) (map[serviceId]int64, bool) {
+	unsplit := map[serviceId]int64{service: 1}
+
+	splits, err := w.k8sAPI.GetTrafficSplits(service.namespace, service.name)
+	if err != nil {
+		log.Errorf("Error getting the TrafficSplits of %s: %s", service, err)
+		return unsplit, false
+	}
+	if len(splits) == 0 {
+		return unsplit, false
+	}
+	if len(splits) > 1 {
+		log.Warnf("Service %s has %d TrafficSplits, using %s", service, len(splits), splits[0].Name)
+	}
+
+	backends := make(map[serviceId]int64)
+	for _, backend := range splits[0].Spec.Backends {
+		weight := backend.Weight.MilliValue()
+		if weight < 0 {
+			log.Errorf("Ignoring backend %s of TrafficSplit %s with negative weight", backend.Service, splits[0].Name)
+			continue
+		}
+		id := serviceId{namespace: service.namespace, name: backend.Service}
+		backends[id] += weight
+	}
+	if len(backends) == 0 {
+		log.Errorf("TrafficSplit %s has no backends, not splitting %s", splits[0].Name, service)
+		return unsplit, false
+	}
+	return backends, true
+}
+
+func (w *trafficSplitWatcher) addSplit(obj interface{}) {
+	w.refresh(obj.(*splitv1alpha1.TrafficSplit))
+}
+
+func (w *trafficSplitWatcher) updateSplit(oldObj, newObj interface{}) {
+	oldSplit := oldObj.(*splitv1alpha1.TrafficSplit)
+	newSplit := newObj.(*splitv1alpha1.TrafficSplit)
+	if oldSplit.Spec.Service != newSplit.Spec.Service {
+		w.refresh(oldSplit)
+	}
+	w.refresh(newSplit)
+}
+
+func (w *trafficSplitWatcher) deleteSplit(obj interface{}) {
+	split, ok := obj.(*splitv1alpha1.TrafficSplit)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorf("Couldn't get object from tombstone %+v", obj)
+			return
+		}
+		split, ok = tombstone.Obj.(*splitv1alpha1.TrafficSplit)
+		if !ok {
+			log.Errorf("Tombstone contained object that is not a TrafficSplit %+v", obj)
+			return
+		}
+	}
+	w.refresh(split)
+}
+
+// refresh updates the backends of the resolutions of the service split by
+// the TrafficSplit.
+func (w *trafficSplitWatcher) refresh(split *splitv1alpha1.TrafficSplit) {
+	id := serviceId{namespace: split.Namespace, name: split.Spec.Service}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	resolutions, ok := w.resolutions[id]
+	if !ok {
+		return
+	}
+
+	backends, isSplit := w.getBackends(id)
+	for resolution := range resolutions {
+		resolution.setBackends(backends, isSplit)
+	}
+}
+
+/// splitResolution ///
+
+// splitResolution merges the addresses of the backends of a service into the
+// updates of its listener, weighting the addresses of each backend by its
+// share of the traffic. The addresses of unsplit services keep the default
+// weight.
+type splitResolution struct {
+	// these values are immutable properties of the splitResolution
+	service          serviceId
+	port             uint32
+	listener         updateListener
+	endpointsWatcher *endpointsWatcher
+	stopOnce         sync.Once
+
+	// these values hold the current state of the splitResolution and are
+	// mutable
+	split    bool
+	backends map[serviceId]*splitBackend
+	// the addresses last sent to the listener, by address
+	sent map[string]*updateAddress
+	// whether the listener was last told that there are no endpoints, and
+	// whether the service exists
+	noEndpoints bool
+	exists      bool
+	// This mutex protects the mutable state. It's never held while calling
+	// the endpointsWatcher, which calls the backends with their servicePort
+	// locked.
+	mutex sync.Mutex
+}
+
+func newSplitResolution(service serviceId, port uint32, listener updateListener, endpointsWatcher *endpointsWatcher) *splitResolution {
+	return &splitResolution{
+		service:          service,
+		port:             port,
+		listener:         listener,
+		endpointsWatcher: endpointsWatcher,
+		backends:         make(map[serviceId]*splitBackend),
+		sent:             make(map[string]*updateAddress),
+	}
+}
+
+// setBackends subscribes to the backends that are new, unsubscribes from the
+// backends that were removed, and sends the changes of the weights to the
+// listener.
+func (r *splitResolution) setBackends(weights map[serviceId]int64, split bool) {
+	added := make([]*splitBackend, 0)
+	removed := make([]*splitBackend, 0)
+
+	r.mutex.Lock()
+	r.split = split
+	for id, weight := range weights {
+		backend, ok := r.backends[id]
+		if !ok {
+			backend = &splitBackend{resolution: r, service: id}
+			r.backends[id] = backend
+			added = append(added, backend)
+		}
+		backend.weight = weight
+	}
+	for id, backend := range r.backends {
+		if _, ok := weights[id]; !ok {
+			delete(r.backends, id)
+			removed = append(removed, backend)
+		}
+	}
+	r.publish()
+	r.mutex.Unlock()
+
+	for _, backend := range removed {
+		if err := r.endpointsWatcher.unsubscribe(&backend.service, r.port, backend); err != nil {
+			log.Error(err)
+		}
+	}
+	for _, backend := range added {
+		if err := r.endpointsWatcher.subscribe(&backend.service, r.port, backend); err != nil {
+			// the backend is left without addresses, so that the others are
+			// still resolved
+			backend.NoEndpoints(false)
+		}
+	}
+}
+
+// stop unsubscribes from all the backends.
+func (r *splitResolution) stop() {
+	r.mutex.Lock()
+	backends := r.backends
+	r.backends = make(map[serviceId]*splitBackend)
+	r.mutex.Unlock()
+
+	for _, backend := range backends {
+		if err := r.endpointsWatcher.unsubscribe(&backend.service, r.port, backend); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// publish sends the changes of the weighted addresses of the backends to the
+// listener. It's a no-op until every backend has sent its addresses, so that
+// a new backend isn't sent the traffic of the others in the meantime.
+func (r *splitResolution) publish() {
+	for _, backend := range r.backends {
+		if !backend.synced {
+			return
+		}
+	}
+
+	addresses := r.weightedAddresses()
+	if len(addresses) == 0 {
+		// services are split across existing backends, and the proxy is told
+		// to resolve the services that don't exist with DNS
+		exists := true
+		if backend, ok := r.backends[r.service]; ok && !r.split {
+			exists = backend.exists
+		}
+		if !r.noEndpoints || r.exists != exists {
+			r.listener.NoEndpoints(exists)
+		}
+		r.noEndpoints = true
+		r.exists = exists
+		r.sent = addresses
+		return
+	}
+
+	add := make([]*updateAddress, 0)
+	remove := make([]*updateAddress, 0)
+	for key, address := range addresses {
+		if sent, ok := r.sent[key]; !ok || sent.weight != address.weight {
+			add = append(add, address)
+		}
+	}
+	for key, address := range r.sent {
+		if _, ok := addresses[key]; !ok {
+			remove = append(remove, address)
+		}
+	}
+
+	if len(add) > 0 || len(remove) > 0 {
+		r.listener.Update(add, remove)
+	}
+	r.noEndpoints = false
+	r.sent = addresses
+}
+
+// weightedAddresses returns the addresses of the backends, by address. The
+// share of the traffic of each backend is its weight relative to the weights
+// of the backends with addresses, and it's divided evenly among its
+// addresses.
+func (r *splitResolution) weightedAddresses() map[string]*updateAddress {
+	addresses := make(map[string]*updateAddress)
+
+	var total int64
+	for _, backend := range r.backends {
+		if len(backend.addresses) > 0 {
+			total += backend.weight
+		}
+	}
+
+	for _, backend := range r.backends {
+		if len(backend.addresses) == 0 || backend.weight == 0 {
+			continue
+		}
+
+		var weight uint32
+		if r.split {
+			weight = uint32(backend.weight * splitWeightScale / (total * int64(len(backend.addresses))))
+			if weight == 0 {
+				weight = 1
+			}
+		}
+
+		for key, address := range backend.addresses {
+			addresses[key] = &updateAddress{
+				address: address.address,
+				pod:     address.pod,
+				gateway: address.gateway,
+				weight:  weight,
+			}
+		}
+	}
+
+	return addresses
+}
+
+// implements the updateListener interface, for the subscription of a
+// splitResolution to one of its backends
+type splitBackend struct {
+	resolution *splitResolution
+	service    serviceId
+
+	// these values are protected by the mutex of the resolution
+	weight    int64
+	synced    bool
+	exists    bool
+	addresses map[string]*updateAddress
+}
+
+func (b *splitBackend) Update(add, remove []*updateAddress) {
+	r := b.resolution
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.backends[b.service] != b {
+		// the backend was removed, and is being unsubscribed
+		return
+	}
+
+	if b.addresses == nil {
+		b.addresses = make(map[string]*updateAddress)
+	}
+	for _, address := range remove {
+		delete(b.addresses, addr.ProxyAddressToString(address.address))
+	}
+	for _, address := range add {
+		b.addresses[addr.ProxyAddressToString(address.address)] = address
+	}
+	b.synced = true
+	b.exists = true
+	r.publish()
+}
+
+func (b *splitBackend) NoEndpoints(exists bool) {
+	r := b.resolution
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.backends[b.service] != b {
+		return
+	}
+
+	b.addresses = nil
+	b.synced = true
+	b.exists = exists
+	r.publish()
+}
+
+func (b *splitBackend) ClientClose() <-chan struct{} {
+	return b.resolution.listener.ClientClose()
+}
+
+func (b *splitBackend) ServerClose() <-chan struct{} {
+	return b.resolution.listener.ServerClose()
+}
+
+func (b *splitBackend) SetServiceId(id *serviceId) {}
+
+// Stop stops the listener of the resolution once, even though each of its
+// backends is stopped when the endpointsWatcher stops.
+func (b *splitBackend) Stop() {
+	b.resolution.stopOnce.Do(b.resolution.listener.Stop)
+}
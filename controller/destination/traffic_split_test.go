@@ -0,0 +1,241 @@
+package destination
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
+)
+
+var trafficSplitConfigs = []string{
+	`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: ns
+spec:
+  type: ClusterIP
+  ports:
+  - port: 8080`,
+	`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web-v1
+  namespace: ns
+spec:
+  type: ClusterIP
+  ports:
+  - port: 8080`,
+	`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: web-v1
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 10.0.0.1
+    targetRef:
+      kind: Pod
+      name: web-v1-0
+      namespace: ns
+  - ip: 10.0.0.2
+    targetRef:
+      kind: Pod
+      name: web-v1-1
+      namespace: ns
+  - ip: 10.0.0.3
+    targetRef:
+      kind: Pod
+      name: web-v1-2
+      namespace: ns
+  ports:
+  - port: 8080`,
+	`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-v1-0
+  namespace: ns
+status:
+  phase: Running
+  podIP: 10.0.0.1`,
+	`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-v1-1
+  namespace: ns
+status:
+  phase: Running
+  podIP: 10.0.0.2`,
+	`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-v1-2
+  namespace: ns
+status:
+  phase: Running
+  podIP: 10.0.0.3`,
+	`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web-v2
+  namespace: ns
+spec:
+  type: ClusterIP
+  ports:
+  - port: 8080`,
+	`
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: web-v2
+  namespace: ns
+subsets:
+- addresses:
+  - ip: 10.0.1.1
+    targetRef:
+      kind: Pod
+      name: web-v2-0
+      namespace: ns
+  ports:
+  - port: 8080`,
+	`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-v2-0
+  namespace: ns
+status:
+  phase: Running
+  podIP: 10.0.1.1`,
+	`
+apiVersion: split.linkerd.io/v1alpha1
+kind: TrafficSplit
+metadata:
+  name: web-rollout
+  namespace: ns
+spec:
+  service: web
+  backends:
+  - service: web-v1
+    weight: 900m
+  - service: web-v2
+    weight: 100m`,
+}
+
+// addressWeights returns the last weight sent for each address added to the
+// listener.
+func addressWeights(addresses []*updateAddress) map[string]uint32 {
+	weights := make(map[string]uint32)
+	for _, a := range addresses {
+		weights[addr.ProxyAddressToString(a.address)] = a.weight
+	}
+	return weights
+}
+
+func TestTrafficSplitWatcher(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(trafficSplitConfigs...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	endpoints := newEndpointsWatcher(k8sAPI)
+	watcher := newTrafficSplitWatcher(k8sAPI, endpoints)
+
+	k8sAPI.Sync(nil)
+
+	t.Run("Weights the addresses of the backends of split services", func(t *testing.T) {
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		resolution := watcher.subscribe(&serviceId{namespace: "ns", name: "web"}, 8080, listener)
+		defer watcher.unsubscribe(resolution)
+
+		expected := map[string]uint32{
+			"10.0.0.1:8080": 3000,
+			"10.0.0.2:8080": 3000,
+			"10.0.0.3:8080": 3000,
+			"10.0.1.1:8080": 1000,
+		}
+		if actual := addressWeights(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected weights %v, got %v", expected, actual)
+		}
+		if listener.noEndpointsCalled {
+			t.Fatal("Expected the split service to have endpoints")
+		}
+	})
+
+	t.Run("Sends the changes of the weights of the backends", func(t *testing.T) {
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		resolution := watcher.subscribe(&serviceId{namespace: "ns", name: "web"}, 8080, listener)
+		defer watcher.unsubscribe(resolution)
+
+		listener.added = nil
+		resolution.setBackends(map[serviceId]int64{
+			{namespace: "ns", name: "web-v1"}: 500,
+			{namespace: "ns", name: "web-v2"}: 500,
+		}, true)
+
+		expected := map[string]uint32{
+			"10.0.0.1:8080": 1666,
+			"10.0.0.2:8080": 1666,
+			"10.0.0.3:8080": 1666,
+			"10.0.1.1:8080": 5000,
+		}
+		if actual := addressWeights(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected weights %v, got %v", expected, actual)
+		}
+
+		listener.added = nil
+		resolution.setBackends(map[serviceId]int64{
+			{namespace: "ns", name: "web-v2"}: 1000,
+		}, true)
+
+		expected = map[string]uint32{"10.0.1.1:8080": 10000}
+		if actual := addressWeights(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected weights %v, got %v", expected, actual)
+		}
+		expectedRemoved := []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080"}
+		if actual := addressStrings(listener.removed); !reflect.DeepEqual(actual, expectedRemoved) {
+			t.Fatalf("Expected removed addresses %v, got %v", expectedRemoved, actual)
+		}
+	})
+
+	t.Run("Doesn't weight the addresses of unsplit services", func(t *testing.T) {
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		resolution := watcher.subscribe(&serviceId{namespace: "ns", name: "web-v1"}, 8080, listener)
+		defer watcher.unsubscribe(resolution)
+
+		expected := map[string]uint32{
+			"10.0.0.1:8080": 0,
+			"10.0.0.2:8080": 0,
+			"10.0.0.3:8080": 0,
+		}
+		if actual := addressWeights(listener.added); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected weights %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("Tells the proxy to resolve unsplit services that don't exist", func(t *testing.T) {
+		listener, cancelFn := newCollectUpdateListener()
+		defer cancelFn()
+
+		resolution := watcher.subscribe(&serviceId{namespace: "ns", name: "missing"}, 8080, listener)
+		defer watcher.unsubscribe(resolution)
+
+		if !listener.noEndpointsCalled || listener.noEndpointsExists {
+			t.Fatal("Expected the service to be reported as not existing")
+		}
+	})
+}
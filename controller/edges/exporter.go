@@ -0,0 +1,242 @@
+package edges
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// edgeQuery sums the outbound responses between every pair of
+	// deployments, as observed by the source proxies.
+	edgeQuery = `sum(rate(response_total{direction="outbound", dst_deployment!=""}[%s])) by (namespace, deployment, dst_namespace, dst_deployment, classification, tls)`
+
+	queryTimeout = 10 * time.Second
+)
+
+var edgeLabels = []string{"src_namespace", "src_workload", "dst_namespace", "dst_workload"}
+
+var (
+	requestRateDesc = prometheus.NewDesc(
+		"linkerd_edge_request_rate",
+		"Requests per second from the source workload to the destination workload.",
+		edgeLabels, nil,
+	)
+	successRatioDesc = prometheus.NewDesc(
+		"linkerd_edge_success_ratio",
+		"Ratio of successful requests from the source workload to the destination workload.",
+		edgeLabels, nil,
+	)
+	mtlsRatioDesc = prometheus.NewDesc(
+		"linkerd_edge_mtls_ratio",
+		"Ratio of requests from the source workload to the destination workload that were sent over mTLS.",
+		edgeLabels, nil,
+	)
+	noIdentityRatioDesc = prometheus.NewDesc(
+		"linkerd_edge_no_identity_ratio",
+		"Ratio of requests from the source workload to the destination workload that weren't sent over mTLS because a peer had no identity.",
+		edgeLabels, nil,
+	)
+	tlsDisabledRatioDesc = prometheus.NewDesc(
+		"linkerd_edge_tls_disabled_ratio",
+		"Ratio of requests from the source workload to the destination workload that weren't sent over mTLS because TLS was disabled.",
+		edgeLabels, nil,
+	)
+)
+
+// Edge holds the traffic between two workloads. The traffic is broken down by
+// TLS status: the requests that weren't sent over mTLS are the ones whose
+// peer had no identity, e.g. because it isn't meshed, and the ones for which
+// TLS was disabled.
+type Edge struct {
+	SrcNamespace     string  `json:"srcNamespace"`
+	SrcWorkload      string  `json:"srcWorkload"`
+	DstNamespace     string  `json:"dstNamespace"`
+	DstWorkload      string  `json:"dstWorkload"`
+	RequestRate      float64 `json:"requestRate"`
+	SuccessRatio     float64 `json:"successRatio"`
+	MTLSRatio        float64 `json:"mtlsRatio"`
+	NoIdentityRatio  float64 `json:"noIdentityRatio"`
+	TLSDisabledRatio float64 `json:"tlsDisabledRatio"`
+}
+
+type edgeKey struct {
+	srcNamespace, srcWorkload, dstNamespace, dstWorkload string
+}
+
+// Exporter periodically aggregates the proxy metrics stored in Prometheus
+// into edges between workloads, and exports them as Prometheus metrics. It
+// implements prometheus.Collector, so that the edges are served alongside the
+// process' own metrics.
+type Exporter struct {
+	prometheusAPI v1.API
+	timeWindow    string
+
+	sync.RWMutex
+	edges     []Edge
+	updatedAt time.Time
+}
+
+func NewExporter(prometheusAPI v1.API, timeWindow string) *Exporter {
+	return &Exporter{
+		prometheusAPI: prometheusAPI,
+		timeWindow:    timeWindow,
+	}
+}
+
+// Run refreshes the edges every interval, until stopCh is closed.
+func (e *Exporter) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.refresh(); err != nil {
+			log.Errorf("failed to refresh edge metrics: %s", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Edges returns the edges as of the last refresh, along with the time of
+// that refresh.
+func (e *Exporter) Edges() ([]Edge, time.Time) {
+	e.RLock()
+	defer e.RUnlock()
+	return e.edges, e.updatedAt
+}
+
+func (e *Exporter) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	res, err := e.prometheusAPI.Query(ctx, fmt.Sprintf(edgeQuery, e.timeWindow), time.Time{})
+	if err != nil {
+		return err
+	}
+	if res.Type() != model.ValVector {
+		return fmt.Errorf("unexpected query result type (expected Vector): %s", res.Type())
+	}
+
+	edges := aggregateEdges(res.(model.Vector))
+
+	e.Lock()
+	defer e.Unlock()
+	e.edges = edges
+	e.updatedAt = time.Now()
+	return nil
+}
+
+// aggregateEdges combines the per classification and TLS status samples of
+// the edge query into one Edge per pair of workloads, sorted by source and
+// destination.
+func aggregateEdges(samples model.Vector) []Edge {
+	type counts struct {
+		total, success, tls, noIdentity, tlsDisabled float64
+	}
+	byKey := make(map[edgeKey]*counts)
+
+	for _, sample := range samples {
+		value := float64(sample.Value)
+		if math.IsNaN(value) {
+			continue
+		}
+
+		key := edgeKey{
+			srcNamespace: string(sample.Metric["namespace"]),
+			srcWorkload:  string(sample.Metric["deployment"]),
+			dstNamespace: string(sample.Metric["dst_namespace"]),
+			dstWorkload:  string(sample.Metric["dst_deployment"]),
+		}
+		if key.srcWorkload == "" || key.dstWorkload == "" {
+			continue
+		}
+
+		c, ok := byKey[key]
+		if !ok {
+			c = &counts{}
+			byKey[key] = c
+		}
+
+		c.total += value
+		if sample.Metric["classification"] == "success" {
+			c.success += value
+		}
+		switch sample.Metric["tls"] {
+		case "true":
+			c.tls += value
+		case "no_identity":
+			c.noIdentity += value
+		case "disabled":
+			c.tlsDisabled += value
+		}
+	}
+
+	edges := make([]Edge, 0, len(byKey))
+	for key, c := range byKey {
+		edge := Edge{
+			SrcNamespace: key.srcNamespace,
+			SrcWorkload:  key.srcWorkload,
+			DstNamespace: key.dstNamespace,
+			DstWorkload:  key.dstWorkload,
+			RequestRate:  c.total,
+		}
+		if c.total > 0 {
+			edge.SuccessRatio = c.success / c.total
+			edge.MTLSRatio = c.tls / c.total
+			edge.NoIdentityRatio = c.noIdentity / c.total
+			edge.TLSDisabledRatio = c.tlsDisabled / c.total
+		}
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.SrcNamespace != b.SrcNamespace {
+			return a.SrcNamespace < b.SrcNamespace
+		}
+		if a.SrcWorkload != b.SrcWorkload {
+			return a.SrcWorkload < b.SrcWorkload
+		}
+		if a.DstNamespace != b.DstNamespace {
+			return a.DstNamespace < b.DstNamespace
+		}
+		return a.DstWorkload < b.DstWorkload
+	})
+
+	return edges
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- requestRateDesc
+	ch <- successRatioDesc
+	ch <- mtlsRatioDesc
+	ch <- noIdentityRatioDesc
+	ch <- tlsDisabledRatioDesc
+}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	edges, _ := e.Edges()
+	for _, edge := range edges {
+		labels := []string{edge.SrcNamespace, edge.SrcWorkload, edge.DstNamespace, edge.DstWorkload}
+		ch <- prometheus.MustNewConstMetric(requestRateDesc, prometheus.GaugeValue, edge.RequestRate, labels...)
+		ch <- prometheus.MustNewConstMetric(successRatioDesc, prometheus.GaugeValue, edge.SuccessRatio, labels...)
+		ch <- prometheus.MustNewConstMetric(mtlsRatioDesc, prometheus.GaugeValue, edge.MTLSRatio, labels...)
+		ch <- prometheus.MustNewConstMetric(noIdentityRatioDesc, prometheus.GaugeValue, edge.NoIdentityRatio, labels...)
+		ch <- prometheus.MustNewConstMetric(tlsDisabledRatioDesc, prometheus.GaugeValue, edge.TLSDisabledRatio, labels...)
+	}
+}
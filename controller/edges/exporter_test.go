@@ -0,0 +1,163 @@
+package edges
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func sample(src, dst, classification, tls string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			"namespace":      "emojivoto",
+			"deployment":     model.LabelValue(src),
+			"dst_namespace":  "emojivoto",
+			"dst_deployment": model.LabelValue(dst),
+			"classification": model.LabelValue(classification),
+			"tls":            model.LabelValue(tls),
+		},
+		Value: model.SampleValue(value),
+	}
+}
+
+func TestExporter(t *testing.T) {
+	mockProm := &public.MockProm{
+		Res: model.Vector{
+			sample("web", "voting", "success", "true", 6),
+			sample("web", "voting", "failure", "true", 1),
+			sample("web", "voting", "success", "no_identity", 1),
+			sample("web", "emoji", "success", "true", 4),
+			sample("vote-bot", "", "success", "", 2),
+		},
+	}
+
+	exporter := NewExporter(mockProm, "1m")
+
+	t.Run("Aggregates samples into edges", func(t *testing.T) {
+		err := exporter.refresh()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expectedQuery := `sum(rate(response_total{direction="outbound", dst_deployment!=""}[1m])) by (namespace, deployment, dst_namespace, dst_deployment, classification, tls)`
+		if len(mockProm.QueriesExecuted) != 1 || mockProm.QueriesExecuted[0] != expectedQuery {
+			t.Fatalf("Expected query %s, got %v", expectedQuery, mockProm.QueriesExecuted)
+		}
+
+		expected := []Edge{
+			{SrcNamespace: "emojivoto", SrcWorkload: "web", DstNamespace: "emojivoto", DstWorkload: "emoji", RequestRate: 4, SuccessRatio: 1, MTLSRatio: 1},
+			{SrcNamespace: "emojivoto", SrcWorkload: "web", DstNamespace: "emojivoto", DstWorkload: "voting", RequestRate: 8, SuccessRatio: 0.875, MTLSRatio: 0.875, NoIdentityRatio: 0.125},
+		}
+
+		edges, updatedAt := exporter.Edges()
+		if updatedAt.IsZero() {
+			t.Fatal("Expected the refresh time to be set")
+		}
+		if !reflect.DeepEqual(edges, expected) {
+			t.Fatalf("Expected edges %+v, got %+v", expected, edges)
+		}
+	})
+
+	t.Run("Exports the edges as Prometheus metrics", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(exporter)
+
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		names := []string{}
+		for _, family := range families {
+			names = append(names, family.GetName())
+			if len(family.GetMetric()) != 2 {
+				t.Fatalf("Expected 2 %s metrics, got %d", family.GetName(), len(family.GetMetric()))
+			}
+		}
+
+		expected := []string{
+			"linkerd_edge_mtls_ratio",
+			"linkerd_edge_no_identity_ratio",
+			"linkerd_edge_request_rate",
+			"linkerd_edge_success_ratio",
+			"linkerd_edge_tls_disabled_ratio",
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected metrics %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Writes snapshots to files", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "edges")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer os.RemoveAll(dir)
+
+		writer, err := NewSnapshotWriter(exporter, "file://"+dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err = writer.write(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "edges-*.json"))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("Expected one snapshot to be written, got %v (%v)", files, err)
+		}
+
+		content, err := ioutil.ReadFile(files[0])
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		var snapshot Snapshot
+		if err = json.Unmarshal(content, &snapshot); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if snapshot.TimeWindow != "1m" || len(snapshot.Edges) != 2 {
+			t.Fatalf("Unexpected snapshot: %+v", snapshot)
+		}
+	})
+
+	t.Run("Uploads snapshots over HTTP", func(t *testing.T) {
+		var uploadedPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPut {
+				t.Errorf("Expected a PUT request, got %s", req.Method)
+			}
+			uploadedPath = req.URL.Path
+		}))
+		defer server.Close()
+
+		writer, err := NewSnapshotWriter(exporter, server.URL+"/bucket/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err = writer.write(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if !strings.HasPrefix(uploadedPath, "/bucket/edges-") {
+			t.Fatalf("Expected snapshot to be uploaded to the bucket, got %s", uploadedPath)
+		}
+	})
+
+	t.Run("Rejects unsupported destinations", func(t *testing.T) {
+		_, err := NewSnapshotWriter(exporter, "s3://bucket")
+		if err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package edges
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Snapshot is the JSON document periodically written to the snapshot
+// destination.
+type Snapshot struct {
+	Timestamp  time.Time `json:"timestamp"`
+	TimeWindow string    `json:"timeWindow"`
+	Edges      []Edge    `json:"edges"`
+}
+
+// SnapshotWriter writes snapshots of the edges to object storage. The
+// destination is a URL: snapshots are written as files under a "file://"
+// URL, and uploaded with an HTTP PUT request under an "http://" or
+// "https://" URL, which is supported by most object stores. Each snapshot is
+// named after its timestamp.
+type SnapshotWriter struct {
+	exporter    *Exporter
+	destination *url.URL
+	httpClient  *http.Client
+}
+
+func NewSnapshotWriter(exporter *Exporter, destination string) (*SnapshotWriter, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		if err := os.MkdirAll(u.Path, 0755); err != nil {
+			return nil, err
+		}
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported snapshot destination scheme: %s", u.Scheme)
+	}
+
+	return &SnapshotWriter{
+		exporter:    exporter,
+		destination: u,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Run writes a snapshot every interval, until stopCh is closed.
+func (w *SnapshotWriter) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.write(); err != nil {
+				log.Errorf("failed to write edge snapshot: %s", err)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (w *SnapshotWriter) write() error {
+	edges, updatedAt := w.exporter.Edges()
+	if updatedAt.IsZero() {
+		log.Debug("skipping edge snapshot, edges have not been refreshed yet")
+		return nil
+	}
+
+	content, err := json.Marshal(Snapshot{
+		Timestamp:  updatedAt.UTC(),
+		TimeWindow: w.exporter.timeWindow,
+		Edges:      edges,
+	})
+	if err != nil {
+		return err
+	}
+
+	name := snapshotName(updatedAt)
+	if w.destination.Scheme == "file" {
+		return ioutil.WriteFile(filepath.Join(w.destination.Path, name), content, 0644)
+	}
+	return w.upload(name, content)
+}
+
+func (w *SnapshotWriter) upload(name string, content []byte) error {
+	u := *w.destination
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status uploading %s: %s", u.Path, rsp.Status)
+	}
+	return nil
+}
+
+func snapshotName(t time.Time) string {
+	return fmt.Sprintf("edges-%s.json", t.UTC().Format("20060102T150405Z"))
+}
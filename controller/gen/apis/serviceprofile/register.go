@@ -0,0 +1,6 @@
+package serviceprofile
+
+const (
+	// GroupName is the API group of the ServiceProfile custom resource
+	GroupName = "linkerd.io"
+)
@@ -0,0 +1,106 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceProfile describes the routes of a service. It's named after the
+// fully-qualified domain name of the service, such as
+// web.emojivoto.svc.cluster.local, and lives in the namespace of the service.
+type ServiceProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceProfileSpec `json:"spec"`
+}
+
+// ServiceProfileSpec is the specification of a ServiceProfile.
+type ServiceProfileSpec struct {
+	Routes []*RouteSpec `json:"routes"`
+
+	// RetryBudget limits the retries of the retryable routes of the service.
+	// The default budget is used if it's not set.
+	RetryBudget *RetryBudget `json:"retryBudget,omitempty"`
+}
+
+// RouteSpec is a route of a service. Requests are attributed to the first
+// route whose condition they match.
+type RouteSpec struct {
+	// Name labels the metrics of the requests of the route
+	Name      string        `json:"name"`
+	Condition *RequestMatch `json:"condition"`
+
+	// ResponseClasses classify the responses of the route as successes or
+	// failures. The responses that don't match any class are failures if their
+	// status is 5XX.
+	ResponseClasses []*ResponseClass `json:"responseClasses,omitempty"`
+
+	// IsRetryable is true if the failed requests of the route can be retried,
+	// which is only safe for idempotent requests
+	IsRetryable bool `json:"isRetryable,omitempty"`
+
+	// Timeout is the maximum duration of the requests of the route, including
+	// their retries, such as "300ms". The proxies apply their default timeout
+	// if it's not set.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// RetryBudget limits the retries of the requests to a service to a ratio of
+// its original requests, so that retries don't overload a failing service.
+type RetryBudget struct {
+	// RetryRatio is the maximum ratio of retries to original requests, such
+	// as 0.2 for one retry every 5 requests
+	RetryRatio float32 `json:"retryRatio"`
+
+	// MinRetriesPerSecond is the number of retries allowed per second
+	// regardless of the ratio, so that services with little traffic can be
+	// retried
+	MinRetriesPerSecond uint32 `json:"minRetriesPerSecond"`
+
+	// TTL is the window over which the ratio is computed, such as "10s"
+	TTL string `json:"ttl"`
+}
+
+// RequestMatch matches requests. The conditions that are set must all be
+// met.
+type RequestMatch struct {
+	All       []*RequestMatch `json:"all,omitempty"`
+	Not       *RequestMatch   `json:"not,omitempty"`
+	Any       []*RequestMatch `json:"any,omitempty"`
+	PathRegex string          `json:"pathRegex,omitempty"`
+	Method    string          `json:"method,omitempty"`
+}
+
+// ResponseClass classifies the responses matching its condition.
+type ResponseClass struct {
+	Condition *ResponseMatch `json:"condition"`
+	IsFailure bool           `json:"isFailure,omitempty"`
+}
+
+// ResponseMatch matches responses. The conditions that are set must all be
+// met.
+type ResponseMatch struct {
+	All    []*ResponseMatch `json:"all,omitempty"`
+	Not    *ResponseMatch   `json:"not,omitempty"`
+	Any    []*ResponseMatch `json:"any,omitempty"`
+	Status *Range           `json:"status,omitempty"`
+}
+
+// Range is an inclusive range of HTTP statuses.
+type Range struct {
+	Min uint32 `json:"min,omitempty"`
+	Max uint32 `json:"max,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceProfileList is a list of ServiceProfiles.
+type ServiceProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceProfile `json:"items"`
+}
@@ -0,0 +1,6 @@
+package trafficsplit
+
+const (
+	// GroupName is the API group of the TrafficSplit custom resource
+	GroupName = "split.linkerd.io"
+)
@@ -0,0 +1,7 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1alpha1 is the v1alpha1 version of the TrafficSplit custom
+// resource, which splits the traffic of a service across several backend
+// services.
+// +groupName=split.linkerd.io
+package v1alpha1
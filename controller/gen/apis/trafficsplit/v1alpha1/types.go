@@ -0,0 +1,49 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficSplit splits the traffic sent to a service across backend services
+// in its namespace, in proportion to their weights.
+type TrafficSplit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TrafficSplitSpec `json:"spec"`
+}
+
+// TrafficSplitSpec is the specification of a TrafficSplit.
+type TrafficSplitSpec struct {
+	// Service is the name of the service whose traffic is split. Clients
+	// keep addressing this service.
+	Service string `json:"service"`
+
+	// Backends are the services that receive the traffic. The service itself
+	// may be one of them.
+	Backends []TrafficSplitBackend `json:"backends"`
+}
+
+// TrafficSplitBackend is a service receiving a share of the traffic of a
+// TrafficSplit.
+type TrafficSplitBackend struct {
+	Service string `json:"service"`
+
+	// Weight is the weight of the service, relative to the other backends.
+	// Backends with a zero weight don't receive any traffic.
+	Weight resource.Quantity `json:"weight"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TrafficSplitList is a list of TrafficSplits.
+type TrafficSplitList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TrafficSplit `json:"items"`
+}
@@ -118,6 +118,32 @@ func (TapEvent_ProxyDirection) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_public_62da165bc60d64f8, []int{13, 0}
 }
 
+type TapEvent_Http_ResponseEnd_Classification int32
+
+const (
+	TapEvent_Http_ResponseEnd_UNCLASSIFIED TapEvent_Http_ResponseEnd_Classification = 0
+	TapEvent_Http_ResponseEnd_SUCCESS      TapEvent_Http_ResponseEnd_Classification = 1
+	TapEvent_Http_ResponseEnd_FAILURE      TapEvent_Http_ResponseEnd_Classification = 2
+)
+
+var TapEvent_Http_ResponseEnd_Classification_name = map[int32]string{
+	0: "UNCLASSIFIED",
+	1: "SUCCESS",
+	2: "FAILURE",
+}
+var TapEvent_Http_ResponseEnd_Classification_value = map[string]int32{
+	"UNCLASSIFIED": 0,
+	"SUCCESS":      1,
+	"FAILURE":      2,
+}
+
+func (x TapEvent_Http_ResponseEnd_Classification) String() string {
+	return proto.EnumName(TapEvent_Http_ResponseEnd_Classification_name, int32(x))
+}
+func (TapEvent_Http_ResponseEnd_Classification) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{13, 1, 3, 0}
+}
+
 type Empty struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -149,12 +175,14 @@ func (m *Empty) XXX_DiscardUnknown() {
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
 type VersionInfo struct {
-	GoVersion            string   `protobuf:"bytes,1,opt,name=goVersion,proto3" json:"goVersion,omitempty"`
-	BuildDate            string   `protobuf:"bytes,2,opt,name=buildDate,proto3" json:"buildDate,omitempty"`
-	ReleaseVersion       string   `protobuf:"bytes,3,opt,name=releaseVersion,proto3" json:"releaseVersion,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	GoVersion      string `protobuf:"bytes,1,opt,name=goVersion,proto3" json:"goVersion,omitempty"`
+	BuildDate      string `protobuf:"bytes,2,opt,name=buildDate,proto3" json:"buildDate,omitempty"`
+	ReleaseVersion string `protobuf:"bytes,3,opt,name=releaseVersion,proto3" json:"releaseVersion,omitempty"`
+	// The versions of the control plane components observed by the controller.
+	Components           []*ComponentVersion `protobuf:"bytes,4,rep,name=components,proto3" json:"components,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *VersionInfo) Reset()         { *m = VersionInfo{} }
@@ -202,8 +230,26 @@ func (m *VersionInfo) GetReleaseVersion() string {
 	return ""
 }
 
+func (m *VersionInfo) GetComponents() []*ComponentVersion {
+	if m != nil {
+		return m.Components
+	}
+	return nil
+}
+
 type ListPodsRequest struct {
-	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// The maximum number of pods to return, sorted by namespace and name. All
+	// the pods are returned if 0.
+	Limit uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The continue_token of the previous page's response, to return the next
+	// page.
+	ContinueToken string `protobuf:"bytes,3,opt,name=continue_token,json=continueToken,proto3" json:"continue_token,omitempty"`
+	// If set, only returns the pods owned by this resource, e.g. a deployment.
+	Owner *Resource `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	// If set, the pods' status, which requires querying Prometheus, isn't
+	// returned.
+	ExcludeStatus        bool     `protobuf:"varint,5,opt,name=exclude_status,json=excludeStatus,proto3" json:"exclude_status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -240,8 +286,39 @@ func (m *ListPodsRequest) GetNamespace() string {
 	return ""
 }
 
+func (m *ListPodsRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListPodsRequest) GetContinueToken() string {
+	if m != nil {
+		return m.ContinueToken
+	}
+	return ""
+}
+
+func (m *ListPodsRequest) GetOwner() *Resource {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+func (m *ListPodsRequest) GetExcludeStatus() bool {
+	if m != nil {
+		return m.ExcludeStatus
+	}
+	return false
+}
+
 type ListPodsResponse struct {
-	Pods                 []*Pod   `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+	Pods []*Pod `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+	// Set if there are more pods than the request's limit, to request the next
+	// page.
+	ContinueToken        string   `protobuf:"bytes,2,opt,name=continue_token,json=continueToken,proto3" json:"continue_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -278,6 +355,13 @@ func (m *ListPodsResponse) GetPods() []*Pod {
 	return nil
 }
 
+func (m *ListPodsResponse) GetContinueToken() string {
+	if m != nil {
+		return m.ContinueToken
+	}
+	return ""
+}
+
 type Pod struct {
 	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	PodIP string `protobuf:"bytes,2,opt,name=podIP,proto3" json:"podIP,omitempty"`
@@ -813,10 +897,12 @@ type TapByResourceRequest struct {
 	// Selects over events to be reported.
 	Match *TapByResourceRequest_Match `protobuf:"bytes,2,opt,name=match,proto3" json:"match,omitempty"`
 	// Limits the number of events to be inspected.
-	MaxRps               float32  `protobuf:"fixed32,3,opt,name=maxRps,proto3" json:"maxRps,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	MaxRps float32 `protobuf:"fixed32,3,opt,name=maxRps,proto3" json:"maxRps,omitempty"`
+	// Terminates the tap after this duration, if set.
+	Duration             *duration.Duration `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
 func (m *TapByResourceRequest) Reset()         { *m = TapByResourceRequest{} }
@@ -864,6 +950,13 @@ func (m *TapByResourceRequest) GetMaxRps() float32 {
 	return 0
 }
 
+func (m *TapByResourceRequest) GetDuration() *duration.Duration {
+	if m != nil {
+		return m.Duration
+	}
+	return nil
+}
+
 type TapByResourceRequest_Match struct {
 	// Types that are valid to be assigned to Match:
 	//	*TapByResourceRequest_Match_All
@@ -871,6 +964,7 @@ type TapByResourceRequest_Match struct {
 	//	*TapByResourceRequest_Match_Not
 	//	*TapByResourceRequest_Match_Destinations
 	//	*TapByResourceRequest_Match_Http_
+	//	*TapByResourceRequest_Match_Sources
 	Match                isTapByResourceRequest_Match_Match `protobuf_oneof:"match"`
 	XXX_NoUnkeyedLiteral struct{}                           `json:"-"`
 	XXX_unrecognized     []byte                             `json:"-"`
@@ -925,6 +1019,10 @@ type TapByResourceRequest_Match_Http_ struct {
 	Http *TapByResourceRequest_Match_Http `protobuf:"bytes,5,opt,name=http,proto3,oneof"`
 }
 
+type TapByResourceRequest_Match_Sources struct {
+	Sources *ResourceSelection `protobuf:"bytes,6,opt,name=sources,proto3,oneof"`
+}
+
 func (*TapByResourceRequest_Match_All) isTapByResourceRequest_Match_Match() {}
 
 func (*TapByResourceRequest_Match_Any) isTapByResourceRequest_Match_Match() {}
@@ -935,6 +1033,8 @@ func (*TapByResourceRequest_Match_Destinations) isTapByResourceRequest_Match_Mat
 
 func (*TapByResourceRequest_Match_Http_) isTapByResourceRequest_Match_Match() {}
 
+func (*TapByResourceRequest_Match_Sources) isTapByResourceRequest_Match_Match() {}
+
 func (m *TapByResourceRequest_Match) GetMatch() isTapByResourceRequest_Match_Match {
 	if m != nil {
 		return m.Match
@@ -977,6 +1077,13 @@ func (m *TapByResourceRequest_Match) GetHttp() *TapByResourceRequest_Match_Http
 	return nil
 }
 
+func (m *TapByResourceRequest_Match) GetSources() *ResourceSelection {
+	if x, ok := m.GetMatch().(*TapByResourceRequest_Match_Sources); ok {
+		return x.Sources
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*TapByResourceRequest_Match) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _TapByResourceRequest_Match_OneofMarshaler, _TapByResourceRequest_Match_OneofUnmarshaler, _TapByResourceRequest_Match_OneofSizer, []interface{}{
@@ -985,6 +1092,7 @@ func (*TapByResourceRequest_Match) XXX_OneofFuncs() (func(msg proto.Message, b *
 		(*TapByResourceRequest_Match_Not)(nil),
 		(*TapByResourceRequest_Match_Destinations)(nil),
 		(*TapByResourceRequest_Match_Http_)(nil),
+		(*TapByResourceRequest_Match_Sources)(nil),
 	}
 }
 
@@ -1017,6 +1125,11 @@ func _TapByResourceRequest_Match_OneofMarshaler(msg proto.Message, b *proto.Buff
 		if err := b.EncodeMessage(x.Http); err != nil {
 			return err
 		}
+	case *TapByResourceRequest_Match_Sources:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Sources); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("TapByResourceRequest_Match.Match has unexpected type %T", x)
@@ -1067,6 +1180,14 @@ func _TapByResourceRequest_Match_OneofUnmarshaler(msg proto.Message, tag, wire i
 		err := b.DecodeMessage(msg)
 		m.Match = &TapByResourceRequest_Match_Http_{msg}
 		return true, err
+	case 6: // match.sources
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResourceSelection)
+		err := b.DecodeMessage(msg)
+		m.Match = &TapByResourceRequest_Match_Sources{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -1101,6 +1222,11 @@ func _TapByResourceRequest_Match_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *TapByResourceRequest_Match_Sources:
+		s := proto.Size(x.Sources)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -1152,6 +1278,7 @@ type TapByResourceRequest_Match_Http struct {
 	//	*TapByResourceRequest_Match_Http_Method
 	//	*TapByResourceRequest_Match_Http_Authority
 	//	*TapByResourceRequest_Match_Http_Path
+	//	*TapByResourceRequest_Match_Http_Status
 	Match                isTapByResourceRequest_Match_Http_Match `protobuf_oneof:"match"`
 	XXX_NoUnkeyedLiteral struct{}                                `json:"-"`
 	XXX_unrecognized     []byte                                  `json:"-"`
@@ -1202,6 +1329,10 @@ type TapByResourceRequest_Match_Http_Path struct {
 	Path string `protobuf:"bytes,4,opt,name=path,proto3,oneof"`
 }
 
+type TapByResourceRequest_Match_Http_Status struct {
+	Status *TapByResourceRequest_Match_Http_StatusRange `protobuf:"bytes,5,opt,name=status,proto3,oneof"`
+}
+
 func (*TapByResourceRequest_Match_Http_Scheme) isTapByResourceRequest_Match_Http_Match() {}
 
 func (*TapByResourceRequest_Match_Http_Method) isTapByResourceRequest_Match_Http_Match() {}
@@ -1210,6 +1341,8 @@ func (*TapByResourceRequest_Match_Http_Authority) isTapByResourceRequest_Match_H
 
 func (*TapByResourceRequest_Match_Http_Path) isTapByResourceRequest_Match_Http_Match() {}
 
+func (*TapByResourceRequest_Match_Http_Status) isTapByResourceRequest_Match_Http_Match() {}
+
 func (m *TapByResourceRequest_Match_Http) GetMatch() isTapByResourceRequest_Match_Http_Match {
 	if m != nil {
 		return m.Match
@@ -1245,6 +1378,13 @@ func (m *TapByResourceRequest_Match_Http) GetPath() string {
 	return ""
 }
 
+func (m *TapByResourceRequest_Match_Http) GetStatus() *TapByResourceRequest_Match_Http_StatusRange {
+	if x, ok := m.GetMatch().(*TapByResourceRequest_Match_Http_Status); ok {
+		return x.Status
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*TapByResourceRequest_Match_Http) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _TapByResourceRequest_Match_Http_OneofMarshaler, _TapByResourceRequest_Match_Http_OneofUnmarshaler, _TapByResourceRequest_Match_Http_OneofSizer, []interface{}{
@@ -1252,6 +1392,7 @@ func (*TapByResourceRequest_Match_Http) XXX_OneofFuncs() (func(msg proto.Message
 		(*TapByResourceRequest_Match_Http_Method)(nil),
 		(*TapByResourceRequest_Match_Http_Authority)(nil),
 		(*TapByResourceRequest_Match_Http_Path)(nil),
+		(*TapByResourceRequest_Match_Http_Status)(nil),
 	}
 }
 
@@ -1271,6 +1412,11 @@ func _TapByResourceRequest_Match_Http_OneofMarshaler(msg proto.Message, b *proto
 	case *TapByResourceRequest_Match_Http_Path:
 		b.EncodeVarint(4<<3 | proto.WireBytes)
 		b.EncodeStringBytes(x.Path)
+	case *TapByResourceRequest_Match_Http_Status:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Status); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("TapByResourceRequest_Match_Http.Match has unexpected type %T", x)
@@ -1309,6 +1455,14 @@ func _TapByResourceRequest_Match_Http_OneofUnmarshaler(msg proto.Message, tag, w
 		x, err := b.DecodeStringBytes()
 		m.Match = &TapByResourceRequest_Match_Http_Path{x}
 		return true, err
+	case 5: // match.status
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TapByResourceRequest_Match_Http_StatusRange)
+		err := b.DecodeMessage(msg)
+		m.Match = &TapByResourceRequest_Match_Http_Status{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -1334,6 +1488,11 @@ func _TapByResourceRequest_Match_Http_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(len(x.Path)))
 		n += len(x.Path)
+	case *TapByResourceRequest_Match_Http_Status:
+		s := proto.Size(x.Status)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -1341,6 +1500,57 @@ func _TapByResourceRequest_Match_Http_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// An inclusive range of HTTP response status codes.
+type TapByResourceRequest_Match_Http_StatusRange struct {
+	Min                  uint32   `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max                  uint32   `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TapByResourceRequest_Match_Http_StatusRange) Reset() {
+	*m = TapByResourceRequest_Match_Http_StatusRange{}
+}
+func (m *TapByResourceRequest_Match_Http_StatusRange) String() string {
+	return proto.CompactTextString(m)
+}
+func (*TapByResourceRequest_Match_Http_StatusRange) ProtoMessage() {}
+func (*TapByResourceRequest_Match_Http_StatusRange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{6, 0, 1, 0}
+}
+func (m *TapByResourceRequest_Match_Http_StatusRange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange.Unmarshal(m, b)
+}
+func (m *TapByResourceRequest_Match_Http_StatusRange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange.Marshal(b, m, deterministic)
+}
+func (dst *TapByResourceRequest_Match_Http_StatusRange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange.Merge(dst, src)
+}
+func (m *TapByResourceRequest_Match_Http_StatusRange) XXX_Size() int {
+	return xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange.Size(m)
+}
+func (m *TapByResourceRequest_Match_Http_StatusRange) XXX_DiscardUnknown() {
+	xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TapByResourceRequest_Match_Http_StatusRange proto.InternalMessageInfo
+
+func (m *TapByResourceRequest_Match_Http_StatusRange) GetMin() uint32 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *TapByResourceRequest_Match_Http_StatusRange) GetMax() uint32 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
 type HttpMethod struct {
 	// Types that are valid to be assigned to Type:
 	//	*HttpMethod_Registered_
@@ -2521,14 +2731,15 @@ func (m *TapEvent_Http_ResponseInit) GetHttpStatus() uint32 {
 }
 
 type TapEvent_Http_ResponseEnd struct {
-	Id                   *TapEvent_Http_StreamId `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	SinceRequestInit     *duration.Duration      `protobuf:"bytes,2,opt,name=since_request_init,json=sinceRequestInit,proto3" json:"since_request_init,omitempty"`
-	SinceResponseInit    *duration.Duration      `protobuf:"bytes,3,opt,name=since_response_init,json=sinceResponseInit,proto3" json:"since_response_init,omitempty"`
-	ResponseBytes        uint64                  `protobuf:"varint,4,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
-	Eos                  *Eos                    `protobuf:"bytes,5,opt,name=eos,proto3" json:"eos,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
-	XXX_unrecognized     []byte                  `json:"-"`
-	XXX_sizecache        int32                   `json:"-"`
+	Id                   *TapEvent_Http_StreamId                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SinceRequestInit     *duration.Duration                       `protobuf:"bytes,2,opt,name=since_request_init,json=sinceRequestInit,proto3" json:"since_request_init,omitempty"`
+	SinceResponseInit    *duration.Duration                       `protobuf:"bytes,3,opt,name=since_response_init,json=sinceResponseInit,proto3" json:"since_response_init,omitempty"`
+	ResponseBytes        uint64                                   `protobuf:"varint,4,opt,name=response_bytes,json=responseBytes,proto3" json:"response_bytes,omitempty"`
+	Eos                  *Eos                                     `protobuf:"bytes,5,opt,name=eos,proto3" json:"eos,omitempty"`
+	Classification       TapEvent_Http_ResponseEnd_Classification `protobuf:"varint,6,opt,name=classification,proto3,enum=linkerd2.public.TapEvent_Http_ResponseEnd_Classification" json:"classification,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                                 `json:"-"`
+	XXX_unrecognized     []byte                                   `json:"-"`
+	XXX_sizecache        int32                                    `json:"-"`
 }
 
 func (m *TapEvent_Http_ResponseEnd) Reset()         { *m = TapEvent_Http_ResponseEnd{} }
@@ -2590,8 +2801,17 @@ func (m *TapEvent_Http_ResponseEnd) GetEos() *Eos {
 	return nil
 }
 
+func (m *TapEvent_Http_ResponseEnd) GetClassification() TapEvent_Http_ResponseEnd_Classification {
+	if m != nil {
+		return m.Classification
+	}
+	return TapEvent_Http_ResponseEnd_UNCLASSIFIED
+}
+
 type ApiError struct {
-	Error                string   `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	// The gRPC status code of the error.
+	Code                 uint32   `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -2628,6 +2848,13 @@ func (m *ApiError) GetError() string {
 	return ""
 }
 
+func (m *ApiError) GetCode() uint32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
 type PodErrors struct {
 	Errors               []*PodErrors_PodError `protobuf:"bytes,1,rep,name=errors,proto3" json:"errors,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
@@ -3381,15 +3608,17 @@ func (m *StatSummaryResponse_Ok) GetStatTables() []*StatTable {
 }
 
 type BasicStats struct {
-	SuccessCount         uint64   `protobuf:"varint,1,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
-	FailureCount         uint64   `protobuf:"varint,2,opt,name=failure_count,json=failureCount,proto3" json:"failure_count,omitempty"`
-	LatencyMsP50         uint64   `protobuf:"varint,3,opt,name=latency_ms_p50,json=latencyMsP50,proto3" json:"latency_ms_p50,omitempty"`
-	LatencyMsP95         uint64   `protobuf:"varint,4,opt,name=latency_ms_p95,json=latencyMsP95,proto3" json:"latency_ms_p95,omitempty"`
-	LatencyMsP99         uint64   `protobuf:"varint,5,opt,name=latency_ms_p99,json=latencyMsP99,proto3" json:"latency_ms_p99,omitempty"`
-	TlsRequestCount      uint64   `protobuf:"varint,6,opt,name=tls_request_count,json=tlsRequestCount,proto3" json:"tls_request_count,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	SuccessCount            uint64   `protobuf:"varint,1,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`
+	FailureCount            uint64   `protobuf:"varint,2,opt,name=failure_count,json=failureCount,proto3" json:"failure_count,omitempty"`
+	LatencyMsP50            uint64   `protobuf:"varint,3,opt,name=latency_ms_p50,json=latencyMsP50,proto3" json:"latency_ms_p50,omitempty"`
+	LatencyMsP95            uint64   `protobuf:"varint,4,opt,name=latency_ms_p95,json=latencyMsP95,proto3" json:"latency_ms_p95,omitempty"`
+	LatencyMsP99            uint64   `protobuf:"varint,5,opt,name=latency_ms_p99,json=latencyMsP99,proto3" json:"latency_ms_p99,omitempty"`
+	TlsRequestCount         uint64   `protobuf:"varint,6,opt,name=tls_request_count,json=tlsRequestCount,proto3" json:"tls_request_count,omitempty"`
+	NoIdentityRequestCount  uint64   `protobuf:"varint,7,opt,name=no_identity_request_count,json=noIdentityRequestCount,proto3" json:"no_identity_request_count,omitempty"`
+	TlsDisabledRequestCount uint64   `protobuf:"varint,8,opt,name=tls_disabled_request_count,json=tlsDisabledRequestCount,proto3" json:"tls_disabled_request_count,omitempty"`
+	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
+	XXX_unrecognized        []byte   `json:"-"`
+	XXX_sizecache           int32    `json:"-"`
 }
 
 func (m *BasicStats) Reset()         { *m = BasicStats{} }
@@ -3458,6 +3687,20 @@ func (m *BasicStats) GetTlsRequestCount() uint64 {
 	return 0
 }
 
+func (m *BasicStats) GetNoIdentityRequestCount() uint64 {
+	if m != nil {
+		return m.NoIdentityRequestCount
+	}
+	return 0
+}
+
+func (m *BasicStats) GetTlsDisabledRequestCount() uint64 {
+	if m != nil {
+		return m.TlsDisabledRequestCount
+	}
+	return 0
+}
+
 type StatTable struct {
 	// Types that are valid to be assigned to Table:
 	//	*StatTable_PodGroup_
@@ -3698,108 +3941,1212 @@ func (m *StatTable_PodGroup_Row) GetErrorsByPod() map[string]*PodErrors {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Empty)(nil), "linkerd2.public.Empty")
-	proto.RegisterType((*VersionInfo)(nil), "linkerd2.public.VersionInfo")
-	proto.RegisterType((*ListPodsRequest)(nil), "linkerd2.public.ListPodsRequest")
-	proto.RegisterType((*ListPodsResponse)(nil), "linkerd2.public.ListPodsResponse")
-	proto.RegisterType((*Pod)(nil), "linkerd2.public.Pod")
-	proto.RegisterType((*TapRequest)(nil), "linkerd2.public.TapRequest")
-	proto.RegisterType((*TapByResourceRequest)(nil), "linkerd2.public.TapByResourceRequest")
-	proto.RegisterType((*TapByResourceRequest_Match)(nil), "linkerd2.public.TapByResourceRequest.Match")
-	proto.RegisterType((*TapByResourceRequest_Match_Seq)(nil), "linkerd2.public.TapByResourceRequest.Match.Seq")
-	proto.RegisterType((*TapByResourceRequest_Match_Http)(nil), "linkerd2.public.TapByResourceRequest.Match.Http")
-	proto.RegisterType((*HttpMethod)(nil), "linkerd2.public.HttpMethod")
-	proto.RegisterType((*Scheme)(nil), "linkerd2.public.Scheme")
-	proto.RegisterType((*IPAddress)(nil), "linkerd2.public.IPAddress")
-	proto.RegisterType((*IPv6)(nil), "linkerd2.public.IPv6")
-	proto.RegisterType((*TcpAddress)(nil), "linkerd2.public.TcpAddress")
-	proto.RegisterType((*Eos)(nil), "linkerd2.public.Eos")
-	proto.RegisterType((*TapEvent)(nil), "linkerd2.public.TapEvent")
-	proto.RegisterType((*TapEvent_EndpointMeta)(nil), "linkerd2.public.TapEvent.EndpointMeta")
-	proto.RegisterMapType((map[string]string)(nil), "linkerd2.public.TapEvent.EndpointMeta.LabelsEntry")
-	proto.RegisterType((*TapEvent_Http)(nil), "linkerd2.public.TapEvent.Http")
-	proto.RegisterType((*TapEvent_Http_StreamId)(nil), "linkerd2.public.TapEvent.Http.StreamId")
-	proto.RegisterType((*TapEvent_Http_RequestInit)(nil), "linkerd2.public.TapEvent.Http.RequestInit")
-	proto.RegisterType((*TapEvent_Http_ResponseInit)(nil), "linkerd2.public.TapEvent.Http.ResponseInit")
-	proto.RegisterType((*TapEvent_Http_ResponseEnd)(nil), "linkerd2.public.TapEvent.Http.ResponseEnd")
-	proto.RegisterType((*ApiError)(nil), "linkerd2.public.ApiError")
-	proto.RegisterType((*PodErrors)(nil), "linkerd2.public.PodErrors")
-	proto.RegisterType((*PodErrors_PodError)(nil), "linkerd2.public.PodErrors.PodError")
-	proto.RegisterType((*PodErrors_PodError_ContainerError)(nil), "linkerd2.public.PodErrors.PodError.ContainerError")
-	proto.RegisterType((*Resource)(nil), "linkerd2.public.Resource")
-	proto.RegisterType((*ResourceSelection)(nil), "linkerd2.public.ResourceSelection")
-	proto.RegisterType((*ResourceError)(nil), "linkerd2.public.ResourceError")
-	proto.RegisterType((*StatSummaryRequest)(nil), "linkerd2.public.StatSummaryRequest")
-	proto.RegisterType((*StatSummaryResponse)(nil), "linkerd2.public.StatSummaryResponse")
-	proto.RegisterType((*StatSummaryResponse_Ok)(nil), "linkerd2.public.StatSummaryResponse.Ok")
-	proto.RegisterType((*BasicStats)(nil), "linkerd2.public.BasicStats")
-	proto.RegisterType((*StatTable)(nil), "linkerd2.public.StatTable")
-	proto.RegisterType((*StatTable_PodGroup)(nil), "linkerd2.public.StatTable.PodGroup")
-	proto.RegisterType((*StatTable_PodGroup_Row)(nil), "linkerd2.public.StatTable.PodGroup.Row")
-	proto.RegisterMapType((map[string]*PodErrors)(nil), "linkerd2.public.StatTable.PodGroup.Row.ErrorsByPodEntry")
-	proto.RegisterEnum("linkerd2.public.HttpMethod_Registered", HttpMethod_Registered_name, HttpMethod_Registered_value)
-	proto.RegisterEnum("linkerd2.public.Scheme_Registered", Scheme_Registered_name, Scheme_Registered_value)
-	proto.RegisterEnum("linkerd2.public.TapEvent_ProxyDirection", TapEvent_ProxyDirection_name, TapEvent_ProxyDirection_value)
+type ResolveDestinationRequest struct {
+	// The authority to resolve, e.g. "web.default.svc.cluster.local:8080".
+	Authority            string   `protobuf:"bytes,1,opt,name=authority,proto3" json:"authority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *ResolveDestinationRequest) Reset()         { *m = ResolveDestinationRequest{} }
+func (m *ResolveDestinationRequest) String() string { return proto.CompactTextString(m) }
+func (*ResolveDestinationRequest) ProtoMessage()    {}
+func (*ResolveDestinationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{23}
+}
+func (m *ResolveDestinationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResolveDestinationRequest.Unmarshal(m, b)
+}
+func (m *ResolveDestinationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResolveDestinationRequest.Marshal(b, m, deterministic)
+}
+func (dst *ResolveDestinationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResolveDestinationRequest.Merge(dst, src)
+}
+func (m *ResolveDestinationRequest) XXX_Size() int {
+	return xxx_messageInfo_ResolveDestinationRequest.Size(m)
+}
+func (m *ResolveDestinationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResolveDestinationRequest.DiscardUnknown(m)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_ResolveDestinationRequest proto.InternalMessageInfo
 
-// ApiClient is the client API for Api service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type ApiClient interface {
-	StatSummary(ctx context.Context, in *StatSummaryRequest, opts ...grpc.CallOption) (*StatSummaryResponse, error)
-	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error)
-	// Superceded by `TapByResource`.
-	Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (Api_TapClient, error)
-	// Executes tapping over Kubernetes resources.
-	TapByResource(ctx context.Context, in *TapByResourceRequest, opts ...grpc.CallOption) (Api_TapByResourceClient, error)
-	Version(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*VersionInfo, error)
-	SelfCheck(ctx context.Context, in *healthcheck.SelfCheckRequest, opts ...grpc.CallOption) (*healthcheck.SelfCheckResponse, error)
+func (m *ResolveDestinationRequest) GetAuthority() string {
+	if m != nil {
+		return m.Authority
+	}
+	return ""
 }
 
-type apiClient struct {
-	cc *grpc.ClientConn
+type ResolveDestinationResponse struct {
+	// False if the destination service does not know about the authority.
+	Exists bool `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	// The "ip:port" addresses of the authority's endpoints.
+	Addresses []string `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// The authority's endpoints, in the same order as addresses, with the
+	// metadata that the proxies are given about them.
+	Endpoints            []*DestinationEndpoint `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
 }
 
-func NewApiClient(cc *grpc.ClientConn) ApiClient {
-	return &apiClient{cc}
+func (m *ResolveDestinationResponse) Reset()         { *m = ResolveDestinationResponse{} }
+func (m *ResolveDestinationResponse) String() string { return proto.CompactTextString(m) }
+func (*ResolveDestinationResponse) ProtoMessage()    {}
+func (*ResolveDestinationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{24}
+}
+func (m *ResolveDestinationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResolveDestinationResponse.Unmarshal(m, b)
+}
+func (m *ResolveDestinationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResolveDestinationResponse.Marshal(b, m, deterministic)
+}
+func (dst *ResolveDestinationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResolveDestinationResponse.Merge(dst, src)
+}
+func (m *ResolveDestinationResponse) XXX_Size() int {
+	return xxx_messageInfo_ResolveDestinationResponse.Size(m)
+}
+func (m *ResolveDestinationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResolveDestinationResponse.DiscardUnknown(m)
 }
 
-func (c *apiClient) StatSummary(ctx context.Context, in *StatSummaryRequest, opts ...grpc.CallOption) (*StatSummaryResponse, error) {
-	out := new(StatSummaryResponse)
-	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/StatSummary", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_ResolveDestinationResponse proto.InternalMessageInfo
+
+func (m *ResolveDestinationResponse) GetExists() bool {
+	if m != nil {
+		return m.Exists
 	}
-	return out, nil
+	return false
 }
 
-func (c *apiClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error) {
-	out := new(ListPodsResponse)
-	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/ListPods", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *ResolveDestinationResponse) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
 	}
-	return out, nil
+	return nil
 }
 
-// Deprecated: Do not use.
-func (c *apiClient) Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (Api_TapClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Api_serviceDesc.Streams[0], "/linkerd2.public.Api/Tap", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &apiTapClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+func (m *ResolveDestinationResponse) GetEndpoints() []*DestinationEndpoint {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
+type TopRoutesRequest struct {
+	Selector             *ResourceSelection `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	TimeWindow           string             `protobuf:"bytes,2,opt,name=time_window,json=timeWindow,proto3" json:"time_window,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TopRoutesRequest) Reset()         { *m = TopRoutesRequest{} }
+func (m *TopRoutesRequest) String() string { return proto.CompactTextString(m) }
+func (*TopRoutesRequest) ProtoMessage()    {}
+func (*TopRoutesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{25}
+}
+func (m *TopRoutesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TopRoutesRequest.Unmarshal(m, b)
+}
+func (m *TopRoutesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TopRoutesRequest.Marshal(b, m, deterministic)
+}
+func (dst *TopRoutesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopRoutesRequest.Merge(dst, src)
+}
+func (m *TopRoutesRequest) XXX_Size() int {
+	return xxx_messageInfo_TopRoutesRequest.Size(m)
+}
+func (m *TopRoutesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopRoutesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopRoutesRequest proto.InternalMessageInfo
+
+func (m *TopRoutesRequest) GetSelector() *ResourceSelection {
+	if m != nil {
+		return m.Selector
+	}
+	return nil
+}
+
+func (m *TopRoutesRequest) GetTimeWindow() string {
+	if m != nil {
+		return m.TimeWindow
+	}
+	return ""
+}
+
+type TopRoutesResponse struct {
+	// Types that are valid to be assigned to Response:
+	//	*TopRoutesResponse_Ok_
+	//	*TopRoutesResponse_Error
+	Response             isTopRoutesResponse_Response `protobuf_oneof:"response"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *TopRoutesResponse) Reset()         { *m = TopRoutesResponse{} }
+func (m *TopRoutesResponse) String() string { return proto.CompactTextString(m) }
+func (*TopRoutesResponse) ProtoMessage()    {}
+func (*TopRoutesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{26}
+}
+func (m *TopRoutesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TopRoutesResponse.Unmarshal(m, b)
+}
+func (m *TopRoutesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TopRoutesResponse.Marshal(b, m, deterministic)
+}
+func (dst *TopRoutesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopRoutesResponse.Merge(dst, src)
+}
+func (m *TopRoutesResponse) XXX_Size() int {
+	return xxx_messageInfo_TopRoutesResponse.Size(m)
+}
+func (m *TopRoutesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopRoutesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopRoutesResponse proto.InternalMessageInfo
+
+type isTopRoutesResponse_Response interface {
+	isTopRoutesResponse_Response()
+}
+
+type TopRoutesResponse_Ok_ struct {
+	Ok *TopRoutesResponse_Ok `protobuf:"bytes,1,opt,name=ok,proto3,oneof"`
+}
+
+type TopRoutesResponse_Error struct {
+	Error *ResourceError `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*TopRoutesResponse_Ok_) isTopRoutesResponse_Response() {}
+
+func (*TopRoutesResponse_Error) isTopRoutesResponse_Response() {}
+
+func (m *TopRoutesResponse) GetResponse() isTopRoutesResponse_Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *TopRoutesResponse) GetOk() *TopRoutesResponse_Ok {
+	if x, ok := m.GetResponse().(*TopRoutesResponse_Ok_); ok {
+		return x.Ok
+	}
+	return nil
+}
+
+func (m *TopRoutesResponse) GetError() *ResourceError {
+	if x, ok := m.GetResponse().(*TopRoutesResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*TopRoutesResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _TopRoutesResponse_OneofMarshaler, _TopRoutesResponse_OneofUnmarshaler, _TopRoutesResponse_OneofSizer, []interface{}{
+		(*TopRoutesResponse_Ok_)(nil),
+		(*TopRoutesResponse_Error)(nil),
+	}
+}
+
+func _TopRoutesResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*TopRoutesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *TopRoutesResponse_Ok_:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ok); err != nil {
+			return err
+		}
+	case *TopRoutesResponse_Error:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Error); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("TopRoutesResponse.Response has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _TopRoutesResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*TopRoutesResponse)
+	switch tag {
+	case 1: // response.ok
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TopRoutesResponse_Ok)
+		err := b.DecodeMessage(msg)
+		m.Response = &TopRoutesResponse_Ok_{msg}
+		return true, err
+	case 2: // response.error
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResourceError)
+		err := b.DecodeMessage(msg)
+		m.Response = &TopRoutesResponse_Error{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _TopRoutesResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*TopRoutesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *TopRoutesResponse_Ok_:
+		s := proto.Size(x.Ok)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TopRoutesResponse_Error:
+		s := proto.Size(x.Error)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type TopRoutesResponse_Ok struct {
+	RouteTable           *RouteTable `protobuf:"bytes,1,opt,name=route_table,json=routeTable,proto3" json:"route_table,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *TopRoutesResponse_Ok) Reset()         { *m = TopRoutesResponse_Ok{} }
+func (m *TopRoutesResponse_Ok) String() string { return proto.CompactTextString(m) }
+func (*TopRoutesResponse_Ok) ProtoMessage()    {}
+func (*TopRoutesResponse_Ok) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{26, 0}
+}
+func (m *TopRoutesResponse_Ok) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TopRoutesResponse_Ok.Unmarshal(m, b)
+}
+func (m *TopRoutesResponse_Ok) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TopRoutesResponse_Ok.Marshal(b, m, deterministic)
+}
+func (dst *TopRoutesResponse_Ok) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopRoutesResponse_Ok.Merge(dst, src)
+}
+func (m *TopRoutesResponse_Ok) XXX_Size() int {
+	return xxx_messageInfo_TopRoutesResponse_Ok.Size(m)
+}
+func (m *TopRoutesResponse_Ok) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopRoutesResponse_Ok.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopRoutesResponse_Ok proto.InternalMessageInfo
+
+func (m *TopRoutesResponse_Ok) GetRouteTable() *RouteTable {
+	if m != nil {
+		return m.RouteTable
+	}
+	return nil
+}
+
+// The requests received by a resource, broken down by the ServiceProfile
+// routes they matched.
+type RouteTable struct {
+	Rows                 []*RouteTable_Row `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *RouteTable) Reset()         { *m = RouteTable{} }
+func (m *RouteTable) String() string { return proto.CompactTextString(m) }
+func (*RouteTable) ProtoMessage()    {}
+func (*RouteTable) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{27}
+}
+func (m *RouteTable) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RouteTable.Unmarshal(m, b)
+}
+func (m *RouteTable) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RouteTable.Marshal(b, m, deterministic)
+}
+func (dst *RouteTable) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RouteTable.Merge(dst, src)
+}
+func (m *RouteTable) XXX_Size() int {
+	return xxx_messageInfo_RouteTable.Size(m)
+}
+func (m *RouteTable) XXX_DiscardUnknown() {
+	xxx_messageInfo_RouteTable.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RouteTable proto.InternalMessageInfo
+
+func (m *RouteTable) GetRows() []*RouteTable_Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type RouteTable_Row struct {
+	// The name of the route, or "[DEFAULT]" for the requests that didn't match
+	// any route of the authority's ServiceProfile.
+	Route string `protobuf:"bytes,1,opt,name=route,proto3" json:"route,omitempty"`
+	// The authority the requests were sent to.
+	Authority            string      `protobuf:"bytes,2,opt,name=authority,proto3" json:"authority,omitempty"`
+	TimeWindow           string      `protobuf:"bytes,3,opt,name=time_window,json=timeWindow,proto3" json:"time_window,omitempty"`
+	Stats                *BasicStats `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *RouteTable_Row) Reset()         { *m = RouteTable_Row{} }
+func (m *RouteTable_Row) String() string { return proto.CompactTextString(m) }
+func (*RouteTable_Row) ProtoMessage()    {}
+func (*RouteTable_Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{27, 0}
+}
+func (m *RouteTable_Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RouteTable_Row.Unmarshal(m, b)
+}
+func (m *RouteTable_Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RouteTable_Row.Marshal(b, m, deterministic)
+}
+func (dst *RouteTable_Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RouteTable_Row.Merge(dst, src)
+}
+func (m *RouteTable_Row) XXX_Size() int {
+	return xxx_messageInfo_RouteTable_Row.Size(m)
+}
+func (m *RouteTable_Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_RouteTable_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RouteTable_Row proto.InternalMessageInfo
+
+func (m *RouteTable_Row) GetRoute() string {
+	if m != nil {
+		return m.Route
+	}
+	return ""
+}
+
+func (m *RouteTable_Row) GetAuthority() string {
+	if m != nil {
+		return m.Authority
+	}
+	return ""
+}
+
+func (m *RouteTable_Row) GetTimeWindow() string {
+	if m != nil {
+		return m.TimeWindow
+	}
+	return ""
+}
+
+func (m *RouteTable_Row) GetStats() *BasicStats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+type EdgesRequest struct {
+	Selector             *ResourceSelection `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *EdgesRequest) Reset()         { *m = EdgesRequest{} }
+func (m *EdgesRequest) String() string { return proto.CompactTextString(m) }
+func (*EdgesRequest) ProtoMessage()    {}
+func (*EdgesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{28}
+}
+func (m *EdgesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EdgesRequest.Unmarshal(m, b)
+}
+func (m *EdgesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EdgesRequest.Marshal(b, m, deterministic)
+}
+func (dst *EdgesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EdgesRequest.Merge(dst, src)
+}
+func (m *EdgesRequest) XXX_Size() int {
+	return xxx_messageInfo_EdgesRequest.Size(m)
+}
+func (m *EdgesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EdgesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EdgesRequest proto.InternalMessageInfo
+
+func (m *EdgesRequest) GetSelector() *ResourceSelection {
+	if m != nil {
+		return m.Selector
+	}
+	return nil
+}
+
+type EdgesResponse struct {
+	// Types that are valid to be assigned to Response:
+	//	*EdgesResponse_Ok_
+	//	*EdgesResponse_Error
+	Response             isEdgesResponse_Response `protobuf_oneof:"response"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *EdgesResponse) Reset()         { *m = EdgesResponse{} }
+func (m *EdgesResponse) String() string { return proto.CompactTextString(m) }
+func (*EdgesResponse) ProtoMessage()    {}
+func (*EdgesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{29}
+}
+func (m *EdgesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EdgesResponse.Unmarshal(m, b)
+}
+func (m *EdgesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EdgesResponse.Marshal(b, m, deterministic)
+}
+func (dst *EdgesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EdgesResponse.Merge(dst, src)
+}
+func (m *EdgesResponse) XXX_Size() int {
+	return xxx_messageInfo_EdgesResponse.Size(m)
+}
+func (m *EdgesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EdgesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EdgesResponse proto.InternalMessageInfo
+
+type isEdgesResponse_Response interface {
+	isEdgesResponse_Response()
+}
+
+type EdgesResponse_Ok_ struct {
+	Ok *EdgesResponse_Ok `protobuf:"bytes,1,opt,name=ok,proto3,oneof"`
+}
+
+type EdgesResponse_Error struct {
+	Error *ResourceError `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*EdgesResponse_Ok_) isEdgesResponse_Response() {}
+
+func (*EdgesResponse_Error) isEdgesResponse_Response() {}
+
+func (m *EdgesResponse) GetResponse() isEdgesResponse_Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *EdgesResponse) GetOk() *EdgesResponse_Ok {
+	if x, ok := m.GetResponse().(*EdgesResponse_Ok_); ok {
+		return x.Ok
+	}
+	return nil
+}
+
+func (m *EdgesResponse) GetError() *ResourceError {
+	if x, ok := m.GetResponse().(*EdgesResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*EdgesResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _EdgesResponse_OneofMarshaler, _EdgesResponse_OneofUnmarshaler, _EdgesResponse_OneofSizer, []interface{}{
+		(*EdgesResponse_Ok_)(nil),
+		(*EdgesResponse_Error)(nil),
+	}
+}
+
+func _EdgesResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*EdgesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *EdgesResponse_Ok_:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ok); err != nil {
+			return err
+		}
+	case *EdgesResponse_Error:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Error); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("EdgesResponse.Response has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _EdgesResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*EdgesResponse)
+	switch tag {
+	case 1: // response.ok
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(EdgesResponse_Ok)
+		err := b.DecodeMessage(msg)
+		m.Response = &EdgesResponse_Ok_{msg}
+		return true, err
+	case 2: // response.error
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResourceError)
+		err := b.DecodeMessage(msg)
+		m.Response = &EdgesResponse_Error{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _EdgesResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*EdgesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *EdgesResponse_Ok_:
+		s := proto.Size(x.Ok)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *EdgesResponse_Error:
+		s := proto.Size(x.Error)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type EdgesResponse_Ok struct {
+	Edges                []*Edge  `protobuf:"bytes,1,rep,name=edges,proto3" json:"edges,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EdgesResponse_Ok) Reset()         { *m = EdgesResponse_Ok{} }
+func (m *EdgesResponse_Ok) String() string { return proto.CompactTextString(m) }
+func (*EdgesResponse_Ok) ProtoMessage()    {}
+func (*EdgesResponse_Ok) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{29, 0}
+}
+func (m *EdgesResponse_Ok) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EdgesResponse_Ok.Unmarshal(m, b)
+}
+func (m *EdgesResponse_Ok) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EdgesResponse_Ok.Marshal(b, m, deterministic)
+}
+func (dst *EdgesResponse_Ok) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EdgesResponse_Ok.Merge(dst, src)
+}
+func (m *EdgesResponse_Ok) XXX_Size() int {
+	return xxx_messageInfo_EdgesResponse_Ok.Size(m)
+}
+func (m *EdgesResponse_Ok) XXX_DiscardUnknown() {
+	xxx_messageInfo_EdgesResponse_Ok.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EdgesResponse_Ok proto.InternalMessageInfo
+
+func (m *EdgesResponse_Ok) GetEdges() []*Edge {
+	if m != nil {
+		return m.Edges
+	}
+	return nil
+}
+
+// The requests sent from a source resource to a destination resource.
+type Edge struct {
+	Src *Resource `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst *Resource `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"`
+	// The TLS identities of the source and destination proxies, if the
+	// requests were sent over mTLS.
+	ClientId string `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ServerId string `protobuf:"bytes,4,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	// Why the requests weren't sent over mTLS, if they weren't.
+	NoIdentityMsg        string   `protobuf:"bytes,5,opt,name=no_identity_msg,json=noIdentityMsg,proto3" json:"no_identity_msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Edge) Reset()         { *m = Edge{} }
+func (m *Edge) String() string { return proto.CompactTextString(m) }
+func (*Edge) ProtoMessage()    {}
+func (*Edge) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{30}
+}
+func (m *Edge) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Edge.Unmarshal(m, b)
+}
+func (m *Edge) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Edge.Marshal(b, m, deterministic)
+}
+func (dst *Edge) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Edge.Merge(dst, src)
+}
+func (m *Edge) XXX_Size() int {
+	return xxx_messageInfo_Edge.Size(m)
+}
+func (m *Edge) XXX_DiscardUnknown() {
+	xxx_messageInfo_Edge.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Edge proto.InternalMessageInfo
+
+func (m *Edge) GetSrc() *Resource {
+	if m != nil {
+		return m.Src
+	}
+	return nil
+}
+
+func (m *Edge) GetDst() *Resource {
+	if m != nil {
+		return m.Dst
+	}
+	return nil
+}
+
+func (m *Edge) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+func (m *Edge) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
+func (m *Edge) GetNoIdentityMsg() string {
+	if m != nil {
+		return m.NoIdentityMsg
+	}
+	return ""
+}
+
+type ListServicesRequest struct {
+	Namespace            string   `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListServicesRequest) Reset()         { *m = ListServicesRequest{} }
+func (m *ListServicesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListServicesRequest) ProtoMessage()    {}
+func (*ListServicesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{31}
+}
+func (m *ListServicesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListServicesRequest.Unmarshal(m, b)
+}
+func (m *ListServicesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListServicesRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListServicesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListServicesRequest.Merge(dst, src)
+}
+func (m *ListServicesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListServicesRequest.Size(m)
+}
+func (m *ListServicesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListServicesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListServicesRequest proto.InternalMessageInfo
+
+func (m *ListServicesRequest) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type ListServicesResponse struct {
+	Services             []*Service `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListServicesResponse) Reset()         { *m = ListServicesResponse{} }
+func (m *ListServicesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListServicesResponse) ProtoMessage()    {}
+func (*ListServicesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{32}
+}
+func (m *ListServicesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListServicesResponse.Unmarshal(m, b)
+}
+func (m *ListServicesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListServicesResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListServicesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListServicesResponse.Merge(dst, src)
+}
+func (m *ListServicesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListServicesResponse.Size(m)
+}
+func (m *ListServicesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListServicesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListServicesResponse proto.InternalMessageInfo
+
+func (m *ListServicesResponse) GetServices() []*Service {
+	if m != nil {
+		return m.Services
+	}
+	return nil
+}
+
+type Service struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// The labels of the pods that the service sends traffic to.
+	Selector map[string]string `protobuf:"bytes,3,rep,name=selector,proto3" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The number of running or pending pods selected by the service, and how
+	// many of them are meshed.
+	MeshedPodCount       uint64   `protobuf:"varint,4,opt,name=meshed_pod_count,json=meshedPodCount,proto3" json:"meshed_pod_count,omitempty"`
+	RunningPodCount      uint64   `protobuf:"varint,5,opt,name=running_pod_count,json=runningPodCount,proto3" json:"running_pod_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Service) Reset()         { *m = Service{} }
+func (m *Service) String() string { return proto.CompactTextString(m) }
+func (*Service) ProtoMessage()    {}
+func (*Service) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{33}
+}
+func (m *Service) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Service.Unmarshal(m, b)
+}
+func (m *Service) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Service.Marshal(b, m, deterministic)
+}
+func (dst *Service) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Service.Merge(dst, src)
+}
+func (m *Service) XXX_Size() int {
+	return xxx_messageInfo_Service.Size(m)
+}
+func (m *Service) XXX_DiscardUnknown() {
+	xxx_messageInfo_Service.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Service proto.InternalMessageInfo
+
+func (m *Service) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Service) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *Service) GetSelector() map[string]string {
+	if m != nil {
+		return m.Selector
+	}
+	return nil
+}
+
+func (m *Service) GetMeshedPodCount() uint64 {
+	if m != nil {
+		return m.MeshedPodCount
+	}
+	return 0
+}
+
+func (m *Service) GetRunningPodCount() uint64 {
+	if m != nil {
+		return m.RunningPodCount
+	}
+	return 0
+}
+
+// The version of a container of a control plane pod, as given by the tag of
+// its image.
+type ComponentVersion struct {
+	Component            string   `protobuf:"bytes,1,opt,name=component,proto3" json:"component,omitempty"`
+	Pod                  string   `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	Container            string   `protobuf:"bytes,3,opt,name=container,proto3" json:"container,omitempty"`
+	Version              string   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ComponentVersion) Reset()         { *m = ComponentVersion{} }
+func (m *ComponentVersion) String() string { return proto.CompactTextString(m) }
+func (*ComponentVersion) ProtoMessage()    {}
+func (*ComponentVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{34}
+}
+func (m *ComponentVersion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ComponentVersion.Unmarshal(m, b)
+}
+func (m *ComponentVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ComponentVersion.Marshal(b, m, deterministic)
+}
+func (dst *ComponentVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ComponentVersion.Merge(dst, src)
+}
+func (m *ComponentVersion) XXX_Size() int {
+	return xxx_messageInfo_ComponentVersion.Size(m)
+}
+func (m *ComponentVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_ComponentVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ComponentVersion proto.InternalMessageInfo
+
+func (m *ComponentVersion) GetComponent() string {
+	if m != nil {
+		return m.Component
+	}
+	return ""
+}
+
+func (m *ComponentVersion) GetPod() string {
+	if m != nil {
+		return m.Pod
+	}
+	return ""
+}
+
+func (m *ComponentVersion) GetContainer() string {
+	if m != nil {
+		return m.Container
+	}
+	return ""
+}
+
+func (m *ComponentVersion) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+type StatSummaryStreamRequest struct {
+	Request *StatSummaryRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	// How often the stats are recomputed and sent, as a duration string such as
+	// "10s". Defaults to 10 seconds.
+	Interval             string   `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatSummaryStreamRequest) Reset()         { *m = StatSummaryStreamRequest{} }
+func (m *StatSummaryStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*StatSummaryStreamRequest) ProtoMessage()    {}
+func (*StatSummaryStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{35}
+}
+func (m *StatSummaryStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatSummaryStreamRequest.Unmarshal(m, b)
+}
+func (m *StatSummaryStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatSummaryStreamRequest.Marshal(b, m, deterministic)
+}
+func (dst *StatSummaryStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatSummaryStreamRequest.Merge(dst, src)
+}
+func (m *StatSummaryStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_StatSummaryStreamRequest.Size(m)
+}
+func (m *StatSummaryStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatSummaryStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatSummaryStreamRequest proto.InternalMessageInfo
+
+func (m *StatSummaryStreamRequest) GetRequest() *StatSummaryRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *StatSummaryStreamRequest) GetInterval() string {
+	if m != nil {
+		return m.Interval
+	}
+	return ""
+}
+
+// An endpoint that the destination service resolves an authority to.
+type DestinationEndpoint struct {
+	// The "ip:port" address of the endpoint.
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// The pod behind the endpoint, if any.
+	Pod string `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	// The weight of the endpoint relative to the other endpoints of the
+	// authority, when its traffic is split across backends.
+	Weight uint32 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	// The TLS identity that the proxies expect the endpoint to have, if any.
+	TlsIdentity string `protobuf:"bytes,4,opt,name=tls_identity,json=tlsIdentity,proto3" json:"tls_identity,omitempty"`
+	// Whether the proxies are hinted that the endpoint accepts HTTP/2.
+	H2                   bool     `protobuf:"varint,5,opt,name=h2,proto3" json:"h2,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DestinationEndpoint) Reset()         { *m = DestinationEndpoint{} }
+func (m *DestinationEndpoint) String() string { return proto.CompactTextString(m) }
+func (*DestinationEndpoint) ProtoMessage()    {}
+func (*DestinationEndpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_public_62da165bc60d64f8, []int{36}
+}
+func (m *DestinationEndpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DestinationEndpoint.Unmarshal(m, b)
+}
+func (m *DestinationEndpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DestinationEndpoint.Marshal(b, m, deterministic)
+}
+func (dst *DestinationEndpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DestinationEndpoint.Merge(dst, src)
+}
+func (m *DestinationEndpoint) XXX_Size() int {
+	return xxx_messageInfo_DestinationEndpoint.Size(m)
+}
+func (m *DestinationEndpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_DestinationEndpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DestinationEndpoint proto.InternalMessageInfo
+
+func (m *DestinationEndpoint) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *DestinationEndpoint) GetPod() string {
+	if m != nil {
+		return m.Pod
+	}
+	return ""
+}
+
+func (m *DestinationEndpoint) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+func (m *DestinationEndpoint) GetTlsIdentity() string {
+	if m != nil {
+		return m.TlsIdentity
+	}
+	return ""
+}
+
+func (m *DestinationEndpoint) GetH2() bool {
+	if m != nil {
+		return m.H2
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "linkerd2.public.Empty")
+	proto.RegisterType((*VersionInfo)(nil), "linkerd2.public.VersionInfo")
+	proto.RegisterType((*ListPodsRequest)(nil), "linkerd2.public.ListPodsRequest")
+	proto.RegisterType((*ListPodsResponse)(nil), "linkerd2.public.ListPodsResponse")
+	proto.RegisterType((*Pod)(nil), "linkerd2.public.Pod")
+	proto.RegisterType((*TapRequest)(nil), "linkerd2.public.TapRequest")
+	proto.RegisterType((*TapByResourceRequest)(nil), "linkerd2.public.TapByResourceRequest")
+	proto.RegisterType((*TapByResourceRequest_Match)(nil), "linkerd2.public.TapByResourceRequest.Match")
+	proto.RegisterType((*TapByResourceRequest_Match_Seq)(nil), "linkerd2.public.TapByResourceRequest.Match.Seq")
+	proto.RegisterType((*TapByResourceRequest_Match_Http)(nil), "linkerd2.public.TapByResourceRequest.Match.Http")
+	proto.RegisterType((*TapByResourceRequest_Match_Http_StatusRange)(nil), "linkerd2.public.TapByResourceRequest.Match.Http.StatusRange")
+	proto.RegisterType((*HttpMethod)(nil), "linkerd2.public.HttpMethod")
+	proto.RegisterType((*Scheme)(nil), "linkerd2.public.Scheme")
+	proto.RegisterType((*IPAddress)(nil), "linkerd2.public.IPAddress")
+	proto.RegisterType((*IPv6)(nil), "linkerd2.public.IPv6")
+	proto.RegisterType((*TcpAddress)(nil), "linkerd2.public.TcpAddress")
+	proto.RegisterType((*Eos)(nil), "linkerd2.public.Eos")
+	proto.RegisterType((*TapEvent)(nil), "linkerd2.public.TapEvent")
+	proto.RegisterType((*TapEvent_EndpointMeta)(nil), "linkerd2.public.TapEvent.EndpointMeta")
+	proto.RegisterMapType((map[string]string)(nil), "linkerd2.public.TapEvent.EndpointMeta.LabelsEntry")
+	proto.RegisterType((*TapEvent_Http)(nil), "linkerd2.public.TapEvent.Http")
+	proto.RegisterType((*TapEvent_Http_StreamId)(nil), "linkerd2.public.TapEvent.Http.StreamId")
+	proto.RegisterType((*TapEvent_Http_RequestInit)(nil), "linkerd2.public.TapEvent.Http.RequestInit")
+	proto.RegisterType((*TapEvent_Http_ResponseInit)(nil), "linkerd2.public.TapEvent.Http.ResponseInit")
+	proto.RegisterType((*TapEvent_Http_ResponseEnd)(nil), "linkerd2.public.TapEvent.Http.ResponseEnd")
+	proto.RegisterType((*ApiError)(nil), "linkerd2.public.ApiError")
+	proto.RegisterType((*PodErrors)(nil), "linkerd2.public.PodErrors")
+	proto.RegisterType((*PodErrors_PodError)(nil), "linkerd2.public.PodErrors.PodError")
+	proto.RegisterType((*PodErrors_PodError_ContainerError)(nil), "linkerd2.public.PodErrors.PodError.ContainerError")
+	proto.RegisterType((*Resource)(nil), "linkerd2.public.Resource")
+	proto.RegisterType((*ResourceSelection)(nil), "linkerd2.public.ResourceSelection")
+	proto.RegisterType((*ResourceError)(nil), "linkerd2.public.ResourceError")
+	proto.RegisterType((*StatSummaryRequest)(nil), "linkerd2.public.StatSummaryRequest")
+	proto.RegisterType((*StatSummaryResponse)(nil), "linkerd2.public.StatSummaryResponse")
+	proto.RegisterType((*StatSummaryResponse_Ok)(nil), "linkerd2.public.StatSummaryResponse.Ok")
+	proto.RegisterType((*BasicStats)(nil), "linkerd2.public.BasicStats")
+	proto.RegisterType((*StatTable)(nil), "linkerd2.public.StatTable")
+	proto.RegisterType((*StatTable_PodGroup)(nil), "linkerd2.public.StatTable.PodGroup")
+	proto.RegisterType((*StatTable_PodGroup_Row)(nil), "linkerd2.public.StatTable.PodGroup.Row")
+	proto.RegisterMapType((map[string]*PodErrors)(nil), "linkerd2.public.StatTable.PodGroup.Row.ErrorsByPodEntry")
+	proto.RegisterType((*ResolveDestinationRequest)(nil), "linkerd2.public.ResolveDestinationRequest")
+	proto.RegisterType((*ResolveDestinationResponse)(nil), "linkerd2.public.ResolveDestinationResponse")
+	proto.RegisterType((*TopRoutesRequest)(nil), "linkerd2.public.TopRoutesRequest")
+	proto.RegisterType((*TopRoutesResponse)(nil), "linkerd2.public.TopRoutesResponse")
+	proto.RegisterType((*TopRoutesResponse_Ok)(nil), "linkerd2.public.TopRoutesResponse.Ok")
+	proto.RegisterType((*RouteTable)(nil), "linkerd2.public.RouteTable")
+	proto.RegisterType((*RouteTable_Row)(nil), "linkerd2.public.RouteTable.Row")
+	proto.RegisterType((*EdgesRequest)(nil), "linkerd2.public.EdgesRequest")
+	proto.RegisterType((*EdgesResponse)(nil), "linkerd2.public.EdgesResponse")
+	proto.RegisterType((*EdgesResponse_Ok)(nil), "linkerd2.public.EdgesResponse.Ok")
+	proto.RegisterType((*Edge)(nil), "linkerd2.public.Edge")
+	proto.RegisterType((*ListServicesRequest)(nil), "linkerd2.public.ListServicesRequest")
+	proto.RegisterType((*ListServicesResponse)(nil), "linkerd2.public.ListServicesResponse")
+	proto.RegisterType((*Service)(nil), "linkerd2.public.Service")
+	proto.RegisterType((*ComponentVersion)(nil), "linkerd2.public.ComponentVersion")
+	proto.RegisterType((*StatSummaryStreamRequest)(nil), "linkerd2.public.StatSummaryStreamRequest")
+	proto.RegisterType((*DestinationEndpoint)(nil), "linkerd2.public.DestinationEndpoint")
+	proto.RegisterMapType((map[string]string)(nil), "linkerd2.public.Service.SelectorEntry")
+	proto.RegisterEnum("linkerd2.public.HttpMethod_Registered", HttpMethod_Registered_name, HttpMethod_Registered_value)
+	proto.RegisterEnum("linkerd2.public.Scheme_Registered", Scheme_Registered_name, Scheme_Registered_value)
+	proto.RegisterEnum("linkerd2.public.TapEvent_ProxyDirection", TapEvent_ProxyDirection_name, TapEvent_ProxyDirection_value)
+	proto.RegisterEnum("linkerd2.public.TapEvent_Http_ResponseEnd_Classification", TapEvent_Http_ResponseEnd_Classification_name, TapEvent_Http_ResponseEnd_Classification_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ApiClient is the client API for Api service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ApiClient interface {
+	StatSummary(ctx context.Context, in *StatSummaryRequest, opts ...grpc.CallOption) (*StatSummaryResponse, error)
+	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error)
+	ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error)
+	// Superceded by `TapByResource`.
+	Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (Api_TapClient, error)
+	// Executes tapping over Kubernetes resources.
+	TapByResource(ctx context.Context, in *TapByResourceRequest, opts ...grpc.CallOption) (Api_TapByResourceClient, error)
+	Version(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*VersionInfo, error)
+	SelfCheck(ctx context.Context, in *healthcheck.SelfCheckRequest, opts ...grpc.CallOption) (*healthcheck.SelfCheckResponse, error)
+	// Reads the first update of a destination stream for an authority.
+	ResolveDestination(ctx context.Context, in *ResolveDestinationRequest, opts ...grpc.CallOption) (*ResolveDestinationResponse, error)
+	// Returns the stats of the requests received by a resource, per route.
+	TopRoutes(ctx context.Context, in *TopRoutesRequest, opts ...grpc.CallOption) (*TopRoutesResponse, error)
+	// Returns the pairs of resources that sent requests to each other, with
+	// their identities.
+	Edges(ctx context.Context, in *EdgesRequest, opts ...grpc.CallOption) (*EdgesResponse, error)
+	// Sends the response of StatSummary for a request every interval, until the
+	// stream is cancelled.
+	StatSummaryStream(ctx context.Context, in *StatSummaryStreamRequest, opts ...grpc.CallOption) (Api_StatSummaryStreamClient, error)
+}
+
+type apiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewApiClient(cc *grpc.ClientConn) ApiClient {
+	return &apiClient{cc}
+}
+
+func (c *apiClient) StatSummary(ctx context.Context, in *StatSummaryRequest, opts ...grpc.CallOption) (*StatSummaryResponse, error) {
+	out := new(StatSummaryResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/StatSummary", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apiClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error) {
+	out := new(ListPodsResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/ListPods", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apiClient) ListServices(ctx context.Context, in *ListServicesRequest, opts ...grpc.CallOption) (*ListServicesResponse, error) {
+	out := new(ListServicesResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/ListServices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Deprecated: Do not use.
+func (c *apiClient) Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (Api_TapClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Api_serviceDesc.Streams[0], "/linkerd2.public.Api/Tap", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &apiTapClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
 	}
 	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
@@ -3874,16 +5221,86 @@ func (c *apiClient) SelfCheck(ctx context.Context, in *healthcheck.SelfCheckRequ
 	return out, nil
 }
 
+func (c *apiClient) ResolveDestination(ctx context.Context, in *ResolveDestinationRequest, opts ...grpc.CallOption) (*ResolveDestinationResponse, error) {
+	out := new(ResolveDestinationResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/ResolveDestination", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apiClient) TopRoutes(ctx context.Context, in *TopRoutesRequest, opts ...grpc.CallOption) (*TopRoutesResponse, error) {
+	out := new(TopRoutesResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/TopRoutes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apiClient) Edges(ctx context.Context, in *EdgesRequest, opts ...grpc.CallOption) (*EdgesResponse, error) {
+	out := new(EdgesResponse)
+	err := c.cc.Invoke(ctx, "/linkerd2.public.Api/Edges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *apiClient) StatSummaryStream(ctx context.Context, in *StatSummaryStreamRequest, opts ...grpc.CallOption) (Api_StatSummaryStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Api_serviceDesc.Streams[2], "/linkerd2.public.Api/StatSummaryStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &apiStatSummaryStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Api_StatSummaryStreamClient interface {
+	Recv() (*StatSummaryResponse, error)
+	grpc.ClientStream
+}
+
+type apiStatSummaryStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *apiStatSummaryStreamClient) Recv() (*StatSummaryResponse, error) {
+	m := new(StatSummaryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ApiServer is the server API for Api service.
 type ApiServer interface {
 	StatSummary(context.Context, *StatSummaryRequest) (*StatSummaryResponse, error)
 	ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error)
+	ListServices(context.Context, *ListServicesRequest) (*ListServicesResponse, error)
 	// Superceded by `TapByResource`.
 	Tap(*TapRequest, Api_TapServer) error
 	// Executes tapping over Kubernetes resources.
 	TapByResource(*TapByResourceRequest, Api_TapByResourceServer) error
 	Version(context.Context, *Empty) (*VersionInfo, error)
 	SelfCheck(context.Context, *healthcheck.SelfCheckRequest) (*healthcheck.SelfCheckResponse, error)
+	// Reads the first update of a destination stream for an authority.
+	ResolveDestination(context.Context, *ResolveDestinationRequest) (*ResolveDestinationResponse, error)
+	// Returns the stats of the requests received by a resource, per route.
+	TopRoutes(context.Context, *TopRoutesRequest) (*TopRoutesResponse, error)
+	// Returns the pairs of resources that sent requests to each other, with
+	// their identities.
+	Edges(context.Context, *EdgesRequest) (*EdgesResponse, error)
+	// Sends the response of StatSummary for a request every interval, until the
+	// stream is cancelled.
+	StatSummaryStream(*StatSummaryStreamRequest, Api_StatSummaryStreamServer) error
 }
 
 func RegisterApiServer(s *grpc.Server, srv ApiServer) {
@@ -3926,6 +5343,24 @@ func _Api_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Api_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApiServer).ListServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/linkerd2.public.Api/ListServices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApiServer).ListServices(ctx, req.(*ListServicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Api_Tap_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(TapRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -4004,6 +5439,81 @@ func _Api_SelfCheck_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Api_ResolveDestination_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveDestinationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApiServer).ResolveDestination(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/linkerd2.public.Api/ResolveDestination",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApiServer).ResolveDestination(ctx, req.(*ResolveDestinationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Api_TopRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApiServer).TopRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/linkerd2.public.Api/TopRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApiServer).TopRoutes(ctx, req.(*TopRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Api_Edges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EdgesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApiServer).Edges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/linkerd2.public.Api/Edges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApiServer).Edges(ctx, req.(*EdgesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Api_StatSummaryStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StatSummaryStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ApiServer).StatSummaryStream(m, &apiStatSummaryStreamServer{stream})
+}
+
+type Api_StatSummaryStreamServer interface {
+	Send(*StatSummaryResponse) error
+	grpc.ServerStream
+}
+
+type apiStatSummaryStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *apiStatSummaryStreamServer) Send(m *StatSummaryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Api_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "linkerd2.public.Api",
 	HandlerType: (*ApiServer)(nil),
@@ -4016,6 +5526,10 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListPods",
 			Handler:    _Api_ListPods_Handler,
 		},
+		{
+			MethodName: "ListServices",
+			Handler:    _Api_ListServices_Handler,
+		},
 		{
 			MethodName: "Version",
 			Handler:    _Api_Version_Handler,
@@ -4024,6 +5538,18 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SelfCheck",
 			Handler:    _Api_SelfCheck_Handler,
 		},
+		{
+			MethodName: "ResolveDestination",
+			Handler:    _Api_ResolveDestination_Handler,
+		},
+		{
+			MethodName: "TopRoutes",
+			Handler:    _Api_TopRoutes_Handler,
+		},
+		{
+			MethodName: "Edges",
+			Handler:    _Api_Edges_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -4036,6 +5562,11 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Api_TapByResource_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StatSummaryStream",
+			Handler:       _Api_StatSummaryStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "public.proto",
 }
@@ -4043,160 +5574,216 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("public.proto", fileDescriptor_public_62da165bc60d64f8) }
 
 var fileDescriptor_public_62da165bc60d64f8 = []byte{
-	// 2477 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x39, 0x3b, 0x73, 0x1b, 0xc9,
-	0xd1, 0x78, 0x2c, 0x5e, 0x0d, 0x80, 0x84, 0x46, 0x3a, 0x7d, 0x10, 0xee, 0x4a, 0x47, 0xad, 0x74,
-	0x3a, 0x96, 0xee, 0x33, 0x48, 0x41, 0x27, 0x9d, 0x28, 0x9d, 0x1f, 0x04, 0x09, 0x0b, 0xb4, 0x29,
-	0x12, 0x1a, 0x40, 0xbe, 0x2a, 0xd5, 0x55, 0xa1, 0x16, 0xd8, 0x21, 0xb1, 0xe6, 0x62, 0x67, 0xb5,
-	0x3b, 0x10, 0x0f, 0xa9, 0x23, 0x07, 0x0e, 0xed, 0xd8, 0xb1, 0x9d, 0xb8, 0x9c, 0xf8, 0x47, 0xf8,
-	0x0f, 0x38, 0xb3, 0x33, 0xa7, 0x4e, 0x1c, 0xdb, 0xae, 0x79, 0x2d, 0x16, 0x04, 0xf8, 0x90, 0x9c,
-	0x38, 0xc2, 0x74, 0x4f, 0x77, 0x6f, 0x4f, 0xbf, 0x67, 0x00, 0x25, 0x7f, 0x32, 0x70, 0x9d, 0x61,
-	0xdd, 0x0f, 0x28, 0xa3, 0x68, 0xd5, 0x75, 0xbc, 0x13, 0x12, 0xd8, 0x8d, 0xba, 0x44, 0xd7, 0x6e,
-	0x1f, 0x53, 0x7a, 0xec, 0x92, 0x0d, 0xb1, 0x3d, 0x98, 0x1c, 0x6d, 0xd8, 0x93, 0xc0, 0x62, 0x0e,
-	0xf5, 0x24, 0x43, 0xad, 0x3a, 0xa4, 0xe3, 0x31, 0xf5, 0x36, 0x46, 0xc4, 0x72, 0xd9, 0x68, 0x38,
-	0x22, 0xc3, 0x13, 0xb9, 0x63, 0xe6, 0x20, 0xd3, 0x1a, 0xfb, 0x6c, 0x6a, 0xbe, 0x85, 0xe2, 0xcf,
-	0x48, 0x10, 0x3a, 0xd4, 0xdb, 0xf3, 0x8e, 0x28, 0xfa, 0x04, 0x0a, 0xc7, 0x54, 0x21, 0xaa, 0xc9,
-	0xb5, 0xe4, 0x7a, 0x01, 0xcf, 0x10, 0x7c, 0x77, 0x30, 0x71, 0x5c, 0x7b, 0xd7, 0x62, 0xa4, 0x9a,
-	0x92, 0xbb, 0x11, 0x02, 0xdd, 0x87, 0x95, 0x80, 0xb8, 0xc4, 0x0a, 0x89, 0x16, 0x90, 0x16, 0x24,
-	0x67, 0xb0, 0xe6, 0x06, 0xac, 0xee, 0x3b, 0x21, 0xeb, 0x50, 0x3b, 0xc4, 0xe4, 0xed, 0x84, 0x84,
-	0x8c, 0x0b, 0xf6, 0xac, 0x31, 0x09, 0x7d, 0x6b, 0x48, 0xf4, 0x67, 0x23, 0x84, 0xf9, 0x35, 0x54,
-	0x66, 0x0c, 0xa1, 0x4f, 0xbd, 0x90, 0xa0, 0x75, 0x30, 0x7c, 0x6a, 0x87, 0xd5, 0xe4, 0x5a, 0x7a,
-	0xbd, 0xd8, 0xb8, 0x51, 0x3f, 0x63, 0x9a, 0x7a, 0x87, 0xda, 0x58, 0x50, 0x98, 0xbf, 0x32, 0x20,
-	0xdd, 0xa1, 0x36, 0x42, 0x60, 0x70, 0x91, 0x4a, 0xbc, 0x58, 0xa3, 0x1b, 0x90, 0xf1, 0xa9, 0xbd,
-	0xd7, 0x51, 0x87, 0x91, 0x00, 0x5a, 0x03, 0xb0, 0x89, 0xef, 0xd2, 0xe9, 0x98, 0x78, 0x4c, 0x1e,
-	0xa2, 0x9d, 0xc0, 0x31, 0x1c, 0xba, 0x03, 0xc5, 0x80, 0xf8, 0xae, 0x33, 0xb4, 0xfa, 0x21, 0x61,
-	0x55, 0xd0, 0x24, 0x0a, 0xd9, 0x25, 0x0c, 0x7d, 0x05, 0x37, 0x15, 0xc4, 0x1d, 0xd2, 0x1f, 0x52,
-	0x8f, 0x05, 0xd4, 0x75, 0x49, 0x50, 0x2d, 0x2a, 0xea, 0x8f, 0x62, 0xfb, 0x3b, 0xd1, 0x36, 0xba,
-	0x0b, 0xa5, 0x90, 0x59, 0x8c, 0x1c, 0x4d, 0x5c, 0x21, 0xbc, 0xa4, 0xc8, 0x8b, 0x1a, 0xcb, 0xa5,
-	0x7f, 0x0a, 0x60, 0x5b, 0x64, 0x4c, 0x3d, 0x41, 0x52, 0x56, 0x24, 0x05, 0x89, 0xe3, 0x04, 0x08,
-	0xd2, 0x3f, 0xa7, 0x83, 0xea, 0x8a, 0xda, 0xe1, 0x00, 0xba, 0x09, 0x59, 0x2e, 0x63, 0x12, 0x56,
-	0x0d, 0x71, 0x5c, 0x05, 0x71, 0x2b, 0x58, 0xb6, 0x4d, 0xec, 0x6a, 0x66, 0x2d, 0xb9, 0x9e, 0xc7,
-	0x12, 0x40, 0x3b, 0xb0, 0x1a, 0x3a, 0xde, 0x90, 0xec, 0x5b, 0x21, 0xc3, 0xc4, 0xa7, 0x01, 0xab,
-	0x66, 0xd7, 0x92, 0xeb, 0xc5, 0xc6, 0xad, 0xba, 0x0c, 0xbb, 0xba, 0x0e, 0xbb, 0xfa, 0xae, 0x0a,
-	0x3b, 0x7c, 0x96, 0x03, 0x6d, 0xc2, 0xf5, 0xd9, 0xc9, 0x0f, 0x22, 0x17, 0xe7, 0xc4, 0xf7, 0x97,
-	0x6d, 0x21, 0x13, 0x4a, 0x0a, 0xdd, 0x71, 0x2d, 0x8f, 0x54, 0xf3, 0x42, 0xa7, 0x39, 0x1c, 0x7a,
-	0x08, 0xd9, 0x89, 0xcf, 0x9c, 0x31, 0xa9, 0x16, 0x2e, 0xd3, 0x48, 0x11, 0x36, 0x73, 0x90, 0xa1,
-	0xa7, 0x1e, 0x09, 0xcc, 0xdf, 0xa7, 0x00, 0x7a, 0x96, 0xaf, 0x23, 0x0f, 0x41, 0xda, 0xa7, 0xb6,
-	0x0c, 0x0a, 0x6e, 0x27, 0x9f, 0xda, 0x67, 0xfc, 0x9f, 0x5a, 0xe2, 0xff, 0x9b, 0x90, 0x1d, 0x5b,
-	0xdf, 0x61, 0x3f, 0x14, 0xd1, 0x91, 0xc2, 0x0a, 0xe2, 0x78, 0x46, 0x3b, 0xdc, 0x54, 0xdc, 0xc2,
-	0x65, 0xac, 0x20, 0x1e, 0x7b, 0x8c, 0xee, 0x75, 0x84, 0x81, 0x0b, 0x58, 0xac, 0x51, 0x0d, 0xf2,
-	0x47, 0x01, 0x1d, 0x77, 0xb4, 0x61, 0xcb, 0x38, 0x82, 0xb9, 0x1c, 0xbe, 0xde, 0xeb, 0x28, 0x4b,
-	0x29, 0x48, 0x78, 0x70, 0x38, 0x22, 0x63, 0x69, 0x16, 0xee, 0x41, 0x01, 0x09, 0x7d, 0x08, 0x1b,
-	0x51, 0x5b, 0x18, 0xa4, 0x80, 0x15, 0xc4, 0xf3, 0xca, 0x9a, 0xb0, 0x11, 0x0d, 0x1c, 0x36, 0x95,
-	0x51, 0x8a, 0x67, 0x08, 0xae, 0x95, 0x6f, 0xb1, 0x91, 0x0c, 0x48, 0x2c, 0xd6, 0xcf, 0x52, 0xd5,
-	0x64, 0x33, 0x0f, 0x59, 0x66, 0x05, 0xc7, 0x84, 0x99, 0x7f, 0xcf, 0xc0, 0x8d, 0x9e, 0xe5, 0x37,
-	0xa7, 0x98, 0x84, 0x74, 0x12, 0x0c, 0x89, 0x36, 0xdb, 0x33, 0x4d, 0x22, 0x2c, 0x57, 0x6c, 0x98,
-	0x0b, 0x09, 0xa8, 0x39, 0xba, 0xc4, 0x25, 0x43, 0xe9, 0x0a, 0xc9, 0x81, 0xb6, 0x21, 0x33, 0xb6,
-	0xd8, 0x70, 0x24, 0x2c, 0x5b, 0x6c, 0x7c, 0xb1, 0xc0, 0xba, 0xec, 0x8b, 0xf5, 0x97, 0x9c, 0x05,
-	0x4b, 0xce, 0xf3, 0xec, 0x5f, 0xfb, 0x93, 0x01, 0x19, 0x41, 0x88, 0x76, 0x20, 0x6d, 0xb9, 0xae,
-	0xd2, 0x6e, 0xe3, 0x3d, 0x3e, 0x51, 0xef, 0x92, 0xb7, 0x3c, 0x10, 0x2c, 0xd7, 0x15, 0x42, 0xbc,
-	0xa9, 0xd2, 0xf3, 0x83, 0x84, 0x78, 0x53, 0xf4, 0x43, 0x48, 0x7b, 0x54, 0x96, 0x91, 0xf7, 0x3b,
-	0x2c, 0x17, 0xe0, 0x51, 0x86, 0xda, 0x50, 0xb2, 0x49, 0xc8, 0x1c, 0x4f, 0x44, 0xb4, 0x4c, 0xde,
-	0x2b, 0x59, 0xbc, 0x9d, 0xc0, 0x73, 0x9c, 0xe8, 0xc7, 0x60, 0x8c, 0x18, 0xf3, 0x45, 0x18, 0x16,
-	0x1b, 0x9b, 0xef, 0x73, 0xa0, 0x36, 0x63, 0x7e, 0x3b, 0x81, 0x05, 0x7f, 0x6d, 0x1f, 0xd2, 0x5d,
-	0xf2, 0x16, 0xb5, 0x20, 0x27, 0xdc, 0x41, 0x74, 0x19, 0x7e, 0x2f, 0x57, 0x6a, 0xde, 0xda, 0x14,
-	0x0c, 0x2e, 0x1d, 0x55, 0xa3, 0xe0, 0xd6, 0xd9, 0xa8, 0xc3, 0xbb, 0x1a, 0x85, 0xb7, 0x4e, 0x46,
-	0x1d, 0xe0, 0xb7, 0xe3, 0x01, 0xae, 0x2b, 0x75, 0x2c, 0xc4, 0x6f, 0xa8, 0x10, 0x37, 0xd4, 0x96,
-	0x80, 0x78, 0x31, 0x10, 0x1f, 0x8f, 0x16, 0xe6, 0x3f, 0x93, 0x00, 0x5c, 0x89, 0x97, 0x52, 0x6c,
-	0x1b, 0x20, 0x20, 0xc7, 0x4e, 0xc8, 0x48, 0x40, 0x64, 0x71, 0x58, 0x69, 0xdc, 0x5f, 0x38, 0xdc,
-	0x8c, 0xa1, 0x8e, 0x23, 0x6a, 0xd9, 0x06, 0x34, 0x84, 0xee, 0x41, 0x69, 0xe2, 0xc5, 0x64, 0xe9,
-	0x03, 0xcc, 0x61, 0x4d, 0x0f, 0x60, 0x26, 0x01, 0xe5, 0x20, 0xfd, 0xa2, 0xd5, 0xab, 0x24, 0x50,
-	0x1e, 0x8c, 0xce, 0x61, 0xb7, 0x57, 0x49, 0x72, 0x54, 0xe7, 0x75, 0xaf, 0x92, 0x42, 0x00, 0xd9,
-	0xdd, 0xd6, 0x7e, 0xab, 0xd7, 0xaa, 0xa4, 0x51, 0x01, 0x32, 0x9d, 0xed, 0xde, 0x4e, 0xbb, 0x62,
-	0xa0, 0x22, 0xe4, 0x0e, 0x3b, 0xbd, 0xbd, 0xc3, 0x83, 0x6e, 0x25, 0xc3, 0x81, 0x9d, 0xc3, 0x83,
-	0x83, 0xd6, 0x4e, 0xaf, 0x92, 0xe5, 0x32, 0xda, 0xad, 0xed, 0xdd, 0x4a, 0x8e, 0x93, 0xf7, 0xf0,
-	0xf6, 0x4e, 0xab, 0x92, 0x6f, 0x66, 0xc1, 0x60, 0x53, 0x9f, 0x98, 0xbf, 0x4d, 0x42, 0xb6, 0x2b,
-	0x6d, 0xbc, 0xbb, 0xe4, 0xc8, 0x8b, 0x31, 0x26, 0x89, 0xff, 0xdb, 0xe3, 0xde, 0x99, 0x3b, 0x2e,
-	0xd7, 0xb0, 0xd7, 0xeb, 0x54, 0x12, 0x5c, 0x43, 0xbe, 0xea, 0x56, 0x92, 0x91, 0x86, 0x3d, 0x28,
-	0xec, 0x75, 0xb6, 0x6d, 0x3b, 0x20, 0x21, 0x6f, 0x54, 0x86, 0xe3, 0xbf, 0xfb, 0x52, 0x68, 0x97,
-	0xe3, 0xde, 0xe4, 0x10, 0xfa, 0x42, 0x60, 0x9f, 0xa8, 0x34, 0xfd, 0x68, 0x41, 0xe7, 0xbd, 0xce,
-	0xbb, 0x27, 0x8a, 0xf8, 0x49, 0xd3, 0x80, 0x94, 0xe3, 0x9b, 0x9b, 0x60, 0x70, 0x2c, 0xef, 0x7c,
-	0x47, 0x4e, 0x10, 0xca, 0x2a, 0x96, 0xc5, 0x12, 0xe0, 0x75, 0xd1, 0xb5, 0x42, 0x59, 0xf9, 0xb3,
-	0x58, 0xac, 0xcd, 0x7d, 0x80, 0xde, 0xd0, 0xd7, 0x8a, 0x3c, 0xe0, 0x52, 0x54, 0x71, 0xa9, 0x2d,
-	0xf9, 0xa0, 0xa2, 0xc3, 0x29, 0xc7, 0x17, 0x55, 0x96, 0xd7, 0xf8, 0x94, 0xa8, 0xf1, 0x62, 0x6d,
-	0xda, 0x90, 0x6e, 0x51, 0x2e, 0xa6, 0x72, 0x1c, 0xf8, 0xc3, 0xbe, 0xec, 0xc3, 0xfd, 0x21, 0xb5,
-	0x65, 0xec, 0x97, 0xdb, 0x09, 0xbc, 0xc2, 0x77, 0xba, 0x62, 0x63, 0x87, 0xda, 0x84, 0xd3, 0x06,
-	0x24, 0x24, 0xac, 0x4f, 0x82, 0x80, 0x06, 0x92, 0x36, 0xa5, 0x69, 0xc5, 0x4e, 0x8b, 0x6f, 0x70,
-	0xda, 0x66, 0x06, 0xd2, 0xc4, 0xb3, 0xcd, 0x7f, 0x97, 0x20, 0xdf, 0xb3, 0xfc, 0xd6, 0x3b, 0xde,
-	0xb2, 0x1e, 0x41, 0x56, 0x66, 0xa1, 0x52, 0xfb, 0xe3, 0xc5, 0x5c, 0x8d, 0xce, 0x87, 0x15, 0x29,
-	0x7a, 0x01, 0x45, 0xb9, 0xea, 0x8f, 0x09, 0xb3, 0x54, 0xdd, 0xb8, 0xbf, 0x2c, 0xcb, 0xc5, 0x47,
-	0xea, 0x2d, 0xcf, 0xf6, 0xa9, 0xe3, 0xb1, 0x97, 0x84, 0x59, 0x18, 0x24, 0x2b, 0x5f, 0xa3, 0xef,
-	0x43, 0x31, 0x56, 0x89, 0x94, 0xab, 0x2e, 0x54, 0x21, 0x4e, 0x8f, 0x5e, 0x41, 0x25, 0x06, 0x4a,
-	0x65, 0x8c, 0xf7, 0x52, 0x66, 0x35, 0xc6, 0x2f, 0x34, 0x7a, 0x05, 0xab, 0x7e, 0x40, 0xbf, 0x9b,
-	0xf6, 0x6d, 0x27, 0x90, 0xe5, 0x52, 0x74, 0xe1, 0x95, 0xc6, 0xfa, 0xf9, 0x12, 0x3b, 0x9c, 0x61,
-	0x57, 0xd3, 0xe3, 0x15, 0x7f, 0x0e, 0x46, 0x5f, 0xaa, 0xf2, 0x2a, 0x4b, 0xfd, 0xed, 0xf3, 0xe5,
-	0xcc, 0x15, 0xd3, 0xdf, 0x24, 0xa1, 0x14, 0x57, 0x15, 0xfd, 0x04, 0xb2, 0xae, 0x35, 0x20, 0xae,
-	0xae, 0xaa, 0x8d, 0xab, 0x1d, 0xb1, 0xbe, 0x2f, 0x98, 0x5a, 0x1e, 0x0b, 0xa6, 0x58, 0x49, 0xa8,
-	0x6d, 0x41, 0x31, 0x86, 0x46, 0x15, 0x48, 0x9f, 0x90, 0xa9, 0x1a, 0x81, 0xf9, 0x92, 0x67, 0xc0,
-	0x3b, 0xcb, 0x9d, 0xe8, 0x71, 0x5e, 0x02, 0xcf, 0x52, 0x4f, 0x93, 0xb5, 0x7f, 0xe5, 0x54, 0x5d,
-	0x3e, 0x84, 0x52, 0x20, 0x2b, 0x77, 0xdf, 0xf1, 0x1c, 0xdd, 0xf1, 0x1f, 0x5c, 0x7c, 0xbc, 0xba,
-	0x2a, 0xf6, 0x7b, 0x9e, 0xc3, 0xf8, 0xf0, 0x1a, 0xcc, 0x40, 0x84, 0xa1, 0x1c, 0xa8, 0x39, 0x5e,
-	0x4a, 0xbc, 0x60, 0x10, 0x98, 0x93, 0x28, 0x79, 0x94, 0xc8, 0x52, 0x10, 0x83, 0xa5, 0x92, 0x4a,
-	0x26, 0xf1, 0x6c, 0xe5, 0x83, 0x07, 0x57, 0x14, 0xd9, 0xf2, 0x6c, 0xa9, 0x64, 0x04, 0xd6, 0x9e,
-	0x40, 0xbe, 0xcb, 0x02, 0x62, 0x8d, 0xf7, 0xc4, 0xd5, 0x61, 0x60, 0x85, 0x2a, 0x37, 0xb1, 0x58,
-	0xcb, 0x61, 0x9a, 0xef, 0x0b, 0xed, 0x0d, 0xac, 0xa0, 0xda, 0x5f, 0x93, 0x50, 0x8c, 0x9d, 0x1d,
-	0x7d, 0x05, 0x29, 0xc7, 0x56, 0x36, 0xfb, 0xfc, 0x12, 0x75, 0xf4, 0x07, 0x71, 0xca, 0xb1, 0x79,
-	0xc2, 0xc6, 0x9a, 0xde, 0xb2, 0x6c, 0x99, 0xf5, 0x9f, 0xa8, 0x1f, 0x6e, 0x44, 0x3d, 0x54, 0x1a,
-	0xe0, 0xff, 0xce, 0xa9, 0xe0, 0x51, 0x6b, 0x9d, 0x9b, 0x10, 0x8d, 0xf3, 0x26, 0xc4, 0xcc, 0x6c,
-	0x42, 0xac, 0xfd, 0x31, 0x09, 0xa5, 0xb8, 0x2b, 0x3e, 0xfc, 0x84, 0x2f, 0x00, 0x89, 0xfb, 0x42,
-	0x7f, 0x2e, 0xbc, 0x52, 0x97, 0x8d, 0xf4, 0x15, 0xc1, 0x14, 0xb7, 0xf1, 0xa7, 0x50, 0xe4, 0xa9,
-	0xa4, 0xea, 0xa8, 0x38, 0x7a, 0x19, 0x03, 0x47, 0xc9, 0x02, 0x5a, 0xfb, 0x5d, 0x8a, 0x3b, 0x25,
-	0x72, 0xee, 0xff, 0x80, 0xca, 0x7b, 0x70, 0x5d, 0x0b, 0x8a, 0x67, 0x42, 0xfa, 0x32, 0x49, 0xd7,
-	0x94, 0xa4, 0x98, 0xfd, 0x3f, 0xe3, 0xf7, 0x6e, 0x25, 0x64, 0x30, 0x65, 0x44, 0x4e, 0x88, 0x06,
-	0x8e, 0x92, 0xac, 0xc9, 0x91, 0xe8, 0x3e, 0xa4, 0x09, 0x0d, 0x55, 0x0d, 0x5f, 0xbc, 0x30, 0xb7,
-	0x68, 0x88, 0x39, 0x01, 0x9f, 0x89, 0x08, 0x3f, 0xbd, 0xf9, 0x14, 0x56, 0xe6, 0x0b, 0x1e, 0x1f,
-	0x2c, 0x5e, 0x1f, 0xfc, 0xf4, 0xe0, 0xf0, 0x9b, 0x83, 0x4a, 0x82, 0x03, 0x7b, 0x07, 0xcd, 0xc3,
-	0xd7, 0x07, 0xbb, 0x95, 0x24, 0x2a, 0x41, 0xfe, 0xf0, 0x75, 0x4f, 0x42, 0xa9, 0x99, 0x88, 0x35,
-	0xc8, 0x6f, 0xfb, 0x8e, 0x68, 0x4c, 0xbc, 0xd2, 0x88, 0xd6, 0xa5, 0xaa, 0x8f, 0x04, 0xf8, 0x75,
-	0xac, 0xd0, 0xa1, 0xb6, 0x20, 0x09, 0xd1, 0x73, 0xc8, 0x0a, 0xb4, 0x2e, 0x7d, 0x77, 0x97, 0xdd,
-	0xeb, 0x25, 0x6d, 0xb4, 0xc2, 0x8a, 0xa5, 0xf6, 0xb7, 0x24, 0xe4, 0x35, 0x12, 0x61, 0x28, 0xf0,
-	0x2b, 0xa3, 0xe5, 0x78, 0x24, 0x50, 0x8e, 0x6e, 0x5c, 0x41, 0x58, 0x7d, 0x47, 0x33, 0x09, 0x90,
-	0x0f, 0x93, 0x91, 0x98, 0xda, 0x3b, 0x58, 0x99, 0xdf, 0x46, 0x55, 0xc8, 0x8d, 0x49, 0x18, 0x5a,
-	0xc7, 0xfa, 0x59, 0x41, 0x83, 0x3c, 0xaf, 0x66, 0xdf, 0x57, 0x4f, 0x25, 0x11, 0x82, 0xdb, 0xc2,
-	0x19, 0x73, 0x2e, 0xf9, 0x42, 0x22, 0x01, 0x5e, 0x52, 0x02, 0x62, 0x85, 0xd4, 0xd3, 0xf7, 0x73,
-	0x09, 0x09, 0x73, 0x0a, 0x63, 0x75, 0x20, 0xaf, 0x67, 0xe9, 0x8b, 0x9f, 0x4c, 0xc4, 0x85, 0x73,
-	0xea, 0xeb, 0xaa, 0x2e, 0xd6, 0xd1, 0x03, 0x48, 0x7a, 0xf6, 0x00, 0x62, 0xbe, 0x85, 0x6b, 0x0b,
-	0xd7, 0x06, 0xf4, 0x18, 0xf2, 0x01, 0x99, 0x1b, 0x16, 0x6e, 0x9d, 0x7b, 0xd9, 0xc0, 0x11, 0x29,
-	0x8f, 0x43, 0xd1, 0x75, 0xfa, 0xa1, 0x90, 0x44, 0xf5, 0xb9, 0xcb, 0x02, 0xdb, 0x55, 0x48, 0xf3,
-	0x5b, 0x28, 0x6b, 0x66, 0x69, 0xc4, 0x0f, 0xfc, 0x5c, 0x14, 0x4f, 0xa9, 0x78, 0x3c, 0xfd, 0x21,
-	0x05, 0x88, 0x27, 0x7d, 0x77, 0x32, 0x1e, 0x5b, 0xc1, 0x54, 0xdf, 0x57, 0x7f, 0x00, 0xf9, 0x48,
-	0xab, 0xab, 0xdf, 0x58, 0x23, 0x1e, 0x5e, 0x61, 0x98, 0x33, 0x26, 0xfd, 0x53, 0xc7, 0xb3, 0xe9,
-	0xa9, 0xfa, 0x24, 0x70, 0xd4, 0x37, 0x02, 0x83, 0xfe, 0x1f, 0x0c, 0x8f, 0x7a, 0xba, 0xec, 0xde,
-	0x5c, 0x4c, 0xaf, 0xb1, 0xcf, 0xa6, 0xbc, 0xe7, 0x73, 0x2a, 0xf4, 0x35, 0x14, 0x19, 0xed, 0x47,
-	0xa7, 0x36, 0x2e, 0x39, 0x35, 0x1f, 0xb2, 0x19, 0x8d, 0x5c, 0xff, 0x23, 0x28, 0x1f, 0x05, 0x74,
-	0x3c, 0xe3, 0xcf, 0x5c, 0xce, 0x5f, 0xe2, 0x1c, 0x1a, 0x6e, 0x02, 0xe4, 0xe9, 0x84, 0x0d, 0xe8,
-	0xc4, 0xb3, 0xcd, 0xbf, 0x24, 0xe1, 0xfa, 0x9c, 0xc5, 0xd4, 0x0b, 0xdb, 0x16, 0xa4, 0xe8, 0xc9,
-	0xb9, 0x35, 0x72, 0x09, 0x47, 0xfd, 0xf0, 0xa4, 0x9d, 0xc0, 0x29, 0x7a, 0x82, 0x9e, 0xc4, 0x5d,
-	0xb3, 0x6c, 0x12, 0x9a, 0x0b, 0x80, 0x76, 0x42, 0x39, 0xaf, 0xb6, 0x0d, 0xa9, 0xc3, 0x13, 0xf4,
-	0x1c, 0xc4, 0x53, 0x57, 0x9f, 0x59, 0x03, 0x37, 0xba, 0x5a, 0xd6, 0x96, 0x6a, 0xd0, 0xe3, 0x24,
-	0x18, 0x42, 0xbd, 0x0c, 0xf9, 0xc9, 0x74, 0xd9, 0x13, 0x97, 0xba, 0xa6, 0x15, 0x3a, 0x62, 0x8c,
-	0x0e, 0xd1, 0x5d, 0x28, 0x87, 0x93, 0xe1, 0x90, 0x84, 0x7c, 0xd2, 0x9e, 0x78, 0x72, 0x90, 0x31,
-	0x70, 0x49, 0x21, 0x77, 0x38, 0x8e, 0x13, 0x1d, 0x59, 0x8e, 0x3b, 0x09, 0x88, 0x22, 0x92, 0xdd,
-	0xbd, 0xa4, 0x90, 0x92, 0xe8, 0x1e, 0x8f, 0x74, 0x46, 0xbc, 0xe1, 0xb4, 0x3f, 0x0e, 0xfb, 0xfe,
-	0xe3, 0x4d, 0xe1, 0x76, 0x03, 0x97, 0x14, 0xf6, 0x65, 0xd8, 0x79, 0xbc, 0x79, 0x96, 0x6a, 0xeb,
-	0xb1, 0xaa, 0xcb, 0x31, 0xaa, 0xad, 0xc7, 0x0b, 0x54, 0x5b, 0xc2, 0x9b, 0xf3, 0x54, 0x5b, 0xe8,
-	0x01, 0x5c, 0x63, 0x6e, 0x18, 0x75, 0x1d, 0xa9, 0x5a, 0x56, 0x10, 0xae, 0x32, 0x57, 0xbf, 0xa3,
-	0x0a, 0xed, 0xcc, 0x7f, 0x18, 0x50, 0x88, 0x8c, 0x83, 0x9a, 0x50, 0xf0, 0xa9, 0xdd, 0x3f, 0x0e,
-	0xe8, 0x44, 0xdf, 0x58, 0xee, 0x9e, 0x6f, 0x4b, 0x5e, 0x08, 0x5f, 0x70, 0xd2, 0x76, 0x02, 0xe7,
-	0x7d, 0xb5, 0xae, 0xfd, 0xda, 0x10, 0x95, 0x55, 0x00, 0xe8, 0x39, 0x18, 0x01, 0x3d, 0xd5, 0x7e,
-	0xf9, 0xfc, 0x0a, 0xb2, 0xea, 0x98, 0x9e, 0x62, 0xc1, 0x54, 0xfb, 0x73, 0x1a, 0xd2, 0x98, 0x9e,
-	0x7e, 0x68, 0xce, 0x5f, 0x9a, 0x86, 0xeb, 0x50, 0x19, 0x93, 0x70, 0x44, 0xec, 0x3e, 0x3f, 0xb4,
-	0x34, 0x93, 0xf4, 0xcd, 0x8a, 0xc4, 0x77, 0xa8, 0x2d, 0x7d, 0xf8, 0x00, 0xae, 0x05, 0x13, 0xcf,
-	0x73, 0xbc, 0xe3, 0x18, 0xa9, 0x74, 0xd0, 0xaa, 0xda, 0x88, 0x68, 0xd7, 0xa1, 0xc2, 0xfd, 0x3f,
-	0x27, 0x55, 0x1a, 0x7f, 0x45, 0xe2, 0x23, 0xca, 0x87, 0x90, 0xe1, 0xc1, 0xa8, 0xdb, 0xec, 0xe2,
-	0xcc, 0x36, 0x8b, 0x47, 0x2c, 0x29, 0xd1, 0xb7, 0x50, 0x96, 0x0d, 0xac, 0x3f, 0x98, 0x72, 0xf9,
-	0xd5, 0x9c, 0x30, 0xec, 0xd3, 0x2b, 0x1a, 0xb6, 0x2e, 0x3b, 0x58, 0x73, 0xca, 0x5b, 0x98, 0x98,
-	0xfd, 0x8b, 0x64, 0x86, 0xa9, 0xbd, 0x81, 0xca, 0x59, 0x82, 0x25, 0xb7, 0x80, 0xcd, 0xf8, 0x2d,
-	0x60, 0x59, 0xb2, 0x45, 0x9d, 0x32, 0x76, 0x43, 0xe0, 0x7d, 0x49, 0xe4, 0x68, 0xe3, 0x17, 0x06,
-	0xa4, 0xb7, 0x7d, 0x07, 0xbd, 0x81, 0x62, 0xac, 0x2e, 0xa0, 0xbb, 0x17, 0x57, 0x0d, 0x11, 0xb2,
-	0xb5, 0x7b, 0x57, 0x29, 0x2d, 0x66, 0x02, 0xbd, 0x82, 0xbc, 0xfe, 0x13, 0x00, 0xad, 0x2d, 0xf0,
-	0x9c, 0xf9, 0x43, 0xa1, 0x76, 0xe7, 0x02, 0x8a, 0x48, 0xe4, 0x2e, 0xa4, 0x7b, 0x96, 0x8f, 0x3e,
-	0x5e, 0x36, 0x00, 0x6a, 0x41, 0xb7, 0xce, 0x9d, 0x0e, 0xcd, 0xf4, 0x2f, 0x53, 0xc9, 0xcd, 0x24,
-	0x7a, 0x0d, 0xe5, 0xb9, 0x57, 0x2e, 0xf4, 0xd9, 0x95, 0x5e, 0xc1, 0x2e, 0x92, 0x9c, 0xd8, 0x4c,
-	0xa2, 0x6d, 0xc8, 0xe9, 0xbf, 0x5d, 0xce, 0xe9, 0x26, 0xb5, 0x4f, 0x16, 0xf0, 0xb1, 0xbf, 0x72,
-	0xcc, 0x04, 0x72, 0xa1, 0xd0, 0x25, 0xee, 0xd1, 0xce, 0x88, 0x0c, 0x4f, 0xd0, 0xf7, 0x66, 0xc4,
-	0xf2, 0x5f, 0xa1, 0x7a, 0xfc, 0x5f, 0xa1, 0x88, 0x4e, 0x6b, 0x57, 0xbf, 0x2a, 0xb9, 0xb6, 0x66,
-	0xf3, 0xd1, 0x9b, 0x87, 0xc7, 0x0e, 0x1b, 0x4d, 0x06, 0x9c, 0x61, 0x43, 0x71, 0xeb, 0xdf, 0xc6,
-	0xc6, 0xec, 0xad, 0x7f, 0xe3, 0x98, 0x78, 0x1b, 0x52, 0xe1, 0x41, 0x56, 0x4c, 0xb8, 0x8f, 0xfe,
-	0x13, 0x00, 0x00, 0xff, 0xff, 0xff, 0x14, 0xb8, 0x67, 0xe9, 0x1a, 0x00, 0x00,
+	// 3362 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x3a, 0x4d, 0x6f, 0x1b, 0xc9,
+	0x95, 0x6a, 0xb2, 0xf9, 0xf5, 0x48, 0x4a, 0x74, 0xd9, 0xe3, 0xa1, 0x39, 0xb3, 0x1e, 0xbb, 0xfd,
+	0x31, 0x5a, 0x7b, 0x97, 0x92, 0xe5, 0x8f, 0x19, 0x8d, 0x3d, 0xb3, 0x2b, 0x51, 0x1c, 0x8b, 0xbb,
+	0xb2, 0xc4, 0x69, 0x52, 0x33, 0xc0, 0x60, 0x16, 0x44, 0x8b, 0x5d, 0xa2, 0x7a, 0xd5, 0xec, 0x6a,
+	0x77, 0x17, 0x65, 0x73, 0x8f, 0x8b, 0x1c, 0x72, 0x98, 0x5c, 0x82, 0x24, 0xd7, 0xc9, 0x39, 0x97,
+	0x20, 0x40, 0x6e, 0xb9, 0x05, 0x39, 0xe5, 0x16, 0x20, 0x40, 0x2e, 0x41, 0x72, 0x0e, 0x10, 0x20,
+	0x97, 0xfc, 0x80, 0xa0, 0xbe, 0x9a, 0xcd, 0x0f, 0x49, 0x94, 0x27, 0x08, 0x72, 0x62, 0xbd, 0x57,
+	0xef, 0xbd, 0x7a, 0xf5, 0xaa, 0xde, 0x57, 0x35, 0xa1, 0xe0, 0x0f, 0x0e, 0x5c, 0xa7, 0x5b, 0xf5,
+	0x03, 0x42, 0x09, 0x5a, 0x72, 0x1d, 0xef, 0x18, 0x07, 0xf6, 0x5a, 0x55, 0xa0, 0x2b, 0xd7, 0x7b,
+	0x84, 0xf4, 0x5c, 0xbc, 0xc2, 0xa7, 0x0f, 0x06, 0x87, 0x2b, 0xf6, 0x20, 0xb0, 0xa8, 0x43, 0x3c,
+	0xc1, 0x50, 0x29, 0x77, 0x49, 0xbf, 0x4f, 0xbc, 0x95, 0x23, 0x6c, 0xb9, 0xf4, 0xa8, 0x7b, 0x84,
+	0xbb, 0xc7, 0x62, 0xc6, 0xc8, 0x40, 0xaa, 0xde, 0xf7, 0xe9, 0xd0, 0xf8, 0xb9, 0x06, 0xf9, 0xcf,
+	0x71, 0x10, 0x3a, 0xc4, 0x6b, 0x78, 0x87, 0x04, 0xbd, 0x0b, 0xb9, 0x1e, 0x91, 0x88, 0xb2, 0x76,
+	0x43, 0x5b, 0xce, 0x99, 0x23, 0x04, 0x9b, 0x3d, 0x18, 0x38, 0xae, 0xbd, 0x65, 0x51, 0x5c, 0x4e,
+	0x88, 0xd9, 0x08, 0x81, 0xee, 0xc2, 0x62, 0x80, 0x5d, 0x6c, 0x85, 0x58, 0x09, 0x48, 0x72, 0x92,
+	0x09, 0x2c, 0xda, 0x00, 0xe8, 0x92, 0xbe, 0x4f, 0x3c, 0xec, 0xd1, 0xb0, 0xac, 0xdf, 0x48, 0x2e,
+	0xe7, 0xd7, 0x6e, 0x56, 0x27, 0x36, 0x57, 0xad, 0x29, 0x12, 0xc9, 0x66, 0xc6, 0x98, 0x8c, 0x5f,
+	0x69, 0xb0, 0xb4, 0xe3, 0x84, 0xb4, 0x49, 0xec, 0xd0, 0xc4, 0x2f, 0x07, 0x38, 0xa4, 0x4c, 0x39,
+	0xcf, 0xea, 0xe3, 0xd0, 0xb7, 0xba, 0x58, 0xa9, 0x1e, 0x21, 0xd0, 0x15, 0x48, 0xb9, 0x4e, 0xdf,
+	0xa1, 0x5c, 0xed, 0xa2, 0x29, 0x00, 0x74, 0x07, 0x16, 0xbb, 0xc4, 0xa3, 0x8e, 0x37, 0xc0, 0x1d,
+	0x4a, 0x8e, 0xb1, 0x52, 0xb9, 0xa8, 0xb0, 0x6d, 0x86, 0x44, 0x2b, 0x90, 0x22, 0xaf, 0x3c, 0x1c,
+	0x94, 0xf5, 0x1b, 0xda, 0x72, 0x7e, 0xed, 0xda, 0x94, 0xb2, 0x26, 0x0e, 0xc9, 0x20, 0xe8, 0x62,
+	0x53, 0xd0, 0x31, 0xb9, 0xf8, 0x75, 0xd7, 0x1d, 0xd8, 0xb8, 0x13, 0x52, 0x8b, 0x0e, 0xc2, 0x72,
+	0xea, 0x86, 0xb6, 0x9c, 0x35, 0x8b, 0x12, 0xdb, 0xe2, 0x48, 0xa3, 0x0b, 0xa5, 0xd1, 0x2e, 0x42,
+	0x9f, 0x78, 0x21, 0x46, 0xcb, 0xa0, 0xfb, 0xc4, 0x0e, 0xcb, 0x1a, 0xb7, 0xcb, 0x95, 0xa9, 0xa5,
+	0x9a, 0xc4, 0x36, 0x39, 0xc5, 0x0c, 0xe5, 0x13, 0x33, 0x94, 0x37, 0xbe, 0xd6, 0x21, 0xd9, 0x24,
+	0x36, 0x42, 0xa0, 0x33, 0x73, 0x48, 0xd3, 0xf0, 0x31, 0xb3, 0x8a, 0x4f, 0xec, 0x46, 0x53, 0x72,
+	0x0a, 0x00, 0xdd, 0x00, 0xb0, 0xb1, 0xef, 0x92, 0x61, 0x1f, 0x7b, 0x54, 0x58, 0x64, 0x7b, 0xc1,
+	0x8c, 0xe1, 0xd0, 0x4d, 0xc8, 0x07, 0xd8, 0x77, 0x9d, 0xae, 0xd5, 0x09, 0x31, 0x2d, 0x83, 0x22,
+	0x91, 0xc8, 0x16, 0xa6, 0xe8, 0x03, 0xb8, 0x2a, 0x21, 0x76, 0x23, 0x3b, 0x4c, 0xa7, 0x80, 0xb8,
+	0x2e, 0x0e, 0xca, 0x79, 0x49, 0xfd, 0x56, 0x6c, 0xbe, 0x16, 0x4d, 0xa3, 0x5b, 0x50, 0x60, 0x36,
+	0xc3, 0x87, 0x03, 0x97, 0x0b, 0x2f, 0x48, 0xf2, 0xbc, 0xc2, 0x32, 0xe9, 0xef, 0x01, 0xd8, 0x16,
+	0xee, 0x13, 0x8f, 0x93, 0x14, 0x25, 0x49, 0x4e, 0xe0, 0x18, 0x01, 0x82, 0xe4, 0xff, 0x92, 0x83,
+	0xf2, 0xa2, 0x9c, 0x61, 0x00, 0xba, 0x0a, 0x69, 0x79, 0x1a, 0x3a, 0xdf, 0xae, 0x84, 0x98, 0x15,
+	0x2c, 0xdb, 0xc6, 0xb6, 0x3c, 0x24, 0x01, 0xa0, 0x1a, 0x2c, 0x85, 0x8e, 0xd7, 0xc5, 0x3b, 0x56,
+	0x48, 0x4d, 0xec, 0x93, 0x80, 0x96, 0xd3, 0xf2, 0xf8, 0x85, 0xdf, 0x55, 0x95, 0xdf, 0x55, 0xb7,
+	0xa4, 0xdf, 0x99, 0x93, 0x1c, 0x68, 0x15, 0x2e, 0x8f, 0x76, 0xbe, 0x1b, 0x5d, 0xcf, 0x0c, 0x5f,
+	0x7f, 0xd6, 0x14, 0x32, 0xa0, 0x20, 0xd1, 0x4d, 0xd7, 0xf2, 0x70, 0x39, 0xcb, 0x75, 0x1a, 0xc3,
+	0xa1, 0x07, 0x90, 0x1e, 0xf8, 0xd4, 0xe9, 0xe3, 0x72, 0xee, 0x3c, 0x8d, 0x24, 0xe1, 0x66, 0x46,
+	0x5e, 0x61, 0xe3, 0x27, 0x09, 0x80, 0xb6, 0xe5, 0x2b, 0xaf, 0x41, 0x90, 0xf4, 0x89, 0x5d, 0xd6,
+	0x94, 0x9d, 0x7c, 0x62, 0x4f, 0x9c, 0x7f, 0x62, 0xc6, 0xf9, 0x5f, 0x85, 0x74, 0xdf, 0x7a, 0x6d,
+	0xfa, 0x21, 0xbf, 0x1d, 0x09, 0x53, 0x42, 0x0c, 0x4f, 0x49, 0x93, 0x99, 0x4a, 0xe7, 0x6e, 0x26,
+	0x21, 0x76, 0xf7, 0x28, 0x69, 0x34, 0xb9, 0x81, 0x73, 0x26, 0x1f, 0xa3, 0x0a, 0x64, 0x0f, 0x03,
+	0xd2, 0x6f, 0x2a, 0xc3, 0x16, 0xcd, 0x08, 0x66, 0x72, 0xd8, 0xb8, 0xd1, 0x94, 0x96, 0x92, 0x10,
+	0x3f, 0xc1, 0xee, 0x11, 0xee, 0x0b, 0xb3, 0xe4, 0x4c, 0x09, 0x71, 0x7d, 0x30, 0x3d, 0x22, 0x36,
+	0x37, 0x48, 0xce, 0x94, 0x10, 0x8b, 0x09, 0xd6, 0x80, 0x1e, 0x91, 0xc0, 0xa1, 0x43, 0x71, 0x4b,
+	0xcd, 0x11, 0x82, 0x69, 0xe5, 0x5b, 0xf4, 0x48, 0x5c, 0x48, 0x93, 0x8f, 0x3f, 0x4a, 0x94, 0xb5,
+	0xcd, 0x2c, 0xa4, 0xa9, 0x15, 0xf4, 0x30, 0x35, 0xfe, 0x94, 0x81, 0x2b, 0x6d, 0xcb, 0xdf, 0x1c,
+	0x46, 0x0e, 0x2e, 0xcd, 0xf6, 0x91, 0x22, 0xe1, 0x96, 0xcb, 0xaf, 0x19, 0xa7, 0x86, 0x84, 0x16,
+	0x76, 0x71, 0x57, 0x1c, 0x85, 0xe0, 0x40, 0x1b, 0x90, 0xea, 0x5b, 0xb4, 0x7b, 0xc4, 0x2d, 0x9b,
+	0x5f, 0xbb, 0x3f, 0xc5, 0x3a, 0x6b, 0xc5, 0xea, 0x0b, 0xc6, 0x62, 0x0a, 0xce, 0x53, 0xed, 0xff,
+	0x18, 0xb2, 0x2a, 0x07, 0x94, 0xf5, 0xf3, 0xae, 0x46, 0x44, 0x5a, 0xf9, 0xff, 0x34, 0xa4, 0xb8,
+	0x7c, 0x54, 0x83, 0xa4, 0xe5, 0xba, 0x72, 0x53, 0x2b, 0x17, 0xd0, 0xac, 0xda, 0xc2, 0x2f, 0xd9,
+	0xfd, 0xb1, 0x5c, 0x97, 0x0b, 0xf1, 0x86, 0xe5, 0xc4, 0x9b, 0x0b, 0xf1, 0x86, 0xe8, 0x3f, 0x20,
+	0xe9, 0x11, 0x11, 0x7d, 0x2e, 0x66, 0x23, 0x26, 0xc0, 0x23, 0x14, 0x6d, 0x43, 0xc1, 0xc6, 0x21,
+	0x75, 0x3c, 0xbe, 0xc7, 0xb0, 0xac, 0xcf, 0x7b, 0x50, 0xdb, 0x0b, 0xe6, 0x18, 0x27, 0xfa, 0x14,
+	0xf4, 0x23, 0x4a, 0x7d, 0x7e, 0x7b, 0xf3, 0x6b, 0xab, 0x17, 0xd9, 0xd0, 0x36, 0xa5, 0xfe, 0xf6,
+	0x82, 0xc9, 0xf9, 0xd1, 0x27, 0x90, 0x11, 0x34, 0x61, 0x39, 0x7d, 0x01, 0x65, 0x14, 0x53, 0x65,
+	0x07, 0x92, 0x2d, 0xfc, 0x12, 0xd5, 0x21, 0xc3, 0x6f, 0x01, 0x56, 0x49, 0xe2, 0x42, 0x37, 0x48,
+	0xf1, 0x56, 0xbe, 0x93, 0x00, 0x9d, 0xa9, 0x87, 0xca, 0x91, 0x53, 0xa9, 0x28, 0x20, 0x61, 0x36,
+	0x23, 0xdd, 0x4a, 0x05, 0x01, 0x09, 0xa3, 0xeb, 0x71, 0xc7, 0x52, 0x19, 0x62, 0x84, 0x42, 0x57,
+	0xa4, 0x6b, 0xe9, 0x72, 0x8a, 0x43, 0xe8, 0xf3, 0x28, 0x00, 0x0b, 0x53, 0x3e, 0xbb, 0xa8, 0x29,
+	0xab, 0x22, 0x71, 0x9a, 0x96, 0xd7, 0xc3, 0x5c, 0x4f, 0x0e, 0x56, 0x1e, 0x40, 0x3e, 0x36, 0x81,
+	0x4a, 0x90, 0xec, 0x3b, 0xa2, 0x7c, 0x29, 0x9a, 0x6c, 0xc8, 0x31, 0xd6, 0x6b, 0x99, 0xfb, 0xd9,
+	0x90, 0xc5, 0x43, 0x6e, 0x88, 0x68, 0x60, 0xfc, 0x55, 0x03, 0x60, 0x6b, 0xbc, 0x10, 0x3b, 0xdc,
+	0x06, 0x08, 0x70, 0xcf, 0x09, 0x29, 0x0e, 0xb0, 0x88, 0x8f, 0x8b, 0x6b, 0x77, 0xa7, 0xf4, 0x1d,
+	0x31, 0x54, 0xcd, 0x88, 0x5a, 0x64, 0x42, 0x05, 0xa1, 0xdb, 0x50, 0x18, 0x78, 0x31, 0x59, 0xca,
+	0x96, 0x63, 0x58, 0xc3, 0x03, 0x18, 0x49, 0x40, 0x19, 0x48, 0x3e, 0xaf, 0xb7, 0x4b, 0x0b, 0x28,
+	0x0b, 0x7a, 0x73, 0xaf, 0xd5, 0x2e, 0x69, 0x0c, 0xd5, 0xdc, 0x6f, 0x97, 0x12, 0x08, 0x20, 0xbd,
+	0x55, 0xdf, 0xa9, 0xb7, 0xeb, 0xa5, 0x24, 0xca, 0x41, 0xaa, 0xb9, 0xd1, 0xae, 0x6d, 0x97, 0x74,
+	0x94, 0x87, 0xcc, 0x5e, 0xb3, 0xdd, 0xd8, 0xdb, 0x6d, 0x95, 0x52, 0x0c, 0xa8, 0xed, 0xed, 0xee,
+	0xd6, 0x6b, 0xed, 0x52, 0x9a, 0xc9, 0xd8, 0xae, 0x6f, 0x6c, 0x95, 0x32, 0x8c, 0xbc, 0x6d, 0x6e,
+	0xd4, 0xea, 0xa5, 0xec, 0x66, 0x1a, 0x74, 0x3a, 0xf4, 0xb1, 0xf1, 0x8d, 0x06, 0xe9, 0x96, 0x38,
+	0xee, 0xad, 0x19, 0x5b, 0x9e, 0xbe, 0xa2, 0x82, 0xf8, 0xdb, 0x6e, 0xf7, 0xe6, 0xd8, 0x76, 0x99,
+	0x86, 0xed, 0x76, 0xb3, 0xb4, 0xc0, 0x34, 0x64, 0xa3, 0x56, 0x49, 0x8b, 0x34, 0x6c, 0x43, 0xae,
+	0xd1, 0xdc, 0xb0, 0xed, 0x00, 0x87, 0x2c, 0x57, 0xeb, 0x8e, 0x7f, 0xf2, 0x88, 0x6b, 0x97, 0x61,
+	0x17, 0x8b, 0x41, 0xe8, 0x3e, 0xc7, 0x3e, 0x91, 0x21, 0xe7, 0xad, 0x29, 0x9d, 0x1b, 0xcd, 0x93,
+	0x27, 0x92, 0xf8, 0xc9, 0xa6, 0x0e, 0x09, 0xc7, 0x37, 0x56, 0x41, 0x67, 0x58, 0x96, 0xfc, 0x0f,
+	0x9d, 0x20, 0x14, 0x81, 0x3c, 0x6d, 0x0a, 0x80, 0xa5, 0x06, 0xd7, 0x0a, 0x45, 0xf2, 0x4b, 0x9b,
+	0x7c, 0x6c, 0xec, 0x00, 0xb4, 0xbb, 0xbe, 0x52, 0xe4, 0x1e, 0x93, 0x22, 0x03, 0x65, 0x65, 0xc6,
+	0x82, 0x92, 0xce, 0x4c, 0x38, 0x3e, 0x4f, 0x34, 0x24, 0x10, 0xd2, 0x8a, 0x26, 0x1f, 0x1b, 0x36,
+	0x24, 0xeb, 0x84, 0x89, 0x29, 0xf5, 0x02, 0xbf, 0x2b, 0xcb, 0xc4, 0x4e, 0x97, 0xd8, 0xc2, 0x0d,
+	0x8b, 0xdb, 0x0b, 0xe6, 0x22, 0x9b, 0x11, 0x17, 0xbb, 0x46, 0x6c, 0xcc, 0x68, 0x03, 0x1c, 0x62,
+	0xda, 0xc1, 0x41, 0x40, 0x02, 0x41, 0x9b, 0x50, 0xb4, 0x7c, 0xa6, 0xce, 0x26, 0x18, 0xed, 0x66,
+	0x0a, 0x92, 0xd8, 0xb3, 0x8d, 0x1f, 0x2f, 0x42, 0xb6, 0x6d, 0xf9, 0xf5, 0x13, 0x96, 0xb5, 0x1f,
+	0x42, 0x5a, 0x38, 0x96, 0x54, 0xfb, 0x9d, 0x69, 0xf7, 0x8b, 0xf6, 0x67, 0x4a, 0x52, 0xf4, 0x1c,
+	0xf2, 0x62, 0xd4, 0xe9, 0x63, 0x6a, 0x49, 0xc7, 0xbd, 0x3b, 0xcb, 0x71, 0xf9, 0x22, 0xd5, 0xba,
+	0x67, 0xfb, 0xc4, 0xf1, 0xe8, 0x0b, 0x4c, 0x2d, 0x13, 0x04, 0x2b, 0x1b, 0xa3, 0x8f, 0x21, 0x1f,
+	0x8b, 0xaa, 0xe5, 0xc4, 0xf9, 0x2a, 0xc4, 0xe9, 0xd1, 0x67, 0x50, 0x8a, 0x81, 0x42, 0x19, 0xfd,
+	0x42, 0xca, 0x2c, 0xc5, 0xf8, 0xb9, 0x46, 0x9f, 0xc1, 0x92, 0x1f, 0x90, 0xd7, 0xc3, 0x8e, 0xed,
+	0x04, 0x22, 0xda, 0xf2, 0xb8, 0xbc, 0xb8, 0xb6, 0x7c, 0xba, 0xc4, 0x26, 0x63, 0xd8, 0x52, 0xf4,
+	0xe6, 0xa2, 0x3f, 0x06, 0xa3, 0x47, 0x32, 0x55, 0x88, 0xb4, 0x75, 0xfd, 0x74, 0x39, 0xf1, 0xc4,
+	0x50, 0xf9, 0xa1, 0x06, 0x85, 0xb8, 0xaa, 0xe8, 0xbf, 0x20, 0xed, 0x5a, 0x07, 0xd8, 0x55, 0x11,
+	0x7e, 0x6d, 0xbe, 0x2d, 0x56, 0x77, 0x38, 0x53, 0xdd, 0xa3, 0xc1, 0xd0, 0x94, 0x12, 0x2a, 0xeb,
+	0x90, 0x8f, 0xa1, 0x59, 0x28, 0x3c, 0xc6, 0x43, 0xd9, 0x05, 0xb0, 0x21, 0xf3, 0x80, 0x13, 0xcb,
+	0x1d, 0xa8, 0x8e, 0x4e, 0x00, 0x1f, 0x25, 0x3e, 0xd4, 0x2a, 0xdf, 0xe4, 0x64, 0x8a, 0xd8, 0x83,
+	0x42, 0x20, 0x82, 0x71, 0xc7, 0xf1, 0x1c, 0x55, 0xf4, 0xdc, 0x3b, 0x7b, 0x7b, 0x55, 0x19, 0xbf,
+	0x1b, 0x9e, 0x43, 0x59, 0xfd, 0x1e, 0x8c, 0x40, 0x64, 0x42, 0x31, 0x90, 0x1d, 0x8f, 0x90, 0x78,
+	0x46, 0x2d, 0x34, 0x26, 0x51, 0xf0, 0x48, 0x91, 0x85, 0x20, 0x06, 0x0b, 0x25, 0xa5, 0x4c, 0xec,
+	0xd9, 0xe5, 0xe4, 0x9c, 0x4a, 0x0a, 0x96, 0xba, 0x67, 0x0b, 0x25, 0x23, 0xb0, 0xf2, 0x04, 0xb2,
+	0x2d, 0x1a, 0x60, 0xab, 0xdf, 0xe0, 0xdd, 0xd3, 0x81, 0x15, 0x4a, 0xdf, 0x34, 0xf9, 0x58, 0xf4,
+	0x13, 0x6c, 0x9e, 0x6b, 0xaf, 0x9b, 0x12, 0xaa, 0xfc, 0x41, 0x83, 0x7c, 0x6c, 0xef, 0xe8, 0x03,
+	0x48, 0x38, 0xb6, 0xb4, 0xd9, 0xfb, 0xe7, 0xa8, 0xa3, 0x16, 0x34, 0x13, 0x8e, 0xcd, 0x1c, 0x36,
+	0x96, 0x7f, 0x67, 0x79, 0xcb, 0x28, 0xff, 0x44, 0xa9, 0x79, 0x25, 0x4a, 0xe7, 0xc2, 0x00, 0x6f,
+	0x9f, 0x12, 0xc1, 0xa3, 0x2c, 0x3f, 0x56, 0x24, 0xeb, 0xa7, 0x15, 0xc9, 0xa9, 0x51, 0x91, 0x5c,
+	0xf9, 0x99, 0x06, 0x85, 0xf8, 0x51, 0xbc, 0xf9, 0x0e, 0x9f, 0x03, 0xe2, 0x2d, 0x53, 0x67, 0xec,
+	0x7a, 0x25, 0xce, 0x2b, 0x5d, 0x4b, 0x9c, 0x29, 0x6e, 0xe3, 0xf7, 0x20, 0xcf, 0x5c, 0x49, 0xb5,
+	0xdb, 0x49, 0x7e, 0x4c, 0xc0, 0x50, 0x22, 0x80, 0x56, 0x7e, 0x9d, 0x84, 0xbc, 0xd2, 0xb9, 0xee,
+	0xd9, 0xff, 0x04, 0x2a, 0x37, 0xe0, 0xb2, 0x12, 0x14, 0xf7, 0x84, 0xe4, 0x79, 0x92, 0x2e, 0x49,
+	0x49, 0x31, 0xfb, 0xdf, 0x61, 0x4f, 0x2f, 0x52, 0xc8, 0xc1, 0x90, 0x62, 0x51, 0xed, 0xea, 0x66,
+	0xe4, 0x64, 0x9b, 0x0c, 0x89, 0xee, 0x42, 0x12, 0x13, 0x55, 0x7c, 0x4d, 0x3f, 0x2d, 0xd4, 0x49,
+	0x68, 0x32, 0x02, 0x64, 0xc1, 0x62, 0xd7, 0xb5, 0xc2, 0xd0, 0x39, 0x94, 0xed, 0xb9, 0x8c, 0x8b,
+	0xeb, 0xf3, 0xfb, 0x52, 0xb5, 0x36, 0x26, 0xc0, 0x9c, 0x10, 0x68, 0x3c, 0x83, 0xc5, 0x71, 0x0a,
+	0x54, 0x82, 0xc2, 0xfe, 0x6e, 0x6d, 0x67, 0xa3, 0xd5, 0x6a, 0x7c, 0xda, 0xa8, 0x6f, 0x95, 0x16,
+	0x58, 0x11, 0xd3, 0xda, 0xaf, 0xd5, 0xea, 0xad, 0x56, 0x49, 0x63, 0xc0, 0xa7, 0x1b, 0x8d, 0x9d,
+	0x7d, 0xb3, 0x5e, 0x4a, 0xb0, 0xa2, 0x0d, 0xb3, 0x65, 0x8d, 0x0f, 0x61, 0x71, 0x3c, 0x22, 0x33,
+	0xba, 0xfd, 0xdd, 0xff, 0xde, 0xdd, 0xfb, 0x62, 0x57, 0x48, 0x68, 0xec, 0x6e, 0xee, 0xed, 0xef,
+	0x6e, 0x95, 0x34, 0x54, 0x80, 0xec, 0xde, 0x7e, 0x5b, 0x40, 0x31, 0x11, 0x8f, 0x20, 0xbb, 0xe1,
+	0x3b, 0x3c, 0x73, 0xb2, 0x50, 0xc8, 0x73, 0xab, 0x0c, 0x8f, 0x02, 0x60, 0x2e, 0x30, 0xca, 0xb5,
+	0x26, 0x1f, 0xb3, 0x36, 0x3a, 0xd7, 0x24, 0x36, 0x67, 0x0b, 0xd1, 0x53, 0x48, 0x73, 0x52, 0x15,
+	0xaf, 0x6f, 0xcd, 0x7a, 0xb6, 0x11, 0xb4, 0xd1, 0xc8, 0x94, 0x2c, 0x95, 0x3f, 0x6a, 0x90, 0x55,
+	0x48, 0x64, 0x42, 0x8e, 0xb5, 0xfa, 0x96, 0xe3, 0x61, 0xa1, 0xc5, 0xac, 0xe0, 0x3f, 0x2d, 0xac,
+	0x5a, 0x53, 0x4c, 0x1c, 0x64, 0xc5, 0x78, 0x24, 0xa6, 0x72, 0x02, 0x8b, 0xe3, 0xd3, 0xa8, 0x0c,
+	0x99, 0x3e, 0x0e, 0x43, 0xab, 0xa7, 0x9e, 0x83, 0x14, 0xc8, 0x82, 0xc1, 0x68, 0x7d, 0xf9, 0xc4,
+	0x17, 0x21, 0x98, 0x7d, 0x9c, 0x3e, 0xe3, 0x12, 0xcf, 0x64, 0x02, 0x60, 0x71, 0x30, 0xc0, 0x56,
+	0x28, 0x7b, 0xce, 0x9c, 0x29, 0x21, 0x6e, 0x62, 0xb6, 0x9c, 0xd1, 0x84, 0xac, 0xaa, 0xe9, 0xcf,
+	0x79, 0xa6, 0x43, 0xa2, 0xe6, 0x93, 0x2b, 0xf3, 0x71, 0xf4, 0x70, 0x95, 0x1c, 0x3d, 0x5c, 0x19,
+	0x2f, 0xe1, 0xd2, 0x54, 0xab, 0xc4, 0xba, 0xdf, 0x00, 0x8f, 0x55, 0x38, 0x67, 0xbc, 0xd4, 0x45,
+	0xa4, 0xcc, 0x79, 0x78, 0xaa, 0xec, 0x84, 0x5c, 0x12, 0x51, 0xfb, 0x2e, 0x72, 0x6c, 0x4b, 0x22,
+	0x8d, 0xaf, 0xa0, 0xa8, 0x98, 0x85, 0x11, 0xdf, 0x70, 0xb9, 0xe8, 0x8e, 0x25, 0x62, 0x77, 0xcc,
+	0xf8, 0x69, 0x02, 0x10, 0x8b, 0x54, 0xad, 0x41, 0xbf, 0x6f, 0x05, 0x43, 0xf5, 0xce, 0xf0, 0x09,
+	0x64, 0x23, 0xad, 0xe6, 0x7f, 0x69, 0x88, 0x78, 0x58, 0x58, 0x64, 0xcf, 0x3f, 0x9d, 0x57, 0x8e,
+	0x67, 0x93, 0x57, 0x72, 0x49, 0x60, 0xa8, 0x2f, 0x38, 0x06, 0xfd, 0x1b, 0xe8, 0x1e, 0xf1, 0x54,
+	0xae, 0xb8, 0x3a, 0x1d, 0x13, 0xd8, 0x33, 0x31, 0x2b, 0x54, 0x18, 0x15, 0x7a, 0x06, 0x79, 0x4a,
+	0x3a, 0xd1, 0xae, 0xcf, 0x7b, 0x0e, 0x65, 0x9d, 0x01, 0x25, 0x0a, 0x42, 0xff, 0x09, 0x45, 0xf6,
+	0x8e, 0x33, 0xe2, 0x4f, 0x9d, 0xcf, 0x5f, 0x60, 0x1c, 0x0a, 0xde, 0x04, 0xc8, 0x92, 0x01, 0x3d,
+	0x20, 0x03, 0xcf, 0x36, 0x7e, 0xa7, 0xc1, 0xe5, 0x31, 0x8b, 0xc9, 0x07, 0xd4, 0x75, 0x48, 0x90,
+	0xe3, 0x53, 0x03, 0xfb, 0x0c, 0x8e, 0xea, 0xde, 0xf1, 0xf6, 0x82, 0x99, 0x20, 0xc7, 0xe8, 0x49,
+	0xfc, 0x68, 0x66, 0x95, 0x6f, 0x63, 0x17, 0x60, 0x7b, 0x41, 0x1e, 0x5e, 0x65, 0x03, 0x12, 0x7b,
+	0xc7, 0xe8, 0x29, 0xf0, 0x27, 0xca, 0x0e, 0xb5, 0x0e, 0xdc, 0xa8, 0x37, 0xaf, 0xcc, 0xd4, 0xa0,
+	0xcd, 0x48, 0x4c, 0x08, 0xd5, 0x30, 0x64, 0x3b, 0x53, 0xb1, 0xda, 0xf8, 0x73, 0x02, 0x60, 0xd3,
+	0x0a, 0x1d, 0x5e, 0xfb, 0x87, 0xe8, 0x16, 0x14, 0xc3, 0x41, 0xb7, 0x8b, 0x43, 0xd6, 0x1e, 0x0c,
+	0x3c, 0x51, 0x7d, 0xe9, 0x66, 0x41, 0x22, 0x6b, 0x0c, 0xc7, 0x88, 0x0e, 0x2d, 0xc7, 0x1d, 0x04,
+	0x58, 0x12, 0x89, 0x92, 0xa4, 0x20, 0x91, 0x82, 0xe8, 0x36, 0xbb, 0xe9, 0x14, 0x7b, 0xdd, 0x61,
+	0xa7, 0x1f, 0x76, 0xfc, 0xc7, 0xab, 0xfc, 0xd8, 0x75, 0xb3, 0x20, 0xb1, 0x2f, 0xc2, 0xe6, 0xe3,
+	0xd5, 0x49, 0xaa, 0xf5, 0xc7, 0x65, 0x7d, 0x92, 0x6a, 0xfd, 0xf1, 0x14, 0xd5, 0x7a, 0x39, 0x35,
+	0x45, 0xb5, 0x8e, 0xee, 0xc1, 0x25, 0xea, 0x86, 0x51, 0xaa, 0x14, 0xaa, 0xa5, 0x39, 0xe1, 0x12,
+	0x75, 0xd5, 0xdb, 0xbd, 0xd0, 0x6e, 0x1d, 0xae, 0x79, 0xa4, 0xe3, 0xd8, 0xd8, 0xa3, 0x0e, 0x1d,
+	0x4e, 0xf0, 0x64, 0x38, 0xcf, 0x55, 0x8f, 0x34, 0xe4, 0xfc, 0x18, 0xeb, 0x53, 0xa8, 0xb0, 0x65,
+	0x6c, 0x27, 0x64, 0xd6, 0xb4, 0x27, 0x78, 0xb3, 0x9c, 0xf7, 0x6d, 0xea, 0x86, 0x5b, 0x92, 0x20,
+	0xce, 0x6c, 0xfc, 0x45, 0x87, 0x5c, 0x74, 0x28, 0x68, 0x13, 0x72, 0x3e, 0xb1, 0x3b, 0xbd, 0x80,
+	0x0c, 0x54, 0x7b, 0x77, 0xeb, 0xf4, 0x33, 0x64, 0x01, 0xf8, 0x39, 0x23, 0xdd, 0x5e, 0x30, 0xb3,
+	0xbe, 0x1c, 0x57, 0x7e, 0xa0, 0xf3, 0x88, 0xce, 0x01, 0xf4, 0x14, 0xf4, 0x80, 0xbc, 0x52, 0xf7,
+	0xe1, 0xfd, 0x39, 0x64, 0x55, 0x4d, 0xf2, 0xca, 0xe4, 0x4c, 0xac, 0x6a, 0x49, 0x9a, 0xe4, 0xd5,
+	0x9b, 0xc6, 0x9a, 0x73, 0xdd, 0x7f, 0x19, 0x4a, 0x7d, 0x1c, 0x1e, 0x61, 0xbb, 0xc3, 0x36, 0x2d,
+	0xcc, 0x25, 0xee, 0xc4, 0xa2, 0xc0, 0x37, 0x89, 0x2d, 0x4c, 0x7c, 0x0f, 0x2e, 0x05, 0x03, 0xcf,
+	0x73, 0xbc, 0x5e, 0x8c, 0x54, 0x5c, 0x8c, 0x25, 0x39, 0x11, 0xd1, 0x2e, 0x43, 0x89, 0xdd, 0xbb,
+	0x31, 0xa9, 0xe2, 0xd0, 0x17, 0x05, 0x3e, 0xa2, 0x7c, 0x00, 0x29, 0xe6, 0x04, 0xaa, 0x26, 0x99,
+	0x2e, 0x70, 0x47, 0x7e, 0x60, 0x0a, 0x4a, 0xf4, 0x15, 0x14, 0x45, 0xe2, 0xec, 0x1c, 0x0c, 0x99,
+	0xfc, 0x72, 0x86, 0x1b, 0xf6, 0xc3, 0x39, 0x0d, 0x5b, 0x15, 0x99, 0x73, 0x73, 0xc8, 0x52, 0x27,
+	0x6f, 0x94, 0xf2, 0x78, 0x84, 0xa9, 0x7c, 0x09, 0xa5, 0x49, 0x82, 0x19, 0x2d, 0xd3, 0x6a, 0xbc,
+	0x65, 0x9a, 0xe5, 0xe4, 0x51, 0x86, 0x8e, 0xb5, 0x53, 0x2c, 0x1f, 0xf2, 0xd8, 0x60, 0xac, 0xc3,
+	0x35, 0x76, 0x58, 0xee, 0x09, 0xde, 0x1a, 0xb5, 0xa4, 0xb1, 0xef, 0x58, 0xa3, 0x72, 0x5c, 0x9b,
+	0x28, 0xc7, 0x8d, 0x1f, 0x69, 0x50, 0x99, 0xc5, 0x2b, 0x83, 0xdf, 0x55, 0x48, 0xe3, 0xd7, 0x4e,
+	0x48, 0x43, 0xce, 0x99, 0x35, 0x25, 0xc4, 0x85, 0x8a, 0xae, 0x1a, 0x87, 0xe5, 0xc4, 0x8d, 0x24,
+	0x17, 0xaa, 0x10, 0xec, 0xce, 0x63, 0xd9, 0x46, 0xb2, 0xd2, 0x99, 0x99, 0xf3, 0xf6, 0xd4, 0x96,
+	0x62, 0xcb, 0xa9, 0x9e, 0xd3, 0x1c, 0xb1, 0x19, 0x21, 0x94, 0xda, 0xc4, 0x37, 0xc9, 0x80, 0xe2,
+	0xf0, 0x1f, 0x95, 0xbd, 0x8c, 0xdf, 0x6a, 0x70, 0x29, 0xb6, 0xaa, 0x34, 0xc2, 0x07, 0xb1, 0x0c,
+	0x70, 0x67, 0xba, 0x64, 0x9d, 0xa4, 0xff, 0xf6, 0xf1, 0x7f, 0x93, 0xc7, 0xff, 0x67, 0x90, 0x0f,
+	0x98, 0x60, 0x91, 0x00, 0x4e, 0x7d, 0x63, 0xe1, 0x8b, 0xcb, 0x04, 0x10, 0x44, 0xe3, 0xb1, 0x04,
+	0xf0, 0x1b, 0x0d, 0x60, 0x44, 0x86, 0x1e, 0x8e, 0x45, 0x90, 0xf7, 0xce, 0x90, 0x18, 0x8b, 0x1c,
+	0xdf, 0xd3, 0x44, 0xe4, 0xb8, 0x02, 0x29, 0xbe, 0x8a, 0x2a, 0x69, 0x39, 0x30, 0x7e, 0xc9, 0x12,
+	0x93, 0x3d, 0xdf, 0x84, 0xdd, 0x93, 0x53, 0x61, 0x23, 0x72, 0x5b, 0x7d, 0x5e, 0xb7, 0x35, 0x76,
+	0xa1, 0x50, 0xb7, 0x7b, 0x7f, 0xb7, 0xbb, 0x61, 0xfc, 0x42, 0x83, 0xa2, 0x14, 0x28, 0x8f, 0xfd,
+	0x61, 0xec, 0xd8, 0xa7, 0xbf, 0x27, 0x8f, 0xd1, 0x7e, 0xfb, 0x23, 0x7f, 0xc0, 0x8f, 0xfc, 0x3e,
+	0xa4, 0x30, 0x93, 0x2b, 0x8f, 0xe6, 0xad, 0x99, 0xab, 0x9a, 0x82, 0x66, 0xec, 0x84, 0x7f, 0xa9,
+	0x81, 0xce, 0xe6, 0xd0, 0x7d, 0x48, 0x86, 0x41, 0xf7, 0xfc, 0x98, 0xce, 0xa8, 0x18, 0xb1, 0x1d,
+	0x8e, 0x7a, 0xcd, 0xd3, 0x89, 0xed, 0x90, 0xa2, 0x77, 0x20, 0xd7, 0x75, 0x1d, 0xec, 0xd1, 0x8e,
+	0x63, 0xcb, 0x23, 0xcc, 0x0a, 0x44, 0xc3, 0x66, 0x93, 0x21, 0x0e, 0x4e, 0x70, 0xc0, 0x26, 0x45,
+	0xd5, 0x9e, 0x15, 0x88, 0x86, 0x8d, 0xee, 0xc2, 0x52, 0x3c, 0x11, 0xf7, 0xc3, 0x9e, 0xec, 0xfe,
+	0x8b, 0xa3, 0xf4, 0xfb, 0x22, 0xec, 0x19, 0x0f, 0xe1, 0x32, 0xfb, 0x7c, 0xdd, 0xc2, 0xc1, 0x89,
+	0xd3, 0xc5, 0xf3, 0x7d, 0x88, 0x37, 0x76, 0xe0, 0xca, 0x38, 0x93, 0x3c, 0xbd, 0x47, 0x90, 0x0d,
+	0x25, 0x4e, 0x5a, 0xb3, 0x3c, 0x1d, 0xd1, 0x05, 0x81, 0x19, 0x51, 0x1a, 0xdf, 0x4f, 0x40, 0x46,
+	0x62, 0x67, 0x7e, 0xe0, 0x1e, 0xd3, 0x25, 0x31, 0xd9, 0x6d, 0x6c, 0xc6, 0xee, 0xa0, 0x08, 0x7b,
+	0x77, 0x4f, 0x5b, 0xb3, 0xaa, 0xfa, 0x00, 0x91, 0x33, 0x22, 0xbe, 0x99, 0x19, 0x54, 0x9f, 0x3f,
+	0x83, 0xa6, 0x66, 0x66, 0xd0, 0xca, 0x53, 0x28, 0x8e, 0x2d, 0x78, 0x91, 0x67, 0x3b, 0xe3, 0xff,
+	0xa0, 0x34, 0xf9, 0xef, 0x09, 0xd1, 0xd7, 0x49, 0x9c, 0x3a, 0x94, 0x08, 0xc1, 0xa4, 0xfb, 0xea,
+	0x2b, 0x8f, 0xf8, 0x06, 0x3c, 0xd6, 0x07, 0x26, 0x27, 0xfb, 0xc0, 0x32, 0x64, 0x4e, 0x84, 0x60,
+	0x79, 0x79, 0x14, 0x68, 0x0c, 0xa0, 0x1c, 0x2b, 0xb1, 0xc5, 0xc3, 0x89, 0xba, 0x18, 0x1f, 0x43,
+	0x46, 0x16, 0x66, 0x67, 0x16, 0x56, 0xe3, 0x2d, 0x90, 0xa9, 0x78, 0xd8, 0x07, 0x63, 0xc7, 0xa3,
+	0x38, 0x38, 0xb1, 0x5c, 0xa9, 0x69, 0x04, 0x1b, 0x5f, 0x6b, 0x70, 0x79, 0x46, 0x82, 0x62, 0x8a,
+	0xca, 0x34, 0xa7, 0x1a, 0x5d, 0x09, 0xce, 0xd8, 0xf2, 0x55, 0x48, 0xbf, 0xc2, 0x4e, 0xef, 0x88,
+	0xca, 0xd7, 0x23, 0x09, 0xa1, 0x9b, 0x50, 0x60, 0xc5, 0xa5, 0xf2, 0x07, 0xb9, 0xe3, 0x3c, 0x75,
+	0x43, 0xe5, 0x0c, 0x68, 0x11, 0x12, 0x47, 0x6b, 0xf2, 0xef, 0x03, 0x89, 0xa3, 0xb5, 0xb5, 0xdf,
+	0x67, 0x20, 0xb9, 0xe1, 0x3b, 0xe8, 0x4b, 0xc8, 0xc7, 0x76, 0x84, 0xe6, 0xd9, 0x6f, 0xe5, 0xf6,
+	0x3c, 0x3d, 0x8b, 0xb1, 0x80, 0x3e, 0x83, 0xac, 0xfa, 0xf3, 0x08, 0xba, 0x31, 0xc5, 0x33, 0xf1,
+	0xef, 0x98, 0xca, 0xcd, 0x33, 0x28, 0x22, 0x91, 0xff, 0x03, 0x85, 0xb8, 0x6f, 0xa2, 0xdb, 0x33,
+	0x99, 0x26, 0xfc, 0xbd, 0x72, 0xe7, 0x1c, 0xaa, 0x48, 0xfc, 0x16, 0x24, 0xdb, 0x96, 0x8f, 0xde,
+	0x99, 0xf5, 0x8a, 0xa4, 0x84, 0x5d, 0x3b, 0xf5, 0x89, 0xc9, 0x48, 0x7e, 0x37, 0xa1, 0xad, 0x6a,
+	0x68, 0x1f, 0x8a, 0x63, 0x5f, 0x09, 0xd1, 0x9d, 0xb9, 0xbe, 0x22, 0x9e, 0x25, 0x79, 0x61, 0x55,
+	0x43, 0x1b, 0x90, 0x51, 0xbe, 0x72, 0x4a, 0x17, 0x5c, 0x79, 0x77, 0x0a, 0x1f, 0xfb, 0xeb, 0x94,
+	0xb1, 0x80, 0x5c, 0xc8, 0xb5, 0xb0, 0x7b, 0x58, 0x63, 0x7f, 0xb4, 0x42, 0xff, 0x3e, 0x22, 0x16,
+	0x7f, 0xc3, 0xaa, 0xc6, 0xff, 0x86, 0x15, 0xd1, 0x29, 0xed, 0xaa, 0xf3, 0x92, 0x47, 0xd6, 0x24,
+	0x80, 0xa6, 0x0b, 0x41, 0x74, 0x6f, 0x66, 0x56, 0x98, 0x59, 0x69, 0x56, 0xee, 0xcf, 0x45, 0x1b,
+	0x2d, 0xd8, 0x86, 0x5c, 0x54, 0x3b, 0xa1, 0x9b, 0x67, 0xd5, 0x55, 0x42, 0xbc, 0x71, 0x7e, 0xe9,
+	0x65, 0x2c, 0xa0, 0x6d, 0x48, 0xf1, 0xd4, 0x8c, 0xfe, 0xe5, 0xb4, 0x94, 0x2d, 0xa4, 0x5d, 0x3f,
+	0x3b, 0xa3, 0x1b, 0x0b, 0xe8, 0x08, 0x2e, 0x4d, 0x85, 0x1e, 0xf4, 0xaf, 0x67, 0x79, 0xd3, 0x58,
+	0x78, 0x9a, 0xd7, 0xf1, 0x56, 0xb5, 0xcd, 0x87, 0x5f, 0x3e, 0xe8, 0x39, 0xf4, 0x68, 0x70, 0xc0,
+	0xce, 0x6a, 0x45, 0x72, 0xa9, 0xdf, 0xb5, 0x95, 0xd1, 0xdf, 0x7a, 0x56, 0x7a, 0xd8, 0x5b, 0x11,
+	0xc2, 0x0e, 0xd2, 0xfc, 0x25, 0xf7, 0xe1, 0xdf, 0x06, 0x00, 0x24, 0x74, 0x00, 0xe3, 0xd5, 0x27,
+	0x00, 0x00,
 }
@@ -0,0 +1,172 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DataSourceName is the name of the data source that the linkerd dashboards
+// query.
+const DataSourceName = "prometheus"
+
+// Provisioner provisions the Prometheus data source and the dashboards of
+// Linkerd to an existing Grafana server, through its HTTP API. Both are
+// overwritten if they already exist, so that provisioning is idempotent.
+type Provisioner struct {
+	grafanaURL *url.URL
+	apiKey     string
+	httpClient *http.Client
+}
+
+type dataSource struct {
+	ID        int               `json:"id,omitempty"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Access    string            `json:"access"`
+	URL       string            `json:"url"`
+	IsDefault bool              `json:"isDefault"`
+	JSONData  map[string]string `json:"jsonData"`
+}
+
+type dashboardRequest struct {
+	Dashboard map[string]interface{} `json:"dashboard"`
+	Overwrite bool                   `json:"overwrite"`
+}
+
+// NewProvisioner returns a Provisioner of the Grafana server at grafanaURL.
+// Its requests bear apiKey, unless it's empty.
+func NewProvisioner(grafanaURL, apiKey string) (*Provisioner, error) {
+	u, err := url.Parse(grafanaURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("Grafana URL must be an http or https URL, got %s", grafanaURL)
+	}
+
+	return &Provisioner{
+		grafanaURL: u,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// ProvisionDataSource creates the data source of the Prometheus server at
+// prometheusURL, or updates it if it exists.
+func (p *Provisioner) ProvisionDataSource(prometheusURL string) error {
+	ds := dataSource{
+		Name:      DataSourceName,
+		Type:      "prometheus",
+		Access:    "proxy",
+		URL:       prometheusURL,
+		IsDefault: true,
+		JSONData:  map[string]string{"timeInterval": "5s"},
+	}
+
+	existing := dataSource{}
+	found, err := p.do(http.MethodGet, "/api/datasources/name/"+DataSourceName, nil, &existing)
+	if err != nil {
+		return err
+	}
+	if !found {
+		_, err = p.do(http.MethodPost, "/api/datasources", ds, nil)
+		return err
+	}
+
+	ds.ID = existing.ID
+	_, err = p.do(http.MethodPut, fmt.Sprintf("/api/datasources/%d", existing.ID), ds, nil)
+	return err
+}
+
+// ProvisionDashboards creates or overwrites the dashboards of the JSON files
+// in dir.
+func (p *Provisioner) ProvisionDashboards(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no dashboards found in %s", dir)
+	}
+
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		dashboard := make(map[string]interface{})
+		if err := json.Unmarshal(content, &dashboard); err != nil {
+			return fmt.Errorf("failed to parse dashboard %s: %s", file, err)
+		}
+		// the dashboards are matched by their uid, ids being specific to
+		// each Grafana server
+		dashboard["id"] = nil
+
+		if _, err := p.do(http.MethodPost, "/api/dashboards/db", dashboardRequest{Dashboard: dashboard, Overwrite: true}, nil); err != nil {
+			return fmt.Errorf("failed to provision dashboard %s: %s", file, err)
+		}
+		log.Infof("provisioned dashboard %s", filepath.Base(file))
+	}
+	return nil
+}
+
+// do sends a request to the API of Grafana, and decodes the response into
+// rsp if it isn't nil. It returns false if the resource of a GET request
+// wasn't found.
+func (p *Provisioner) do(method, path string, body, rsp interface{}) (bool, error) {
+	u := *p.grafanaURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	var reqBody io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return false, err
+		}
+		reqBody = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	httpRsp, err := p.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer httpRsp.Body.Close()
+
+	if httpRsp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return false, nil
+	}
+	if httpRsp.StatusCode < 200 || httpRsp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(httpRsp.Body, 1024))
+		return false, fmt.Errorf("unexpected status %s %s: %s: %s", method, u.Path, httpRsp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if rsp != nil {
+		if err := json.NewDecoder(httpRsp.Body).Decode(rsp); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,181 @@
+package grafana
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type request struct {
+	method        string
+	path          string
+	authorization string
+	body          map[string]interface{}
+}
+
+// fakeGrafana records the requests it serves, and responds with the data
+// source whose name is "prometheus" if it exists.
+type fakeGrafana struct {
+	sync.Mutex
+	requests   []request
+	dataSource string
+}
+
+func (g *fakeGrafana) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.Lock()
+	defer g.Unlock()
+
+	r := request{method: req.Method, path: req.URL.Path, authorization: req.Header.Get("Authorization")}
+	if req.Body != nil {
+		json.NewDecoder(req.Body).Decode(&r.body)
+	}
+	g.requests = append(g.requests, r)
+
+	if req.Method == http.MethodGet {
+		if g.dataSource == "" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write([]byte(g.dataSource))
+		return
+	}
+	w.Write([]byte("{}"))
+}
+
+func TestProvisionDataSource(t *testing.T) {
+	expectedDataSource := map[string]interface{}{
+		"name":      "prometheus",
+		"type":      "prometheus",
+		"access":    "proxy",
+		"url":       "http://prometheus.linkerd.svc.cluster.local:9090",
+		"isDefault": true,
+		"jsonData":  map[string]interface{}{"timeInterval": "5s"},
+	}
+
+	t.Run("Creates the data source", func(t *testing.T) {
+		grafana := &fakeGrafana{}
+		server := httptest.NewServer(grafana)
+		defer server.Close()
+
+		p, err := NewProvisioner(server.URL, "key")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := p.ProvisionDataSource("http://prometheus.linkerd.svc.cluster.local:9090"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if len(grafana.requests) != 2 {
+			t.Fatalf("Expected 2 requests, got %+v", grafana.requests)
+		}
+		create := grafana.requests[1]
+		if create.method != http.MethodPost || create.path != "/api/datasources" {
+			t.Fatalf("Unexpected request: %s %s", create.method, create.path)
+		}
+		if create.authorization != "Bearer key" {
+			t.Fatalf("Unexpected authorization: %s", create.authorization)
+		}
+		if !reflect.DeepEqual(create.body, expectedDataSource) {
+			t.Fatalf("Expected data source %v, got %v", expectedDataSource, create.body)
+		}
+	})
+
+	t.Run("Updates the existing data source", func(t *testing.T) {
+		grafana := &fakeGrafana{dataSource: `{"id": 3, "name": "prometheus"}`}
+		server := httptest.NewServer(grafana)
+		defer server.Close()
+
+		p, err := NewProvisioner(server.URL+"/grafana/", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := p.ProvisionDataSource("http://prometheus.linkerd.svc.cluster.local:9090"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if len(grafana.requests) != 2 {
+			t.Fatalf("Expected 2 requests, got %+v", grafana.requests)
+		}
+		if grafana.requests[0].path != "/grafana/api/datasources/name/prometheus" {
+			t.Fatalf("Unexpected request: %s", grafana.requests[0].path)
+		}
+		update := grafana.requests[1]
+		if update.method != http.MethodPut || update.path != "/grafana/api/datasources/3" {
+			t.Fatalf("Unexpected request: %s %s", update.method, update.path)
+		}
+		if update.authorization != "" {
+			t.Fatalf("Unexpected authorization: %s", update.authorization)
+		}
+		expectedDataSource["id"] = float64(3)
+		if !reflect.DeepEqual(update.body, expectedDataSource) {
+			t.Fatalf("Expected data source %v, got %v", expectedDataSource, update.body)
+		}
+	})
+}
+
+func TestProvisionDashboards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dashboards")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dashboard := `{"id": 7, "uid": "XKy9QWRmz", "title": "Linkerd Top Line"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "top-line.json"), []byte(dashboard), 0644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Overwrites the dashboards", func(t *testing.T) {
+		grafana := &fakeGrafana{}
+		server := httptest.NewServer(grafana)
+		defer server.Close()
+
+		p, err := NewProvisioner(server.URL, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := p.ProvisionDashboards(dir); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := []request{
+			{
+				method: http.MethodPost,
+				path:   "/api/dashboards/db",
+				body: map[string]interface{}{
+					"dashboard": map[string]interface{}{"id": nil, "uid": "XKy9QWRmz", "title": "Linkerd Top Line"},
+					"overwrite": true,
+				},
+			},
+		}
+		if !reflect.DeepEqual(grafana.requests, expected) {
+			t.Fatalf("Expected requests %+v, got %+v", expected, grafana.requests)
+		}
+	})
+
+	t.Run("Returns an error if no dashboards are found", func(t *testing.T) {
+		p, err := NewProvisioner("http://grafana:3000", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		err = p.ProvisionDashboards(filepath.Join(dir, "missing"))
+		if err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+	})
+}
+
+func TestNewProvisioner(t *testing.T) {
+	for _, grafanaURL := range []string{"grafana:3000", "ftp://grafana", "http://"} {
+		if _, err := NewProvisioner(grafanaURL, ""); err == nil {
+			t.Fatalf("Expected an error for %s, got nil", grafanaURL)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package identity
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/ca"
+)
+
+// serverCredentials are the TLS credentials of the identity service. The
+// identity service can't be certified through a proxy of its own, since the
+// proxy would have to be certified by the identity service before it could
+// accept connections, so its certificate is issued directly by the CA, for
+// the identity that the proxies expect the identity service to have.
+//
+// The certificate is reissued once it's due for renewal, and once the CA's
+// issuer changes. It doesn't carry the issuer's chain, which is distributed
+// along with the trust anchors.
+type serverCredentials struct {
+	sync.Mutex
+
+	ca      *ca.CA
+	dnsName string
+
+	// the current certificate, and the issuer that issued it
+	crt    *tls.Certificate
+	issuer *x509.Certificate
+}
+
+func newServerCredentials(issuer *ca.CA, dnsName string) *serverCredentials {
+	return &serverCredentials{
+		ca:      issuer,
+		dnsName: dnsName,
+	}
+}
+
+func (c *serverCredentials) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: c.getCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+func (c *serverCredentials) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	issuer := c.ca.Certificate()
+	if c.crt != nil && c.issuer.Equal(issuer) && time.Now().Before(ca.RenewalTime(c.crt.Leaf)) {
+		return c.crt, nil
+	}
+
+	issued, err := c.ca.IssueEndEntityCertificate(c.dnsName)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(issued.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(issued.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.crt = &tls.Certificate{
+		Certificate: [][]byte{issued.Certificate},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	c.issuer = issuer
+	return c.crt, nil
+}
@@ -0,0 +1,98 @@
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/ca"
+)
+
+// issuerPEM returns the PEM-encoded certificate and key of a new self-signed
+// issuer.
+func issuerPEM(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "issuer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	crt, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func TestServerCredentials(t *testing.T) {
+	dnsName := "linkerd-ca.linkerd.serviceaccount.identity.linkerd.cluster.local"
+
+	issuer, err := ca.NewCA()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	creds := newServerCredentials(issuer, dnsName)
+
+	verify := func(crt *x509.Certificate) {
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM([]byte(issuer.TrustAnchorPEM()))
+		if _, err := crt.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots}); err != nil {
+			t.Fatalf("Expected the certificate to be valid for %s, got %s", dnsName, err)
+		}
+	}
+
+	t.Run("Issues a certificate for the identity service", func(t *testing.T) {
+		crt, err := creds.getCertificate(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		verify(crt.Leaf)
+
+		again, err := creds.getCertificate(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if again != crt {
+			t.Fatalf("Expected the certificate to be reused until it's due for renewal")
+		}
+	})
+
+	t.Run("Reissues the certificate when the issuer changes", func(t *testing.T) {
+		before, err := creds.getCertificate(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		issuerCrt, issuerKey := issuerPEM(t)
+		if err := issuer.SetIssuer(issuerCrt, issuerKey, ""); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		after, err := creds.getCertificate(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if after == before {
+			t.Fatalf("Expected the certificate to be reissued by the new issuer")
+		}
+		verify(after.Leaf)
+	})
+}
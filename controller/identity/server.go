@@ -0,0 +1,190 @@
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2-proxy-api/go/identity"
+	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	authV1 "k8s.io/api/authentication/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// TokenValidator authenticates the ServiceAccount tokens presented by
+// proxies.
+type TokenValidator interface {
+	// Validate returns the namespace and name of the ServiceAccount that the
+	// token belongs to, or an error if it isn't a valid ServiceAccount token.
+	Validate(token []byte) (string, string, error)
+}
+
+type server struct {
+	ca                  *ca.CA
+	validator           TokenValidator
+	controllerNamespace string
+	trustDomain         string
+	validity            time.Duration
+	expirations         *ca.Expirations
+	recorder            *k8s.EventRecorder
+}
+
+// The Identity service certifies the identities of proxies, with the identity
+// API of linkerd2-proxy-api that the proxies' LINKERD2_PROXY_IDENTITY_*
+// variables configure them to use. A proxy sends a certificate signing request
+// for the identity of its pod's ServiceAccount, along with the ServiceAccount's
+// token, and is issued a certificate that is valid for the given validity,
+// after which the proxy has to be certified again. The expirations of the
+// issued certificates are recorded in expirations.
+//
+// Requests whose tokens are rejected are recorded in warning events on the
+// ServiceAccount of the requested identity.
+//
+// The service is served over TLS, with a certificate issued by ca for the
+// identity of the CA's ServiceAccount, which the proxies verify with the
+// trust anchors that they're configured with.
+func NewServer(
+	addr string,
+	controllerNamespace string,
+	trustDomain string,
+	validity time.Duration,
+	ca *ca.CA,
+	expirations *ca.Expirations,
+	validator TokenValidator,
+	k8sAPI *k8s.API,
+) (*grpc.Server, net.Listener, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serviceIdentity := pkgK8s.ServiceAccountIdentity{
+		ControllerNamespace: controllerNamespace,
+		TrustDomain:         trustDomain,
+	}.ToIdentityServiceIdentity()
+	creds := newServerCredentials(ca, serviceIdentity.ToDNSName())
+
+	s := prometheus.NewGrpcServer(grpc.Creds(credentials.NewTLS(creds.tlsConfig())))
+	srv := server{
+		ca:                  ca,
+		validator:           validator,
+		controllerNamespace: controllerNamespace,
+		trustDomain:         trustDomain,
+		validity:            validity,
+		expirations:         expirations,
+		recorder:            k8s.NewEventRecorder(k8sAPI.Client, "linkerd-identity"),
+	}
+	pb.RegisterIdentityServer(s, &srv)
+
+	return s, lis, nil
+}
+
+func (s *server) Certify(ctx context.Context, req *pb.CertifyRequest) (*pb.CertifyResponse, error) {
+	identity, err := pkgK8s.ParseServiceAccountIdentity(req.GetIdentity(), s.controllerNamespace, s.trustDomain)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	namespace, name, err := s.validator.Validate(req.GetToken())
+	if err != nil {
+		return nil, s.reject(identity, status.Errorf(codes.Unauthenticated, "invalid token: %s", err))
+	}
+	if namespace != identity.Namespace || name != identity.Name {
+		return nil, s.reject(identity, status.Errorf(codes.PermissionDenied, "the token of ServiceAccount %s/%s can't be used to certify %s", namespace, name, req.GetIdentity()))
+	}
+
+	csr, err := x509.ParseCertificateRequest(req.GetCertificateSigningRequest())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid certificate signing request: %s", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid certificate signing request signature: %s", err)
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != req.GetIdentity() {
+		return nil, status.Errorf(codes.InvalidArgument, "the certificate signing request must be for %s only", req.GetIdentity())
+	}
+
+	crt, err := s.ca.IssueCertificateForRequest(req.GetIdentity(), csr, s.validity)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to issue certificate for %s: %s", req.GetIdentity(), err)
+	}
+
+	leaf, err := x509.ParseCertificate(crt)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse certificate issued for %s: %s", req.GetIdentity(), err)
+	}
+	s.expirations.Set(ca.EndEntityCertificate, req.GetIdentity(), leaf)
+	validUntil, err := ptypes.TimestampProto(leaf.NotAfter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid expiration of the certificate issued for %s: %s", req.GetIdentity(), err)
+	}
+
+	log.Debugf("certified %s until %s", req.GetIdentity(), leaf.NotAfter)
+	return &pb.CertifyResponse{LeafCertificate: crt, ValidUntil: validUntil}, nil
+}
+
+// reject records the rejection of a certification request in an event on the
+// ServiceAccount of the requested identity, and returns its error.
+func (s *server) reject(identity pkgK8s.ServiceAccountIdentity, err error) error {
+	s.recorder.EventOnReference(serviceAccountReference(identity), v1.EventTypeWarning, "CertificationRejected",
+		fmt.Sprintf("Rejected the certification of %s: %s", identity.ToDNSName(), status.Convert(err).Message()))
+	return err
+}
+
+func serviceAccountReference(identity pkgK8s.ServiceAccountIdentity) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "ServiceAccount",
+		Namespace:  identity.Namespace,
+		Name:       identity.Name,
+	}
+}
+
+// tokenReviewValidator validates tokens with the TokenReview API of the
+// Kubernetes API server.
+type tokenReviewValidator struct {
+	client kubernetes.Interface
+}
+
+// NewTokenReviewValidator returns a TokenValidator that validates tokens with
+// the TokenReview API, which requires the permission to create TokenReviews.
+func NewTokenReviewValidator(client kubernetes.Interface) TokenValidator {
+	return &tokenReviewValidator{client}
+}
+
+func (v *tokenReviewValidator) Validate(token []byte) (string, string, error) {
+	review, err := v.client.AuthenticationV1().TokenReviews().Create(&authV1.TokenReview{
+		Spec: authV1.TokenReviewSpec{Token: string(token)},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if review.Status.Error != "" {
+		return "", "", errors.New(review.Status.Error)
+	}
+	if !review.Status.Authenticated {
+		return "", "", errors.New("the token isn't authenticated")
+	}
+
+	// the users of ServiceAccounts are named
+	// system:serviceaccount:<namespace>:<name>
+	parts := strings.Split(review.Status.User.Username, ":")
+	if len(parts) != 4 || parts[0] != "system" || parts[1] != "serviceaccount" {
+		return "", "", fmt.Errorf("the token of %s isn't a ServiceAccount token", review.Status.User.Username)
+	}
+	return parts[2], parts[3], nil
+}
@@ -0,0 +1,221 @@
+package identity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2-proxy-api/go/identity"
+	"github.com/linkerd/linkerd2/controller/ca"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	authV1 "k8s.io/api/authentication/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type mockTokenValidator struct {
+	tokens map[string][2]string
+}
+
+func (v *mockTokenValidator) Validate(token []byte) (string, string, error) {
+	account, ok := v.tokens[string(token)]
+	if !ok {
+		return "", "", errors.New("unknown token")
+	}
+	return account[0], account[1], nil
+}
+
+func csrFor(t *testing.T, dnsNames ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: dnsNames}, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return csr
+}
+
+func TestCertify(t *testing.T) {
+	webIdentity := "web.emojivoto.serviceaccount.identity.linkerd.cluster.local"
+
+	issuer, err := ca.NewCA()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto
+`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+
+	srv := &server{
+		ca: issuer,
+		validator: &mockTokenValidator{tokens: map[string][2]string{
+			"web-token":    {"emojivoto", "web"},
+			"voting-token": {"emojivoto", "voting"},
+		}},
+		controllerNamespace: "linkerd",
+		trustDomain:         "cluster.local",
+		validity:            24 * time.Hour,
+		expirations:         ca.NewExpirations(),
+		recorder:            k8s.NewEventRecorder(k8sAPI.Client, "linkerd-identity"),
+	}
+
+	t.Run("Issues a short-lived certificate for the ServiceAccount of the token", func(t *testing.T) {
+		rsp, err := srv.Certify(context.Background(), &pb.CertifyRequest{
+			Identity:                  webIdentity,
+			Token:                     []byte("web-token"),
+			CertificateSigningRequest: csrFor(t, webIdentity),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		crt, err := x509.ParseCertificate(rsp.GetLeafCertificate())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(issuer.TrustAnchorPEM())) {
+			t.Fatal("Failed to parse the trust anchor")
+		}
+		if _, err := crt.Verify(x509.VerifyOptions{
+			DNSName:   webIdentity,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}); err != nil {
+			t.Fatalf("Expected the certificate to be valid for [%s], got: %s", webIdentity, err)
+		}
+		if validFor := time.Until(crt.NotAfter); validFor > 48*time.Hour {
+			t.Fatalf("Expected the certificate to be short-lived, but it's valid for %s", validFor)
+		}
+		validUntil, err := ptypes.Timestamp(rsp.GetValidUntil())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !validUntil.Equal(crt.NotAfter) {
+			t.Fatalf("Expected the response to be valid until %s, got %s", crt.NotAfter, validUntil)
+		}
+		if identities := srv.expirations.Identities(ca.EndEntityCertificate); len(identities) != 1 || identities[0] != webIdentity {
+			t.Fatalf("Expected the expiration of the certificate of [%s] to be recorded, got %v", webIdentity, identities)
+		}
+	})
+
+	testCases := []struct {
+		desc string
+		req  *pb.CertifyRequest
+		code codes.Code
+	}{
+		{
+			desc: "Rejects identities of other trust domains",
+			req: &pb.CertifyRequest{
+				Identity:                  "web.emojivoto.serviceaccount.identity.linkerd.prod.example.com",
+				Token:                     []byte("web-token"),
+				CertificateSigningRequest: csrFor(t, "web.emojivoto.serviceaccount.identity.linkerd.prod.example.com"),
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "Rejects invalid tokens",
+			req: &pb.CertifyRequest{
+				Identity:                  webIdentity,
+				Token:                     []byte("forged-token"),
+				CertificateSigningRequest: csrFor(t, webIdentity),
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			desc: "Rejects the tokens of other ServiceAccounts",
+			req: &pb.CertifyRequest{
+				Identity:                  webIdentity,
+				Token:                     []byte("voting-token"),
+				CertificateSigningRequest: csrFor(t, webIdentity),
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			desc: "Rejects requests for other names",
+			req: &pb.CertifyRequest{
+				Identity:                  webIdentity,
+				Token:                     []byte("web-token"),
+				CertificateSigningRequest: csrFor(t, webIdentity, "voting.emojivoto.serviceaccount.identity.linkerd.cluster.local"),
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := srv.Certify(context.Background(), tc.req)
+			if status.Code(err) != tc.code {
+				t.Fatalf("Expected an error with code %s, got: %v", tc.code, err)
+			}
+		})
+	}
+
+	t.Run("Records the rejected tokens in events on the ServiceAccount", func(t *testing.T) {
+		events, err := k8sAPI.Client.CoreV1().Events("emojivoto").List(metaV1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(events.Items) != 2 {
+			t.Fatalf("Expected an event for each rejected token, got %+v", events.Items)
+		}
+		for _, event := range events.Items {
+			if event.Reason != "CertificationRejected" || event.Type != "Warning" {
+				t.Fatalf("Expected a CertificationRejected warning, got %s %s", event.Type, event.Reason)
+			}
+			if event.InvolvedObject.Kind != "ServiceAccount" || event.InvolvedObject.Name != "web" {
+				t.Fatalf("Expected event on the web ServiceAccount, got %+v", event.InvolvedObject)
+			}
+		}
+	})
+}
+
+func TestTokenReviewValidator(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authV1.TokenReview)
+		switch review.Spec.Token {
+		case "web-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:emojivoto:web"
+		case "user-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "admin"
+		}
+		return true, review, nil
+	})
+	validator := NewTokenReviewValidator(client)
+
+	namespace, name, err := validator.Validate([]byte("web-token"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if namespace != "emojivoto" || name != "web" {
+		t.Fatalf("Expected ServiceAccount emojivoto/web, got %s/%s", namespace, name)
+	}
+
+	for _, token := range []string{"user-token", "forged-token"} {
+		if _, _, err := validator.Validate([]byte(token)); err == nil {
+			t.Fatalf("Expected an error for [%s]", token)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package k8s
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/linkerd/linkerd2/pkg/k8s"
@@ -12,10 +11,12 @@ import (
 	"google.golang.org/grpc/status"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	appinformers "k8s.io/client-go/informers/apps/v1beta2"
+	batchinformers "k8s.io/client-go/informers/batch/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
@@ -27,13 +28,24 @@ const (
 	CM ApiResource = iota
 	Deploy
 	Endpoint
+	Job
 	NS
+	Node
 	Pod
 	RC
 	RS
 	Svc
 )
 
+// podLabelIndex is the name of the index of the Pod informer that maps each
+// label of a pod, prefixed with its namespace, to the pod, so that the pods
+// selected by a set of labels can be found without listing every pod in the
+// namespace.
+const podLabelIndex = "labels"
+
+// defaultSyncTimeout is how long Sync waits for the informers to be synced.
+const defaultSyncTimeout = 60 * time.Second
+
 // API provides shared informers for all Kubernetes objects
 type API struct {
 	Client kubernetes.Interface
@@ -41,11 +53,15 @@ type API struct {
 	cm       coreinformers.ConfigMapInformer
 	deploy   appinformers.DeploymentInformer
 	endpoint coreinformers.EndpointsInformer
+	job      batchinformers.JobInformer
 	ns       coreinformers.NamespaceInformer
+	node     coreinformers.NodeInformer
 	pod      coreinformers.PodInformer
 	rc       coreinformers.ReplicationControllerInformer
 	rs       appinformers.ReplicaSetInformer
 	svc      coreinformers.ServiceInformer
+	ts       cache.SharedIndexInformer
+	sp       cache.SharedIndexInformer
 
 	syncChecks      []cache.InformerSynced
 	sharedInformers informers.SharedInformerFactory
@@ -53,7 +69,16 @@ type API struct {
 
 // NewAPI takes a Kubernetes client and returns an initialized API
 func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
+	return NewNamespacedAPI(k8sClient, nil, resources...)
+}
+
+// NewNamespacedAPI returns an initialized API whose informers only watch the
+// given namespaces, or all namespaces if none are given.
+func NewNamespacedAPI(k8sClient kubernetes.Interface, namespaces []string, resources ...ApiResource) *API {
 	sharedInformers := informers.NewSharedInformerFactory(k8sClient, 10*time.Minute)
+	if len(namespaces) > 0 {
+		registerNamespacedInformers(sharedInformers, k8sClient, namespaces, resources)
+	}
 
 	api := &API{
 		Client:          k8sClient,
@@ -65,52 +90,83 @@ func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
 		switch resource {
 		case CM:
 			api.cm = sharedInformers.Core().V1().ConfigMaps()
-			api.syncChecks = append(api.syncChecks, api.cm.Informer().HasSynced)
+			api.addInformer("configmap", api.cm.Informer())
 		case Deploy:
 			api.deploy = sharedInformers.Apps().V1beta2().Deployments()
-			api.syncChecks = append(api.syncChecks, api.deploy.Informer().HasSynced)
+			api.addInformer("deployment", api.deploy.Informer())
 		case Endpoint:
 			api.endpoint = sharedInformers.Core().V1().Endpoints()
-			api.syncChecks = append(api.syncChecks, api.endpoint.Informer().HasSynced)
+			api.addInformer("endpoints", api.endpoint.Informer())
+		case Job:
+			api.job = sharedInformers.Batch().V1().Jobs()
+			api.addInformer("job", api.job.Informer())
 		case NS:
 			api.ns = sharedInformers.Core().V1().Namespaces()
-			api.syncChecks = append(api.syncChecks, api.ns.Informer().HasSynced)
+			api.addInformer("namespace", api.ns.Informer())
+		case Node:
+			api.node = sharedInformers.Core().V1().Nodes()
+			api.addInformer("node", api.node.Informer())
 		case Pod:
 			api.pod = sharedInformers.Core().V1().Pods()
-			api.syncChecks = append(api.syncChecks, api.pod.Informer().HasSynced)
+			err := api.pod.Informer().AddIndexers(cache.Indexers{podLabelIndex: indexPodByLabels})
+			if err != nil {
+				log.Fatalf("failed to add the pod label index: %s", err)
+			}
+			api.addInformer("pod", api.pod.Informer())
 		case RC:
 			api.rc = sharedInformers.Core().V1().ReplicationControllers()
-			api.syncChecks = append(api.syncChecks, api.rc.Informer().HasSynced)
+			api.addInformer("replicationcontroller", api.rc.Informer())
 		case RS:
 			api.rs = sharedInformers.Apps().V1beta2().ReplicaSets()
-			api.syncChecks = append(api.syncChecks, api.rs.Informer().HasSynced)
+			api.addInformer("replicaset", api.rs.Informer())
 		case Svc:
 			api.svc = sharedInformers.Core().V1().Services()
-			api.syncChecks = append(api.syncChecks, api.svc.Informer().HasSynced)
+			api.addInformer("service", api.svc.Informer())
 		}
 	}
 
 	return api
 }
 
-// Sync waits for all informers to be synced.
+// Sync waits for all informers to be synced, and exits if they aren't
+// within defaultSyncTimeout.
 // For servers, call this asynchronously.
 // For testing, call this synchronously.
 func (api *API) Sync(readyCh chan<- struct{}) {
+	if err := api.SyncWithTimeout(defaultSyncTimeout); err != nil {
+		log.Fatal(err)
+	}
+
+	if readyCh != nil {
+		close(readyCh)
+	}
+}
+
+// SyncWithTimeout starts the informers and waits for them to be synced,
+// returning an error if they aren't within timeout.
+func (api *API) SyncWithTimeout(timeout time.Duration) error {
 	api.sharedInformers.Start(nil)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	log.Infof("waiting for caches to sync")
 	if !cache.WaitForCacheSync(ctx.Done(), api.syncChecks...) {
-		log.Fatal("failed to sync caches")
+		return fmt.Errorf("failed to sync caches within %s", timeout)
 	}
 	log.Infof("caches synced")
+	return nil
+}
 
-	if readyCh != nil {
-		close(readyCh)
+// HasSynced returns true once the caches of all informers are synced, e.g. for
+// the readiness probes of the control plane components.
+func (api *API) HasSynced() bool {
+	for _, synced := range api.syncChecks {
+		if !synced() {
+			return false
+		}
 	}
+	return true
 }
 
 func (api *API) NS() coreinformers.NamespaceInformer {
@@ -120,6 +176,13 @@ func (api *API) NS() coreinformers.NamespaceInformer {
 	return api.ns
 }
 
+func (api *API) Node() coreinformers.NodeInformer {
+	if api.node == nil {
+		panic("Node informer not configured")
+	}
+	return api.node
+}
+
 func (api *API) Deploy() appinformers.DeploymentInformer {
 	if api.deploy == nil {
 		panic("Deploy informer not configured")
@@ -134,6 +197,13 @@ func (api *API) RS() appinformers.ReplicaSetInformer {
 	return api.rs
 }
 
+func (api *API) Job() batchinformers.JobInformer {
+	if api.job == nil {
+		panic("Job informer not configured")
+	}
+	return api.job
+}
+
 func (api *API) Pod() coreinformers.PodInformer {
 	if api.pod == nil {
 		panic("Pod informer not configured")
@@ -169,6 +239,17 @@ func (api *API) CM() coreinformers.ConfigMapInformer {
 	return api.cm
 }
 
+// GetNodeTopology returns the zone and region of the node, as labeled by the
+// cloud provider. They're empty if the node isn't labeled, or doesn't exist.
+func (api *API) GetNodeTopology(nodeName string) (string, string) {
+	node, err := api.Node().Lister().Get(nodeName)
+	if err != nil {
+		log.Debugf("failed to get node %s: %s", nodeName, err)
+		return "", ""
+	}
+	return node.Labels[k8s.NodeZoneLabel], node.Labels[k8s.NodeRegionLabel]
+}
+
 // GetObjects returns a list of Kubernetes objects, given a namespace, type, and name.
 // If namespace is an empty string, match objects in all namespaces.
 // If name is an empty string, match all objects of the given type.
@@ -194,51 +275,91 @@ func (api *API) GetObjects(namespace, restype, name string) ([]runtime.Object, e
 // references from the Kubernetes API. The kind is represented as the Kubernetes
 // singular resource type (e.g. deployment, daemonset, job, etc.)
 func (api *API) GetOwnerKindAndName(pod *apiv1.Pod) (string, string) {
-	if len(pod.GetOwnerReferences()) != 1 {
-		return "pod", pod.Name
-	}
+	owner := k8s.GetPodOwner(pod, api.getOwnerReferences)
+	return owner.Kind, owner.Name
+}
 
-	parent := pod.GetOwnerReferences()[0]
-	if parent.Kind == "ReplicaSet" {
-		rs, err := api.RS().Lister().ReplicaSets(pod.Namespace).Get(parent.Name)
-		if err != nil || len(rs.GetOwnerReferences()) != 1 {
-			return strings.ToLower(parent.Kind), parent.Name
+// GetPodOwnerObjects returns the owners of a pod that the API watches, from
+// its direct owner up its owner chain, e.g. its ReplicaSet and then the
+// Deployment of the ReplicaSet. The walk stops at the first owner whose
+// informer isn't configured or that can't be retrieved.
+func (api *API) GetPodOwnerObjects(pod *apiv1.Pod) []metav1.Object {
+	owners := []metav1.Object{}
+	k8s.GetPodOwner(pod, func(kind, namespace, name string) ([]metav1.OwnerReference, error) {
+		obj, err := api.getOwner(kind, namespace, name)
+		if err != nil || obj == nil {
+			return nil, err
 		}
-		rsParent := rs.GetOwnerReferences()[0]
-		return strings.ToLower(rsParent.Kind), rsParent.Name
+		owners = append(owners, obj)
+		return obj.GetOwnerReferences(), nil
+	})
+	return owners
+}
+
+// getOwnerReferences returns the owner references of the object of the given
+// kind, namespace and name from the informer of its kind, so that the owners
+// of any workload the API watches are walked up to, e.g. the CronJob of a
+// Job. It returns no references for the kinds whose informer isn't
+// configured, whose owners are reported as is.
+func (api *API) getOwnerReferences(kind, namespace, name string) ([]metav1.OwnerReference, error) {
+	obj, err := api.getOwner(kind, namespace, name)
+	if err != nil || obj == nil {
+		return nil, err
 	}
+	return obj.GetOwnerReferences(), nil
+}
 
-	return strings.ToLower(parent.Kind), parent.Name
+// getOwner returns the object of the given kind, namespace and name from the
+// informer of its kind, or nil if the informer of its kind isn't configured.
+func (api *API) getOwner(kind, namespace, name string) (metav1.Object, error) {
+	var obj metav1.Object
+	var err error
+	switch {
+	case kind == "Deployment" && api.deploy != nil:
+		obj, err = api.deploy.Lister().Deployments(namespace).Get(name)
+	case kind == "Job" && api.job != nil:
+		obj, err = api.job.Lister().Jobs(namespace).Get(name)
+	case kind == "ReplicaSet" && api.rs != nil:
+		obj, err = api.rs.Lister().ReplicaSets(namespace).Get(name)
+	case kind == "ReplicationController" && api.rc != nil:
+		obj, err = api.rc.Lister().ReplicationControllers(namespace).Get(name)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		log.Debugf("failed to get %s %s/%s: %s", kind, namespace, name, err)
+		return nil, err
+	}
+	return obj, nil
 }
 
 // GetPodsFor returns all running and pending Pods associated with a given
 // Kubernetes object. Use includeFailed to also get failed Pods
 func (api *API) GetPodsFor(obj runtime.Object, includeFailed bool) ([]*apiv1.Pod, error) {
 	var namespace string
-	var selector labels.Selector
+	var matchLabels labels.Set
 	var pods []*apiv1.Pod
 	var err error
 
 	switch typed := obj.(type) {
 	case *apiv1.Namespace:
 		namespace = typed.Name
-		selector = labels.Everything()
 
 	case *appsv1beta2.Deployment:
 		namespace = typed.Namespace
-		selector = labels.Set(typed.Spec.Selector.MatchLabels).AsSelector()
+		matchLabels = typed.Spec.Selector.MatchLabels
 
 	case *appsv1beta2.ReplicaSet:
 		namespace = typed.Namespace
-		selector = labels.Set(typed.Spec.Selector.MatchLabels).AsSelector()
+		matchLabels = typed.Spec.Selector.MatchLabels
 
 	case *apiv1.ReplicationController:
 		namespace = typed.Namespace
-		selector = labels.Set(typed.Spec.Selector).AsSelector()
+		matchLabels = typed.Spec.Selector
 
 	case *apiv1.Service:
 		namespace = typed.Namespace
-		selector = labels.Set(typed.Spec.Selector).AsSelector()
+		matchLabels = typed.Spec.Selector
 
 	case *apiv1.Pod:
 		// Special case for pods:
@@ -257,7 +378,7 @@ func (api *API) GetPodsFor(obj runtime.Object, includeFailed bool) ([]*apiv1.Pod
 	// if obj.(type) is Pod, we've already retrieved it and put it in pods
 	// for the other types, pods will still be empty
 	if len(pods) == 0 {
-		pods, err = api.Pod().Lister().Pods(namespace).List(selector)
+		pods, err = api.getPodsWithLabels(namespace, matchLabels)
 		if err != nil {
 			return nil, err
 		}
@@ -273,6 +394,58 @@ func (api *API) GetPodsFor(obj runtime.Object, includeFailed bool) ([]*apiv1.Pod
 	return allPods, nil
 }
 
+// getPodsWithLabels returns the pods of the namespace that have all of the
+// given labels, or all of its pods if no labels are given. The pods are looked
+// up in the label index, by the label that the fewest pods have.
+func (api *API) getPodsWithLabels(namespace string, matchLabels labels.Set) ([]*apiv1.Pod, error) {
+	if len(matchLabels) == 0 {
+		return api.Pod().Lister().Pods(namespace).List(labels.Everything())
+	}
+
+	indexer := api.Pod().Informer().GetIndexer()
+	var candidates []interface{}
+	for key, value := range matchLabels {
+		objs, err := indexer.ByIndex(podLabelIndex, podLabelIndexKey(namespace, key, value))
+		if err != nil {
+			return nil, err
+		}
+		if candidates == nil || len(objs) < len(candidates) {
+			candidates = objs
+		}
+		if len(candidates) == 0 {
+			break
+		}
+	}
+
+	selector := matchLabels.AsSelector()
+	pods := []*apiv1.Pod{}
+	for _, obj := range candidates {
+		pod := obj.(*apiv1.Pod)
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+func indexPodByLabels(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*apiv1.Pod)
+	if !ok {
+		return nil, fmt.Errorf("object is not a pod: %v", obj)
+	}
+
+	keys := make([]string, 0, len(pod.Labels))
+	for key, value := range pod.Labels {
+		keys = append(keys, podLabelIndexKey(pod.Namespace, key, value))
+	}
+	return keys, nil
+}
+
+func podLabelIndexKey(namespace, key, value string) string {
+	return namespace + "/" + key + "=" + value
+}
+
 func (api *API) getNamespaces(name string) ([]runtime.Object, error) {
 	var err error
 	var namespaces []*apiv1.Namespace
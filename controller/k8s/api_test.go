@@ -350,6 +350,53 @@ status:
 				err: nil,
 				k8sResInput: `
 apiVersion: v1
+kind: Service
+metadata:
+  name: emoji
+  namespace: emojivoto
+spec:
+  selector:
+    app: emoji-svc
+    version: v2`,
+				k8sResResults: []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed-v2
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    version: v2
+status:
+  phase: Running`,
+				},
+				k8sResMisc: []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed-v1
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    version: v1
+status:
+  phase: Running`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed-v2
+  namespace: other
+  labels:
+    app: emoji-svc
+    version: v2
+status:
+  phase: Running`,
+				},
+			},
+			getPodsForExpected{
+				err: nil,
+				k8sResInput: `
+apiVersion: v1
 kind: Pod
 metadata:
   name: emojivoto-meshed
@@ -471,6 +518,31 @@ metadata:
     kind: Job
     name: slow-cooker`,
 		},
+		{
+			expectedOwnerKind: "cronjob",
+			expectedOwnerName: "backup",
+			podConfig: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: backup-1545868800-x7k2p
+  namespace: default
+  ownerReferences:
+  - apiVersion: batch/v1
+    kind: Job
+    name: backup-1545868800`,
+			extraConfigs: []string{`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: backup-1545868800
+  namespace: default
+  ownerReferences:
+  - apiVersion: batch/v1beta1
+    kind: CronJob
+    name: backup`,
+			},
+		},
 		{
 			expectedOwnerKind: "replicationcontroller",
 			expectedOwnerName: "web",
@@ -1,7 +1,12 @@
 package k8s
 
 import (
+	spv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	splitv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/trafficsplit/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	// Load all the auth plugins for the cloud providers.
@@ -9,20 +14,52 @@ import (
 )
 
 func NewClientSet(kubeConfig string) (*kubernetes.Clientset, error) {
-	var config *rest.Config
-	var err error
+	config, err := getConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+// NewTrafficSplitClient returns a REST client for the TrafficSplit custom
+// resource.
+func NewTrafficSplitClient(kubeConfig string) (*rest.RESTClient, error) {
+	return newCustomResourceClient(kubeConfig, splitv1alpha1.SchemeGroupVersion)
+}
+
+// NewServiceProfileClient returns a REST client for the ServiceProfile custom
+// resource.
+func NewServiceProfileClient(kubeConfig string) (*rest.RESTClient, error) {
+	return newCustomResourceClient(kubeConfig, spv1alpha1.SchemeGroupVersion)
+}
+
+// newCustomResourceClient returns a REST client for the custom resources of
+// the group version, whose types are registered with the scheme of the
+// client-go clientset.
+func newCustomResourceClient(kubeConfig string, groupVersion schema.GroupVersion) (*rest.RESTClient, error) {
+	config, err := getConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
 
+	config.GroupVersion = &groupVersion
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return rest.RESTClientFor(config)
+}
+
+func getConfig(kubeConfig string) (*rest.Config, error) {
 	if kubeConfig == "" {
 		// configure client while running inside the k8s cluster
 		// uses Service Acct token mounted in the Pod
-		config, err = rest.InClusterConfig()
-	} else {
-		// configure access to the cluster from outside
-		config, err = clientcmd.BuildConfigFromFlags("", kubeConfig)
-	}
-	if err != nil {
-		return nil, err
+		return rest.InClusterConfig()
 	}
 
-	return kubernetes.NewForConfig(config)
+	// configure access to the cluster from outside
+	return clientcmd.BuildConfigFromFlags("", kubeConfig)
 }
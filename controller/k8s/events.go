@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// EventRecorder emits Kubernetes Events on the objects affected by the
+// actions of a control plane component, so that they're shown by
+// `kubectl describe` and `kubectl get events`.
+type EventRecorder struct {
+	client    kubernetes.Interface
+	component string
+}
+
+// NewEventRecorder returns an EventRecorder whose events are reported as
+// emitted by the given component.
+func NewEventRecorder(client kubernetes.Interface, component string) *EventRecorder {
+	return &EventRecorder{client: client, component: component}
+}
+
+// Event emits an event of the given type, v1.EventTypeNormal or
+// v1.EventTypeWarning, on the object. The reason is a short CamelCase
+// identifier of the action, and the message its human-readable description.
+// Events are best-effort: failures to emit them are only logged.
+func (r *EventRecorder) Event(obj runtime.Object, eventType, reason, message string) {
+	ref, err := objectReference(obj)
+	if err != nil {
+		log.Errorf("failed to reference object for %s event: %s", reason, err)
+		return
+	}
+	r.EventOnReference(ref, eventType, reason, message)
+}
+
+// Eventf is like Event, with a message formatted according to a format
+// specifier.
+func (r *EventRecorder) Eventf(obj runtime.Object, eventType, reason, format string, args ...interface{}) {
+	r.Event(obj, eventType, reason, fmt.Sprintf(format, args...))
+}
+
+// EventOnReference emits an event on the referenced object, for objects that
+// aren't at hand, such as the owners of the pods being created.
+func (r *EventRecorder) EventOnReference(ref *v1.ObjectReference, eventType, reason, message string) {
+	// events of cluster-scoped objects are created in the default namespace
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         v1.EventSource{Component: r.component},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	if _, err := r.client.CoreV1().Events(namespace).Create(event); err != nil {
+		log.Errorf("failed to emit %s event on %s %s/%s: %s", reason, ref.Kind, ref.Namespace, ref.Name, err)
+	}
+}
+
+// objectReference returns a reference to an object. The objects returned by
+// listers and clients have no type metadata, so their kind is looked up in the
+// scheme of the client-go clientset, where the custom resources of Linkerd
+// are registered as well.
+func objectReference(obj runtime.Object) (*v1.ObjectReference, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		gvk = gvks[0]
+	}
+	apiVersion, kind := gvk.ToAPIVersionAndKind()
+
+	return &v1.ObjectReference{
+		APIVersion:      apiVersion,
+		Kind:            kind,
+		Namespace:       accessor.GetNamespace(),
+		Name:            accessor.GetName(),
+		UID:             accessor.GetUID(),
+		ResourceVersion: accessor.GetResourceVersion(),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEventRecorder(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	recorder := NewEventRecorder(client, "linkerd-test")
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-deployment-tls-linkerd-io", Namespace: "emojivoto", UID: "1234"},
+	}
+	recorder.Eventf(secret, v1.EventTypeNormal, "IssuedCertificate", "Issued certificate for %s", "web")
+	recorder.EventOnReference(&v1.ObjectReference{Kind: "Node", Name: "node-1"}, v1.EventTypeWarning, "Rejected", "Rejected node")
+
+	events, err := client.CoreV1().Events("emojivoto").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events.Items))
+	}
+
+	event := events.Items[0]
+	expectedRef := v1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  "emojivoto",
+		Name:       "web-deployment-tls-linkerd-io",
+		UID:        "1234",
+	}
+	if event.InvolvedObject != expectedRef {
+		t.Fatalf("Expected event on %+v, got %+v", expectedRef, event.InvolvedObject)
+	}
+	if event.Reason != "IssuedCertificate" || event.Message != "Issued certificate for web" || event.Type != v1.EventTypeNormal {
+		t.Fatalf("Unexpected event: %s %s: %s", event.Type, event.Reason, event.Message)
+	}
+	if event.Source.Component != "linkerd-test" || event.Count != 1 {
+		t.Fatalf("Unexpected event source or count: %+v, %d", event.Source, event.Count)
+	}
+
+	events, err = client.CoreV1().Events(metav1.NamespaceDefault).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(events.Items) != 1 || events.Items[0].InvolvedObject.Name != "node-1" {
+		t.Fatalf("Expected the event of the node in the default namespace, got %+v", events.Items)
+	}
+}
@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	informerSynced = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_informer_synced",
+			Help: "Whether the cache of the informer of a resource is synced with the Kubernetes API.",
+		},
+		[]string{"resource"},
+	)
+
+	informerLastEvent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "k8s_informer_last_event_timestamp_seconds",
+			Help: "Timestamp of the last event received by the informer of a resource.",
+		},
+		[]string{"resource"},
+	)
+
+	informerEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "k8s_informer_events_total",
+			Help: "A counter for the add, update and delete events received by the informer of a resource.",
+		},
+		[]string{"resource", "event"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(informerSynced, informerLastEvent, informerEvents)
+}
+
+// addInformer registers an informer whose cache has to be synced for the API
+// to be synced, and records the freshness of its cache in the metrics of the
+// given resource.
+func (api *API) addInformer(resource string, informer cache.SharedInformer) {
+	synced := informerSynced.WithLabelValues(resource)
+	synced.Set(0)
+	api.syncChecks = append(api.syncChecks, func() bool {
+		if !informer.HasSynced() {
+			return false
+		}
+		synced.Set(1)
+		return true
+	})
+
+	lastEvent := informerLastEvent.WithLabelValues(resource)
+	record := func(event string) {
+		informerEvents.WithLabelValues(resource, event).Inc()
+		lastEvent.Set(float64(time.Now().Unix()))
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { record("add") },
+		UpdateFunc: func(interface{}, interface{}) { record("update") },
+		DeleteFunc: func(interface{}) { record("delete") },
+	})
+}
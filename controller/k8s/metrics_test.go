@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func gaugeValue(t *testing.T, resource string) float64 {
+	metric := &dto.Metric{}
+	if err := informerSynced.WithLabelValues(resource).Write(metric); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return metric.GetGauge().GetValue()
+}
+
+func TestInformerMetrics(t *testing.T) {
+	api, err := NewFakeAPI(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emoji-6bf9f47bd5-ktj8d
+  namespace: emojivoto`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	if api.HasSynced() {
+		t.Fatal("Expected the API not to be synced before it's started")
+	}
+	if value := gaugeValue(t, "pod"); value != 0 {
+		t.Fatalf("Expected the pod informer not to be reported as synced, got %f", value)
+	}
+
+	if err := api.SyncWithTimeout(defaultSyncTimeout); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if !api.HasSynced() {
+		t.Fatal("Expected the API to be synced")
+	}
+	if value := gaugeValue(t, "pod"); value != 1 {
+		t.Fatalf("Expected the pod informer to be reported as synced, got %f", value)
+	}
+}
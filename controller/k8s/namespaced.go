@@ -0,0 +1,225 @@
+package k8s
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ParseNamespaces splits a comma-separated list of namespaces, as passed to
+// the -watch-namespaces flag of the controller components.
+func ParseNamespaces(namespaces string) []string {
+	parsed := []string{}
+	for _, ns := range strings.Split(namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			parsed = append(parsed, ns)
+		}
+	}
+	return parsed
+}
+
+// registerNamespacedInformers registers informers that only list and watch
+// the given namespaces with the factory, for each resource. The informers of
+// the factory are created once per type, so these replace its default
+// informers, which watch all namespaces, and only require the permissions of
+// Roles in each namespace.
+func registerNamespacedInformers(factory informers.SharedInformerFactory, client kubernetes.Interface, namespaces []string, resources []ApiResource) {
+	for _, resource := range resources {
+		var obj runtime.Object
+		var restClient cache.Getter
+		var name string
+
+		switch resource {
+		case CM:
+			obj, restClient, name = &apiv1.ConfigMap{}, client.CoreV1().RESTClient(), "configmaps"
+		case Deploy:
+			obj, restClient, name = &appsv1beta2.Deployment{}, client.AppsV1beta2().RESTClient(), "deployments"
+		case Endpoint:
+			obj, restClient, name = &apiv1.Endpoints{}, client.CoreV1().RESTClient(), "endpoints"
+		case Job:
+			obj, restClient, name = &batchv1.Job{}, client.BatchV1().RESTClient(), "jobs"
+		case NS:
+			obj, restClient, name = &apiv1.Namespace{}, client.CoreV1().RESTClient(), "namespaces"
+		case Pod:
+			obj, restClient, name = &apiv1.Pod{}, client.CoreV1().RESTClient(), "pods"
+		case RC:
+			obj, restClient, name = &apiv1.ReplicationController{}, client.CoreV1().RESTClient(), "replicationcontrollers"
+		case RS:
+			obj, restClient, name = &appsv1beta2.ReplicaSet{}, client.AppsV1beta2().RESTClient(), "replicasets"
+		case Svc:
+			obj, restClient, name = &apiv1.Service{}, client.CoreV1().RESTClient(), "services"
+		default:
+			continue
+		}
+
+		lw := &multiNamespaceListWatch{}
+		for _, ns := range namespaces {
+			if resource == NS {
+				// namespaces aren't namespaced, so only the watched ones are
+				// selected by name
+				lw.listWatches = append(lw.listWatches, cache.NewListWatchFromClient(restClient, name, "", fields.OneTermEqualSelector("metadata.name", ns)))
+			} else {
+				lw.listWatches = append(lw.listWatches, cache.NewListWatchFromClient(restClient, name, ns, fields.Everything()))
+			}
+		}
+
+		factory.InformerFor(obj, func(_ kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return cache.NewSharedIndexInformer(lw, obj, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		})
+	}
+}
+
+// newNamespacedListWatch returns a ListerWatcher of a namespaced resource of
+// the REST client, in the given namespaces, or in all namespaces if none are
+// given.
+func newNamespacedListWatch(client cache.Getter, resource string, namespaces []string) cache.ListerWatcher {
+	if len(namespaces) == 0 {
+		return cache.NewListWatchFromClient(client, resource, metav1.NamespaceAll, fields.Everything())
+	}
+
+	lw := &multiNamespaceListWatch{}
+	for _, ns := range namespaces {
+		lw.listWatches = append(lw.listWatches, cache.NewListWatchFromClient(client, resource, ns, fields.Everything()))
+	}
+	return lw
+}
+
+// multiNamespaceListWatch lists and watches a resource in several namespaces,
+// with one ListerWatcher per namespace. The resource versions of the lists of
+// each namespace are kept, so that each namespace is watched from its own
+// list. Since the events of the namespaces are interleaved, the resource
+// version of the last event doesn't tell which events of the other namespaces
+// were seen, so the informer has to list the resource again once a watch
+// ends.
+type multiNamespaceListWatch struct {
+	listWatches []cache.ListerWatcher
+
+	sync.Mutex
+	versions []string
+}
+
+func (lw *multiNamespaceListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	var list runtime.Object
+	items := []runtime.Object{}
+	versions := make([]string, len(lw.listWatches))
+
+	for i, listWatch := range lw.listWatches {
+		l, err := listWatch.List(options)
+		if err != nil {
+			return nil, err
+		}
+		objs, err := meta.ExtractList(l)
+		if err != nil {
+			return nil, err
+		}
+		listMeta, err := meta.ListAccessor(l)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, objs...)
+		versions[i] = listMeta.GetResourceVersion()
+		list = l
+	}
+
+	if err := meta.SetList(list, items); err != nil {
+		return nil, err
+	}
+
+	lw.Lock()
+	lw.versions = versions
+	lw.Unlock()
+	return list, nil
+}
+
+func (lw *multiNamespaceListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.Lock()
+	versions := lw.versions
+	lw.versions = nil
+	lw.Unlock()
+
+	if versions == nil {
+		// the previous watch ended; io.EOF makes the informer list again
+		return nil, io.EOF
+	}
+
+	watchers := []watch.Interface{}
+	for i, listWatch := range lw.listWatches {
+		opts := options
+		opts.ResourceVersion = versions[i]
+		w, err := listWatch.Watch(opts)
+		if err != nil {
+			for _, w := range watchers {
+				w.Stop()
+			}
+			return nil, err
+		}
+		watchers = append(watchers, w)
+	}
+
+	return newMultiWatch(watchers), nil
+}
+
+// multiWatch merges the events of several watches. It stops when any of them
+// ends, so that the informer can start over.
+type multiWatch struct {
+	watchers []watch.Interface
+	result   chan watch.Event
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newMultiWatch(watchers []watch.Interface) *multiWatch {
+	mw := &multiWatch{
+		watchers: watchers,
+		result:   make(chan watch.Event),
+		stopped:  make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range watchers {
+		wg.Add(1)
+		go func(w watch.Interface) {
+			defer wg.Done()
+			defer mw.Stop()
+			for event := range w.ResultChan() {
+				select {
+				case mw.result <- event:
+				case <-mw.stopped:
+					return
+				}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(mw.result)
+	}()
+
+	return mw
+}
+
+func (mw *multiWatch) Stop() {
+	mw.stopOnce.Do(func() {
+		close(mw.stopped)
+		for _, w := range mw.watchers {
+			w.Stop()
+		}
+	})
+}
+
+func (mw *multiWatch) ResultChan() <-chan watch.Event {
+	return mw.result
+}
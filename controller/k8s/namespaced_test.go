@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestParseNamespaces(t *testing.T) {
+	expected := []string{"emojivoto", "books"}
+	if namespaces := ParseNamespaces(" emojivoto,,books "); !reflect.DeepEqual(namespaces, expected) {
+		t.Fatalf("Expected namespaces %v, got %v", expected, namespaces)
+	}
+	if namespaces := ParseNamespaces(""); len(namespaces) != 0 {
+		t.Fatalf("Expected no namespaces, got %v", namespaces)
+	}
+}
+
+func TestMultiNamespaceListWatch(t *testing.T) {
+	watchers := map[string]*watch.FakeWatcher{}
+	watchedVersions := map[string]string{}
+
+	namespaceListWatch := func(ns, version string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return &apiv1.PodList{
+					ListMeta: metav1.ListMeta{ResourceVersion: version},
+					Items:    []apiv1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "pod"}}},
+				}, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				watchedVersions[ns] = options.ResourceVersion
+				watchers[ns] = watch.NewFake()
+				return watchers[ns], nil
+			},
+		}
+	}
+
+	lw := &multiNamespaceListWatch{
+		listWatches: []cache.ListerWatcher{
+			namespaceListWatch("emojivoto", "10"),
+			namespaceListWatch("books", "20"),
+		},
+	}
+
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	pods := list.(*apiv1.PodList).Items
+	if len(pods) != 2 || pods[0].Namespace != "emojivoto" || pods[1].Namespace != "books" {
+		t.Fatalf("Expected the pods of both namespaces, got %v", pods)
+	}
+
+	w, err := lw.Watch(metav1.ListOptions{ResourceVersion: "20"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedVersions := map[string]string{"emojivoto": "10", "books": "20"}
+	if !reflect.DeepEqual(watchedVersions, expectedVersions) {
+		t.Fatalf("Expected each namespace to be watched from its list, got %v", watchedVersions)
+	}
+
+	pod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "books", Name: "new-pod"}}
+	go watchers["books"].Add(pod)
+	if event := <-w.ResultChan(); event.Type != watch.Added || event.Object != pod {
+		t.Fatalf("Expected the pod to be added, got %v", event)
+	}
+
+	watchers["emojivoto"].Stop()
+	if _, ok := <-w.ResultChan(); ok {
+		t.Fatal("Expected the watch to end with the watch of a namespace")
+	}
+
+	if _, err := lw.Watch(metav1.ListOptions{}); err != io.EOF {
+		t.Fatalf("Expected io.EOF before listing again, got %v", err)
+	}
+}
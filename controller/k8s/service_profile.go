@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"time"
+
+	spv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+)
+
+func init() {
+	// the ServiceProfile types are registered with the scheme of the
+	// client-go clientset, so that they are decoded by its codecs
+	if err := spv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to register the ServiceProfile types: %s", err)
+	}
+}
+
+// NewServiceProfileListWatch returns a ListerWatcher of the ServiceProfiles of
+// the given namespaces, or of all namespaces if none are given, for the
+// ServiceProfile REST client.
+func NewServiceProfileListWatch(client cache.Getter, namespaces []string) cache.ListerWatcher {
+	return newNamespacedListWatch(client, "serviceprofiles", namespaces)
+}
+
+// ServiceProfilesServed returns true if the Kubernetes API serves the
+// ServiceProfiles, i.e. if their CustomResourceDefinition is installed. The
+// informer of the ServiceProfiles never syncs otherwise.
+func ServiceProfilesServed(client kubernetes.Interface) (bool, error) {
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(spv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if resources == nil {
+		return false, nil
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == "serviceprofiles" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// WithServiceProfiles configures the API with an informer of the
+// ServiceProfiles listed and watched by lw. It must be called before the API
+// is synced.
+func (api *API) WithServiceProfiles(lw cache.ListerWatcher) *API {
+	obj := &spv1alpha1.ServiceProfile{}
+	api.sp = api.sharedInformers.InformerFor(obj, func(_ kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(lw, obj, resync, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})
+	})
+	api.addInformer("serviceprofile", api.sp)
+	return api
+}
+
+// HasServiceProfiles returns true if the API is configured with an informer
+// of the ServiceProfiles.
+func (api *API) HasServiceProfiles() bool {
+	return api.sp != nil
+}
+
+func (api *API) SP() cache.SharedIndexInformer {
+	if api.sp == nil {
+		panic("SP informer not configured")
+	}
+	return api.sp
+}
+
+// GetServiceProfile returns the ServiceProfile with the given namespace and
+// name, or nil if it doesn't exist or the API isn't configured with an
+// informer of the ServiceProfiles.
+func (api *API) GetServiceProfile(namespace, name string) (*spv1alpha1.ServiceProfile, error) {
+	if !api.HasServiceProfiles() {
+		return nil, nil
+	}
+	obj, exists, err := api.SP().GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return obj.(*spv1alpha1.ServiceProfile), nil
+}
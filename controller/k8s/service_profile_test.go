@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServiceProfilesServed(t *testing.T) {
+	t.Run("Returns true if the ServiceProfiles are served", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		client.Resources = []*metav1.APIResourceList{
+			{
+				GroupVersion: "linkerd.io/v1alpha1",
+				APIResources: []metav1.APIResource{{Name: "serviceprofiles", Namespaced: true, Kind: "ServiceProfile"}},
+			},
+		}
+
+		served, err := ServiceProfilesServed(client)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !served {
+			t.Fatal("Expected the ServiceProfiles to be served")
+		}
+	})
+
+	t.Run("Returns false if the CRD isn't installed", func(t *testing.T) {
+		served, err := ServiceProfilesServed(fake.NewSimpleClientset())
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if served {
+			t.Fatal("Expected the ServiceProfiles not to be served")
+		}
+	})
+}
+
+func TestGetServiceProfileWithoutInformer(t *testing.T) {
+	api := NewAPI(fake.NewSimpleClientset(), Svc)
+
+	profile, err := api.GetServiceProfile("booksapp", "books.booksapp.svc.cluster.local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if profile != nil {
+		t.Fatalf("Expected no profile, got %v", profile)
+	}
+}
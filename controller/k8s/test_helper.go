@@ -1,9 +1,14 @@
 package k8s
 
 import (
+	spv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	splitv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/trafficsplit/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 )
 
 func toRuntimeObject(config string) (runtime.Object, error) {
@@ -14,24 +19,53 @@ func toRuntimeObject(config string) (runtime.Object, error) {
 
 func NewFakeAPI(configs ...string) (*API, error) {
 	objs := []runtime.Object{}
+	splits := []splitv1alpha1.TrafficSplit{}
+	profiles := []spv1alpha1.ServiceProfile{}
 	for _, config := range configs {
 		obj, err := toRuntimeObject(config)
 		if err != nil {
 			return nil, err
 		}
-		objs = append(objs, obj)
+		// custom resources aren't served by the fake clientset
+		switch typed := obj.(type) {
+		case *splitv1alpha1.TrafficSplit:
+			splits = append(splits, *typed)
+		case *spv1alpha1.ServiceProfile:
+			profiles = append(profiles, *typed)
+		default:
+			objs = append(objs, obj)
+		}
 	}
 
 	clientSet := fake.NewSimpleClientset(objs...)
-	return NewAPI(
+	api := NewAPI(
 		clientSet,
 		CM,
 		Deploy,
 		Endpoint,
+		Job,
 		NS,
+		Node,
 		Pod,
 		RC,
 		RS,
 		Svc,
-	), nil
+	)
+
+	api.WithTrafficSplits(fakeListWatch(&splitv1alpha1.TrafficSplitList{Items: splits}))
+	api.WithServiceProfiles(fakeListWatch(&spv1alpha1.ServiceProfileList{Items: profiles}))
+	return api, nil
+}
+
+// fakeListWatch returns a ListerWatcher that lists the objects of the list,
+// and never sends any event.
+func fakeListWatch(list runtime.Object) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return list, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
 }
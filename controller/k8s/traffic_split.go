@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	splitv1alpha1 "github.com/linkerd/linkerd2/controller/gen/apis/trafficsplit/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+)
+
+// trafficSplitServiceIndex is the name of the index of the TrafficSplit
+// informer that maps each split service, prefixed with its namespace, to its
+// TrafficSplits.
+const trafficSplitServiceIndex = "service"
+
+func init() {
+	// the TrafficSplit types are registered with the scheme of the client-go
+	// clientset, so that they are decoded by its codecs
+	if err := splitv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to register the TrafficSplit types: %s", err)
+	}
+}
+
+// NewTrafficSplitListWatch returns a ListerWatcher of the TrafficSplits of the
+// given namespaces, or of all namespaces if none are given, for the
+// TrafficSplit REST client.
+func NewTrafficSplitListWatch(client cache.Getter, namespaces []string) cache.ListerWatcher {
+	return newNamespacedListWatch(client, "trafficsplits", namespaces)
+}
+
+// WithTrafficSplits configures the API with an informer of the TrafficSplits
+// listed and watched by lw. It must be called before the API is synced.
+func (api *API) WithTrafficSplits(lw cache.ListerWatcher) *API {
+	obj := &splitv1alpha1.TrafficSplit{}
+	api.ts = api.sharedInformers.InformerFor(obj, func(_ kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(lw, obj, resync, cache.Indexers{
+			cache.NamespaceIndex:     cache.MetaNamespaceIndexFunc,
+			trafficSplitServiceIndex: indexTrafficSplitByService,
+		})
+	})
+	api.addInformer("trafficsplit", api.ts)
+	return api
+}
+
+func (api *API) TS() cache.SharedIndexInformer {
+	if api.ts == nil {
+		panic("TS informer not configured")
+	}
+	return api.ts
+}
+
+// GetTrafficSplits returns the TrafficSplits of a service, sorted by name.
+func (api *API) GetTrafficSplits(namespace, service string) ([]*splitv1alpha1.TrafficSplit, error) {
+	objs, err := api.TS().GetIndexer().ByIndex(trafficSplitServiceIndex, namespace+"/"+service)
+	if err != nil {
+		return nil, err
+	}
+
+	splits := make([]*splitv1alpha1.TrafficSplit, 0, len(objs))
+	for _, obj := range objs {
+		splits = append(splits, obj.(*splitv1alpha1.TrafficSplit))
+	}
+	sort.Slice(splits, func(i, j int) bool {
+		return splits[i].Name < splits[j].Name
+	})
+	return splits, nil
+}
+
+func indexTrafficSplitByService(obj interface{}) ([]string, error) {
+	split, ok := obj.(*splitv1alpha1.TrafficSplit)
+	if !ok {
+		return nil, fmt.Errorf("object is not a TrafficSplit: %v", obj)
+	}
+	return []string{split.Namespace + "/" + split.Spec.Service}, nil
+}
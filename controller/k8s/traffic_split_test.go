@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestGetTrafficSplits(t *testing.T) {
+	api, err := NewFakeAPI(`
+apiVersion: split.linkerd.io/v1alpha1
+kind: TrafficSplit
+metadata:
+  name: web-rollout
+  namespace: ns
+spec:
+  service: web
+  backends:
+  - service: web-v1
+    weight: 900m
+  - service: web-v2
+    weight: 100m`, `
+apiVersion: split.linkerd.io/v1alpha1
+kind: TrafficSplit
+metadata:
+  name: api-rollout
+  namespace: ns
+spec:
+  service: api
+  backends:
+  - service: api-v2
+    weight: 1`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	api.Sync(nil)
+
+	splits, err := api.GetTrafficSplits("ns", "web")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(splits) != 1 || splits[0].Name != "web-rollout" {
+		t.Fatalf("Expected the web-rollout TrafficSplit, got %v", splits)
+	}
+
+	backends := splits[0].Spec.Backends
+	if len(backends) != 2 || backends[0].Service != "web-v1" || backends[0].Weight.MilliValue() != 900 {
+		t.Fatalf("Unexpected backends: %v", backends)
+	}
+
+	splits, err = api.GetTrafficSplits("other", "web")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(splits) != 0 {
+		t.Fatalf("Expected no TrafficSplits, got %v", splits)
+	}
+}
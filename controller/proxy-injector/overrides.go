@@ -0,0 +1,178 @@
+package injector
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+)
+
+// validateProxyConfigOverrides checks the proxy configuration recorded in a
+// pod's annotations, which may have been edited by hand, so that the proxy
+// injected with it can start.
+func validateProxyConfigOverrides(annotations map[string]string) error {
+	validators := []struct {
+		annotation string
+		validate   func(value string) error
+	}{
+		{pkgK8s.ProxyImageAnnotation, validateImageName},
+		{pkgK8s.ProxyLogLevelAnnotation, validateLogLevel},
+		{pkgK8s.ProxyControlPlaneAddressAnnotation, validateHostPort},
+		{pkgK8s.ProxyInboundPortAnnotation, validatePort},
+		{pkgK8s.ProxyOutboundPortAnnotation, validatePort},
+		{pkgK8s.ProxyTraceCollectorAnnotation, validateHostPort},
+		{pkgK8s.ProxyTracePropagationAnnotation, validateTracePropagation},
+	}
+
+	for _, v := range validators {
+		value, ok := annotations[v.annotation]
+		if !ok {
+			continue
+		}
+		if err := v.validate(value); err != nil {
+			return fmt.Errorf("invalid %s annotation %q: %s", v.annotation, value, err)
+		}
+	}
+	return nil
+}
+
+func validateImageName(image string) error {
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return errors.New("must be an image name")
+	}
+	// the tag is the version of the proxy of the sidecar config
+	if pkgK8s.ImageTag(image) != "" || pkgK8s.ImageDigest(image) != "" {
+		return errors.New("must be an image name without a tag or digest")
+	}
+	return nil
+}
+
+func validateLogLevel(level string) error {
+	if level == "" || strings.ContainsAny(level, " \t\n") {
+		return errors.New("must be a log level, such as \"info\" or \"warn,linkerd2_proxy=debug\"")
+	}
+	return nil
+}
+
+func validateHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return errors.New("must be of the form host:port")
+	}
+	return validatePort(port)
+}
+
+func validatePort(port string) error {
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return errors.New("must be a port number between 1 and 65535")
+	}
+	return nil
+}
+
+func validateTracePropagation(propagation string) error {
+	if propagation != pkgK8s.TracePropagationB3 && propagation != pkgK8s.TracePropagationW3C {
+		return fmt.Errorf("must be %q or %q", pkgK8s.TracePropagationB3, pkgK8s.TracePropagationW3C)
+	}
+	return nil
+}
+
+// applyProxyConfigOverrides updates the sidecar config with the proxy
+// configuration recorded in a pod's annotations by `linkerd inject`. The
+// annotations are also set on the sidecar config, so that they take
+// precedence over the ones rendered by `linkerd install`. The overrides must
+// have been validated by validateProxyConfigOverrides.
+func applyProxyConfigOverrides(annotations map[string]string, sidecar *v1.Pod) {
+	override := func(annotation string, apply func(value string)) {
+		value, ok := annotations[annotation]
+		if !ok {
+			return
+		}
+		if sidecar.Annotations == nil {
+			sidecar.Annotations = map[string]string{}
+		}
+		sidecar.Annotations[annotation] = value
+		apply(value)
+	}
+
+	for i := range sidecar.Spec.Containers {
+		proxy := &sidecar.Spec.Containers[i]
+		if proxy.Name != pkgK8s.ProxyContainerName {
+			continue
+		}
+
+		override(pkgK8s.ProxyImageAnnotation, func(image string) {
+			// the image is recorded without its tag, which is the version of the
+			// proxy of the sidecar config; the digest that the sidecar config's
+			// image may be pinned by doesn't apply to other images
+			if tag := pkgK8s.ImageTag(proxy.Image); tag != "" {
+				image += ":" + tag
+			}
+			proxy.Image = image
+		})
+		override(pkgK8s.ProxyLogLevelAnnotation, func(level string) {
+			setEnv(proxy, "LINKERD2_PROXY_LOG", level)
+		})
+		override(pkgK8s.ProxyControlPlaneAddressAnnotation, func(addr string) {
+			setEnv(proxy, "LINKERD2_PROXY_CONTROL_URL", fmt.Sprintf("tcp://%s", addr))
+		})
+		override(pkgK8s.ProxyInboundPortAnnotation, func(port string) {
+			setEnv(proxy, "LINKERD2_PROXY_PUBLIC_LISTENER", fmt.Sprintf("tcp://0.0.0.0:%s", port))
+			containerPort, err := strconv.ParseInt(port, 10, 32)
+			if err != nil {
+				return
+			}
+			for j := range proxy.Ports {
+				if proxy.Ports[j].Name == "linkerd-proxy" {
+					proxy.Ports[j].ContainerPort = int32(containerPort)
+				}
+			}
+		})
+		override(pkgK8s.ProxyOutboundPortAnnotation, func(port string) {
+			setEnv(proxy, "LINKERD2_PROXY_PRIVATE_LISTENER", fmt.Sprintf("tcp://127.0.0.1:%s", port))
+		})
+		override(pkgK8s.ProxyTraceCollectorAnnotation, func(addr string) {
+			setEnv(proxy, "LINKERD2_PROXY_TRACE_COLLECTOR_SVC_ADDR", addr)
+		})
+		override(pkgK8s.ProxyTracePropagationAnnotation, func(propagation string) {
+			setEnv(proxy, "LINKERD2_PROXY_TRACE_PROPAGATION", propagation)
+		})
+	}
+
+	for i := range sidecar.Spec.InitContainers {
+		initContainer := &sidecar.Spec.InitContainers[i]
+		if initContainer.Name != pkgK8s.InitContainerName {
+			continue
+		}
+
+		override(pkgK8s.ProxyInboundPortAnnotation, func(port string) {
+			setArg(initContainer, "--incoming-proxy-port", port)
+		})
+		override(pkgK8s.ProxyOutboundPortAnnotation, func(port string) {
+			setArg(initContainer, "--outgoing-proxy-port", port)
+		})
+	}
+}
+
+func setEnv(container *v1.Container, name, value string) {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			container.Env[i].Value = value
+			return
+		}
+	}
+	container.Env = append(container.Env, v1.EnvVar{Name: name, Value: value})
+}
+
+func setArg(container *v1.Container, flag, value string) {
+	for i := 0; i < len(container.Args)-1; i++ {
+		if container.Args[i] == flag {
+			container.Args[i+1] = value
+			return
+		}
+	}
+	container.Args = append(container.Args, flag, value)
+}
@@ -0,0 +1,19 @@
+package injector
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/linkerd/linkerd2/controller/webhook"
+)
+
+// NewWebhookServer returns a TLS server that serves the webhook to the
+// Kubernetes API server, which calls it through the MutatingWebhookConfiguration
+// created by `linkerd install --proxy-auto-inject`.
+func NewWebhookServer(addr string, w *Webhook, cert tls.Certificate) *http.Server {
+	return webhook.NewServer(addr, w, cert)
+}
+
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	webhook.Handler(w.Mutate).ServeHTTP(rw, req)
+}
@@ -0,0 +1,327 @@
+package injector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// OwnerNamePlaceholder and OwnerKindPlaceholder stand for the name and kind
+	// of the owner of an injected pod in the sidecar config, where they make up
+	// the TLS identity of the proxy.
+	OwnerNamePlaceholder = "__OWNER_NAME__"
+	OwnerKindPlaceholder = "__OWNER_KIND__"
+)
+
+// ownerLabels are the labels identifying the owner of an injected pod, as set
+// by `linkerd inject`.
+var ownerLabels = map[string]string{
+	"cronjob":               pkgK8s.ProxyCronJobLabel,
+	"daemonset":             pkgK8s.ProxyDaemonSetLabel,
+	"deployment":            pkgK8s.ProxyDeploymentLabel,
+	"job":                   pkgK8s.ProxyJobLabel,
+	"replicaset":            pkgK8s.ProxyReplicaSetLabel,
+	"replicationcontroller": pkgK8s.ProxyReplicationControllerLabel,
+	"statefulset":           pkgK8s.ProxyStatefulSetLabel,
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Webhook adds the proxy to the pods created in namespaces, or with pod
+// annotations, that enable injection. The proxy and its init container are
+// taken from the sidecar config, a pod rendered by `linkerd install` whose
+// labels, annotations, containers, init containers and volumes are added to
+// the injected pods.
+type Webhook struct {
+	k8sAPI              *k8s.API
+	recorder            *k8s.EventRecorder
+	controllerNamespace string
+	tlsMode             string
+	sidecarConfig       string
+	// identityMode is true if the proxies of the sidecar config are certified
+	// by the identity service.
+	identityMode bool
+}
+
+// NewWebhook returns a Webhook injecting the sidecar config, which is the YAML
+// representation of a pod. The cluster-wide TLS mode applies to the
+// namespaces without a TLSModeAnnotation.
+func NewWebhook(k8sAPI *k8s.API, controllerNamespace, tlsMode, sidecarConfig string) (*Webhook, error) {
+	if tlsMode != pkgK8s.TLSModePermissive && tlsMode != pkgK8s.TLSModeStrict {
+		return nil, fmt.Errorf("invalid TLS mode %q, must be one of: %s, %s", tlsMode, pkgK8s.TLSModePermissive, pkgK8s.TLSModeStrict)
+	}
+
+	var pod v1.Pod
+	if err := yaml.Unmarshal([]byte(sidecarConfig), &pod); err != nil {
+		return nil, fmt.Errorf("invalid sidecar config: %s", err)
+	}
+
+	return &Webhook{
+		k8sAPI:              k8sAPI,
+		recorder:            k8s.NewEventRecorder(k8sAPI.Client, "linkerd-proxy-injector"),
+		controllerNamespace: controllerNamespace,
+		tlsMode:             tlsMode,
+		sidecarConfig:       sidecarConfig,
+		identityMode:        pod.Annotations[pkgK8s.IdentityModeAnnotation] == pkgK8s.IdentityModeServiceAccount,
+	}, nil
+}
+
+// Mutate returns the response to an admission request, patching the pod to
+// be created if the proxy should be injected into it. Pods are admitted even
+// if they can't be injected, except in namespaces in strict TLS mode, whose
+// proxies must be certified by the identity service, and except if their
+// proxy configuration overrides are invalid, since the proxy wouldn't start.
+// The injection, or the reason why it's skipped, failed or rejected, is
+// recorded in an event on the pod's owner, unless the request is a dry run.
+func (w *Webhook) Mutate(req *admissionV1beta1.AdmissionRequest, dryRun bool) *admissionV1beta1.AdmissionResponse {
+	rsp := &admissionV1beta1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+	}
+
+	var pod v1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		log.Errorf("failed to decode pod in admission request %s: %s", req.UID, err)
+		rsp.Result = &metaV1.Status{Message: err.Error()}
+		return rsp
+	}
+	// pods created by controllers don't have a namespace until they're
+	// admitted
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+
+	inject, reason := w.shouldInject(&pod)
+	if !inject {
+		log.Debugf("skipping injection of pod %s/%s%s", pod.Namespace, pod.Name, pod.GenerateName)
+		if reason != "" {
+			w.event(&pod, dryRun, v1.EventTypeNormal, "InjectionSkipped",
+				fmt.Sprintf("Skipped the injection of the proxy into pod %s%s: %s", pod.Name, pod.GenerateName, reason))
+		}
+		return rsp
+	}
+
+	if !w.identityMode && w.namespaceTLSMode(&pod, dryRun) == pkgK8s.TLSModeStrict {
+		err := fmt.Errorf("namespace %s is in %s TLS mode, which requires the proxies to be certified by the identity service", pod.Namespace, pkgK8s.TLSModeStrict)
+		return w.reject(rsp, &pod, dryRun, err)
+	}
+	if err := validateProxyConfigOverrides(pod.Annotations); err != nil {
+		return w.reject(rsp, &pod, dryRun, err)
+	}
+
+	patch, err := w.patch(&pod)
+	if err != nil {
+		log.Errorf("failed to inject pod %s/%s%s: %s", pod.Namespace, pod.Name, pod.GenerateName, err)
+		w.event(&pod, dryRun, v1.EventTypeWarning, "InjectionFailed",
+			fmt.Sprintf("Failed to inject the proxy into pod %s%s: %s", pod.Name, pod.GenerateName, err))
+		rsp.Result = &metaV1.Status{Message: err.Error()}
+		return rsp
+	}
+	w.event(&pod, dryRun, v1.EventTypeNormal, "Injected",
+		fmt.Sprintf("Injected the proxy into pod %s%s", pod.Name, pod.GenerateName))
+
+	patchType := admissionV1beta1.PatchTypeJSONPatch
+	rsp.Patch = patch
+	rsp.PatchType = &patchType
+	return rsp
+}
+
+// reject denies the admission of the pod for the reason err, which is recorded
+// in an event on the pod's owner.
+func (w *Webhook) reject(rsp *admissionV1beta1.AdmissionResponse, pod *v1.Pod, dryRun bool, err error) *admissionV1beta1.AdmissionResponse {
+	log.Errorf("rejecting pod %s/%s%s: %s", pod.Namespace, pod.Name, pod.GenerateName, err)
+	w.event(pod, dryRun, v1.EventTypeWarning, "InjectionRejected",
+		fmt.Sprintf("Rejected pod %s%s: %s", pod.Name, pod.GenerateName, err))
+	rsp.Allowed = false
+	rsp.Result = &metaV1.Status{Message: err.Error()}
+	return rsp
+}
+
+// shouldInject returns true if injection is enabled for a pod, either by its
+// own annotation or, unless the pod or its workload disables it, by its
+// namespace's. The control plane, pods using the host's network and pods that
+// already have a proxy are never injected: if injection is enabled for such a
+// pod, the reason why it's skipped is returned as well.
+func (w *Webhook) shouldInject(pod *v1.Pod) (bool, string) {
+	if pod.Namespace == w.controllerNamespace || !w.injectionEnabled(pod) {
+		return false, ""
+	}
+
+	if pod.Spec.HostNetwork {
+		return false, "pods using the host's network can't be injected"
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == pkgK8s.ProxyContainerName {
+			return false, "the pod already has a proxy"
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == pkgK8s.InitContainerName {
+			return false, "the pod already has a proxy init container"
+		}
+	}
+	return true, ""
+}
+
+// injectionEnabled returns true if the annotation of the pod, or else of its
+// namespace, enables injection, and the pod's workload doesn't disable it.
+func (w *Webhook) injectionEnabled(pod *v1.Pod) bool {
+	switch pod.Annotations[pkgK8s.ProxyInjectAnnotation] {
+	case pkgK8s.ProxyInjectEnabled:
+		return true
+	case pkgK8s.ProxyInjectDisabled:
+		return false
+	}
+
+	if w.workloadDisablesInjection(pod) {
+		return false
+	}
+
+	ns, err := w.k8sAPI.NS().Lister().Get(pod.Namespace)
+	if err != nil {
+		log.Errorf("failed to get namespace %s: %s", pod.Namespace, err)
+		return false
+	}
+	return ns.Annotations[pkgK8s.ProxyInjectAnnotation] == pkgK8s.ProxyInjectEnabled
+}
+
+// namespaceTLSMode returns the TLS mode of the pod's namespace. A namespace
+// whose TLS mode can't be determined is strict, and an invalid
+// TLSModeAnnotation is recorded in a warning event on the pod's owner.
+func (w *Webhook) namespaceTLSMode(pod *v1.Pod, dryRun bool) string {
+	ns, err := w.k8sAPI.NS().Lister().Get(pod.Namespace)
+	if err != nil {
+		log.Errorf("failed to get namespace %s: %s", pod.Namespace, err)
+		return pkgK8s.TLSModeStrict
+	}
+
+	mode, err := pkgK8s.NamespaceTLSMode(ns, w.tlsMode)
+	if err != nil {
+		log.Errorf("injecting pod %s/%s%s in %s mode: %s", pod.Namespace, pod.Name, pod.GenerateName, mode, err)
+		w.event(pod, dryRun, v1.EventTypeWarning, "InvalidTLSMode",
+			fmt.Sprintf("Injecting pod %s%s in %s mode: %s", pod.Name, pod.GenerateName, mode, err))
+	}
+	return mode
+}
+
+// workloadDisablesInjection returns true if any owner in the owner chain of a
+// pod disables injection, e.g. its ReplicaSet or the Deployment of the
+// ReplicaSet, or its Job.
+func (w *Webhook) workloadDisablesInjection(pod *v1.Pod) bool {
+	for _, owner := range w.k8sAPI.GetPodOwnerObjects(pod) {
+		if owner.GetAnnotations()[pkgK8s.ProxyInjectAnnotation] == pkgK8s.ProxyInjectDisabled {
+			return true
+		}
+	}
+	return false
+}
+
+// event records an event about the injection of the pod on its owner, unless
+// the admission request is a dry run, which must not have side effects.
+func (w *Webhook) event(pod *v1.Pod, dryRun bool, eventType, reason, message string) {
+	if dryRun {
+		return
+	}
+	w.recorder.EventOnReference(podEventReference(pod), eventType, reason, message)
+}
+
+// podEventReference returns a reference to the object that the events about
+// the injection of a pod are emitted on. Pods aren't created yet when they're
+// injected, so these are emitted on the pod's controller, or its only owner.
+func podEventReference(pod *v1.Pod) *v1.ObjectReference {
+	for _, owner := range pod.OwnerReferences {
+		if (owner.Controller != nil && *owner.Controller) || len(pod.OwnerReferences) == 1 {
+			return &v1.ObjectReference{
+				APIVersion: owner.APIVersion,
+				Kind:       owner.Kind,
+				Namespace:  pod.Namespace,
+				Name:       owner.Name,
+				UID:        owner.UID,
+			}
+		}
+	}
+	return &v1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+	}
+}
+
+// patch returns the JSON patch adding the sidecar config to a pod.
+func (w *Webhook) patch(pod *v1.Pod) ([]byte, error) {
+	ownerKind, ownerName := w.k8sAPI.GetOwnerKindAndName(pod)
+	if ownerName == "" {
+		// pods without owners may only have a generated name prefix
+		ownerName = strings.TrimSuffix(pod.GenerateName, "-")
+	}
+
+	config := strings.NewReplacer(
+		OwnerNamePlaceholder, ownerName,
+		OwnerKindPlaceholder, ownerKind,
+	).Replace(w.sidecarConfig)
+
+	var sidecar v1.Pod
+	if err := yaml.Unmarshal([]byte(config), &sidecar); err != nil {
+		return nil, err
+	}
+	applyProxyConfigOverrides(pod.Annotations, &sidecar)
+
+	labels := map[string]string{}
+	for k, v := range pod.Labels {
+		labels[k] = v
+	}
+	for k, v := range sidecar.Labels {
+		labels[k] = v
+	}
+	if label, ok := ownerLabels[ownerKind]; ok {
+		labels[label] = ownerName
+	}
+
+	annotations := map[string]string{}
+	for k, v := range pod.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range sidecar.Annotations {
+		annotations[k] = v
+	}
+
+	// "add" operations replace the values of existing fields
+	patch := []patchOperation{
+		{Op: "add", Path: "/metadata/labels", Value: labels},
+		{Op: "add", Path: "/metadata/annotations", Value: annotations},
+		{Op: "add", Path: "/spec/containers", Value: append(pod.Spec.Containers, sidecar.Spec.Containers...)},
+	}
+	// the sidecar config has no init container when the iptables of pods are
+	// configured by the linkerd CNI plugin
+	if len(sidecar.Spec.InitContainers) > 0 {
+		patch = append(patch, patchOperation{
+			Op: "add", Path: "/spec/initContainers", Value: append(pod.Spec.InitContainers, sidecar.Spec.InitContainers...),
+		})
+	}
+	if len(sidecar.Spec.Volumes) > 0 {
+		patch = append(patch, patchOperation{
+			Op: "add", Path: "/spec/volumes", Value: append(pod.Spec.Volumes, sidecar.Spec.Volumes...),
+		})
+	}
+	if len(sidecar.Spec.ReadinessGates) > 0 {
+		patch = append(patch, patchOperation{
+			Op: "add", Path: "/spec/readinessGates", Value: append(pod.Spec.ReadinessGates, sidecar.Spec.ReadinessGates...),
+		})
+	}
+
+	return json.Marshal(patch)
+}
@@ -0,0 +1,574 @@
+package injector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const sidecarConfig = `
+metadata:
+  annotations:
+    linkerd.io/proxy-version: testinjectversion
+  labels:
+    linkerd.io/control-plane-ns: linkerd
+spec:
+  containers:
+  - name: linkerd-proxy
+    image: gcr.io/linkerd-io/proxy:testinjectversion
+  initContainers:
+  - name: linkerd-init
+    image: gcr.io/linkerd-io/proxy-init:testinjectversion
+  volumes:
+  - name: linkerd-secrets
+    secret:
+      secretName: __OWNER_NAME__-__OWNER_KIND__-tls-linkerd-io
+`
+
+type decodedPatch map[string]json.RawMessage
+
+func newWebhook(t *testing.T) *Webhook {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto
+  annotations:
+    linkerd.io/inject: enabled
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: books
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: vault
+  annotations:
+    linkerd.io/inject: enabled
+    linkerd.io/tls-mode: strict
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: typo
+  annotations:
+    linkerd.io/inject: enabled
+    linkerd.io/tls-mode: stirct
+`, `
+apiVersion: apps/v1beta2
+kind: ReplicaSet
+metadata:
+  name: web-dead-beef
+  namespace: emojivoto
+  ownerReferences:
+  - apiVersion: apps/v1beta2
+    kind: Deployment
+    name: web
+`, `
+apiVersion: apps/v1beta2
+kind: ReplicaSet
+metadata:
+  name: legacy-dead-beef
+  namespace: emojivoto
+  annotations:
+    linkerd.io/inject: disabled
+  ownerReferences:
+  - apiVersion: apps/v1beta2
+    kind: Deployment
+    name: legacy
+`, `
+apiVersion: apps/v1beta2
+kind: ReplicaSet
+metadata:
+  name: batch-dead-beef
+  namespace: emojivoto
+  ownerReferences:
+  - apiVersion: apps/v1beta2
+    kind: Deployment
+    name: batch
+`, `
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: batch
+  namespace: emojivoto
+  annotations:
+    linkerd.io/inject: disabled
+`, `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: emojivoto
+  annotations:
+    linkerd.io/inject: disabled
+`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+
+	webhook, err := NewWebhook(k8sAPI, "linkerd", pkgK8s.TLSModePermissive, sidecarConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return webhook
+}
+
+func admissionRequest(t *testing.T, pod *v1.Pod) *admissionV1beta1.AdmissionRequest {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return &admissionV1beta1.AdmissionRequest{
+		UID:       "123",
+		Namespace: pod.Namespace,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func mutate(t *testing.T, webhook *Webhook, pod *v1.Pod) decodedPatch {
+	rsp := webhook.Mutate(admissionRequest(t, pod), false)
+	if !rsp.Allowed || rsp.UID != "123" {
+		t.Fatalf("Expected pod to be allowed, got %+v", rsp)
+	}
+	if rsp.Patch == nil {
+		return nil
+	}
+
+	var operations []struct {
+		Op    string
+		Path  string
+		Value json.RawMessage
+	}
+	if err := json.Unmarshal(rsp.Patch, &operations); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	patch := decodedPatch{}
+	for _, op := range operations {
+		if op.Op != "add" {
+			t.Fatalf("Unexpected patch operation: %s", op.Op)
+		}
+		patch[op.Path] = op.Value
+	}
+	return patch
+}
+
+func (p decodedPatch) decode(t *testing.T, path string, value interface{}) {
+	raw, ok := p[path]
+	if !ok {
+		t.Fatalf("Expected %s to be patched, got %v", path, p)
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func appPod(namespace string, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metaV1.ObjectMeta{
+			GenerateName: "web-dead-beef-",
+			Namespace:    namespace,
+			Labels:       map[string]string{"app": "web-svc"},
+			Annotations:  annotations,
+			OwnerReferences: []metaV1.OwnerReference{
+				{APIVersion: "apps/v1beta2", Kind: "ReplicaSet", Name: "web-dead-beef"},
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "web-svc", Image: "buoyantio/emojivoto-web:v3"}},
+		},
+	}
+}
+
+func TestMutate(t *testing.T) {
+	webhook := newWebhook(t)
+
+	t.Run("Injects pods in namespaces that enable injection", func(t *testing.T) {
+		patch := mutate(t, webhook, appPod("emojivoto", nil))
+		if patch == nil {
+			t.Fatal("Expected pod to be injected")
+		}
+
+		var labels map[string]string
+		patch.decode(t, "/metadata/labels", &labels)
+		expectedLabels := map[string]string{
+			"app":                       "web-svc",
+			pkgK8s.ControllerNSLabel:    "linkerd",
+			pkgK8s.ProxyDeploymentLabel: "web",
+		}
+		if !reflect.DeepEqual(labels, expectedLabels) {
+			t.Fatalf("Expected labels %v, got %v", expectedLabels, labels)
+		}
+
+		var annotations map[string]string
+		patch.decode(t, "/metadata/annotations", &annotations)
+		if annotations[pkgK8s.ProxyVersionAnnotation] != "testinjectversion" {
+			t.Fatalf("Expected proxy version annotation, got %v", annotations)
+		}
+
+		var containers []v1.Container
+		patch.decode(t, "/spec/containers", &containers)
+		if len(containers) != 2 || containers[0].Name != "web-svc" || containers[1].Name != pkgK8s.ProxyContainerName {
+			t.Fatalf("Expected the proxy to be added to the containers, got %+v", containers)
+		}
+
+		var initContainers []v1.Container
+		patch.decode(t, "/spec/initContainers", &initContainers)
+		if len(initContainers) != 1 || initContainers[0].Name != pkgK8s.InitContainerName {
+			t.Fatalf("Expected the init container to be added, got %+v", initContainers)
+		}
+
+		var volumes []v1.Volume
+		patch.decode(t, "/spec/volumes", &volumes)
+		if len(volumes) != 1 || volumes[0].Secret.SecretName != "web-deployment-tls-linkerd-io" {
+			t.Fatalf("Expected the secret of the pod owner's identity to be mounted, got %+v", volumes)
+		}
+	})
+
+	t.Run("Injects pods that enable injection in other namespaces", func(t *testing.T) {
+		pod := appPod("books", map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectEnabled})
+		pod.OwnerReferences = nil
+
+		patch := mutate(t, webhook, pod)
+		if patch == nil {
+			t.Fatal("Expected pod to be injected")
+		}
+
+		var volumes []v1.Volume
+		patch.decode(t, "/spec/volumes", &volumes)
+		if volumes[0].Secret.SecretName != "web-dead-beef-pod-tls-linkerd-io" {
+			t.Fatalf("Expected the secret of the pod's identity to be mounted, got %+v", volumes)
+		}
+	})
+
+	t.Run("Applies the proxy configuration overrides of pods", func(t *testing.T) {
+		pod := appPod("emojivoto", map[string]string{
+			pkgK8s.ProxyImageAnnotation:          "example.com/linkerd/proxy",
+			pkgK8s.ProxyLogLevelAnnotation:       "debug",
+			pkgK8s.ProxyInboundPortAnnotation:    "5143",
+			pkgK8s.ProxyTraceCollectorAnnotation: "oc-collector.tracing:55678",
+		})
+
+		patch := mutate(t, webhook, pod)
+		if patch == nil {
+			t.Fatal("Expected pod to be injected")
+		}
+
+		var containers []v1.Container
+		patch.decode(t, "/spec/containers", &containers)
+		proxy := containers[1]
+		if proxy.Image != "example.com/linkerd/proxy:testinjectversion" {
+			t.Fatalf("Expected the proxy image to be overridden, got %s", proxy.Image)
+		}
+		expectedEnv := []v1.EnvVar{
+			{Name: "LINKERD2_PROXY_LOG", Value: "debug"},
+			{Name: "LINKERD2_PROXY_PUBLIC_LISTENER", Value: "tcp://0.0.0.0:5143"},
+			{Name: "LINKERD2_PROXY_TRACE_COLLECTOR_SVC_ADDR", Value: "oc-collector.tracing:55678"},
+		}
+		if !reflect.DeepEqual(proxy.Env, expectedEnv) {
+			t.Fatalf("Expected proxy env %v, got %v", expectedEnv, proxy.Env)
+		}
+
+		var initContainers []v1.Container
+		patch.decode(t, "/spec/initContainers", &initContainers)
+		expectedArgs := []string{"--incoming-proxy-port", "5143"}
+		if !reflect.DeepEqual(initContainers[0].Args, expectedArgs) {
+			t.Fatalf("Expected init args %v, got %v", expectedArgs, initContainers[0].Args)
+		}
+
+		var annotations map[string]string
+		patch.decode(t, "/metadata/annotations", &annotations)
+		if annotations[pkgK8s.ProxyLogLevelAnnotation] != "debug" {
+			t.Fatalf("Expected the overrides to be kept in the annotations, got %v", annotations)
+		}
+	})
+
+	t.Run("Doesn't add an init container when the CNI plugin is enabled", func(t *testing.T) {
+		// `linkerd install --linkerd-cni-enabled` renders no init container
+		cniSidecarConfig := strings.Replace(sidecarConfig, `  initContainers:
+  - name: linkerd-init
+    image: gcr.io/linkerd-io/proxy-init:testinjectversion
+`, "", 1)
+		cniWebhook, err := NewWebhook(webhook.k8sAPI, "linkerd", pkgK8s.TLSModePermissive, cniSidecarConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		patch := mutate(t, cniWebhook, appPod("emojivoto", nil))
+		if patch == nil {
+			t.Fatal("Expected pod to be injected")
+		}
+		if _, ok := patch["/spec/initContainers"]; ok {
+			t.Fatalf("Expected no init container to be added, got %v", patch)
+		}
+	})
+
+	skipped := []struct {
+		desc string
+		pod  *v1.Pod
+	}{
+		{"pods in namespaces that don't enable injection", appPod("books", nil)},
+		{"pods that disable injection", appPod("emojivoto", map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectDisabled})},
+		{"control plane pods", appPod("linkerd", map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectEnabled})},
+	}
+
+	injected := appPod("emojivoto", nil)
+	injected.Spec.Containers = append(injected.Spec.Containers, v1.Container{Name: pkgK8s.ProxyContainerName})
+	skipped = append(skipped, struct {
+		desc string
+		pod  *v1.Pod
+	}{"pods that already have a proxy", injected})
+
+	legacy := appPod("emojivoto", nil)
+	legacy.OwnerReferences[0].Name = "legacy-dead-beef"
+	skipped = append(skipped, struct {
+		desc string
+		pod  *v1.Pod
+	}{"pods of workloads that disable injection", legacy})
+
+	batch := appPod("emojivoto", nil)
+	batch.OwnerReferences[0].Name = "batch-dead-beef"
+	skipped = append(skipped, struct {
+		desc string
+		pod  *v1.Pod
+	}{"pods of Deployments that disable injection", batch})
+
+	migrate := appPod("emojivoto", nil)
+	migrate.OwnerReferences[0].APIVersion = "batch/v1"
+	migrate.OwnerReferences[0].Kind = "Job"
+	migrate.OwnerReferences[0].Name = "migrate"
+	skipped = append(skipped, struct {
+		desc string
+		pod  *v1.Pod
+	}{"pods of Jobs that disable injection", migrate})
+
+	hostNetwork := appPod("emojivoto", nil)
+	hostNetwork.Spec.HostNetwork = true
+	skipped = append(skipped, struct {
+		desc string
+		pod  *v1.Pod
+	}{"pods using the host's network", hostNetwork})
+
+	for _, tc := range skipped {
+		t.Run("Skips "+tc.desc, func(t *testing.T) {
+			if patch := mutate(t, webhook, tc.pod); patch != nil {
+				t.Fatalf("Expected pod not to be injected, got %v", patch)
+			}
+		})
+	}
+}
+
+func TestMutateEvents(t *testing.T) {
+	webhook := newWebhook(t)
+
+	hostNetwork := appPod("emojivoto", nil)
+	hostNetwork.Spec.HostNetwork = true
+	for _, pod := range []*v1.Pod{
+		appPod("emojivoto", nil),
+		hostNetwork,
+		// pods that aren't enabled for injection aren't recorded
+		appPod("books", nil),
+	} {
+		mutate(t, webhook, pod)
+	}
+
+	events, err := webhook.k8sAPI.Client.CoreV1().Events("").List(metaV1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(events.Items) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events.Items), events.Items)
+	}
+
+	reasons := map[string]string{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != "ReplicaSet" || event.InvolvedObject.Name != "web-dead-beef" {
+			t.Fatalf("Expected event on the pod's ReplicaSet, got %+v", event.InvolvedObject)
+		}
+		if event.Source.Component != "linkerd-proxy-injector" {
+			t.Fatalf("Expected event from the proxy injector, got %s", event.Source.Component)
+		}
+		reasons[event.Reason] = event.Message
+	}
+
+	if _, ok := reasons["Injected"]; !ok {
+		t.Fatalf("Expected an Injected event, got %v", reasons)
+	}
+	expected := "Skipped the injection of the proxy into pod web-dead-beef-: pods using the host's network can't be injected"
+	if reasons["InjectionSkipped"] != expected {
+		t.Fatalf("Expected InjectionSkipped event [%s], got [%s]", expected, reasons["InjectionSkipped"])
+	}
+}
+
+func TestMutateTLSMode(t *testing.T) {
+	webhook := newWebhook(t)
+	identitySidecarConfig := strings.Replace(sidecarConfig, "    linkerd.io/proxy-version: testinjectversion\n",
+		"    linkerd.io/proxy-version: testinjectversion\n    "+pkgK8s.IdentityModeAnnotation+": "+pkgK8s.IdentityModeServiceAccount+"\n", 1)
+	identityWebhook, err := NewWebhook(webhook.k8sAPI, "linkerd", pkgK8s.TLSModePermissive, identitySidecarConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	strictWebhook, err := NewWebhook(webhook.k8sAPI, "linkerd", pkgK8s.TLSModeStrict, sidecarConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	testCases := []struct {
+		desc      string
+		webhook   *Webhook
+		namespace string
+		allowed   bool
+		reasons   []string
+	}{
+		{"Injects pods in permissive namespaces", webhook, "emojivoto", true, []string{"Injected"}},
+		{"Rejects pods in strict namespaces", webhook, "vault", false, []string{"InjectionRejected"}},
+		{"Rejects pods in namespaces with an invalid TLS mode", webhook, "typo", false, []string{"InjectionRejected", "InvalidTLSMode"}},
+		{"Rejects pods in namespaces without a TLS mode in strict mode", strictWebhook, "emojivoto", false, []string{"InjectionRejected"}},
+		{"Injects pods certified by the identity service in strict namespaces", identityWebhook, "vault", true, []string{"Injected"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			client := tc.webhook.k8sAPI.Client.CoreV1().Events(tc.namespace)
+			before, err := client.List(metaV1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			rsp := tc.webhook.Mutate(admissionRequest(t, appPod(tc.namespace, nil)), false)
+			if rsp.Allowed != tc.allowed {
+				t.Fatalf("Expected allowed to be %t, got %+v", tc.allowed, rsp)
+			}
+			if !tc.allowed && (rsp.Patch != nil || !strings.Contains(rsp.Result.Message, "strict TLS mode")) {
+				t.Fatalf("Expected the pod to be rejected for the strict TLS mode, got %+v", rsp)
+			}
+
+			events, err := client.List(metaV1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			var reasons []string
+			for _, event := range events.Items[len(before.Items):] {
+				if event.InvolvedObject.Kind != "ReplicaSet" || event.InvolvedObject.Name != "web-dead-beef" {
+					t.Fatalf("Expected event on the pod's ReplicaSet, got %+v", event.InvolvedObject)
+				}
+				reasons = append(reasons, event.Reason)
+			}
+			sort.Strings(reasons)
+			if !reflect.DeepEqual(reasons, tc.reasons) {
+				t.Fatalf("Expected events %v, got %v", tc.reasons, reasons)
+			}
+		})
+	}
+
+	t.Run("Rejects pods with invalid proxy configuration overrides", func(t *testing.T) {
+		invalid := map[string]string{
+			pkgK8s.ProxyImageAnnotation:               "example.com/linkerd/proxy:v1",
+			pkgK8s.ProxyLogLevelAnnotation:            "",
+			pkgK8s.ProxyControlPlaneAddressAnnotation: "proxy-api.linkerd.svc.cluster.local",
+			pkgK8s.ProxyInboundPortAnnotation:         "70000",
+			pkgK8s.ProxyOutboundPortAnnotation:        "outbound",
+			pkgK8s.ProxyTraceCollectorAnnotation:      "collector:0",
+			pkgK8s.ProxyTracePropagationAnnotation:    "zipkin",
+		}
+		for annotation, value := range invalid {
+			pod := appPod("emojivoto", map[string]string{annotation: value})
+			rsp := webhook.Mutate(admissionRequest(t, pod), false)
+			if rsp.Allowed || rsp.Patch != nil {
+				t.Fatalf("Expected the pod with %s=%q to be rejected, got %+v", annotation, value, rsp)
+			}
+			if !strings.Contains(rsp.Result.Message, annotation) {
+				t.Fatalf("Expected the error to name the %s annotation, got %q", annotation, rsp.Result.Message)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid cluster-wide TLS modes", func(t *testing.T) {
+		if _, err := NewWebhook(webhook.k8sAPI, "linkerd", "stirct", sidecarConfig); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	webhook := newWebhook(t)
+
+	t.Run("Responds to admission reviews", func(t *testing.T) {
+		review := admissionV1beta1.AdmissionReview{Request: admissionRequest(t, appPod("emojivoto", nil))}
+		body, err := json.Marshal(review)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		rsp := httptest.NewRecorder()
+		webhook.ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+		var reviewed admissionV1beta1.AdmissionReview
+		if err := json.Unmarshal(rsp.Body.Bytes(), &reviewed); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if reviewed.Response == nil || reviewed.Response.UID != "123" || reviewed.Response.Patch == nil {
+			t.Fatalf("Expected a patch for request 123, got %+v", reviewed.Response)
+		}
+	})
+
+	t.Run("Doesn't record events for dry runs", func(t *testing.T) {
+		review := admissionV1beta1.AdmissionReview{Request: admissionRequest(t, appPod("emojivoto", nil))}
+		body, err := json.Marshal(review)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		// the vendored admission API doesn't have the dryRun field
+		body = bytes.Replace(body, []byte(`"request":{`), []byte(`"request":{"dryRun":true,`), 1)
+
+		client := webhook.k8sAPI.Client.CoreV1().Events("emojivoto")
+		before, err := client.List(metaV1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		rsp := httptest.NewRecorder()
+		webhook.ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+		var reviewed admissionV1beta1.AdmissionReview
+		if err := json.Unmarshal(rsp.Body.Bytes(), &reviewed); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if reviewed.Response == nil || reviewed.Response.Patch == nil {
+			t.Fatalf("Expected a patch for the dry run, got %+v", reviewed.Response)
+		}
+
+		after, err := client.List(metaV1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(after.Items) != len(before.Items) {
+			t.Fatalf("Expected no events for the dry run, got %+v", after.Items[len(before.Items):])
+		}
+	})
+
+	t.Run("Rejects requests without an admission review", func(t *testing.T) {
+		rsp := httptest.NewRecorder()
+		webhook.ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("{}"))))
+
+		if rsp.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, rsp.Code)
+		}
+	})
+}
@@ -0,0 +1,516 @@
+package servicemirror
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// GatewayPortName is the name of the port of the gateway service on which the
+// gateway accepts the requests from the other clusters. Gateway services with
+// a single port may leave it unnamed.
+const GatewayPortName = "mc-gateway"
+
+// ServiceMirror mirrors the services of a remote cluster that are labeled with
+// the mirror.linkerd.io/exported label into the local cluster. The mirror of
+// a service is created in the namespace of the same name as the remote
+// service's, if it exists, and is named after the remote service, suffixed
+// with the name of the remote cluster. Its endpoints are the addresses of the
+// gateway of the remote cluster, which forwards the requests to the remote
+// service. The TLS identity of the gateway and the fully qualified name of the
+// remote service are recorded on the mirrored service, so that the destination
+// service secures the connections to the gateway with mTLS, and has the
+// proxies rewrite the authority of the requests to the remote service's.
+type ServiceMirror struct {
+	clusterName               string
+	gatewayName               string
+	gatewayNamespace          string
+	remoteControllerNamespace string
+	remoteClusterDomain       string
+	localAPI                  *k8s.API
+	remoteAPI                 *k8s.API
+	syncHandler               func(key string) error
+
+	// The queue is keyed on "$namespace/$name" of the remote services.
+	queue workqueue.RateLimitingInterface
+}
+
+// gateway holds the addresses, port and TLS identity of the gateway of the
+// remote cluster.
+type gateway struct {
+	addresses []string
+	port      int32
+	identity  string
+}
+
+// NewServiceMirror returns a ServiceMirror that mirrors the services of the
+// remote cluster, which are served by remoteAPI, into the local cluster, which
+// is served by localAPI. remoteAPI must be configured with the Svc resource,
+// and localAPI with the Endpoint, NS and Svc resources.
+func NewServiceMirror(
+	clusterName, gatewayName, gatewayNamespace, remoteControllerNamespace, remoteClusterDomain string,
+	localAPI, remoteAPI *k8s.API,
+) *ServiceMirror {
+	m := &ServiceMirror{
+		clusterName:               clusterName,
+		gatewayName:               gatewayName,
+		gatewayNamespace:          gatewayNamespace,
+		remoteControllerNamespace: remoteControllerNamespace,
+		remoteClusterDomain:       remoteClusterDomain,
+		localAPI:                  localAPI,
+		remoteAPI:                 remoteAPI,
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "service-mirror"),
+	}
+
+	remoteAPI.Svc().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    m.handleRemoteServiceAdd,
+			UpdateFunc: m.handleRemoteServiceUpdate,
+			DeleteFunc: m.handleRemoteServiceDelete,
+		},
+	)
+
+	// mirrored services that are deleted by hand are created again, and the
+	// services of namespaces that are created after the remote services are
+	// mirrored into them
+	localAPI.Svc().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			DeleteFunc: m.handleLocalServiceDelete,
+		},
+	)
+	localAPI.NS().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: m.handleNamespaceAdd,
+		},
+	)
+
+	m.syncHandler = m.syncService
+
+	return m
+}
+
+func (m *ServiceMirror) Run(readyCh <-chan struct{}, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer m.queue.ShutDown()
+
+	<-readyCh
+
+	log.Infof("starting service mirror of cluster %s", m.clusterName)
+	defer log.Infof("shutting down service mirror of cluster %s", m.clusterName)
+
+	// the mirrors of the services that were deleted or unexported while the
+	// service mirror wasn't running are deleted on startup
+	if err := m.enqueueMirroredServices(); err != nil {
+		log.Errorf("failed to list the mirrored services: %s", err)
+	}
+
+	go wait.Until(m.worker, time.Second, stopCh)
+
+	<-stopCh
+}
+
+func (m *ServiceMirror) worker() {
+	for m.processNextWorkItem() {
+	}
+}
+
+func (m *ServiceMirror) processNextWorkItem() bool {
+	key, quit := m.queue.Get()
+	if quit {
+		return false
+	}
+	defer m.queue.Done(key)
+
+	err := m.syncHandler(key.(string))
+	if err != nil {
+		log.Errorf("error mirroring service %s: %s", key, err)
+		m.queue.AddRateLimited(key)
+		return true
+	}
+
+	m.queue.Forget(key)
+	return true
+}
+
+func (m *ServiceMirror) syncService(key string) error {
+	log.Debugf("syncService(%s)", key)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		log.Errorf("Failed to parse service mirror sync request %s", key)
+		return nil
+	}
+
+	mirrorName := m.mirrorName(name)
+
+	remote, err := m.remoteAPI.Svc().Lister().Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) || (err == nil && !isExported(remote)) {
+		return m.deleteMirror(namespace, mirrorName)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = m.localAPI.NS().Lister().Get(namespace)
+	if apierrors.IsNotFound(err) {
+		// the service is mirrored once the namespace is created
+		log.Debugf("not mirroring %s: namespace %s doesn't exist", key, namespace)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	gw, err := m.getGateway()
+	if err != nil {
+		return err
+	}
+
+	if err := m.applyService(m.mirroredService(remote, gw)); err != nil {
+		return err
+	}
+	return m.applyEndpoints(m.mirroredEndpoints(remote, gw))
+}
+
+// getGateway returns the gateway of the remote cluster, or nil if it doesn't
+// have an address yet.
+func (m *ServiceMirror) getGateway() (*gateway, error) {
+	svc, err := m.remoteAPI.Svc().Lister().Services(m.gatewayNamespace).Get(m.gatewayName)
+	if apierrors.IsNotFound(err) {
+		log.Warnf("gateway %s.%s doesn't exist in cluster %s", m.gatewayName, m.gatewayNamespace, m.clusterName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gw := &gateway{identity: svc.Annotations[pkgK8s.GatewayIdentityAnnotation]}
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			gw.addresses = append(gw.addresses, ingress.IP)
+		}
+	}
+	gw.addresses = append(gw.addresses, svc.Spec.ExternalIPs...)
+	if len(gw.addresses) == 0 {
+		log.Warnf("gateway %s.%s of cluster %s has no external IP", m.gatewayName, m.gatewayNamespace, m.clusterName)
+		return nil, nil
+	}
+
+	for _, port := range svc.Spec.Ports {
+		if port.Name == GatewayPortName || len(svc.Spec.Ports) == 1 {
+			gw.port = port.Port
+			break
+		}
+	}
+	if gw.port == 0 {
+		return nil, fmt.Errorf("gateway %s.%s of cluster %s has no %s port", m.gatewayName, m.gatewayNamespace, m.clusterName, GatewayPortName)
+	}
+
+	return gw, nil
+}
+
+// mirroredService returns the mirror of the remote service. The ports of the
+// mirrored service target the port of the gateway.
+func (m *ServiceMirror) mirroredService(remote *v1.Service, gw *gateway) *v1.Service {
+	annotations := map[string]string{
+		pkgK8s.RemoteServiceFQNAnnotation: fmt.Sprintf("%s.%s.svc.%s", remote.Name, remote.Namespace, m.remoteClusterDomain),
+	}
+	if gw != nil && gw.identity != "" {
+		annotations[pkgK8s.RemoteGatewayIdentityAnnotation] = gw.identity
+		annotations[pkgK8s.RemoteControllerNSAnnotation] = m.remoteControllerNamespace
+	}
+
+	ports := make([]v1.ServicePort, 0, len(remote.Spec.Ports))
+	for _, port := range remote.Spec.Ports {
+		targetPort := intstr.FromInt(int(port.Port))
+		if gw != nil {
+			targetPort = intstr.FromInt(int(gw.port))
+		}
+		ports = append(ports, v1.ServicePort{
+			Name:       port.Name,
+			Protocol:   port.Protocol,
+			Port:       port.Port,
+			TargetPort: targetPort,
+		})
+	}
+
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        m.mirrorName(remote.Name),
+			Namespace:   remote.Namespace,
+			Labels:      m.mirrorLabels(),
+			Annotations: annotations,
+		},
+		Spec: v1.ServiceSpec{
+			Type:  v1.ServiceTypeClusterIP,
+			Ports: ports,
+		},
+	}
+}
+
+// mirroredEndpoints returns the endpoints of the mirror of the remote service,
+// which are the addresses of the gateway. The endpoints have no addresses if
+// the gateway has none.
+func (m *ServiceMirror) mirroredEndpoints(remote *v1.Service, gw *gateway) *v1.Endpoints {
+	endpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.mirrorName(remote.Name),
+			Namespace: remote.Namespace,
+			Labels:    m.mirrorLabels(),
+		},
+	}
+	if gw == nil {
+		return endpoints
+	}
+
+	addresses := make([]v1.EndpointAddress, 0, len(gw.addresses))
+	for _, address := range gw.addresses {
+		addresses = append(addresses, v1.EndpointAddress{IP: address})
+	}
+	ports := make([]v1.EndpointPort, 0, len(remote.Spec.Ports))
+	for _, port := range remote.Spec.Ports {
+		ports = append(ports, v1.EndpointPort{
+			Name:     port.Name,
+			Port:     gw.port,
+			Protocol: port.Protocol,
+		})
+	}
+
+	endpoints.Subsets = []v1.EndpointSubset{{Addresses: addresses, Ports: ports}}
+	return endpoints
+}
+
+func (m *ServiceMirror) applyService(svc *v1.Service) error {
+	client := m.localAPI.Client.CoreV1().Services(svc.Namespace)
+
+	existing, err := m.localAPI.Svc().Lister().Services(svc.Namespace).Get(svc.Name)
+	if apierrors.IsNotFound(err) {
+		log.Infof("creating mirrored service %s.%s", svc.Name, svc.Namespace)
+		_, err = client.Create(svc)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if !m.isMirror(existing.Labels) {
+		return fmt.Errorf("service %s.%s exists and isn't mirrored from cluster %s", svc.Name, svc.Namespace, m.clusterName)
+	}
+
+	if reflect.DeepEqual(existing.Labels, svc.Labels) &&
+		reflect.DeepEqual(existing.Annotations, svc.Annotations) &&
+		reflect.DeepEqual(existing.Spec.Ports, svc.Spec.Ports) {
+		return nil
+	}
+
+	// the cluster IP and the other defaults set by Kubernetes are kept
+	updated := existing.DeepCopy()
+	updated.Labels = svc.Labels
+	updated.Annotations = svc.Annotations
+	updated.Spec.Ports = svc.Spec.Ports
+
+	log.Infof("updating mirrored service %s.%s", svc.Name, svc.Namespace)
+	_, err = client.Update(updated)
+	return err
+}
+
+func (m *ServiceMirror) applyEndpoints(endpoints *v1.Endpoints) error {
+	client := m.localAPI.Client.CoreV1().Endpoints(endpoints.Namespace)
+
+	existing, err := m.localAPI.Endpoint().Lister().Endpoints(endpoints.Namespace).Get(endpoints.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(endpoints)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Labels, endpoints.Labels) &&
+		reflect.DeepEqual(existing.Subsets, endpoints.Subsets) {
+		return nil
+	}
+
+	updated := existing.DeepCopy()
+	updated.Labels = endpoints.Labels
+	updated.Subsets = endpoints.Subsets
+
+	log.Infof("updating endpoints of mirrored service %s.%s", endpoints.Name, endpoints.Namespace)
+	_, err = client.Update(updated)
+	return err
+}
+
+// deleteMirror deletes the mirrored service, and its endpoints, if it exists.
+func (m *ServiceMirror) deleteMirror(namespace, name string) error {
+	existing, err := m.localAPI.Svc().Lister().Services(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !m.isMirror(existing.Labels) {
+		return nil
+	}
+
+	log.Infof("deleting mirrored service %s.%s", name, namespace)
+	err = m.localAPI.Client.CoreV1().Services(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	err = m.localAPI.Client.CoreV1().Endpoints(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (m *ServiceMirror) handleRemoteServiceAdd(obj interface{}) {
+	svc := obj.(*v1.Service)
+	if m.isGateway(svc) {
+		m.enqueueExportedServices()
+		return
+	}
+	if isExported(svc) {
+		m.enqueueService(svc.Namespace, svc.Name)
+	}
+}
+
+func (m *ServiceMirror) handleRemoteServiceUpdate(oldObj, newObj interface{}) {
+	oldSvc := oldObj.(*v1.Service)
+	newSvc := newObj.(*v1.Service)
+	if m.isGateway(newSvc) {
+		m.enqueueExportedServices()
+		return
+	}
+	// services that are no longer exported are enqueued to delete their mirror
+	if isExported(oldSvc) || isExported(newSvc) {
+		m.enqueueService(newSvc.Namespace, newSvc.Name)
+	}
+}
+
+func (m *ServiceMirror) handleRemoteServiceDelete(obj interface{}) {
+	svc, ok := obj.(*v1.Service)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorf("Couldn't get object from tombstone %+v", obj)
+			return
+		}
+		svc, ok = tombstone.Obj.(*v1.Service)
+		if !ok {
+			log.Errorf("Tombstone contained object that is not a Service %+v", obj)
+			return
+		}
+	}
+	m.handleRemoteServiceAdd(svc)
+}
+
+func (m *ServiceMirror) handleLocalServiceDelete(obj interface{}) {
+	svc, ok := obj.(*v1.Service)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorf("Couldn't get object from tombstone %+v", obj)
+			return
+		}
+		svc, ok = tombstone.Obj.(*v1.Service)
+		if !ok {
+			log.Errorf("Tombstone contained object that is not a Service %+v", obj)
+			return
+		}
+	}
+	if m.isMirror(svc.Labels) {
+		m.enqueueService(svc.Namespace, m.remoteName(svc.Name))
+	}
+}
+
+func (m *ServiceMirror) handleNamespaceAdd(obj interface{}) {
+	ns := obj.(*v1.Namespace)
+	services, err := m.remoteAPI.Svc().Lister().Services(ns.Name).List(exportedSelector())
+	if err != nil {
+		log.Errorf("failed to list the exported services of namespace %s: %s", ns.Name, err)
+		return
+	}
+	for _, svc := range services {
+		m.enqueueService(svc.Namespace, svc.Name)
+	}
+}
+
+func (m *ServiceMirror) enqueueExportedServices() {
+	services, err := m.remoteAPI.Svc().Lister().List(exportedSelector())
+	if err != nil {
+		log.Errorf("failed to list the exported services: %s", err)
+		return
+	}
+	for _, svc := range services {
+		m.enqueueService(svc.Namespace, svc.Name)
+	}
+}
+
+// enqueueMirroredServices enqueues the remote services of all the services
+// mirrored from the remote cluster, and all the exported services.
+func (m *ServiceMirror) enqueueMirroredServices() error {
+	mirrors, err := m.localAPI.Svc().Lister().List(labels.SelectorFromSet(m.mirrorLabels()))
+	if err != nil {
+		return err
+	}
+	for _, svc := range mirrors {
+		m.enqueueService(svc.Namespace, m.remoteName(svc.Name))
+	}
+
+	m.enqueueExportedServices()
+	return nil
+}
+
+func (m *ServiceMirror) enqueueService(namespace, name string) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	log.Debugf("enqueuing service mirror sync for %s", key)
+	m.queue.Add(key)
+}
+
+func (m *ServiceMirror) isGateway(svc *v1.Service) bool {
+	return svc.Namespace == m.gatewayNamespace && svc.Name == m.gatewayName
+}
+
+func (m *ServiceMirror) isMirror(objLabels map[string]string) bool {
+	return objLabels[pkgK8s.MirroredServiceLabel] == "true" &&
+		objLabels[pkgK8s.RemoteClusterNameLabel] == m.clusterName
+}
+
+func (m *ServiceMirror) mirrorLabels() map[string]string {
+	return map[string]string{
+		pkgK8s.MirroredServiceLabel:   "true",
+		pkgK8s.RemoteClusterNameLabel: m.clusterName,
+	}
+}
+
+func (m *ServiceMirror) mirrorName(name string) string {
+	return fmt.Sprintf("%s-%s", name, m.clusterName)
+}
+
+func (m *ServiceMirror) remoteName(mirrorName string) string {
+	return strings.TrimSuffix(mirrorName, "-"+m.clusterName)
+}
+
+func isExported(svc *v1.Service) bool {
+	return svc.Labels[pkgK8s.ExportedServiceLabel] == "true"
+}
+
+func exportedSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{pkgK8s.ExportedServiceLabel: "true"})
+}
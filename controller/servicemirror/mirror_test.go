@@ -0,0 +1,211 @@
+package servicemirror
+
+import (
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	gatewayConfig = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: linkerd-gateway
+  namespace: linkerd
+  annotations:
+    mirror.linkerd.io/gateway-identity: linkerd-gateway.deployment.linkerd.linkerd-managed.linkerd.svc.east.example.com
+spec:
+  type: LoadBalancer
+  ports:
+  - name: mc-gateway
+    port: 4143
+status:
+  loadBalancer:
+    ingress:
+    - ip: 203.0.113.10`
+
+	remoteBooksConfig = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: books
+  namespace: booksapp
+  labels:
+    mirror.linkerd.io/exported: "true"
+spec:
+  ports:
+  - name: http
+    port: 7000`
+
+	remoteAuthorsConfig = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: authors
+  namespace: booksapp
+spec:
+  ports:
+  - name: http
+    port: 7001`
+
+	localNamespaceConfig = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: booksapp`
+
+	localAuthorsMirrorConfig = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: authors-east
+  namespace: booksapp
+  labels:
+    mirror.linkerd.io/mirrored-service: "true"
+    mirror.linkerd.io/cluster-name: east
+spec:
+  ports:
+  - name: http
+    port: 7001
+    targetPort: 4143`
+
+	localBooksConfig = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: books-east
+  namespace: booksapp
+spec:
+  ports:
+  - name: http
+    port: 7000`
+)
+
+func newMirror(t *testing.T, localConfigs, remoteConfigs []string) *ServiceMirror {
+	localAPI, err := k8s.NewFakeAPI(localConfigs...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	remoteAPI, err := k8s.NewFakeAPI(remoteConfigs...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	mirror := NewServiceMirror("east", "linkerd-gateway", "linkerd", "linkerd", "east.example.com", localAPI, remoteAPI)
+
+	localAPI.Sync(nil)
+	remoteAPI.Sync(nil)
+
+	return mirror
+}
+
+func TestSyncService(t *testing.T) {
+	t.Run("Mirrors exported services behind the gateway", func(t *testing.T) {
+		mirror := newMirror(t,
+			[]string{localNamespaceConfig},
+			[]string{gatewayConfig, remoteBooksConfig},
+		)
+
+		if err := mirror.syncService("booksapp/books"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		svc, err := mirror.localAPI.Client.CoreV1().Services("booksapp").Get("books-east", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the mirrored service to be created, got: %s", err)
+		}
+		if !mirror.isMirror(svc.Labels) {
+			t.Fatalf("Unexpected labels: %v", svc.Labels)
+		}
+		identity := "linkerd-gateway.deployment.linkerd.linkerd-managed.linkerd.svc.east.example.com"
+		if svc.Annotations[pkgK8s.RemoteGatewayIdentityAnnotation] != identity {
+			t.Fatalf("Expected the gateway identity %s, got %v", identity, svc.Annotations)
+		}
+		if svc.Annotations[pkgK8s.RemoteServiceFQNAnnotation] != "books.booksapp.svc.east.example.com" {
+			t.Fatalf("Unexpected remote service name: %v", svc.Annotations)
+		}
+		if len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].Port != 7000 || svc.Spec.Ports[0].TargetPort != intstr.FromInt(4143) {
+			t.Fatalf("Unexpected ports: %v", svc.Spec.Ports)
+		}
+
+		endpoints, err := mirror.localAPI.Client.CoreV1().Endpoints("booksapp").Get("books-east", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the mirrored endpoints to be created, got: %s", err)
+		}
+		if len(endpoints.Subsets) != 1 ||
+			len(endpoints.Subsets[0].Addresses) != 1 ||
+			endpoints.Subsets[0].Addresses[0].IP != "203.0.113.10" ||
+			endpoints.Subsets[0].Ports[0].Port != 4143 {
+			t.Fatalf("Unexpected endpoints: %v", endpoints.Subsets)
+		}
+	})
+
+	t.Run("Mirrors services without endpoints if the gateway doesn't exist", func(t *testing.T) {
+		mirror := newMirror(t,
+			[]string{localNamespaceConfig},
+			[]string{remoteBooksConfig},
+		)
+
+		if err := mirror.syncService("booksapp/books"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		endpoints, err := mirror.localAPI.Client.CoreV1().Endpoints("booksapp").Get("books-east", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the mirrored endpoints to be created, got: %s", err)
+		}
+		if len(endpoints.Subsets) != 0 {
+			t.Fatalf("Expected no endpoints, got %v", endpoints.Subsets)
+		}
+	})
+
+	t.Run("Doesn't mirror services whose namespace doesn't exist", func(t *testing.T) {
+		mirror := newMirror(t,
+			[]string{},
+			[]string{gatewayConfig, remoteBooksConfig},
+		)
+
+		if err := mirror.syncService("booksapp/books"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		_, err := mirror.localAPI.Client.CoreV1().Services("booksapp").Get("books-east", metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Fatalf("Expected the service not to be mirrored, got: %v", err)
+		}
+	})
+
+	t.Run("Deletes the mirrors of services that aren't exported", func(t *testing.T) {
+		mirror := newMirror(t,
+			[]string{localNamespaceConfig, localAuthorsMirrorConfig},
+			[]string{gatewayConfig, remoteAuthorsConfig},
+		)
+
+		if err := mirror.syncService("booksapp/authors"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		_, err := mirror.localAPI.Client.CoreV1().Services("booksapp").Get("authors-east", metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Fatalf("Expected the mirrored service to be deleted, got: %v", err)
+		}
+	})
+
+	t.Run("Doesn't overwrite services that aren't mirrored", func(t *testing.T) {
+		mirror := newMirror(t,
+			[]string{localNamespaceConfig, localBooksConfig},
+			[]string{gatewayConfig, remoteBooksConfig},
+		)
+
+		err := mirror.syncService("booksapp/books")
+		expected := "service books-east.booksapp exists and isn't mirrored from cluster east"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package validator
+
+import (
+	"net/http"
+
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	log "github.com/sirupsen/logrus"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Validate returns the response to an admission request, rejecting the
+// ServiceProfiles to be created or updated that the proxies would ignore: the
+// ones with invalid route regexes, duplicate routes, or malformed timeouts or
+// retry budgets. Validating has no side effects, so dry runs are validated
+// alike.
+func Validate(req *admissionV1beta1.AdmissionRequest, dryRun bool) *admissionV1beta1.AdmissionResponse {
+	rsp := &admissionV1beta1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+	}
+
+	if err := profiles.Validate(req.Object.Raw); err != nil {
+		log.Infof("rejecting ServiceProfile %s/%s: %s", req.Namespace, req.Name, err)
+		rsp.Allowed = false
+		rsp.Result = &metaV1.Status{
+			Status:  metaV1.StatusFailure,
+			Reason:  metaV1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+	}
+	return rsp
+}
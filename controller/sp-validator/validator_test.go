@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/controller/webhook"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func admissionRequest(t *testing.T, profile string) *admissionV1beta1.AdmissionRequest {
+	raw, err := yaml.YAMLToJSON([]byte(profile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return &admissionV1beta1.AdmissionRequest{
+		UID:       "123",
+		Namespace: "booksapp",
+		Name:      "books.booksapp.svc.cluster.local",
+		Operation: admissionV1beta1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Admits valid ServiceProfiles", func(t *testing.T) {
+		rsp := Validate(admissionRequest(t, `
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+  namespace: booksapp
+spec:
+  routes:
+  - name: GET /books/{id}
+    condition:
+      pathRegex: /books/\d+
+      method: GET
+    timeout: 300ms
+  retryBudget:
+    retryRatio: 0.2
+    minRetriesPerSecond: 10
+    ttl: 10s`), false)
+
+		if rsp.UID != "123" || !rsp.Allowed {
+			t.Fatalf("Expected request 123 to be allowed, got %+v", rsp)
+		}
+	})
+
+	testCases := []struct {
+		desc    string
+		spec    string
+		message string
+	}{
+		{
+			desc: "invalid route regexes",
+			spec: `
+  routes:
+  - name: books
+    condition:
+      pathRegex: /books/(`,
+			message: `invalid condition of route "books": invalid path regex "/books/("`,
+		},
+		{
+			desc: "duplicate routes",
+			spec: `
+  routes:
+  - name: books
+    condition:
+      method: GET
+  - name: books
+    condition:
+      method: POST`,
+			message: `duplicate route name "books"`,
+		},
+		{
+			desc: "malformed timeouts",
+			spec: `
+  routes:
+  - name: books
+    condition:
+      method: GET
+    timeout: "300"`,
+			message: `invalid timeout of route "books"`,
+		},
+		{
+			desc: "malformed retry budgets",
+			spec: `
+  retryBudget:
+    retryRatio: 0.2
+    minRetriesPerSecond: 10
+    ttl: forever`,
+			message: `invalid retry budget: invalid TTL "forever"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run("Rejects ServiceProfiles with "+tc.desc, func(t *testing.T) {
+			rsp := Validate(admissionRequest(t, `
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+  namespace: booksapp
+spec:`+tc.spec), false)
+
+			if rsp.Allowed {
+				t.Fatalf("Expected the ServiceProfile to be rejected")
+			}
+			if rsp.Result == nil || !strings.HasPrefix(rsp.Result.Message, tc.message) {
+				t.Fatalf("Expected message starting with [%s], got %+v", tc.message, rsp.Result)
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	review := admissionV1beta1.AdmissionReview{Request: admissionRequest(t, `
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+spec:
+  routes:
+  - name: books`)}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	rsp := httptest.NewRecorder()
+	webhook.Handler(Validate).ServeHTTP(rsp, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	var reviewed admissionV1beta1.AdmissionReview
+	if err := json.Unmarshal(rsp.Body.Bytes(), &reviewed); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if reviewed.Response == nil || reviewed.Response.UID != "123" || reviewed.Response.Allowed {
+		t.Fatalf("Expected request 123 to be rejected, got %+v", reviewed.Response)
+	}
+}
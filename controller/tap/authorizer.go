@@ -0,0 +1,86 @@
+package tap
+
+import (
+	"context"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	authV1 "k8s.io/api/authorization/v1"
+)
+
+const (
+	// UserMetadataKey and GroupMetadataKey carry the identity of the caller,
+	// as authenticated by the Kubernetes API server, in the gRPC metadata of
+	// TapByResource requests.
+	UserMetadataKey  = "l5d-tap-user"
+	GroupMetadataKey = "l5d-tap-group"
+)
+
+// authorize checks that the caller identified in the request's metadata is
+// allowed to tap the target's namespace, by submitting a SubjectAccessReview
+// for the "watch" verb on the "tap" resource in the namespace. Targets without
+// a namespace require the permission in every namespace.
+//
+// The metadata isn't authenticated by the tap server: the loopback interface
+// of the controller pod, which the tap server listens on, is the trust
+// boundary. Its only client there is the public API, which sets the metadata
+// from the requests authenticated by the Kubernetes API server, so the tap
+// server must not be exposed on the pod's network.
+func (s *server) authorize(ctx context.Context, target *public.Resource) error {
+	if !s.enforceRBAC {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	users := md[UserMetadataKey]
+	if len(users) != 1 || users[0] == "" {
+		return status.Errorf(codes.Unauthenticated, "tap requests must be made through the %s API", pkgK8s.TapAPIGroup)
+	}
+
+	namespace := target.GetNamespace()
+	if target.GetType() == pkgK8s.Namespace {
+		namespace = target.GetName()
+	}
+
+	review := &authV1.SubjectAccessReview{
+		Spec: authV1.SubjectAccessReviewSpec{
+			User:   users[0],
+			Groups: md[GroupMetadataKey],
+			ResourceAttributes: &authV1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "watch",
+				Group:     pkgK8s.TapAPIGroup,
+				Version:   pkgK8s.TapAPIVersion,
+				Resource:  "tap",
+			},
+		},
+	}
+
+	rsp, err := s.k8sAPI.Client.AuthorizationV1().SubjectAccessReviews().Create(review)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to authorize tap request: %s", err)
+	}
+
+	if !rsp.Status.Allowed {
+		if namespace == "" {
+			return status.Errorf(codes.PermissionDenied, "%s is not allowed to tap all namespaces", users[0])
+		}
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed to tap namespace %s", users[0], namespace)
+	}
+
+	return nil
+}
+
+// caller returns the user that made a request through the tap API, as
+// authenticated by the Kubernetes API server, or "" if the request didn't come
+// through the tap API.
+func caller(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if users := md[UserMetadataKey]; len(users) == 1 {
+		return users[0]
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package tap
+
+import (
+	"context"
+	"testing"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc/metadata"
+	authV1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI()
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+
+	var reviewed *authV1.SubjectAccessReview
+	k8sAPI.Client.(*fake.Clientset).PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviewed = action.(k8stesting.CreateAction).GetObject().(*authV1.SubjectAccessReview)
+		review := reviewed.DeepCopy()
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "emojivoto"
+		return true, review, nil
+	})
+
+	s := &server{k8sAPI: k8sAPI, enforceRBAC: true}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		UserMetadataKey, "alice",
+		GroupMetadataKey, "developers",
+		GroupMetadataKey, "system:authenticated",
+	))
+
+	t.Run("Allows callers authorized to tap the target's namespace", func(t *testing.T) {
+		err := s.authorize(ctx, &public.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		attributes := reviewed.Spec.ResourceAttributes
+		if reviewed.Spec.User != "alice" || len(reviewed.Spec.Groups) != 2 {
+			t.Fatalf("Unexpected identity in review: %+v", reviewed.Spec)
+		}
+		if attributes.Verb != "watch" || attributes.Group != pkgK8s.TapAPIGroup || attributes.Resource != "tap" || attributes.Namespace != "emojivoto" {
+			t.Fatalf("Unexpected resource attributes in review: %+v", attributes)
+		}
+	})
+
+	t.Run("Reviews namespace targets against the namespace itself", func(t *testing.T) {
+		err := s.authorize(ctx, &public.Resource{Type: pkgK8s.Namespace, Name: "emojivoto"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Denies callers not authorized to tap the target's namespace", func(t *testing.T) {
+		err := s.authorize(ctx, &public.Resource{Namespace: "linkerd", Type: pkgK8s.Deployment, Name: "controller"})
+		expected := "rpc error: code = PermissionDenied desc = alice is not allowed to tap namespace linkerd"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Denies unauthenticated callers", func(t *testing.T) {
+		err := s.authorize(context.Background(), &public.Resource{Namespace: "emojivoto", Type: pkgK8s.Deployment, Name: "web"})
+		expected := "rpc error: code = Unauthenticated desc = tap requests must be made through the tap.linkerd.io API"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Allows every caller when RBAC is not enforced", func(t *testing.T) {
+		s := &server{k8sAPI: k8sAPI, enforceRBAC: false}
+		err := s.authorize(context.Background(), &public.Resource{Namespace: "linkerd", Type: pkgK8s.Deployment, Name: "controller"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package tap
+
+import (
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+// responseClassifier sets the classification of the ResponseEnd events of a
+// single proxy, so that clients see why a request is counted as a failure. A
+// response is a failure if it ends with a gRPC status other than OK, if its
+// stream is reset, or, in the absence of a gRPC status, if its HTTP status is
+// a 5xx. Since the HTTP status of a stream is only carried by its
+// ResponseInit event, it is held until the matching ResponseEnd event
+// arrives.
+type responseClassifier struct {
+	statuses map[streamKey]uint32
+}
+
+func newResponseClassifier() *responseClassifier {
+	return &responseClassifier{
+		statuses: make(map[streamKey]uint32),
+	}
+}
+
+// classify records the HTTP status of ResponseInit events, and sets the
+// classification of ResponseEnd events in place.
+func (c *responseClassifier) classify(event *public.TapEvent) {
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *public.TapEvent_Http_ResponseInit_:
+		c.statuses[keyFor(ev.ResponseInit.GetId())] = ev.ResponseInit.GetHttpStatus()
+
+	case *public.TapEvent_Http_ResponseEnd_:
+		key := keyFor(ev.ResponseEnd.GetId())
+		status := c.statuses[key]
+		delete(c.statuses, key)
+
+		ev.ResponseEnd.Classification = classifyResponse(status, ev.ResponseEnd.GetEos())
+	}
+}
+
+func classifyResponse(status uint32, eos *public.Eos) public.TapEvent_Http_ResponseEnd_Classification {
+	switch end := eos.GetEnd().(type) {
+	case *public.Eos_GrpcStatusCode:
+		if end.GrpcStatusCode != 0 {
+			return public.TapEvent_Http_ResponseEnd_FAILURE
+		}
+		return public.TapEvent_Http_ResponseEnd_SUCCESS
+
+	case *public.Eos_ResetErrorCode:
+		return public.TapEvent_Http_ResponseEnd_FAILURE
+	}
+
+	if status >= 500 {
+		return public.TapEvent_Http_ResponseEnd_FAILURE
+	}
+	return public.TapEvent_Http_ResponseEnd_SUCCESS
+}
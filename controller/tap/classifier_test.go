@@ -0,0 +1,50 @@
+package tap
+
+import (
+	"testing"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func responseEndWithEos(stream uint64, eos *public.Eos) *public.TapEvent {
+	event := responseEnd(stream)
+	event.GetHttp().GetResponseEnd().Eos = eos
+	return event
+}
+
+func TestResponseClassifier(t *testing.T) {
+	grpcStatus := func(code uint32) *public.Eos {
+		return &public.Eos{End: &public.Eos_GrpcStatusCode{GrpcStatusCode: code}}
+	}
+	reset := &public.Eos{End: &public.Eos_ResetErrorCode{ResetErrorCode: 2}}
+
+	expectations := []struct {
+		status   uint32
+		eos      *public.Eos
+		expected public.TapEvent_Http_ResponseEnd_Classification
+	}{
+		{status: 200, expected: public.TapEvent_Http_ResponseEnd_SUCCESS},
+		{status: 404, expected: public.TapEvent_Http_ResponseEnd_SUCCESS},
+		{status: 503, expected: public.TapEvent_Http_ResponseEnd_FAILURE},
+		{status: 200, eos: grpcStatus(0), expected: public.TapEvent_Http_ResponseEnd_SUCCESS},
+		{status: 200, eos: grpcStatus(14), expected: public.TapEvent_Http_ResponseEnd_FAILURE},
+		{status: 200, eos: reset, expected: public.TapEvent_Http_ResponseEnd_FAILURE},
+	}
+
+	for i, exp := range expectations {
+		classifier := newResponseClassifier()
+		end := responseEndWithEos(uint64(i), exp.eos)
+
+		classifier.classify(requestInit(uint64(i)))
+		classifier.classify(responseInit(uint64(i), exp.status))
+		classifier.classify(end)
+
+		classification := end.GetHttp().GetResponseEnd().GetClassification()
+		if classification != exp.expected {
+			t.Fatalf("Expected status %d with %v to be classified as %s, got %s", exp.status, exp.eos, exp.expected, classification)
+		}
+		if len(classifier.statuses) != 0 {
+			t.Fatalf("Expected no streams to be tracked once they ended, got %v", classifier.statuses)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tap
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	activeStreams = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "tap_active_streams",
+			Help: "The number of TapByResource streams being served.",
+		},
+	)
+
+	activeProxyTaps = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "tap_active_proxy_taps",
+			Help: "The number of taps established on proxies, by all the TapByResource streams.",
+		},
+	)
+
+	rejectedTaps = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tap_rejected_total",
+			Help: "A counter of the TapByResource streams rejected because their caller reached its limit of concurrent streams, and of the proxies left untapped because they reached their limit of concurrent taps.",
+		},
+		[]string{"limit"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(activeStreams, activeProxyTaps, rejectedTaps)
+}
+
+// tapLimiter limits the number of TapByResource streams that a caller serves
+// concurrently, and the number of taps concurrently established on a proxy,
+// so that the taps of a single user can't degrade every proxy of a namespace.
+// A limit of 0 means no limit.
+type tapLimiter struct {
+	sync.Mutex
+	maxPerProxy  uint
+	maxPerCaller uint
+
+	// proxies and callers count the active taps by proxy address, and the
+	// active streams by caller.
+	proxies map[string]uint
+	callers map[string]uint
+}
+
+func newTapLimiter(maxPerProxy, maxPerCaller uint) *tapLimiter {
+	return &tapLimiter{
+		maxPerProxy:  maxPerProxy,
+		maxPerCaller: maxPerCaller,
+		proxies:      make(map[string]uint),
+		callers:      make(map[string]uint),
+	}
+}
+
+// acquireStream reserves a stream for caller, returning false if the caller
+// already reached its limit. Callers that aren't identified, i.e. whose
+// requests don't come through the tap API, aren't limited.
+func (l *tapLimiter) acquireStream(caller string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if caller != "" && l.maxPerCaller > 0 && l.callers[caller] >= l.maxPerCaller {
+		rejectedTaps.WithLabelValues("caller").Inc()
+		return false
+	}
+	l.callers[caller]++
+	activeStreams.Inc()
+	return true
+}
+
+func (l *tapLimiter) releaseStream(caller string) {
+	l.Lock()
+	defer l.Unlock()
+
+	release(l.callers, caller)
+	activeStreams.Dec()
+}
+
+// acquireProxy reserves a tap on the proxy at addr, returning false if the
+// proxy already reached its limit.
+func (l *tapLimiter) acquireProxy(addr string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.proxyExhausted(addr) {
+		rejectedTaps.WithLabelValues("proxy").Inc()
+		return false
+	}
+	l.proxies[addr]++
+	activeProxyTaps.Inc()
+	return true
+}
+
+func (l *tapLimiter) releaseProxy(addr string) {
+	l.Lock()
+	defer l.Unlock()
+
+	release(l.proxies, addr)
+	activeProxyTaps.Dec()
+}
+
+// proxiesExhausted returns true if all the proxies at addrs reached their
+// limit, and there's at least one of them.
+func (l *tapLimiter) proxiesExhausted(addrs []string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, addr := range addrs {
+		if !l.proxyExhausted(addr) {
+			return false
+		}
+	}
+	return len(addrs) > 0
+}
+
+func (l *tapLimiter) proxyExhausted(addr string) bool {
+	return l.maxPerProxy > 0 && l.proxies[addr] >= l.maxPerProxy
+}
+
+func release(counts map[string]uint, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
@@ -0,0 +1,76 @@
+package tap
+
+import (
+	"testing"
+)
+
+func TestTapLimiter(t *testing.T) {
+	t.Run("Limits the concurrent streams of a caller", func(t *testing.T) {
+		l := newTapLimiter(0, 2)
+
+		for i := 0; i < 2; i++ {
+			if !l.acquireStream("alice") {
+				t.Fatalf("Expected stream %d of alice to be accepted", i)
+			}
+		}
+		if l.acquireStream("alice") {
+			t.Fatalf("Expected the third stream of alice to be rejected")
+		}
+		if !l.acquireStream("bob") {
+			t.Fatalf("Expected the streams of bob not to be limited by the ones of alice")
+		}
+
+		l.releaseStream("alice")
+		if !l.acquireStream("alice") {
+			t.Fatalf("Expected a stream of alice to be accepted once another one ended")
+		}
+	})
+
+	t.Run("Doesn't limit unidentified callers", func(t *testing.T) {
+		l := newTapLimiter(0, 1)
+
+		for i := 0; i < 3; i++ {
+			if !l.acquireStream("") {
+				t.Fatalf("Expected stream %d of an unidentified caller to be accepted", i)
+			}
+		}
+	})
+
+	t.Run("Limits the concurrent taps of a proxy", func(t *testing.T) {
+		l := newTapLimiter(1, 0)
+
+		if l.proxiesExhausted([]string{"10.0.0.1", "10.0.0.2"}) {
+			t.Fatalf("Expected untapped proxies not to be exhausted")
+		}
+		if !l.acquireProxy("10.0.0.1") {
+			t.Fatalf("Expected the first tap of 10.0.0.1 to be accepted")
+		}
+		if l.acquireProxy("10.0.0.1") {
+			t.Fatalf("Expected the second tap of 10.0.0.1 to be rejected")
+		}
+		if l.proxiesExhausted([]string{"10.0.0.1", "10.0.0.2"}) {
+			t.Fatalf("Expected proxies not to be exhausted while one of them can be tapped")
+		}
+		if !l.proxiesExhausted([]string{"10.0.0.1"}) {
+			t.Fatalf("Expected 10.0.0.1 to be exhausted")
+		}
+
+		l.releaseProxy("10.0.0.1")
+		if len(l.proxies) != 0 {
+			t.Fatalf("Expected released proxies to be forgotten, got %v", l.proxies)
+		}
+	})
+
+	t.Run("Doesn't limit anything with limits of 0", func(t *testing.T) {
+		l := newTapLimiter(0, 0)
+
+		for i := 0; i < 100; i++ {
+			if !l.acquireStream("alice") || !l.acquireProxy("10.0.0.1") {
+				t.Fatalf("Expected tap %d to be accepted", i)
+			}
+		}
+		if l.proxiesExhausted([]string{"10.0.0.1"}) {
+			t.Fatalf("Expected proxies without limit never to be exhausted")
+		}
+	})
+}
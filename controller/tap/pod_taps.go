@@ -0,0 +1,161 @@
+package tap
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	log "github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podWatcher notifies its subscribers whenever a pod is added, deleted, or
+// changes in a way that could affect the set of pods backing a tap target.
+// Notifications are coalesced, so a subscriber that is slow to react only
+// sees the latest state once.
+type podWatcher struct {
+	sync.RWMutex
+	subscribers map[chan struct{}]struct{}
+}
+
+// podTaps keeps a tap established on every pod of a tap target, starting and
+// stopping taps as pods come and go. The target's max rps is evenly divided
+// between all the tapped pods.
+type podTaps struct {
+	sync.Mutex
+	maxRps float32
+	tap    func(ctx context.Context, maxRps func() float32, addr string)
+
+	// The taps are keyed on "$podNamespace/$podName".
+	taps map[string]*podTap
+}
+
+type podTap struct {
+	addr   string
+	cancel context.CancelFunc
+}
+
+func newPodWatcher(k8sAPI *k8s.API) *podWatcher {
+	w := &podWatcher{
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+
+	k8sAPI.Pod().Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { w.notify() },
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				if podTargetChanged(oldObj.(*apiv1.Pod), newObj.(*apiv1.Pod)) {
+					w.notify()
+				}
+			},
+			DeleteFunc: func(obj interface{}) { w.notify() },
+		},
+	)
+
+	return w
+}
+
+// subscribe registers a channel that receives a value whenever pods change.
+// The channel should be buffered, since notifications are never blocked on.
+func (w *podWatcher) subscribe(updates chan struct{}) {
+	w.Lock()
+	defer w.Unlock()
+	w.subscribers[updates] = struct{}{}
+}
+
+func (w *podWatcher) unsubscribe(updates chan struct{}) {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.subscribers, updates)
+}
+
+func (w *podWatcher) notify() {
+	w.RLock()
+	defer w.RUnlock()
+	for updates := range w.subscribers {
+		select {
+		case updates <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+}
+
+// podTargetChanged returns true if a pod update could change which pods are
+// tapped, or the address they are tapped on.
+func podTargetChanged(oldPod, newPod *apiv1.Pod) bool {
+	return oldPod.Status.PodIP != newPod.Status.PodIP ||
+		oldPod.Status.Phase != newPod.Status.Phase ||
+		!reflect.DeepEqual(oldPod.Labels, newPod.Labels)
+}
+
+func newPodTaps(maxRps float32, tap func(ctx context.Context, maxRps func() float32, addr string)) *podTaps {
+	return &podTaps{
+		maxRps: maxRps,
+		tap:    tap,
+		taps:   make(map[string]*podTap),
+	}
+}
+
+// update starts a tap on each pod that is not tapped yet, and stops the taps
+// on pods that are no longer part of the target. Pods that haven't been
+// assigned an IP yet are tapped once they are.
+func (t *podTaps) update(ctx context.Context, pods []*apiv1.Pod) {
+	t.Lock()
+	defer t.Unlock()
+
+	current := make(map[string]string)
+	for _, pod := range pods {
+		if pod.Status.PodIP != "" {
+			current[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = pod.Status.PodIP
+		}
+	}
+
+	for key, tap := range t.taps {
+		if addr, ok := current[key]; !ok || addr != tap.addr {
+			log.Infof("Stopping tap on %s (%s)", key, tap.addr)
+			tap.cancel()
+			delete(t.taps, key)
+		}
+	}
+
+	for key, addr := range current {
+		if _, ok := t.taps[key]; ok {
+			continue
+		}
+
+		tapCtx, cancel := context.WithCancel(ctx)
+		t.taps[key] = &podTap{addr: addr, cancel: cancel}
+		go t.tap(tapCtx, t.rpsPerPod, addr)
+	}
+}
+
+// stop stops all the taps.
+func (t *podTaps) stop() {
+	t.Lock()
+	defer t.Unlock()
+
+	for key, tap := range t.taps {
+		tap.cancel()
+		delete(t.taps, key)
+	}
+}
+
+// rpsPerPod returns the share of the max rps of each tapped pod.
+func (t *podTaps) rpsPerPod() float32 {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.taps) == 0 {
+		return t.maxRps
+	}
+
+	rps := t.maxRps / float32(len(t.taps))
+	if rps < 1 {
+		rps = 1
+	}
+	return rps
+}
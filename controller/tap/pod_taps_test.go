@@ -0,0 +1,129 @@
+package tap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(name, ip string) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "emojivoto"},
+		Status:     apiv1.PodStatus{PodIP: ip},
+	}
+}
+
+type recordingTapper struct {
+	sync.Mutex
+	active map[string]struct{}
+}
+
+func (r *recordingTapper) tap(ctx context.Context, maxRps func() float32, addr string) {
+	r.Lock()
+	r.active[addr] = struct{}{}
+	r.Unlock()
+
+	<-ctx.Done()
+
+	r.Lock()
+	delete(r.active, addr)
+	r.Unlock()
+}
+
+func (r *recordingTapper) waitFor(t *testing.T, expected ...string) {
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.Lock()
+		addrs := []string{}
+		for addr := range r.active {
+			addrs = append(addrs, addr)
+		}
+		r.Unlock()
+
+		sort.Strings(addrs)
+		if len(addrs) == len(expected) {
+			matches := true
+			for i := range addrs {
+				if addrs[i] != expected[i] {
+					matches = false
+				}
+			}
+			if matches {
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected taps on %v, got %v", expected, addrs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPodTaps(t *testing.T) {
+	t.Run("Starts and stops taps as pods come and go", func(t *testing.T) {
+		tapper := &recordingTapper{active: make(map[string]struct{})}
+		taps := newPodTaps(10, tapper.tap)
+		defer taps.stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		taps.update(ctx, []*apiv1.Pod{newPod("web-1", "10.0.0.1"), newPod("web-2", "")})
+		tapper.waitFor(t, "10.0.0.1")
+
+		taps.update(ctx, []*apiv1.Pod{newPod("web-1", "10.0.0.1"), newPod("web-2", "10.0.0.2")})
+		tapper.waitFor(t, "10.0.0.1", "10.0.0.2")
+
+		if rps := taps.rpsPerPod(); rps != 5 {
+			t.Fatalf("Expected the max rps to be divided between 2 pods, got %f", rps)
+		}
+
+		taps.update(ctx, []*apiv1.Pod{newPod("web-2", "10.0.0.2"), newPod("web-3", "10.0.0.3")})
+		tapper.waitFor(t, "10.0.0.2", "10.0.0.3")
+
+		taps.update(ctx, []*apiv1.Pod{newPod("web-3", "10.0.0.4")})
+		tapper.waitFor(t, "10.0.0.4")
+
+		taps.stop()
+		tapper.waitFor(t)
+	})
+
+	t.Run("Taps each pod at 1 rps at least", func(t *testing.T) {
+		taps := newPodTaps(1, func(ctx context.Context, maxRps func() float32, addr string) {})
+
+		taps.update(context.Background(), []*apiv1.Pod{newPod("web-1", "10.0.0.1"), newPod("web-2", "10.0.0.2")})
+
+		if rps := taps.rpsPerPod(); rps != 1 {
+			t.Fatalf("Expected 1 rps per pod, got %f", rps)
+		}
+	})
+}
+
+func TestPodWatcher(t *testing.T) {
+	t.Run("Coalesces notifications", func(t *testing.T) {
+		watcher := &podWatcher{subscribers: make(map[chan struct{}]struct{})}
+		updates := make(chan struct{}, 1)
+		watcher.subscribe(updates)
+
+		watcher.notify()
+		watcher.notify()
+
+		if len(updates) != 1 {
+			t.Fatalf("Expected 1 pending notification, got %d", len(updates))
+		}
+		<-updates
+
+		watcher.unsubscribe(updates)
+		watcher.notify()
+
+		if len(updates) != 0 {
+			t.Fatalf("Expected no notifications after unsubscribing, got %d", len(updates))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package tap
+
+import (
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+// requestLimit drops the events of the requests beyond the number of requests
+// that a call to Observe may send to the client. Only the requests whose
+// events are sent count against the limit, rather than the ones the tap
+// server filters out.
+type requestLimit struct {
+	limit   uint32
+	count   uint32
+	emitted map[streamKey]struct{}
+}
+
+func newRequestLimit(limit uint32) *requestLimit {
+	return &requestLimit{
+		limit:   limit,
+		emitted: make(map[streamKey]struct{}),
+	}
+}
+
+// allow returns true if the event may be sent. The first event of a stream is
+// allowed until the limit is reached, and the following events of a stream
+// are allowed if its first event was.
+func (l *requestLimit) allow(event *public.TapEvent) bool {
+	var key streamKey
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *public.TapEvent_Http_RequestInit_:
+		key = keyFor(ev.RequestInit.GetId())
+	case *public.TapEvent_Http_ResponseInit_:
+		key = keyFor(ev.ResponseInit.GetId())
+	case *public.TapEvent_Http_ResponseEnd_:
+		key = keyFor(ev.ResponseEnd.GetId())
+		if _, ok := l.emitted[key]; !ok {
+			return false
+		}
+		delete(l.emitted, key)
+		return true
+	default:
+		return true
+	}
+
+	if _, ok := l.emitted[key]; ok {
+		return true
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	l.emitted[key] = struct{}{}
+	return true
+}
+
+// done returns true once the limit is reached and the allowed streams have
+// ended.
+func (l *requestLimit) done() bool {
+	return l.count >= l.limit && len(l.emitted) == 0
+}
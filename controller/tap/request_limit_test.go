@@ -0,0 +1,66 @@
+package tap
+
+import (
+	"testing"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestRequestLimit(t *testing.T) {
+	t.Run("Allows the events of requests up to the limit", func(t *testing.T) {
+		limit := newRequestLimit(2)
+
+		allowed := []*public.TapEvent{}
+		for _, event := range []*public.TapEvent{
+			requestInit(1), requestInit(2), requestInit(3),
+			responseInit(1, 200), responseInit(2, 200), responseInit(3, 200),
+			responseEnd(1), responseEnd(3),
+		} {
+			if limit.allow(event) {
+				allowed = append(allowed, event)
+			}
+		}
+
+		if len(allowed) != 5 {
+			t.Fatalf("Expected 5 events to be allowed, got %d: %v", len(allowed), allowed)
+		}
+		for _, event := range allowed {
+			if stream := keyFor(streamID(event)).stream; stream == 3 {
+				t.Fatalf("Expected the events of stream 3 to be dropped, got %v", event)
+			}
+		}
+		if limit.done() {
+			t.Fatal("Expected the limit not to be done before stream 2 ends")
+		}
+
+		if !limit.allow(responseEnd(2)) {
+			t.Fatal("Expected the end of stream 2 to be allowed")
+		}
+		if !limit.done() {
+			t.Fatal("Expected the limit to be done once the allowed streams ended")
+		}
+	})
+
+	t.Run("Counts streams whose request wasn't sent", func(t *testing.T) {
+		limit := newRequestLimit(1)
+
+		if !limit.allow(responseInit(1, 503)) {
+			t.Fatal("Expected the response of stream 1 to be allowed")
+		}
+		if limit.allow(requestInit(2)) {
+			t.Fatal("Expected the request of stream 2 to be dropped")
+		}
+	})
+}
+
+func streamID(event *public.TapEvent) *public.TapEvent_Http_StreamId {
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *public.TapEvent_Http_RequestInit_:
+		return ev.RequestInit.GetId()
+	case *public.TapEvent_Http_ResponseInit_:
+		return ev.ResponseInit.GetId()
+	case *public.TapEvent_Http_ResponseEnd_:
+		return ev.ResponseEnd.GetId()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package tap
+
+import (
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+// resourceFilter drops the events that were not sent from every one of its
+// sources and to every one of its destinations. The sources and destinations
+// are matched by the tap server against the labels of each event, once they
+// have been hydrated, so that events reported by both inbound and outbound
+// proxies can be matched.
+type resourceFilter struct {
+	sources      []map[string]string
+	destinations []map[string]string
+}
+
+func newResourceFilter(match *public.TapByResourceRequest_Match) *resourceFilter {
+	f := &resourceFilter{}
+	for _, reqMatch := range match.GetAll().GetMatches() {
+		if src := reqMatch.GetSources().GetResource(); src != nil {
+			f.sources = append(f.sources, destinationLabels(src))
+		}
+		if dst := reqMatch.GetDestinations().GetResource(); dst != nil {
+			f.destinations = append(f.destinations, destinationLabels(dst))
+		}
+	}
+	return f
+}
+
+// matches returns true if the event's source and destination labels include
+// the labels of every source and destination of the filter.
+func (f *resourceFilter) matches(event *public.TapEvent) bool {
+	for _, labels := range f.sources {
+		if !hasLabels(event.GetSourceMeta().GetLabels(), labels) {
+			return false
+		}
+	}
+	for _, labels := range f.destinations {
+		if !hasLabels(event.GetDestinationMeta().GetLabels(), labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmpty returns true if the filter has no sources or destinations, and so
+// matches every event.
+func (f *resourceFilter) isEmpty() bool {
+	return len(f.sources) == 0 && len(f.destinations) == 0
+}
+
+func hasLabels(eventLabels, labels map[string]string) bool {
+	for k, v := range labels {
+		if eventLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
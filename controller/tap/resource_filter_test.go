@@ -0,0 +1,87 @@
+package tap
+
+import (
+	"testing"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func eventBetween(srcLabels, dstLabels map[string]string) *public.TapEvent {
+	return &public.TapEvent{
+		SourceMeta:      &public.TapEvent_EndpointMeta{Labels: srcLabels},
+		DestinationMeta: &public.TapEvent_EndpointMeta{Labels: dstLabels},
+	}
+}
+
+func TestResourceFilter(t *testing.T) {
+	web := map[string]string{"deployment": "web", "namespace": "emojivoto"}
+	voting := map[string]string{"deployment": "voting", "namespace": "emojivoto"}
+	emoji := map[string]string{"deployment": "emoji", "namespace": "emojivoto"}
+
+	match := &public.TapByResourceRequest_Match{
+		Match: &public.TapByResourceRequest_Match_All{
+			All: &public.TapByResourceRequest_Match_Seq{
+				Matches: []*public.TapByResourceRequest_Match{
+					{
+						Match: &public.TapByResourceRequest_Match_Sources{
+							Sources: &public.ResourceSelection{
+								Resource: &public.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+							},
+						},
+					},
+					{
+						Match: &public.TapByResourceRequest_Match_Destinations{
+							Destinations: &public.ResourceSelection{
+								Resource: &public.Resource{Namespace: "emojivoto", Type: "deployment", Name: "voting"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Matches all events when there are no sources or destinations", func(t *testing.T) {
+		filter := newResourceFilter(nil)
+		if !filter.isEmpty() {
+			t.Fatal("Expected filter to be empty")
+		}
+		if !filter.matches(eventBetween(nil, nil)) {
+			t.Fatal("Expected event to match")
+		}
+	})
+
+	t.Run("Matches events by their source and destination labels", func(t *testing.T) {
+		filter := newResourceFilter(match)
+		if filter.isEmpty() {
+			t.Fatal("Expected filter not to be empty")
+		}
+
+		expectations := []struct {
+			event   *public.TapEvent
+			matches bool
+		}{
+			{eventBetween(web, voting), true},
+			{eventBetween(web, emoji), false},
+			{eventBetween(voting, web), false},
+			{eventBetween(web, map[string]string{"deployment": "voting", "namespace": "other"}), false},
+			{eventBetween(nil, voting), false},
+		}
+
+		for i, exp := range expectations {
+			if matches := filter.matches(exp.event); matches != exp.matches {
+				t.Fatalf("[%d] Expected match to be %t, got %t", i, exp.matches, matches)
+			}
+		}
+	})
+
+	t.Run("Does not send sources or destinations to the proxy", func(t *testing.T) {
+		proxyMatch, err := makeByResourceMatch(match)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n := len(proxyMatch.GetAll().GetMatches()); n != 0 {
+			t.Fatalf("Expected no matches to be sent to the proxy, got %d", n)
+		}
+	})
+}
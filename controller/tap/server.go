@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	httpPb "github.com/linkerd/linkerd2-proxy-api/go/http_types"
 	netPb "github.com/linkerd/linkerd2-proxy-api/go/net"
 	proxy "github.com/linkerd/linkerd2-proxy-api/go/tap"
@@ -29,11 +30,20 @@ import (
 const podIPIndex = "ip"
 const defaultMaxRps = 100.0
 
+// filteredLimitFactor is the factor of the number of requests that a proxy is
+// asked for when the tap server filters their events, to make up for the
+// requests that are filtered out.
+const filteredLimitFactor = 10
+
 type (
 	server struct {
 		tapPort             uint
 		k8sAPI              *k8s.API
+		podWatcher          *podWatcher
 		controllerNamespace string
+		trustDomain         string
+		enforceRBAC         bool
+		limiter             *tapLimiter
 	}
 )
 
@@ -56,23 +66,36 @@ func (s *server) TapByResource(req *public.TapByResourceRequest, stream pb.Tap_T
 		req.MaxRps = defaultMaxRps
 	}
 
-	objects, err := s.k8sAPI.GetObjects(req.Target.Resource.Namespace, req.Target.Resource.Type, req.Target.Resource.Name)
-	if err != nil {
-		return apiUtil.GRPCError(err)
+	ctx := stream.Context()
+	if err := s.authorize(ctx, req.Target.Resource); err != nil {
+		return err
 	}
 
-	pods := []*apiv1.Pod{}
-	for _, object := range objects {
-		podsFor, err := s.k8sAPI.GetPodsFor(object, false)
-		if err != nil {
-			return apiUtil.GRPCError(err)
-		}
+	user := caller(ctx)
+	if !s.limiter.acquireStream(user) {
+		return status.Errorf(codes.ResourceExhausted, "%s already has the maximum of %d active taps; end one of them before starting another", user, s.limiter.maxPerCaller)
+	}
+	defer s.limiter.releaseStream(user)
 
-		for _, pod := range podsFor {
-			if pkgK8s.IsMeshed(pod, s.controllerNamespace) {
-				pods = append(pods, pod)
-			}
+	if req.Duration != nil {
+		duration, err := ptypes.Duration(req.Duration)
+		if err != nil || duration <= 0 {
+			return status.Error(codes.InvalidArgument, "TapByResource received invalid duration")
 		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	// subscribe before listing the pods, so that no changes are missed
+	updates := make(chan struct{}, 1)
+	s.podWatcher.subscribe(updates)
+	defer s.podWatcher.unsubscribe(updates)
+
+	pods, err := s.podsFor(req.Target.Resource)
+	if err != nil {
+		return apiUtil.GRPCError(err)
 	}
 
 	if len(pods) == 0 {
@@ -80,31 +103,53 @@ func (s *server) TapByResource(req *public.TapByResourceRequest, stream pb.Tap_T
 			req.GetTarget().GetResource().GetType(), req.GetTarget().GetResource().GetName())
 	}
 
+	addrs := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Status.PodIP != "" {
+			addrs = append(addrs, pod.Status.PodIP)
+		}
+	}
+	if s.limiter.proxiesExhausted(addrs) {
+		return status.Errorf(codes.ResourceExhausted, "the pods of %s/%s are already tapped by the maximum of %d taps per proxy; retry once other taps end",
+			req.GetTarget().GetResource().GetType(), req.GetTarget().GetResource().GetName(), s.limiter.maxPerProxy)
+	}
+
 	log.Infof("Tapping %d pods for target: %+v", len(pods), *req.Target.Resource)
 
 	events := make(chan *public.TapEvent)
 
-	// divide the rps evenly between all pods to tap
-	rpsPerPod := req.MaxRps / float32(len(pods))
-	if rpsPerPod < 1 {
-		rpsPerPod = 1
-	}
-
 	match, err := makeByResourceMatch(req.Match)
 	if err != nil {
 		return apiUtil.GRPCError(err)
 	}
+	resources := newResourceFilter(req.Match)
+	ranges := statusRanges(req.Match)
 
-	for _, pod := range pods {
-		// initiate a tap on the pod
-		go s.tapProxy(stream.Context(), rpsPerPod, match, pod.Status.PodIP, events)
-	}
-
-	// read events from the taps and send them back
+	taps := newPodTaps(req.MaxRps, func(ctx context.Context, maxRps func() float32, addr string) {
+		if !s.limiter.acquireProxy(addr) {
+			log.Warnf("Not tapping %s, which is already tapped by the maximum of %d taps", addr, s.limiter.maxPerProxy)
+			return
+		}
+		defer s.limiter.releaseProxy(addr)
+		s.tapProxy(ctx, maxRps, match, resources, ranges, addr, events)
+	})
+	defer taps.stop()
+	taps.update(ctx, pods)
+
+	// read events from the taps and send them back, while keeping the taps in
+	// sync with the pods of the target, until the client terminates the stream
+	// or the tap's duration elapses
 	for {
 		select {
-		case <-stream.Context().Done():
+		case <-ctx.Done():
 			return nil
+		case <-updates:
+			pods, err := s.podsFor(req.Target.Resource)
+			if err != nil {
+				log.Errorf("failed to update pods for target %+v: %s", *req.Target.Resource, err)
+				continue
+			}
+			taps.update(ctx, pods)
 		case event := <-events:
 			err := stream.Send(event)
 			if err != nil {
@@ -114,6 +159,30 @@ func (s *server) TapByResource(req *public.TapByResourceRequest, stream pb.Tap_T
 	}
 }
 
+// podsFor returns the meshed pods that currently back a tap target.
+func (s *server) podsFor(resource *public.Resource) ([]*apiv1.Pod, error) {
+	objects, err := s.k8sAPI.GetObjects(resource.Namespace, resource.Type, resource.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := []*apiv1.Pod{}
+	for _, object := range objects {
+		podsFor, err := s.k8sAPI.GetPodsFor(object, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pod := range podsFor {
+			if pkgK8s.IsMeshed(pod, s.controllerNamespace) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+
+	return pods, nil
+}
+
 // TODO: validate scheme
 func parseScheme(scheme string) *httpPb.Scheme {
 	value, ok := httpPb.Scheme_Registered_value[strings.ToUpper(scheme)]
@@ -159,18 +228,11 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 
 	for _, reqMatch := range seq.Matches {
 		switch typed := reqMatch.Match.(type) {
-		case *public.TapByResourceRequest_Match_Destinations:
-
-			for k, v := range destinationLabels(typed.Destinations.Resource) {
-				matches = append(matches, &proxy.ObserveRequest_Match{
-					Match: &proxy.ObserveRequest_Match_DestinationLabel{
-						DestinationLabel: &proxy.ObserveRequest_Match_Label{
-							Key:   k,
-							Value: v,
-						},
-					},
-				})
-			}
+		case *public.TapByResourceRequest_Match_Destinations,
+			*public.TapByResourceRequest_Match_Sources:
+			// sources and destinations are filtered by the tap server, see
+			// resourceFilter
+			continue
 
 		case *public.TapByResourceRequest_Match_Http_:
 
@@ -209,6 +271,9 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 						},
 					},
 				}
+			case *public.TapByResourceRequest_Match_Http_Status:
+				// response status is filtered by the tap server, see statusFilter
+				continue
 			default:
 				return nil, status.Errorf(codes.Unimplemented, "unknown HTTP match type: %v", httpTyped)
 			}
@@ -252,8 +317,9 @@ func destinationLabels(resource *public.Resource) map[string]string {
 // To limit the rps to maxRps, this method calls Observe on the pod with a limit
 // of maxRps * 1s at most once per 1s window.  If this limit is reached in
 // less than 1s, we sleep until the end of the window before calling Observe
-// again.
-func (s *server) tapProxy(ctx context.Context, maxRps float32, match *proxy.ObserveRequest_Match, addr string, events chan *public.TapEvent) {
+// again. maxRps is evaluated for every window, as it changes when pods are
+// added to or removed from the tap.
+func (s *server) tapProxy(ctx context.Context, maxRps func() float32, match *proxy.ObserveRequest_Match, resources *resourceFilter, ranges []*public.TapByResourceRequest_Match_Http_StatusRange, addr string, events chan *public.TapEvent) {
 	tapAddr := fmt.Sprintf("%s:%d", addr, s.tapPort)
 	log.Infof("Establishing tap on %s", tapAddr)
 	conn, err := grpc.DialContext(ctx, tapAddr, grpc.WithInsecure())
@@ -264,44 +330,78 @@ func (s *server) tapProxy(ctx context.Context, maxRps float32, match *proxy.Obse
 	client := proxy.NewTapClient(conn)
 	defer conn.Close()
 
+	for { // Request loop
+		windowStart := time.Now()
+		windowEnd := windowStart.Add(tapInterval)
+		limit := uint32(maxRps() * float32(tapInterval.Seconds()))
+		if !s.observe(ctx, client, match, resources, ranges, limit, addr, events) {
+			return
+		}
+		if time.Now().Before(windowEnd) {
+			time.Sleep(time.Until(windowEnd))
+		}
+	}
+}
+
+// observe calls Observe on a proxy, and sends the events of up to limit
+// requests that pass the filters of the tap server. The requests that are
+// filtered out count against the limit of the proxy, so the proxy is then
+// asked for filteredLimitFactor times as many requests, and the call is ended
+// once the tap's own limit is reached. It returns false if the tap should end.
+func (s *server) observe(ctx context.Context, client proxy.TapClient, match *proxy.ObserveRequest_Match, resources *resourceFilter, ranges []*public.TapByResourceRequest_Match_Http_StatusRange, limit uint32, addr string, events chan *public.TapEvent) bool {
 	req := &proxy.ObserveRequest{
-		Limit: uint32(maxRps * float32(tapInterval.Seconds())),
 		Match: match,
+		Limit: limit,
+	}
+	if !resources.isEmpty() || len(ranges) > 0 {
+		req.Limit = limit * filteredLimitFactor
 	}
 
-	for { // Request loop
-		windowStart := time.Now()
-		windowEnd := windowStart.Add(tapInterval)
-		rsp, err := client.Observe(ctx, req)
+	observeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rsp, err := client.Observe(observeCtx, req)
+	if err != nil {
+		log.Error(err)
+		return false
+	}
+
+	// the streams of a call end along with it, so the state kept about them
+	// lives as long as the call, and is bounded by its limit
+	classifier := newResponseClassifier()
+	filter := newStatusFilter(ranges, int(req.Limit))
+	requests := newRequestLimit(limit)
+
+	for !requests.done() { // Stream loop
+		event, err := rsp.Recv()
+		if err == io.EOF {
+			log.Debugf("[%s] proxy terminated the stream", addr)
+			return true
+		}
 		if err != nil {
-			log.Error(err)
-			return
+			log.Errorf("[%s] encountered an error: %s", addr, err)
+			return false
 		}
-		for { // Stream loop
-			event, err := rsp.Recv()
-			if err == io.EOF {
-				log.Debugf("[%s] proxy terminated the stream", addr)
-				break
-			}
-			if err != nil {
-				log.Errorf("[%s] encountered an error: %s", addr, err)
-				return
-			}
 
-			translatedEvent := s.translateEvent(event)
+		translatedEvent := s.translateEvent(event)
+		if !resources.matches(translatedEvent) {
+			continue
+		}
+		classifier.classify(translatedEvent)
 
+		for _, filteredEvent := range filter.filter(translatedEvent) {
+			if !requests.allow(filteredEvent) {
+				continue
+			}
 			select {
 			case <-ctx.Done():
 				log.Debugf("[%s] client terminated the stream", addr)
-				return
-			default:
-				events <- translatedEvent
+				return false
+			case events <- filteredEvent:
 			}
 		}
-		if time.Now().Before(windowEnd) {
-			time.Sleep(time.Until(windowEnd))
-		}
 	}
+	log.Debugf("[%s] tap reached its limit of %d requests", addr, limit)
+	return true
 }
 
 func (s *server) translateEvent(orig *proxy.TapEvent) *public.TapEvent {
@@ -473,6 +573,7 @@ func (s *server) translateEvent(orig *proxy.TapEvent) *public.TapEvent {
 	}
 
 	s.hydrateEventLabels(ev)
+	s.hydrateExpectedPeerIdentity(ev)
 
 	return ev
 }
@@ -482,6 +583,10 @@ func NewServer(
 	addr string,
 	tapPort uint,
 	controllerNamespace string,
+	trustDomain string,
+	enforceRBAC bool,
+	maxTapsPerProxy uint,
+	maxTapsPerCaller uint,
 	k8sAPI *k8s.API,
 ) (*grpc.Server, net.Listener, error) {
 	k8sAPI.Pod().Informer().AddIndexers(cache.Indexers{podIPIndex: indexPodByIP})
@@ -495,7 +600,11 @@ func NewServer(
 	srv := server{
 		tapPort:             tapPort,
 		k8sAPI:              k8sAPI,
+		podWatcher:          newPodWatcher(k8sAPI),
 		controllerNamespace: controllerNamespace,
+		trustDomain:         trustDomain,
+		enforceRBAC:         enforceRBAC,
+		limiter:             newTapLimiter(maxTapsPerProxy, maxTapsPerCaller),
 	}
 	pb.RegisterTapServer(s, &srv)
 
@@ -533,6 +642,40 @@ func (s *server) hydrateEventLabels(ev *public.TapEvent) {
 
 }
 
+// hydrateExpectedPeerIdentity adds the TLS identity that the peer of the proxy
+// that reported the event, i.e. the source of inbound requests and the
+// destination of outbound ones, is expected to have to the peer's labels, if
+// the request was sent over mTLS. The identity is inferred from the metadata
+// of the peer's pod: the proxies don't report the identity of the certificate
+// their peer presented, if any, so it isn't validated against the connection.
+func (s *server) hydrateExpectedPeerIdentity(ev *public.TapEvent) {
+	var peer *public.TcpAddress
+	var labels map[string]string
+	switch ev.ProxyDirection {
+	case public.TapEvent_INBOUND:
+		peer, labels = ev.Source, ev.SourceMeta.Labels
+	case public.TapEvent_OUTBOUND:
+		peer, labels = ev.Destination, ev.DestinationMeta.Labels
+	default:
+		return
+	}
+	if labels["tls"] != "true" {
+		return
+	}
+
+	pod, err := s.podForIP(peer.GetIp())
+	if err != nil {
+		log.Warnf("error hydrating expected peer identity: %s", err)
+		return
+	}
+	if pod == nil || pod.Labels[pkgK8s.ControllerNSLabel] == "" {
+		return
+	}
+
+	ownerKind, ownerName := s.k8sAPI.GetOwnerKindAndName(pod)
+	labels[pkgK8s.ExpectedTLSIdentityLabel] = pkgK8s.PodIdentity(pod, ownerKind, ownerName, pod.Labels[pkgK8s.ControllerNSLabel], s.trustDomain)
+}
+
 // hydrateIPMeta attempts to determine the metadata labels for `ip` and, if
 // successful, adds them to `labels`.
 func (s *server) hydrateIPLabels(ip *public.IPAddress, labels map[string]string) error {
@@ -5,9 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
 	public "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/client-go/tools/cache"
 )
 
 type tapExpected struct {
@@ -181,6 +184,54 @@ status:
 					},
 				},
 			},
+			tapExpected{
+				msg:    "rpc error: code = InvalidArgument desc = TapByResource received invalid duration",
+				k8sRes: []string{},
+				req: public.TapByResourceRequest{
+					Target: &public.ResourceSelection{
+						Resource: &public.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Pod,
+							Name:      "emojivoto-meshed",
+						},
+					},
+					Duration: &duration.Duration{Seconds: -1},
+				},
+			},
+			tapExpected{
+				// the tap terminates before the client's deadline is exceeded
+				msg: "EOF",
+				k8sRes: []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    linkerd.io/control-plane-ns: controller-ns
+  annotations:
+    linkerd.io/proxy-version: testinjectversion
+status:
+  phase: Running
+`,
+				},
+				req: public.TapByResourceRequest{
+					Target: &public.ResourceSelection{
+						Resource: &public.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Pod,
+							Name:      "emojivoto-meshed",
+						},
+					},
+					Match: &public.TapByResourceRequest_Match{
+						Match: &public.TapByResourceRequest_Match_All{
+							All: &public.TapByResourceRequest_Match_Seq{},
+						},
+					},
+					Duration: &duration.Duration{Nanos: int32(10 * time.Millisecond)},
+				},
+			},
 		}
 
 		for _, exp := range expectations {
@@ -189,7 +240,7 @@ status:
 				t.Fatalf("NewFakeAPI returned an error: %s", err)
 			}
 
-			server, listener, err := NewServer("localhost:0", 0, "controller-ns", k8sAPI)
+			server, listener, err := NewServer("localhost:0", 0, "controller-ns", pkgK8s.DefaultTrustDomain, false, 0, 0, k8sAPI)
 			if err != nil {
 				t.Fatalf("NewServer error: %s", err)
 			}
@@ -222,3 +273,99 @@ status:
 		}
 	})
 }
+
+func TestHydrateExpectedPeerIdentity(t *testing.T) {
+	k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: controller-ns
+status:
+  phase: Running
+  podIP: 10.1.1.1
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-identity
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: controller-ns
+  annotations:
+    linkerd.io/identity-mode: service-account
+spec:
+  serviceAccountName: emoji
+status:
+  phase: Running
+  podIP: 10.1.1.2
+`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Pod().Informer().AddIndexers(cache.Indexers{podIPIndex: indexPodByIP})
+	k8sAPI.Sync(nil)
+
+	srv := &server{k8sAPI: k8sAPI, trustDomain: pkgK8s.DefaultTrustDomain}
+
+	event := func(direction public.TapEvent_ProxyDirection, peerIP, tls string) *public.TapEvent {
+		ip, err := addr.ParsePublicIPV4(peerIP)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		ev := &public.TapEvent{
+			Source:          &public.TcpAddress{Ip: addr.PublicIPV4(10, 1, 1, 9)},
+			SourceMeta:      &public.TapEvent_EndpointMeta{Labels: map[string]string{}},
+			Destination:     &public.TcpAddress{Ip: addr.PublicIPV4(10, 1, 1, 9)},
+			DestinationMeta: &public.TapEvent_EndpointMeta{Labels: map[string]string{}},
+			ProxyDirection:  direction,
+		}
+		peer := ev.DestinationMeta
+		if direction == public.TapEvent_INBOUND {
+			ev.Source.Ip = ip
+			peer = ev.SourceMeta
+		} else {
+			ev.Destination.Ip = ip
+		}
+		peer.Labels["tls"] = tls
+		return ev
+	}
+
+	testCases := []struct {
+		desc     string
+		event    *public.TapEvent
+		identity string
+	}{
+		{
+			desc:     "Adds the identity of the source of inbound requests",
+			event:    event(public.TapEvent_INBOUND, "10.1.1.1", "true"),
+			identity: "emojivoto-meshed.pod.emojivoto.linkerd-managed.controller-ns.svc.cluster.local",
+		},
+		{
+			desc:     "Adds the identity of the destination of outbound requests",
+			event:    event(public.TapEvent_OUTBOUND, "10.1.1.2", "true"),
+			identity: "emoji.emojivoto.serviceaccount.identity.controller-ns.cluster.local",
+		},
+		{
+			desc:     "Doesn't add an identity to requests sent without TLS",
+			event:    event(public.TapEvent_INBOUND, "10.1.1.1", "no_identity"),
+			identity: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			srv.hydrateExpectedPeerIdentity(tc.event)
+
+			labels := tc.event.GetDestinationMeta().GetLabels()
+			if tc.event.GetProxyDirection() == public.TapEvent_INBOUND {
+				labels = tc.event.GetSourceMeta().GetLabels()
+			}
+			if identity := labels[pkgK8s.ExpectedTLSIdentityLabel]; identity != tc.identity {
+				t.Fatalf("Expected identity [%s], got [%s]", tc.identity, identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package tap
+
+import (
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+type streamKey struct {
+	base   uint32
+	stream uint64
+}
+
+// statusFilter drops the events of HTTP streams whose response status does
+// not fall within every one of its ranges. The proxies can't filter on
+// response status, so this is applied by the tap server to the events of a
+// single proxy. Since the status of a stream is only known once its response
+// starts, each RequestInit event is held back until the matching ResponseInit
+// event arrives. At most maxStreams streams are tracked at once, and the events
+// of the streams beyond that are dropped.
+type statusFilter struct {
+	ranges     []*public.TapByResourceRequest_Match_Http_StatusRange
+	maxStreams int
+	pending    map[streamKey]*public.TapEvent
+	matched    map[streamKey]struct{}
+}
+
+func newStatusFilter(ranges []*public.TapByResourceRequest_Match_Http_StatusRange, maxStreams int) *statusFilter {
+	return &statusFilter{
+		ranges:     ranges,
+		maxStreams: maxStreams,
+		pending:    make(map[streamKey]*public.TapEvent),
+		matched:    make(map[streamKey]struct{}),
+	}
+}
+
+// filter returns the events that should be sent to the client after event is
+// received, in the order they should be sent.
+func (f *statusFilter) filter(event *public.TapEvent) []*public.TapEvent {
+	if len(f.ranges) == 0 {
+		return []*public.TapEvent{event}
+	}
+
+	http := event.GetHttp()
+	if http == nil {
+		return nil
+	}
+
+	switch ev := http.GetEvent().(type) {
+	case *public.TapEvent_Http_RequestInit_:
+		if f.full() {
+			return nil
+		}
+		f.pending[keyFor(ev.RequestInit.GetId())] = event
+		return nil
+
+	case *public.TapEvent_Http_ResponseInit_:
+		key := keyFor(ev.ResponseInit.GetId())
+		req, ok := f.pending[key]
+		delete(f.pending, key)
+
+		if !f.matches(ev.ResponseInit.GetHttpStatus()) || (!ok && f.full()) {
+			return nil
+		}
+		f.matched[key] = struct{}{}
+
+		if ok {
+			return []*public.TapEvent{req, event}
+		}
+		return []*public.TapEvent{event}
+
+	case *public.TapEvent_Http_ResponseEnd_:
+		key := keyFor(ev.ResponseEnd.GetId())
+		delete(f.pending, key)
+
+		if _, ok := f.matched[key]; !ok {
+			return nil
+		}
+		delete(f.matched, key)
+		return []*public.TapEvent{event}
+	}
+
+	return nil
+}
+
+func (f *statusFilter) full() bool {
+	return len(f.pending)+len(f.matched) >= f.maxStreams
+}
+
+func (f *statusFilter) matches(status uint32) bool {
+	for _, r := range f.ranges {
+		if status < r.GetMin() || status > r.GetMax() {
+			return false
+		}
+	}
+	return true
+}
+
+func keyFor(id *public.TapEvent_Http_StreamId) streamKey {
+	return streamKey{base: id.GetBase(), stream: id.GetStream()}
+}
+
+// statusRanges returns the status ranges in a request's match, which are
+// applied by the tap server instead of being sent to the proxies.
+func statusRanges(match *public.TapByResourceRequest_Match) []*public.TapByResourceRequest_Match_Http_StatusRange {
+	ranges := []*public.TapByResourceRequest_Match_Http_StatusRange{}
+	for _, reqMatch := range match.GetAll().GetMatches() {
+		if r := reqMatch.GetHttp().GetStatus(); r != nil {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,147 @@
+package tap
+
+import (
+	"testing"
+
+	public "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func requestInit(stream uint64) *public.TapEvent {
+	return &public.TapEvent{
+		Event: &public.TapEvent_Http_{
+			Http: &public.TapEvent_Http{
+				Event: &public.TapEvent_Http_RequestInit_{
+					RequestInit: &public.TapEvent_Http_RequestInit{
+						Id: &public.TapEvent_Http_StreamId{Base: 1, Stream: stream},
+					},
+				},
+			},
+		},
+	}
+}
+
+func responseInit(stream uint64, status uint32) *public.TapEvent {
+	return &public.TapEvent{
+		Event: &public.TapEvent_Http_{
+			Http: &public.TapEvent_Http{
+				Event: &public.TapEvent_Http_ResponseInit_{
+					ResponseInit: &public.TapEvent_Http_ResponseInit{
+						Id:         &public.TapEvent_Http_StreamId{Base: 1, Stream: stream},
+						HttpStatus: status,
+					},
+				},
+			},
+		},
+	}
+}
+
+func responseEnd(stream uint64) *public.TapEvent {
+	return &public.TapEvent{
+		Event: &public.TapEvent_Http_{
+			Http: &public.TapEvent_Http{
+				Event: &public.TapEvent_Http_ResponseEnd_{
+					ResponseEnd: &public.TapEvent_Http_ResponseEnd{
+						Id: &public.TapEvent_Http_StreamId{Base: 1, Stream: stream},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	t.Run("Passes all events through when there are no ranges", func(t *testing.T) {
+		filter := newStatusFilter(nil, 10)
+		event := requestInit(1)
+
+		filtered := filter.filter(event)
+		if len(filtered) != 1 || filtered[0] != event {
+			t.Fatalf("Expected event to be passed through, got %v", filtered)
+		}
+	})
+
+	t.Run("Only sends events for streams with a matching status", func(t *testing.T) {
+		filter := newStatusFilter([]*public.TapByResourceRequest_Match_Http_StatusRange{
+			{Min: 500, Max: 599},
+		}, 10)
+
+		reqOk, rspOk, endOk := requestInit(1), responseInit(1, 200), responseEnd(1)
+		reqErr, rspErr, endErr := requestInit(2), responseInit(2, 503), responseEnd(2)
+
+		sent := []*public.TapEvent{}
+		for _, event := range []*public.TapEvent{reqOk, reqErr, rspOk, rspErr, endOk, endErr} {
+			sent = append(sent, filter.filter(event)...)
+		}
+
+		expected := []*public.TapEvent{reqErr, rspErr, endErr}
+		if len(sent) != len(expected) {
+			t.Fatalf("Expected %d events to be sent, got %d: %v", len(expected), len(sent), sent)
+		}
+		for i := range expected {
+			if sent[i] != expected[i] {
+				t.Fatalf("Expected event %d to be %v, got %v", i, expected[i], sent[i])
+			}
+		}
+
+		if len(filter.pending) != 0 || len(filter.matched) != 0 {
+			t.Fatalf("Expected no streams to be tracked once they ended, got %v and %v", filter.pending, filter.matched)
+		}
+	})
+
+	t.Run("Drops the streams beyond the maximum number of tracked streams", func(t *testing.T) {
+		filter := newStatusFilter([]*public.TapByResourceRequest_Match_Http_StatusRange{
+			{Min: 500, Max: 599},
+		}, 1)
+
+		sent := []*public.TapEvent{}
+		for _, event := range []*public.TapEvent{requestInit(1), requestInit(2), responseInit(2, 503), responseInit(1, 503)} {
+			sent = append(sent, filter.filter(event)...)
+		}
+
+		if len(sent) != 2 || sent[0].GetHttp().GetRequestInit().GetId().GetStream() != 1 {
+			t.Fatalf("Expected the events of the first stream only, got %v", sent)
+		}
+		if len(filter.pending)+len(filter.matched) != 1 {
+			t.Fatalf("Expected 1 stream to be tracked, got %v and %v", filter.pending, filter.matched)
+		}
+	})
+}
+
+func TestStatusRanges(t *testing.T) {
+	statusRange := &public.TapByResourceRequest_Match_Http_StatusRange{Min: 404, Max: 404}
+	match := &public.TapByResourceRequest_Match{
+		Match: &public.TapByResourceRequest_Match_All{
+			All: &public.TapByResourceRequest_Match_Seq{
+				Matches: []*public.TapByResourceRequest_Match{
+					{
+						Match: &public.TapByResourceRequest_Match_Http_{
+							Http: &public.TapByResourceRequest_Match_Http{
+								Match: &public.TapByResourceRequest_Match_Http_Path{Path: "/api"},
+							},
+						},
+					},
+					{
+						Match: &public.TapByResourceRequest_Match_Http_{
+							Http: &public.TapByResourceRequest_Match_Http{
+								Match: &public.TapByResourceRequest_Match_Http_Status{Status: statusRange},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ranges := statusRanges(match)
+	if len(ranges) != 1 || ranges[0] != statusRange {
+		t.Fatalf("Expected status ranges [%v], got %v", statusRange, ranges)
+	}
+
+	proxyMatch, err := makeByResourceMatch(match)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := len(proxyMatch.GetAll().GetMatches()); n != 1 {
+		t.Fatalf("Expected the status range to not be sent to the proxy, got %d matches", n)
+	}
+}
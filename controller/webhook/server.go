@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	log "github.com/sirupsen/logrus"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// maxReviewSize bounds the size of the admission reviews read by the server.
+const maxReviewSize = 1 << 20
+
+// Handler responds to the admission requests sent by the Kubernetes API server
+// to a mutating or validating webhook. dryRun is true if the request's changes
+// won't be persisted, in which case the handler must not have side effects.
+type Handler func(req *admissionV1beta1.AdmissionRequest, dryRun bool) *admissionV1beta1.AdmissionResponse
+
+// NewServer returns a TLS server that serves handler to the Kubernetes API
+// server, which calls it through the webhook configuration created by
+// `linkerd install`.
+func NewServer(addr string, handler http.Handler, cert tls.Certificate) *http.Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: prometheus.WithTelemetry(handler),
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		},
+	}
+}
+
+// ServeHTTP decodes the admission review POSTed by the Kubernetes API server,
+// and responds with the review of its request by h.
+func (h Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "admission reviews must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxReviewSize))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var review admissionV1beta1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(rw, "admission review has no request", http.StatusBadRequest)
+		return
+	}
+
+	// the dryRun field of admission requests is newer than the vendored
+	// admission API, so it's decoded on its own
+	var dryRun struct {
+		Request struct {
+			DryRun bool `json:"dryRun"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(body, &dryRun); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h(review.Request, dryRun.Request.DryRun)
+	review.Request = nil
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Errorf("failed to write admission review: %s", err)
+	}
+}
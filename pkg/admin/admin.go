@@ -12,10 +12,18 @@ import (
 type handler struct {
 	promHandler http.Handler
 	ready       bool
+	draining    bool
 	sync.RWMutex
 }
 
 func StartServer(addr string, readyCh <-chan struct{}) {
+	StartDrainableServer(addr, readyCh, nil)
+}
+
+// StartDrainableServer is like StartServer, but the process is reported
+// unready once drainCh is closed, so that it's removed from the endpoints of
+// its services while its servers drain, see Drainer.
+func StartDrainableServer(addr string, readyCh, drainCh <-chan struct{}) {
 	log.Infof("starting admin server on %s", addr)
 
 	h := &handler{
@@ -30,6 +38,13 @@ func StartServer(addr string, readyCh <-chan struct{}) {
 		}()
 	}
 
+	if drainCh != nil {
+		go func() {
+			<-drainCh
+			h.setDraining()
+		}()
+	}
+
 	s := &http.Server{
 		Addr:         addr,
 		Handler:      h,
@@ -68,7 +83,7 @@ func (h *handler) serveReady(w http.ResponseWriter, req *http.Request) {
 func (h *handler) getReady() bool {
 	h.RLock()
 	defer h.RUnlock()
-	return h.ready
+	return h.ready && !h.draining
 }
 
 func (h *handler) setReady(ready bool) {
@@ -76,3 +91,9 @@ func (h *handler) setReady(ready bool) {
 	defer h.Unlock()
 	h.ready = ready
 }
+
+func (h *handler) setDraining() {
+	h.Lock()
+	defer h.Unlock()
+	h.draining = true
+}
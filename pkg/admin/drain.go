@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// Drainer shuts down the servers of a process gracefully when it's told to
+// stop, e.g. during a rolling upgrade: the process is first reported unready,
+// and keeps serving for Delay so that it's removed from the endpoints of its
+// services. Its servers then stop accepting new connections and streams, and
+// the in-flight requests and streams, such as taps, are given Period to
+// complete before they're aborted.
+type Drainer struct {
+	Delay  time.Duration
+	Period time.Duration
+
+	draining chan struct{}
+
+	streamsMutex sync.Mutex
+	streams      map[io.Closer]struct{}
+}
+
+// streamsPollInterval is how often DrainHTTP checks whether the tracked
+// streams have completed, as http.Server.Shutdown does for its connections.
+const streamsPollInterval = 100 * time.Millisecond
+
+// NewDrainer returns a Drainer whose Draining channel is to be passed to
+// StartDrainableServer.
+func NewDrainer(delay, period time.Duration) *Drainer {
+	return &Drainer{
+		Delay:    delay,
+		Period:   period,
+		draining: make(chan struct{}),
+		streams:  make(map[io.Closer]struct{}),
+	}
+}
+
+// Draining returns a channel that's closed once the process starts draining.
+func (d *Drainer) Draining() <-chan struct{} {
+	return d.draining
+}
+
+// Start reports the process unready, and returns after Delay, once the servers
+// can stop accepting new connections.
+func (d *Drainer) Start() {
+	log.Infof("draining: reporting unready for %s before stopping the servers", d.Delay)
+	close(d.draining)
+	time.Sleep(d.Delay)
+}
+
+// DrainGRPC stops server from accepting new streams, and waits for the
+// in-flight ones to complete, up to Period.
+func (d *Drainer) DrainGRPC(server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(d.Period):
+		log.Warnf("draining: aborting the streams still in flight after %s", d.Period)
+		server.Stop()
+	}
+}
+
+// TrackStream records a stream served over a connection hijacked from an
+// http.Server, e.g. a websocket, whose Shutdown doesn't wait for it. The
+// returned function is to be called once the stream completes.
+func (d *Drainer) TrackStream(stream io.Closer) func() {
+	d.streamsMutex.Lock()
+	d.streams[stream] = struct{}{}
+	d.streamsMutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.streamsMutex.Lock()
+			delete(d.streams, stream)
+			d.streamsMutex.Unlock()
+		})
+	}
+}
+
+// DrainHTTP stops servers from accepting new connections, and waits for the
+// in-flight requests and tracked streams to complete, up to Period.
+func (d *Drainer) DrainHTTP(servers ...*http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.Period)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Warnf("draining: aborting the requests to %s still in flight after %s", server.Addr, d.Period)
+				server.Close()
+			}
+		}(server)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.drainStreams(ctx)
+	}()
+	wg.Wait()
+}
+
+// drainStreams waits for the tracked streams to complete, and closes the ones
+// still open once ctx is done.
+func (d *Drainer) drainStreams(ctx context.Context) {
+	ticker := time.NewTicker(streamsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.streamsMutex.Lock()
+		open := len(d.streams)
+		if open > 0 && ctx.Err() != nil {
+			log.Warnf("draining: aborting the %d streams still open after %s", open, d.Period)
+			for stream := range d.streams {
+				stream.Close()
+				delete(d.streams, stream)
+			}
+			open = 0
+		}
+		d.streamsMutex.Unlock()
+		if open == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
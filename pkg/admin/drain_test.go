@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrainer(t *testing.T) {
+	t.Run("Reports the process unready once draining", func(t *testing.T) {
+		drainer := NewDrainer(0, time.Second)
+		h := &handler{ready: true}
+		go func() {
+			<-drainer.Draining()
+			h.setDraining()
+		}()
+
+		drainer.Start()
+
+		// the handler is notified asynchronously
+		deadline := time.Now().Add(time.Second)
+		for h.getReady() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		rsp := httptest.NewRecorder()
+		h.serveReady(rsp, httptest.NewRequest("GET", "/ready", nil))
+		if rsp.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected the process to be unready while draining, got status %d", rsp.Code)
+		}
+	})
+
+	t.Run("Aborts the requests still in flight after the drain period", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "localhost:0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		started := make(chan struct{})
+		server := &http.Server{
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				close(started)
+				<-req.Context().Done()
+			}),
+		}
+		go server.Serve(lis)
+
+		go http.Get("http://" + lis.Addr().String())
+		<-started
+
+		drainer := NewDrainer(0, 50*time.Millisecond)
+		done := make(chan struct{})
+		go func() {
+			drainer.DrainHTTP(server)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the server to be shut down after the drain period")
+		}
+	})
+
+	t.Run("Waits for the tracked streams to complete", func(t *testing.T) {
+		drainer := NewDrainer(0, 5*time.Second)
+		stream := &fakeStream{closed: make(chan struct{})}
+		untrack := drainer.TrackStream(stream)
+
+		done := make(chan struct{})
+		go func() {
+			drainer.DrainHTTP()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			t.Fatalf("Expected the drain to wait for the tracked stream")
+		case <-time.After(2 * streamsPollInterval):
+		}
+
+		untrack()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the drain to complete once the stream completed")
+		}
+		if stream.isClosed() {
+			t.Fatalf("Expected the completed stream not to be closed by the drain")
+		}
+	})
+
+	t.Run("Closes the tracked streams still open after the drain period", func(t *testing.T) {
+		drainer := NewDrainer(0, 50*time.Millisecond)
+		stream := &fakeStream{closed: make(chan struct{})}
+		drainer.TrackStream(stream)
+
+		done := make(chan struct{})
+		go func() {
+			drainer.DrainHTTP()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the drain to complete after the drain period")
+		}
+		if !stream.isClosed() {
+			t.Fatalf("Expected the stream still open to be closed")
+		}
+	})
+}
+
+type fakeStream struct {
+	closed chan struct{}
+}
+
+func (s *fakeStream) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func (s *fakeStream) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
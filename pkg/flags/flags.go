@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/linkerd/linkerd2/pkg/prometheus"
 	"github.com/linkerd/linkerd2/pkg/version"
 	log "github.com/sirupsen/logrus"
 )
@@ -20,10 +21,13 @@ func ConfigureAndParse() {
 	logLevel := flag.String("log-level", log.InfoLevel.String(),
 		"log level, must be one of: panic, fatal, error, warn, info, debug")
 	printVersion := flag.Bool("version", false, "print version and exit")
+	latencyBuckets := flag.String("latency-buckets", "",
+		"comma-separated upper bounds, in milliseconds, of the latency histogram buckets; the default buckets are used if empty")
 
 	flag.Parse()
 
 	setLogLevel(*logLevel)
+	setLatencyBuckets(*latencyBuckets)
 	maybePrintVersionAndExit(*printVersion)
 }
 
@@ -35,6 +39,17 @@ func setLogLevel(logLevel string) {
 	log.SetLevel(level)
 }
 
+func setLatencyBuckets(latencyBuckets string) {
+	if latencyBuckets == "" {
+		return
+	}
+	buckets, err := prometheus.ParseLatencyBuckets(latencyBuckets)
+	if err != nil {
+		log.Fatalf("invalid latency-buckets: %s", err)
+	}
+	prometheus.SetLatencyBuckets(buckets)
+}
+
 func maybePrintVersionAndExit(printVersion bool) {
 	if printVersion {
 		fmt.Println(version.Version)
@@ -1,17 +1,27 @@
 package healthcheck
 
 import (
+	"bytes"
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/linkerd/linkerd2/controller/api/public"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	pkgprom "github.com/linkerd/linkerd2/pkg/prometheus"
+	"github.com/linkerd/linkerd2/pkg/publicapi"
+	"github.com/linkerd/linkerd2/pkg/registry"
 	"github.com/linkerd/linkerd2/pkg/version"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
 	authorizationapi "k8s.io/api/authorization/v1beta1"
 	"k8s.io/api/core/v1"
 	k8sVersion "k8s.io/apimachinery/pkg/version"
@@ -60,6 +70,49 @@ const (
 	LinkerdVersionCategory    = "linkerd-version"
 )
 
+// prometheusURLKey is the key of the install config that records the URL of
+// the existing Prometheus server that the control plane was installed with.
+const prometheusURLKey = "prometheus-url"
+
+// grafanaURLKey is the key of the install config that records the URL of the
+// existing Grafana server that the control plane was installed with.
+const grafanaURLKey = "grafana-url"
+
+// linkerdVersionKey is the key of the install config that records the version
+// of the control plane.
+const linkerdVersionKey = "linkerd-version"
+
+// proxyArchitecturesKey is the key of the install config that records the
+// architectures that the proxy images are published for.
+const proxyArchitecturesKey = "proxy-architectures"
+
+// dashboardReadyPath is the path of the readiness endpoint of the dashboard,
+// proxied by the Kubernetes API, which checks that the dashboard can query
+// the public API and Grafana.
+const dashboardReadyPath = "/services/web:http/proxy/ready"
+
+// caAdminPortName is the name of the port on which the CA serves its metrics,
+// which export the expirations of the certificates it issues.
+const caAdminPortName = "admin-http"
+
+// The metrics of the CA that export the expirations of certificates.
+const (
+	certificateExpirationMetric     = "certificate_expiration_seconds"
+	certificateRenewalFailureMetric = "certificate_renewal_failure_seconds"
+
+	issuerCertificateType = "issuer"
+)
+
+// DefaultCertExpiryWarningThreshold is how long before they expire the
+// certificates that aren't renewed by the control plane itself, such as the
+// issuer certificate and the serving certificates of the webhooks, are
+// reported as about to expire.
+const DefaultCertExpiryWarningThreshold = 30 * 24 * time.Hour
+
+// proxyTrustAnchorsEnvVar is the environment variable of the proxies injected
+// with TLS that locates the trust anchors of their namespace.
+const proxyTrustAnchorsEnvVar = "LINKERD2_PROXY_TLS_TRUST_ANCHORS"
+
 var (
 	maxRetries  = 60
 	retryWindow = 5 * time.Second
@@ -79,6 +132,17 @@ type CheckResult struct {
 	Description string
 	Retry       bool
 	Err         error
+
+	// CategoryOrder is the position of the check's category among the
+	// categories of the HealthChecker, and CheckOrder the position of the
+	// check among its checks. SubCheckOrder is the position, starting at 1, of
+	// a result of the control plane's self-check among the ones returned by
+	// the check's RPC, and 0 for the result of the check itself. Together,
+	// they order the results the way RunChecks observes them, see
+	// CheckResults.
+	CategoryOrder int
+	CheckOrder    int
+	SubCheckOrder int
 }
 
 type checkObserver func(*CheckResult)
@@ -87,12 +151,22 @@ type HealthCheckOptions struct {
 	ControlPlaneNamespace          string
 	DataPlaneNamespace             string
 	KubeConfig                     string
+	KubeContext                    string
+	Impersonate                    string
+	ImpersonateGroup               []string
 	APIAddr                        string
 	VersionOverride                string
 	ShouldRetry                    bool
 	ShouldCheckKubeVersion         bool
 	ShouldCheckControlPlaneVersion bool
 	ShouldCheckDataPlaneVersion    bool
+	// CertExpiryWarningThreshold overrides
+	// DefaultCertExpiryWarningThreshold when set.
+	CertExpiryWarningThreshold time.Duration
+	// PinnedImages are the images, pinned by their digests, that the control
+	// plane is installed with, which the pre-install checks check can be
+	// pulled.
+	PinnedImages []string
 }
 
 type HealthChecker struct {
@@ -105,6 +179,7 @@ type HealthChecker struct {
 	clientset        *kubernetes.Clientset
 	kubeVersion      *k8sVersion.Info
 	controlPlanePods []v1.Pod
+	installConfig    *v1.ConfigMap
 	apiClient        pb.ApiClient
 	dataPlanePods    []v1.Pod
 	latestVersion    string
@@ -140,7 +215,7 @@ func (hc *HealthChecker) addKubernetesAPIChecks() {
 		description: "can initialize the client",
 		fatal:       true,
 		check: func() (err error) {
-			hc.kubeAPI, err = k8s.NewAPI(hc.KubeConfig)
+			hc.kubeAPI, err = k8s.NewAPI(hc.KubeConfig, hc.KubeContext, hc.Impersonate, hc.ImpersonateGroup)
 			return
 		},
 	})
@@ -168,10 +243,34 @@ func (hc *HealthChecker) addKubernetesAPIChecks() {
 				return hc.kubeAPI.CheckVersion(hc.kubeVersion)
 			},
 		})
+
+		hc.checkers = append(hc.checkers, &checker{
+			category:    KubernetesAPICategory,
+			description: "serves the admission webhooks of the proxy injector",
+			fatal:       false,
+			check: func() error {
+				capabilities, err := hc.kubeAPI.GetCapabilities(hc.httpClient)
+				if err != nil {
+					return err
+				}
+				return validateCapabilities(capabilities)
+			},
+		})
 	}
 }
 
 func (hc *HealthChecker) addLinkerdPreInstallChecks() {
+	if len(hc.PinnedImages) > 0 {
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdPreInstallCategory,
+			description: "can pull the pinned images",
+			fatal:       false,
+			check: func() error {
+				return checkImagesPullable(registry.NewClient(), hc.PinnedImages)
+			},
+		})
+	}
+
 	hc.checkers = append(hc.checkers, &checker{
 		category:    LinkerdPreInstallCategory,
 		description: "control plane namespace does not already exist",
@@ -269,11 +368,97 @@ func (hc *HealthChecker) addLinkerdAPIChecks() {
 		fatal:       true,
 		check: func() error {
 			var err error
+			hc.installConfig, err = hc.kubeAPI.GetConfigMap(hc.httpClient, hc.ControlPlaneNamespace, k8s.InstallConfigMapName)
+			if err != nil {
+				return err
+			}
 			hc.controlPlanePods, err = hc.kubeAPI.GetPodsByNamespace(hc.httpClient, hc.ControlPlaneNamespace)
 			if err != nil {
 				return err
 			}
-			return validateControlPlanePods(hc.controlPlanePods)
+			return validateControlPlanePods(hc.controlPlanePods, hc.installConfig)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "control plane images match the install config",
+		fatal:       false,
+		check: func() error {
+			return validateControlPlaneImages(hc.controlPlanePods, hc.installConfig)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "proxy images are published for the architectures of the nodes",
+		fatal:       false,
+		check: func() error {
+			nodes, err := hc.kubeAPI.GetNodes(hc.httpClient)
+			if err != nil {
+				return err
+			}
+			return validateNodeArchitectures(nodes, hc.installConfig)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "control plane pods meet the namespace's pod security level",
+		fatal:       false,
+		check: func() error {
+			namespace, err := hc.kubeAPI.GetNamespace(hc.httpClient, hc.ControlPlaneNamespace)
+			if err != nil {
+				return err
+			}
+			capabilities, err := hc.kubeAPI.GetCapabilities(hc.httpClient)
+			if err != nil {
+				return err
+			}
+			var profiles k8s.SeccompProfiles
+			if capabilities.HasSeccompProfileField() {
+				profiles, err = hc.kubeAPI.GetSeccompProfiles(hc.httpClient, hc.ControlPlaneNamespace)
+				if err != nil {
+					return err
+				}
+			}
+			return validateControlPlanePodSecurity(namespace, hc.controlPlanePods, profiles)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "certificates are renewed before they expire",
+		fatal:       false,
+		check: func() error {
+			metrics, err := hc.getCAMetrics()
+			if err != nil {
+				return err
+			}
+			return validateCertificateExpirations(metrics, hc.certExpiryWarningThreshold(), time.Now())
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "webhook and API server serving certificates are not about to expire",
+		fatal:       false,
+		check: func() error {
+			servers := []struct{ name, secret string }{
+				{"the proxy injector webhook", k8s.ProxyInjectorTLSSecretName},
+				{"the service profile validator webhook", k8s.ProfileValidatorTLSSecretName},
+				{"the tap API server", k8s.TapAPIServerTLSSecretName},
+			}
+			for _, server := range servers {
+				secret, err := hc.kubeAPI.GetSecret(hc.httpClient, hc.ControlPlaneNamespace, server.secret)
+				if err != nil {
+					return err
+				}
+				if err := validateServingCertificate(server.name, secret, hc.certExpiryWarningThreshold(), time.Now()); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	})
 
@@ -282,11 +467,7 @@ func (hc *HealthChecker) addLinkerdAPIChecks() {
 		description: "can initialize the client",
 		fatal:       true,
 		check: func() (err error) {
-			if hc.APIAddr != "" {
-				hc.apiClient, err = public.NewInternalClient(hc.ControlPlaneNamespace, hc.APIAddr)
-			} else {
-				hc.apiClient, err = public.NewExternalClient(hc.ControlPlaneNamespace, hc.kubeAPI)
-			}
+			hc.apiClient, err = publicapi.NewAPIClient(hc.ControlPlaneNamespace, hc.APIAddr, hc.kubeAPI)
 			return
 		},
 	})
@@ -301,6 +482,19 @@ func (hc *HealthChecker) addLinkerdAPIChecks() {
 			return hc.apiClient.SelfCheck(ctx, &healthcheckPb.SelfCheckRequest{})
 		},
 	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "dashboard is ready",
+		fatal:       false,
+		check: func() error {
+			status, body, err := hc.getDashboardReadiness()
+			if err != nil {
+				return err
+			}
+			return validateDashboardReadiness(status, body)
+		},
+	})
 }
 
 func (hc *HealthChecker) addLinkerdDataPlaneChecks() {
@@ -331,7 +525,45 @@ func (hc *HealthChecker) addLinkerdDataPlaneChecks() {
 				return err
 			}
 
-			return validateDataPlanePods(hc.dataPlanePods, hc.DataPlaneNamespace)
+			err = validateDataPlanePods(hc.dataPlanePods, hc.DataPlaneNamespace)
+			if len(hc.dataPlanePods) == 0 && hc.DataPlaneNamespace != "" {
+				err = hc.describeUnmeshedWorkloads(err)
+			}
+			return err
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdDataPlaneCategory,
+		description: "data plane proxies match the control plane version",
+		fatal:       false,
+		check: func() error {
+			return validateDataPlaneProxyVersions(hc.dataPlanePods, hc.installConfig)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdDataPlaneCategory,
+		description: "data plane proxies use the current trust anchors",
+		fatal:       false,
+		check: func() error {
+			controlPlaneBundle, err := hc.kubeAPI.GetConfigMap(hc.httpClient, hc.ControlPlaneNamespace, k8s.TLSTrustAnchorConfigMapName)
+			if err != nil || controlPlaneBundle == nil {
+				return err
+			}
+
+			bundles := make(map[string]*v1.ConfigMap)
+			for _, pod := range hc.dataPlanePods {
+				if _, ok := bundles[pod.Namespace]; ok {
+					continue
+				}
+				bundles[pod.Namespace], err = hc.kubeAPI.GetConfigMap(hc.httpClient, pod.Namespace, k8s.TLSTrustAnchorConfigMapName)
+				if err != nil {
+					return err
+				}
+			}
+
+			return validateDataPlaneTrustAnchors(hc.dataPlanePods, controlPlaneBundle, bundles)
 		},
 	})
 
@@ -438,10 +670,31 @@ func (hc *HealthChecker) Add(category, description string, check func() error) {
 func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
 	success := true
 
-	for _, checker := range hc.checkers {
+	// the checks of each category are recorded in the health check metrics,
+	// which are exported when the checks run in a process of the control plane
+	var category string
+	var categoryStart time.Time
+	categorySuccess := true
+	categoryOrder := -1
+
+	for i, checker := range hc.checkers {
+		if checker.category != category {
+			if category != "" {
+				pkgprom.ObserveHealthCheck(category, categoryStart, categorySuccess)
+			}
+			category, categoryStart, categorySuccess = checker.category, time.Now(), true
+			categoryOrder++
+		}
+
+		checkOrder := i
+		orderedObserver := func(result *CheckResult) {
+			result.CategoryOrder, result.CheckOrder = categoryOrder, checkOrder
+			observer(result)
+		}
+
 		if checker.check != nil {
-			if !hc.runCheck(checker, observer) {
-				success = false
+			if !hc.runCheck(checker, orderedObserver) {
+				success, categorySuccess = false, false
 				if checker.fatal {
 					break
 				}
@@ -449,8 +702,8 @@ func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
 		}
 
 		if checker.checkRPC != nil {
-			if !hc.runCheckRPC(checker, observer) {
-				success = false
+			if !hc.runCheckRPC(checker, orderedObserver) {
+				success, categorySuccess = false, false
 				if checker.fatal {
 					break
 				}
@@ -458,6 +711,10 @@ func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
 		}
 	}
 
+	if category != "" {
+		pkgprom.ObserveHealthCheck(category, categoryStart, categorySuccess)
+	}
+
 	return success
 }
 
@@ -468,7 +725,7 @@ func (hc *HealthChecker) runCheck(c *checker, observer checkObserver) bool {
 	}
 
 	for {
-		err := c.check()
+		err := describeKubernetesError(c.check())
 		checkResult := &CheckResult{
 			Category:    c.category,
 			Description: c.description,
@@ -477,6 +734,12 @@ func (hc *HealthChecker) runCheck(c *checker, observer checkObserver) bool {
 
 		if err != nil && retries > 0 {
 			retries--
+			log.WithFields(log.Fields{
+				"category":    c.category,
+				"check":       c.description,
+				"retriesLeft": retries,
+				"error":       err,
+			}).Debugf("Retrying check in %s", retryWindow)
 			checkResult.Retry = true
 			observer(checkResult)
 			time.Sleep(retryWindow)
@@ -488,6 +751,28 @@ func (hc *HealthChecker) runCheck(c *checker, observer checkObserver) bool {
 	}
 }
 
+// validateCapabilities explains the features of Linkerd that the optional
+// APIs missing from the cluster prevent from working.
+func validateCapabilities(capabilities *k8s.Capabilities) error {
+	if capabilities.AdmissionRegistrationVersion() == "" {
+		return fmt.Errorf("The admissionregistration.k8s.io API isn't enabled, so pods can't be injected automatically with --proxy-auto-inject; inject them with `linkerd inject` instead")
+	}
+	return nil
+}
+
+// describeKubernetesError adds a hint on how to fix the errors of the requests
+// to the Kubernetes API that were refused or couldn't reach it.
+func describeKubernetesError(err error) error {
+	switch {
+	case k8s.IsUnauthorized(err):
+		return fmt.Errorf("%s; check the permissions of the kubeconfig's user, or of the impersonated user and groups", err)
+	case k8s.IsConnectionError(err):
+		return fmt.Errorf("%s; check that the cluster of the kubeconfig's context is reachable", err)
+	default:
+		return err
+	}
+}
+
 func (hc *HealthChecker) runCheckRPC(c *checker, observer checkObserver) bool {
 	checkRsp, err := c.checkRPC()
 	observer(&CheckResult{
@@ -499,15 +784,16 @@ func (hc *HealthChecker) runCheckRPC(c *checker, observer checkObserver) bool {
 		return false
 	}
 
-	for _, check := range checkRsp.Results {
+	for i, check := range checkRsp.Results {
 		var err error
 		if check.Status != healthcheckPb.CheckStatus_OK {
 			err = fmt.Errorf(check.FriendlyMessageToUser)
 		}
 		observer(&CheckResult{
-			Category:    fmt.Sprintf("%s[%s]", c.category, check.SubsystemName),
-			Description: check.CheckDescription,
-			Err:         err,
+			Category:      fmt.Sprintf("%s[%s]", c.category, check.SubsystemName),
+			Description:   check.CheckDescription,
+			Err:           err,
+			SubCheckOrder: i + 1,
 		})
 		if err != nil {
 			return false
@@ -572,7 +858,172 @@ func (hc *HealthChecker) checkCanCreate(namespace, group, version, resource stri
 	return nil
 }
 
-func validateControlPlanePods(pods []v1.Pod) error {
+func (hc *HealthChecker) certExpiryWarningThreshold() time.Duration {
+	if hc.CertExpiryWarningThreshold > 0 {
+		return hc.CertExpiryWarningThreshold
+	}
+	return DefaultCertExpiryWarningThreshold
+}
+
+// getDashboardReadiness queries the readiness endpoint of the dashboard
+// through the Kubernetes API, returning the status and body of its response.
+func (hc *HealthChecker) getDashboardReadiness() (int, []byte, error) {
+	url, err := hc.kubeAPI.UrlFor(hc.ControlPlaneNamespace, dashboardReadyPath)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	rsp, err := hc.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rsp.Body.Close()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rsp.StatusCode, body, nil
+}
+
+// validateDashboardReadiness explains why the dashboard isn't ready, given the
+// response of its readiness endpoint.
+func validateDashboardReadiness(status int, body []byte) error {
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusServiceUnavailable:
+		return fmt.Errorf("The dashboard is not ready: %s", strings.TrimSpace(string(body)))
+	default:
+		return fmt.Errorf("The dashboard is not reachable: unexpected response status %d", status)
+	}
+}
+
+// getCAMetrics returns the metrics of the CA, scraped through the Kubernetes
+// API, or nil if the control plane was installed without TLS.
+func (hc *HealthChecker) getCAMetrics() ([]byte, error) {
+	for _, pod := range hc.controlPlanePods {
+		if pod.Labels[k8s.ControllerComponentLabel] != "ca" || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s:%s/proxy/metrics", pod.Namespace, pod.Name, caAdminPortName)
+		return hc.kubeAPI.GetResource(hc.httpClient, path)
+	}
+	return nil, nil
+}
+
+// validateCertificateExpirations checks that none of the certificates whose
+// expirations are exported by the CA are about to expire: the proxies'
+// certificates mustn't be within their renewal-failure window, in which they
+// should already have been renewed, and the issuer certificate, which is
+// renewed outside of the control plane, mustn't expire within threshold.
+func validateCertificateExpirations(metrics []byte, threshold time.Duration, now time.Time) error {
+	if metrics == nil {
+		return nil
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(metrics))
+	if err != nil {
+		return fmt.Errorf("Failed to parse the CA metrics: %s", err)
+	}
+
+	labels := func(metric *dto.Metric) (string, string) {
+		var certType, identity string
+		for _, label := range metric.GetLabel() {
+			switch label.GetName() {
+			case "type":
+				certType = label.GetValue()
+			case "identity":
+				identity = label.GetValue()
+			}
+		}
+		return certType, identity
+	}
+
+	failing := []string{}
+	expirations := make(map[string]time.Time)
+	for _, metric := range families[certificateExpirationMetric].GetMetric() {
+		certType, identity := labels(metric)
+		expiration := time.Unix(int64(metric.GetGauge().GetValue()), 0)
+		expirations[certType+"/"+identity] = expiration
+		if certType == issuerCertificateType && now.Add(threshold).After(expiration) {
+			failing = append(failing, fmt.Sprintf("the %s certificate of %s, which expires at %s",
+				certType, identity, expiration.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	for _, metric := range families[certificateRenewalFailureMetric].GetMetric() {
+		certType, identity := labels(metric)
+		if certType == issuerCertificateType || now.Before(time.Unix(int64(metric.GetGauge().GetValue()), 0)) {
+			continue
+		}
+		failing = append(failing, fmt.Sprintf("the %s certificate of %s, which expires at %s",
+			certType, identity, expirations[certType+"/"+identity].UTC().Format(time.RFC3339)))
+	}
+	if len(failing) == 0 {
+		return nil
+	}
+
+	sort.Strings(failing)
+	return fmt.Errorf("Certificates haven't been renewed and are about to expire: %s", strings.Join(failing, "; "))
+}
+
+// validateServingCertificate checks that the serving certificate of a webhook
+// or API server, which is issued at install time and never renewed by the
+// control plane, doesn't expire within threshold. The secret is nil if the
+// server wasn't installed.
+func validateServingCertificate(server string, secret *v1.Secret, threshold time.Duration, now time.Time) error {
+	if secret == nil {
+		return nil
+	}
+
+	block, _ := pem.Decode(secret.Data[k8s.ServingTLSCertKey])
+	if block == nil {
+		return fmt.Errorf("The serving certificate of %s is not PEM-encoded", server)
+	}
+	crt, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("Failed to parse the serving certificate of %s: %s", server, err)
+	}
+
+	if now.Add(threshold).After(crt.NotAfter) {
+		return fmt.Errorf("The serving certificate of %s is about to expire: it expires at %s", server, crt.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkImagesPullable checks that the images pinned by their digests can be
+// pulled from their registries, such as the mirror of an air-gapped cluster,
+// from where the CLI runs.
+func checkImagesPullable(client *registry.Client, images []string) error {
+	unpullable := []string{}
+	for _, image := range images {
+		ref, err := registry.ParseReference(image)
+		if err == nil {
+			err = client.CheckPullable(ref)
+		}
+		if err != nil {
+			unpullable = append(unpullable, fmt.Sprintf("%s (%s)", image, err))
+		}
+	}
+	if len(unpullable) > 0 {
+		return fmt.Errorf("Some pinned images can't be pulled: %s", strings.Join(unpullable, ", "))
+	}
+	return nil
+}
+
+// validateControlPlanePods checks that the pods of the control plane are
+// running and ready. Prometheus isn't expected when the control plane was
+// installed with an existing Prometheus server.
+func validateControlPlanePods(pods []v1.Pod, installConfig *v1.ConfigMap) error {
 	statuses := make(map[string][]v1.ContainerStatus)
 
 	for _, pod := range pods {
@@ -585,7 +1036,14 @@ func validateControlPlanePods(pods []v1.Pod) error {
 		}
 	}
 
-	names := []string{"controller", "grafana", "prometheus", "web"}
+	names := []string{"controller"}
+	if installConfig == nil || installConfig.Data[grafanaURLKey] == "" {
+		names = append(names, "grafana")
+	}
+	if installConfig == nil || installConfig.Data[prometheusURLKey] == "" {
+		names = append(names, "prometheus")
+	}
+	names = append(names, "web")
 	if _, found := statuses["ca"]; found {
 		names = append(names, "ca")
 	}
@@ -606,6 +1064,307 @@ func validateControlPlanePods(pods []v1.Pod) error {
 	return nil
 }
 
+// validateControlPlaneImages checks that the containers of the control plane
+// run the images recorded by `linkerd install`, e.g. that they are pulled from
+// the configured registry. Control planes installed before the images were
+// recorded are not validated.
+func validateControlPlaneImages(pods []v1.Pod, installConfig *v1.ConfigMap) error {
+	if installConfig == nil {
+		return nil
+	}
+
+	images := make(map[string]bool)
+	for key, image := range installConfig.Data {
+		if strings.HasSuffix(key, "-image") {
+			images[image] = true
+		}
+	}
+
+	for _, pod := range pods {
+		// only the pods rendered by `linkerd install` are injected, as opposed
+		// to e.g. the pods of the CNI plugin
+		if pod.Labels[k8s.ControllerNSLabel] == "" {
+			continue
+		}
+		containers := []v1.Container{}
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, container := range containers {
+			if !images[container.Image] {
+				return fmt.Errorf("The \"%s\" pod's \"%s\" container runs the \"%s\" image, which isn't recorded in the \"%s\" ConfigMap",
+					pod.Name, container.Name, container.Image, k8s.InstallConfigMapName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNodeArchitectures checks that the proxy images are published for the
+// architectures of the nodes, so that the pods scheduled on any node can be
+// injected. Control planes installed before the architectures were recorded
+// are not validated.
+func validateNodeArchitectures(nodes []v1.Node, installConfig *v1.ConfigMap) error {
+	if installConfig == nil || installConfig.Data[proxyArchitecturesKey] == "" {
+		return nil
+	}
+
+	architectures := make(map[string]bool)
+	for _, arch := range strings.Split(installConfig.Data[proxyArchitecturesKey], ",") {
+		architectures[arch] = true
+	}
+
+	unsupported := []string{}
+	for _, node := range nodes {
+		if arch := node.Status.NodeInfo.Architecture; arch != "" && !architectures[arch] {
+			unsupported = append(unsupported, fmt.Sprintf("%s (%s)", node.Name, arch))
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("The proxy images aren't published for the architectures of some nodes, whose pods can't be injected: %s; the proxy images are published for %s",
+			strings.Join(unsupported, ", "), installConfig.Data[proxyArchitecturesKey])
+	}
+
+	return nil
+}
+
+// validateDataPlaneProxyVersions checks that the proxies of the data plane run
+// the version that the control plane was installed with. Control planes
+// installed before the version was recorded are not validated.
+func validateDataPlaneProxyVersions(pods []v1.Pod, installConfig *v1.ConfigMap) error {
+	if installConfig == nil || installConfig.Data[linkerdVersionKey] == "" {
+		return nil
+	}
+	version := installConfig.Data[linkerdVersionKey]
+
+	for _, pod := range pods {
+		if proxyVersion := pod.Annotations[k8s.ProxyVersionAnnotation]; proxyVersion != "" && proxyVersion != version {
+			return fmt.Errorf("The \"%s\" pod in the \"%s\" namespace runs proxy version %s, but the control plane runs version %s",
+				pod.Name, pod.Namespace, proxyVersion, version)
+		}
+	}
+
+	return nil
+}
+
+// validateDataPlaneTrustAnchors checks that the proxies of the data plane use
+// the trust anchors of the control plane. The trust anchors are distributed to
+// each namespace in a ConfigMap, which proxies load when they start, so the
+// proxies that started before the ConfigMap was last updated, such as after
+// the trust anchors were rotated, still use the previous ones until they're
+// restarted. Proxies without TLS aren't validated.
+func validateDataPlaneTrustAnchors(pods []v1.Pod, controlPlaneBundle *v1.ConfigMap, bundles map[string]*v1.ConfigMap) error {
+	trustAnchors := controlPlaneBundle.Data[k8s.TLSTrustAnchorFileName]
+
+	staleNamespaces := map[string]bool{}
+	stalePods := []string{}
+	for _, pod := range pods {
+		if !usesTrustAnchors(pod) {
+			continue
+		}
+
+		bundle := bundles[pod.Namespace]
+		if bundle == nil || bundle.Data[k8s.TLSTrustAnchorFileName] != trustAnchors {
+			staleNamespaces[pod.Namespace] = true
+			continue
+		}
+
+		updatedAt, err := time.Parse(time.RFC3339, bundle.Annotations[k8s.TrustAnchorsUpdatedAtAnnotation])
+		if err != nil {
+			// the trust anchors were distributed before their updates were recorded
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			running := container.State.Running
+			if container.Name == k8s.ProxyContainerName && running != nil && running.StartedAt.Time.Before(updatedAt) {
+				stalePods = append(stalePods, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+			}
+		}
+	}
+
+	errs := []string{}
+	if len(staleNamespaces) > 0 {
+		namespaces := []string{}
+		for namespace := range staleNamespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		errs = append(errs, fmt.Sprintf("The trust anchors of these namespaces don't match the control plane's yet: %s",
+			strings.Join(namespaces, ", ")))
+	}
+	if len(stalePods) > 0 {
+		sort.Strings(stalePods)
+		errs = append(errs, fmt.Sprintf("The proxies of these pods started before the trust anchors were updated and need to be restarted: %s",
+			strings.Join(stalePods, ", ")))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// usesTrustAnchors returns whether the proxy of the pod loads the trust
+// anchors of its namespace, i.e. whether it was injected with TLS.
+func usesTrustAnchors(pod v1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != k8s.ProxyContainerName {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.Name == proxyTrustAnchorsEnvVar {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baselineCapabilities are the capabilities that containers may add at the
+// baseline pod security level. At the restricted level, they may only add
+// NET_BIND_SERVICE.
+var baselineCapabilities = map[v1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// validateControlPlanePodSecurity checks that the pods of the control plane
+// meet the pod security level enforced by the namespace, so that they are
+// admitted again when they're rescheduled or upgraded. The restricted level
+// is met by installing with --restricted-pod-security. The seccomp profiles
+// of the pods are read from profiles, or from the deprecated seccomp
+// annotations if it's nil, on versions of Kubernetes older than 1.19.
+func validateControlPlanePodSecurity(namespace *v1.Namespace, pods []v1.Pod, profiles k8s.SeccompProfiles) error {
+	if namespace == nil {
+		return nil
+	}
+
+	level := namespace.Labels[k8s.PodSecurityEnforceLabel]
+	switch level {
+	case "", k8s.PodSecurityPrivileged:
+		return nil
+	case k8s.PodSecurityBaseline, k8s.PodSecurityRestricted:
+	default:
+		return fmt.Errorf("The \"%s\" namespace enforces the unknown \"%s\" pod security level", namespace.Name, level)
+	}
+
+	for _, pod := range pods {
+		// only the pods rendered by `linkerd install` are injected, as opposed
+		// to e.g. the pods of the CNI plugin
+		if pod.Labels[k8s.ControllerNSLabel] == "" {
+			continue
+		}
+		containers := []v1.Container{}
+		containers = append(containers, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		for _, container := range containers {
+			if violation := podSecurityViolation(pod, container, level, profiles); violation != "" {
+				return fmt.Errorf("The \"%s\" pod's \"%s\" container %s, which the \"%s\" pod security level of the \"%s\" namespace doesn't allow",
+					pod.Name, container.Name, violation, level, namespace.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// podSecurityViolation describes the first setting of the container that
+// doesn't meet the pod security level, or returns an empty string.
+func podSecurityViolation(pod v1.Pod, container v1.Container, level string, profiles k8s.SeccompProfiles) string {
+	sc := container.SecurityContext
+	if sc == nil {
+		sc = &v1.SecurityContext{}
+	}
+
+	if sc.Privileged != nil && *sc.Privileged {
+		return "is privileged"
+	}
+	if sc.Capabilities != nil {
+		for _, capability := range sc.Capabilities.Add {
+			if !baselineCapabilities[capability] || (level == k8s.PodSecurityRestricted && capability != "NET_BIND_SERVICE") {
+				return fmt.Sprintf("adds the %s capability", capability)
+			}
+		}
+	}
+	if level == k8s.PodSecurityBaseline {
+		return ""
+	}
+
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		return "allows privilege escalation"
+	}
+	runAsNonRoot := sc.RunAsNonRoot
+	if runAsNonRoot == nil && pod.Spec.SecurityContext != nil {
+		runAsNonRoot = pod.Spec.SecurityContext.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		return "may run as root"
+	}
+	dropsAll := false
+	if sc.Capabilities != nil {
+		for _, capability := range sc.Capabilities.Drop {
+			dropsAll = dropsAll || capability == "ALL"
+		}
+	}
+	if !dropsAll {
+		return "doesn't drop all capabilities"
+	}
+	if !hasSeccompProfile(pod, container, profiles) {
+		return "doesn't set a seccomp profile"
+	}
+
+	return ""
+}
+
+// hasSeccompProfile returns true if the container runs with the runtime's
+// default seccomp profile or a local one, set by its own securityContext or
+// the pod's, or by the deprecated seccomp annotations if profiles is nil.
+func hasSeccompProfile(pod v1.Pod, container v1.Container, profiles k8s.SeccompProfiles) bool {
+	if profiles != nil {
+		profile := profiles[pod.Name][container.Name]
+		if profile == "" {
+			profile = profiles[pod.Name][""]
+		}
+		return profile == k8s.SeccompProfileTypeRuntimeDefault || profile == "Localhost"
+	}
+
+	profile := pod.Annotations[k8s.ContainerSeccompAnnotationPrefix+container.Name]
+	if profile == "" {
+		profile = pod.Annotations[k8s.PodSeccompAnnotation]
+	}
+	return profile == k8s.SeccompProfileRuntimeDefault || profile == "docker/default" || strings.HasPrefix(profile, "localhost/")
+}
+
+// describeUnmeshedWorkloads adds the workloads of the data plane namespace
+// that aren't meshed to err, if any are found.
+func (hc *HealthChecker) describeUnmeshedWorkloads(err error) error {
+	_, unmeshed, podsErr := hc.kubeAPI.GetMeshedPods(hc.httpClient, hc.ControlPlaneNamespace, hc.DataPlaneNamespace, "")
+	if podsErr != nil || len(unmeshed) == 0 {
+		return err
+	}
+	return fmt.Errorf("%s; these workloads aren't meshed: %s", err, describeWorkloads(hc.kubeAPI.GetPodOwners(hc.httpClient, unmeshed)))
+}
+
+func describeWorkloads(workloads []k8s.Workload) string {
+	names := make([]string, len(workloads))
+	for i, workload := range workloads {
+		names[i] = workload.String()
+	}
+	return strings.Join(names, ", ")
+}
+
 func validateDataPlanePods(pods []v1.Pod, targetNamespace string) error {
 	if len(pods) == 0 {
 		msg := fmt.Sprintf("No \"%s\" containers found", k8s.ProxyContainerName)
@@ -2,15 +2,26 @@ package healthcheck
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/registry"
 	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -280,7 +291,7 @@ func TestValidateControlPlanePods(t *testing.T) {
 			pod("web-98c9ddbcd-7b5lh", v1.PodRunning, true),
 		}
 
-		err := validateControlPlanePods(pods)
+		err := validateControlPlanePods(pods, nil)
 		if err == nil {
 			t.Fatal("Expected error, got nothing")
 		}
@@ -297,7 +308,7 @@ func TestValidateControlPlanePods(t *testing.T) {
 			pod("web-98c9ddbcd-7b5lh", v1.PodRunning, true),
 		}
 
-		err := validateControlPlanePods(pods)
+		err := validateControlPlanePods(pods, nil)
 		if err == nil {
 			t.Fatal("Expected error, got nothing")
 		}
@@ -314,11 +325,520 @@ func TestValidateControlPlanePods(t *testing.T) {
 			pod("web-98c9ddbcd-7b5lh", v1.PodRunning, true),
 		}
 
-		err := validateControlPlanePods(pods)
+		err := validateControlPlanePods(pods, nil)
 		if err != nil {
 			t.Fatalf("Unexpected error: %s", err)
 		}
 	})
+
+	t.Run("Returns nil without Prometheus if the control plane uses an existing one", func(t *testing.T) {
+		pods := []v1.Pod{
+			pod("controller-6f78cbd47-bc557", v1.PodRunning, true),
+			pod("grafana-5b7d796646-hh46d", v1.PodRunning, true),
+			pod("web-98c9ddbcd-7b5lh", v1.PodRunning, true),
+		}
+		installConfig := &v1.ConfigMap{
+			Data: map[string]string{"prometheus-url": "http://prometheus.monitoring.svc.cluster.local:9090"},
+		}
+
+		err := validateControlPlanePods(pods, installConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns nil without Grafana if the control plane uses an existing one", func(t *testing.T) {
+		pods := []v1.Pod{
+			pod("controller-6f78cbd47-bc557", v1.PodRunning, true),
+			pod("prometheus-74d66f86f6-6t6dh", v1.PodRunning, true),
+			pod("web-98c9ddbcd-7b5lh", v1.PodRunning, true),
+		}
+		installConfig := &v1.ConfigMap{
+			Data: map[string]string{"grafana-url": "https://grafana.example.com"},
+		}
+
+		err := validateControlPlanePods(pods, installConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateControlPlaneImages(t *testing.T) {
+	installConfig := &v1.ConfigMap{
+		Data: map[string]string{
+			"registry":         "registry.example.com:5000/linkerd",
+			"controller-image": "registry.example.com:5000/linkerd/controller:stable-2.0.0",
+			"proxy-image":      "registry.example.com:5000/linkerd/proxy:stable-2.0.0",
+			"proxy-init-image": "registry.example.com:5000/linkerd/proxy-init:stable-2.0.0",
+		},
+	}
+	pod := func(name, controllerImage string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{k8s.ControllerNSLabel: "linkerd"},
+			},
+			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{
+					{Name: "linkerd-init", Image: "registry.example.com:5000/linkerd/proxy-init:stable-2.0.0"},
+				},
+				Containers: []v1.Container{
+					{Name: "public-api", Image: controllerImage},
+					{Name: "linkerd-proxy", Image: "registry.example.com:5000/linkerd/proxy:stable-2.0.0"},
+				},
+			},
+		}
+	}
+
+	t.Run("Returns nil if the pods run the recorded images", func(t *testing.T) {
+		pods := []v1.Pod{pod("controller-6f78cbd47-bc557", "registry.example.com:5000/linkerd/controller:stable-2.0.0")}
+
+		err := validateControlPlaneImages(pods, installConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if a pod runs another image", func(t *testing.T) {
+		pods := []v1.Pod{pod("controller-6f78cbd47-bc557", "gcr.io/linkerd-io/controller:stable-2.0.0")}
+
+		err := validateControlPlaneImages(pods, installConfig)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The \"controller-6f78cbd47-bc557\" pod's \"public-api\" container runs the \"gcr.io/linkerd-io/controller:stable-2.0.0\" image, which isn't recorded in the \"linkerd-config\" ConfigMap"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if the images weren't recorded", func(t *testing.T) {
+		pods := []v1.Pod{pod("controller-6f78cbd47-bc557", "gcr.io/linkerd-io/controller:stable-2.0.0")}
+
+		err := validateControlPlaneImages(pods, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateControlPlanePodSecurity(t *testing.T) {
+	yes := true
+	no := false
+	namespace := func(level string) *v1.Namespace {
+		return &v1.Namespace{
+			ObjectMeta: meta.ObjectMeta{
+				Name:   "linkerd",
+				Labels: map[string]string{k8s.PodSecurityEnforceLabel: level},
+			},
+		}
+	}
+	restrictedPod := func() v1.Pod {
+		return v1.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name:   "controller-6f78cbd47-bc557",
+				Labels: map[string]string{k8s.ControllerNSLabel: "linkerd"},
+			},
+			Spec: v1.PodSpec{
+				SecurityContext: &v1.PodSecurityContext{RunAsNonRoot: &yes},
+				Containers: []v1.Container{
+					{
+						Name: "public-api",
+						SecurityContext: &v1.SecurityContext{
+							AllowPrivilegeEscalation: &no,
+							Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+						},
+					},
+				},
+			},
+		}
+	}
+	initPod := func() v1.Pod {
+		pod := restrictedPod()
+		pod.Spec.InitContainers = []v1.Container{
+			{
+				Name: "linkerd-init",
+				SecurityContext: &v1.SecurityContext{
+					Capabilities: &v1.Capabilities{Add: []v1.Capability{"NET_ADMIN"}},
+					Privileged:   &no,
+				},
+			},
+		}
+		return pod
+	}
+	profiles := k8s.SeccompProfiles{
+		"controller-6f78cbd47-bc557": {"": k8s.SeccompProfileTypeRuntimeDefault},
+	}
+
+	t.Run("Returns nil if the namespace doesn't enforce a level", func(t *testing.T) {
+		err := validateControlPlanePodSecurity(&v1.Namespace{}, []v1.Pod{initPod()}, profiles)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns nil if the pods meet the restricted level", func(t *testing.T) {
+		err := validateControlPlanePodSecurity(namespace(k8s.PodSecurityRestricted), []v1.Pod{restrictedPod()}, profiles)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Reads the seccomp annotations of pods on versions of Kubernetes older than 1.19", func(t *testing.T) {
+		pod := restrictedPod()
+		pod.Annotations = map[string]string{k8s.PodSeccompAnnotation: k8s.SeccompProfileRuntimeDefault}
+
+		err := validateControlPlanePodSecurity(namespace(k8s.PodSecurityRestricted), []v1.Pod{pod}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if the proxy-init container runs at the baseline level", func(t *testing.T) {
+		err := validateControlPlanePodSecurity(namespace(k8s.PodSecurityBaseline), []v1.Pod{initPod()}, profiles)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The \"controller-6f78cbd47-bc557\" pod's \"linkerd-init\" container adds the NET_ADMIN capability, which the \"baseline\" pod security level of the \"linkerd\" namespace doesn't allow"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns an error if a pod doesn't set a seccomp profile at the restricted level", func(t *testing.T) {
+		pod := restrictedPod()
+		pod.Annotations = map[string]string{k8s.PodSeccompAnnotation: k8s.SeccompProfileRuntimeDefault}
+
+		err := validateControlPlanePodSecurity(namespace(k8s.PodSecurityRestricted), []v1.Pod{pod}, k8s.SeccompProfiles{})
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The \"controller-6f78cbd47-bc557\" pod's \"public-api\" container doesn't set a seccomp profile, which the \"restricted\" pod security level of the \"linkerd\" namespace doesn't allow"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns an error if the namespace enforces an unknown level", func(t *testing.T) {
+		err := validateControlPlanePodSecurity(namespace("strict"), []v1.Pod{restrictedPod()}, profiles)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+	})
+}
+
+func TestCheckImagesPullable(t *testing.T) {
+	digest := "sha256:a0f9e1fdd2d0a0a1e0e8d7b6c8f2d4e3a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/linkerd/proxy/manifests/"+digest {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	mirror := strings.TrimPrefix(server.URL, "https://")
+	client := &registry.Client{HTTPClient: server.Client()}
+
+	t.Run("Returns nil if the images can be pulled", func(t *testing.T) {
+		err := checkImagesPullable(client, []string{mirror + "/linkerd/proxy:stable-2.1.0@" + digest})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if images can't be pulled", func(t *testing.T) {
+		images := []string{
+			mirror + "/linkerd/proxy:stable-2.1.0@" + digest,
+			mirror + "/linkerd/proxy-init:stable-2.1.0@" + digest,
+			mirror + "/linkerd/web:stable-2.1.0",
+		}
+
+		err := checkImagesPullable(client, images)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := fmt.Sprintf("Some pinned images can't be pulled: %s (%s doesn't have the image), %s (%s isn't pinned by a digest)",
+			images[1], mirror, images[2], images[2])
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+}
+
+func TestValidateNodeArchitectures(t *testing.T) {
+	installConfig := &v1.ConfigMap{Data: map[string]string{"proxy-architectures": "amd64,arm64"}}
+	node := func(name, arch string) v1.Node {
+		return v1.Node{
+			ObjectMeta: meta.ObjectMeta{Name: name},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{Architecture: arch}},
+		}
+	}
+
+	t.Run("Returns nil if the proxy images are published for the nodes' architectures", func(t *testing.T) {
+		err := validateNodeArchitectures([]v1.Node{node("node-1", "amd64"), node("node-2", "arm64")}, installConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if nodes run other architectures", func(t *testing.T) {
+		nodes := []v1.Node{node("node-3", "s390x"), node("node-1", "amd64"), node("node-2", "ppc64le")}
+
+		err := validateNodeArchitectures(nodes, installConfig)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The proxy images aren't published for the architectures of some nodes, whose pods can't be injected: node-2 (ppc64le), node-3 (s390x); the proxy images are published for amd64,arm64"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if the architectures weren't recorded", func(t *testing.T) {
+		err := validateNodeArchitectures([]v1.Node{node("node-3", "s390x")}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateDataPlaneProxyVersions(t *testing.T) {
+	installConfig := &v1.ConfigMap{Data: map[string]string{"linkerd-version": "stable-2.0.0"}}
+	pod := func(version string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name:        "emoji-d9c7866bb-7v74n",
+				Namespace:   "emojivoto",
+				Annotations: map[string]string{k8s.ProxyVersionAnnotation: version},
+			},
+		}
+	}
+
+	t.Run("Returns nil if the proxies run the installed version", func(t *testing.T) {
+		err := validateDataPlaneProxyVersions([]v1.Pod{pod("stable-2.0.0")}, installConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if a proxy runs another version", func(t *testing.T) {
+		err := validateDataPlaneProxyVersions([]v1.Pod{pod("edge-18.9.1")}, installConfig)
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The \"emoji-d9c7866bb-7v74n\" pod in the \"emojivoto\" namespace runs proxy version edge-18.9.1, but the control plane runs version stable-2.0.0"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if the version wasn't recorded", func(t *testing.T) {
+		err := validateDataPlaneProxyVersions([]v1.Pod{pod("edge-18.9.1")}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateDataPlaneTrustAnchors(t *testing.T) {
+	updatedAt := time.Date(2018, 9, 20, 12, 0, 0, 0, time.UTC)
+	bundle := func(trustAnchors string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{
+				Name:        k8s.TLSTrustAnchorConfigMapName,
+				Annotations: map[string]string{k8s.TrustAnchorsUpdatedAtAnnotation: updatedAt.Format(time.RFC3339)},
+			},
+			Data: map[string]string{k8s.TLSTrustAnchorFileName: trustAnchors},
+		}
+	}
+	pod := func(name string, startedAt time.Time) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "emojivoto"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: k8s.ProxyContainerName,
+						Env:  []v1.EnvVar{{Name: "LINKERD2_PROXY_TLS_TRUST_ANCHORS", Value: "/var/linkerd-io/trust-anchors/trust-anchors.pem"}},
+					},
+				},
+			},
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: k8s.ProxyContainerName,
+						State: v1.ContainerState{
+							Running: &v1.ContainerStateRunning{StartedAt: meta.NewTime(startedAt)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("Returns nil if the proxies started after the trust anchors were updated", func(t *testing.T) {
+		pods := []v1.Pod{pod("emoji-d9c7866bb-7v74n", updatedAt.Add(time.Hour))}
+		err := validateDataPlaneTrustAnchors(pods, bundle("anchors"), map[string]*v1.ConfigMap{"emojivoto": bundle("anchors")})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error listing the proxies that started before the trust anchors were updated", func(t *testing.T) {
+		pods := []v1.Pod{
+			pod("web-7f6b8d9c4-xk2lp", updatedAt.Add(-time.Hour)),
+			pod("emoji-d9c7866bb-7v74n", updatedAt.Add(time.Hour)),
+			pod("voting-5f5b555dff-xmgsx", updatedAt.Add(-time.Minute)),
+		}
+		err := validateDataPlaneTrustAnchors(pods, bundle("anchors"), map[string]*v1.ConfigMap{"emojivoto": bundle("anchors")})
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The proxies of these pods started before the trust anchors were updated and need to be restarted: emojivoto/voting-5f5b555dff-xmgsx, emojivoto/web-7f6b8d9c4-xk2lp"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns an error if the trust anchors of a namespace don't match the control plane's", func(t *testing.T) {
+		pods := []v1.Pod{pod("emoji-d9c7866bb-7v74n", updatedAt.Add(time.Hour))}
+		err := validateDataPlaneTrustAnchors(pods, bundle("anchors"), map[string]*v1.ConfigMap{"emojivoto": bundle("previous anchors")})
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The trust anchors of these namespaces don't match the control plane's yet: emojivoto"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil for proxies without TLS", func(t *testing.T) {
+		plaintext := pod("emoji-d9c7866bb-7v74n", updatedAt.Add(-time.Hour))
+		plaintext.Spec.Containers[0].Env = nil
+		err := validateDataPlaneTrustAnchors([]v1.Pod{plaintext}, bundle("anchors"), map[string]*v1.ConfigMap{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateCertificateExpirations(t *testing.T) {
+	metrics := []byte(`# HELP certificate_expiration_seconds Time at which the certificate expires, in seconds since the epoch.
+# TYPE certificate_expiration_seconds gauge
+certificate_expiration_seconds{identity="Cluster-local Managed Pod CA",type="issuer"} 1.5693456e+09
+certificate_expiration_seconds{identity="web.emojivoto.serviceaccount.identity.linkerd.cluster.local",type="end-entity"} 1.5380352e+09
+# HELP certificate_renewal_failure_seconds Time at which the certificate enters its renewal-failure window, in seconds since the epoch. The certificate should have been renewed well before then.
+# TYPE certificate_renewal_failure_seconds gauge
+certificate_renewal_failure_seconds{identity="Cluster-local Managed Pod CA",type="issuer"} 1.5640896e+09
+certificate_renewal_failure_seconds{identity="web.emojivoto.serviceaccount.identity.linkerd.cluster.local",type="end-entity"} 1.538028e+09
+`)
+
+	t.Run("Returns nil if no certificate is within its renewal-failure window", func(t *testing.T) {
+		err := validateCertificateExpirations(metrics, DefaultCertExpiryWarningThreshold, time.Unix(1538000000, 0))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if a certificate is within its renewal-failure window", func(t *testing.T) {
+		err := validateCertificateExpirations(metrics, DefaultCertExpiryWarningThreshold, time.Unix(1538030000, 0))
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "Certificates haven't been renewed and are about to expire: the end-entity certificate of web.emojivoto.serviceaccount.identity.linkerd.cluster.local, which expires at 2018-09-27T08:00:00Z"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns an error if the issuer certificate expires within the threshold", func(t *testing.T) {
+		err := validateCertificateExpirations(metrics, 400*24*time.Hour, time.Unix(1538000000, 0))
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "Certificates haven't been renewed and are about to expire: the issuer certificate of Cluster-local Managed Pod CA, which expires at 2019-09-24T17:20:00Z"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if the control plane was installed without TLS", func(t *testing.T) {
+		err := validateCertificateExpirations(nil, DefaultCertExpiryWarningThreshold, time.Unix(1538030000, 0))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateServingCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	notAfter := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "proxy-injector.linkerd.svc"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	secret := &v1.Secret{
+		Data: map[string][]byte{
+			k8s.ServingTLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+
+	t.Run("Returns nil if the certificate doesn't expire within the threshold", func(t *testing.T) {
+		err := validateServingCertificate("the proxy injector webhook", secret, DefaultCertExpiryWarningThreshold, notAfter.Add(-60*24*time.Hour))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if the certificate expires within the threshold", func(t *testing.T) {
+		err := validateServingCertificate("the proxy injector webhook", secret, 90*24*time.Hour, notAfter.Add(-60*24*time.Hour))
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		expected := "The serving certificate of the proxy injector webhook is about to expire: it expires at 2018-10-01T00:00:00Z"
+		if err.Error() != expected {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if the webhook isn't installed", func(t *testing.T) {
+		err := validateServingCertificate("the proxy injector webhook", nil, DefaultCertExpiryWarningThreshold, notAfter)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestValidateDashboardReadiness(t *testing.T) {
+	t.Run("Returns nil if the dashboard is ready", func(t *testing.T) {
+		if err := validateDashboardReadiness(http.StatusOK, []byte("ok\n")); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns the reason why the dashboard isn't ready", func(t *testing.T) {
+		err := validateDashboardReadiness(http.StatusServiceUnavailable, []byte("Grafana is unreachable: connection refused\n"))
+		expected := "The dashboard is not ready: Grafana is unreachable: connection refused"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns an error if the dashboard can't be reached", func(t *testing.T) {
+		err := validateDashboardReadiness(http.StatusBadGateway, nil)
+		expected := "The dashboard is not reachable: unexpected response status 502"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
 }
 
 func TestValidateDataPlanePods(t *testing.T) {
@@ -0,0 +1,85 @@
+package healthcheck
+
+import (
+	"sort"
+	"sync"
+)
+
+// CheckResults aggregates the results of checks, so that they're grouped and
+// sorted in the order of the checks, whatever the order in which they're
+// observed. Observe can be passed to RunChecks as its observer, and called
+// concurrently. The last result of a check replaces its previous ones, such as
+// the results of its retries.
+type CheckResults struct {
+	sync.Mutex
+	results map[checkResultKey]*CheckResult
+}
+
+// CategoryResults are the results of the checks of a category.
+type CategoryResults struct {
+	Category string
+	Results  []*CheckResult
+}
+
+type checkResultKey struct {
+	category, check, subCheck int
+}
+
+// NewCheckResults returns an empty CheckResults.
+func NewCheckResults() *CheckResults {
+	return &CheckResults{
+		results: make(map[checkResultKey]*CheckResult),
+	}
+}
+
+// Observe records the result of a check.
+func (r *CheckResults) Observe(result *CheckResult) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.results[checkResultKey{result.CategoryOrder, result.CheckOrder, result.SubCheckOrder}] = result
+}
+
+// Results returns the results sorted by category, check and sub-check.
+func (r *CheckResults) Results() []*CheckResult {
+	r.Lock()
+	defer r.Unlock()
+
+	results := make([]*CheckResult, 0, len(r.results))
+	for _, result := range r.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.CategoryOrder != b.CategoryOrder {
+			return a.CategoryOrder < b.CategoryOrder
+		}
+		if a.CheckOrder != b.CheckOrder {
+			return a.CheckOrder < b.CheckOrder
+		}
+		return a.SubCheckOrder < b.SubCheckOrder
+	})
+
+	return results
+}
+
+// Categories returns the sorted results grouped by category, in the order in
+// which the categories are first seen. The results of the control plane's
+// self-check have a category of their own, e.g. "linkerd-api[grpc-server]",
+// which follows the category of the check that returned them, even if other
+// checks of that category follow the self-check.
+func (r *CheckResults) Categories() []CategoryResults {
+	categories := make([]CategoryResults, 0)
+	indexes := make(map[string]int)
+	for _, result := range r.Results() {
+		i, ok := indexes[result.Category]
+		if !ok {
+			i = len(categories)
+			indexes[result.Category] = i
+			categories = append(categories, CategoryResults{Category: result.Category})
+		}
+		categories[i].Results = append(categories[i].Results, result)
+	}
+
+	return categories
+}
@@ -0,0 +1,129 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
+)
+
+func TestCheckResults(t *testing.T) {
+	rpcClient := public.MockApiClient{
+		SelfCheckResponseToReturn: &healthcheckPb.SelfCheckResponse{
+			Results: []*healthcheckPb.CheckResult{
+				{SubsystemName: "rpc", CheckDescription: "rpc desc1", Status: healthcheckPb.CheckStatus_OK},
+				{SubsystemName: "rpc", CheckDescription: "rpc desc2", Status: healthcheckPb.CheckStatus_OK},
+			},
+		},
+	}
+
+	retryWindow = 0
+	retried := false
+
+	hc := HealthChecker{
+		checkers: []*checker{
+			{
+				category:    "cat1",
+				description: "desc1",
+				check:       func() error { return nil },
+			},
+			{
+				category:    "cat1",
+				description: "desc2",
+				retry:       true,
+				check: func() error {
+					if !retried {
+						retried = true
+						return fmt.Errorf("retry")
+					}
+					return nil
+				},
+			},
+			{
+				category:    "cat2",
+				description: "desc3",
+				checkRPC: func() (*healthcheckPb.SelfCheckResponse, error) {
+					return rpcClient.SelfCheck(context.Background(), &healthcheckPb.SelfCheckRequest{})
+				},
+			},
+			{
+				category:    "cat2",
+				description: "desc5",
+				check:       func() error { return nil },
+			},
+			{
+				category:    "cat3",
+				description: "desc4",
+				check:       func() error { return fmt.Errorf("error") },
+			},
+		},
+	}
+
+	observed := make([]*CheckResult, 0)
+	hc.RunChecks(func(result *CheckResult) {
+		observed = append(observed, result)
+	})
+
+	render := func(categories []CategoryResults) []string {
+		rendered := make([]string, 0)
+		for _, category := range categories {
+			for _, result := range category.Results {
+				res := fmt.Sprintf("%s %s retry=%t", category.Category, result.Description, result.Retry)
+				if result.Err != nil {
+					res += fmt.Sprintf(": %s", result.Err)
+				}
+				rendered = append(rendered, res)
+			}
+		}
+		return rendered
+	}
+
+	expected := []string{
+		"cat1 desc1 retry=false",
+		"cat1 desc2 retry=false",
+		"cat2 desc3 retry=false",
+		"cat2 desc5 retry=false",
+		"cat2[rpc] rpc desc1 retry=false",
+		"cat2[rpc] rpc desc2 retry=false",
+		"cat3 desc4 retry=false: error",
+	}
+
+	t.Run("Groups and sorts the results in the order of the checks", func(t *testing.T) {
+		results := NewCheckResults()
+		for _, result := range observed {
+			results.Observe(result)
+		}
+
+		if rendered := render(results.Categories()); !reflect.DeepEqual(rendered, expected) {
+			t.Fatalf("Expected results %v, but got %v", expected, rendered)
+		}
+	})
+
+	t.Run("Aggregates the results observed concurrently in any order", func(t *testing.T) {
+		results := NewCheckResults()
+
+		// the retry is observed first, so that it's replaced by the final
+		// result of its check
+		var wg sync.WaitGroup
+		for i := len(observed) - 1; i >= 0; i-- {
+			if observed[i].Retry {
+				results.Observe(observed[i])
+				continue
+			}
+			wg.Add(1)
+			go func(result *CheckResult) {
+				defer wg.Done()
+				results.Observe(result)
+			}(observed[i])
+		}
+		wg.Wait()
+
+		if rendered := render(results.Categories()); !reflect.DeepEqual(rendered, expected) {
+			t.Fatalf("Expected results %v, but got %v", expected, rendered)
+		}
+	})
+}
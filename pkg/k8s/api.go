@@ -7,10 +7,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
 
@@ -20,8 +21,30 @@ import (
 
 var minApiVersion = [3]int{1, 8, 0}
 
+// Backoff configures the retries of the requests to the Kubernetes API that
+// fail transiently, e.g. because the API server throttles them or the
+// connection was reset. A request is attempted up to Steps times, waiting
+// Duration before the first retry, and Factor times longer before each
+// following one.
+type Backoff struct {
+	Duration time.Duration
+	Factor   float64
+	Steps    int
+}
+
+// DefaultBackoff is the Backoff of the clients returned by NewAPI.
+var DefaultBackoff = Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Steps:    4,
+}
+
+// KubernetesAPI is a client of the Kubernetes API. Its requests are retried
+// according to Backoff, and are not retried if it's unset. The errors of the
+// requests are APIErrors and ConnectionErrors.
 type KubernetesAPI struct {
 	*rest.Config
+	Backoff Backoff
 }
 
 func (kubeAPI *KubernetesAPI) NewClient() (*http.Client, error) {
@@ -39,14 +62,15 @@ func (kubeAPI *KubernetesAPI) GetVersionInfo(client *http.Client) (*version.Info
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rsp, err := kubeAPI.getRequest(ctx, client, "/version")
+	path := "/version"
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
 	if err != nil {
 		return nil, err
 	}
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return nil, newAPIError("GET", path, rsp)
 	}
 
 	bytes, err := ioutil.ReadAll(rsp.Body)
@@ -78,10 +102,9 @@ func (kubeAPI *KubernetesAPI) CheckProxyVersion(pods []v1.Pod, version string) e
 	for _, pod := range pods {
 		for _, container := range pod.Spec.Containers {
 			if container.Name == ProxyContainerName {
-				parts := strings.Split(container.Image, ":")
-				if len(parts) == 2 && parts[1] != version {
+				if tag := ImageTag(container.Image); tag != "" && tag != version {
 					return fmt.Errorf("%s is running version %s but the latest version is %s",
-						pod.Name, parts[1], version)
+						pod.Name, tag, version)
 				}
 			}
 		}
@@ -94,19 +117,119 @@ func (kubeAPI *KubernetesAPI) NamespaceExists(client *http.Client, namespace str
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rsp, err := kubeAPI.getRequest(ctx, client, "/api/v1/namespaces/"+namespace)
+	path := "/api/v1/namespaces/" + namespace
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
 	if err != nil {
 		return false, err
 	}
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusNotFound {
-		return false, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return false, newAPIError("GET", path, rsp)
 	}
 
 	return rsp.StatusCode == http.StatusOK, nil
 }
 
+// GetNamespace returns the namespace with the given name, or nil if it
+// doesn't exist.
+func (kubeAPI *KubernetesAPI) GetNamespace(client *http.Client, name string) (*v1.Namespace, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := "/api/v1/namespaces/" + name
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, rsp)
+	}
+
+	bytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespace v1.Namespace
+	err = json.Unmarshal(bytes, &namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namespace, nil
+}
+
+// GetConfigMap returns the ConfigMap with the given name in a namespace, or
+// nil if it doesn't exist.
+func (kubeAPI *KubernetesAPI) GetConfigMap(client *http.Client, namespace, name string) (*v1.ConfigMap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := "/api/v1/namespaces/" + namespace + "/configmaps/" + name
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, rsp)
+	}
+
+	bytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var configMap v1.ConfigMap
+	err = json.Unmarshal(bytes, &configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configMap, nil
+}
+
+// GetSecret returns the Secret with the given name in a namespace, or nil if it
+// doesn't exist.
+func (kubeAPI *KubernetesAPI) GetSecret(client *http.Client, namespace, name string) (*v1.Secret, error) {
+	bytes, err := kubeAPI.GetResource(client, "/api/v1/namespaces/"+namespace+"/secrets/"+name)
+	if err != nil || bytes == nil {
+		return nil, err
+	}
+
+	var secret v1.Secret
+	if err := json.Unmarshal(bytes, &secret); err != nil {
+		return nil, err
+	}
+
+	return &secret, nil
+}
+
+// GetNodes returns the nodes of the cluster.
+func (kubeAPI *KubernetesAPI) GetNodes(client *http.Client) ([]v1.Node, error) {
+	bytes, err := kubeAPI.GetResource(client, "/api/v1/nodes")
+	if err != nil || bytes == nil {
+		return nil, err
+	}
+
+	var nodeList v1.NodeList
+	if err := json.Unmarshal(bytes, &nodeList); err != nil {
+		return nil, err
+	}
+
+	return nodeList.Items, nil
+}
+
 // GetPodsByNamespace returns all pods in a given namespace
 func (kubeAPI *KubernetesAPI) GetPodsByNamespace(client *http.Client, namespace string) ([]v1.Pod, error) {
 	return kubeAPI.getPods(client, "/api/v1/namespaces/"+namespace+"/pods")
@@ -116,15 +239,134 @@ func (kubeAPI *KubernetesAPI) GetPodsByNamespace(client *http.Client, namespace
 // interface with a given controllerNamespace. If targetNamespace is provided,
 // only pods from that namespace are returned.
 func (kubeAPI *KubernetesAPI) GetPodsByControllerNamespace(client *http.Client, controllerNamespace, targetNamespace string) ([]v1.Pod, error) {
-	selector := url.QueryEscape(fmt.Sprintf("%s=%s", ControllerNSLabel, controllerNamespace))
-	var path string
-	if targetNamespace == "" {
-		path = "/api/v1/pods"
-	} else {
-		path = "/api/v1/namespaces/" + targetNamespace + "/pods"
+	selector := fmt.Sprintf("%s=%s", ControllerNSLabel, controllerNamespace)
+	return kubeAPI.GetPods(client, targetNamespace, selector)
+}
+
+// GetPods returns the pods of a namespace, or of all namespaces if it's empty,
+// that match a label selector, or all of them if it's empty.
+func (kubeAPI *KubernetesAPI) GetPods(client *http.Client, namespace, selector string) ([]v1.Pod, error) {
+	path := "/api/v1/pods"
+	if namespace != "" {
+		path = "/api/v1/namespaces/" + namespace + "/pods"
 	}
+	if selector != "" {
+		path += "?labelSelector=" + url.QueryEscape(selector)
+	}
+
+	return kubeAPI.getPods(client, path)
+}
 
-	return kubeAPI.getPods(client, fmt.Sprintf("%s?labelSelector=%s", path, selector))
+// SeccompProfiles are the types of the seccomp profiles that pods set with
+// the securityContext.seccompProfile field, by pod name and then by container
+// name, with the profile of the pod itself under the empty container name.
+type SeccompProfiles map[string]map[string]string
+
+// seccompSecurityContext is the part of the securityContext of pods and
+// containers that sets their seccomp profile. The seccompProfile field is
+// newer than the Kubernetes API types Linkerd is built with, so it's decoded
+// separately from the pods.
+type seccompSecurityContext struct {
+	SeccompProfile *struct {
+		Type string `json:"type"`
+	} `json:"seccompProfile"`
+}
+
+// GetSeccompProfiles returns the seccomp profiles of the pods of a namespace.
+func (kubeAPI *KubernetesAPI) GetSeccompProfiles(client *http.Client, namespace string) (SeccompProfiles, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := "/api/v1/namespaces/" + namespace + "/pods"
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, rsp)
+	}
+
+	bytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	type container struct {
+		Name            string                  `json:"name"`
+		SecurityContext *seccompSecurityContext `json:"securityContext"`
+	}
+	var podList struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				SecurityContext *seccompSecurityContext `json:"securityContext"`
+				InitContainers  []container             `json:"initContainers"`
+				Containers      []container             `json:"containers"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(bytes, &podList); err != nil {
+		return nil, err
+	}
+
+	profiles := SeccompProfiles{}
+	for _, pod := range podList.Items {
+		containers := append([]container{{SecurityContext: pod.Spec.SecurityContext}}, pod.Spec.InitContainers...)
+		containers = append(containers, pod.Spec.Containers...)
+		profiles[pod.Metadata.Name] = map[string]string{}
+		for _, c := range containers {
+			if c.SecurityContext != nil && c.SecurityContext.SeccompProfile != nil {
+				profiles[pod.Metadata.Name][c.Name] = c.SecurityContext.SeccompProfile.Type
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// GetMeshedPods returns the pods matching namespace and selector as GetPods
+// does, split into those injected with a proxy of the control plane in
+// controllerNamespace and the others.
+func (kubeAPI *KubernetesAPI) GetMeshedPods(client *http.Client, controllerNamespace, namespace, selector string) ([]v1.Pod, []v1.Pod, error) {
+	pods, err := kubeAPI.GetPods(client, namespace, selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meshed, unmeshed := SplitMeshedPods(pods, controllerNamespace)
+	return meshed, unmeshed, nil
+}
+
+// GetPodOwners returns the distinct workloads owning pods, such as the
+// Deployments of the ReplicaSets owning them, or the CronJobs of their Jobs.
+func (kubeAPI *KubernetesAPI) GetPodOwners(client *http.Client, pods []v1.Pod) []Workload {
+	return GetPodOwners(pods, func(kind, namespace, name string) ([]metav1.OwnerReference, error) {
+		var path string
+		switch kind {
+		case "ReplicaSet":
+			path = "/apis/apps/v1beta2/namespaces/" + namespace + "/replicasets/" + name
+		case "Job":
+			path = "/apis/batch/v1/namespaces/" + namespace + "/jobs/" + name
+		default:
+			return nil, nil
+		}
+
+		bytes, err := kubeAPI.GetResource(client, path)
+		if err != nil || bytes == nil {
+			return nil, err
+		}
+
+		var object struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(bytes, &object); err != nil {
+			return nil, err
+		}
+		return object.Metadata.OwnerReferences, nil
+	})
 }
 
 func (kubeAPI *KubernetesAPI) getPods(client *http.Client, path string) ([]v1.Pod, error) {
@@ -138,7 +380,7 @@ func (kubeAPI *KubernetesAPI) getPods(client *http.Client, path string) ([]v1.Po
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return nil, newAPIError("GET", path, rsp)
 	}
 
 	bytes, err := ioutil.ReadAll(rsp.Body)
@@ -160,27 +402,110 @@ func (kubeAPI *KubernetesAPI) UrlFor(namespace string, extraPathStartingWithSlas
 	return generateKubernetesApiBaseUrlFor(kubeAPI.Host, namespace, extraPathStartingWithSlash)
 }
 
+// GetResource returns the JSON of the resource at the given path of the
+// Kubernetes API, such as /api/v1/namespaces/linkerd, or nil if it doesn't
+// exist.
+func (kubeAPI *KubernetesAPI) GetResource(client *http.Client, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, rsp)
+	}
+
+	return ioutil.ReadAll(rsp.Body)
+}
+
+// DeleteResource deletes the resource at the given path of the Kubernetes
+// API, such as /api/v1/namespaces/linkerd. Resources that don't exist are
+// ignored.
+func (kubeAPI *KubernetesAPI) DeleteResource(client *http.Client, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rsp, err := kubeAPI.request(ctx, client, "DELETE", path)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusAccepted && rsp.StatusCode != http.StatusNotFound {
+		return newAPIError("DELETE", path, rsp)
+	}
+
+	return nil
+}
+
 func (kubeAPI *KubernetesAPI) getRequest(ctx context.Context, client *http.Client, path string) (*http.Response, error) {
+	return kubeAPI.request(ctx, client, "GET", path)
+}
+
+func (kubeAPI *KubernetesAPI) request(ctx context.Context, client *http.Client, method, path string) (*http.Response, error) {
 	endpoint, err := url.Parse(kubeAPI.Host + path)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", endpoint.String(), nil)
+	req, err := http.NewRequest(method, endpoint.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return client.Do(req.WithContext(ctx))
+	delay := kubeAPI.Backoff.Duration
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		rsp, err := client.Do(req.WithContext(ctx))
+		logger := log.WithFields(log.Fields{
+			"method":   method,
+			"path":     path,
+			"attempt":  attempt,
+			"duration": time.Since(start),
+		})
+		var transient bool
+		if err != nil {
+			transient = isTransientError(err)
+			err = &ConnectionError{Host: kubeAPI.Host, Err: err}
+			logger = logger.WithField("error", err)
+		} else {
+			transient = isTransientStatus(rsp.StatusCode)
+			logger = logger.WithField("status", rsp.StatusCode)
+		}
+		logger.Debug("Kubernetes API request")
+		if !transient || attempt >= kubeAPI.Backoff.Steps {
+			return rsp, err
+		}
+
+		if rsp != nil {
+			rsp.Body.Close()
+		}
+		logger.Debugf("Retrying Kubernetes API request in %s", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, &ConnectionError{Host: kubeAPI.Host, Err: ctx.Err()}
+		}
+		delay = time.Duration(float64(delay) * kubeAPI.Backoff.Factor)
+	}
 }
 
 // NewAPI validates a Kubernetes config and returns a client for accessing the
-// configured cluster
-func NewAPI(configPath string) (*KubernetesAPI, error) {
-	config, err := getConfig(configPath)
+// configured cluster, using kubeContext instead of the current context of the
+// config if it is set, and impersonating the impersonate user and the
+// impersonateGroup groups if set
+func NewAPI(configPath, kubeContext, impersonate string, impersonateGroup []string) (*KubernetesAPI, error) {
+	config, err := getConfig(configPath, kubeContext, impersonate, impersonateGroup)
 	if err != nil {
 		return nil, fmt.Errorf("error configuring Kubernetes API client: %v", err)
 	}
 
-	return &KubernetesAPI{Config: config}, nil
+	return &KubernetesAPI{Config: config, Backoff: DefaultBackoff}, nil
 }
@@ -2,7 +2,13 @@ package k8s
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
 )
 
 func TestKubernetesApiUrlFor(t *testing.T) {
@@ -11,7 +17,7 @@ func TestKubernetesApiUrlFor(t *testing.T) {
 
 	t.Run("Returns base config containing k8s endpoint listed in config.test", func(t *testing.T) {
 		expected := fmt.Sprintf("https://55.197.171.239/api/v1/namespaces/%s%s", namespace, extraPath)
-		api, err := NewAPI("testdata/config.test")
+		api, err := NewAPI("testdata/config.test", "", "", []string{})
 		if err != nil {
 			t.Fatalf("Unexpected error creating Kubernetes API: %+v", err)
 		}
@@ -24,3 +30,140 @@ func TestKubernetesApiUrlFor(t *testing.T) {
 		}
 	})
 }
+
+func TestKubernetesApiRequest(t *testing.T) {
+	backoff := Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+	t.Run("Retries the requests that are throttled", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"kind":"Secret"}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Backoff: backoff}
+		bytes, err := api.GetResource(http.DefaultClient, "/api/v1/namespaces/linkerd/secrets/secret")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if string(bytes) != `{"kind":"Secret"}` {
+			t.Fatalf("Unexpected response: %s", bytes)
+		}
+		if attempts != 3 {
+			t.Fatalf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("Returns the error of the last attempt", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Backoff: backoff}
+		_, err := api.GetResource(http.DefaultClient, "/api/v1/namespaces/linkerd")
+		apiErr, ok := err.(*APIError)
+		if !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Expected an APIError with status 503, got: %v", err)
+		}
+		if attempts != backoff.Steps {
+			t.Fatalf("Expected %d attempts, got %d", backoff.Steps, attempts)
+		}
+	})
+
+	t.Run("Doesn't retry the requests without a backoff", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		if _, err := api.GetResource(http.DefaultClient, "/api/v1/namespaces/linkerd"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+		if attempts != 1 {
+			t.Fatalf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("Returns typed errors for unauthorized requests", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","message":"namespaces \"linkerd\" is forbidden"}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Backoff: backoff}
+		_, err := api.NamespaceExists(http.DefaultClient, "linkerd")
+		if !IsUnauthorized(err) {
+			t.Fatalf("Expected an unauthorized error, got: %v", err)
+		}
+		expected := `Unexpected Kubernetes API response to GET /api/v1/namespaces/linkerd: 403 Forbidden: namespaces "linkerd" is forbidden`
+		if err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%s]", expected, err)
+		}
+	})
+
+	t.Run("Returns typed errors for unreachable APIs", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Backoff: backoff}
+		_, err := api.GetPodsByNamespace(http.DefaultClient, "linkerd")
+		if !IsConnectionError(err) {
+			t.Fatalf("Expected a connection error, got: %v", err)
+		}
+	})
+}
+
+func TestGetSeccompProfiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/namespaces/linkerd/pods" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{
+  "kind": "PodList",
+  "items": [
+    {
+      "metadata": {"name": "controller-6f78cbd47-bc557"},
+      "spec": {
+        "securityContext": {"runAsNonRoot": true, "seccompProfile": {"type": "RuntimeDefault"}},
+        "containers": [
+          {"name": "public-api"},
+          {"name": "linkerd-proxy", "securityContext": {"seccompProfile": {"type": "Localhost"}}}
+        ]
+      }
+    },
+    {
+      "metadata": {"name": "web-5f9b8c7d6-x2b4k"},
+      "spec": {"containers": [{"name": "web"}]}
+    }
+  ]
+}`))
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+	profiles, err := api.GetSeccompProfiles(http.DefaultClient, "linkerd")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := SeccompProfiles{
+		"controller-6f78cbd47-bc557": {"": "RuntimeDefault", "linkerd-proxy": "Localhost"},
+		"web-5f9b8c7d6-x2b4k":        {},
+	}
+	if !reflect.DeepEqual(profiles, expected) {
+		t.Fatalf("Expected %v, got %v", expected, profiles)
+	}
+}
@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	admissionRegistrationGroup = "admissionregistration.k8s.io"
+	apiExtensionsGroup         = "apiextensions.k8s.io"
+	discoveryGroup             = "discovery.k8s.io"
+)
+
+// seccompProfileFieldVersion is the version of Kubernetes that replaced the
+// seccomp annotations of pods with the securityContext.seccompProfile field.
+var seccompProfileFieldVersion = [3]int{1, 19, 0}
+
+// Capabilities are the version of a cluster's Kubernetes API, and the
+// optional APIs it serves, which the manifests of the control plane and the
+// features of Linkerd depend on.
+type Capabilities struct {
+	// Version is the major, minor and patch version of the Kubernetes API.
+	Version [3]int
+	// GroupVersions are the served versions of the API groups, such as
+	// apps/v1, or v1 for the core group.
+	GroupVersions map[string]bool
+}
+
+// Has returns true if the API serves the given group version, e.g.
+// admissionregistration.k8s.io/v1beta1.
+func (c *Capabilities) Has(groupVersion string) bool {
+	return c.GroupVersions[groupVersion]
+}
+
+// AdmissionRegistrationVersion returns the most recent served version of the
+// admissionregistration.k8s.io group, which the webhook configurations of the
+// proxy injector are created with, or an empty string if it isn't served.
+func (c *Capabilities) AdmissionRegistrationVersion() string {
+	return c.preferredVersion(admissionRegistrationGroup, "v1", "v1beta1")
+}
+
+// CRDVersion returns the most recent served version of the
+// apiextensions.k8s.io group, which the CustomResourceDefinitions of Linkerd
+// are created with, or an empty string if it isn't served.
+func (c *Capabilities) CRDVersion() string {
+	return c.preferredVersion(apiExtensionsGroup, "v1", "v1beta1")
+}
+
+// HasEndpointSlices returns true if the API serves EndpointSlices.
+func (c *Capabilities) HasEndpointSlices() bool {
+	return c.preferredVersion(discoveryGroup, "v1", "v1beta1") != ""
+}
+
+// HasSeccompProfileField returns true if the API sets the seccomp profiles of
+// pods with the securityContext.seccompProfile field rather than the
+// deprecated seccomp annotations.
+func (c *Capabilities) HasSeccompProfileField() bool {
+	return isCompatibleVersion(seccompProfileFieldVersion, c.Version)
+}
+
+func (c *Capabilities) preferredVersion(group string, versions ...string) string {
+	for _, version := range versions {
+		if c.Has(group + "/" + version) {
+			return version
+		}
+	}
+	return ""
+}
+
+// GetCapabilities returns the version of the Kubernetes API, and the group
+// versions it serves, as discovered from its /version and /apis endpoints.
+func (kubeAPI *KubernetesAPI) GetCapabilities(client *http.Client) (*Capabilities, error) {
+	versionInfo, err := kubeAPI.GetVersionInfo(client)
+	if err != nil {
+		return nil, err
+	}
+	version, err := getK8sVersion(versionInfo.String())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	path := "/apis"
+	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError("GET", path, rsp)
+	}
+
+	bytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups metav1.APIGroupList
+	if err := json.Unmarshal(bytes, &groups); err != nil {
+		return nil, fmt.Errorf("failed to decode the API groups: %s", err)
+	}
+
+	return newCapabilities(version, groups), nil
+}
+
+func newCapabilities(version [3]int, groups metav1.APIGroupList) *Capabilities {
+	capabilities := &Capabilities{
+		Version: version,
+		// the core group is always served, under /api
+		GroupVersions: map[string]bool{"v1": true},
+	}
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			capabilities.GroupVersions[version.GroupVersion] = true
+		}
+	}
+	return capabilities
+}
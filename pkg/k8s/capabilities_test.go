@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"11","gitVersion":"v1.11.1"}`))
+		case "/apis":
+			w.Write([]byte(`{
+  "kind": "APIGroupList",
+  "groups": [
+    {"name": "apps", "versions": [{"groupVersion": "apps/v1", "version": "v1"}]},
+    {"name": "admissionregistration.k8s.io", "versions": [{"groupVersion": "admissionregistration.k8s.io/v1beta1", "version": "v1beta1"}]},
+    {"name": "apiextensions.k8s.io", "versions": [{"groupVersion": "apiextensions.k8s.io/v1beta1", "version": "v1beta1"}]}
+  ]
+}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+	capabilities, err := api.GetCapabilities(http.DefaultClient)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if capabilities.Version != [3]int{1, 11, 1} {
+		t.Fatalf("Expected version 1.11.1, got %v", capabilities.Version)
+	}
+	for _, groupVersion := range []string{"v1", "apps/v1"} {
+		if !capabilities.Has(groupVersion) {
+			t.Fatalf("Expected %s to be served", groupVersion)
+		}
+	}
+	if version := capabilities.AdmissionRegistrationVersion(); version != "v1beta1" {
+		t.Fatalf("Expected admissionregistration.k8s.io/v1beta1, got [%s]", version)
+	}
+	if version := capabilities.CRDVersion(); version != "v1beta1" {
+		t.Fatalf("Expected apiextensions.k8s.io/v1beta1, got [%s]", version)
+	}
+	if capabilities.HasEndpointSlices() {
+		t.Fatal("Expected EndpointSlices not to be served")
+	}
+	if capabilities.HasSeccompProfileField() {
+		t.Fatal("Expected seccomp profiles to be set with annotations")
+	}
+}
+
+func TestCapabilitiesHasSeccompProfileField(t *testing.T) {
+	for _, tc := range []struct {
+		version  [3]int
+		expected bool
+	}{
+		{[3]int{1, 18, 9}, false},
+		{[3]int{1, 19, 0}, true},
+		{[3]int{1, 27, 3}, true},
+	} {
+		capabilities := &Capabilities{Version: tc.version}
+		if capabilities.HasSeccompProfileField() != tc.expected {
+			t.Fatalf("Expected HasSeccompProfileField to be %t for version %v", tc.expected, tc.version)
+		}
+	}
+}
+
+func TestCapabilitiesPreferredVersions(t *testing.T) {
+	capabilities := &Capabilities{GroupVersions: map[string]bool{
+		"admissionregistration.k8s.io/v1":      true,
+		"admissionregistration.k8s.io/v1beta1": true,
+		"discovery.k8s.io/v1beta1":             true,
+	}}
+
+	if version := capabilities.AdmissionRegistrationVersion(); version != "v1" {
+		t.Fatalf("Expected the most recent version v1, got [%s]", version)
+	}
+	if version := capabilities.CRDVersion(); version != "" {
+		t.Fatalf("Expected apiextensions.k8s.io not to be served, got [%s]", version)
+	}
+	if !capabilities.HasEndpointSlices() {
+		t.Fatal("Expected EndpointSlices to be served")
+	}
+}
@@ -0,0 +1,125 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxErrorBodySize is the size of the response bodies read to find the
+// message of the Kubernetes API's errors.
+const maxErrorBodySize = 64 * 1024
+
+// APIError is returned when the Kubernetes API responds to a request with an
+// unexpected status.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	// Message is the message of the Status the Kubernetes API responded with,
+	// if any.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("Unexpected Kubernetes API response to %s %s: %s", e.Method, e.Path, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// ConnectionError is returned when a request can't reach the Kubernetes API.
+type ConnectionError struct {
+	Host string
+	Err  error
+}
+
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("cannot connect to the Kubernetes API at %s: %s", e.Host, e.Err)
+}
+
+// IsUnauthorized returns true if the Kubernetes API refused a request because
+// its credentials are invalid or don't grant access to the resource.
+func IsUnauthorized(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
+
+// IsNotFound returns true if the resource a request was made for doesn't
+// exist.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConnectionError returns true if a request couldn't reach the Kubernetes
+// API.
+func IsConnectionError(err error) bool {
+	_, ok := err.(*ConnectionError)
+	return ok
+}
+
+// newAPIError returns the APIError of an unexpected response, whose body is
+// read to find the message of the Status it holds.
+func newAPIError(method, path string, rsp *http.Response) *APIError {
+	apiErr := &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: rsp.StatusCode,
+		Status:     rsp.Status,
+	}
+
+	bytes, err := ioutil.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+	if err != nil {
+		return apiErr
+	}
+	var status metav1.Status
+	if err := json.Unmarshal(bytes, &status); err == nil {
+		apiErr.Message = status.Message
+	}
+	return apiErr
+}
+
+// isTransientStatus returns true if a request may succeed if it's retried
+// after a response with the given status.
+func isTransientStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTransientError returns true if a request may succeed if it's retried
+// after failing with err, e.g. because the connection was refused or reset.
+func isTransientError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+		if sysErr, ok := err.(*os.SyscallError); ok {
+			err = sysErr.Err
+		}
+	}
+	switch err {
+	case syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED:
+		return true
+	}
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Temporary()
+}
@@ -0,0 +1,53 @@
+package k8s
+
+import "strings"
+
+// linkerdImageNames are the names of the images that Linkerd builds, which are
+// released along with the control plane, as opposed to the third-party images
+// it runs, such as Prometheus'.
+var linkerdImageNames = map[string]bool{
+	"cni-plugin": true,
+	"controller": true,
+	"grafana":    true,
+	"proxy":      true,
+	"proxy-init": true,
+	"web":        true,
+}
+
+// ImageTag returns the tag of an image, e.g. stable-2.1.0 for
+// gcr.io/linkerd-io/proxy:stable-2.1.0, or for the same image pinned by its
+// digest, gcr.io/linkerd-io/proxy:stable-2.1.0@sha256:..., or "" if the image
+// isn't tagged.
+func ImageTag(image string) string {
+	if digest := strings.Index(image, "@"); digest >= 0 {
+		image = image[:digest]
+	}
+	// the port of the registry, if any, is followed by the repository
+	name := image[strings.LastIndex(image, "/")+1:]
+	if tag := strings.LastIndex(name, ":"); tag >= 0 {
+		return name[tag+1:]
+	}
+	return ""
+}
+
+// ImageDigest returns the digest that an image is pinned by, e.g.
+// sha256:... for gcr.io/linkerd-io/proxy@sha256:..., or "" if it isn't pinned.
+func ImageDigest(image string) string {
+	if digest := strings.Index(image, "@"); digest >= 0 {
+		return image[digest+1:]
+	}
+	return ""
+}
+
+// IsLinkerdImage returns whether the image is built by Linkerd, from whichever
+// registry it's pulled, e.g. the mirror of an air-gapped install.
+func IsLinkerdImage(image string) bool {
+	if digest := strings.Index(image, "@"); digest >= 0 {
+		image = image[:digest]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if tag := strings.LastIndex(name, ":"); tag >= 0 {
+		name = name[:tag]
+	}
+	return linkerdImageNames[name]
+}
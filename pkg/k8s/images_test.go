@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"testing"
+)
+
+func TestImageTag(t *testing.T) {
+	digest := "@sha256:a0f9e1fdd2d0a0a1e0e8d7b6c8f2d4e3a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1"
+	images := map[string]string{
+		"gcr.io/linkerd-io/proxy:stable-2.1.0":                 "stable-2.1.0",
+		"gcr.io/linkerd-io/proxy:stable-2.1.0" + digest:        "stable-2.1.0",
+		"registry.example.com:5000/linkerd/proxy:edge-18.11.1": "edge-18.11.1",
+		"registry.example.com:5000/linkerd/proxy":              "",
+		"gcr.io/linkerd-io/proxy" + digest:                     "",
+		"prom/prometheus":                                      "",
+	}
+
+	for image, expected := range images {
+		if tag := ImageTag(image); tag != expected {
+			t.Fatalf("Expected the tag of %s to be [%s], got [%s]", image, expected, tag)
+		}
+	}
+}
+
+func TestIsLinkerdImage(t *testing.T) {
+	digest := "@sha256:a0f9e1fdd2d0a0a1e0e8d7b6c8f2d4e3a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1"
+	images := map[string]bool{
+		"gcr.io/linkerd-io/controller:stable-2.1.0":                 true,
+		"registry.example.com:5000/linkerd/proxy-init:stable-2.1.0": true,
+		"registry.example.com:5000/linkerd/web" + digest:            true,
+		"prom/prometheus:v2.4.0":                                    false,
+		"registry.example.com:5000/prom/prometheus:v2.4.0":          false,
+	}
+
+	for image, expected := range images {
+		if isLinkerd := IsLinkerdImage(image); isLinkerd != expected {
+			t.Fatalf("Expected IsLinkerdImage(%s) to be %t, got %t", image, expected, isLinkerd)
+		}
+	}
+}
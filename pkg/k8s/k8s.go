@@ -1,8 +1,10 @@
 package k8s
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -58,22 +60,53 @@ func generateBaseKubernetesApiUrl(schemeHostAndPort string) (*url.URL, error) {
 	return url, nil
 }
 
-func getConfig(fpath string) (*rest.Config, error) {
+// inClusterConfig returns the configuration of the pod's service account.
+var inClusterConfig = rest.InClusterConfig
+
+// getConfig returns the configuration of the Kubernetes API client, loaded
+// from the kubeconfig file at fpath, or the default one if fpath is empty. If
+// kubeContext is set, it overrides the current context of the file. If there
+// is no kubeconfig and neither fpath nor kubeContext are set, e.g. when running
+// in a pod, the token and CA of the pod's service account are used instead. If
+// impersonate is set, the requests are made as that user, and as the members
+// of impersonateGroup.
+func getConfig(fpath, kubeContext, impersonate string, impersonateGroup []string) (*rest.Config, error) {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if fpath != "" {
 		rules.ExplicitPath = fpath
 	}
-	overrides := &clientcmd.ConfigOverrides{}
-	return clientcmd.
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	config, err := clientcmd.
 		NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).
 		ClientConfig()
+	if err != nil {
+		if fpath != "" || kubeContext != "" || !clientcmd.IsEmptyConfig(err) {
+			return nil, err
+		}
+
+		config, err = inClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no kubeconfig was found, and the in-cluster configuration is unavailable: %s", err)
+		}
+	}
+
+	if impersonate != "" {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: impersonate,
+			Groups:   impersonateGroup,
+		}
+	} else if len(impersonateGroup) > 0 {
+		return nil, errors.New("impersonating a group requires impersonating a user")
+	}
+	return config, nil
 }
 
 // CanonicalResourceNameFromFriendlyName returns a canonical name from common shorthands used in command line tools.
 // This works based on https://github.com/kubernetes/kubernetes/blob/63ffb1995b292be0a1e9ebde6216b83fc79dd988/pkg/kubectl/kubectl.go#L39
 // This also works for non-k8s resources, e.g. authorities
+// Names are case-insensitive, as they are for kubectl.
 func CanonicalResourceNameFromFriendlyName(friendlyName string) (string, error) {
-	switch friendlyName {
+	switch strings.ToLower(friendlyName) {
 	case "deploy", "deployment", "deployments":
 		return Deployment, nil
 	case "ds", "daemonset", "daemonsets":
@@ -97,6 +130,45 @@ func CanonicalResourceNameFromFriendlyName(friendlyName string) (string, error)
 	return "", fmt.Errorf("cannot find Kubernetes canonical name from friendly name [%s]", friendlyName)
 }
 
+// ParseResource parses the arguments of the commands that name a resource, in
+// the kubectl-style forms KIND, KIND/NAME or KIND NAME, where KIND may be any
+// of the names accepted by CanonicalResourceNameFromFriendlyName. It returns
+// the canonical name of the resource's kind, and the resource's name, which is
+// empty if none was given.
+func ParseResource(args ...string) (string, string, error) {
+	var kind, name string
+	switch len(args) {
+	case 0:
+		return "", "", errors.New("No resource arguments provided")
+	case 1:
+		elems := strings.Split(args[0], "/")
+		switch len(elems) {
+		case 1:
+			kind = elems[0]
+		case 2:
+			kind, name = elems[0], elems[1]
+			if name == "" {
+				return "", "", errors.New("Invalid resource string: " + args[0])
+			}
+		default:
+			return "", "", errors.New("Invalid resource string: " + args[0])
+		}
+	case 2:
+		if strings.Contains(args[0], "/") {
+			return "", "", errors.New("Invalid resource string: " + strings.Join(args, " "))
+		}
+		kind, name = args[0], args[1]
+	default:
+		return "", "", errors.New("Too many arguments provided for resource: " + strings.Join(args, "/"))
+	}
+
+	canonicalKind, err := CanonicalResourceNameFromFriendlyName(kind)
+	if err != nil {
+		return "", "", err
+	}
+	return canonicalKind, name, nil
+}
+
 // Return a the shortest name for a k8s canonical name.
 // Essentially the reverse of CanonicalResourceNameFromFriendlyName
 func ShortNameFromCanonicalResourceName(canonicalName string) string {
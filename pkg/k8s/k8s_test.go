@@ -1,7 +1,11 @@
 package k8s
 
 import (
+	"os"
+	"reflect"
 	"testing"
+
+	"k8s.io/client-go/rest"
 )
 
 func TestGenerateKubernetesApiBaseUrlFor(t *testing.T) {
@@ -60,7 +64,7 @@ func TestGenerateBaseKubernetesApiUrl(t *testing.T) {
 
 func TestGetConfig(t *testing.T) {
 	t.Run("Gets host correctly form existing file", func(t *testing.T) {
-		config, err := getConfig("testdata/config.test")
+		config, err := getConfig("testdata/config.test", "", "", []string{})
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -71,14 +75,129 @@ func TestGetConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("Gets host of the given context", func(t *testing.T) {
+		config, err := getConfig("testdata/config.test", "cluster2", "", []string{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedHost := "https://30.88.172.234"
+		if config.Host != expectedHost {
+			t.Fatalf("Expected host to be [%s] got [%s]", expectedHost, config.Host)
+		}
+	})
+
+	t.Run("Returns error if the context doesn't exist", func(t *testing.T) {
+		_, err := getConfig("testdata/config.test", "missing", "", []string{})
+		if err == nil {
+			t.Fatalf("Expecting error when context doesnt exist, got nothing")
+		}
+	})
+
+	t.Run("Impersonates the given user and groups", func(t *testing.T) {
+		config, err := getConfig("testdata/config.test", "", "alice", []string{"devs", "ops"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if config.Impersonate.UserName != "alice" {
+			t.Fatalf("Expected to impersonate [alice] got [%s]", config.Impersonate.UserName)
+		}
+		if !reflect.DeepEqual(config.Impersonate.Groups, []string{"devs", "ops"}) {
+			t.Fatalf("Expected to impersonate groups [devs ops] got %v", config.Impersonate.Groups)
+		}
+	})
+
+	t.Run("Returns error if groups are impersonated without a user", func(t *testing.T) {
+		_, err := getConfig("testdata/config.test", "", "", []string{"devs"})
+		if err == nil {
+			t.Fatalf("Expecting error when impersonating groups without a user, got nothing")
+		}
+	})
+
 	t.Run("Returns error if configuration cannot be found", func(t *testing.T) {
-		_, err := getConfig("/this/doest./not/exist.config")
+		_, err := getConfig("/this/doest./not/exist.config", "", "", []string{})
 		if err == nil {
 			t.Fatalf("Expecting error when config file doesnt exist, got nothing")
 		}
 	})
 }
 
+func TestGetConfigInCluster(t *testing.T) {
+	defer func(kubeconfig string) {
+		os.Setenv("KUBECONFIG", kubeconfig)
+		inClusterConfig = rest.InClusterConfig
+	}(os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", "testdata/missing.config")
+	inClusterConfig = func() (*rest.Config, error) {
+		return &rest.Config{Host: "https://10.96.0.1:443"}, nil
+	}
+
+	t.Run("Falls back to the in-cluster configuration without a kubeconfig", func(t *testing.T) {
+		config, err := getConfig("", "", "", []string{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedHost := "https://10.96.0.1:443"
+		if config.Host != expectedHost {
+			t.Fatalf("Expected host to be [%s] got [%s]", expectedHost, config.Host)
+		}
+	})
+
+	t.Run("Doesn't fall back if a context is given", func(t *testing.T) {
+		_, err := getConfig("", "cluster2", "", []string{})
+		if err == nil {
+			t.Fatalf("Expecting error when the context doesnt exist, got nothing")
+		}
+	})
+}
+
+func TestParseResource(t *testing.T) {
+	t.Run("Parses the kind and name of resources", func(t *testing.T) {
+		expectations := []struct {
+			args []string
+			kind string
+			name string
+		}{
+			{[]string{"deploy"}, Deployment, ""},
+			{[]string{"deployments/web"}, Deployment, "web"},
+			{[]string{"po", "web-1"}, Pod, "web-1"},
+			{[]string{"NS/emojivoto"}, Namespace, "emojivoto"},
+			{[]string{"ds"}, DaemonSet, ""},
+			{[]string{"sts", "db"}, StatefulSet, "db"},
+		}
+
+		for _, exp := range expectations {
+			kind, name, err := ParseResource(exp.args...)
+			if err != nil {
+				t.Fatalf("Unexpected error parsing %v: %v", exp.args, err)
+			}
+			if kind != exp.kind || name != exp.name {
+				t.Fatalf("Expected %v to be parsed as [%s/%s], got [%s/%s]", exp.args, exp.kind, exp.name, kind, name)
+			}
+		}
+	})
+
+	t.Run("Returns error if the arguments are invalid", func(t *testing.T) {
+		invalidArgs := [][]string{
+			{},
+			{"deploy/"},
+			{"deploy/web/1"},
+			{"deploy/web", "1"},
+			{"deploy", "web", "1"},
+			{"paths/web"},
+		}
+
+		for _, args := range invalidArgs {
+			kind, name, err := ParseResource(args...)
+			if err == nil {
+				t.Fatalf("Expecting error when parsing %v, but it was parsed as [%s/%s]", args, kind, name)
+			}
+		}
+	})
+}
+
 func TestCanonicalResourceNameFromFriendlyName(t *testing.T) {
 	t.Run("Returns canonical name for all known variants", func(t *testing.T) {
 		expectations := map[string]string{
@@ -88,6 +207,8 @@ func TestCanonicalResourceNameFromFriendlyName(t *testing.T) {
 			"deployments": Deployment,
 			"au":          Authority,
 			"authorities": Authority,
+			"Deployment":  Deployment,
+			"STS":         StatefulSet,
 		}
 
 		for input, expectedName := range expectations {
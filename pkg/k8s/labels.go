@@ -7,6 +7,7 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/linkerd/linkerd2/pkg/version"
 	appsV1 "k8s.io/api/apps/v1"
@@ -50,6 +51,10 @@ const (
 	// StatefulSet that this proxy belongs to.
 	ProxyStatefulSetLabel = "linkerd.io/proxy-statefulset"
 
+	// ProxyCronJobLabel is injected into mesh-enabled apps, identifying the
+	// CronJob that this proxy belongs to.
+	ProxyCronJobLabel = "linkerd.io/proxy-cronjob"
+
 	/*
 	 * Annotations
 	 */
@@ -62,6 +67,119 @@ const (
 	// (e.g. v0.1.3).
 	ProxyVersionAnnotation = "linkerd.io/proxy-version"
 
+	// ProxyInjectAnnotation controls whether the proxy injector adds the proxy
+	// to pods, when set on pods or their namespaces to ProxyInjectEnabled or
+	// ProxyInjectDisabled. An annotation on a pod takes precedence over one on
+	// its namespace. Pods, workloads and namespaces that set it to
+	// ProxyInjectDisabled are also skipped by `linkerd inject`.
+	ProxyInjectAnnotation = "linkerd.io/inject"
+	ProxyInjectEnabled    = "enabled"
+	ProxyInjectDisabled   = "disabled"
+
+	// ProxyImageAnnotation, ProxyLogLevelAnnotation,
+	// ProxyControlPlaneAddressAnnotation, ProxyInboundPortAnnotation,
+	// ProxyOutboundPortAnnotation, ProxyTraceCollectorAnnotation and
+	// ProxyTracePropagationAnnotation record the proxy configuration that was
+	// overridden when a workload was injected, so that the same configuration
+	// is used when the proxy is injected again, e.g. by the proxy injector.
+	ProxyImageAnnotation               = "linkerd.io/proxy-image"
+	ProxyLogLevelAnnotation            = "linkerd.io/proxy-log-level"
+	ProxyControlPlaneAddressAnnotation = "linkerd.io/control-plane-address"
+	ProxyInboundPortAnnotation         = "linkerd.io/proxy-inbound-port"
+	ProxyOutboundPortAnnotation        = "linkerd.io/proxy-outbound-port"
+	ProxyTraceCollectorAnnotation      = "linkerd.io/trace-collector"
+	ProxyTracePropagationAnnotation    = "linkerd.io/trace-propagation"
+
+	// TracePropagationB3 and TracePropagationW3C are the formats of the trace
+	// context that the proxies can propagate.
+	TracePropagationB3  = "b3"
+	TracePropagationW3C = "w3c"
+
+	// IdentityModeAnnotation records how the proxy of an injected pod gets its
+	// TLS identity. It's set to IdentityModeServiceAccount when the proxy is
+	// certified by the identity service for the pod's ServiceAccount, instead
+	// of mounting the Secret the CA issues for the pod's owner.
+	IdentityModeAnnotation     = "linkerd.io/identity-mode"
+	IdentityModeServiceAccount = "service-account"
+
+	// TLSModeAnnotation is set on a namespace to override the cluster-wide
+	// TLS mode of its pods. With TLSModeStrict, the proxy injector rejects the
+	// pods it injects unless their proxies are certified by the identity
+	// service; with TLSModePermissive, it admits them.
+	TLSModeAnnotation = "linkerd.io/tls-mode"
+	TLSModePermissive = "permissive"
+	TLSModeStrict     = "strict"
+
+	// TrustAnchorsUpdatedAtAnnotation records when the trust anchors of the
+	// TLSTrustAnchorConfigMapName ConfigMap of a namespace last changed, in
+	// RFC 3339 format. Proxies load the trust anchors when they start, so the
+	// proxies that started before then still use the previous ones.
+	TrustAnchorsUpdatedAtAnnotation = "linkerd.io/trust-anchors-updated-at"
+
+	// SeccompProfileTypeRuntimeDefault is the type of the seccomp profile
+	// that the control plane and the proxy set in their securityContext when
+	// installed or injected with --restricted-pod-security.
+	SeccompProfileTypeRuntimeDefault = "RuntimeDefault"
+
+	// PodSeccompAnnotation sets the seccomp profile of a pod's containers, and
+	// ContainerSeccompAnnotationPrefix followed by the name of a container
+	// sets the profile of that container, on versions of Kubernetes older
+	// than 1.19, which deprecated them for the securityContext.seccompProfile
+	// field.
+	PodSeccompAnnotation             = "seccomp.security.alpha.kubernetes.io/pod"
+	ContainerSeccompAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	SeccompProfileRuntimeDefault     = "runtime/default"
+
+	// PodSecurityEnforceLabel is the label of a namespace that sets the pod
+	// security level enforced by the Kubernetes API server for its pods, i.e.
+	// PodSecurityPrivileged, PodSecurityBaseline or PodSecurityRestricted.
+	PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	PodSecurityPrivileged   = "privileged"
+	PodSecurityBaseline     = "baseline"
+	PodSecurityRestricted   = "restricted"
+
+	// NodeZoneLabel and NodeRegionLabel are set on nodes by the cloud
+	// providers, to the zone and region of the nodes.
+	NodeZoneLabel   = "failure-domain.beta.kubernetes.io/zone"
+	NodeRegionLabel = "failure-domain.beta.kubernetes.io/region"
+
+	// NodeArchLabel and NodeArchBetaLabel are set on nodes by the kubelet, to
+	// the architecture of the nodes, e.g. amd64 or arm64. Pods are scheduled
+	// on the nodes of an architecture with a node selector on either label.
+	NodeArchLabel     = "kubernetes.io/arch"
+	NodeArchBetaLabel = "beta.kubernetes.io/arch"
+
+	/*
+	 * Multicluster
+	 */
+
+	// ExportedServiceLabel is set to "true" on the services of a cluster that
+	// are mirrored into the clusters linked to it by the service mirror.
+	ExportedServiceLabel = "mirror.linkerd.io/exported"
+
+	// MirroredServiceLabel is set to "true" on the services created by the
+	// service mirror, and RemoteClusterNameLabel identifies the cluster they
+	// were mirrored from.
+	MirroredServiceLabel   = "mirror.linkerd.io/mirrored-service"
+	RemoteClusterNameLabel = "mirror.linkerd.io/cluster-name"
+
+	// GatewayIdentityAnnotation is set on the gateway service of a cluster to
+	// the TLS identity of the gateway's proxy, through which the other
+	// clusters reach its exported services.
+	GatewayIdentityAnnotation = "mirror.linkerd.io/gateway-identity"
+
+	// RemoteGatewayIdentityAnnotation and RemoteControllerNSAnnotation record
+	// on a mirrored service the TLS identity of the gateway of the remote
+	// cluster, and the namespace of the remote control plane, so that the
+	// destination service secures the connections to the gateway with mTLS.
+	// RemoteServiceFQNAnnotation records the fully-qualified name of the
+	// remote service, to which the proxies rewrite the authority of the
+	// requests to the mirrored service, so that the gateway routes them to
+	// the remote service.
+	RemoteGatewayIdentityAnnotation = "mirror.linkerd.io/remote-gateway-identity"
+	RemoteControllerNSAnnotation    = "mirror.linkerd.io/remote-controller-ns"
+	RemoteServiceFQNAnnotation      = "mirror.linkerd.io/remote-svc-fq-name"
+
 	/*
 	 * Component Names
 	 */
@@ -72,6 +190,11 @@ const (
 	// ProxyContainerName is the name assigned to the injected proxy container.
 	ProxyContainerName = "linkerd-proxy"
 
+	// InstallConfigMapName is the name of the ConfigMap that records the
+	// images that the control plane was installed with, so that they can be
+	// validated by `linkerd check`.
+	InstallConfigMapName = "linkerd-config"
+
 	// TLSTrustAnchorConfigMapName is the name of the ConfigMap that holds the
 	// trust anchors (trusted root certificates).
 	TLSTrustAnchorConfigMapName = "linkerd-ca-bundle"
@@ -82,6 +205,79 @@ const (
 
 	TLSCertFileName       = "certificate.crt"
 	TLSPrivateKeyFileName = "private-key.p8"
+
+	// TLSFederationConfigMapName is the name of the ConfigMap that holds the
+	// trust anchors of the other trust domains that are federated with the
+	// control plane's trust domain.
+	TLSFederationConfigMapName = "linkerd-federation"
+
+	// DefaultTrustDomain is the trust domain that TLS identities belong to when
+	// none is configured at install time.
+	DefaultTrustDomain = "cluster.local"
+
+	// DefaultClusterDomain is the DNS domain of the cluster's services when
+	// none is configured at install time.
+	DefaultClusterDomain = "cluster.local"
+
+	// IdentityServiceName is the name of the Service of the identity service,
+	// which serves on IdentityServicePort.
+	IdentityServiceName = "linkerd-identity"
+	IdentityServicePort = 8083
+
+	// ProxyInjectorTLSSecretName, ProfileValidatorTLSSecretName and
+	// TapAPIServerTLSSecretName are the names of the Secrets that hold the
+	// serving certificates of the proxy injector and service profile validator
+	// webhooks and of the tap API server, issued at install time, under
+	// ServingTLSCertKey.
+	ProxyInjectorTLSSecretName    = "linkerd-proxy-injector-tls"
+	ProfileValidatorTLSSecretName = "linkerd-sp-validator-tls"
+	TapAPIServerTLSSecretName     = "linkerd-tap-apiserver-tls"
+	ServingTLSCertKey             = "tls.crt"
+
+	// IdentityIssuerSecretName is the name of the Secret that holds the issuer
+	// credentials provided at install time.
+	IdentityIssuerSecretName = "linkerd-identity-issuer"
+
+	// IdentityIssuerCertificateKey, IdentityIssuerPrivateKeyKey and
+	// IdentityIssuerTrustAnchorsKey are the keys of the Secret that holds the
+	// issuer credentials of the CA when they're maintained outside of the
+	// control plane. They follow the format of the kubernetes.io/tls Secrets
+	// written by cert-manager: the PEM-encoded issuer certificate and its
+	// chain, its private key, and the trust anchors it chains to.
+	IdentityIssuerCertificateKey  = "tls.crt"
+	IdentityIssuerPrivateKeyKey   = "tls.key"
+	IdentityIssuerTrustAnchorsKey = "ca.crt"
+
+	// CAServiceAccountName and ControllerServiceAccountName are the names of
+	// the ServiceAccounts of the CA, which serves the identity service, and of
+	// the controller.
+	CAServiceAccountName         = "linkerd-ca"
+	ControllerServiceAccountName = "linkerd-controller"
+
+	// ServiceAccountTokenPath is the path at which Kubernetes mounts the token
+	// of a pod's ServiceAccount in its containers.
+	ServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// TLSSecretsVolumeName is the name of the volume through which the
+	// injected proxy mounts the secret holding its TLS identity.
+	TLSSecretsVolumeName = "linkerd-secrets"
+
+	// ProxyReadyConditionType is the type of the pod condition, and of the
+	// readiness gate added by `linkerd inject --readiness-gate`, that is set
+	// once a pod's proxy is ready to serve meshed traffic.
+	ProxyReadyConditionType = "linkerd.io/proxy-ready"
+
+	// TapAPIGroup and TapAPIVersion identify the tap API registered with the
+	// Kubernetes API server by `linkerd install --tap-rbac`. RBAC rules grant
+	// access to tap with the "watch" verb on the "tap" resource of this group.
+	TapAPIGroup   = "tap.linkerd.io"
+	TapAPIVersion = "v1alpha1"
+
+	// CustomMetricsAPIGroup and CustomMetricsAPIVersion identify the custom
+	// metrics API registered with the Kubernetes API server by
+	// `linkerd install --metrics-adapter`, which HorizontalPodAutoscalers query.
+	CustomMetricsAPIGroup   = "custom.metrics.k8s.io"
+	CustomMetricsAPIVersion = "v1beta1"
 )
 
 // CreatedByAnnotationValue returns the value associated with
@@ -110,10 +306,66 @@ func GetPodLabels(ownerKind, ownerName string, pod *coreV1.Pod) map[string]strin
 	return labels
 }
 
+// ExpectedTLSIdentityLabel is the metadata label of the peer of a tapped
+// request sent over mTLS that holds the TLS identity the peer's proxy is
+// expected to be certified for, given its pod. The proxies don't report the
+// identity of their peers' certificates, so it isn't the validated identity of
+// the connection.
+const ExpectedTLSIdentityLabel = "expected_tls_identity"
+
+// PodIdentity returns the DNS name of the TLS identity of the pod's proxy:
+// the identity of its ServiceAccount if the proxy is certified by the
+// identity service, or else the identity of the pod's owner.
+func PodIdentity(pod *coreV1.Pod, ownerKind, ownerName, controllerNamespace, trustDomain string) string {
+	if pod.Annotations[IdentityModeAnnotation] == IdentityModeServiceAccount {
+		serviceAccount := pod.Spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		identity := ServiceAccountIdentity{
+			Name:                serviceAccount,
+			Namespace:           pod.Namespace,
+			ControllerNamespace: controllerNamespace,
+			TrustDomain:         trustDomain,
+		}
+		return identity.ToDNSName()
+	}
+
+	identity := TLSIdentity{
+		Name:                ownerName,
+		Kind:                ownerKind,
+		Namespace:           pod.Namespace,
+		ControllerNamespace: controllerNamespace,
+		TrustDomain:         trustDomain,
+	}
+	return identity.ToDNSName()
+}
+
 func IsMeshed(pod *coreV1.Pod, controllerNS string) bool {
 	return pod.Labels[ControllerNSLabel] == controllerNS
 }
 
+// NamespaceTLSMode returns the TLS mode of the proxies of a namespace: the mode
+// of its TLSModeAnnotation if it's set, or else the cluster-wide mode. A nil
+// namespace has the cluster-wide mode. An invalid annotation is returned as an
+// error along with TLSModeStrict, so that a typo can't admit proxies without
+// an identity.
+func NamespaceTLSMode(ns *coreV1.Namespace, clusterMode string) (string, error) {
+	if ns == nil {
+		return clusterMode, nil
+	}
+
+	mode, ok := ns.Annotations[TLSModeAnnotation]
+	switch {
+	case !ok:
+		return clusterMode, nil
+	case mode == TLSModePermissive || mode == TLSModeStrict:
+		return mode, nil
+	default:
+		return TLSModeStrict, fmt.Errorf("invalid %s annotation %q on namespace %s, must be one of: %s, %s", TLSModeAnnotation, mode, ns.Name, TLSModePermissive, TLSModeStrict)
+	}
+}
+
 // TLSIdentity is the identity of a pod owner (Deployment, Pod,
 // ReplicationController, etc.).
 type TLSIdentity struct {
@@ -130,11 +382,19 @@ type TLSIdentity struct {
 
 	// ControllerNamespace is the namespace of the controller for the pod.
 	ControllerNamespace string
+
+	// TrustDomain is the trust domain the identity belongs to. It defaults to
+	// DefaultTrustDomain when empty.
+	TrustDomain string
 }
 
 func (i TLSIdentity) ToDNSName() string {
-	return fmt.Sprintf("%s.%s.%s.linkerd-managed.%s.svc.cluster.local", i.Name,
-		i.Kind, i.Namespace, i.ControllerNamespace)
+	trustDomain := i.TrustDomain
+	if trustDomain == "" {
+		trustDomain = DefaultTrustDomain
+	}
+	return fmt.Sprintf("%s.%s.%s.linkerd-managed.%s.svc.%s", i.Name,
+		i.Kind, i.Namespace, i.ControllerNamespace, trustDomain)
 }
 
 func (i TLSIdentity) ToSecretName() string {
@@ -147,5 +407,81 @@ func (i TLSIdentity) ToControllerIdentity() TLSIdentity {
 		Kind:                "deployment",
 		Namespace:           i.ControllerNamespace,
 		ControllerNamespace: i.ControllerNamespace,
+		TrustDomain:         i.TrustDomain,
 	}
 }
+
+// ServiceAccountIdentity is the identity of the pods that run as a
+// ServiceAccount, as certified by the identity service.
+type ServiceAccountIdentity struct {
+	// Name is the name of the ServiceAccount.
+	Name string
+
+	// Namespace is the namespace of the ServiceAccount and its pods.
+	Namespace string
+
+	// ControllerNamespace is the namespace of the controller for the pods.
+	ControllerNamespace string
+
+	// TrustDomain is the trust domain the identity belongs to. It defaults to
+	// DefaultTrustDomain when empty.
+	TrustDomain string
+}
+
+// serviceAccountIdentityInfix separates the ServiceAccount and its namespace
+// from the controller namespace and trust domain in the DNS names of
+// ServiceAccountIdentities.
+const serviceAccountIdentityInfix = ".serviceaccount.identity."
+
+func (i ServiceAccountIdentity) ToDNSName() string {
+	trustDomain := i.TrustDomain
+	if trustDomain == "" {
+		trustDomain = DefaultTrustDomain
+	}
+	return fmt.Sprintf("%s.%s%s%s.%s", i.Name, i.Namespace,
+		serviceAccountIdentityInfix, i.ControllerNamespace, trustDomain)
+}
+
+func (i ServiceAccountIdentity) ToControllerIdentity() ServiceAccountIdentity {
+	return ServiceAccountIdentity{
+		Name:                ControllerServiceAccountName,
+		Namespace:           i.ControllerNamespace,
+		ControllerNamespace: i.ControllerNamespace,
+		TrustDomain:         i.TrustDomain,
+	}
+}
+
+func (i ServiceAccountIdentity) ToIdentityServiceIdentity() ServiceAccountIdentity {
+	return ServiceAccountIdentity{
+		Name:                CAServiceAccountName,
+		Namespace:           i.ControllerNamespace,
+		ControllerNamespace: i.ControllerNamespace,
+		TrustDomain:         i.TrustDomain,
+	}
+}
+
+// ParseServiceAccountIdentity returns the ServiceAccountIdentity of a DNS name
+// in the given controller namespace and trust domain.
+func ParseServiceAccountIdentity(dnsName, controllerNamespace, trustDomain string) (ServiceAccountIdentity, error) {
+	if trustDomain == "" {
+		trustDomain = DefaultTrustDomain
+	}
+	suffix := serviceAccountIdentityInfix + controllerNamespace + "." + trustDomain
+	if !strings.HasSuffix(dnsName, suffix) {
+		return ServiceAccountIdentity{}, fmt.Errorf("%s is not an identity of the %s trust domain", dnsName, trustDomain)
+	}
+
+	// ServiceAccount names may contain dots, but namespaces can't
+	account := strings.TrimSuffix(dnsName, suffix)
+	i := strings.LastIndex(account, ".")
+	if i <= 0 || i == len(account)-1 {
+		return ServiceAccountIdentity{}, fmt.Errorf("%s is not the identity of a ServiceAccount", dnsName)
+	}
+
+	return ServiceAccountIdentity{
+		Name:                account[:i],
+		Namespace:           account[i+1:],
+		ControllerNamespace: controllerNamespace,
+		TrustDomain:         trustDomain,
+	}, nil
+}
@@ -38,3 +38,112 @@ func TestGetPodLabels(t *testing.T) {
 		}
 	})
 }
+
+func TestTLSIdentity(t *testing.T) {
+	t.Run("Uses the default trust domain", func(t *testing.T) {
+		identity := TLSIdentity{
+			Name:                "web",
+			Kind:                "deployment",
+			Namespace:           "emojivoto",
+			ControllerNamespace: "linkerd",
+		}
+
+		expected := "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"
+		if dnsName := identity.ToDNSName(); dnsName != expected {
+			t.Fatalf("Expected DNS name [%s], got [%s]", expected, dnsName)
+		}
+	})
+
+	t.Run("Embeds the trust domain in the identity and its controller's", func(t *testing.T) {
+		identity := TLSIdentity{
+			Name:                "web",
+			Kind:                "deployment",
+			Namespace:           "emojivoto",
+			ControllerNamespace: "linkerd",
+			TrustDomain:         "prod.example.com",
+		}
+
+		expected := "web.deployment.emojivoto.linkerd-managed.linkerd.svc.prod.example.com"
+		if dnsName := identity.ToDNSName(); dnsName != expected {
+			t.Fatalf("Expected DNS name [%s], got [%s]", expected, dnsName)
+		}
+
+		expected = "controller.deployment.linkerd.linkerd-managed.linkerd.svc.prod.example.com"
+		if dnsName := identity.ToControllerIdentity().ToDNSName(); dnsName != expected {
+			t.Fatalf("Expected controller DNS name [%s], got [%s]", expected, dnsName)
+		}
+	})
+}
+
+func TestServiceAccountIdentity(t *testing.T) {
+	t.Run("Round-trips through its DNS name", func(t *testing.T) {
+		identity := ServiceAccountIdentity{
+			Name:                "web.v2",
+			Namespace:           "emojivoto",
+			ControllerNamespace: "linkerd",
+			TrustDomain:         "prod.example.com",
+		}
+
+		expected := "web.v2.emojivoto.serviceaccount.identity.linkerd.prod.example.com"
+		dnsName := identity.ToDNSName()
+		if dnsName != expected {
+			t.Fatalf("Expected DNS name [%s], got [%s]", expected, dnsName)
+		}
+
+		parsed, err := ParseServiceAccountIdentity(dnsName, "linkerd", "prod.example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(parsed, identity) {
+			t.Fatalf("Expected identity [%+v], got [%+v]", identity, parsed)
+		}
+
+		expected = "linkerd-controller.linkerd.serviceaccount.identity.linkerd.prod.example.com"
+		if dnsName := identity.ToControllerIdentity().ToDNSName(); dnsName != expected {
+			t.Fatalf("Expected controller DNS name [%s], got [%s]", expected, dnsName)
+		}
+	})
+
+	t.Run("Rejects the identities of other trust domains and pod owners", func(t *testing.T) {
+		for _, dnsName := range []string{
+			"web.emojivoto.serviceaccount.identity.linkerd.cluster.local",
+			"web.emojivoto.serviceaccount.identity.other.prod.example.com",
+			"emojivoto.serviceaccount.identity.linkerd.prod.example.com",
+			"web.deployment.emojivoto.linkerd-managed.linkerd.svc.prod.example.com",
+		} {
+			if _, err := ParseServiceAccountIdentity(dnsName, "linkerd", "prod.example.com"); err == nil {
+				t.Fatalf("Expected an error for [%s]", dnsName)
+			}
+		}
+	})
+}
+
+func TestNamespaceTLSMode(t *testing.T) {
+	namespace := func(annotations map[string]string) *coreV1.Namespace {
+		return &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "emojivoto", Annotations: annotations}}
+	}
+
+	testCases := []struct {
+		desc     string
+		ns       *coreV1.Namespace
+		expected string
+		err      bool
+	}{
+		{"Defaults to the cluster-wide mode", namespace(nil), TLSModePermissive, false},
+		{"Defaults to the cluster-wide mode for unknown namespaces", nil, TLSModePermissive, false},
+		{"Uses the mode of the namespace", namespace(map[string]string{TLSModeAnnotation: TLSModeStrict}), TLSModeStrict, false},
+		{"Is strict if the mode of the namespace is invalid", namespace(map[string]string{TLSModeAnnotation: "mandatory"}), TLSModeStrict, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			mode, err := NamespaceTLSMode(tc.ns, TLSModePermissive)
+			if (err != nil) != tc.err {
+				t.Fatalf("Expected error: %t, got: %v", tc.err, err)
+			}
+			if mode != tc.expected {
+				t.Fatalf("Expected mode %s, got %s", tc.expected, mode)
+			}
+		})
+	}
+}
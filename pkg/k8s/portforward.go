@@ -0,0 +1,252 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// portForwardRetryInterval is the interval at which a dropped port-forward
+// is re-established.
+const portForwardRetryInterval = 2 * time.Second
+
+// PortForward forwards a local port to a port of a pod through the Kubernetes
+// API, as `kubectl port-forward` does. The pod is selected by label, so that
+// the port-forward is re-established to another pod of the same component
+// whenever the connection drops, e.g. when the pod is restarted.
+type PortForward struct {
+	config        *rest.Config
+	clientset     kubernetes.Interface
+	namespace     string
+	selector      string
+	fieldSelector string
+	localPort     int
+	remotePort    int
+	out           io.Writer
+	errOut        io.Writer
+	readyCh       chan struct{}
+	readyOnce     sync.Once
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+}
+
+// NewPortForward returns a PortForward from localPort to the remotePort of a
+// running pod matching the label selector in namespace, or from a random
+// local port if localPort is 0, in the cluster of kubeContext or of the current
+// context if it's empty, as the impersonate user and impersonateGroup groups
+// if set. The messages of the port-forward are written
+// to out, and its errors to errOut.
+func NewPortForward(configPath, kubeContext, impersonate string, impersonateGroup []string, namespace, selector string, localPort, remotePort int, out, errOut io.Writer) (*PortForward, error) {
+	config, err := getConfig(configPath, kubeContext, impersonate, impersonateGroup)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring Kubernetes API client: %v", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newPortForward(config, clientset, namespace, selector, localPort, remotePort, out, errOut)
+}
+
+// NewControlPlanePortForward returns a PortForward to the remotePort of a pod
+// of a control plane component.
+func NewControlPlanePortForward(configPath, kubeContext, impersonate string, impersonateGroup []string, controlPlaneNamespace, component string, localPort, remotePort int, out, errOut io.Writer) (*PortForward, error) {
+	selector := fmt.Sprintf("%s=%s", ControllerComponentLabel, component)
+	return NewPortForward(configPath, kubeContext, impersonate, impersonateGroup, controlPlaneNamespace, selector, localPort, remotePort, out, errOut)
+}
+
+// NewPodPortForward returns a PortForward to the remotePort of the pod of the
+// given name, which is only forwarded to while it's running.
+func NewPodPortForward(configPath, kubeContext, impersonate string, impersonateGroup []string, namespace, podName string, localPort, remotePort int, out, errOut io.Writer) (*PortForward, error) {
+	pf, err := NewPortForward(configPath, kubeContext, impersonate, impersonateGroup, namespace, "", localPort, remotePort, out, errOut)
+	if err != nil {
+		return nil, err
+	}
+	pf.fieldSelector = fmt.Sprintf("metadata.name=%s", podName)
+	return pf, nil
+}
+
+func newPortForward(config *rest.Config, clientset kubernetes.Interface, namespace, selector string, localPort, remotePort int, out, errOut io.Writer) (*PortForward, error) {
+	if localPort == 0 {
+		// the local port is chosen up front, so that it stays the same when
+		// the port-forward is re-established
+		port, err := randomLocalPort()
+		if err != nil {
+			return nil, err
+		}
+		localPort = port
+	}
+
+	return &PortForward{
+		config:     config,
+		clientset:  clientset,
+		namespace:  namespace,
+		selector:   selector,
+		localPort:  localPort,
+		remotePort: remotePort,
+		out:        out,
+		errOut:     errOut,
+		readyCh:    make(chan struct{}),
+		stopCh:     make(chan struct{}),
+	}, nil
+}
+
+// Run forwards the port until Stop is called, re-establishing the
+// port-forward whenever it drops. It returns an error if no pod can be
+// forwarded to when it starts.
+func (pf *PortForward) Run() error {
+	for {
+		err := pf.forward()
+		select {
+		case <-pf.stopCh:
+			return nil
+		case <-pf.readyCh:
+			log.Debugf("port-forward to %s in namespace %s dropped, retrying: %v", pf.selectors(), pf.namespace, err)
+		default:
+			if err == nil {
+				err = errors.New("the port-forward was closed before it was ready")
+			}
+			return err
+		}
+
+		select {
+		case <-time.After(portForwardRetryInterval):
+		case <-pf.stopCh:
+			return nil
+		}
+	}
+}
+
+// forward forwards the port to a pod until the connection drops or Stop is
+// called.
+func (pf *PortForward) forward() error {
+	pod, err := pf.selectPod()
+	if err != nil {
+		return err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(pf.config)
+	if err != nil {
+		return err
+	}
+	req := pf.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	ports := []string{fmt.Sprintf("%d:%d", pf.localPort, pf.remotePort)}
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.New(dialer, ports, pf.stopCh, readyCh, pf.out, pf.errOut)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		select {
+		case <-readyCh:
+			pf.readyOnce.Do(func() { close(pf.readyCh) })
+		case <-pf.stopCh:
+		}
+	}()
+
+	return forwarder.ForwardPorts()
+}
+
+// selectPod returns a running pod matching the selector, preferring those
+// whose containers are ready.
+func (pf *PortForward) selectPod() (*v1.Pod, error) {
+	pods, err := pf.clientset.CoreV1().Pods(pf.namespace).List(metav1.ListOptions{
+		LabelSelector: pf.selector,
+		FieldSelector: pf.fieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var running *v1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if podReady(pod) {
+			return pod, nil
+		}
+		if running == nil {
+			running = pod
+		}
+	}
+	if running == nil {
+		return nil, fmt.Errorf("no running pods found for %s in namespace %s", pf.selectors(), pf.namespace)
+	}
+	return running, nil
+}
+
+// selectors describes the label and field selectors of the pods forwarded to.
+func (pf *PortForward) selectors() string {
+	selectors := []string{}
+	for _, selector := range []string{pf.selector, pf.fieldSelector} {
+		if selector != "" {
+			selectors = append(selectors, selector)
+		}
+	}
+	return strings.Join(selectors, ",")
+}
+
+// Ready returns a channel that is closed once the port is forwarded.
+func (pf *PortForward) Ready() <-chan struct{} {
+	return pf.readyCh
+}
+
+// Stop stops forwarding the port.
+func (pf *PortForward) Stop() {
+	pf.stopOnce.Do(func() { close(pf.stopCh) })
+}
+
+// LocalPort returns the local port that is forwarded.
+func (pf *PortForward) LocalPort() int {
+	return pf.localPort
+}
+
+// URLFor returns the URL of path on the forwarded port.
+func (pf *PortForward) URLFor(path string) string {
+	return fmt.Sprintf("http://localhost:%d%s", pf.localPort, path)
+}
+
+func podReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func randomLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return 0, errors.New("failed to find a free local port")
+	}
+	return addr.Port, nil
+}
@@ -0,0 +1,135 @@
+package k8s
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func webPod(name string, phase v1.PodPhase, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "linkerd",
+			Labels:    map[string]string{ControllerComponentLabel: "web"},
+		},
+		Status: v1.PodStatus{
+			Phase:      phase,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestPortForwardSelectPod(t *testing.T) {
+	selector := fmt.Sprintf("%s=web", ControllerComponentLabel)
+
+	expectations := []struct {
+		description string
+		pods        []*v1.Pod
+		expected    string
+	}{
+		{
+			"selects a ready pod",
+			[]*v1.Pod{
+				webPod("web-1", v1.PodPending, false),
+				webPod("web-2", v1.PodRunning, false),
+				webPod("web-3", v1.PodRunning, true),
+			},
+			"web-3",
+		},
+		{
+			"selects a running pod if none is ready",
+			[]*v1.Pod{
+				webPod("web-1", v1.PodFailed, false),
+				webPod("web-2", v1.PodRunning, false),
+			},
+			"web-2",
+		},
+		{
+			"returns an error if no pod is running",
+			[]*v1.Pod{
+				webPod("web-1", v1.PodPending, false),
+			},
+			"",
+		},
+	}
+
+	for _, exp := range expectations {
+		t.Run(exp.description, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, pod := range exp.pods {
+				if _, err := clientset.CoreV1().Pods(pod.Namespace).Create(pod); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+			}
+
+			pf, err := newPortForward(&rest.Config{}, clientset, "linkerd", selector, 0, 8084, ioutil.Discard, ioutil.Discard)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			pod, err := pf.selectPod()
+			if exp.expected == "" {
+				if err == nil {
+					t.Fatalf("Expected an error, got pod %s", pod.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if pod.Name != exp.expected {
+				t.Fatalf("Expected pod %s, got %s", exp.expected, pod.Name)
+			}
+		})
+	}
+}
+
+func TestPortForwardURLFor(t *testing.T) {
+	t.Run("Forwards the given local port", func(t *testing.T) {
+		pf, err := newPortForward(&rest.Config{}, fake.NewSimpleClientset(), "linkerd", "", 50750, 8084, ioutil.Discard, ioutil.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		url := pf.URLFor("/overview")
+		if url != "http://localhost:50750/overview" {
+			t.Fatalf("Unexpected URL: %s", url)
+		}
+	})
+
+	t.Run("Forwards a random local port if none is given", func(t *testing.T) {
+		pf, err := newPortForward(&rest.Config{}, fake.NewSimpleClientset(), "linkerd", "", 0, 8084, ioutil.Discard, ioutil.Discard)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if pf.LocalPort() == 0 {
+			t.Fatal("Expected a local port to be chosen")
+		}
+	})
+}
+
+func TestPortForwardStop(t *testing.T) {
+	pf, err := newPortForward(&rest.Config{}, fake.NewSimpleClientset(), "linkerd", "", 0, 8084, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// stopping twice doesn't panic
+	pf.Stop()
+	pf.Stop()
+
+	if err := pf.Run(); err != nil {
+		t.Fatalf("Expected Run to return once stopped, got: %s", err)
+	}
+}
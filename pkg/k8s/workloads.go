@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload is the resource owning a pod, such as a Deployment, or the pod
+// itself if it has no owner.
+type Workload struct {
+	// Kind is the singular, lowercased Kubernetes resource type of the
+	// workload (deployment, daemonset, job, pod, etc.).
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the workload in the KIND/NAME form of the CLI, with the
+// short name of its kind if it has one.
+func (w Workload) String() string {
+	kind := w.Kind
+	if short := ShortNameFromCanonicalResourceName(kind); short != "" {
+		kind = short
+	}
+	return fmt.Sprintf("%s/%s", kind, w.Name)
+}
+
+// OwnerReferencesGetter returns the owner references of the object of the
+// given kind (e.g. ReplicaSet), namespace and name. It returns no references
+// for the kinds it doesn't look up.
+type OwnerReferencesGetter func(kind, namespace, name string) ([]metav1.OwnerReference, error)
+
+// GetPodOwner returns the workload owning a pod, walking up the owner
+// references of its owners with getOwnerReferences, e.g. to the Deployment of
+// a pod's ReplicaSet. The walk stops at the last owner found if the references
+// of an owner can't be retrieved.
+func GetPodOwner(pod *v1.Pod, getOwnerReferences OwnerReferencesGetter) Workload {
+	owner := Workload{Kind: Pod, Namespace: pod.Namespace, Name: pod.Name}
+
+	ref := ownerReference(pod.GetOwnerReferences())
+	for ref != nil {
+		owner.Kind = strings.ToLower(ref.Kind)
+		owner.Name = ref.Name
+
+		refs, err := getOwnerReferences(ref.Kind, pod.Namespace, ref.Name)
+		if err != nil {
+			break
+		}
+		ref = ownerReference(refs)
+	}
+
+	return owner
+}
+
+// GetPodOwners returns the distinct workloads owning pods, sorted by
+// namespace, kind and name.
+func GetPodOwners(pods []v1.Pod, getOwnerReferences OwnerReferencesGetter) []Workload {
+	seen := make(map[Workload]struct{})
+	owners := []Workload{}
+	for i := range pods {
+		owner := GetPodOwner(&pods[i], getOwnerReferences)
+		if _, ok := seen[owner]; ok {
+			continue
+		}
+		seen[owner] = struct{}{}
+		owners = append(owners, owner)
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Namespace != owners[j].Namespace {
+			return owners[i].Namespace < owners[j].Namespace
+		}
+		if owners[i].Kind != owners[j].Kind {
+			return owners[i].Kind < owners[j].Kind
+		}
+		return owners[i].Name < owners[j].Name
+	})
+	return owners
+}
+
+// SplitMeshedPods returns the pods injected with a proxy of the control plane
+// in controllerNS, and the other pods.
+func SplitMeshedPods(pods []v1.Pod, controllerNS string) ([]v1.Pod, []v1.Pod) {
+	meshed := []v1.Pod{}
+	unmeshed := []v1.Pod{}
+	for _, pod := range pods {
+		if IsMeshed(&pod, controllerNS) {
+			meshed = append(meshed, pod)
+		} else {
+			unmeshed = append(unmeshed, pod)
+		}
+	}
+	return meshed, unmeshed
+}
+
+// ownerReference returns the reference to the controller of an object, or its
+// only owner if none of its owners is marked as its controller.
+func ownerReference(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) == 1 {
+		return &refs[0]
+	}
+	return nil
+}
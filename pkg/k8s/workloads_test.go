@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ownedPod(name string, labels map[string]string, refs ...metav1.OwnerReference) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "emojivoto",
+			Labels:          labels,
+			OwnerReferences: refs,
+		},
+	}
+}
+
+func TestGetPodOwner(t *testing.T) {
+	controller := true
+	owners := map[string][]metav1.OwnerReference{
+		"ReplicaSet/web-5f79f964bc": {{Kind: "Deployment", Name: "web", Controller: &controller}},
+		"Job/backup-1550000000":     {{Kind: "CronJob", Name: "backup", Controller: &controller}},
+	}
+	getOwnerReferences := func(kind, namespace, name string) ([]metav1.OwnerReference, error) {
+		if kind == "ReplicaSet" && name == "missing" {
+			return nil, errors.New("not found")
+		}
+		return owners[kind+"/"+name], nil
+	}
+
+	expectations := []struct {
+		description string
+		pod         v1.Pod
+		expected    Workload
+	}{
+		{
+			"walks up to the deployment of a replicaset",
+			ownedPod("web-5f79f964bc-d5jvf", nil, metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5f79f964bc"}),
+			Workload{Kind: Deployment, Namespace: "emojivoto", Name: "web"},
+		},
+		{
+			"walks up to the cronjob of a job",
+			ownedPod("backup-1550000000-bxtnq", nil, metav1.OwnerReference{Kind: "Job", Name: "backup-1550000000"}),
+			Workload{Kind: "cronjob", Namespace: "emojivoto", Name: "backup"},
+		},
+		{
+			"stops at the owner whose references can't be retrieved",
+			ownedPod("missing-98dbz", nil, metav1.OwnerReference{Kind: "ReplicaSet", Name: "missing"}),
+			Workload{Kind: "replicaset", Namespace: "emojivoto", Name: "missing"},
+		},
+		{
+			"follows the controller among several owners",
+			ownedPod("db-0", nil,
+				metav1.OwnerReference{Kind: "ConfigMap", Name: "db-config"},
+				metav1.OwnerReference{Kind: "StatefulSet", Name: "db", Controller: &controller},
+			),
+			Workload{Kind: StatefulSet, Namespace: "emojivoto", Name: "db"},
+		},
+		{
+			"returns the pod itself if it has no owner",
+			ownedPod("vote-bot", nil),
+			Workload{Kind: Pod, Namespace: "emojivoto", Name: "vote-bot"},
+		},
+	}
+
+	for _, exp := range expectations {
+		t.Run(exp.description, func(t *testing.T) {
+			owner := GetPodOwner(&exp.pod, getOwnerReferences)
+			if owner != exp.expected {
+				t.Fatalf("Expected owner %+v, got %+v", exp.expected, owner)
+			}
+		})
+	}
+
+	t.Run("returns the distinct owners of pods", func(t *testing.T) {
+		pods := []v1.Pod{
+			ownedPod("web-5f79f964bc-d5jvf", nil, metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5f79f964bc"}),
+			ownedPod("web-5f79f964bc-xk2jq", nil, metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5f79f964bc"}),
+			ownedPod("vote-bot", nil),
+		}
+
+		owners := GetPodOwners(pods, getOwnerReferences)
+		expected := []Workload{
+			{Kind: Deployment, Namespace: "emojivoto", Name: "web"},
+			{Kind: Pod, Namespace: "emojivoto", Name: "vote-bot"},
+		}
+		if !reflect.DeepEqual(owners, expected) {
+			t.Fatalf("Expected owners %+v, got %+v", expected, owners)
+		}
+		if owners[0].String() != "deploy/web" {
+			t.Fatalf("Expected owner to be described as [deploy/web], got [%s]", owners[0])
+		}
+	})
+}
+
+func TestSplitMeshedPods(t *testing.T) {
+	pods := []v1.Pod{
+		ownedPod("web", map[string]string{ControllerNSLabel: "linkerd"}),
+		ownedPod("voting", map[string]string{ControllerNSLabel: "linkerd-other"}),
+		ownedPod("vote-bot", nil),
+	}
+
+	meshed, unmeshed := SplitMeshedPods(pods, "linkerd")
+	if len(meshed) != 1 || meshed[0].Name != "web" {
+		t.Fatalf("Expected only the web pod to be meshed, got %v", meshed)
+	}
+	if len(unmeshed) != 2 || unmeshed[0].Name != "voting" || unmeshed[1].Name != "vote-bot" {
+		t.Fatalf("Expected the voting and vote-bot pods to be unmeshed, got %v", unmeshed)
+	}
+}
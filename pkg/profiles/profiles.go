@@ -0,0 +1,358 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	httpPb "github.com/linkerd/linkerd2-proxy-api/go/http_types"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+)
+
+const (
+	// DefaultDNSZone is the DNS zone of the names of ServiceProfiles when the
+	// zone of the cluster isn't configured.
+	DefaultDNSZone = "cluster.local"
+
+	// DefaultRetryRatio, DefaultMinRetriesPerSecond and DefaultRetryTTL make up
+	// the retry budget of the services whose ServiceProfile doesn't set one.
+	DefaultRetryRatio          = 0.2
+	DefaultMinRetriesPerSecond = 10
+	DefaultRetryTTL            = 10 * time.Second
+
+	// routeLabel is the metric label of the requests of a route. The proxies
+	// prefix it with "rt_".
+	routeLabel = "route"
+
+	// minStatus and maxStatus bound the statuses of the responses.
+	minStatus = 100
+	maxStatus = 599
+)
+
+// ProfileName returns the name of the ServiceProfile of a service, which is the
+// fully-qualified domain name of the service in the DNS zone.
+func ProfileName(service, namespace, dnsZone string) string {
+	if dnsZone == "" {
+		dnsZone = DefaultDNSZone
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", service, namespace, dnsZone)
+}
+
+// Validate returns an error if the YAML configuration isn't a valid
+// ServiceProfile.
+func Validate(config []byte) error {
+	var profile sp.ServiceProfile
+	if err := yaml.Unmarshal(config, &profile); err != nil {
+		return fmt.Errorf("failed to parse ServiceProfile: %s", err)
+	}
+
+	if profile.Kind != "ServiceProfile" {
+		return fmt.Errorf("expected a ServiceProfile, got kind %q", profile.Kind)
+	}
+	if profile.Name == "" {
+		return errors.New("ServiceProfile must have a name")
+	}
+
+	_, err := ToServiceProfile(&profile.Spec)
+	return err
+}
+
+// ToServiceProfile returns the DestinationProfile sent to the proxies for the
+// specification of a ServiceProfile.
+func ToServiceProfile(profile *sp.ServiceProfileSpec) (*pb.DestinationProfile, error) {
+	routes := make([]*pb.Route, 0, len(profile.Routes))
+	names := make(map[string]struct{})
+
+	for _, route := range profile.Routes {
+		pbRoute, err := ToRoute(route)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[route.Name]; ok {
+			return nil, fmt.Errorf("duplicate route name %q", route.Name)
+		}
+		names[route.Name] = struct{}{}
+		routes = append(routes, pbRoute)
+	}
+
+	budget, err := ToRetryBudget(profile.RetryBudget)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DestinationProfile{Routes: routes, RetryBudget: budget}, nil
+}
+
+// ToRetryBudget returns the proxy API retry budget of a ServiceProfile, or the
+// default budget if the profile doesn't set one.
+func ToRetryBudget(budget *sp.RetryBudget) (*pb.RetryBudget, error) {
+	if budget == nil {
+		return &pb.RetryBudget{
+			RetryRatio:          DefaultRetryRatio,
+			MinRetriesPerSecond: DefaultMinRetriesPerSecond,
+			Ttl:                 ptypes.DurationProto(DefaultRetryTTL),
+		}, nil
+	}
+
+	if budget.RetryRatio < 0 {
+		return nil, fmt.Errorf("invalid retry budget: negative retry ratio %v", budget.RetryRatio)
+	}
+	ttl, err := time.ParseDuration(budget.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry budget: invalid TTL %q: %s", budget.TTL, err)
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid retry budget: the TTL %q isn't positive", budget.TTL)
+	}
+
+	return &pb.RetryBudget{
+		RetryRatio:          budget.RetryRatio,
+		MinRetriesPerSecond: budget.MinRetriesPerSecond,
+		Ttl:                 ptypes.DurationProto(ttl),
+	}, nil
+}
+
+// ToRoute returns the proxy API route of a ServiceProfile route.
+func ToRoute(route *sp.RouteSpec) (*pb.Route, error) {
+	if route == nil {
+		return nil, errors.New("missing route")
+	}
+	if route.Name == "" {
+		return nil, errors.New("routes must have a name")
+	}
+
+	condition, err := ToRequestMatch(route.Condition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition of route %q: %s", route.Name, err)
+	}
+
+	classes := make([]*pb.ResponseClass, 0, len(route.ResponseClasses))
+	for _, class := range route.ResponseClasses {
+		if class == nil {
+			return nil, fmt.Errorf("missing response class in route %q", route.Name)
+		}
+		match, err := ToResponseMatch(class.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response class of route %q: %s", route.Name, err)
+		}
+		classes = append(classes, &pb.ResponseClass{
+			Condition: match,
+			IsFailure: class.IsFailure,
+		})
+	}
+
+	pbRoute := &pb.Route{
+		Condition:       condition,
+		ResponseClasses: classes,
+		MetricsLabels:   map[string]string{routeLabel: route.Name},
+		IsRetryable:     route.IsRetryable,
+	}
+
+	if route.Timeout != "" {
+		timeout, err := time.ParseDuration(route.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of route %q: %s", route.Name, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout of route %q: %q isn't positive", route.Name, route.Timeout)
+		}
+		pbRoute.Timeout = ptypes.DurationProto(timeout)
+	}
+
+	return pbRoute, nil
+}
+
+// ToRequestMatch returns the proxy API request match of a ServiceProfile
+// request match. Matches with several conditions set match requests meeting
+// all of them.
+func ToRequestMatch(match *sp.RequestMatch) (*pb.RequestMatch, error) {
+	if match == nil {
+		return nil, errors.New("missing request match")
+	}
+
+	matches := make([]*pb.RequestMatch, 0)
+
+	if match.All != nil {
+		all, err := toRequestMatches(match.All)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.RequestMatch{
+			Match: &pb.RequestMatch_All{All: &pb.RequestMatch_Seq{Matches: all}},
+		})
+	}
+
+	if match.Any != nil {
+		any, err := toRequestMatches(match.Any)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.RequestMatch{
+			Match: &pb.RequestMatch_Any{Any: &pb.RequestMatch_Seq{Matches: any}},
+		})
+	}
+
+	if match.Not != nil {
+		not, err := ToRequestMatch(match.Not)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.RequestMatch{
+			Match: &pb.RequestMatch_Not{Not: not},
+		})
+	}
+
+	if match.PathRegex != "" {
+		// the proxies match the regex against the whole path
+		if _, err := regexp.Compile(match.PathRegex); err != nil {
+			return nil, fmt.Errorf("invalid path regex %q: %s", match.PathRegex, err)
+		}
+		matches = append(matches, &pb.RequestMatch{
+			Match: &pb.RequestMatch_Path{Path: &pb.PathMatch{Regex: match.PathRegex}},
+		})
+	}
+
+	if match.Method != "" {
+		matches = append(matches, &pb.RequestMatch{
+			Match: &pb.RequestMatch_Method{Method: toMethod(match.Method)},
+		})
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("request matches must have a condition")
+	case 1:
+		return matches[0], nil
+	default:
+		return &pb.RequestMatch{
+			Match: &pb.RequestMatch_All{All: &pb.RequestMatch_Seq{Matches: matches}},
+		}, nil
+	}
+}
+
+func toRequestMatches(matches []*sp.RequestMatch) ([]*pb.RequestMatch, error) {
+	pbMatches := make([]*pb.RequestMatch, 0, len(matches))
+	for _, match := range matches {
+		pbMatch, err := ToRequestMatch(match)
+		if err != nil {
+			return nil, err
+		}
+		pbMatches = append(pbMatches, pbMatch)
+	}
+	return pbMatches, nil
+}
+
+// ToResponseMatch returns the proxy API response match of a ServiceProfile
+// response match. Matches with several conditions set match responses meeting
+// all of them.
+func ToResponseMatch(match *sp.ResponseMatch) (*pb.ResponseMatch, error) {
+	if match == nil {
+		return nil, errors.New("missing response match")
+	}
+
+	matches := make([]*pb.ResponseMatch, 0)
+
+	if match.All != nil {
+		all, err := toResponseMatches(match.All)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_All{All: &pb.ResponseMatch_Seq{Matches: all}},
+		})
+	}
+
+	if match.Any != nil {
+		any, err := toResponseMatches(match.Any)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_Any{Any: &pb.ResponseMatch_Seq{Matches: any}},
+		})
+	}
+
+	if match.Not != nil {
+		not, err := ToResponseMatch(match.Not)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_Not{Not: not},
+		})
+	}
+
+	if match.Status != nil {
+		status, err := toStatusRange(match.Status)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_Status{Status: status},
+		})
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("response matches must have a condition")
+	case 1:
+		return matches[0], nil
+	default:
+		return &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_All{All: &pb.ResponseMatch_Seq{Matches: matches}},
+		}, nil
+	}
+}
+
+func toResponseMatches(matches []*sp.ResponseMatch) ([]*pb.ResponseMatch, error) {
+	pbMatches := make([]*pb.ResponseMatch, 0, len(matches))
+	for _, match := range matches {
+		pbMatch, err := ToResponseMatch(match)
+		if err != nil {
+			return nil, err
+		}
+		pbMatches = append(pbMatches, pbMatch)
+	}
+	return pbMatches, nil
+}
+
+// toStatusRange returns the range of statuses, whose missing bounds are
+// unbounded, e.g. a range without a maximum matches every status from its
+// minimum to 599.
+func toStatusRange(status *sp.Range) (*pb.HttpStatusRange, error) {
+	min, max := status.Min, status.Max
+	if min == 0 {
+		min = minStatus
+	}
+	if max == 0 {
+		max = maxStatus
+	}
+
+	if min < minStatus || max > maxStatus {
+		return nil, fmt.Errorf("invalid status range %d-%d: statuses must be between %d and %d", min, max, minStatus, maxStatus)
+	}
+	if min > max {
+		return nil, fmt.Errorf("invalid status range %d-%d: the minimum is greater than the maximum", min, max)
+	}
+	return &pb.HttpStatusRange{Min: min, Max: max}, nil
+}
+
+func toMethod(method string) *httpPb.HttpMethod {
+	if value, ok := httpPb.HttpMethod_Registered_value[strings.ToUpper(method)]; ok {
+		return &httpPb.HttpMethod{
+			Type: &httpPb.HttpMethod_Registered_{
+				Registered: httpPb.HttpMethod_Registered(value),
+			},
+		}
+	}
+	return &httpPb.HttpMethod{
+		Type: &httpPb.HttpMethod_Unregistered{
+			Unregistered: strings.ToUpper(method),
+		},
+	}
+}
@@ -0,0 +1,260 @@
+package profiles
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	httpPb "github.com/linkerd/linkerd2-proxy-api/go/http_types"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+)
+
+func TestProfileName(t *testing.T) {
+	if name := ProfileName("web", "emojivoto", ""); name != "web.emojivoto.svc.cluster.local" {
+		t.Fatalf("Unexpected name: %s", name)
+	}
+	if name := ProfileName("web", "emojivoto", "example.com"); name != "web.emojivoto.svc.example.com" {
+		t.Fatalf("Unexpected name: %s", name)
+	}
+}
+
+func TestToRoute(t *testing.T) {
+	t.Run("Converts routes with several conditions", func(t *testing.T) {
+		route := &sp.RouteSpec{
+			Name: "GET /books/{id}",
+			Condition: &sp.RequestMatch{
+				PathRegex: `/books/\d+`,
+				Method:    "get",
+			},
+			ResponseClasses: []*sp.ResponseClass{
+				{
+					Condition: &sp.ResponseMatch{
+						Not: &sp.ResponseMatch{Status: &sp.Range{Min: 200, Max: 299}},
+					},
+					IsFailure: true,
+				},
+			},
+		}
+
+		expected := &pb.Route{
+			Condition: &pb.RequestMatch{
+				Match: &pb.RequestMatch_All{
+					All: &pb.RequestMatch_Seq{
+						Matches: []*pb.RequestMatch{
+							{
+								Match: &pb.RequestMatch_Path{Path: &pb.PathMatch{Regex: `/books/\d+`}},
+							},
+							{
+								Match: &pb.RequestMatch_Method{
+									Method: &httpPb.HttpMethod{
+										Type: &httpPb.HttpMethod_Registered_{Registered: httpPb.HttpMethod_GET},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			ResponseClasses: []*pb.ResponseClass{
+				{
+					Condition: &pb.ResponseMatch{
+						Match: &pb.ResponseMatch_Not{
+							Not: &pb.ResponseMatch{
+								Match: &pb.ResponseMatch_Status{
+									Status: &pb.HttpStatusRange{Min: 200, Max: 299},
+								},
+							},
+						},
+					},
+					IsFailure: true,
+				},
+			},
+			MetricsLabels: map[string]string{"route": "GET /books/{id}"},
+		}
+
+		actual, err := ToRoute(route)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected route %+v, got %+v", expected, actual)
+		}
+	})
+
+	t.Run("Converts the retries and timeouts of routes", func(t *testing.T) {
+		route := &sp.RouteSpec{
+			Name:        "GET /books",
+			Condition:   &sp.RequestMatch{Method: "GET"},
+			IsRetryable: true,
+			Timeout:     "300ms",
+		}
+
+		actual, err := ToRoute(route)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !actual.IsRetryable {
+			t.Fatal("Expected the route to be retryable")
+		}
+		if !reflect.DeepEqual(actual.Timeout, ptypes.DurationProto(300*time.Millisecond)) {
+			t.Fatalf("Unexpected timeout: %v", actual.Timeout)
+		}
+	})
+
+	t.Run("Converts status ranges without a maximum", func(t *testing.T) {
+		actual, err := ToResponseMatch(&sp.ResponseMatch{Status: &sp.Range{Min: 500}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := &pb.ResponseMatch{
+			Match: &pb.ResponseMatch_Status{Status: &pb.HttpStatusRange{Min: 500, Max: 599}},
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Expected match %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("Rejects invalid routes", func(t *testing.T) {
+		expectations := map[string]*sp.RouteSpec{
+			"routes must have a name": {
+				Condition: &sp.RequestMatch{Method: "GET"},
+			},
+			`invalid condition of route "empty": request matches must have a condition`: {
+				Name:      "empty",
+				Condition: &sp.RequestMatch{},
+			},
+			"invalid condition of route \"regex\": invalid path regex \"/books/(\": error parsing regexp: missing closing ): `/books/(`": {
+				Name:      "regex",
+				Condition: &sp.RequestMatch{PathRegex: "/books/("},
+			},
+			`invalid response class of route "status": invalid status range 599-500: the minimum is greater than the maximum`: {
+				Name:      "status",
+				Condition: &sp.RequestMatch{Method: "GET"},
+				ResponseClasses: []*sp.ResponseClass{
+					{Condition: &sp.ResponseMatch{Status: &sp.Range{Min: 599, Max: 500}}},
+				},
+			},
+			`invalid response class of route "range": invalid status range 100-600: statuses must be between 100 and 599`: {
+				Name:      "range",
+				Condition: &sp.RequestMatch{Method: "GET"},
+				ResponseClasses: []*sp.ResponseClass{
+					{Condition: &sp.ResponseMatch{Status: &sp.Range{Max: 600}}},
+				},
+			},
+			`invalid timeout of route "negative": "-1s" isn't positive`: {
+				Name:      "negative",
+				Condition: &sp.RequestMatch{Method: "GET"},
+				Timeout:   "-1s",
+			},
+		}
+
+		for msg, route := range expectations {
+			_, err := ToRoute(route)
+			if err == nil || err.Error() != msg {
+				t.Fatalf("Expected error [%s], got [%v]", msg, err)
+			}
+		}
+	})
+}
+
+func TestToRetryBudget(t *testing.T) {
+	t.Run("Uses the default budget", func(t *testing.T) {
+		budget, err := ToRetryBudget(nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := &pb.RetryBudget{
+			RetryRatio:          0.2,
+			MinRetriesPerSecond: 10,
+			Ttl:                 ptypes.DurationProto(10 * time.Second),
+		}
+		if !reflect.DeepEqual(budget, expected) {
+			t.Fatalf("Expected budget %v, got %v", expected, budget)
+		}
+	})
+
+	t.Run("Converts budgets", func(t *testing.T) {
+		budget, err := ToRetryBudget(&sp.RetryBudget{RetryRatio: 0.5, MinRetriesPerSecond: 2, TTL: "1m"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := &pb.RetryBudget{
+			RetryRatio:          0.5,
+			MinRetriesPerSecond: 2,
+			Ttl:                 ptypes.DurationProto(time.Minute),
+		}
+		if !reflect.DeepEqual(budget, expected) {
+			t.Fatalf("Expected budget %v, got %v", expected, budget)
+		}
+	})
+
+	t.Run("Rejects invalid budgets", func(t *testing.T) {
+		expectations := map[string]*sp.RetryBudget{
+			"invalid retry budget: negative retry ratio -0.1":   {RetryRatio: -0.1, TTL: "10s"},
+			`invalid retry budget: the TTL "0s" isn't positive`: {RetryRatio: 0.2, TTL: "0s"},
+		}
+
+		for msg, budget := range expectations {
+			_, err := ToRetryBudget(budget)
+			if err == nil || err.Error() != msg {
+				t.Fatalf("Expected error [%s], got [%v]", msg, err)
+			}
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("Accepts valid ServiceProfiles", func(t *testing.T) {
+		err := Validate([]byte(`
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+  namespace: booksapp
+spec:
+  routes:
+  - name: GET /books
+    condition:
+      pathRegex: /books
+      method: GET`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Rejects duplicate route names", func(t *testing.T) {
+		err := Validate([]byte(`
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.booksapp.svc.cluster.local
+  namespace: booksapp
+spec:
+  routes:
+  - name: books
+    condition:
+      method: GET
+  - name: books
+    condition:
+      method: POST`))
+		expected := `duplicate route name "books"`
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Rejects other kinds", func(t *testing.T) {
+		err := Validate([]byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: books`))
+		expected := `expected a ServiceProfile, got kind "Service"`
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package profiles
+
+import (
+	"io"
+	"text/template"
+)
+
+// Template is the template of the ServiceProfile of a service, documenting
+// the fields of ServiceProfiles.
+const Template = `### ServiceProfile for {{.ServiceName}}.{{.ServiceNamespace}} ###
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: {{.ProfileName}}
+  namespace: {{.ServiceNamespace}}
+spec:
+  # A service profile defines a list of routes. Linkerd aggregates metrics like
+  # request volume, latency, and success rate by route.
+  routes:
+  - name: '/authors/{id}'
+
+    # Each route must define a condition. The requests that match the condition
+    # belong to the route. If a request matches more than one route, the first
+    # match wins.
+    condition:
+      # The simplest condition is a regular expression matching the whole path.
+      pathRegex: '/authors/\d+'
+
+      # This condition checks the method of the request.
+      method: POST
+
+      # If more than one condition field is set, all of them must be met. This
+      # is equivalent to using the 'all' condition:
+      # all:
+      # - pathRegex: '/authors/\d+'
+      # - method: POST
+
+      # Conditions can be combined using 'all', 'any', and 'not'.
+      # any:
+      # - all:
+      #   - method: POST
+      #   - pathRegex: '/authors/\d+'
+      # - all:
+      #   - not:
+      #       method: DELETE
+      #   - pathRegex: /info.txt
+
+    # A route may define a list of response classes, which classify the
+    # responses of the route as successes or failures. Responses that don't
+    # match any response class are failures if their status is 5XX.
+    responseClasses:
+    - condition:
+        # Responses are matched on their status. Response conditions can also
+        # be combined using 'all', 'any', and 'not'.
+        status:
+          min: 500
+          max: 599
+      isFailure: true
+
+    # A route may be marked as retryable, so that the proxies retry its failed
+    # requests. Only mark routes as retryable if their requests are idempotent.
+    # isRetryable: true
+
+    # A route may define a timeout, after which the proxies fail its requests,
+    # including their retries. The proxies apply their default timeout if it's
+    # not set.
+    # timeout: 300ms
+
+  # The retries of the retryable routes are limited by the retry budget of the
+  # service, so that they don't overload a failing service. The budget below is
+  # the default one.
+  # retryBudget:
+  #   # The maximum ratio of retries to original requests.
+  #   retryRatio: 0.2
+  #   # The number of retries allowed per second regardless of the ratio.
+  #   minRetriesPerSecond: 10
+  #   # The window over which the ratio is computed.
+  #   ttl: 10s
+`
+
+type templateConfig struct {
+	ServiceName      string
+	ServiceNamespace string
+	ProfileName      string
+}
+
+// RenderTemplate writes the template of the ServiceProfile of a service to w.
+func RenderTemplate(service, namespace string, w io.Writer) error {
+	tmpl, err := template.New("profile").Parse(Template)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, templateConfig{
+		ServiceName:      service,
+		ServiceNamespace: namespace,
+		ProfileName:      ProfileName(service, namespace, ""),
+	})
+}
@@ -0,0 +1,53 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	healthCheckDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "healthcheck_duration_seconds",
+			Help:    "A histogram of the time taken to run the health checks of a category, by category.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		},
+		[]string{"category"},
+	)
+
+	healthCheckLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "healthcheck_last_success_timestamp_seconds",
+			Help: "The Unix time at which all the health checks of a category last passed, by category.",
+		},
+		[]string{"category"},
+	)
+
+	healthCheckConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "healthcheck_consecutive_failures",
+			Help: "The number of consecutive runs of the health checks of a category in which a check failed, by category. It's reset when they all pass.",
+		},
+		[]string{"category"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(healthCheckDuration, healthCheckLastSuccess, healthCheckConsecutiveFailures)
+}
+
+// ObserveHealthCheck records a run of the health checks of a category, which
+// started at the given time, so that alerts can be built on the health of the
+// mesh as checked by the control plane.
+func ObserveHealthCheck(category string, start time.Time, success bool) {
+	now := time.Now()
+	healthCheckDuration.WithLabelValues(category).Observe(now.Sub(start).Seconds())
+
+	if success {
+		healthCheckLastSuccess.WithLabelValues(category).Set(float64(now.UnixNano()) / 1e9)
+		healthCheckConsecutiveFailures.WithLabelValues(category).Set(0)
+	} else {
+		healthCheckConsecutiveFailures.WithLabelValues(category).Inc()
+	}
+}
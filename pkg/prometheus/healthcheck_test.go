@@ -0,0 +1,30 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObserveHealthCheck(t *testing.T) {
+	category := "test-category"
+	start := time.Now()
+
+	ObserveHealthCheck(category, start, false)
+	ObserveHealthCheck(category, start, false)
+
+	if failures := metricValue(t, healthCheckConsecutiveFailures.WithLabelValues(category)); failures != 2 {
+		t.Fatalf("Expected 2 consecutive failures, got %v", failures)
+	}
+	if lastSuccess := metricValue(t, healthCheckLastSuccess.WithLabelValues(category)); lastSuccess != 0 {
+		t.Fatalf("Expected no successful run, got one at %v", lastSuccess)
+	}
+
+	ObserveHealthCheck(category, start, true)
+
+	if failures := metricValue(t, healthCheckConsecutiveFailures.WithLabelValues(category)); failures != 0 {
+		t.Fatalf("Expected the consecutive failures to be reset, got %v", failures)
+	}
+	if lastSuccess := metricValue(t, healthCheckLastSuccess.WithLabelValues(category)); lastSuccess < float64(start.Unix()) {
+		t.Fatalf("Expected a successful run after %d, got one at %v", start.Unix(), lastSuccess)
+	}
+}
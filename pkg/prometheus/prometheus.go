@@ -1,7 +1,11 @@
 package prometheus
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
@@ -9,14 +13,17 @@ import (
 	"google.golang.org/grpc"
 )
 
-// returns a grpc server pre-configured with prometheus interceptors
-func NewGrpcServer() *grpc.Server {
-	server := grpc.NewServer(
+// returns a grpc server pre-configured with prometheus interceptors, and the
+// given options
+func NewGrpcServer(opt ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append([]grpc.ServerOption{
 		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
 		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-	)
+	}, opt...)...)
 
-	grpc_prometheus.EnableHandlingTimeHistogram()
+	grpc_prometheus.EnableHandlingTimeHistogram(
+		grpc_prometheus.WithHistogramBuckets(RequestDurationBucketsSeconds),
+	)
 	grpc_prometheus.Register(server)
 	return server
 }
@@ -29,6 +36,59 @@ var RequestDurationBucketsSeconds = append(append(append(append(
 	prometheus.LinearBuckets(10, 10, 5)...),
 )
 
+// LatencyBucketsMs are the upper bounds, in milliseconds, of the latency
+// histogram buckets configured with the -latency-buckets flag, or nil if the
+// default buckets are used.
+var LatencyBucketsMs []float64
+
+// SetLatencyBuckets configures the upper bounds, in milliseconds, of the
+// latency histogram buckets. They replace RequestDurationBucketsSeconds, so
+// this must be called before the histograms of the process are created.
+func SetLatencyBuckets(bucketsMs []float64) {
+	LatencyBucketsMs = bucketsMs
+	RequestDurationBucketsSeconds = make([]float64, len(bucketsMs))
+	for i, ms := range bucketsMs {
+		RequestDurationBucketsSeconds[i] = ms / 1000
+	}
+}
+
+// ParseLatencyBuckets parses comma-separated upper bounds of latency buckets,
+// in milliseconds, which must be positive and increasing.
+func ParseLatencyBuckets(value string) ([]float64, error) {
+	buckets := []float64{}
+	for _, field := range strings.Split(value, ",") {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latency bucket %q: not a number of milliseconds", field)
+		}
+		if bucket <= 0 {
+			return nil, fmt.Errorf("invalid latency bucket %q: buckets must be positive", field)
+		}
+		if len(buckets) > 0 && bucket <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("invalid latency bucket %q: buckets must be increasing", field)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// LatencyBucketMatcher returns a regular expression matching the "le" label
+// of the configured latency buckets, and of the +Inf bucket, with which
+// latency quantiles are computed from these buckets only. It returns an empty
+// string if the default buckets are used.
+func LatencyBucketMatcher() string {
+	if len(LatencyBucketsMs) == 0 {
+		return ""
+	}
+
+	bounds := make([]string, 0, len(LatencyBucketsMs)+1)
+	for _, bucket := range LatencyBucketsMs {
+		bounds = append(bounds, regexp.QuoteMeta(strconv.FormatFloat(bucket, 'f', -1, 64)))
+	}
+	bounds = append(bounds, regexp.QuoteMeta("+Inf"))
+	return strings.Join(bounds, "|")
+}
+
 // define response size buckets (bytes)
 var ResponseSizeBuckets = append(append(append(append(
 	prometheus.LinearBuckets(100, 100, 5),
@@ -0,0 +1,45 @@
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLatencyBuckets(t *testing.T) {
+	buckets, err := ParseLatencyBuckets("0.5, 1,10,1000")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []float64{0.5, 1, 10, 1000}
+	if !reflect.DeepEqual(buckets, expected) {
+		t.Fatalf("Expected buckets %v, got %v", expected, buckets)
+	}
+
+	for _, value := range []string{"", "1,ms", "-1,1", "0", "10,10", "10,5"} {
+		if _, err := ParseLatencyBuckets(value); err == nil {
+			t.Fatalf("Expected an error for [%s], got none", value)
+		}
+	}
+}
+
+func TestSetLatencyBuckets(t *testing.T) {
+	defaultBuckets := RequestDurationBucketsSeconds
+	defer func() {
+		LatencyBucketsMs = nil
+		RequestDurationBucketsSeconds = defaultBuckets
+	}()
+
+	if matcher := LatencyBucketMatcher(); matcher != "" {
+		t.Fatalf("Expected no matcher for the default buckets, got %s", matcher)
+	}
+
+	SetLatencyBuckets([]float64{0.5, 250, 5000})
+
+	expected := []float64{0.0005, 0.25, 5}
+	if !reflect.DeepEqual(RequestDurationBucketsSeconds, expected) {
+		t.Fatalf("Expected the buckets in seconds %v, got %v", expected, RequestDurationBucketsSeconds)
+	}
+	if matcher := LatencyBucketMatcher(); matcher != `0\.5|250|5000|\+Inf` {
+		t.Fatalf("Unexpected matcher: %s", matcher)
+	}
+}
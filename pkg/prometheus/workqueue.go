@@ -0,0 +1,82 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	workqueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "workqueue_depth",
+			Help: "The number of items waiting in a workqueue, by the name of the queue.",
+		},
+		[]string{"name"},
+	)
+
+	workqueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workqueue_adds_total",
+			Help: "A counter of the items added to a workqueue, by the name of the queue.",
+		},
+		[]string{"name"},
+	)
+
+	workqueueLatency = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "workqueue_queue_latency_microseconds",
+			Help: "How long the items of a workqueue wait before they're processed, by the name of the queue.",
+		},
+		[]string{"name"},
+	)
+
+	workqueueWorkDuration = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "workqueue_work_duration_microseconds",
+			Help: "How long the processing of the items of a workqueue takes, by the name of the queue.",
+		},
+		[]string{"name"},
+	)
+
+	workqueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workqueue_retries_total",
+			Help: "A counter of the items of a rate-limited workqueue that were requeued after failing, by the name of the queue.",
+		},
+		[]string{"name"},
+	)
+)
+
+// The metrics of the client-go workqueues of the controllers are exported by
+// every process of the control plane that imports this package, along with
+// its process, gRPC server and HTTP handler metrics. The provider has to be
+// set before the first queue is created, hence in init.
+func init() {
+	prometheus.MustRegister(workqueueDepth, workqueueAdds, workqueueLatency, workqueueWorkDuration, workqueueRetries)
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// workqueueMetricsProvider implements workqueue.MetricsProvider with the
+// workqueue metric vectors, labeled with the names of the queues. Unnamed
+// queues report to the metrics of an empty name.
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
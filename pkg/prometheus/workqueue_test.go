@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetrics(t *testing.T) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test-queue")
+	defer queue.ShutDown()
+
+	queue.Add("a")
+	queue.Add("b")
+
+	if depth := metricValue(t, workqueueDepth.WithLabelValues("test-queue")); depth != 2 {
+		t.Fatalf("Expected a depth of 2, got %v", depth)
+	}
+	if adds := metricValue(t, workqueueAdds.WithLabelValues("test-queue")); adds != 2 {
+		t.Fatalf("Expected 2 adds, got %v", adds)
+	}
+
+	item, _ := queue.Get()
+	if depth := metricValue(t, workqueueDepth.WithLabelValues("test-queue")); depth != 1 {
+		t.Fatalf("Expected a depth of 1, got %v", depth)
+	}
+
+	queue.AddRateLimited(item)
+	queue.Done(item)
+	if retries := metricValue(t, workqueueRetries.WithLabelValues("test-queue")); retries != 1 {
+		t.Fatalf("Expected 1 retry, got %v", retries)
+	}
+}
+
+func metricValue(t *testing.T, metric prometheus.Metric) float64 {
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if m.Gauge != nil {
+		return m.Gauge.GetValue()
+	}
+	return m.Counter.GetValue()
+}
@@ -0,0 +1,191 @@
+// Package publicapi is a client of the public API of the Linkerd control
+// plane, for tools built on top of it like the linkerd CLI.
+//
+// The API is reached through the service proxy of the Kubernetes API server
+// configured in a kubeconfig file, or directly at the address of the public
+// API service, e.g. from within the cluster or through a port-forward. Its
+// messages are protocol buffers, posted over HTTP:
+//
+//	client, err := publicapi.NewClient(publicapi.Config{ControlPlaneNamespace: "linkerd"})
+//	if err != nil {
+//		return err
+//	}
+//	rsp, err := client.Stat(ctx, util.StatSummaryRequestParams{
+//		TimeWindow:   "1m",
+//		ResourceType: k8s.Deployment,
+//		Namespace:    "emojivoto",
+//	})
+package publicapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/linkerd/linkerd2/controller/api/util"
+	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// Config configures how a Client reaches the public API.
+type Config struct {
+	// ControlPlaneNamespace is the namespace in which Linkerd is installed.
+	ControlPlaneNamespace string
+
+	// KubeConfig is the path to the kubeconfig file; the default kubeconfig is
+	// used if it is empty.
+	KubeConfig string
+
+	// KubeContext is the name of the kubeconfig context to use; the current
+	// context is used if it is empty.
+	KubeContext string
+
+	// Impersonate and ImpersonateGroup are the user and groups impersonated for
+	// the requests to the Kubernetes API server.
+	Impersonate      string
+	ImpersonateGroup []string
+
+	// APIAddr is the host:port of the public API service, if it is reached
+	// directly rather than through the Kubernetes API server.
+	APIAddr string
+
+	// Authorization is the Authorization header of the requests, such as
+	// "Bearer <token>", for control planes whose public API authenticates its
+	// callers.
+	Authorization string
+}
+
+// Client makes requests to the public API.
+type Client struct {
+	api           pb.ApiClient
+	authorization string
+}
+
+// NewAPIClient returns a client of the public API at apiAddr, or reached
+// through the Kubernetes API server of kubeAPI if apiAddr is empty.
+func NewAPIClient(controlPlaneNamespace, apiAddr string, kubeAPI *k8s.KubernetesAPI) (pb.ApiClient, error) {
+	if apiAddr != "" {
+		return public.NewInternalClient(controlPlaneNamespace, apiAddr)
+	}
+	return public.NewExternalClient(controlPlaneNamespace, kubeAPI)
+}
+
+// NewClient returns a client of the public API configured by config. When the
+// API is reached through the Kubernetes API server, the tap requests are made
+// through the tap API if it is registered, so that they are authorized
+// against the caller's RBAC permissions.
+func NewClient(config Config) (*Client, error) {
+	if config.APIAddr != "" {
+		api, err := NewAPIClient(config.ControlPlaneNamespace, config.APIAddr, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &Client{api: api, authorization: config.Authorization}, nil
+	}
+
+	kubeAPI, err := k8s.NewAPI(config.KubeConfig, config.KubeContext, config.Impersonate, config.ImpersonateGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := NewAPIClient(config.ControlPlaneNamespace, "", kubeAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err = public.NewTapAPIClient(api, kubeAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{api: api, authorization: config.Authorization}, nil
+}
+
+// NewClientFromAPI returns a Client making its requests with api, such as a
+// client built with NewAPIClient, or a mock in tests.
+func NewClientFromAPI(api pb.ApiClient) *Client {
+	return &Client{api: api}
+}
+
+// API returns the underlying client of the public API, for the requests that
+// Client has no method for.
+func (c *Client) API() pb.ApiClient {
+	return c.api
+}
+
+// Stat returns the traffic stats of the resources selected by params.
+func (c *Client) Stat(ctx context.Context, params util.StatSummaryRequestParams) (*pb.StatSummaryResponse, error) {
+	req, err := util.BuildStatSummaryRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := c.api.StatSummary(c.withAuthorization(ctx), req)
+	if err != nil {
+		return nil, err
+	}
+	if e := rsp.GetError(); e != nil {
+		return nil, fmt.Errorf("StatSummary API response error: %v", e.Error)
+	}
+
+	return rsp, nil
+}
+
+// Tap calls handle with every event of the requests selected by params, until
+// ctx is done, the stream of events ends, or handle returns an error, which is
+// returned by Tap.
+func (c *Client) Tap(ctx context.Context, params util.TapRequestParams, handle func(*pb.TapEvent) error) error {
+	req, err := util.BuildTapByResourceRequest(params)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(c.withAuthorization(ctx))
+	defer cancel()
+
+	stream, err := c.api.TapByResource(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+}
+
+// SelfCheck returns the results of the checks that the control plane runs on
+// itself.
+func (c *Client) SelfCheck(ctx context.Context) (*healthcheckPb.SelfCheckResponse, error) {
+	return c.api.SelfCheck(c.withAuthorization(ctx), &healthcheckPb.SelfCheckRequest{})
+}
+
+// Version returns the version of the control plane.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	rsp, err := c.api.Version(c.withAuthorization(ctx), &pb.Empty{})
+	if err != nil {
+		return "", err
+	}
+	return rsp.GetReleaseVersion(), nil
+}
+
+func (c *Client) withAuthorization(ctx context.Context) context.Context {
+	if c.authorization == "" {
+		return ctx
+	}
+	return public.WithAuthorization(ctx, c.authorization)
+}
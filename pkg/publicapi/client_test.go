@@ -0,0 +1,105 @@
+package publicapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func TestStat(t *testing.T) {
+	params := util.StatSummaryRequestParams{
+		TimeWindow:   "1m",
+		ResourceType: k8s.Deployment,
+		Namespace:    "emojivoto",
+	}
+
+	t.Run("Returns the stats of the resources", func(t *testing.T) {
+		expected := &pb.StatSummaryResponse{
+			Response: &pb.StatSummaryResponse_Ok_{Ok: &pb.StatSummaryResponse_Ok{}},
+		}
+		client := NewClientFromAPI(&public.MockApiClient{StatSummaryResponseToReturn: expected})
+
+		rsp, err := client.Stat(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if rsp != expected {
+			t.Fatalf("Expected response %v, got %v", expected, rsp)
+		}
+	})
+
+	t.Run("Returns the error of the response", func(t *testing.T) {
+		client := NewClientFromAPI(&public.MockApiClient{
+			StatSummaryResponseToReturn: &pb.StatSummaryResponse{
+				Response: &pb.StatSummaryResponse_Error{Error: &pb.ResourceError{Error: "no such resource"}},
+			},
+		})
+
+		_, err := client.Stat(context.Background(), params)
+		if err == nil || err.Error() != "StatSummary API response error: no such resource" {
+			t.Fatalf("Expected the error of the response, got %v", err)
+		}
+	})
+
+	t.Run("Rejects invalid requests", func(t *testing.T) {
+		client := NewClientFromAPI(&public.MockApiClient{})
+
+		_, err := client.Stat(context.Background(), util.StatSummaryRequestParams{TimeWindow: "1m", ResourceType: "foo"})
+		if err == nil {
+			t.Fatalf("Expected an error for an invalid resource type")
+		}
+	})
+}
+
+func TestTap(t *testing.T) {
+	params := util.TapRequestParams{
+		Resource:  "deploy/web",
+		Namespace: "emojivoto",
+	}
+	events := []pb.TapEvent{
+		{ProxyDirection: pb.TapEvent_INBOUND},
+		{ProxyDirection: pb.TapEvent_OUTBOUND},
+	}
+
+	t.Run("Handles the events until the end of the stream", func(t *testing.T) {
+		client := NewClientFromAPI(&public.MockApiClient{
+			Api_TapByResourceClientToReturn: &public.MockApi_TapByResourceClient{TapEventsToReturn: events},
+		})
+
+		handled := []pb.TapEvent_ProxyDirection{}
+		err := client.Tap(context.Background(), params, func(event *pb.TapEvent) error {
+			handled = append(handled, event.GetProxyDirection())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(handled) != 2 || handled[0] != pb.TapEvent_INBOUND || handled[1] != pb.TapEvent_OUTBOUND {
+			t.Fatalf("Expected the events to be handled in order, got %v", handled)
+		}
+	})
+
+	t.Run("Stops at the first error of the handler", func(t *testing.T) {
+		client := NewClientFromAPI(&public.MockApiClient{
+			Api_TapByResourceClientToReturn: &public.MockApi_TapByResourceClient{TapEventsToReturn: events},
+		})
+
+		handled := 0
+		expected := errors.New("stop")
+		err := client.Tap(context.Background(), params, func(event *pb.TapEvent) error {
+			handled++
+			return expected
+		})
+		if err != expected {
+			t.Fatalf("Expected the error of the handler, got %v", err)
+		}
+		if handled != 1 {
+			t.Fatalf("Expected 1 event to be handled, got %d", handled)
+		}
+	})
+}
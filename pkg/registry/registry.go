@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// dockerHubRegistry is the registry of the images whose name doesn't start
+	// with the host of a registry, e.g. prom/prometheus.
+	dockerHubRegistry = "registry-1.docker.io"
+
+	// manifestMediaTypes are the media types of the image manifests and of the
+	// manifest lists of multi-arch images.
+	manifestMediaTypes = "application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json, " +
+		"application/vnd.oci.image.index.v1+json"
+)
+
+// challengeParam matches the parameters of the WWW-Authenticate challenges of
+// registries, e.g. realm="https://auth.docker.io/token".
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Reference identifies an image pinned by its digest in a registry.
+type Reference struct {
+	Registry   string
+	Repository string
+	Digest     string
+}
+
+// ParseReference returns the reference of an image pinned by its digest, such
+// as registry.example.com/linkerd/proxy:stable-2.1.0@sha256:... The tag, if
+// any, is ignored, as the digest identifies the image.
+func ParseReference(image string) (Reference, error) {
+	at := strings.Index(image, "@")
+	if at < 0 {
+		return Reference{}, fmt.Errorf("%s isn't pinned by a digest", image)
+	}
+	name, digest := image[:at], image[at+1:]
+	if tag := strings.LastIndex(name, ":"); tag > strings.LastIndex(name, "/") {
+		name = name[:tag]
+	}
+
+	ref := Reference{Registry: dockerHubRegistry, Repository: name, Digest: digest}
+	parts := strings.SplitN(name, "/", 2)
+	switch {
+	case len(parts) == 1:
+		ref.Repository = "library/" + name
+	case strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost":
+		ref.Registry, ref.Repository = parts[0], parts[1]
+	}
+	return ref, nil
+}
+
+// Client checks that images can be pulled from their registries, through the
+// Docker Registry HTTP API V2.
+type Client struct {
+	HTTPClient *http.Client
+
+	// Auths are the base64-encoded user:password credentials of registries,
+	// by host.
+	Auths map[string]string
+}
+
+// NewClient returns a client that authenticates to registries with the
+// credentials of the Docker config file, if any. Credential helpers aren't
+// supported, so the registries that require them are accessed anonymously.
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Auths:      readDockerAuths(),
+	}
+}
+
+// CheckPullable returns an error if the manifest of an image can't be fetched
+// from its registry, e.g. because the registry can't be reached, requires
+// credentials that aren't configured, or doesn't have the image.
+func (c *Client) CheckPullable(ref Reference) error {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, ref.Digest)
+	rsp, err := c.request("HEAD", manifestURL, "")
+	if err != nil {
+		return err
+	}
+
+	if rsp.StatusCode == http.StatusUnauthorized {
+		authorization, err := c.authorize(ref.Registry, rsp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return err
+		}
+		rsp, err = c.request("HEAD", manifestURL, authorization)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s doesn't have the image", ref.Registry)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s denies pulling the image: %s", ref.Registry, rsp.Status)
+	default:
+		return fmt.Errorf("%s responded with %s", ref.Registry, rsp.Status)
+	}
+}
+
+// request sends a request without reading its response's body, which is
+// closed.
+func (c *Client) request(method, target, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", manifestMediaTypes)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rsp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	rsp.Body.Close()
+	return rsp, nil
+}
+
+// authorize returns the Authorization header that answers the challenge of a
+// registry: the credentials of the registry for Basic challenges, or a token
+// issued by the realm of Bearer challenges, anonymously unless the registry
+// has credentials.
+func (c *Client) authorize(registry, challenge string) (string, error) {
+	scheme := strings.SplitN(challenge, " ", 2)[0]
+	credentials := c.Auths[registry]
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if credentials == "" {
+			return "", fmt.Errorf("%s requires credentials, which aren't configured", registry)
+		}
+		return "Basic " + credentials, nil
+	case "bearer":
+		params := map[string]string{}
+		for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+			params[match[1]] = match[2]
+		}
+		token, err := c.fetchToken(params, credentials)
+		if err != nil {
+			return "", fmt.Errorf("failed to authenticate to %s: %s", registry, err)
+		}
+		return "Bearer " + token, nil
+	}
+	return "", fmt.Errorf("%s requires an unsupported authentication scheme: %s", registry, challenge)
+}
+
+// fetchToken returns a token issued by the realm of a Bearer challenge for
+// its service and scope.
+func (c *Client) fetchToken(params map[string]string, credentials string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid realm: %s", params["realm"])
+	}
+	query := realm.Query()
+	for _, name := range []string{"service", "scope"} {
+		if params[name] != "" {
+			query.Set(name, params[name])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Basic "+credentials)
+	}
+	rsp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s responded with %s", realm.Host, rsp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("%s didn't issue a token", realm.Host)
+}
+
+// readDockerAuths returns the credentials of the registries recorded in the
+// Docker config file, by host, or none if it can't be read.
+func readDockerAuths() map[string]string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".docker")
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil
+	}
+
+	auths := map[string]string{}
+	for server, auth := range config.Auths {
+		// the servers are recorded as hosts or URLs, and Docker Hub as the
+		// URL of its index
+		host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+		host = strings.SplitN(host, "/", 2)[0]
+		if host == "index.docker.io" {
+			host = dockerHubRegistry
+		}
+		auths[host] = auth.Auth
+	}
+	return auths
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const digest = "sha256:a0f9e1fdd2d0a0a1e0e8d7b6c8f2d4e3a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1"
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		image    string
+		expected Reference
+	}{
+		{
+			"registry.example.com/linkerd/proxy:stable-2.1.0@" + digest,
+			Reference{"registry.example.com", "linkerd/proxy", digest},
+		},
+		{
+			"registry.example.com:5000/linkerd/proxy@" + digest,
+			Reference{"registry.example.com:5000", "linkerd/proxy", digest},
+		},
+		{
+			"localhost/proxy:stable-2.1.0@" + digest,
+			Reference{"localhost", "proxy", digest},
+		},
+		{
+			"prom/prometheus:v2.4.0@" + digest,
+			Reference{"registry-1.docker.io", "prom/prometheus", digest},
+		},
+		{
+			"busybox@" + digest,
+			Reference{"registry-1.docker.io", "library/busybox", digest},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			ref, err := ParseReference(tc.image)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(ref, tc.expected) {
+				t.Fatalf("Expected %+v, got %+v", tc.expected, ref)
+			}
+		})
+	}
+
+	t.Run("Rejects images that aren't pinned by a digest", func(t *testing.T) {
+		if _, err := ParseReference("registry.example.com/linkerd/proxy:stable-2.1.0"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestCheckPullable(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:linkerd/proxy:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "hunter2"}`)
+		case "/v2/linkerd/proxy/manifests/" + digest:
+			if r.Header.Get("Authorization") != "Bearer hunter2" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:linkerd/proxy:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/v2/linkerd/controller/manifests/" + digest:
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	host := serverURL.Host
+	client := &Client{HTTPClient: server.Client()}
+
+	t.Run("Returns nil if the image can be pulled with a token", func(t *testing.T) {
+		if err := client.CheckPullable(Reference{host, "linkerd/proxy", digest}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Returns an error if the registry doesn't have the image", func(t *testing.T) {
+		err := client.CheckPullable(Reference{host, "linkerd/web", digest})
+		expected := host + " doesn't have the image"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns an error if the registry requires credentials that aren't configured", func(t *testing.T) {
+		err := client.CheckPullable(Reference{host, "linkerd/controller", digest})
+		expected := host + " requires credentials, which aren't configured"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns an error if the registry rejects the credentials", func(t *testing.T) {
+		client := &Client{HTTPClient: server.Client(), Auths: map[string]string{host: "dXNlcjpwYXNzd29yZA=="}}
+		err := client.CheckPullable(Reference{host, "linkerd/controller", digest})
+		expected := host + " denies pulling the image: 401 Unauthorized"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -29,6 +29,10 @@ type FirewallConfiguration struct {
 	ProxyOutgoingPort      int
 	ProxyUid               int
 	SimulateOnly           bool
+	// NetNs is the path of the network namespace whose iptables are
+	// configured, e.g. by the CNI plugin. The current network namespace is
+	// configured when it's empty.
+	NetNs string
 }
 
 //ConfigureFirewall configures a pod's internal iptables to redirect all desired traffic through the proxy, allowing for
@@ -144,6 +148,10 @@ func addRulesForIgnoredPorts(portsToIgnore []int, chainName string, commands []*
 }
 
 func executeCommand(firewallConfiguration FirewallConfiguration, cmd *exec.Cmd) error {
+	if firewallConfiguration.NetNs != "" {
+		args := append([]string{"--net=" + firewallConfiguration.NetNs, "--"}, cmd.Args...)
+		cmd = exec.Command("nsenter", args...)
+	}
 
 	log.Printf("> %s", strings.Trim(fmt.Sprintf("%v", cmd.Args), "[]"))
 
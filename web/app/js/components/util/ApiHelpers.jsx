@@ -51,7 +51,7 @@ export const apiErrorPropType = PropTypes.shape({
   error: PropTypes.string
 });
 
-const ApiHelpers = (pathPrefix, defaultMetricsWindow = '1m') => {
+const ApiHelpers = (pathPrefix, grafanaUrl = '', defaultMetricsWindow = '1m') => {
   let metricsWindow = defaultMetricsWindow;
   const podsPath = `/api/pods`;
 
@@ -117,7 +117,8 @@ const ApiHelpers = (pathPrefix, defaultMetricsWindow = '1m') => {
     });
   };
 
-  // prefix all links in the app with `pathPrefix`
+  // prefix all links in the app with `pathPrefix`, except the links to an
+  // external Grafana, which are prefixed with `grafanaUrl`
   class PrefixedLink extends React.Component {
     static defaultProps = {
       deployment: "",
@@ -132,6 +133,16 @@ const ApiHelpers = (pathPrefix, defaultMetricsWindow = '1m') => {
     }
 
     render() {
+      if (this.props.deployment === "grafana" && !_.isEmpty(grafanaUrl)) {
+        return (
+          <a
+            href={`${grafanaUrl}${this.props.to}`}
+            {...(this.props.targetBlank ? {target:'_blank'} : {})}>
+            {this.props.children}
+          </a>
+        );
+      }
+
       let prefix = pathPrefix;
       if (!_.isEmpty(this.props.deployment)) {
         prefix = prefix.replace("/web:", "/"+this.props.deployment+":");
@@ -21,15 +21,17 @@ import './../img/favicon.png'; // needs to be referenced somewhere so webpack bu
 let appMain = document.getElementById('main');
 let appData = !appMain ? {} : appMain.dataset;
 
-let pathPrefix = "";
-let proxyPathMatch = window.location.pathname.match(/\/api\/v1\/namespaces\/.*\/proxy/g);
-if (proxyPathMatch) {
-  pathPrefix = proxyPathMatch[0];
-}
+// the path under which the dashboard is served, without its trailing slash,
+// e.g. when running the dashboard via `linkerd dashboard` or behind an ingress
+let pathPrefix = (appData.pathPrefix || "").replace(/\/$/, "");
+
+// the URL of the Grafana server outside of the control plane, if any, that
+// the Grafana dashboards are linked to
+let grafanaUrl = (appData.grafanaUrl || "").replace(/\/$/, "");
 
 const context = {
   ...appData,
-  api: ApiHelpers(pathPrefix),
+  api: ApiHelpers(pathPrefix, grafanaUrl),
   pathPrefix: pathPrefix,
   productName: "Linkerd"
 };
@@ -102,6 +102,16 @@ describe('ApiHelpers', () => {
       expect(prefixedLink.html()).to.contain(linkProps.children[0]);
     });
 
+    it('links to an external Grafana', () => {
+      api = ApiHelpers('/my/path/prefix/web:/foo', 'https://grafana.example.com');
+      let linkProps = { deployment: "grafana", to: "/dashboard/db/linkerd-deployment", children: ["Informative Link Title"] };
+      let prefixedLink = mount(routerWrap(api.PrefixedLink, linkProps));
+
+      expect(prefixedLink.find("Link")).to.have.length(0);
+      expect(prefixedLink.html()).to.contain('href="https://grafana.example.com/dashboard/db/linkerd-deployment"');
+      expect(prefixedLink.html()).to.contain(linkProps.children[0]);
+    });
+
     it('sets target=blank', () => {
       api = ApiHelpers('/my/path/prefix');
       let linkProps = { targetBlank: true, to: "/myrelpath", children: ["Informative Link Title"] };
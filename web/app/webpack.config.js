@@ -2,14 +2,38 @@
 
 const path = require('path');
 
+const production = process.env.NODE_ENV === 'production';
+
+// ManifestPlugin writes dist/manifest.json, which maps the name of the bundle
+// to its content-hashed file name, so that the web server can reference it
+// and cache it forever.
+class ManifestPlugin {
+  apply(compiler) {
+    compiler.hooks.emit.tap('ManifestPlugin', compilation => {
+      let manifest = {};
+      compilation.chunks.forEach(chunk => {
+        chunk.files.filter(file => file.endsWith('.js')).forEach(file => {
+          manifest['index_bundle.js'] = file;
+        });
+      });
+      let json = JSON.stringify(manifest, null, 2);
+      compilation.assets['manifest.json'] = {
+        source: () => json,
+        size: () => json.length
+      };
+    });
+  }
+}
+
 module.exports = {
-  mode: process.env.NODE_ENV === 'production' ? 'production' : 'development',
+  mode: production ? 'production' : 'development',
   entry: './js/index.js',
   output: {
     path: path.resolve(__dirname, 'dist'),
     publicPath: 'dist/',
-    filename: 'index_bundle.js'
+    filename: production ? 'index_bundle.[contenthash].js' : 'index_bundle.js'
   },
+  plugins: [new ManifestPlugin()],
   devtool: 'cheap-module-source-map',
   externals: {
     cheerio: 'window',
@@ -1,8 +1,9 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"net"
 	"os"
 	"os/signal"
@@ -12,6 +13,7 @@ import (
 	"github.com/linkerd/linkerd2/controller/api/public"
 	"github.com/linkerd/linkerd2/pkg/admin"
 	"github.com/linkerd/linkerd2/pkg/flags"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/linkerd/linkerd2/web/srv"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,10 +27,36 @@ func main() {
 	uuid := flag.String("uuid", "", "unique linkerd install id")
 	reload := flag.Bool("reload", true, "reloading set to true or false")
 	webpackDevServer := flag.String("webpack-dev-server", "", "use webpack to serve static assets; frontend will use this instead of static-dir")
+	pathPrefix := flag.String("path-prefix", "", "path under which the dashboard is served, e.g. \"/linkerd\" behind an ingress")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
+	grafanaAddr := flag.String("grafana-addr", "", "host:port of the Grafana server checked by the /ready endpoint (defaults to the Grafana of the control plane)")
+	grafanaURL := flag.String("grafana-url", "", "URL of an external Grafana server that the dashboard links to and checks instead of the Grafana of the control plane")
+	tapAPI := flag.Bool("tap-api", false, "if true, tap through the tap API registered with the Kubernetes API server, as the web's service account")
+	tapMaxDuration := flag.Duration("tap-max-duration", 10*time.Minute, "maximum duration of the tap sessions of the dashboard")
+	authMode := flag.String("auth-mode", "", "how to authenticate the users of the dashboard: \"header\" trusts the identity header of an authenticating reverse proxy, \"token\" requires the tokens of -auth-token-file, which users exchange for a session on the login page; users aren't authenticated if empty")
+	authHeader := flag.String("auth-header", "X-Forwarded-User", "identity header trusted if -auth-mode=header")
+	authTokenFile := flag.String("auth-token-file", "", "CSV file of the \"token,user\" lines authenticated if -auth-mode=token")
+	authNamespacesFile := flag.String("auth-namespaces-file", "", "CSV file of the \"user,namespace\" lines allowing users to view namespaces, \"*\" standing for all namespaces; users may view every namespace if empty")
+	drainDelay := flag.Duration("drain-delay", 5*time.Second, "how long the process reports unready once it's told to stop, before its server stops accepting new connections")
+	drainPeriod := flag.Duration("drain-period", 20*time.Second, "how long the in-flight requests and websockets have to complete once the server stops accepting new connections")
+	enforcedHost := flag.String("enforced-host", "", "regexp of the additional hosts at which the dashboard is served, e.g. the host of an ingress; the requests for hosts other than localhost, IP addresses and the web service are rejected, to prevent DNS rebinding attacks")
 	flags.ConfigureAndParse()
 
-	_, _, err := net.SplitHostPort(*kubernetesApiHost) // Verify kubernetesApiHost is of the form host:port.
+	hostValidator, err := srv.NewHostValidator(*controllerNamespace, *enforcedHost)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	authenticator, authorizer, err := buildAuth(*authMode, *authHeader, *authTokenFile, *authNamespacesFile)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if *grafanaAddr == "" {
+		*grafanaAddr = fmt.Sprintf("grafana.%s.svc.cluster.local:3000", *controllerNamespace)
+	}
+
+	_, _, err = net.SplitHostPort(*kubernetesApiHost) // Verify kubernetesApiHost is of the form host:port.
 	if err != nil {
 		log.Fatalf("failed to parse API server address: %s", *kubernetesApiHost)
 	}
@@ -37,22 +65,78 @@ func main() {
 		log.Fatalf("failed to construct client for API server URL %s", *kubernetesApiHost)
 	}
 
+	if *tapAPI {
+		kubeAPI, err := k8s.NewAPI("", "", "", []string{})
+		if err != nil {
+			log.Fatalf("failed to configure Kubernetes API client: %s", err)
+		}
+
+		client, err = public.NewTapAPIClient(client, kubeAPI)
+		if err != nil {
+			log.Fatalf("failed to construct client for the tap API: %s", err)
+		}
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	server := srv.NewServer(*addr, *templateDir, *staticDir, *uuid, *controllerNamespace, *webpackDevServer, *reload, client)
+	drainer := admin.NewDrainer(*drainDelay, *drainPeriod)
+	server := srv.NewServer(*addr, *templateDir, *staticDir, *uuid, *controllerNamespace, *webpackDevServer, *pathPrefix, *grafanaAddr, *grafanaURL, *reload, *tapMaxDuration, authenticator, authorizer, hostValidator, drainer, client)
 
 	go func() {
 		log.Infof("starting HTTP server on %+v", *addr)
 		server.ListenAndServe()
 	}()
 
-	go admin.StartServer(*metricsAddr, nil)
+	go admin.StartDrainableServer(*metricsAddr, nil, drainer.Draining())
 
 	<-stop
+	drainer.Start()
 
 	log.Infof("shutting down HTTP server on %+v", *addr)
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	server.Shutdown(ctx)
+	drainer.DrainHTTP(server)
+}
+
+func buildAuth(mode, header, tokenFile, namespacesFile string) (srv.Authenticator, *srv.NamespaceAuthorizer, error) {
+	var authenticator srv.Authenticator
+	switch mode {
+	case "":
+	case "header":
+		authenticator = srv.NewHeaderAuthenticator(header)
+	case "token":
+		if tokenFile == "" {
+			return nil, nil, errors.New("-auth-token-file must be set if -auth-mode=token")
+		}
+		file, err := os.Open(tokenFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+
+		authenticator, err = srv.NewTokenAuthenticator(file)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid -auth-mode: %s", mode)
+	}
+
+	if namespacesFile == "" {
+		return authenticator, nil, nil
+	}
+	if authenticator == nil {
+		return nil, nil, errors.New("-auth-namespaces-file requires -auth-mode to be set")
+	}
+
+	file, err := os.Open(namespacesFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	authorizer, err := srv.NewNamespaceAuthorizer(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return authenticator, authorizer, nil
 }
@@ -2,13 +2,18 @@ package srv
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/gorilla/websocket"
 	"github.com/julienschmidt/httprouter"
 	"github.com/linkerd/linkerd2/controller/api/util"
@@ -21,6 +26,34 @@ type (
 	jsonError struct {
 		Error string `json:"error"`
 	}
+
+	// tapRequest is the first message sent by the dashboard over the tap
+	// websocket. It takes the same parameters as `linkerd tap`, with the
+	// duration formatted like the CLI flag (e.g. "30s").
+	tapRequest struct {
+		util.TapRequestParams
+		Duration string `json:"duration"`
+	}
+
+	// resourceDetail aggregates what the dashboard shows on the detail page of
+	// a resource. The protobuf messages are marshaled like renderJsonPb does.
+	resourceDetail struct {
+		Stats       json.RawMessage   `json:"stats"`
+		Upstreams   []json.RawMessage `json:"upstreams"`
+		Downstreams []json.RawMessage `json:"downstreams"`
+		EdgesError  string            `json:"edgesError,omitempty"`
+		Pods        []json.RawMessage `json:"pods"`
+	}
+)
+
+const (
+	// tapMaxRps is the maximum rps of a tap started from the dashboard, which
+	// is also the default rps of `linkerd tap`.
+	tapMaxRps = 100.0
+
+	// tapStopMessage is sent by the dashboard over the tap websocket to stop
+	// the tap session before its duration elapsed.
+	tapStopMessage = "stop"
 )
 
 var (
@@ -70,9 +103,24 @@ func (h *handler) handleApiVersion(w http.ResponseWriter, req *http.Request, p h
 }
 
 func (h *handler) handleApiPods(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
-	pods, err := h.apiClient.ListPods(req.Context(), &pb.ListPodsRequest{
-		Namespace: req.FormValue("namespace"),
-	})
+	listPodsReq := &pb.ListPodsRequest{
+		Namespace:     req.FormValue("namespace"),
+		ContinueToken: req.FormValue("continue"),
+	}
+	if limit := req.FormValue("limit"); limit != "" {
+		l, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil {
+			renderJsonError(w, err, http.StatusBadRequest)
+			return
+		}
+		listPodsReq.Limit = uint32(l)
+	}
+	if err := h.authorize(req, listPodsReq.Namespace); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	pods, err := h.apiClient.ListPods(req.Context(), listPodsReq)
 
 	if err != nil {
 		renderJsonError(w, err, http.StatusInternalServerError)
@@ -82,7 +130,28 @@ func (h *handler) handleApiPods(w http.ResponseWriter, req *http.Request, p http
 	renderJsonPb(w, pods)
 }
 
-func (h *handler) handleApiStat(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+func (h *handler) handleApiServices(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	namespace := req.FormValue("namespace")
+	if err := h.authorize(req, namespace); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	services, err := h.apiClient.ListServices(req.Context(), &pb.ListServicesRequest{
+		Namespace: namespace,
+	})
+
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	renderJsonPb(w, services)
+}
+
+// statSummaryRequest builds a StatSummaryRequest from the query parameters of
+// the dashboard's request.
+func statSummaryRequest(req *http.Request) (*pb.StatSummaryRequest, error) {
 	allNs := false
 	if req.FormValue("all_namespaces") == "true" {
 		allNs = true
@@ -106,11 +175,41 @@ func (h *handler) handleApiStat(w http.ResponseWriter, req *http.Request, p http
 		requestParams.ResourceType = defaultResourceType
 	}
 
-	statRequest, err := util.BuildStatSummaryRequest(requestParams)
+	return util.BuildStatSummaryRequest(requestParams)
+}
+
+// resourceNamespace returns the namespace of a resource, or the namespace
+// itself for namespace resources.
+func resourceNamespace(resource *pb.Resource) string {
+	if resource.GetType() == k8s.Namespace {
+		return resource.GetName()
+	}
+	return resource.GetNamespace()
+}
+
+// statSummaryNamespaces returns the namespaces of the resources of a
+// StatSummary request.
+func statSummaryNamespaces(req *pb.StatSummaryRequest) []string {
+	namespaces := []string{resourceNamespace(req.GetSelector().GetResource())}
+	if to := req.GetToResource(); to != nil {
+		namespaces = append(namespaces, resourceNamespace(to))
+	}
+	if from := req.GetFromResource(); from != nil {
+		namespaces = append(namespaces, resourceNamespace(from))
+	}
+	return namespaces
+}
+
+func (h *handler) handleApiStat(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	statRequest, err := statSummaryRequest(req)
 	if err != nil {
 		renderJsonError(w, err, http.StatusInternalServerError)
 		return
 	}
+	if err := h.authorize(req, statSummaryNamespaces(statRequest)...); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
 
 	result, err := h.apiClient.StatSummary(req.Context(), statRequest)
 	if err != nil {
@@ -120,12 +219,298 @@ func (h *handler) handleApiStat(w http.ResponseWriter, req *http.Request, p http
 	renderJsonPb(w, result)
 }
 
+// handleApiStatStream sends the stats of the request's query parameters over
+// a websocket every "interval", until the websocket is closed.
+func (h *handler) handleApiStatStream(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	statRequest, err := statSummaryRequest(req)
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authorize(req, statSummaryNamespaces(statRequest)...); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	ws, err := websocketUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer ws.Close()
+	defer h.trackStream(ws)()
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	stream, err := h.apiClient.StatSummaryStream(ctx, &pb.StatSummaryStreamRequest{
+		Request:  statRequest,
+		Interval: req.FormValue("interval"),
+	})
+	if err != nil {
+		websocketError(ws, websocket.CloseInternalServerErr, err.Error())
+		return
+	}
+
+	go func() {
+		for {
+			rsp, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					websocketError(ws, websocket.CloseInternalServerErr, err.Error())
+				}
+				break
+			}
+
+			buf := new(bytes.Buffer)
+			err = pbMarshaler.Marshal(buf, rsp)
+			if err != nil {
+				websocketError(ws, websocket.CloseInternalServerErr, err.Error())
+				break
+			}
+
+			if err := ws.WriteMessage(websocket.TextMessage, buf.Bytes()); err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure) {
+					log.Error(err)
+				}
+				break
+			}
+		}
+	}()
+
+	for {
+		_, _, err := ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure) {
+				log.Errorf("Unexpected close error: %s", err)
+			}
+			return
+		}
+	}
+}
+
+func (h *handler) handleApiTopRoutes(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	// the routes are those of the selected resource only, and can't be
+	// narrowed down to the requests sent to another resource, whose namespace
+	// would have to be authorized as well
+	for _, param := range []string{"to_name", "to_type", "to_namespace"} {
+		if req.FormValue(param) != "" {
+			renderJsonError(w, fmt.Errorf("%s isn't supported", param), http.StatusBadRequest)
+			return
+		}
+	}
+
+	requestParams := util.TopRoutesRequestParams{
+		TimeWindow:   req.FormValue("window"),
+		ResourceName: req.FormValue("resource_name"),
+		ResourceType: req.FormValue("resource_type"),
+		Namespace:    req.FormValue("namespace"),
+	}
+
+	topReq, err := util.BuildTopRoutesRequest(requestParams)
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authorize(req, resourceNamespace(topReq.GetSelector().GetResource())); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	result, err := h.apiClient.TopRoutes(req.Context(), topReq)
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+	renderJsonPb(w, result)
+}
+
+func (h *handler) handleApiEdges(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	resource, err := util.BuildResource(req.FormValue("namespace"), req.FormValue("resource_type"))
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authorize(req, resourceNamespace(&resource)); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	result, err := h.apiClient.Edges(req.Context(), &pb.EdgesRequest{
+		Selector: &pb.ResourceSelection{Resource: &resource},
+	})
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+	renderJsonPb(w, h.filterEdges(req, result))
+}
+
+// filterEdges returns the edges whose source and destination the user of the
+// request may view.
+func (h *handler) filterEdges(req *http.Request, edges *pb.EdgesResponse) *pb.EdgesResponse {
+	if h.authorizer == nil || edges.GetOk() == nil {
+		return edges
+	}
+
+	allowed := make([]*pb.Edge, 0)
+	for _, edge := range edges.GetOk().GetEdges() {
+		if h.allows(req, edge.GetSrc().GetNamespace()) && h.allows(req, edge.GetDst().GetNamespace()) {
+			allowed = append(allowed, edge)
+		}
+	}
+	return &pb.EdgesResponse{
+		Response: &pb.EdgesResponse_Ok_{Ok: &pb.EdgesResponse_Ok{Edges: allowed}},
+	}
+}
+
+// allowsTapEvent returns whether the user of the request may view the source
+// and destination of the tap event.
+func (h *handler) allowsTapEvent(req *http.Request, event *pb.TapEvent) bool {
+	return h.allows(req, event.GetSourceMeta().GetLabels()["namespace"]) &&
+		h.allows(req, event.GetDestinationMeta().GetLabels()["namespace"])
+}
+
+// handleApiResource returns the stats, edges and meshed pods of the resource
+// of the request's path, querying the public API concurrently.
+func (h *handler) handleApiResource(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	resource, err := util.BuildResource(p.ByName("namespace"), p.ByName("kind"), p.ByName("name"))
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authorize(req, resourceNamespace(&resource)); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	statRequest, err := util.BuildStatSummaryRequest(util.StatSummaryRequestParams{
+		TimeWindow:   req.FormValue("window"),
+		ResourceName: resource.Name,
+		ResourceType: resource.Type,
+		Namespace:    resource.Namespace,
+	})
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	podsRequest := &pb.ListPodsRequest{Namespace: resource.Namespace, Owner: &resource}
+	if resource.Type == k8s.Namespace {
+		podsRequest = &pb.ListPodsRequest{Namespace: resource.Name}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		stats    *pb.StatSummaryResponse
+		edges    *pb.EdgesResponse
+		pods     *pb.ListPodsResponse
+		statsErr error
+		edgesErr error
+		podsErr  error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		stats, statsErr = h.apiClient.StatSummary(req.Context(), statRequest)
+	}()
+	go func() {
+		defer wg.Done()
+		edges, edgesErr = h.apiClient.Edges(req.Context(), &pb.EdgesRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: resource.Namespace, Type: resource.Type},
+			},
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		pods, podsErr = h.apiClient.ListPods(req.Context(), podsRequest)
+	}()
+	wg.Wait()
+
+	for _, err := range []error{statsErr, edgesErr, podsErr} {
+		if err != nil {
+			renderJsonError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	detail, err := buildResourceDetail(resource, stats, h.filterEdges(req, edges), pods)
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+	renderJson(w, detail)
+}
+
+// buildResourceDetail keeps the edges from and to the resource, and its
+// meshed pods.
+func buildResourceDetail(resource pb.Resource, stats *pb.StatSummaryResponse, edges *pb.EdgesResponse, pods *pb.ListPodsResponse) (*resourceDetail, error) {
+	detail := &resourceDetail{
+		Upstreams:   make([]json.RawMessage, 0),
+		Downstreams: make([]json.RawMessage, 0),
+		Pods:        make([]json.RawMessage, 0),
+		EdgesError:  edges.GetError().GetError(),
+	}
+
+	var err error
+	if detail.Stats, err = marshalJsonPb(stats); err != nil {
+		return nil, err
+	}
+
+	for _, edge := range edges.GetOk().GetEdges() {
+		switch {
+		case isResource(edge.GetDst(), resource):
+			raw, err := marshalJsonPb(edge)
+			if err != nil {
+				return nil, err
+			}
+			detail.Upstreams = append(detail.Upstreams, raw)
+		case isResource(edge.GetSrc(), resource):
+			raw, err := marshalJsonPb(edge)
+			if err != nil {
+				return nil, err
+			}
+			detail.Downstreams = append(detail.Downstreams, raw)
+		}
+	}
+
+	for _, pod := range pods.GetPods() {
+		if !pod.GetAdded() {
+			continue
+		}
+		raw, err := marshalJsonPb(pod)
+		if err != nil {
+			return nil, err
+		}
+		detail.Pods = append(detail.Pods, raw)
+	}
+
+	return detail, nil
+}
+
+func isResource(r *pb.Resource, resource pb.Resource) bool {
+	return r.GetNamespace() == resource.Namespace && r.GetName() == resource.Name
+}
+
+func marshalJsonPb(msg proto.Message) (json.RawMessage, error) {
+	buf := new(bytes.Buffer)
+	if err := pbMarshaler.Marshal(buf, msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func websocketError(ws *websocket.Conn, wsError int, msg string) {
 	ws.WriteControl(websocket.CloseMessage,
 		websocket.FormatCloseMessage(wsError, msg),
 		time.Time{})
 }
 
+// handleApiTap runs a tap session over a websocket. The first message of the
+// dashboard is the tapRequest of the session, whose events are then sent over
+// the websocket until the tap's duration elapsed, the dashboard sends
+// tapStopMessage, or the websocket is closed.
 func (h *handler) handleApiTap(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	ws, err := websocketUpgrader.Upgrade(w, req, nil)
 	if err != nil {
@@ -133,6 +518,7 @@ func (h *handler) handleApiTap(w http.ResponseWriter, req *http.Request, p httpr
 		return
 	}
 	defer ws.Close()
+	defer h.trackStream(ws)()
 
 	messageType, message, err := ws.ReadMessage()
 	if err != nil {
@@ -145,20 +531,27 @@ func (h *handler) handleApiTap(w http.ResponseWriter, req *http.Request, p httpr
 		return
 	}
 
-	var requestParams util.TapRequestParams
-	err = json.Unmarshal(message, &requestParams)
+	tapReq, err := buildTapRequest(message, h.tapMaxDuration)
 	if err != nil {
-		websocketError(ws, websocket.CloseInternalServerErr, err.Error())
+		websocketError(ws, websocket.ClosePolicyViolation, err.Error())
+		return
+	}
+	if err := h.authorize(req, tapNamespaces(tapReq)...); err != nil {
+		websocketError(ws, websocket.ClosePolicyViolation, err.Error())
 		return
 	}
 
-	tapReq, err := util.BuildTapByResourceRequest(requestParams)
+	// the session ends when its duration elapsed, even if the tap server
+	// doesn't end the tap on its own
+	duration, err := ptypes.Duration(tapReq.GetDuration())
 	if err != nil {
-		websocketError(ws, websocket.CloseInternalServerErr, err.Error())
+		websocketError(ws, websocket.ClosePolicyViolation, err.Error())
 		return
 	}
+	ctx, cancel := context.WithTimeout(req.Context(), duration)
+	defer cancel()
 
-	tapClient, err := h.apiClient.TapByResource(req.Context(), tapReq)
+	tapClient, err := h.apiClient.TapByResource(ctx, tapReq)
 	if err != nil {
 		websocketError(ws, websocket.CloseInternalServerErr, err.Error())
 		return
@@ -168,13 +561,22 @@ func (h *handler) handleApiTap(w http.ResponseWriter, req *http.Request, p httpr
 	go func() {
 		for {
 			rsp, err := tapClient.Recv()
-			if err == io.EOF {
+			if err == io.EOF || ctx.Err() == context.DeadlineExceeded {
+				// the tap's duration elapsed
+				websocketError(ws, websocket.CloseNormalClosure, "tap finished")
+				break
+			}
+			if ctx.Err() != nil {
+				// the tap was stopped
 				break
 			}
 			if err != nil {
 				websocketError(ws, websocket.CloseInternalServerErr, err.Error())
 				break
 			}
+			if !h.allowsTapEvent(req, rsp) {
+				continue
+			}
 
 			buf := new(bytes.Buffer)
 			err = pbMarshaler.Marshal(buf, rsp)
@@ -193,12 +595,69 @@ func (h *handler) handleApiTap(w http.ResponseWriter, req *http.Request, p httpr
 	}()
 
 	for {
-		_, _, err := ws.ReadMessage()
+		messageType, message, err := ws.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure) {
 				log.Errorf("Unexpected close error: %s", err)
 			}
 			return
 		}
+
+		if messageType == websocket.TextMessage && string(message) == tapStopMessage {
+			cancel()
+			websocketError(ws, websocket.CloseNormalClosure, "tap stopped")
+			return
+		}
+	}
+}
+
+// buildTapRequest builds a TapByResourceRequest from the dashboard's request,
+// enforcing the same validation as `linkerd tap`. The rps of the tap is capped
+// at tapMaxRps, so that the dashboard can't overwhelm the tapped proxies, and
+// its duration at maxDuration, so that abandoned sessions don't run forever.
+func buildTapRequest(message []byte, maxDuration time.Duration) (*pb.TapByResourceRequest, error) {
+	var req tapRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return nil, err
+	}
+
+	params := req.TapRequestParams
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration [%s]: %s", req.Duration, err)
+		}
+		params.Duration = duration
+	}
+
+	if params.MaxRps < 0 {
+		return nil, fmt.Errorf("invalid max rps [%f]: must not be negative", params.MaxRps)
+	}
+	if params.MaxRps == 0 || params.MaxRps > tapMaxRps {
+		params.MaxRps = tapMaxRps
+	}
+
+	if params.Duration < 0 {
+		return nil, fmt.Errorf("invalid duration [%s]: must not be negative", params.Duration)
+	}
+	if params.Duration == 0 || params.Duration > maxDuration {
+		params.Duration = maxDuration
+	}
+
+	return util.BuildTapByResourceRequest(params)
+}
+
+// tapNamespaces returns the namespaces of the target and of the sources and
+// destinations matched by a tap request.
+func tapNamespaces(req *pb.TapByResourceRequest) []string {
+	namespaces := []string{resourceNamespace(req.GetTarget().GetResource())}
+	for _, match := range req.GetMatch().GetAll().GetMatches() {
+		if destinations := match.GetDestinations(); destinations != nil {
+			namespaces = append(namespaces, resourceNamespace(destinations.GetResource()))
+		}
+		if sources := match.GetSources(); sources != nil {
+			namespaces = append(namespaces, resourceNamespace(sources.GetResource()))
+		}
 	}
+	return namespaces
 }
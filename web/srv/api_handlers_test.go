@@ -1,11 +1,13 @@
 package srv
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/linkerd/linkerd2/controller/api/public"
@@ -53,3 +55,194 @@ func TestHandleApiVersion(t *testing.T) {
 		t.Errorf("Expected to find: %+v", expectedVersionJson)
 	}
 }
+
+func TestBuildTapRequest(t *testing.T) {
+	t.Run("Builds a request with the dashboard's filters", func(t *testing.T) {
+		req, err := buildTapRequest([]byte(`{"resource": "deploy/web", "namespace": "emojivoto", "toResource": "deploy/voting", "method": "GET", "maxRps": 10, "duration": "30s"}`), time.Minute)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if req.GetMaxRps() != 10 {
+			t.Fatalf("Expected max rps to be 10, got %f", req.GetMaxRps())
+		}
+		if req.GetDuration().GetSeconds() != 30 {
+			t.Fatalf("Expected a 30s duration, got %v", req.GetDuration())
+		}
+		if n := len(req.GetMatch().GetAll().GetMatches()); n != 2 {
+			t.Fatalf("Expected 2 matches, got %d", n)
+		}
+	})
+
+	t.Run("Caps the max rps", func(t *testing.T) {
+		for _, maxRps := range []string{"0", "1000"} {
+			req, err := buildTapRequest([]byte(`{"resource": "deploy/web", "maxRps": `+maxRps+`}`), time.Minute)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if req.GetMaxRps() != tapMaxRps {
+				t.Fatalf("Expected max rps %s to be capped at %f, got %f", maxRps, tapMaxRps, req.GetMaxRps())
+			}
+		}
+	})
+
+	t.Run("Caps the duration", func(t *testing.T) {
+		for _, duration := range []string{"", "1h"} {
+			req, err := buildTapRequest([]byte(`{"resource": "deploy/web", "duration": "`+duration+`"}`), time.Minute)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if req.GetDuration().GetSeconds() != 60 {
+				t.Fatalf("Expected duration [%s] to be capped at 1m, got %v", duration, req.GetDuration())
+			}
+		}
+	})
+
+	t.Run("Rejects invalid requests", func(t *testing.T) {
+		for _, message := range []string{
+			`not json`,
+			`{"resource": "deploy/web", "duration": "forever"}`,
+			`{"resource": "deploy/web", "maxRps": -1}`,
+			`{"resource": "deploy/web", "duration": "-30s"}`,
+			`{"resource": "svc/web"}`,
+			`{"resource": "deploy/web", "status": "9xx"}`,
+		} {
+			if _, err := buildTapRequest([]byte(message), time.Minute); err == nil {
+				t.Fatalf("Expected an error for %s, got none", message)
+			}
+		}
+	})
+}
+
+func TestHandleApiResource(t *testing.T) {
+	mockApiClient := &public.MockApiClient{
+		StatSummaryResponseToReturn: &pb.StatSummaryResponse{},
+		EdgesResponseToReturn: &pb.EdgesResponse{
+			Response: &pb.EdgesResponse_Ok_{
+				Ok: &pb.EdgesResponse_Ok{
+					Edges: []*pb.Edge{
+						&pb.Edge{
+							Src: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+							Dst: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "voting"},
+						},
+						&pb.Edge{
+							Src: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "vote-bot"},
+							Dst: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+						},
+						&pb.Edge{
+							Src: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+							Dst: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "emoji"},
+						},
+						&pb.Edge{
+							Src: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "vote-bot"},
+							Dst: &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "voting"},
+						},
+					},
+				},
+			},
+		},
+		ListPodsResponseToReturn: &pb.ListPodsResponse{
+			Pods: []*pb.Pod{
+				&pb.Pod{Name: "emojivoto/web-1", Added: true},
+				&pb.Pod{Name: "emojivoto/web-2", Added: false},
+			},
+		},
+	}
+	handler := &handler{apiClient: mockApiClient}
+
+	t.Run("Returns the stats, edges and meshed pods of the resource", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/resource/deploy/emojivoto/web", nil)
+		handler.handleApiResource(recorder, req, httprouter.Params{
+			{Key: "kind", Value: "deploy"},
+			{Key: "namespace", Value: "emojivoto"},
+			{Key: "name", Value: "web"},
+		})
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+
+		var detail resourceDetail
+		if err := json.Unmarshal(recorder.Body.Bytes(), &detail); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(detail.Upstreams) != 1 || !strings.Contains(string(detail.Upstreams[0]), "vote-bot") {
+			t.Fatalf("Unexpected upstreams: %s", detail.Upstreams)
+		}
+		if len(detail.Downstreams) != 2 {
+			t.Fatalf("Expected 2 downstreams, got: %s", detail.Downstreams)
+		}
+		if len(detail.Pods) != 1 || !strings.Contains(string(detail.Pods[0]), "emojivoto/web-1") {
+			t.Fatalf("Unexpected pods: %s", detail.Pods)
+		}
+	})
+
+	t.Run("Rejects unknown resource types", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/resource/foo/emojivoto/web", nil)
+		handler.handleApiResource(recorder, req, httprouter.Params{
+			{Key: "kind", Value: "foo"},
+			{Key: "namespace", Value: "emojivoto"},
+			{Key: "name", Value: "web"},
+		})
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+}
+
+func TestHandleApiPodsAuthorization(t *testing.T) {
+	authorizer, err := NewNamespaceAuthorizer(strings.NewReader("alice,emojivoto\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	handler := &handler{
+		apiClient:  &public.MockApiClient{ListPodsResponseToReturn: &pb.ListPodsResponse{}},
+		authorizer: authorizer,
+	}
+
+	for namespace, expected := range map[string]int{
+		"emojivoto": http.StatusOK,
+		"linkerd":   http.StatusForbidden,
+		"":          http.StatusForbidden,
+	} {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/pods?namespace="+namespace, nil)
+		req = req.WithContext(withUser(req.Context(), "alice"))
+		handler.handleApiPods(recorder, req, httprouter.Params{})
+
+		if recorder.Code != expected {
+			t.Fatalf("Expected status %d for namespace [%s], got %d", expected, namespace, recorder.Code)
+		}
+	}
+}
+
+func TestFilterEdges(t *testing.T) {
+	authorizer, err := NewNamespaceAuthorizer(strings.NewReader("alice,emojivoto\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	handler := &handler{authorizer: authorizer}
+
+	edge := func(src, dst string) *pb.Edge {
+		return &pb.Edge{
+			Src: &pb.Resource{Namespace: src, Type: "deployment", Name: "src"},
+			Dst: &pb.Resource{Namespace: dst, Type: "deployment", Name: "dst"},
+		}
+	}
+	allowed := edge("emojivoto", "emojivoto")
+	edges := &pb.EdgesResponse{
+		Response: &pb.EdgesResponse_Ok_{Ok: &pb.EdgesResponse_Ok{
+			Edges: []*pb.Edge{allowed, edge("emojivoto", "linkerd"), edge("linkerd", "emojivoto")},
+		}},
+	}
+
+	req := httptest.NewRequest("GET", "/api/edges?namespace=emojivoto", nil)
+	req = req.WithContext(withUser(req.Context(), "alice"))
+	filtered := handler.filterEdges(req, edges).GetOk().GetEdges()
+	if len(filtered) != 1 || filtered[0] != allowed {
+		t.Fatalf("Expected only the edges within emojivoto, got %v", filtered)
+	}
+}
@@ -0,0 +1,240 @@
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	bearerPrefix = "Bearer "
+
+	// sessionCookie holds the session of a user of a tokenAuthenticator, who
+	// exchanged their token for it on the login page, so that the browser
+	// authenticates the dashboard's requests, including its websockets, on its
+	// own.
+	sessionCookie = "linkerd-dashboard-session"
+	sessionTTL    = 12 * time.Hour
+
+	// allNamespaces grants a user of a NamespaceAuthorizer access to every
+	// namespace, which is required by the queries that aren't limited to a
+	// namespace.
+	allNamespaces = "*"
+)
+
+type (
+	// Authenticator authenticates the users of the dashboard.
+	Authenticator interface {
+		// Authenticate returns the user that sent the request.
+		Authenticate(req *http.Request) (string, error)
+	}
+
+	// headerAuthenticator trusts the user of the identity header set by a
+	// reverse proxy authenticating the dashboard's users, e.g. with OIDC. The
+	// dashboard must only be reachable through the proxy.
+	headerAuthenticator struct {
+		header string
+	}
+
+	// tokenAuthenticator authenticates the requests bearing one of its static
+	// tokens, or the cookie of a session that was opened with one of them.
+	tokenAuthenticator struct {
+		tokens []tokenUser
+
+		sync.Mutex
+		sessions map[string]session
+	}
+
+	tokenUser struct {
+		token []byte
+		user  string
+	}
+
+	session struct {
+		user    string
+		expires time.Time
+	}
+
+	// NamespaceAuthorizer restricts the namespaces each user of the dashboard
+	// may view.
+	NamespaceAuthorizer struct {
+		namespaces map[string]map[string]struct{}
+	}
+
+	userKey struct{}
+)
+
+// NewHeaderAuthenticator returns an Authenticator trusting the user of the
+// given request header, such as X-Forwarded-User.
+func NewHeaderAuthenticator(header string) Authenticator {
+	return &headerAuthenticator{header: header}
+}
+
+func (a *headerAuthenticator) Authenticate(req *http.Request) (string, error) {
+	user := req.Header.Get(a.header)
+	if user == "" {
+		return "", fmt.Errorf("requests must set the %s header", a.header)
+	}
+	return user, nil
+}
+
+// NewTokenAuthenticator returns an Authenticator of the requests bearing the
+// tokens of a CSV file of "token,user" lines.
+func NewTokenAuthenticator(r io.Reader) (Authenticator, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tokens: %s", err)
+	}
+
+	tokens := make([]tokenUser, len(records))
+	for i, record := range records {
+		tokens[i] = tokenUser{token: []byte(record[0]), user: record[1]}
+	}
+	return &tokenAuthenticator{tokens: tokens, sessions: make(map[string]session)}, nil
+}
+
+func (a *tokenAuthenticator) Authenticate(req *http.Request) (string, error) {
+	if cookie, err := req.Cookie(sessionCookie); err == nil {
+		if user, ok := a.sessionUser(cookie.Value); ok {
+			return user, nil
+		}
+	}
+
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", errors.New("requests must bear a token or the cookie of a session")
+	}
+	user, ok := a.tokenUser(strings.TrimPrefix(header, bearerPrefix))
+	if !ok {
+		return "", errors.New("the token isn't valid")
+	}
+	return user, nil
+}
+
+// tokenUser returns the user of the token. Every token is compared in
+// constant time, so that the time it takes doesn't tell how much of a token
+// was guessed right.
+func (a *tokenAuthenticator) tokenUser(token string) (string, bool) {
+	var user string
+	found := 0
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(t.token, []byte(token)) == 1 {
+			user = t.user
+			found = 1
+		}
+	}
+	return user, found == 1
+}
+
+// login opens a session for the user of the token, returning its id.
+func (a *tokenAuthenticator) login(token string) (string, time.Time, error) {
+	user, ok := a.tokenUser(token)
+	if !ok {
+		return "", time.Time{}, errors.New("the token isn't valid")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	id := hex.EncodeToString(b)
+	expires := time.Now().Add(sessionTTL)
+
+	a.Lock()
+	defer a.Unlock()
+	for id, s := range a.sessions {
+		if time.Now().After(s.expires) {
+			delete(a.sessions, id)
+		}
+	}
+	a.sessions[id] = session{user: user, expires: expires}
+	return id, expires, nil
+}
+
+// logout closes the session, if it's open.
+func (a *tokenAuthenticator) logout(id string) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.sessions, id)
+}
+
+func (a *tokenAuthenticator) sessionUser(id string) (string, bool) {
+	a.Lock()
+	defer a.Unlock()
+	s, ok := a.sessions[id]
+	if !ok || time.Now().After(s.expires) {
+		return "", false
+	}
+	return s.user, true
+}
+
+// NewNamespaceAuthorizer returns a NamespaceAuthorizer of a CSV file of
+// "user,namespace" lines, each allowing a user to view a namespace. Users
+// allowed to view "*" may view every namespace.
+func NewNamespaceAuthorizer(r io.Reader) (*NamespaceAuthorizer, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace authorizations: %s", err)
+	}
+
+	namespaces := make(map[string]map[string]struct{})
+	for _, record := range records {
+		user, namespace := record[0], record[1]
+		if namespaces[user] == nil {
+			namespaces[user] = make(map[string]struct{})
+		}
+		namespaces[user][namespace] = struct{}{}
+	}
+	return &NamespaceAuthorizer{namespaces: namespaces}, nil
+}
+
+// authorize returns an error unless the user may view all the namespaces. The
+// empty namespace stands for all namespaces. A nil NamespaceAuthorizer allows
+// everything.
+func (a *NamespaceAuthorizer) authorize(user string, namespaces ...string) error {
+	if a == nil {
+		return nil
+	}
+
+	allowed := a.namespaces[user]
+	if _, ok := allowed[allNamespaces]; ok {
+		return nil
+	}
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			return fmt.Errorf("%s is not allowed to view all namespaces", user)
+		}
+		if _, ok := allowed[namespace]; !ok {
+			return fmt.Errorf("%s is not allowed to view namespace %s", user, namespace)
+		}
+	}
+	return nil
+}
+
+func withUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// readCSV reads the records of two fields of a CSV file, skipping the lines
+// starting with "#".
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
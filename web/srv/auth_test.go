@@ -0,0 +1,149 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHeaderAuthenticator(t *testing.T) {
+	authenticator := NewHeaderAuthenticator("X-Forwarded-User")
+
+	t.Run("Trusts the user of the header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/pods", nil)
+		req.Header.Set("X-Forwarded-User", "alice")
+
+		user, err := authenticator.Authenticate(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user != "alice" {
+			t.Fatalf("Expected user alice, got %s", user)
+		}
+	})
+
+	t.Run("Rejects requests without the header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/pods", nil)
+
+		if _, err := authenticator.Authenticate(req); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	authenticator, err := NewTokenAuthenticator(strings.NewReader("# dashboard users\nsecret-1,alice\nsecret-2, bob\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Authenticates the bearers of the tokens", func(t *testing.T) {
+		for token, expected := range map[string]string{"secret-1": "alice", "secret-2": "bob"} {
+			req := httptest.NewRequest("GET", "/api/pods", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			user, err := authenticator.Authenticate(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if user != expected {
+				t.Fatalf("Expected user %s, got %s", expected, user)
+			}
+		}
+	})
+
+	t.Run("Rejects requests without a valid token", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer secret-3", "Basic secret-1"} {
+			req := httptest.NewRequest("GET", "/api/pods", nil)
+			req.Header.Set("Authorization", authorization)
+
+			if _, err := authenticator.Authenticate(req); err == nil {
+				t.Fatalf("Expected an error for [%s], got none", authorization)
+			}
+		}
+	})
+
+	t.Run("Authenticates the sessions opened with the tokens", func(t *testing.T) {
+		tokens := authenticator.(*tokenAuthenticator)
+		id, _, err := tokens.login("secret-2")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		req := httptest.NewRequest("GET", "/api/pods", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: id})
+		user, err := authenticator.Authenticate(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user != "bob" {
+			t.Fatalf("Expected user bob, got %s", user)
+		}
+
+		tokens.logout(id)
+		if _, err := authenticator.Authenticate(req); err == nil {
+			t.Fatal("Expected an error after logging out, got none")
+		}
+	})
+
+	t.Run("Rejects sessions that weren't opened with a valid token", func(t *testing.T) {
+		tokens := authenticator.(*tokenAuthenticator)
+		if _, _, err := tokens.login("secret-3"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+
+		req := httptest.NewRequest("GET", "/api/pods", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "secret-1"})
+		if _, err := authenticator.Authenticate(req); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Rejects invalid token files", func(t *testing.T) {
+		if _, err := NewTokenAuthenticator(strings.NewReader("secret-1\n")); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestNamespaceAuthorizer(t *testing.T) {
+	authorizer, err := NewNamespaceAuthorizer(strings.NewReader("alice,emojivoto\nalice,booksapp\nbob,*\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Allows users to view their namespaces", func(t *testing.T) {
+		if err := authorizer.authorize("alice", "emojivoto", "booksapp"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := authorizer.authorize("bob", "emojivoto", ""); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("Rejects views of other namespaces", func(t *testing.T) {
+		expected := "alice is not allowed to view namespace linkerd"
+		err := authorizer.authorize("alice", "emojivoto", "linkerd")
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		expected = "alice is not allowed to view all namespaces"
+		err = authorizer.authorize("alice", "")
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		if err := authorizer.authorize("carol", "emojivoto"); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+
+	t.Run("Allows everything without an authorizer", func(t *testing.T) {
+		var authorizer *NamespaceAuthorizer
+		if err := authorizer.authorize("carol", ""); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
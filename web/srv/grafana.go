@@ -0,0 +1,104 @@
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// serviceProxyRoute matches the paths of the Kubernetes API's service proxy,
+// which the Grafana of the control plane is served under.
+const serviceProxyRoute = "/api/v1/namespaces/:namespace/services/:service/proxy/*servicepath"
+
+// serviceProxyPath returns the path of a service of the control plane under
+// the Kubernetes API's service proxy, e.g. through `kubectl proxy`.
+func serviceProxyPath(namespace, service string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/services/%s:http/proxy", namespace, service)
+}
+
+// grafanaPath returns the path under which the Grafana of the control plane is
+// proxied. Grafana's root_url is the path of its service under the Kubernetes
+// API's service proxy, so the dashboard proxies it under the same path, for
+// Grafana to be served both through `kubectl proxy` and through the dashboard,
+// e.g. by the port-forward of `linkerd dashboard`.
+func grafanaPath(controllerNamespace string) string {
+	return serviceProxyPath(controllerNamespace, "grafana")
+}
+
+// newGrafanaProxy returns a reverse proxy to the Grafana server at addr.
+func newGrafanaProxy(addr string) *httputil.ReverseProxy {
+	target := &url.URL{Scheme: "http", Host: addr}
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.Host = target.Host
+			// the credentials of the dashboard's users aren't Grafana's
+			req.Header.Del("Authorization")
+			removeCookie(req, sessionCookie)
+		},
+	}
+}
+
+// handleServiceProxy serves the paths of the Kubernetes API's service proxy
+// that the dashboard mirrors: Grafana, and the version of the dashboard that the
+// version panel of Grafana's dashboards reads from the web service.
+func (h *handler) handleServiceProxy(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	if p.ByName("namespace") != h.controllerNamespace {
+		http.NotFound(w, req)
+		return
+	}
+	switch {
+	case p.ByName("service") == "grafana:http":
+		h.handleGrafana(w, req, p)
+	case p.ByName("service") == "web:http" && p.ByName("servicepath") == "/overview" && req.Method == http.MethodGet:
+		h.handleIndex(w, req, p)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// handleGrafana proxies the request to Grafana, under the path following
+// grafanaPath. Grafana shows the metrics of every namespace, so only the users
+// that may view all namespaces may view it.
+//
+// Grafana's anonymous users are editors, which lets them explore the metrics
+// with the dashboards' queries, so only the requests that can't change
+// Grafana's dashboards or settings are proxied.
+func (h *handler) handleGrafana(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		renderJsonError(w, errors.New("Grafana is read-only through the dashboard"), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.authorize(req, ""); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	proxied := *req
+	proxied.URL = new(url.URL)
+	*proxied.URL = *req.URL
+	proxied.URL.Path = p.ByName("servicepath")
+	proxied.URL.RawPath = ""
+	h.grafanaProxy.ServeHTTP(w, &proxied)
+}
+
+// removeCookie removes the cookie of the given name from the request.
+func removeCookie(req *http.Request, name string) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	kept := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			kept = append(kept, cookie.String())
+		}
+	}
+	if len(kept) > 0 {
+		req.Header.Set("Cookie", strings.Join(kept, "; "))
+	}
+}
@@ -0,0 +1,93 @@
+package srv
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestHandleGrafana(t *testing.T) {
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.URL.RequestURI() + " " + req.Header.Get("Authorization") + " " + req.Header.Get("Cookie")))
+	}))
+	defer grafana.Close()
+
+	authorizer, err := NewNamespaceAuthorizer(strings.NewReader("alice,*\nbob,emojivoto\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	h := &handler{
+		controllerNamespace: "linkerd",
+		authorizer:          authorizer,
+		grafanaProxy:        newGrafanaProxy(strings.TrimPrefix(grafana.URL, "http://")),
+	}
+	params := httprouter.Params{
+		{Key: "namespace", Value: "linkerd"},
+		{Key: "service", Value: "grafana:http"},
+		{Key: "servicepath", Value: "/dashboard/db/linkerd-deployment"},
+	}
+	path := grafanaPath("linkerd") + "/dashboard/db/linkerd-deployment"
+
+	t.Run("Proxies the requests of the users that may view all namespaces", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path+"?var-namespace=emojivoto", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "session"})
+		req.AddCookie(&http.Cookie{Name: "grafana_sess", Value: "grafana"})
+		req = req.WithContext(withUser(req.Context(), "alice"))
+		recorder := httptest.NewRecorder()
+		h.handleServiceProxy(recorder, req, params)
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, recorder.Code)
+		}
+		body, _ := ioutil.ReadAll(recorder.Body)
+		expected := "/dashboard/db/linkerd-deployment?var-namespace=emojivoto  grafana_sess=grafana"
+		if string(body) != expected {
+			t.Fatalf("Expected Grafana to be queried with [%s], got [%s]", expected, body)
+		}
+	})
+
+	t.Run("Rejects the users restricted to some namespaces", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		req = req.WithContext(withUser(req.Context(), "bob"))
+		recorder := httptest.NewRecorder()
+		h.handleServiceProxy(recorder, req, params)
+
+		if recorder.Code != http.StatusForbidden {
+			t.Fatalf("Expected status %d, got %d", http.StatusForbidden, recorder.Code)
+		}
+	})
+
+	t.Run("Rejects the requests that could change Grafana", func(t *testing.T) {
+		for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+			req := httptest.NewRequest(method, grafanaPath("linkerd")+"/api/dashboards/db", nil)
+			req = req.WithContext(withUser(req.Context(), "alice"))
+			recorder := httptest.NewRecorder()
+			h.handleGrafana(recorder, req, httprouter.Params{{Key: "servicepath", Value: "/api/dashboards/db"}})
+
+			if recorder.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("Expected status %d for %s, got %d", http.StatusMethodNotAllowed, method, recorder.Code)
+			}
+		}
+	})
+
+	t.Run("Only serves the services of the control plane", func(t *testing.T) {
+		for _, p := range []httprouter.Params{
+			{{Key: "namespace", Value: "emojivoto"}, {Key: "service", Value: "grafana:http"}, {Key: "servicepath", Value: "/"}},
+			{{Key: "namespace", Value: "linkerd"}, {Key: "service", Value: "prometheus:9090"}, {Key: "servicepath", Value: "/"}},
+		} {
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(withUser(req.Context(), "alice"))
+			recorder := httptest.NewRecorder()
+			h.handleServiceProxy(recorder, req, p)
+
+			if recorder.Code != http.StatusNotFound {
+				t.Fatalf("Expected status %d for %v, got %d", http.StatusNotFound, p, recorder.Code)
+			}
+		}
+	})
+}
@@ -1,8 +1,12 @@
 package srv
 
 import (
+	"io"
 	"net/http"
+	"net/http/httputil"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
@@ -12,6 +16,14 @@ import (
 var proxyPathRegexp = regexp.MustCompile("/api/v1/namespaces/.*/proxy/")
 
 type (
+	// StreamTracker tracks the websockets hijacked from the server's
+	// connections, which http.Server.Shutdown doesn't wait for, e.g. so that
+	// they're drained along with its requests. TrackStream's returned function
+	// is called once the websocket is closed.
+	StreamTracker interface {
+		TrackStream(stream io.Closer) func()
+	}
+
 	renderTemplate func(http.ResponseWriter, string, string, interface{}) error
 	serveFile      func(http.ResponseWriter, string, string, interface{}) error
 
@@ -21,20 +33,56 @@ type (
 		apiClient           pb.ApiClient
 		uuid                string
 		controllerNamespace string
+		pathPrefix          string
+		grafanaAddr         string
+		grafanaURL          string
+		grafanaProxy        *httputil.ReverseProxy
+		tapMaxDuration      time.Duration
+		authorizer          *NamespaceAuthorizer
+		streams             StreamTracker
 	}
 )
 
+// trackStream tracks the websocket with the server's StreamTracker, if set.
+func (h *handler) trackStream(ws io.Closer) func() {
+	if h.streams == nil {
+		return func() {}
+	}
+	return h.streams.TrackStream(ws)
+}
+
+// authorize returns an error unless the user of the request may view all the
+// namespaces, the empty namespace standing for all namespaces.
+func (h *handler) authorize(req *http.Request, namespaces ...string) error {
+	return h.authorizer.authorize(userFromContext(req.Context()), namespaces...)
+}
+
+// allows returns whether the user of the request may view the namespace. The
+// peers of no namespace, e.g. clients outside the cluster, aren't hidden.
+func (h *handler) allows(req *http.Request, namespace string) bool {
+	return namespace == "" || h.authorize(req, namespace) == nil
+}
+
 func (h *handler) handleIndex(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
 	// when running the dashboard via `linkerd dashboard`, serve the index bundle at the right path
 	pathPfx := proxyPathRegexp.FindString(req.URL.Path)
 	if pathPfx == "" {
 		pathPfx = "/"
 	}
+	if h.pathPrefix != "" {
+		pathPfx += strings.TrimPrefix(h.pathPrefix, "/") + "/"
+	}
 
 	params := appParams{
 		UUID:                h.uuid,
 		ControllerNamespace: h.controllerNamespace,
 		PathPrefix:          pathPfx,
+		GrafanaURL:          h.grafanaURL,
+	}
+	// the proxied Grafana is linked to unless the dashboard is itself viewed
+	// through the Kubernetes API's service proxy, which serves Grafana too
+	if h.grafanaProxy != nil && !proxyPathRegexp.MatchString(req.URL.Path) {
+		params.GrafanaURL = h.pathPrefix + grafanaPath(h.controllerNamespace)
 	}
 
 	version, err := h.apiClient.Version(req.Context(), &pb.Empty{}) // TODO: remove and call /api/version from web app
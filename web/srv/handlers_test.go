@@ -60,3 +60,70 @@ func TestHandleIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleIndexPathPrefix(t *testing.T) {
+	server := FakeServer()
+
+	for _, exp := range []struct {
+		pathPrefix string
+		path       string
+		expected   string
+	}{
+		{"", "/overview", "/"},
+		{"/linkerd", "/linkerd/overview", "/linkerd/"},
+		{"", "/api/v1/namespaces/linkerd/services/linkerd-web:http/proxy/overview", "/api/v1/namespaces/linkerd/services/linkerd-web:http/proxy/"},
+		{"/linkerd", "/api/v1/namespaces/linkerd/services/linkerd-web:http/proxy/linkerd/overview", "/api/v1/namespaces/linkerd/services/linkerd-web:http/proxy/linkerd/"},
+	} {
+		handler := &handler{
+			render:     server.RenderTemplate,
+			apiClient:  &public.MockApiClient{VersionInfoToReturn: &pb.VersionInfo{}},
+			pathPrefix: exp.pathPrefix,
+		}
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", exp.path, nil)
+		handler.handleIndex(recorder, req, httprouter.Params{})
+
+		expectedSubstrings := []string{
+			"data-path-prefix=\"" + exp.expected + "\"",
+			"src=\"" + exp.expected + "dist/index_bundle.js\"",
+		}
+		for _, expectedSubstring := range expectedSubstrings {
+			if !strings.Contains(recorder.Body.String(), expectedSubstring) {
+				t.Fatalf("Expected string [%s] to be present in [%s]", expectedSubstring, recorder.Body.String())
+			}
+		}
+	}
+}
+
+func TestHandleIndexGrafanaURL(t *testing.T) {
+	server := FakeServer()
+
+	for _, exp := range []struct {
+		pathPrefix string
+		path       string
+		expected   string
+	}{
+		{"", "/overview", "/api/v1/namespaces/linkerd/services/grafana:http/proxy"},
+		{"/linkerd", "/linkerd/overview", "/linkerd/api/v1/namespaces/linkerd/services/grafana:http/proxy"},
+		// the dashboard is viewed through the Kubernetes API's service proxy
+		{"", "/api/v1/namespaces/linkerd/services/web:http/proxy/overview", ""},
+	} {
+		handler := &handler{
+			render:              server.RenderTemplate,
+			apiClient:           &public.MockApiClient{VersionInfoToReturn: &pb.VersionInfo{}},
+			controllerNamespace: "linkerd",
+			pathPrefix:          exp.pathPrefix,
+			grafanaProxy:        newGrafanaProxy("grafana.linkerd.svc.cluster.local:3000"),
+		}
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", exp.path, nil)
+		handler.handleIndex(recorder, req, httprouter.Params{})
+
+		expected := "data-grafana-url=\"" + exp.expected + "\""
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Fatalf("Expected string [%s] to be present in [%s]", expected, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package srv
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// HostValidator validates the Host header of the requests to the dashboard,
+// which protects it from DNS rebinding attacks: a page of another site that
+// resolves its host to the address of the dashboard, e.g. 127.0.0.1 when it's
+// port-forwarded, still sends requests for its own host.
+//
+// The dashboard is served at localhost, at the names of the web service
+// within the cluster, and at IP addresses, which can't be rebound. The latter
+// include the address of the web pod, which the Kubernetes API server proxies
+// requests to.
+type HostValidator struct {
+	hosts    *regexp.Regexp
+	enforced *regexp.Regexp
+}
+
+// NewHostValidator returns a HostValidator of the hosts of the dashboard of
+// the control plane in controllerNamespace, extended with the hosts matching
+// the enforcedHost regexp, e.g. those of an ingress, unless it's empty.
+func NewHostValidator(controllerNamespace, enforcedHost string) (*HostValidator, error) {
+	ns := regexp.QuoteMeta(controllerNamespace)
+	validator := &HostValidator{
+		hosts: regexp.MustCompile(fmt.Sprintf(`^(localhost|web\.%s\.svc|web\.%s\.svc\.cluster\.local)$`, ns, ns)),
+	}
+
+	if enforcedHost != "" {
+		// the whole host must match, not just a part of it
+		enforced, err := regexp.Compile("^(" + enforcedHost + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid enforced host regexp: %s", err)
+		}
+		validator.enforced = enforced
+	}
+
+	return validator, nil
+}
+
+// Validate returns an error if the dashboard isn't served at host, the value
+// of a Host header whose port, if any, is ignored.
+func (v *HostValidator) Validate(host string) error {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	hostname = strings.ToLower(strings.TrimSuffix(strings.Trim(hostname, "[]"), "."))
+
+	if net.ParseIP(hostname) != nil || v.hosts.MatchString(hostname) {
+		return nil
+	}
+	if v.enforced != nil && v.enforced.MatchString(hostname) {
+		return nil
+	}
+	return fmt.Errorf("the dashboard isn't served at host %s", hostname)
+}
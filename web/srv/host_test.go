@@ -0,0 +1,70 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostValidator(t *testing.T) {
+	validator, err := NewHostValidator("linkerd", `dashboard\.example\.com`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Accepts the hosts of the dashboard", func(t *testing.T) {
+		for _, host := range []string{
+			"localhost",
+			"localhost:50750",
+			"LOCALHOST:50750",
+			"127.0.0.1:50750",
+			"[::1]:50750",
+			"[::1]",
+			"10.1.2.3:8084",
+			"web.linkerd.svc:8084",
+			"web.linkerd.svc.cluster.local.:8084",
+			"dashboard.example.com",
+		} {
+			if err := validator.Validate(host); err != nil {
+				t.Fatalf("Expected host %s to be accepted, got: %s", host, err)
+			}
+		}
+	})
+
+	t.Run("Rejects other hosts", func(t *testing.T) {
+		for _, host := range []string{
+			"attacker.com:50750",
+			"localhost.attacker.com",
+			"web.emojivoto.svc.cluster.local:8084",
+			"dashboard.example.com.attacker.com",
+			"evil-dashboard.example.com",
+		} {
+			if err := validator.Validate(host); err == nil {
+				t.Fatalf("Expected host %s to be rejected", host)
+			}
+		}
+	})
+
+	t.Run("Rejects invalid regexps", func(t *testing.T) {
+		if _, err := NewHostValidator("linkerd", "dashboard("); err == nil {
+			t.Fatal("Expected an error, got none")
+		}
+	})
+}
+
+func TestServeHTTPHostValidation(t *testing.T) {
+	validator, err := NewHostValidator("linkerd", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	server := NewServer(":0", "", "", "", "linkerd", "", "", "", "", false, 0, nil, nil, validator, nil, nil).Handler
+
+	req := httptest.NewRequest("GET", "/api/version", nil)
+	req.Host = "attacker.com:50750"
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+}
@@ -0,0 +1,93 @@
+package srv
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// loginPath and logoutPath open and close the sessions of the users of a
+	// tokenAuthenticator. They are served under the path prefix.
+	loginPath  = "/login"
+	logoutPath = "/logout"
+)
+
+type loginParams struct {
+	PathPrefix   string
+	Redirect     string
+	ErrorMessage string
+}
+
+// serveLogin serves the login page of a tokenAuthenticator, which exchanges
+// the token of its form for the cookie of a new session, and then redirects
+// to the page the user was trying to view.
+func (s *Server) serveLogin(w http.ResponseWriter, req *http.Request, authenticator *tokenAuthenticator) {
+	redirect := s.localRedirect(req.FormValue("redirect"))
+	if req.Method != http.MethodPost {
+		s.renderLogin(w, http.StatusOK, redirect, "")
+		return
+	}
+
+	id, expires, err := authenticator.login(req.PostFormValue("token"))
+	if err != nil {
+		s.renderLogin(w, http.StatusUnauthorized, redirect, err.Error())
+		return
+	}
+	setSessionCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     s.pathPrefix + "/",
+		Expires:  expires,
+		Secure:   isHTTPS(req),
+		HttpOnly: true,
+	})
+	http.Redirect(w, req, redirect, http.StatusSeeOther)
+}
+
+// serveLogout closes the session of the request, if any, and redirects to the
+// login page.
+func (s *Server) serveLogout(w http.ResponseWriter, req *http.Request, authenticator *tokenAuthenticator) {
+	if cookie, err := req.Cookie(sessionCookie); err == nil {
+		authenticator.logout(cookie.Value)
+	}
+	setSessionCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Path:     s.pathPrefix + "/",
+		MaxAge:   -1,
+		Secure:   isHTTPS(req),
+		HttpOnly: true,
+	})
+	http.Redirect(w, req, s.pathPrefix+loginPath, http.StatusSeeOther)
+}
+
+func (s *Server) renderLogin(w http.ResponseWriter, status int, redirect, errorMessage string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	s.RenderTemplate(w, "login.tmpl.html", "base", loginParams{
+		PathPrefix:   s.pathPrefix + "/",
+		Redirect:     redirect,
+		ErrorMessage: errorMessage,
+	})
+}
+
+// localRedirect returns the path to redirect to after logging in, which must
+// be a path of the dashboard rather than another site.
+func (s *Server) localRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, s.pathPrefix+"/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return s.pathPrefix + "/"
+	}
+	return redirect
+}
+
+// setSessionCookie sets the cookie of a session, which browsers only send
+// along with the dashboard's own requests, so that other sites can't send
+// requests on behalf of its users.
+func setSessionCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	w.Header().Add("Set-Cookie", cookie.String()+"; SameSite=Strict")
+}
+
+// isHTTPS returns whether the request was sent over TLS, either to the
+// dashboard or to the proxy in front of it.
+func isHTTPS(req *http.Request) bool {
+	return req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,74 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServeLogin(t *testing.T) {
+	authenticator, err := NewTokenAuthenticator(strings.NewReader("secret-1,alice\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	tokens := authenticator.(*tokenAuthenticator)
+	server := FakeServer()
+	server.pathPrefix = "/linkerd"
+
+	login := func(token, redirect string) *httptest.ResponseRecorder {
+		form := url.Values{"token": {token}, "redirect": {redirect}}
+		req := httptest.NewRequest("POST", "/linkerd/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		server.serveLogin(recorder, req, tokens)
+		return recorder
+	}
+
+	t.Run("Exchanges a valid token for the cookie of a session", func(t *testing.T) {
+		recorder := login("secret-1", "/linkerd/pods")
+		if recorder.Code != http.StatusSeeOther {
+			t.Fatalf("Expected status %d, got %d", http.StatusSeeOther, recorder.Code)
+		}
+		if location := recorder.Header().Get("Location"); location != "/linkerd/pods" {
+			t.Fatalf("Expected a redirect to /linkerd/pods, got %s", location)
+		}
+
+		cookie := recorder.Header().Get("Set-Cookie")
+		for _, expected := range []string{sessionCookie + "=", "Path=/linkerd/", "HttpOnly", "SameSite=Strict"} {
+			if !strings.Contains(cookie, expected) {
+				t.Fatalf("Expected the cookie to contain [%s], got %s", expected, cookie)
+			}
+		}
+
+		req := httptest.NewRequest("GET", "/linkerd/api/pods", nil)
+		req.AddCookie(recorder.Result().Cookies()[0])
+		user, err := authenticator.Authenticate(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user != "alice" {
+			t.Fatalf("Expected user alice, got %s", user)
+		}
+	})
+
+	t.Run("Rejects invalid tokens", func(t *testing.T) {
+		recorder := login("secret-2", "/linkerd/pods")
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, recorder.Code)
+		}
+		if cookie := recorder.Header().Get("Set-Cookie"); cookie != "" {
+			t.Fatalf("Expected no cookie, got %s", cookie)
+		}
+	})
+
+	t.Run("Only redirects to the dashboard", func(t *testing.T) {
+		for _, redirect := range []string{"https://example.com/", "//example.com/", "/\\example.com/", "/other"} {
+			recorder := login("secret-1", redirect)
+			if location := recorder.Header().Get("Location"); location != "/linkerd/" {
+				t.Fatalf("Expected a redirect to /linkerd/ instead of %s, got %s", redirect, location)
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package srv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+const (
+	// readyPath serves the readiness of the dashboard's dependencies. It isn't
+	// authenticated, nor served under the path prefix, so that `linkerd check`
+	// can always query it.
+	readyPath = "/ready"
+
+	readyTimeout = 5 * time.Second
+)
+
+// handleReady responds "ok" if the dashboard can query the public API and
+// Grafana, and with a 503 explaining which of them is unreachable otherwise.
+// An external Grafana is checked instead of the Grafana of the control plane.
+func (h *handler) handleReady(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	ctx, cancel := context.WithTimeout(req.Context(), readyTimeout)
+	defer cancel()
+
+	if _, err := h.apiClient.Version(ctx, &pb.Empty{}); err != nil {
+		http.Error(w, fmt.Sprintf("the public API is unreachable: %s", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	grafanaURL := h.grafanaURL
+	if grafanaURL == "" && h.grafanaAddr != "" {
+		grafanaURL = "http://" + h.grafanaAddr
+	}
+	if grafanaURL != "" {
+		if err := checkGrafana(ctx, grafanaURL); err != nil {
+			http.Error(w, fmt.Sprintf("Grafana is unreachable: %s", err), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.Write([]byte("ok\n"))
+}
+
+// checkGrafana queries the health endpoint of the Grafana server at
+// grafanaURL.
+func checkGrafana(ctx context.Context, grafanaURL string) error {
+	req, err := http.NewRequest("GET", grafanaURL+"/api/health", nil)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", rsp.Status)
+	}
+	return nil
+}
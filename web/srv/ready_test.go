@@ -0,0 +1,62 @@
+package srv
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestHandleReady(t *testing.T) {
+	grafana := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/health" {
+			http.NotFound(w, req)
+		}
+	}))
+	defer grafana.Close()
+	grafanaAddr := strings.TrimPrefix(grafana.URL, "http://")
+
+	expectations := []struct {
+		description string
+		apiErr      error
+		grafanaAddr string
+		grafanaURL  string
+		status      int
+		body        string
+	}{
+		{"ready", nil, grafanaAddr, "", http.StatusOK, "ok"},
+		{"public API unreachable", errors.New("connection refused"), grafanaAddr, "", http.StatusServiceUnavailable, "the public API is unreachable: connection refused"},
+		{"Grafana unreachable", nil, "127.0.0.1:0", "", http.StatusServiceUnavailable, "Grafana is unreachable"},
+		{"external Grafana ready", nil, "127.0.0.1:0", grafana.URL, http.StatusOK, "ok"},
+		{"external Grafana unreachable", nil, grafanaAddr, "http://127.0.0.1:0", http.StatusServiceUnavailable, "Grafana is unreachable"},
+	}
+
+	for _, exp := range expectations {
+		t.Run(exp.description, func(t *testing.T) {
+			handler := &handler{
+				apiClient: &public.MockApiClient{
+					VersionInfoToReturn: &pb.VersionInfo{},
+					ErrorToReturn:       exp.apiErr,
+				},
+				grafanaAddr: exp.grafanaAddr,
+				grafanaURL:  exp.grafanaURL,
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", readyPath, nil)
+			handler.handleReady(recorder, req, httprouter.Params{})
+
+			if recorder.Code != exp.status {
+				t.Fatalf("Expected status %d, got %d", exp.status, recorder.Code)
+			}
+			if !strings.HasPrefix(recorder.Body.String(), exp.body) {
+				t.Fatalf("Expected body [%s], got [%s]", exp.body, recorder.Body.String())
+			}
+		})
+	}
+}
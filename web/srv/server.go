@@ -6,11 +6,12 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/linkerd/linkerd2/controller/api/public"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
-	"github.com/linkerd/linkerd2/pkg/filesonly"
 	"github.com/linkerd/linkerd2/pkg/prometheus"
 	log "github.com/sirupsen/logrus"
 )
@@ -27,10 +28,16 @@ type (
 		templateContext templateContext
 		templates       map[string]*template.Template
 		router          *httprouter.Router
+		pathPrefix      string
+		authenticator   Authenticator
+		hostValidator   *HostValidator
 	}
 
 	templateContext struct {
 		WebpackDevServer string
+		// IndexBundle is the file name of the dashboard's bundle in the
+		// static dir, which is content-hashed in production builds.
+		IndexBundle string
 	}
 	templatePayload struct {
 		Context  templateContext
@@ -43,20 +50,76 @@ type (
 		Error               bool
 		ErrorMessage        string
 		PathPrefix          string
+		GrafanaURL          string
 	}
 )
 
 // this is called by the HTTP server to actually respond to a request
 func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.hostValidator != nil {
+		if err := s.hostValidator.Validate(req.Host); err != nil {
+			renderJsonError(w, err, http.StatusForbidden)
+			return
+		}
+	}
+
+	tokens, _ := s.authenticator.(*tokenAuthenticator)
+	if tokens != nil {
+		switch req.URL.Path {
+		case s.pathPrefix + loginPath:
+			s.serveLogin(w, req, tokens)
+			return
+		case s.pathPrefix + logoutPath:
+			s.serveLogout(w, req, tokens)
+			return
+		}
+	}
+
+	if s.authenticator != nil && req.URL.Path != readyPath {
+		user, err := s.authenticator.Authenticate(req)
+		if err != nil {
+			// the dashboard's users log in with their token, which the API's
+			// clients bear instead
+			if tokens != nil && req.Method == http.MethodGet && !strings.HasPrefix(req.URL.Path, s.pathPrefix+"/api/") {
+				s.renderLogin(w, http.StatusUnauthorized, s.localRedirect(req.URL.RequestURI()), "")
+				return
+			}
+			renderJsonError(w, err, http.StatusUnauthorized)
+			return
+		}
+		req = req.WithContext(withUser(req.Context(), user))
+	}
+
+	// forward the credentials of the user to the public API, which may
+	// authorize its queries, unless they are the dashboard's own tokens
+	if tokens == nil {
+		if authorization := req.Header.Get("Authorization"); authorization != "" {
+			req = req.WithContext(public.WithAuthorization(req.Context(), authorization))
+		}
+	}
 	s.router.ServeHTTP(w, req)
 }
 
-func NewServer(addr, templateDir, staticDir, uuid, controllerNamespace, webpackDevServer string, reload bool, apiClient pb.ApiClient) *http.Server {
+// NewServer returns the dashboard's server. Its users are authenticated by the
+// authenticator and their views restricted by the authorizer, if set, after
+// the Host of their requests is validated by the hostValidator, if set. Its
+// websockets are tracked by streams, if set. All its routes are served under
+// pathPrefix, e.g. "/linkerd" behind an ingress.
+func NewServer(addr, templateDir, staticDir, uuid, controllerNamespace, webpackDevServer, pathPrefix, grafanaAddr, grafanaURL string, reload bool, tapMaxDuration time.Duration, authenticator Authenticator, authorizer *NamespaceAuthorizer, hostValidator *HostValidator, streams StreamTracker, apiClient pb.ApiClient) *http.Server {
+	// "/linkerd/" and "linkerd" both serve the dashboard at /linkerd/
+	pathPrefix = strings.TrimSuffix("/"+strings.Trim(pathPrefix, "/"), "/")
+
 	server := &Server{
-		templateDir:     templateDir,
-		staticDir:       staticDir,
-		templateContext: templateContext{webpackDevServer},
-		reload:          reload,
+		templateDir: templateDir,
+		staticDir:   staticDir,
+		templateContext: templateContext{
+			WebpackDevServer: webpackDevServer,
+			IndexBundle:      loadIndexBundle(staticDir),
+		},
+		reload:        reload,
+		pathPrefix:    pathPrefix,
+		authenticator: authenticator,
+		hostValidator: hostValidator,
 	}
 
 	server.router = &httprouter.Router{
@@ -72,6 +135,16 @@ func NewServer(addr, templateDir, staticDir, uuid, controllerNamespace, webpackD
 		serveFile:           server.serveFile,
 		uuid:                uuid,
 		controllerNamespace: controllerNamespace,
+		pathPrefix:          pathPrefix,
+		grafanaAddr:         grafanaAddr,
+		grafanaURL:          strings.TrimSuffix(grafanaURL, "/"),
+		tapMaxDuration:      tapMaxDuration,
+		authorizer:          authorizer,
+		streams:             streams,
+	}
+	// an external Grafana is linked to rather than proxied
+	if grafanaURL == "" && grafanaAddr != "" {
+		handler.grafanaProxy = newGrafanaProxy(grafanaAddr)
 	}
 
 	httpServer := &http.Server{
@@ -81,33 +154,61 @@ func NewServer(addr, templateDir, staticDir, uuid, controllerNamespace, webpackD
 		Handler:      wrappedServer,
 	}
 
+	route := func(path string) string { return pathPrefix + path }
+	if pathPrefix != "" {
+		server.router.GET("/", func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			http.Redirect(w, req, route("/"), http.StatusFound)
+		})
+	}
+
+	server.router.GET(readyPath, handler.handleReady)
+
 	// webapp routes
-	server.router.GET("/", handler.handleIndex)
-	server.router.GET("/overview", handler.handleIndex)
-	server.router.GET("/servicemesh", handler.handleIndex)
-	server.router.GET("/namespaces", handler.handleIndex)
-	server.router.GET("/namespaces/:namespace", handler.handleIndex)
-	server.router.GET("/deployments", handler.handleIndex)
-	server.router.GET("/replicationcontrollers", handler.handleIndex)
-	server.router.GET("/pods", handler.handleIndex)
-	server.router.GET("/authorities", handler.handleIndex)
-	server.router.GET("/namespaces/:namespace/pods/:pod", handler.handleIndex)
-	server.router.GET("/namespaces/:namespace/deployments/:deployment", handler.handleIndex)
-	server.router.GET("/namespaces/:namespace/replicationcontrollers/:replicationcontroller", handler.handleIndex)
-	server.router.GET("/tap", handler.handleIndex)
-	server.router.GET("/top", handler.handleIndex)
-	server.router.ServeFiles(
-		"/dist/*filepath", // add catch-all parameter to match all files in dir
-		filesonly.FileSystem(server.staticDir))
+	server.router.GET(route("/"), handler.handleIndex)
+	server.router.GET(route("/overview"), handler.handleIndex)
+	server.router.GET(route("/servicemesh"), handler.handleIndex)
+	server.router.GET(route("/namespaces"), handler.handleIndex)
+	server.router.GET(route("/namespaces/:namespace"), handler.handleIndex)
+	server.router.GET(route("/deployments"), handler.handleIndex)
+	server.router.GET(route("/replicationcontrollers"), handler.handleIndex)
+	server.router.GET(route("/pods"), handler.handleIndex)
+	server.router.GET(route("/authorities"), handler.handleIndex)
+	server.router.GET(route("/namespaces/:namespace/pods/:pod"), handler.handleIndex)
+	server.router.GET(route("/namespaces/:namespace/deployments/:deployment"), handler.handleIndex)
+	server.router.GET(route("/namespaces/:namespace/replicationcontrollers/:replicationcontroller"), handler.handleIndex)
+	server.router.GET(route("/tap"), handler.handleIndex)
+	server.router.GET(route("/top"), handler.handleIndex)
+	server.router.GET(
+		route("/dist/*filepath"), // add catch-all parameter to match all files in dir
+		newStaticHandler(server.staticDir).handle)
 
 	// webapp api routes
-	server.router.GET("/api/version", handler.handleApiVersion)
+	server.router.GET(route("/api/version"), handler.handleApiVersion)
 	// Traffic Performance Summary.  This route used to be called /api/stat
 	// but was renamed to avoid triggering ad blockers.
 	// See: https://github.com/linkerd/linkerd2/issues/970
-	server.router.GET("/api/tps-reports", handler.handleApiStat)
-	server.router.GET("/api/pods", handler.handleApiPods)
-	server.router.GET("/api/tap", handler.handleApiTap)
+	server.router.GET(route("/api/tps-reports"), handler.handleApiStat)
+	server.router.GET(route("/api/tps-reports/stream"), handler.handleApiStatStream)
+	// The stats of `linkerd stat`, for tools other than the dashboard.
+	server.router.GET(route("/api/stat"), handler.handleApiCliStat)
+	server.router.GET(route("/api/pods"), handler.handleApiPods)
+	server.router.GET(route("/api/services"), handler.handleApiServices)
+	server.router.GET(route("/api/routes"), handler.handleApiTopRoutes)
+	server.router.GET(route("/api/edges"), handler.handleApiEdges)
+	server.router.GET(route("/api/tap"), handler.handleApiTap)
+	server.router.GET(route("/api/resource/:kind/:namespace/:name"), handler.handleApiResource)
+
+	// Grafana routes, under the paths of the Kubernetes API's service proxy
+	if handler.grafanaProxy != nil {
+		server.router.GET(route(serviceProxyRoute), handler.handleServiceProxy)
+		server.router.HEAD(route(serviceProxyRoute), handler.handleServiceProxy)
+		// `linkerd dashboard` links to Grafana without the path prefix
+		if pathPrefix != "" {
+			server.router.GET(serviceProxyRoute, func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+				http.Redirect(w, req, route(req.URL.RequestURI()), http.StatusFound)
+			})
+		}
+	}
 
 	return httpServer
 }
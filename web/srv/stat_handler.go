@@ -0,0 +1,194 @@
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+type (
+	// statResponse is the JSON response of /api/stat, with a row per resource
+	// of `linkerd stat`.
+	statResponse struct {
+		Rows []*statRow `json:"rows"`
+	}
+
+	statRow struct {
+		Namespace   string `json:"namespace"`
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		MeshedPods  uint64 `json:"meshedPods"`
+		RunningPods uint64 `json:"runningPods"`
+		// Stats is nil if the resource didn't receive requests during the
+		// time window.
+		Stats *statRowStats `json:"stats"`
+	}
+
+	statRowStats struct {
+		SuccessRate        float64 `json:"successRate"`
+		RequestRate        float64 `json:"requestRate"`
+		LatencyMsP50       uint64  `json:"latencyMsP50"`
+		LatencyMsP95       uint64  `json:"latencyMsP95"`
+		LatencyMsP99       uint64  `json:"latencyMsP99"`
+		TlsPercent         float64 `json:"tlsPercent"`
+		NoIdentityPercent  float64 `json:"noIdentityPercent"`
+		TlsDisabledPercent float64 `json:"tlsDisabledPercent"`
+	}
+)
+
+// handleApiCliStat serves the stats of `linkerd stat`. It takes the
+// arguments and flags of the command as query parameters: "resource" (e.g.
+// "deploy" or "deploy/web"), "namespace", "all_namespaces", "to",
+// "to_namespace", "from", "from_namespace" and "window".
+func (h *handler) handleApiCliStat(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	statRequest, err := buildCliStatRequest(req)
+	if err != nil {
+		renderJsonError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authorize(req, statSummaryNamespaces(statRequest)...); err != nil {
+		renderJsonError(w, err, http.StatusForbidden)
+		return
+	}
+
+	rsp, err := h.apiClient.StatSummary(req.Context(), statRequest)
+	if err != nil {
+		renderJsonError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if e := rsp.GetError(); e != nil {
+		renderJsonError(w, errors.New(e.GetError()), http.StatusBadRequest)
+		return
+	}
+
+	renderJson(w, buildStatResponse(rsp, statRequest.GetSelector().GetResource().GetType()))
+}
+
+// buildCliStatRequest builds a StatSummaryRequest from the query parameters
+// of the request, like `linkerd stat` does from its arguments and flags.
+func buildCliStatRequest(req *http.Request) (*pb.StatSummaryRequest, error) {
+	resource := req.FormValue("resource")
+	if resource == "" {
+		return nil, errors.New("the resource parameter must be set")
+	}
+
+	namespace := req.FormValue("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	toResource := req.FormValue("to")
+	toNamespace := req.FormValue("to_namespace")
+	fromResource := req.FormValue("from")
+	fromNamespace := req.FormValue("from_namespace")
+
+	if toResource != "" && fromResource != "" {
+		return nil, errors.New("the to and from parameters are mutually exclusive")
+	}
+	if toNamespace != "" && fromNamespace != "" {
+		return nil, errors.New("the to_namespace and from_namespace parameters are mutually exclusive")
+	}
+
+	target, err := util.BuildResource(namespace, resource)
+	if err != nil {
+		return nil, err
+	}
+	if target.Type == k8s.Namespace {
+		for param, value := range map[string]string{
+			"to_namespace":   toNamespace,
+			"from_namespace": fromNamespace,
+		} {
+			if value != "" {
+				return nil, fmt.Errorf("the %s parameter is incompatible with the namespace resource type", param)
+			}
+		}
+		if namespace != "default" {
+			return nil, errors.New("the namespace parameter is incompatible with the namespace resource type")
+		}
+	}
+
+	var toRes, fromRes pb.Resource
+	if toResource != "" {
+		toRes, err = util.BuildResource(toNamespace, toResource)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if fromResource != "" {
+		fromRes, err = util.BuildResource(fromNamespace, fromResource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return util.BuildStatSummaryRequest(util.StatSummaryRequestParams{
+		TimeWindow:    req.FormValue("window"),
+		ResourceName:  target.Name,
+		ResourceType:  target.Type,
+		Namespace:     namespace,
+		ToName:        toRes.Name,
+		ToType:        toRes.Type,
+		ToNamespace:   toNamespace,
+		FromName:      fromRes.Name,
+		FromType:      fromRes.Type,
+		FromNamespace: fromNamespace,
+		AllNamespaces: req.FormValue("all_namespaces") == "true",
+	})
+}
+
+// buildStatResponse returns the rows of the requested resource type, sorted
+// like the tables of `linkerd stat`: by resource type for "all", and then by
+// namespace and name.
+func buildStatResponse(rsp *pb.StatSummaryResponse, resourceType string) *statResponse {
+	rows := make([]*statRow, 0)
+	for _, table := range rsp.GetOk().GetStatTables() {
+		for _, r := range table.GetPodGroup().GetRows() {
+			if resourceType != k8s.All && r.GetResource().GetType() != resourceType {
+				continue
+			}
+
+			row := &statRow{
+				Namespace:   r.GetResource().GetNamespace(),
+				Type:        r.GetResource().GetType(),
+				Name:        r.GetResource().GetName(),
+				MeshedPods:  r.GetMeshedPodCount(),
+				RunningPods: r.GetRunningPodCount(),
+			}
+			if r.Stats != nil {
+				row.Stats = &statRowStats{
+					SuccessRate:        util.GetSuccessRate(r),
+					RequestRate:        util.GetRequestRate(r),
+					LatencyMsP50:       r.Stats.LatencyMsP50,
+					LatencyMsP95:       r.Stats.LatencyMsP95,
+					LatencyMsP99:       r.Stats.LatencyMsP99,
+					TlsPercent:         util.GetPercentOfRequests(r, r.Stats.TlsRequestCount),
+					NoIdentityPercent:  util.GetPercentOfRequests(r, r.Stats.NoIdentityRequestCount),
+					TlsDisabledPercent: util.GetPercentOfRequests(r, r.Stats.TlsDisabledRequestCount),
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	typeOrder := make(map[string]int)
+	for i, t := range k8s.StatAllResourceTypes {
+		typeOrder[t] = i
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Type != b.Type {
+			return typeOrder[a.Type] < typeOrder[b.Type]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return &statResponse{Rows: rows}
+}
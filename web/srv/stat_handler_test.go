@@ -0,0 +1,113 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+func TestHandleApiCliStat(t *testing.T) {
+	mockApiClient := &public.MockApiClient{
+		StatSummaryResponseToReturn: &pb.StatSummaryResponse{
+			Response: &pb.StatSummaryResponse_Ok_{
+				Ok: &pb.StatSummaryResponse_Ok{
+					StatTables: []*pb.StatTable{
+						&pb.StatTable{
+							Table: &pb.StatTable_PodGroup_{
+								PodGroup: &pb.StatTable_PodGroup{
+									Rows: []*pb.StatTable_PodGroup_Row{
+										&pb.StatTable_PodGroup_Row{
+											Resource:        &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "web"},
+											TimeWindow:      "1m",
+											MeshedPodCount:  1,
+											RunningPodCount: 2,
+											Stats: &pb.BasicStats{
+												SuccessCount:    90,
+												FailureCount:    30,
+												LatencyMsP50:    10,
+												LatencyMsP95:    20,
+												LatencyMsP99:    30,
+												TlsRequestCount: 60,
+											},
+										},
+										&pb.StatTable_PodGroup_Row{
+											Resource:        &pb.Resource{Namespace: "emojivoto", Type: "deployment", Name: "emoji"},
+											TimeWindow:      "1m",
+											MeshedPodCount:  1,
+											RunningPodCount: 1,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	handler := &handler{apiClient: mockApiClient}
+
+	t.Run("Returns the rows of `linkerd stat`", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/stat?resource=deploy&namespace=emojivoto", nil)
+		handler.handleApiCliStat(recorder, req, httprouter.Params{})
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+		}
+
+		var rsp statResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &rsp); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := statResponse{
+			Rows: []*statRow{
+				&statRow{Namespace: "emojivoto", Type: "deployment", Name: "emoji", MeshedPods: 1, RunningPods: 1},
+				&statRow{
+					Namespace:   "emojivoto",
+					Type:        "deployment",
+					Name:        "web",
+					MeshedPods:  1,
+					RunningPods: 2,
+					Stats: &statRowStats{
+						SuccessRate:  0.75,
+						RequestRate:  2,
+						LatencyMsP50: 10,
+						LatencyMsP95: 20,
+						LatencyMsP99: 30,
+						TlsPercent:   0.5,
+					},
+				},
+			},
+		}
+		if !reflect.DeepEqual(rsp, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, rsp)
+		}
+	})
+
+	t.Run("Rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"",
+			"resource=foo",
+			"resource=deploy&to=deploy/web&from=deploy/emoji",
+			"resource=deploy&to_namespace=emojivoto&from_namespace=default",
+			"resource=ns&namespace=emojivoto",
+			"resource=ns&to_namespace=emojivoto",
+			"resource=deploy&window=forever",
+		} {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/stat?"+query, nil)
+			handler.handleApiCliStat(recorder, req, httprouter.Params{})
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d for [%s], got %d", http.StatusBadRequest, query, recorder.Code)
+			}
+		}
+	})
+}
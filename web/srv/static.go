@@ -0,0 +1,229 @@
+package srv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// indexBundle is the name of the dashboard's bundle in the manifest
+	// written by webpack, which maps it to its content-hashed file name.
+	indexBundle  = "index_bundle.js"
+	manifestFile = "manifest.json"
+
+	// content-hashed assets never change, while the others are revalidated
+	// with conditional requests
+	immutableCacheControl  = "public, max-age=31536000, immutable"
+	revalidateCacheControl = "no-cache"
+)
+
+var (
+	// contentHashRegexp matches the names of the assets whose content hash
+	// is part of their name, such as index_bundle.8f2a63c1bde3e5c4d1a7.js.
+	contentHashRegexp = regexp.MustCompile(`\.[0-9a-f]{16,}\.`)
+
+	// compressibleExtensions are the assets that are gzipped when they aren't
+	// precompressed.
+	compressibleExtensions = map[string]struct{}{
+		".css":  {},
+		".html": {},
+		".js":   {},
+		".json": {},
+		".map":  {},
+		".svg":  {},
+	}
+
+	// precompressedEncodings are the encodings of precompressed assets, by
+	// order of preference, and the extensions of their files.
+	precompressedEncodings = []struct{ encoding, extension string }{
+		{"br", ".br"},
+		{"gzip", ".gz"},
+	}
+)
+
+type (
+	// staticHandler serves the dashboard's assets, compressed if the browser
+	// accepts it, with cache headers and support for conditional requests.
+	// Directories aren't listed.
+	staticHandler struct {
+		dir string
+
+		sync.Mutex
+		gzipped map[string]*gzippedAsset
+	}
+
+	gzippedAsset struct {
+		modTime time.Time
+		content []byte
+	}
+)
+
+func newStaticHandler(dir string) *staticHandler {
+	return &staticHandler{
+		dir:     dir,
+		gzipped: make(map[string]*gzippedAsset),
+	}
+}
+
+func (s *staticHandler) handle(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+	name := p.ByName("filepath")
+	path := safelyJoinPath(s.dir, name)
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, req)
+		return
+	}
+
+	ext := filepath.Ext(path)
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	if contentHashRegexp.MatchString(filepath.Base(path)) {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", revalidateCacheControl)
+	}
+
+	_, compressible := compressibleExtensions[ext]
+	if compressible {
+		w.Header().Set("Vary", "Accept-Encoding")
+
+		for _, pre := range precompressedEncodings {
+			if !acceptsEncoding(req, pre.encoding) {
+				continue
+			}
+			file, err := os.Open(path + pre.extension)
+			if err != nil {
+				continue
+			}
+			defer file.Close()
+
+			setEncoding(w, info, pre.encoding)
+			http.ServeContent(w, req, name, info.ModTime(), file)
+			return
+		}
+
+		if acceptsEncoding(req, "gzip") {
+			content, err := s.gzip(path, info)
+			if err != nil {
+				log.Errorf("failed to gzip %s: %s", path, err)
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			setEncoding(w, info, "gzip")
+			http.ServeContent(w, req, name, info.ModTime(), bytes.NewReader(content))
+			return
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer file.Close()
+
+	setEncoding(w, info, "")
+	http.ServeContent(w, req, name, info.ModTime(), file)
+}
+
+// gzip returns the gzipped content of the asset, which is cached until the
+// asset is modified.
+func (s *staticHandler) gzip(path string, info os.FileInfo) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if asset, ok := s.gzipped[path]; ok && asset.modTime.Equal(info.ModTime()) {
+		return asset.content, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	writer, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	s.gzipped[path] = &gzippedAsset{modTime: info.ModTime(), content: buf.Bytes()}
+	return buf.Bytes(), nil
+}
+
+// setEncoding sets the Content-Encoding of the response, and an ETag
+// identifying the version of the asset in this encoding, which
+// http.ServeContent compares to the If-None-Match header of conditional
+// requests.
+func setEncoding(w http.ResponseWriter, info os.FileInfo, encoding string) {
+	etag := fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+		etag += "-" + encoding
+	}
+	w.Header().Set("ETag", `"`+etag+`"`)
+}
+
+// acceptsEncoding returns true if the Accept-Encoding header of the request
+// accepts the encoding.
+func acceptsEncoding(req *http.Request, encoding string) bool {
+	for _, header := range req.Header["Accept-Encoding"] {
+		for _, accepted := range strings.Split(header, ",") {
+			parts := strings.Split(accepted, ";")
+			if strings.TrimSpace(parts[0]) != encoding {
+				continue
+			}
+			if len(parts) > 1 && strings.Replace(parts[1], " ", "", -1) == "q=0" {
+				return false
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// loadIndexBundle returns the content-hashed file name of the dashboard's
+// bundle, from the manifest written by webpack in dir. Bundles built without
+// a manifest aren't hashed.
+func loadIndexBundle(dir string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Errorf("failed to read the manifest of the assets: %s", err)
+		}
+		return indexBundle
+	}
+
+	var manifest map[string]string
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		log.Errorf("failed to parse the manifest of the assets: %s", err)
+		return indexBundle
+	}
+	if bundle, ok := manifest[indexBundle]; ok {
+		return bundle
+	}
+	return indexBundle
+}
@@ -0,0 +1,121 @@
+package srv
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestStaticHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	bundle := "index_bundle.8f2a63c1bde3e5c4d1a7.js"
+	files := map[string]string{
+		bundle:            "console.log('linkerd');",
+		"styles.css":      "body { margin: 0; }",
+		"styles.css.br":   "brotli",
+		"img/favicon.png": "png",
+		manifestFile:      `{"index_bundle.js": "` + bundle + `"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	handler := newStaticHandler(dir)
+	serve := func(name string, header http.Header) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/dist/"+name, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		handler.handle(recorder, req, httprouter.Params{{Key: "filepath", Value: "/" + name}})
+		return recorder
+	}
+
+	t.Run("Gzips content-hashed assets and caches them forever", func(t *testing.T) {
+		rsp := serve(bundle, http.Header{"Accept-Encoding": {"gzip, deflate"}})
+
+		if rsp.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rsp.Code)
+		}
+		if rsp.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected a gzipped response, got headers %v", rsp.Header())
+		}
+		if rsp.Header().Get("Cache-Control") != immutableCacheControl {
+			t.Fatalf("Expected Cache-Control [%s], got [%s]", immutableCacheControl, rsp.Header().Get("Cache-Control"))
+		}
+
+		reader, err := gzip.NewReader(rsp.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if string(content) != files[bundle] {
+			t.Fatalf("Expected content [%s], got [%s]", files[bundle], content)
+		}
+	})
+
+	t.Run("Serves precompressed assets", func(t *testing.T) {
+		rsp := serve("styles.css", http.Header{"Accept-Encoding": {"gzip, br"}})
+
+		if rsp.Header().Get("Content-Encoding") != "br" || rsp.Body.String() != "brotli" {
+			t.Fatalf("Expected the brotli asset, got headers %v and body [%s]", rsp.Header(), rsp.Body.String())
+		}
+		if rsp.Header().Get("Cache-Control") != revalidateCacheControl {
+			t.Fatalf("Expected Cache-Control [%s], got [%s]", revalidateCacheControl, rsp.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("Serves uncompressed assets to browsers that don't accept compression", func(t *testing.T) {
+		rsp := serve("styles.css", http.Header{"Accept-Encoding": {"br;q=0"}})
+
+		if rsp.Header().Get("Content-Encoding") != "" || rsp.Body.String() != files["styles.css"] {
+			t.Fatalf("Expected the uncompressed asset, got headers %v and body [%s]", rsp.Header(), rsp.Body.String())
+		}
+	})
+
+	t.Run("Responds to conditional requests", func(t *testing.T) {
+		etag := serve("img/favicon.png", nil).Header().Get("ETag")
+		rsp := serve("img/favicon.png", http.Header{"If-None-Match": {etag}})
+
+		if rsp.Code != http.StatusNotModified {
+			t.Fatalf("Expected status %d, got %d", http.StatusNotModified, rsp.Code)
+		}
+	})
+
+	t.Run("Doesn't list directories", func(t *testing.T) {
+		for _, name := range []string{"img", "missing.js", "../static_test.go"} {
+			if rsp := serve(name, nil); rsp.Code != http.StatusNotFound {
+				t.Fatalf("Expected status %d for %s, got %d", http.StatusNotFound, name, rsp.Code)
+			}
+		}
+	})
+
+	t.Run("Loads the content-hashed name of the bundle", func(t *testing.T) {
+		if name := loadIndexBundle(dir); name != bundle {
+			t.Fatalf("Expected bundle %s, got %s", bundle, name)
+		}
+		if name := loadIndexBundle(filepath.Join(dir, "img")); name != indexBundle {
+			t.Fatalf("Expected bundle %s, got %s", indexBundle, name)
+		}
+	})
+}
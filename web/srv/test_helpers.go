@@ -4,5 +4,8 @@ func FakeServer() Server {
 	return Server{
 		templateDir: "../templates",
 		reload:      true,
+		templateContext: templateContext{
+			IndexBundle: indexBundle,
+		},
 	}
 }